@@ -0,0 +1,132 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+
+	"github.com/wends155/opcda/com"
+)
+
+// ItemStage names the step of AddItemsWithReport at which an item was processed.
+type ItemStage string
+
+const (
+	// ItemStageValidate is the ValidateItems call made before adding when validation is requested.
+	ItemStageValidate ItemStage = "validate"
+	// ItemStageAdd is the AddItems call.
+	ItemStageAdd ItemStage = "add"
+)
+
+// AddItemsReportEntry records the outcome of one tag of AddItemsWithReport.
+type AddItemsReportEntry struct {
+	// Tag is the item ID.
+	Tag string `json:"tag"`
+	// ClientHandle is the client handle of the added item, or 0 if the item was not added.
+	ClientHandle uint32 `json:"clientHandle"`
+	// HRESULT is the raw result code reported by the server for the item at Stage.
+	HRESULT int32 `json:"hresult"`
+	// Message is the server's description of a failed HRESULT, or empty on success.
+	Message string `json:"message,omitempty"`
+	// Stage is the last step the item went through: the step that rejected it, or ItemStageAdd.
+	Stage ItemStage `json:"stage"`
+	// CanonicalType is the native data type reported by the server, if it accepted the item.
+	CanonicalType com.VT `json:"canonicalType"`
+	// AccessRights are the access rights reported by the server, if it accepted the item.
+	AccessRights uint32 `json:"accessRights"`
+}
+
+// Failed reports whether the server rejected the item.
+func (e AddItemsReportEntry) Failed() bool {
+	return e.HRESULT < 0
+}
+
+// AddItemsReport is a machine-readable account of an AddItemsWithReport call, suitable for
+// marshalling to JSON.
+type AddItemsReport struct {
+	// Entries holds one entry per tag, in the order of the tags.
+	Entries []AddItemsReportEntry `json:"entries"`
+	// Added counts the items that were added.
+	Added int `json:"added"`
+	// ValidateFailed counts the items rejected by ValidateItems.
+	ValidateFailed int `json:"validateFailed"`
+	// AddFailed counts the items rejected by AddItems.
+	AddFailed int `json:"addFailed"`
+}
+
+// AddItemsWithReport adds items like AddItems and additionally returns an AddItemsReport with the raw
+// result code, stage, canonical data type and access rights of every tag. When validate is true the tags
+// are checked with ValidateItems first and only those the server accepts are added; the others are
+// reported at ItemStageValidate. The returned items and errors are parallel to tags, as with AddItems.
+//
+// Example:
+//
+//	_, _, report, err := items.AddItemsWithReport(tags, true)
+//	if err == nil {
+//		json.NewEncoder(f).Encode(report)
+//	}
+func (is *OPCItems) AddItemsWithReport(tags []string, validate bool) ([]*OPCItem, []error, *AddItemsReport, error) {
+	if is == nil || is.itemMgtProvider == nil {
+		return nil, nil, nil, errors.New("uninitialized items or failed group connection")
+	}
+	report := &AddItemsReport{Entries: make([]AddItemsReportEntry, len(tags))}
+	if len(tags) == 0 {
+		return nil, nil, report, nil
+	}
+	defaults := is.GetDefaults()
+	defs := make([]ItemDef, len(tags))
+	for i, tag := range tags {
+		defs[i] = defaults
+		defs[i].Tag = tag
+	}
+	opcItems := make([]*OPCItem, len(defs))
+	resultErrors := make([]error, len(defs))
+	pending := make([]int, 0, len(defs))
+	if validate {
+		definitions := is.createDefinitions(defs, make(map[uint32]struct{}))
+		_, errs, err := is.itemMgtProvider.ValidateItems(definitions, false)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		for j, def := range defs {
+			if errs[j] >= 0 {
+				pending = append(pending, j)
+				continue
+			}
+			resultErrors[j] = is.getError(errs[j])
+			report.Entries[j] = AddItemsReportEntry{
+				Tag:     def.Tag,
+				HRESULT: errs[j],
+				Message: resultErrors[j].Error(),
+				Stage:   ItemStageValidate,
+			}
+			report.ValidateFailed++
+		}
+	} else {
+		for j := range defs {
+			pending = append(pending, j)
+		}
+	}
+	if len(pending) > 0 {
+		addDefs := make([]ItemDef, len(pending))
+		for k, j := range pending {
+			addDefs[k] = defs[j]
+		}
+		entries := make([]AddItemsReportEntry, len(pending))
+		added, errs, err := is.addItems(addDefs, entries)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		for k, j := range pending {
+			opcItems[j] = added[k]
+			resultErrors[j] = errs[k]
+			report.Entries[j] = entries[k]
+			if entries[k].Failed() {
+				report.AddFailed++
+			} else {
+				report.Added++
+			}
+		}
+	}
+	return opcItems, resultErrors, report, nil
+}
@@ -0,0 +1,84 @@
+//go:build windows
+
+package opcda
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wends155/opcda/com"
+	"golang.org/x/sys/windows"
+)
+
+func TestOPCItems_AddItemsWithReport_Mocked(t *testing.T) {
+	var addedTags []string
+	group := &OPCGroup{groupProvider: &mockGroupProvider{}}
+	items := NewOPCItems(group, &mockItemMgtProvider{
+		ValidateItemsFn: func(defs []com.TagOPCITEMDEF, bBlob bool) ([]com.TagOPCITEMRESULTStruct, []int32, error) {
+			errs := make([]int32, len(defs))
+			for i, def := range defs {
+				if windows.UTF16PtrToString(def.SzItemID) == "Missing" {
+					errs[i] = int32(OPCUnknownItemID)
+				}
+			}
+			return make([]com.TagOPCITEMRESULTStruct, len(defs)), errs, nil
+		},
+		AddItemsFn: func(defs []com.TagOPCITEMDEF) ([]com.TagOPCITEMRESULTStruct, []int32, error) {
+			results := make([]com.TagOPCITEMRESULTStruct, len(defs))
+			errs := make([]int32, len(defs))
+			for i, def := range defs {
+				tag := windows.UTF16PtrToString(def.SzItemID)
+				addedTags = append(addedTags, tag)
+				if tag == "Locked" {
+					errs[i] = int32(OPCBadRights)
+					continue
+				}
+				results[i] = com.TagOPCITEMRESULTStruct{Server: uint32(i + 1), NativeType: uint16(com.VT_R8), AccessRights: OPC_READABLE}
+			}
+			return results, errs, nil
+		},
+	}, &mockServerProvider{})
+
+	added, errs, report, err := items.AddItemsWithReport([]string{"Random.Real8", "Missing", "Locked"}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Random.Real8", "Locked"}, addedTags)
+	assert.NotNil(t, added[0])
+	assert.Nil(t, added[1])
+	assert.Nil(t, added[2])
+	assert.NoError(t, errs[0])
+	assert.Error(t, errs[1])
+	assert.Error(t, errs[2])
+
+	assert.Equal(t, 1, report.Added)
+	assert.Equal(t, 1, report.ValidateFailed)
+	assert.Equal(t, 1, report.AddFailed)
+	assert.Equal(t, AddItemsReportEntry{
+		Tag:           "Random.Real8",
+		ClientHandle:  added[0].GetClientHandle(),
+		Stage:         ItemStageAdd,
+		CanonicalType: com.VT_R8,
+		AccessRights:  OPC_READABLE,
+	}, report.Entries[0])
+	assert.Equal(t, ItemStageValidate, report.Entries[1].Stage)
+	assert.Equal(t, int32(OPCUnknownItemID), report.Entries[1].HRESULT)
+	assert.NotEmpty(t, report.Entries[1].Message)
+	assert.Equal(t, ItemStageAdd, report.Entries[2].Stage)
+	assert.Zero(t, report.Entries[2].ClientHandle)
+	assert.True(t, report.Entries[2].Failed())
+	assert.Equal(t, 1, items.GetCount())
+
+	encoded, err := json.Marshal(report)
+	assert.NoError(t, err)
+	assert.Contains(t, string(encoded), `"stage":"validate"`)
+
+	addedTags = nil
+	_, _, report, err = items.AddItemsWithReport([]string{"Missing"}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Missing"}, addedTags)
+	assert.Equal(t, 1, report.Added)
+
+	var nilItems *OPCItems
+	_, _, _, err = nilItems.AddItemsWithReport([]string{"x"}, false)
+	assert.Error(t, err)
+}
@@ -0,0 +1,107 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// ErrAsyncTimeout is returned when an awaited async operation does not complete in time.
+// The operation has been cancelled with AsyncCancel when this error is returned.
+var ErrAsyncTimeout = errors.New("async operation timed out")
+
+// awaitTransactionBase marks transaction IDs generated for awaited operations. Keeping them in the
+// upper half of the range avoids collisions with IDs chosen by callers of the async methods.
+const awaitTransactionBase = uint32(0x80000000)
+
+// nextAwaitTransactionID returns a transaction ID for an awaited async operation of the group.
+func (g *OPCGroup) nextAwaitTransactionID() uint32 {
+	return atomic.AddUint32(&g.awaitTransID, 1) | awaitTransactionBase
+}
+
+// await registers interest in the completion callbacks of a transaction. Callbacks with the
+// transaction ID are sent to the returned channel in addition to the registered listeners.
+// The caller must call unawait when it stops waiting.
+func (g *OPCGroup) await(transactionID uint32) chan interface{} {
+	g.callbackLock.Lock()
+	defer g.callbackLock.Unlock()
+	return g.awaitLocked(transactionID)
+}
+
+// awaitLocked is await for a caller holding callbackLock.
+func (g *OPCGroup) awaitLocked(transactionID uint32) chan interface{} {
+	ch := make(chan interface{}, 2)
+	if g.awaiters == nil {
+		g.awaiters = make(map[uint32]chan interface{})
+	}
+	g.awaiters[transactionID] = ch
+	return ch
+}
+
+// unawait removes a registration made by await.
+func (g *OPCGroup) unawait(transactionID uint32) {
+	g.callbackLock.Lock()
+	defer g.callbackLock.Unlock()
+	delete(g.awaiters, transactionID)
+}
+
+// awaitValue registers interest in the first value of the item with clientHandle that is not waiting for
+// initial data. The returned channel receives once a data change carries such a value. It returns nil
+// without registering when the data callback of the group is not connected, since no data change would
+// arrive. The caller must call unawaitValue with a non-nil channel when it stops waiting.
+func (g *OPCGroup) awaitValue(clientHandle uint32) chan struct{} {
+	g.callbackLock.Lock()
+	defer g.callbackLock.Unlock()
+	if g.event == nil {
+		return nil
+	}
+	ch := make(chan struct{}, 1)
+	if g.valueWaiters == nil {
+		g.valueWaiters = make(map[chan struct{}]uint32)
+	}
+	g.valueWaiters[ch] = clientHandle
+	return ch
+}
+
+// unawaitValue removes a registration made by awaitValue.
+func (g *OPCGroup) unawaitValue(ch chan struct{}) {
+	g.callbackLock.Lock()
+	defer g.callbackLock.Unlock()
+	delete(g.valueWaiters, ch)
+}
+
+// notifyValueWaiters wakes the waiters of awaitValue whose item has a value in data. It never blocks the
+// callback loop.
+func (g *OPCGroup) notifyValueWaiters(data *DataChangeCallBackData) {
+	g.callbackLock.Lock()
+	defer g.callbackLock.Unlock()
+	for ch, clientHandle := range g.valueWaiters {
+		for i, handle := range data.ItemClientHandles {
+			if handle != clientHandle || i >= len(data.Qualities) ||
+				data.Qualities[i]&OPC_STATUS_MASK == OPC_QUALITY_WAITING_FOR_INITIAL_DATA {
+				continue
+			}
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+			break
+		}
+	}
+}
+
+// notifyAwaiter passes a completion callback to the waiter of its transaction, if any.
+// It never blocks the callback loop.
+func (g *OPCGroup) notifyAwaiter(transactionID uint32, data interface{}) {
+	g.callbackLock.Lock()
+	ch := g.awaiters[transactionID]
+	g.callbackLock.Unlock()
+	if ch == nil {
+		return
+	}
+	select {
+	case ch <- data:
+	default:
+	}
+}
@@ -0,0 +1,92 @@
+//go:build windows
+
+package opcda
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrUnknownCancelID is returned by AsyncCancelAwait when the cancel ID does not belong to an
+	// outstanding async transaction of the group, for example because the transaction already completed.
+	ErrUnknownCancelID = errors.New("unknown or completed async transaction")
+	// ErrCancelTooLate is returned by AsyncCancelAwait when the transaction completed normally
+	// instead of being cancelled.
+	ErrCancelTooLate = errors.New("async transaction completed before it was cancelled")
+)
+
+// AsyncCancelAwait requests that the server cancel an outstanding transaction of the group and waits for
+// the outcome. It returns nil once the CancelComplete callback of the transaction arrives, ErrCancelTooLate
+// if the read, write or refresh completed instead, and the error of ctx if ctx is done first.
+// The cancel ID must come from AsyncRead, AsyncWrite or AsyncRefresh of this group; ErrUnknownCancelID is
+// returned for transactions that are unknown or already complete. The group's callbacks are advised
+// if they are not already, since the outcome is reported through them.
+func (g *OPCGroup) AsyncCancelAwait(ctx context.Context, cancelID uint32) error {
+	if g == nil || g.groupProvider == nil {
+		return errors.New("uninitialized group")
+	}
+	transactionID, ok := g.cancelTransaction(cancelID)
+	if !ok {
+		return ErrUnknownCancelID
+	}
+	var done chan interface{}
+	err := g.adviseAnd(func() { done = g.awaitLocked(transactionID) })
+	if err != nil {
+		return err
+	}
+	defer g.unawait(transactionID)
+	// the transaction may have completed before the waiter was registered
+	if _, ok = g.cancelTransaction(cancelID); !ok {
+		return ErrCancelTooLate
+	}
+	err = g.AsyncCancel(cancelID)
+	if err != nil {
+		return err
+	}
+	for {
+		select {
+		case data := <-done:
+			switch data.(type) {
+			case *CancelCompleteCallBackData:
+				return nil
+			case *ReadCompleteCallBackData, *WriteCompleteCallBackData, *DataChangeCallBackData:
+				return ErrCancelTooLate
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// trackCancel records the transaction ID of an outstanding async transaction under its cancel ID.
+func (g *OPCGroup) trackCancel(cancelID, transactionID uint32) {
+	g.callbackLock.Lock()
+	defer g.callbackLock.Unlock()
+	if g.cancelTransactions == nil {
+		g.cancelTransactions = make(map[uint32]uint32)
+	}
+	g.cancelTransactions[cancelID] = transactionID
+}
+
+// cancelTransaction returns the transaction ID of the outstanding transaction with the cancel ID.
+func (g *OPCGroup) cancelTransaction(cancelID uint32) (uint32, bool) {
+	g.callbackLock.Lock()
+	defer g.callbackLock.Unlock()
+	transactionID, ok := g.cancelTransactions[cancelID]
+	return transactionID, ok
+}
+
+// completeTransaction releases the in-flight permit of a completed async transaction and forgets
+// its cancel ID.
+func (g *OPCGroup) completeTransaction(transactionID uint32) {
+	g.releaseInflight(transactionID)
+	g.callbackLock.Lock()
+	defer g.callbackLock.Unlock()
+	for cancelID, id := range g.cancelTransactions {
+		if id == transactionID {
+			delete(g.cancelTransactions, cancelID)
+			break
+		}
+	}
+}
@@ -0,0 +1,64 @@
+//go:build windows
+
+package opcda
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOPCGroup_AsyncCancelAwait_Mocked(t *testing.T) {
+	group := &OPCGroup{provider: &mockServerProvider{}, event: &DataEventReceiver{}}
+	group.groupProvider = &mockGroupProvider{
+		AsyncReadFn: func(serverHandles []uint32, transactionID uint32) (uint32, []int32, error) {
+			return 40 + transactionID, []int32{0}, nil
+		},
+		AsyncCancelFn: func(cancelID uint32) error {
+			switch cancelID {
+			case 41:
+				go group.fireCancelComplete(&CCancelCompleteCallBackData{TransID: 1})
+			case 42:
+				go group.fireReadComplete(&CReadCompleteCallBackData{TransID: 2})
+			}
+			return nil
+		},
+	}
+
+	cancelID, _, err := group.AsyncRead([]uint32{1}, 1)
+	assert.NoError(t, err)
+	assert.NoError(t, group.AsyncCancelAwait(context.Background(), cancelID))
+	assert.ErrorIs(t, group.AsyncCancelAwait(context.Background(), cancelID), ErrUnknownCancelID)
+
+	cancelID, _, err = group.AsyncRead([]uint32{1}, 2)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, group.AsyncCancelAwait(context.Background(), cancelID), ErrCancelTooLate)
+
+	cancelID, _, err = group.AsyncRead([]uint32{1}, 3)
+	assert.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, group.AsyncCancelAwait(ctx, cancelID), context.DeadlineExceeded)
+	assert.Empty(t, group.awaiters)
+
+	group.event = nil
+	group.Release()
+	assert.ErrorIs(t, group.AsyncCancelAwait(context.Background(), cancelID), ErrUnknownCancelID)
+
+	var nilGroup *OPCGroup
+	assert.Error(t, nilGroup.AsyncCancelAwait(context.Background(), 1))
+}
+
+func TestOPCGroup_AsyncCancelAwait_Rejected_Mocked(t *testing.T) {
+	group := &OPCGroup{provider: &mockServerProvider{}, event: &DataEventReceiver{}}
+	group.groupProvider = &mockGroupProvider{
+		AsyncReadFn: func(serverHandles []uint32, transactionID uint32) (uint32, []int32, error) {
+			return 5, []int32{int32(OPCInvalidHandle)}, nil
+		},
+	}
+	cancelID, _, err := group.AsyncRead([]uint32{1}, 1)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, group.AsyncCancelAwait(context.Background(), cancelID), ErrUnknownCancelID)
+}
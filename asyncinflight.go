@@ -0,0 +1,300 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrTooManyInflight is returned by the group async methods when the server-wide in-flight limit
+// is reached and the server is in InflightFailFast mode.
+var ErrTooManyInflight = errors.New("too many in-flight async operations")
+
+// InflightMode selects what an async call does when the in-flight limit is reached.
+type InflightMode int
+
+const (
+	// InflightBlock waits until a permit becomes available. It is the default.
+	// Permits are returned by the callback loops of the groups as completions arrive, so an async call must
+	// not wait for one on a goroutine a callback loop waits for: the consumer of a channel registered with
+	// DeliveryBlock, for example, stalls the loop of its group, whose completions then free no permit, and
+	// the call hangs until the in-flight timeout reclaims a permit, or forever if the timeout is disabled.
+	// Use InflightFailFast where async calls are issued from such consumers.
+	InflightBlock InflightMode = iota
+	// InflightFailFast returns ErrTooManyInflight immediately.
+	InflightFailFast
+)
+
+// errInflightWaitCancelled is returned by acquireUntil when the wait for a permit is cancelled.
+var errInflightWaitCancelled = errors.New("wait for an in-flight permit cancelled")
+
+// defaultInflightTimeout is how long a permit is held when the server never completes its transaction.
+const defaultInflightTimeout = time.Minute
+
+// AsyncStats reports the in-flight async operations of a server.
+type AsyncStats struct {
+	// Inflight is the number of async transactions waiting for completion.
+	Inflight int
+	// Peak is the highest Inflight value observed.
+	Peak int
+	// Max is the configured limit, or 0 if unlimited.
+	Max int
+	// Waiting is the number of calls blocked waiting for a permit.
+	Waiting int
+	// Rejected counts calls that failed with ErrTooManyInflight.
+	Rejected uint64
+	// TimedOut counts permits reclaimed because no completion arrived in time.
+	TimedOut uint64
+}
+
+// inflightKey identifies an async transaction by the client group handle and transaction ID
+// reported in the completion callback.
+type inflightKey struct {
+	group uint32
+	trans uint32
+}
+
+// asyncLimiter is a server-wide semaphore for async group operations.
+// Permits are taken before an async call and returned when the transaction is completed, cancelled or times out.
+type asyncLimiter struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	max      int
+	mode     InflightMode
+	timeout  time.Duration
+	pending  map[inflightKey][]time.Time
+	inflight int
+	peak     int
+	waiting  int
+	rejected uint64
+	timedOut uint64
+}
+
+// newAsyncLimiter creates an unlimited asyncLimiter.
+func newAsyncLimiter() *asyncLimiter {
+	l := &asyncLimiter{
+		timeout: defaultInflightTimeout,
+		pending: make(map[inflightKey][]time.Time),
+	}
+	l.cond = sync.NewCond(&l.mu)
+	return l
+}
+
+// setMax sets the limit; n <= 0 removes it. Blocked callers are woken to re-check.
+func (l *asyncLimiter) setMax(n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if n < 0 {
+		n = 0
+	}
+	l.max = n
+	l.cond.Broadcast()
+}
+
+// setMode sets what acquire does when the limit is reached.
+func (l *asyncLimiter) setMode(mode InflightMode) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.mode = mode
+	l.cond.Broadcast()
+}
+
+// setTimeout sets how long a permit is held without a completion; d <= 0 never reclaims permits.
+func (l *asyncLimiter) setTimeout(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.timeout = d
+	l.cond.Broadcast()
+}
+
+// acquire takes a permit for the transaction, blocking or failing according to the mode.
+func (l *asyncLimiter) acquire(group, trans uint32) error {
+	return l.acquireUntil(group, trans, nil)
+}
+
+// acquireUntil is acquire with a wait that ends with errInflightWaitCancelled when cancel is closed.
+// A nil cancel waits like acquire.
+func (l *asyncLimiter) acquireUntil(group, trans uint32, cancel <-chan struct{}) error {
+	if cancel != nil {
+		returned := make(chan struct{})
+		defer close(returned)
+		go func() {
+			select {
+			case <-cancel:
+				l.mu.Lock()
+				l.cond.Broadcast()
+				l.mu.Unlock()
+			case <-returned:
+			}
+		}()
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for {
+		l.expireLocked(time.Now())
+		if l.max == 0 || l.inflight < l.max {
+			break
+		}
+		if l.mode == InflightFailFast {
+			l.rejected++
+			return ErrTooManyInflight
+		}
+		select {
+		case <-cancel:
+			return errInflightWaitCancelled
+		default:
+		}
+		l.waiting++
+		timer := l.wakeAtNextExpiryLocked()
+		l.cond.Wait()
+		if timer != nil {
+			timer.Stop()
+		}
+		l.waiting--
+	}
+	key := inflightKey{group: group, trans: trans}
+	l.pending[key] = append(l.pending[key], time.Now())
+	l.inflight++
+	if l.inflight > l.peak {
+		l.peak = l.inflight
+	}
+	return nil
+}
+
+// release returns the oldest permit held for the transaction. Unknown transactions are ignored.
+func (l *asyncLimiter) release(group, trans uint32) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	key := inflightKey{group: group, trans: trans}
+	started := l.pending[key]
+	if len(started) == 0 {
+		return
+	}
+	if len(started) == 1 {
+		delete(l.pending, key)
+	} else {
+		l.pending[key] = started[1:]
+	}
+	l.inflight--
+	l.cond.Broadcast()
+}
+
+// releaseGroup returns every permit held by a group, used when the group goes away.
+func (l *asyncLimiter) releaseGroup(group uint32) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for key, started := range l.pending {
+		if key.group == group {
+			l.inflight -= len(started)
+			delete(l.pending, key)
+		}
+	}
+	l.cond.Broadcast()
+}
+
+// stats returns a snapshot of the limiter state.
+func (l *asyncLimiter) stats() AsyncStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.expireLocked(time.Now())
+	return AsyncStats{
+		Inflight: l.inflight,
+		Peak:     l.peak,
+		Max:      l.max,
+		Waiting:  l.waiting,
+		Rejected: l.rejected,
+		TimedOut: l.timedOut,
+	}
+}
+
+// expireLocked reclaims permits older than the timeout. The caller must hold l.mu.
+func (l *asyncLimiter) expireLocked(now time.Time) {
+	if l.timeout <= 0 {
+		return
+	}
+	expired := 0
+	for key, started := range l.pending {
+		n := 0
+		for n < len(started) && now.Sub(started[n]) >= l.timeout {
+			n++
+		}
+		if n == 0 {
+			continue
+		}
+		expired += n
+		if n == len(started) {
+			delete(l.pending, key)
+		} else {
+			l.pending[key] = started[n:]
+		}
+	}
+	if expired > 0 {
+		l.inflight -= expired
+		l.timedOut += uint64(expired)
+		l.cond.Broadcast()
+	}
+}
+
+// wakeAtNextExpiryLocked schedules a wake-up of blocked callers when the oldest permit times out.
+// The caller must hold l.mu.
+func (l *asyncLimiter) wakeAtNextExpiryLocked() *time.Timer {
+	if l.timeout <= 0 {
+		return nil
+	}
+	var oldest time.Time
+	for _, started := range l.pending {
+		if len(started) > 0 && (oldest.IsZero() || started[0].Before(oldest)) {
+			oldest = started[0]
+		}
+	}
+	if oldest.IsZero() {
+		return nil
+	}
+	return time.AfterFunc(time.Until(oldest.Add(l.timeout)), func() {
+		l.mu.Lock()
+		l.cond.Broadcast()
+		l.mu.Unlock()
+	})
+}
+
+// SetMaxInflightAsync limits the number of async reads, writes and refreshes that may wait for completion
+// across all groups of the server. Permits are returned when the completion or cancel callback arrives,
+// when the call fails, or after the in-flight timeout. n <= 0 removes the limit.
+func (s *OPCServer) SetMaxInflightAsync(n int) error {
+	if s == nil || s.inflight == nil {
+		return errors.New("uninitialized server connection")
+	}
+	s.inflight.setMax(n)
+	return nil
+}
+
+// SetInflightAsyncMode selects whether async calls block (InflightBlock) or fail with ErrTooManyInflight
+// (InflightFailFast) when the limit set by SetMaxInflightAsync is reached. See InflightBlock for why
+// consumers that hold up a callback loop must not issue blocking async calls.
+func (s *OPCServer) SetInflightAsyncMode(mode InflightMode) error {
+	if s == nil || s.inflight == nil {
+		return errors.New("uninitialized server connection")
+	}
+	s.inflight.setMode(mode)
+	return nil
+}
+
+// SetInflightAsyncTimeout sets how long an async transaction may hold a permit without a completion
+// before the permit is reclaimed. The default is one minute; d <= 0 disables the timeout.
+func (s *OPCServer) SetInflightAsyncTimeout(d time.Duration) error {
+	if s == nil || s.inflight == nil {
+		return errors.New("uninitialized server connection")
+	}
+	s.inflight.setTimeout(d)
+	return nil
+}
+
+// GetAsyncStats returns the current in-flight async statistics of the server.
+func (s *OPCServer) GetAsyncStats() AsyncStats {
+	if s == nil || s.inflight == nil {
+		return AsyncStats{}
+	}
+	return s.inflight.stats()
+}
@@ -0,0 +1,155 @@
+//go:build windows
+
+package opcda
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wends155/opcda/com"
+)
+
+func newInflightTestGroup(server *OPCServer, groupProvider groupProvider) *OPCGroup {
+	return &OPCGroup{
+		parent:            server.groups,
+		provider:          server.provider,
+		groupProvider:     groupProvider,
+		serverGroupHandle: 7,
+	}
+}
+
+func TestOPCServer_MaxInflightAsync_FailFast_Mocked(t *testing.T) {
+	server := newOPCServerWithProvider(&mockServerProvider{}, "mock", "localhost")
+	assert.NoError(t, server.SetMaxInflightAsync(2))
+	assert.NoError(t, server.SetInflightAsyncMode(InflightFailFast))
+	group := newInflightTestGroup(server, &mockGroupProvider{
+		AsyncReadFn: func(serverHandles []uint32, transactionID uint32) (uint32, []int32, error) {
+			return transactionID, []int32{0}, nil
+		},
+	})
+
+	_, _, err := group.AsyncRead([]uint32{1}, 1)
+	assert.NoError(t, err)
+	_, _, err = group.AsyncRead([]uint32{1}, 2)
+	assert.NoError(t, err)
+	_, _, err = group.AsyncRead([]uint32{1}, 3)
+	assert.ErrorIs(t, err, ErrTooManyInflight)
+
+	stats := server.GetAsyncStats()
+	assert.Equal(t, 2, stats.Inflight)
+	assert.Equal(t, 2, stats.Max)
+	assert.Equal(t, uint64(1), stats.Rejected)
+
+	group.fireReadComplete(&CReadCompleteCallBackData{TransID: 1})
+	group.fireCancelComplete(&CCancelCompleteCallBackData{TransID: 2})
+	stats = server.GetAsyncStats()
+	assert.Equal(t, 0, stats.Inflight)
+	assert.Equal(t, 2, stats.Peak)
+}
+
+func TestOPCServer_MaxInflightAsync_Block_Mocked(t *testing.T) {
+	server := newOPCServerWithProvider(&mockServerProvider{}, "mock", "localhost")
+	assert.NoError(t, server.SetMaxInflightAsync(1))
+	group := newInflightTestGroup(server, &mockGroupProvider{
+		AsyncRefreshFn: func(source com.OPCDATASOURCE, transactionID uint32) (uint32, error) {
+			return transactionID, nil
+		},
+	})
+
+	_, err := group.AsyncRefresh(OPC_DS_CACHE, 1)
+	assert.NoError(t, err)
+	done := make(chan error, 1)
+	go func() {
+		_, err := group.AsyncRefresh(OPC_DS_CACHE, 2)
+		done <- err
+	}()
+	assert.Eventually(t, func() bool { return server.GetAsyncStats().Waiting == 1 }, time.Second, time.Millisecond)
+
+	group.fireDataChange(context.Background(), &CDataChangeCallBackData{TransID: 1})
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("blocked AsyncRefresh was not released by the completion")
+	}
+	assert.Equal(t, 1, server.GetAsyncStats().Inflight)
+}
+
+func TestOPCServer_MaxInflightAsync_FailFastFromConsumer_Mocked(t *testing.T) {
+	server := newOPCServerWithProvider(&mockServerProvider{}, "mock", "localhost")
+	assert.NoError(t, server.SetMaxInflightAsync(1))
+	assert.NoError(t, server.SetInflightAsyncMode(InflightFailFast))
+	group := newInflightTestGroup(server, &mockGroupProvider{
+		AsyncReadFn: func(serverHandles []uint32, transactionID uint32) (uint32, []int32, error) {
+			return transactionID, []int32{0}, nil
+		},
+	})
+	group.event = &DataEventReceiver{}
+	dataChangeCB := make(chan *CDataChangeCallBackData, 1)
+	readCB := make(chan *CReadCompleteCallBackData, 1)
+	group.callbackLock.Lock()
+	group.startLoop(dataChangeCB, readCB, make(chan *CWriteCompleteCallBackData), make(chan *CCancelCompleteCallBackData))
+	group.callbackLock.Unlock()
+	defer group.stopCallbackLoop()
+	samples := make(chan *DataChangeCallBackData)
+	assert.NoError(t, group.RegisterDataChangeWithPolicy(samples, DeliveryBlock))
+
+	_, _, err := group.AsyncRead([]uint32{1}, 1)
+	assert.NoError(t, err)
+	// the consumer of a blocking subscription fails instead of waiting for a permit its own loop returns
+	dataChangeCB <- &CDataChangeCallBackData{}
+	<-samples
+	_, _, err = group.AsyncRead([]uint32{1}, 2)
+	assert.ErrorIs(t, err, ErrTooManyInflight)
+
+	readCB <- &CReadCompleteCallBackData{TransID: 1}
+	assert.Eventually(t, func() bool { return server.GetAsyncStats().Inflight == 0 }, time.Second, time.Millisecond)
+	_, _, err = group.AsyncRead([]uint32{1}, 2)
+	assert.NoError(t, err)
+}
+
+func TestOPCServer_MaxInflightAsync_RefreshTransactionZero_Mocked(t *testing.T) {
+	server := newOPCServerWithProvider(&mockServerProvider{}, "mock", "localhost")
+	assert.NoError(t, server.SetMaxInflightAsync(1))
+	assert.NoError(t, server.SetInflightAsyncMode(InflightFailFast))
+	group := newInflightTestGroup(server, &mockGroupProvider{
+		AsyncRefreshFn: func(source com.OPCDATASOURCE, transactionID uint32) (uint32, error) {
+			return 5, nil
+		},
+	})
+
+	// a refresh with transaction ID 0 completes like a subscription data change, so it takes no permit
+	for i := 0; i < 3; i++ {
+		_, err := group.AsyncRefresh(OPC_DS_CACHE, 0)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 0, server.GetAsyncStats().Inflight)
+	_, ok := group.cancelTransaction(5)
+	assert.False(t, ok)
+
+	_, err := group.AsyncRefresh(OPC_DS_CACHE, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, server.GetAsyncStats().Inflight)
+}
+
+func TestOPCServer_MaxInflightAsync_ReleaseOnFailureAndTimeout_Mocked(t *testing.T) {
+	server := newOPCServerWithProvider(&mockServerProvider{}, "mock", "localhost")
+	assert.NoError(t, server.SetMaxInflightAsync(1))
+	assert.NoError(t, server.SetInflightAsyncMode(InflightFailFast))
+	group := newInflightTestGroup(server, &mockGroupProvider{
+		AsyncWriteFn: func(serverHandles []uint32, values []com.VARIANT, transactionID uint32) (uint32, []int32, error) {
+			return transactionID, []int32{int32(OPCInvalidHandle)}, nil
+		},
+	})
+
+	_, _, err := group.AsyncWrite([]uint32{1}, []interface{}{int32(1)}, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, server.GetAsyncStats().Inflight)
+
+	assert.NoError(t, server.SetInflightAsyncTimeout(10*time.Millisecond))
+	assert.NoError(t, server.inflight.acquire(group.serverGroupHandle, 2))
+	assert.Eventually(t, func() bool { return server.GetAsyncStats().Inflight == 0 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, uint64(1), server.GetAsyncStats().TimedOut)
+}
@@ -0,0 +1,145 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"github.com/wends155/opcda/com"
+)
+
+// ErrBrowseNotSupported is returned by BrowseFlat when the server does not implement the OPC DA 3.0
+// IOPCBrowse interface. Such servers can be browsed with OPCBrowser instead.
+var ErrBrowseNotSupported = errors.New("server does not support IOPCBrowse")
+
+// browse3Provider defines the internal contract for stateless browsing.
+// It abstracts the optional IOPCBrowse server interface to allow for mocking and testing.
+type browse3Provider interface {
+	// Browse returns the elements below a branch.
+	Browse(itemID string, options com.BrowseOptions) ([]com.BrowseElement, error)
+	// Release releases the COM resources associated with the provider.
+	Release()
+}
+
+// comBrowse3Provider is the concrete implementation of browse3Provider using COM.
+type comBrowse3Provider struct {
+	browse *com.IOPCBrowse
+}
+
+// Browse returns the elements below a branch.
+func (p *comBrowse3Provider) Browse(itemID string, options com.BrowseOptions) ([]com.BrowseElement, error) {
+	return p.browse.Browse(itemID, options)
+}
+
+// Release releases the COM resources associated with the provider.
+func (p *comBrowse3Provider) Release() {
+	p.browse.Release()
+}
+
+// queryComBrowse3 queries the server for IOPCBrowse and applies authInfo to the new proxy.
+func queryComBrowse3(provider serverProvider, authInfo *com.COAUTHINFO) (browse3Provider, error) {
+	var iUnknown *com.IUnknown
+	err := provider.QueryInterface(&com.IID_IOPCBrowse, unsafe.Pointer(&iUnknown))
+	if err != nil {
+		if errors.Is(err, com.HRESULT(com.E_NOINTERFACE)) {
+			return nil, fmt.Errorf("%w: %w", ErrBrowseNotSupported, err)
+		}
+		return nil, NewOPCWrapperError("query interface IOPCBrowse", err)
+	}
+	err = setProxyBlanket(iUnknown, authInfo)
+	if err != nil {
+		iUnknown.Release()
+		return nil, NewOPCWrapperError("set proxy blanket IOPCBrowse", err)
+	}
+	return &comBrowse3Provider{browse: &com.IOPCBrowse{IUnknown: iUnknown}}, nil
+}
+
+// BrowseProperty is a property of an element returned by BrowseFlat.
+type BrowseProperty struct {
+	// ID identifies the property.
+	ID PropertyID
+	// DataType is the data type of the property value.
+	DataType com.VT
+	// Description is the description of the property.
+	Description string
+	// Err is the error the server reported for the property.
+	Err error
+}
+
+// BrowseElement is an element of the server address space returned by BrowseFlat.
+type BrowseElement struct {
+	// Name is the short name of the element.
+	Name string
+	// ItemID is the fully qualified item ID of the element.
+	ItemID string
+	// IsItem reports whether the element is an item that can be added to a group.
+	IsItem bool
+	// HasChildren reports whether the element is a branch with children.
+	HasChildren bool
+	// Properties are the properties available on the element.
+	Properties []BrowseProperty
+	// Err is the error the server reported for the properties of the element.
+	Err error
+}
+
+// BrowseFlat returns the elements directly below the branch itemID, or below the root if itemID is empty,
+// with the properties available on each, using the stateless OPC DA 3.0 IOPCBrowse interface. filter is a
+// wildcard pattern the element names must match; an empty filter returns every element.
+// Unlike OPCBrowser it does not change any browse position, so it can be called concurrently.
+// Servers that only implement OPC DA 2.0 return an error wrapping ErrBrowseNotSupported.
+//
+// Example:
+//
+//	elements, err := server.BrowseFlat("Simulation Items.Random", "*")
+//	if errors.Is(err, opcda.ErrBrowseNotSupported) {
+//		// fall back to OPCBrowser
+//	}
+func (s *OPCServer) BrowseFlat(itemID string, filter string) ([]BrowseElement, error) {
+	if s == nil || s.provider == nil {
+		return nil, errors.New("uninitialized server connection")
+	}
+	browse, err := s.browse3()
+	if err != nil {
+		return nil, err
+	}
+	defer browse.Release()
+	found, err := browse.Browse(itemID, com.BrowseOptions{
+		Filter:              com.OPC_BROWSE_FILTER_ALL,
+		ElementNameFilter:   filter,
+		ReturnAllProperties: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	elements := make([]BrowseElement, len(found))
+	for i, e := range found {
+		elements[i] = BrowseElement{
+			Name:        e.Name,
+			ItemID:      e.ItemID,
+			IsItem:      e.Flags&com.OPC_BROWSE_ISITEM != 0,
+			HasChildren: e.Flags&com.OPC_BROWSE_HASCHILDREN != 0,
+		}
+		if e.PropertiesError < 0 {
+			elements[i].Err = s.errors([]int32{e.PropertiesError})[0]
+		}
+		if len(e.Properties) > 0 {
+			codes := make([]int32, len(e.Properties))
+			for j, p := range e.Properties {
+				codes[j] = p.Error
+			}
+			errs := s.errors(codes)
+			elements[i].Properties = make([]BrowseProperty, len(e.Properties))
+			for j, p := range e.Properties {
+				elements[i].Properties[j] = BrowseProperty{
+					ID:          PropertyID(p.PropertyID),
+					DataType:    p.DataType,
+					Description: p.Description,
+					Err:         errs[j],
+				}
+			}
+		}
+	}
+	return elements, nil
+}
@@ -0,0 +1,68 @@
+//go:build windows
+
+package opcda
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wends155/opcda/com"
+	"golang.org/x/sys/windows"
+)
+
+func TestOPCServer_BrowseFlat_Mocked(t *testing.T) {
+	released := 0
+	server := newOPCServerWithProvider(&mockServerProvider{}, "mock", "localhost")
+	server.factories.queryBrowse3 = func(serverProvider, *com.COAUTHINFO) (browse3Provider, error) {
+		return &mockBrowse3Provider{
+			BrowseFn: func(itemID string, options com.BrowseOptions) ([]com.BrowseElement, error) {
+				assert.Equal(t, "Random", itemID)
+				assert.Equal(t, "Int*", options.ElementNameFilter)
+				assert.True(t, options.ReturnAllProperties)
+				return []com.BrowseElement{
+					{
+						Name:   "Int4",
+						ItemID: "Random.Int4",
+						Flags:  com.OPC_BROWSE_ISITEM,
+						Properties: []com.ItemProperty{
+							{PropertyID: uint32(OPC_PROPERTY_DATATYPE), DataType: com.VT_I2, Description: "Item Canonical DataType"},
+							{PropertyID: uint32(OPC_PROPERTY_EU_UNITS), Error: int32(OPCInvalidPID)},
+						},
+					},
+					{Name: "Int", ItemID: "Random.Int", Flags: com.OPC_BROWSE_HASCHILDREN, PropertiesError: int32(OPCUnknownItemID)},
+				}, nil
+			},
+			ReleaseFn: func() { released++ },
+		}, nil
+	}
+
+	elements, err := server.BrowseFlat("Random", "Int*")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, released)
+	assert.Len(t, elements, 2)
+	assert.True(t, elements[0].IsItem)
+	assert.False(t, elements[0].HasChildren)
+	assert.Equal(t, BrowseProperty{ID: OPC_PROPERTY_DATATYPE, DataType: com.VT_I2, Description: "Item Canonical DataType"}, elements[0].Properties[0])
+	assert.Error(t, elements[0].Properties[1].Err)
+	assert.False(t, elements[1].IsItem)
+	assert.True(t, elements[1].HasChildren)
+	assert.Error(t, elements[1].Err)
+}
+
+func TestOPCServer_BrowseFlat_NotSupported_Mocked(t *testing.T) {
+	server := newOPCServerWithProvider(&mockServerProvider{
+		QueryInterfaceFn: func(iid *windows.GUID, ppv unsafe.Pointer) error {
+			assert.Equal(t, com.IID_IOPCBrowse, *iid)
+			return com.HRESULT(com.E_NOINTERFACE)
+		},
+	}, "mock", "localhost")
+
+	_, err := server.BrowseFlat("", "")
+	assert.ErrorIs(t, err, ErrBrowseNotSupported)
+	assert.ErrorIs(t, err, com.HRESULT(com.E_NOINTERFACE))
+
+	var nilServer *OPCServer
+	_, err = nilServer.BrowseFlat("", "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,229 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"github.com/wends155/opcda/com"
+	"golang.org/x/sys/windows"
+)
+
+// ErrForceUnadviseNotAllowed is returned by ForceUnadviseAll unless SetAllowForceUnadvise enabled it.
+var ErrForceUnadviseNotAllowed = errors.New("force unadvise is not allowed on the group")
+
+// ConnectionInfo describes a callback connection of a group on the server.
+type ConnectionInfo struct {
+	// Interface is the IID of the callback interface of the connection point, such as IID_IOPCDataCallback.
+	Interface windows.GUID
+	// Cookie identifies the connection on its connection point.
+	Cookie uint32
+	// Own reports whether the connection is the data callback of this OPCGroup.
+	Own bool
+}
+
+// dataCallbackPoint is the connection point the data callback of a group is advised on.
+// It abstracts IConnectionPoint to allow for mocking and testing.
+type dataCallbackPoint interface {
+	// Unadvise disconnects the data callback.
+	Unadvise(cookie uint32) error
+	// Release releases the COM resources associated with the connection point.
+	Release()
+}
+
+// comDataCallbackPoint is the concrete implementation of dataCallbackPoint using COM.
+type comDataCallbackPoint struct {
+	container *com.IConnectionPointContainer
+	point     *com.IConnectionPoint
+}
+
+// Unadvise disconnects the data callback.
+func (p *comDataCallbackPoint) Unadvise(cookie uint32) error {
+	return p.point.Unadvise(cookie)
+}
+
+// Release releases the connection point and its container.
+func (p *comDataCallbackPoint) Release() {
+	p.point.Release()
+	p.container.Release()
+}
+
+// connectionPointsProvider defines the internal contract for inspecting the callback connections of a group.
+// It abstracts IConnectionPointContainer and the enumerators of its connection points to allow for mocking and testing.
+type connectionPointsProvider interface {
+	// Connections lists the connections of every connection point.
+	Connections() ([]ConnectionInfo, error)
+	// Unadvise disconnects a connection of the connection point of the interface.
+	Unadvise(iid windows.GUID, cookie uint32) error
+	// Release releases the COM resources associated with the provider.
+	Release()
+}
+
+// comConnectionPoints is the concrete implementation of connectionPointsProvider using COM.
+type comConnectionPoints struct {
+	container *com.IConnectionPointContainer
+	points    []*com.IConnectionPoint
+	iids      []windows.GUID
+}
+
+// openComConnectionPoints enumerates the connection points of the group and applies authInfo to their proxies.
+func openComConnectionPoints(provider groupProvider, authInfo *com.COAUTHINFO) (connectionPointsProvider, error) {
+	var iUnknown *com.IUnknown
+	err := provider.QueryInterface(&com.IID_IConnectionPointContainer, unsafe.Pointer(&iUnknown))
+	if err != nil {
+		return nil, NewOPCWrapperError("query interface IConnectionPointContainer", err)
+	}
+	p := &comConnectionPoints{container: &com.IConnectionPointContainer{IUnknown: iUnknown}}
+	err = setProxyBlanket(iUnknown, authInfo)
+	if err != nil {
+		p.Release()
+		return nil, NewOPCWrapperError("set proxy blanket IConnectionPointContainer", err)
+	}
+	p.points, err = p.container.EnumConnectionPoints()
+	if err != nil {
+		p.Release()
+		return nil, NewOPCWrapperError("enum connection points", err)
+	}
+	for _, point := range p.points {
+		err = setProxyBlanket(point.IUnknown, authInfo)
+		if err != nil {
+			p.Release()
+			return nil, NewOPCWrapperError("set proxy blanket IConnectionPoint", err)
+		}
+		iid, err := point.GetConnectionInterface()
+		if err != nil {
+			p.Release()
+			return nil, NewOPCWrapperError("get connection interface", err)
+		}
+		p.iids = append(p.iids, iid)
+	}
+	return p, nil
+}
+
+// Connections lists the connections of every connection point.
+func (p *comConnectionPoints) Connections() ([]ConnectionInfo, error) {
+	var infos []ConnectionInfo
+	for i, point := range p.points {
+		cookies, err := point.EnumConnections()
+		if err != nil {
+			return nil, NewOPCWrapperError("enum connections", err)
+		}
+		for _, cookie := range cookies {
+			infos = append(infos, ConnectionInfo{Interface: p.iids[i], Cookie: cookie})
+		}
+	}
+	return infos, nil
+}
+
+// Unadvise disconnects a connection of the connection point of the interface.
+func (p *comConnectionPoints) Unadvise(iid windows.GUID, cookie uint32) error {
+	for i, point := range p.points {
+		if p.iids[i] == iid {
+			return point.Unadvise(cookie)
+		}
+	}
+	return fmt.Errorf("no connection point for %s", iid)
+}
+
+// Release releases the COM resources associated with the provider.
+func (p *comConnectionPoints) Release() {
+	for _, point := range p.points {
+		point.Release()
+	}
+	p.container.Release()
+}
+
+// SetAllowForceUnadvise enables ForceUnadviseAll on the group, and lets registering a callback disconnect
+// the other connections of the group when the server refuses it with CONNECT_E_ADVISELIMIT. This is a
+// dangerous operation: the server cannot tell stale connections left by a crashed client from the live
+// connections of other clients sharing the group, such as public groups, and disconnects both.
+func (g *OPCGroup) SetAllowForceUnadvise(allow bool) {
+	if g == nil {
+		return
+	}
+	g.allowForceUnadvise.Store(allow)
+}
+
+// ListCallbackConnections returns the callback connections the server holds for the group on all of its
+// connection points, including the data callback of this OPCGroup, which has Own set. Stale connections
+// left behind by clients that terminated abnormally show up here. Servers that do not implement the
+// enumeration of connections return an error.
+func (g *OPCGroup) ListCallbackConnections() ([]ConnectionInfo, error) {
+	if g == nil || g.groupProvider == nil {
+		return nil, errors.New("uninitialized group")
+	}
+	g.callbackLock.Lock()
+	defer g.callbackLock.Unlock()
+	points, err := g.connectionPoints()
+	if err != nil {
+		return nil, err
+	}
+	defer points.Release()
+	infos, err := points.Connections()
+	if err != nil {
+		return nil, err
+	}
+	for i := range infos {
+		infos[i].Own = g.isOwnConnection(infos[i])
+	}
+	return infos, nil
+}
+
+// ForceUnadviseAll disconnects every callback connection of the group on the server except the data
+// callback of this OPCGroup, and returns how many were disconnected. It clears stale connections that
+// make the server refuse new ones with CONNECT_E_ADVISELIMIT, replacing a manual cleanup on the server.
+// Because it also disconnects other clients of the group, it returns ErrForceUnadviseNotAllowed unless
+// SetAllowForceUnadvise enabled it. Errors disconnecting individual connections are joined.
+//
+// Example:
+//
+//	group.SetAllowForceUnadvise(true)
+//	if n, err := group.ForceUnadviseAll(); err == nil {
+//		log.Printf("removed %d stale connections", n)
+//	}
+func (g *OPCGroup) ForceUnadviseAll() (int, error) {
+	if g == nil || g.groupProvider == nil {
+		return 0, errors.New("uninitialized group")
+	}
+	if !g.allowForceUnadvise.Load() {
+		return 0, ErrForceUnadviseNotAllowed
+	}
+	g.callbackLock.Lock()
+	defer g.callbackLock.Unlock()
+	return g.forceUnadvise()
+}
+
+// forceUnadvise disconnects the callback connections of the group that are not its own.
+// The caller must hold callbackLock.
+func (g *OPCGroup) forceUnadvise() (int, error) {
+	points, err := g.connectionPoints()
+	if err != nil {
+		return 0, err
+	}
+	defer points.Release()
+	infos, err := points.Connections()
+	if err != nil {
+		return 0, err
+	}
+	var errs []error
+	removed := 0
+	for _, info := range infos {
+		if g.isOwnConnection(info) {
+			continue
+		}
+		err = points.Unadvise(info.Interface, info.Cookie)
+		if err != nil && !errors.Is(err, com.HRESULT(com.CONNECT_E_NOCONNECTION)) {
+			errs = append(errs, fmt.Errorf("unadvise connection %d: %w", info.Cookie, err))
+			continue
+		}
+		removed++
+	}
+	return removed, errors.Join(errs...)
+}
+
+// isOwnConnection reports whether the connection is the data callback of the group. The caller must hold callbackLock.
+func (g *OPCGroup) isOwnConnection(info ConnectionInfo) bool {
+	return g.event != nil && info.Interface == IID_IOPCDataCallback && info.Cookie == g.cookie
+}
@@ -0,0 +1,66 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wends155/opcda/com"
+	"golang.org/x/sys/windows"
+)
+
+func TestOPCGroup_CallbackConnections_Mocked(t *testing.T) {
+	shutdownIID := windows.GUID{Data1: 1}
+	var unadvised []uint32
+	released := 0
+	points := &mockConnectionPointsProvider{
+		ConnectionsFn: func() ([]ConnectionInfo, error) {
+			return []ConnectionInfo{
+				{Interface: IID_IOPCDataCallback, Cookie: 1},
+				{Interface: IID_IOPCDataCallback, Cookie: 2},
+				{Interface: shutdownIID, Cookie: 3},
+				{Interface: IID_IOPCDataCallback, Cookie: 4},
+			}, nil
+		},
+		UnadviseFn: func(iid windows.GUID, cookie uint32) error {
+			unadvised = append(unadvised, cookie)
+			switch cookie {
+			case 3:
+				return com.HRESULT(com.CONNECT_E_NOCONNECTION)
+			case 4:
+				return errors.New("refused")
+			}
+			return nil
+		},
+		ReleaseFn: func() { released++ },
+	}
+	server := newOPCServerWithProvider(&mockServerProvider{}, "mock", "localhost")
+	server.factories.openConnectionPoints = func(groupProvider, *com.COAUTHINFO) (connectionPointsProvider, error) {
+		return points, nil
+	}
+	group := newInflightTestGroup(server, &mockGroupProvider{})
+	group.event = &DataEventReceiver{}
+	group.cookie = 2
+
+	infos, err := group.ListCallbackConnections()
+	assert.NoError(t, err)
+	assert.Len(t, infos, 4)
+	assert.False(t, infos[0].Own)
+	assert.True(t, infos[1].Own)
+	assert.False(t, infos[2].Own)
+	assert.Equal(t, 1, released)
+
+	_, err = group.ForceUnadviseAll()
+	assert.ErrorIs(t, err, ErrForceUnadviseNotAllowed)
+	assert.Empty(t, unadvised)
+
+	group.SetAllowForceUnadvise(true)
+	removed, err := group.ForceUnadviseAll()
+	assert.ErrorContains(t, err, "refused")
+	assert.Equal(t, 2, removed)
+	assert.Equal(t, []uint32{1, 3, 4}, unadvised)
+	assert.Equal(t, 2, released)
+	group.event = nil
+}
@@ -0,0 +1,99 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"time"
+)
+
+// ItemUpdate is the value, quality, timestamp and error reported for one item in a callback.
+type ItemUpdate struct {
+	Value     interface{}
+	Quality   uint16
+	Timestamp time.Time
+	Err       error
+}
+
+// ResolveClientHandle returns the item of the group with the client handle, as reported in the
+// ItemClientHandles of the data callbacks.
+func (g *OPCGroup) ResolveClientHandle(handle uint32) (*OPCItem, bool) {
+	if g == nil || g.items == nil {
+		return nil, false
+	}
+	return g.items.itemByClientHandle(handle)
+}
+
+// HandleResolver maps a client handle to the item ID it stands for, reporting whether it knows the handle.
+type HandleResolver func(clientHandle uint32) (tag string, ok bool)
+
+// SetHandleResolver installs a resolver consulted by ResolveTag before the items of the group. It lets
+// callbacks of groups whose items were not added through this package, such as public groups or groups
+// shared with another client, be annotated with item IDs. A nil resolver removes it. The resolver may be
+// swapped at any time and is called on the goroutine that resolves the handles.
+func (g *OPCGroup) SetHandleResolver(resolver HandleResolver) error {
+	if g == nil {
+		return errors.New("uninitialized group")
+	}
+	if resolver == nil {
+		g.handleResolver.Store(nil)
+		return nil
+	}
+	g.handleResolver.Store(&resolver)
+	return nil
+}
+
+// ResolveTag returns the item ID of a client handle reported in a callback. The resolver installed with
+// SetHandleResolver is asked first; handles it does not know are looked up among the items of the group.
+func (g *OPCGroup) ResolveTag(clientHandle uint32) (string, bool) {
+	if g == nil {
+		return "", false
+	}
+	if resolver := g.handleResolver.Load(); resolver != nil {
+		if tag, ok := (*resolver)(clientHandle); ok {
+			return tag, true
+		}
+	}
+	item, ok := g.ResolveClientHandle(clientHandle)
+	if !ok {
+		return "", false
+	}
+	return item.GetItemID(), true
+}
+
+// ResolveDataChange maps the entries of a data change callback to the items of the group.
+// Entries whose client handle does not belong to an item of the group, for example because the item
+// was removed while the callback was queued, are left out.
+//
+// Example:
+//
+//	for item, update := range group.ResolveDataChange(data) {
+//		fmt.Println(item.GetItemID(), update.Value, update.Quality)
+//	}
+func (g *OPCGroup) ResolveDataChange(data *DataChangeCallBackData) map[*OPCItem]ItemUpdate {
+	if data == nil {
+		return nil
+	}
+	updates := make(map[*OPCItem]ItemUpdate, len(data.ItemClientHandles))
+	for i, handle := range data.ItemClientHandles {
+		item, ok := g.ResolveClientHandle(handle)
+		if !ok {
+			continue
+		}
+		var update ItemUpdate
+		if i < len(data.Values) {
+			update.Value = data.Values[i]
+		}
+		if i < len(data.Qualities) {
+			update.Quality = data.Qualities[i]
+		}
+		if i < len(data.TimeStamps) {
+			update.Timestamp = data.TimeStamps[i]
+		}
+		if i < len(data.Errors) {
+			update.Err = data.Errors[i]
+		}
+		updates[item] = update
+	}
+	return updates
+}
@@ -0,0 +1,141 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wends155/opcda/com"
+)
+
+func TestOPCGroup_ResolveClientHandle_Mocked(t *testing.T) {
+	group := &OPCGroup{groupProvider: &mockGroupProvider{}}
+	group.items = NewOPCItems(group, &mockItemMgtProvider{
+		AddItemsFn: func(defs []com.TagOPCITEMDEF) ([]com.TagOPCITEMRESULTStruct, []int32, error) {
+			results := make([]com.TagOPCITEMRESULTStruct, len(defs))
+			for i := range results {
+				results[i].Server = uint32(100 + i)
+			}
+			return results, make([]int32, len(defs)), nil
+		},
+	}, &mockServerProvider{})
+	added, _, err := group.items.AddItemsWithOptions([]ItemDef{{Tag: "a", ClientHandle: 10}, {Tag: "b", ClientHandle: 20}})
+	assert.NoError(t, err)
+
+	item, ok := group.ResolveClientHandle(10)
+	assert.True(t, ok)
+	assert.Same(t, added[0], item)
+	_, ok = group.ResolveClientHandle(30)
+	assert.False(t, ok)
+
+	assert.NoError(t, added[1].SetClientHandle(30))
+	_, ok = group.ResolveClientHandle(20)
+	assert.False(t, ok)
+	item, ok = group.ResolveClientHandle(30)
+	assert.True(t, ok)
+	assert.Same(t, added[1], item)
+
+	group.items.Remove([]uint32{100})
+	_, ok = group.ResolveClientHandle(10)
+	assert.False(t, ok)
+
+	var nilGroup *OPCGroup
+	_, ok = nilGroup.ResolveClientHandle(10)
+	assert.False(t, ok)
+}
+
+func TestOPCItem_SetClientHandle_Duplicate_Mocked(t *testing.T) {
+	var set [][]uint32
+	group := &OPCGroup{groupProvider: &mockGroupProvider{}}
+	group.items = NewOPCItems(group, &mockItemMgtProvider{
+		AddItemsFn: func(defs []com.TagOPCITEMDEF) ([]com.TagOPCITEMRESULTStruct, []int32, error) {
+			results := make([]com.TagOPCITEMRESULTStruct, len(defs))
+			for i := range results {
+				results[i].Server = uint32(100 + i)
+			}
+			return results, make([]int32, len(defs)), nil
+		},
+		SetClientHandlesFn: func(serverHandles []uint32, clientHandles []uint32) ([]int32, error) {
+			set = append(set, clientHandles)
+			return make([]int32, len(serverHandles)), nil
+		},
+	}, &mockServerProvider{})
+	added, _, err := group.items.AddItemsWithOptions([]ItemDef{{Tag: "a", ClientHandle: 10}, {Tag: "b", ClientHandle: 20}})
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, added[1].SetClientHandle(10), ErrDuplicateClientHandle)
+	assert.Equal(t, uint32(20), added[1].GetClientHandle())
+	assert.NoError(t, added[1].SetClientHandle(20))
+	errs := group.items.SetClientHandles([]uint32{100, 101}, []uint32{30, 30})
+	assert.NoError(t, errs[0])
+	assert.ErrorIs(t, errs[1], ErrDuplicateClientHandle)
+	assert.Equal(t, [][]uint32{{20}, {30}}, set)
+
+	item, ok := group.ResolveClientHandle(30)
+	assert.True(t, ok)
+	assert.Same(t, added[0], item)
+	item, ok = group.ResolveClientHandle(20)
+	assert.True(t, ok)
+	assert.Same(t, added[1], item)
+	_, ok = group.ResolveClientHandle(10)
+	assert.False(t, ok)
+}
+
+func TestOPCGroup_ResolveDataChange_Mocked(t *testing.T) {
+	group := &OPCGroup{groupProvider: &mockGroupProvider{}}
+	group.items = NewOPCItems(group, &mockItemMgtProvider{}, &mockServerProvider{})
+	added, _, err := group.items.AddItemsWithOptions([]ItemDef{{Tag: "a", ClientHandle: 1}, {Tag: "b", ClientHandle: 2}})
+	assert.NoError(t, err)
+
+	now := time.Now()
+	itemErr := errors.New("bad item")
+	updates := group.ResolveDataChange(&DataChangeCallBackData{
+		ItemClientHandles: []uint32{2, 99, 1},
+		Values:            []interface{}{int32(5), "unknown", nil},
+		Qualities:         []uint16{OPC_QUALITY_GOOD, OPC_QUALITY_GOOD, OPC_QUALITY_BAD},
+		TimeStamps:        []time.Time{now, now, now},
+		Errors:            []error{nil, nil, itemErr},
+	})
+	assert.Len(t, updates, 2)
+	assert.Equal(t, ItemUpdate{Value: int32(5), Quality: OPC_QUALITY_GOOD, Timestamp: now}, updates[added[1]])
+	assert.Equal(t, ItemUpdate{Quality: OPC_QUALITY_BAD, Timestamp: now, Err: itemErr}, updates[added[0]])
+	assert.Nil(t, group.ResolveDataChange(nil))
+}
+
+func TestOPCGroup_ResolveTag_Mocked(t *testing.T) {
+	group := &OPCGroup{groupProvider: &mockGroupProvider{}}
+	group.items = NewOPCItems(group, &mockItemMgtProvider{}, &mockServerProvider{})
+	_, _, err := group.items.AddItemsWithOptions([]ItemDef{{Tag: "Random.Int4", ClientHandle: 1}})
+	assert.NoError(t, err)
+
+	tag, ok := group.ResolveTag(1)
+	assert.True(t, ok)
+	assert.Equal(t, "Random.Int4", tag)
+	_, ok = group.ResolveTag(2)
+	assert.False(t, ok)
+
+	assert.NoError(t, group.SetHandleResolver(func(clientHandle uint32) (string, bool) {
+		if clientHandle == 2 {
+			return "External.Tag", true
+		}
+		return "", false
+	}))
+	tag, ok = group.ResolveTag(2)
+	assert.True(t, ok)
+	assert.Equal(t, "External.Tag", tag)
+	tag, ok = group.ResolveTag(1)
+	assert.True(t, ok)
+	assert.Equal(t, "Random.Int4", tag)
+
+	assert.NoError(t, group.SetHandleResolver(nil))
+	_, ok = group.ResolveTag(2)
+	assert.False(t, ok)
+
+	var nilGroup *OPCGroup
+	assert.Error(t, nilGroup.SetHandleResolver(nil))
+	_, ok = nilGroup.ResolveTag(1)
+	assert.False(t, ok)
+}
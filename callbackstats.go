@@ -0,0 +1,29 @@
+//go:build windows
+
+package opcda
+
+// CallbackStats counts the callback events of a group that were not delivered to a registered channel.
+// An event dropped for several channels is counted once per channel.
+type CallbackStats struct {
+	// DataChangeDropped counts data change events dropped because a channel was full, including the
+	// queued events evicted under DeliveryDropOldest and the events abandoned under DeliveryBlock when
+	// the group was released.
+	DataChangeDropped uint64
+	// ReadCompleteDropped counts read complete events dropped because a channel was full.
+	ReadCompleteDropped uint64
+	// WriteCompleteDropped counts write complete events dropped because a channel was full.
+	WriteCompleteDropped uint64
+}
+
+// CallbackStats returns the number of callback events dropped since the group was created, so
+// consumers that cannot keep up can be detected.
+func (g *OPCGroup) CallbackStats() CallbackStats {
+	if g == nil {
+		return CallbackStats{}
+	}
+	return CallbackStats{
+		DataChangeDropped:    g.dataChangeDropped.Load(),
+		ReadCompleteDropped:  g.readCompleteDropped.Load(),
+		WriteCompleteDropped: g.writeCompleteDropped.Load(),
+	}
+}
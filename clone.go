@@ -0,0 +1,61 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Clone opens a second, independent connection to the same server with the same configuration: the
+// ProgID or CLSID, node and credentials of the connection, its client name, quirks, the in-flight async
+// limits and the group defaults. The clone has no groups and shares no COM interfaces with s, so either
+// connection can be used concurrently and disconnected without affecting the other. The clone of a
+// server connected through a PinnedRuntime uses the same runtime.
+//
+// Example:
+//
+//	reads, err := server.Clone()
+//	if err == nil {
+//		defer reads.Disconnect()
+//	}
+func (s *OPCServer) Clone() (*OPCServer, error) {
+	if s == nil || s.provider == nil {
+		return nil, errors.New("uninitialized server connection")
+	}
+	clone, err := s.connectAgain()
+	if err != nil {
+		return nil, err
+	}
+	s.quirksLock.Lock()
+	quirks := s.quirks
+	s.quirksLock.Unlock()
+	clone.SetQuirks(quirks)
+	if s.clientName != "" {
+		err = clone.SetClientName(s.clientName)
+		if err != nil {
+			clone.Disconnect()
+			return nil, fmt.Errorf("set client name: %w", err)
+		}
+	}
+	if s.inflight != nil && clone.inflight != nil {
+		s.inflight.mu.Lock()
+		limit, mode, timeout := s.inflight.max, s.inflight.mode, s.inflight.timeout
+		s.inflight.mu.Unlock()
+		clone.inflight.setMax(limit)
+		clone.inflight.setMode(mode)
+		clone.inflight.setTimeout(timeout)
+	}
+	if s.groups != nil && clone.groups != nil {
+		s.groups.RLock()
+		clone.groups.defaultActive = s.groups.defaultActive
+		clone.groups.defaultGroupUpdateRate = s.groups.defaultGroupUpdateRate
+		clone.groups.defaultDeadband = s.groups.defaultDeadband
+		clone.groups.defaultLocaleID = s.groups.defaultLocaleID
+		clone.groups.defaultGroupTimeBias = s.groups.defaultGroupTimeBias
+		clone.groups.defaultDeadbandSet = s.groups.defaultDeadbandSet
+		clone.groups.defaultTimeBiasSet = s.groups.defaultTimeBiasSet
+		s.groups.RUnlock()
+	}
+	return clone, nil
+}
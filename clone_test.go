@@ -0,0 +1,81 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wends155/opcda/com"
+)
+
+func TestOPCServer_Clone_Mocked(t *testing.T) {
+	authInfo := com.NewCOAUTHINFO("operator", "PLANT", "secret")
+	originalReleased := false
+	original := newOPCServerWithProvider(&mockServerProvider{ReleaseFn: func() { originalReleased = true }}, "Vendor.Server.1", "plant-pc")
+	original.authInfo = authInfo
+	assert.NoError(t, original.SetClientName("trending"))
+	assert.NoError(t, original.SetMaxInflightAsync(4))
+	assert.NoError(t, original.SetInflightAsyncMode(InflightFailFast))
+	assert.NoError(t, original.SetInflightAsyncTimeout(5*time.Second))
+	original.GetOPCGroups().SetDefaultGroupUpdateRate(250)
+	original.GetOPCGroups().SetDefaultGroupDeadband(1.5)
+	quirks := Quirks{DeactivateForSetDatatypes: true, RetryPropertiesIndividually: true}
+	assert.NoError(t, original.SetQuirks(quirks))
+
+	var clientName string
+	cloneProvider := &mockServerProvider{
+		SetClientNameFn: func(name string) error {
+			clientName = name
+			return nil
+		},
+	}
+	original.factories.connect = func(progID, node string, info *com.COAUTHINFO) (*OPCServer, error) {
+		assert.Equal(t, "Vendor.Server.1", progID)
+		assert.Equal(t, "plant-pc", node)
+		assert.Same(t, authInfo, info)
+		return newOPCServerWithProvider(cloneProvider, progID, node), nil
+	}
+
+	clone, err := original.Clone()
+	assert.NoError(t, err)
+	assert.NotSame(t, original, clone)
+	assert.Same(t, cloneProvider, clone.provider)
+	assert.NotSame(t, original.groups, clone.groups)
+	assert.Equal(t, "trending", clientName)
+	stats := clone.GetAsyncStats()
+	assert.Equal(t, 4, stats.Max)
+	assert.Equal(t, uint32(250), clone.GetOPCGroups().GetDefaultGroupUpdateRate())
+	assert.Equal(t, float32(1.5), clone.GetOPCGroups().GetDefaultGroupDeadband())
+	assert.Equal(t, quirks, clone.GetQuirks())
+
+	assert.NoError(t, clone.Disconnect())
+	assert.False(t, originalReleased)
+}
+
+func TestOPCServer_Clone_Errors_Mocked(t *testing.T) {
+	var nilServer *OPCServer
+	_, err := nilServer.Clone()
+	assert.Error(t, err)
+
+	original := newOPCServerWithProvider(&mockServerProvider{}, "Vendor.Server.1", "localhost")
+	original.factories.connect = func(progID, node string, info *com.COAUTHINFO) (*OPCServer, error) {
+		return nil, errors.New("server unavailable")
+	}
+	_, err = original.Clone()
+	assert.EqualError(t, err, "server unavailable")
+
+	released := false
+	assert.NoError(t, original.SetClientName("trending"))
+	original.factories.connect = func(progID, node string, info *com.COAUTHINFO) (*OPCServer, error) {
+		return newOPCServerWithProvider(&mockServerProvider{
+			SetClientNameFn: func(string) error { return errors.New("rejected") },
+			ReleaseFn:       func() { released = true },
+		}, progID, node), nil
+	}
+	_, err = original.Clone()
+	assert.ErrorContains(t, err, "rejected")
+	assert.True(t, released)
+}
@@ -32,7 +32,8 @@ func (sl *IEnumString) Vtbl() *IEnumStringVtbl {
 	return (*IEnumStringVtbl)(unsafe.Pointer(sl.IUnknown.LpVtbl))
 }
 
-// Next retrieves the next celt items in the enumeration sequence.
+// Next retrieves the next celt items in the enumeration sequence. It may return fewer items than
+// requested, and returns none without calling the server when celt is 0.
 //
 // Parameters:
 //
@@ -46,6 +47,9 @@ func (sl *IEnumString) Vtbl() *IEnumStringVtbl {
 //
 //	items, err := enum.Next(10)
 func (sl *IEnumString) Next(celt uint32) (result []string, err error) {
+	if celt == 0 {
+		return
+	}
 	pRgelt := make([]*uint16, celt)
 	var pceltFetched uint32
 	r0, _, _ := syscall.SyscallN(
@@ -55,15 +59,47 @@ func (sl *IEnumString) Next(celt uint32) (result []string, err error) {
 		uintptr(unsafe.Pointer(&pRgelt[0])),
 		uintptr(unsafe.Pointer(&pceltFetched)),
 	)
+	if pceltFetched > celt {
+		pceltFetched = celt
+	}
 	if int32(r0) < 0 {
-		err = syscall.Errno(r0)
+		// free any strings a misbehaving server returned along with the failure
+		for i := uint32(0); i < pceltFetched; i++ {
+			if pRgelt[i] != nil {
+				CoTaskMemFree(unsafe.Pointer(pRgelt[i]))
+			}
+		}
+		err = HRESULT(r0)
 		return
 	}
 	result = make([]string, pceltFetched)
 	for i := uint32(0); i < pceltFetched; i++ {
 		pwstr := pRgelt[i]
+		if pwstr == nil {
+			continue
+		}
 		result[i] = windows.UTF16PtrToString(pwstr)
 		CoTaskMemFree(unsafe.Pointer(pwstr))
 	}
 	return
 }
+
+// enumStringBatch is the number of strings requested per Next call when an enumerator is drained.
+const enumStringBatch = 100
+
+// drainStrings calls next until it returns no more strings and returns all of them. Servers may return
+// fewer strings than requested before the end of the enumeration, so only an empty batch ends it.
+// On error the partial result is dropped so callers never mistake it for a complete enumeration.
+func drainStrings(next func(celt uint32) ([]string, error)) ([]string, error) {
+	var result []string
+	for {
+		batch, err := next(enumStringBatch)
+		if err != nil {
+			return nil, err
+		}
+		if len(batch) == 0 {
+			return result, nil
+		}
+		result = append(result, batch...)
+	}
+}
@@ -0,0 +1,119 @@
+//go:build windows
+
+package com
+
+import (
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var IID_IEnumUnknown = windows.GUID{
+	Data1: 0x00000100,
+	Data2: 0x0000,
+	Data3: 0x0000,
+	Data4: [8]byte{0xC0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x46},
+}
+
+// IEnumUnknownVtbl is the virtual function table for the IEnumUnknown interface.
+type IEnumUnknownVtbl struct {
+	IUnknownVtbl
+	// Next retrieves the next celt objects in the enumeration sequence.
+	Next uintptr
+	// Skip skips over the next celt objects in the enumeration sequence.
+	Skip uintptr
+	// Reset resets the enumeration sequence to the beginning.
+	Reset uintptr
+	// Clone creates a new enumerator that contains the same enumeration state as the current one.
+	Clone uintptr
+}
+
+// IEnumUnknown is a standard COM interface for enumerating objects, such as the groups of an OPC server.
+type IEnumUnknown struct {
+	// IUnknown is the underlying COM interface.
+	*IUnknown
+}
+
+func (sl *IEnumUnknown) Vtbl() *IEnumUnknownVtbl {
+	return (*IEnumUnknownVtbl)(unsafe.Pointer(sl.IUnknown.LpVtbl))
+}
+
+// Next retrieves the next celt objects in the enumeration sequence. It may return fewer objects than
+// requested, and returns none without calling the server when celt is 0. The caller releases every
+// returned object.
+//
+// Example:
+//
+//	objects, err := enum.Next(10)
+//	for _, object := range objects {
+//		defer object.Release()
+//	}
+func (sl *IEnumUnknown) Next(celt uint32) (result []*IUnknown, err error) {
+	if celt == 0 {
+		return
+	}
+	pRgelt := make([]*IUnknown, celt)
+	var pceltFetched uint32
+	r0, _, _ := syscall.SyscallN(
+		sl.Vtbl().Next,
+		uintptr(unsafe.Pointer(sl.IUnknown)),
+		uintptr(celt),
+		uintptr(unsafe.Pointer(&pRgelt[0])),
+		uintptr(unsafe.Pointer(&pceltFetched)),
+	)
+	if pceltFetched > celt {
+		pceltFetched = celt
+	}
+	if int32(r0) < 0 {
+		// release any objects a misbehaving server returned along with the failure
+		for i := uint32(0); i < pceltFetched; i++ {
+			if pRgelt[i] != nil {
+				pRgelt[i].Release()
+			}
+		}
+		err = HRESULT(r0)
+		return
+	}
+	result = make([]*IUnknown, 0, pceltFetched)
+	for i := uint32(0); i < pceltFetched; i++ {
+		if pRgelt[i] != nil {
+			trackAcquire(pRgelt[i], IID_IUnknown)
+			result = append(result, pRgelt[i])
+		}
+	}
+	return
+}
+
+// Skip skips over the next celt objects in the enumeration sequence. Skipping past the end is not an error.
+//
+// Example:
+//
+//	err := enum.Skip(5)
+func (sl *IEnumUnknown) Skip(celt uint32) error {
+	r0, _, _ := syscall.SyscallN(
+		sl.Vtbl().Skip,
+		uintptr(unsafe.Pointer(sl.IUnknown)),
+		uintptr(celt),
+	)
+	if int32(r0) < 0 {
+		return HRESULT(r0)
+	}
+	return nil
+}
+
+// Reset resets the enumeration sequence to the beginning.
+//
+// Example:
+//
+//	err := enum.Reset()
+func (sl *IEnumUnknown) Reset() error {
+	r0, _, _ := syscall.SyscallN(
+		sl.Vtbl().Reset,
+		uintptr(unsafe.Pointer(sl.IUnknown)),
+	)
+	if int32(r0) < 0 {
+		return HRESULT(r0)
+	}
+	return nil
+}
@@ -61,6 +61,9 @@ func (sl *IOPCAsyncIO2) Vtbl() *IOPCAsyncIO2Vtbl {
 //
 //	cancelID, errors, err := asyncIO.Read(serverHandles, 123)
 func (sl *IOPCAsyncIO2) Read(phServer []uint32, dwTransactionID uint32) (pdwCancelID uint32, ppErrors []int32, err error) {
+	if len(phServer) == 0 {
+		return
+	}
 	var pErrors unsafe.Pointer
 	r0, _, _ := syscall.SyscallN(
 		sl.Vtbl().Read,
@@ -71,7 +74,7 @@ func (sl *IOPCAsyncIO2) Read(phServer []uint32, dwTransactionID uint32) (pdwCanc
 		uintptr(unsafe.Pointer(&pdwCancelID)),
 		uintptr(unsafe.Pointer(&pErrors)))
 	if int32(r0) < 0 {
-		err = syscall.Errno(r0)
+		err = HRESULT(r0)
 		return
 	}
 	defer func() {
@@ -87,12 +90,19 @@ func (sl *IOPCAsyncIO2) Read(phServer []uint32, dwTransactionID uint32) (pdwCanc
 	return
 }
 
-// Write performs an asynchronous write of one or more items in the group.
+// Write performs an asynchronous write of one or more items in the group. pItemValues must hold one
+// value per server handle.
 //
 // Example:
 //
 //	cancelID, errors, err := asyncIO.Write(serverHandles, variants, 456)
 func (sl *IOPCAsyncIO2) Write(phServer []uint32, pItemValues []VARIANT, dwTransactionID uint32) (pdwCancelID uint32, ppErrors []int32, err error) {
+	if err = checkBatchLengths("values", len(pItemValues), len(phServer)); err != nil {
+		return
+	}
+	if len(phServer) == 0 {
+		return
+	}
 	var pErrors unsafe.Pointer
 	r0, _, _ := syscall.SyscallN(
 		sl.Vtbl().Write,
@@ -104,7 +114,7 @@ func (sl *IOPCAsyncIO2) Write(phServer []uint32, pItemValues []VARIANT, dwTransa
 		uintptr(unsafe.Pointer(&pdwCancelID)),
 		uintptr(unsafe.Pointer(&pErrors)))
 	if int32(r0) < 0 {
-		err = syscall.Errno(r0)
+		err = HRESULT(r0)
 		return
 	}
 	defer func() {
@@ -133,7 +143,7 @@ func (sl *IOPCAsyncIO2) Refresh2(dwSource OPCDATASOURCE, dwTransactionID uint32)
 		uintptr(dwTransactionID),
 		uintptr(unsafe.Pointer(&pdwCancelID)))
 	if int32(r0) < 0 {
-		err = syscall.Errno(r0)
+		err = HRESULT(r0)
 		return
 	}
 	return
@@ -151,7 +161,7 @@ func (sl *IOPCAsyncIO2) Cancel2(dwCancelID uint32) (err error) {
 		uintptr(dwCancelID),
 	)
 	if int32(r0) < 0 {
-		err = syscall.Errno(r0)
+		err = HRESULT(r0)
 		return
 	}
 	return
@@ -0,0 +1,263 @@
+//go:build windows
+
+package com
+
+import (
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var IID_IOPCBrowse = windows.GUID{
+	Data1: 0x39227004,
+	Data2: 0xA18F,
+	Data3: 0x4b57,
+	Data4: [8]byte{0x8B, 0x0A, 0x52, 0x35, 0x67, 0x0F, 0x44, 0x68},
+}
+
+// IOPCBrowseVtbl is the virtual function table for the IOPCBrowse interface.
+type IOPCBrowseVtbl struct {
+	IUnknownVtbl
+	// GetProperties returns properties of one or more items.
+	GetProperties uintptr
+	// Browse returns the elements below a branch of the address space.
+	Browse uintptr
+}
+
+// IOPCBrowse provides stateless browsing of the server address space as defined in the OPC Data Access
+// Custom Interface Standard 3.0. It is an optional server interface.
+type IOPCBrowse struct {
+	// IUnknown is the underlying COM interface.
+	*IUnknown
+}
+
+func (v *IOPCBrowse) Vtbl() *IOPCBrowseVtbl {
+	return (*IOPCBrowseVtbl)(unsafe.Pointer(v.IUnknown.LpVtbl))
+}
+
+// OPCBROWSEFILTER selects the kind of elements returned by IOPCBrowse.Browse.
+type OPCBROWSEFILTER uint32
+
+const (
+	// OPC_BROWSE_FILTER_ALL returns branches and items.
+	OPC_BROWSE_FILTER_ALL OPCBROWSEFILTER = 1
+	// OPC_BROWSE_FILTER_BRANCHES returns only elements with children.
+	OPC_BROWSE_FILTER_BRANCHES OPCBROWSEFILTER = 2
+	// OPC_BROWSE_FILTER_ITEMS returns only items.
+	OPC_BROWSE_FILTER_ITEMS OPCBROWSEFILTER = 3
+)
+
+// Flags of a browse element.
+const (
+	// OPC_BROWSE_HASCHILDREN marks an element with children.
+	OPC_BROWSE_HASCHILDREN uint32 = 0x01
+	// OPC_BROWSE_ISITEM marks an element that is an item.
+	OPC_BROWSE_ISITEM uint32 = 0x02
+)
+
+// TagOPCITEMPROPERTY is a property of an item returned by IOPCBrowse.
+type TagOPCITEMPROPERTY struct {
+	// VtDataType is the data type of the property.
+	VtDataType uint16
+	// WReserved is reserved for future use.
+	WReserved uint16
+	// DwPropertyID is the identifier of the property.
+	DwPropertyID uint32
+	// SzItemID is the item ID of the property, if it can be accessed as an item.
+	SzItemID *uint16
+	// SzDescription is the description of the property.
+	SzDescription *uint16
+	// VValue is the value of the property, if requested.
+	VValue VARIANT
+	// HrErrorID is the result for the property.
+	HrErrorID int32
+	// DwReserved is reserved for future use.
+	DwReserved uint32
+}
+
+// TagOPCITEMPROPERTIES holds the properties of an item returned by IOPCBrowse.
+type TagOPCITEMPROPERTIES struct {
+	// HrErrorID is the result for the item.
+	HrErrorID int32
+	// DwNumProperties is the number of properties.
+	DwNumProperties uint32
+	// PItemProperties points to the properties.
+	PItemProperties *TagOPCITEMPROPERTY
+	// DwReserved is reserved for future use.
+	DwReserved uint32
+}
+
+// TagOPCBROWSEELEMENT is an element of the address space returned by IOPCBrowse.Browse.
+type TagOPCBROWSEELEMENT struct {
+	// SzName is the short name of the element.
+	SzName *uint16
+	// SzItemID is the fully qualified item ID of the element.
+	SzItemID *uint16
+	// DwFlagValue combines OPC_BROWSE_HASCHILDREN and OPC_BROWSE_ISITEM.
+	DwFlagValue uint32
+	// DwReserved is reserved for future use.
+	DwReserved uint32
+	// ItemProperties are the properties of the element, if requested.
+	ItemProperties TagOPCITEMPROPERTIES
+}
+
+// ItemProperty is a Go-friendly version of TagOPCITEMPROPERTY.
+type ItemProperty struct {
+	// PropertyID is the identifier of the property.
+	PropertyID uint32
+	// DataType is the data type of the property.
+	DataType VT
+	// ItemID is the item ID of the property, if it can be accessed as an item.
+	ItemID string
+	// Description is the description of the property.
+	Description string
+	// Value is the value of the property, if requested.
+	Value interface{}
+	// Error is the result for the property as an HRESULT.
+	Error int32
+}
+
+// BrowseElement is a Go-friendly version of TagOPCBROWSEELEMENT.
+type BrowseElement struct {
+	// Name is the short name of the element.
+	Name string
+	// ItemID is the fully qualified item ID of the element.
+	ItemID string
+	// Flags combines OPC_BROWSE_HASCHILDREN and OPC_BROWSE_ISITEM.
+	Flags uint32
+	// PropertiesError is the result of reading the properties of the element as an HRESULT.
+	PropertiesError int32
+	// Properties are the properties of the element, if requested.
+	Properties []ItemProperty
+}
+
+// BrowseOptions selects the elements and properties returned by IOPCBrowse.Browse.
+type BrowseOptions struct {
+	// Filter selects branches, items or both; zero means OPC_BROWSE_FILTER_ALL.
+	Filter OPCBROWSEFILTER
+	// ElementNameFilter is a wildcard pattern the element names must match.
+	ElementNameFilter string
+	// VendorFilter is a vendor specific filter.
+	VendorFilter string
+	// ReturnAllProperties returns every property of each element instead of PropertyIDs.
+	ReturnAllProperties bool
+	// ReturnPropertyValues returns the values of the properties as well.
+	ReturnPropertyValues bool
+	// PropertyIDs are the properties returned when ReturnAllProperties is false.
+	PropertyIDs []uint32
+}
+
+// Browse returns the elements below the branch itemID, or below the root if itemID is empty. It follows
+// the continuation points of the server until every element has been returned.
+//
+// Example:
+//
+//	elements, err := browse.Browse("Simulation Items", com.BrowseOptions{ReturnAllProperties: true})
+func (v *IOPCBrowse) Browse(itemID string, options BrowseOptions) ([]BrowseElement, error) {
+	pItemID, err := syscall.UTF16PtrFromString(itemID)
+	if err != nil {
+		return nil, err
+	}
+	pNameFilter, err := syscall.UTF16PtrFromString(options.ElementNameFilter)
+	if err != nil {
+		return nil, err
+	}
+	pVendorFilter, err := syscall.UTF16PtrFromString(options.VendorFilter)
+	if err != nil {
+		return nil, err
+	}
+	filter := options.Filter
+	if filter == 0 {
+		filter = OPC_BROWSE_FILTER_ALL
+	}
+	var pPropertyIDs *uint32
+	if len(options.PropertyIDs) > 0 {
+		pPropertyIDs = &options.PropertyIDs[0]
+	}
+	// the continuation point is allocated by the server and handed back to it unchanged
+	var continuation *uint16
+	defer func() {
+		CoTaskMemFree(unsafe.Pointer(continuation))
+	}()
+	var result []BrowseElement
+	for {
+		var moreElements int32
+		var count uint32
+		var pElements unsafe.Pointer
+		r0, _, _ := syscall.SyscallN(
+			v.Vtbl().Browse,
+			uintptr(unsafe.Pointer(v.IUnknown)),
+			uintptr(unsafe.Pointer(pItemID)),
+			uintptr(unsafe.Pointer(&continuation)),
+			0,
+			uintptr(filter),
+			uintptr(unsafe.Pointer(pNameFilter)),
+			uintptr(unsafe.Pointer(pVendorFilter)),
+			uintptr(BoolToComBOOL(options.ReturnAllProperties)),
+			uintptr(BoolToComBOOL(options.ReturnPropertyValues)),
+			uintptr(len(options.PropertyIDs)),
+			uintptr(unsafe.Pointer(pPropertyIDs)),
+			uintptr(unsafe.Pointer(&moreElements)),
+			uintptr(unsafe.Pointer(&count)),
+			uintptr(unsafe.Pointer(&pElements)),
+		)
+		if int32(r0) < 0 {
+			return nil, HRESULT(r0)
+		}
+		result = append(result, readBrowseElements(pElements, count)...)
+		if continuation == nil || *continuation == 0 || count == 0 {
+			return result, nil
+		}
+	}
+}
+
+// readBrowseElements converts and frees an array of browse elements returned by the server.
+func readBrowseElements(pElements unsafe.Pointer, count uint32) []BrowseElement {
+	if pElements == nil {
+		return nil
+	}
+	defer CoTaskMemFree(pElements)
+	elements := make([]BrowseElement, count)
+	for i := uint32(0); i < count; i++ {
+		e := (*TagOPCBROWSEELEMENT)(unsafe.Pointer(uintptr(pElements) + uintptr(i)*unsafe.Sizeof(TagOPCBROWSEELEMENT{})))
+		elements[i] = BrowseElement{
+			Name:            windows.UTF16PtrToString(e.SzName),
+			ItemID:          windows.UTF16PtrToString(e.SzItemID),
+			Flags:           e.DwFlagValue,
+			PropertiesError: e.ItemProperties.HrErrorID,
+			Properties:      readItemProperties(&e.ItemProperties),
+		}
+		CoTaskMemFree(unsafe.Pointer(e.SzName))
+		CoTaskMemFree(unsafe.Pointer(e.SzItemID))
+	}
+	return elements
+}
+
+// readItemProperties converts and frees the properties of an item returned by the server.
+func readItemProperties(p *TagOPCITEMPROPERTIES) []ItemProperty {
+	if p.PItemProperties == nil {
+		return nil
+	}
+	defer CoTaskMemFree(unsafe.Pointer(p.PItemProperties))
+	properties := make([]ItemProperty, p.DwNumProperties)
+	for i := uint32(0); i < p.DwNumProperties; i++ {
+		prop := (*TagOPCITEMPROPERTY)(unsafe.Pointer(uintptr(unsafe.Pointer(p.PItemProperties)) + uintptr(i)*unsafe.Sizeof(TagOPCITEMPROPERTY{})))
+		properties[i] = ItemProperty{
+			PropertyID:  prop.DwPropertyID,
+			DataType:    VT(prop.VtDataType),
+			ItemID:      windows.UTF16PtrToString(prop.SzItemID),
+			Description: windows.UTF16PtrToString(prop.SzDescription),
+			Error:       prop.HrErrorID,
+		}
+		if prop.HrErrorID >= 0 {
+			if value, err := prop.VValue.Value(); err == nil {
+				properties[i].Value = value
+			}
+		}
+		prop.VValue.Clear()
+		CoTaskMemFree(unsafe.Pointer(prop.SzItemID))
+		CoTaskMemFree(unsafe.Pointer(prop.SzDescription))
+	}
+	return properties
+}
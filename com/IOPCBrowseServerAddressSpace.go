@@ -56,7 +56,7 @@ func (v *IOPCBrowseServerAddressSpace) QueryOrganization() (pNameSpaceType OPCNA
 		uintptr(unsafe.Pointer(&pNameSpaceType)),
 	)
 	if int32(r0) < 0 {
-		err = syscall.Errno(r0)
+		err = HRESULT(r0)
 	}
 	return
 }
@@ -83,7 +83,7 @@ func (v *IOPCBrowseServerAddressSpace) ChangeBrowsePosition(dwBrowseDirection OP
 		uintptr(unsafe.Pointer(pName)),
 	)
 	if int32(r0) < 0 {
-		err = syscall.Errno(r0)
+		err = HRESULT(r0)
 	}
 	return
 }
@@ -121,27 +121,19 @@ func (v *IOPCBrowseServerAddressSpace) BrowseOPCItemIDs(dwBrowseFilterType OPCBR
 		uintptr(unsafe.Pointer(&pString)),
 	)
 	if int32(r0) < 0 {
-		err = syscall.Errno(r0)
+		err = HRESULT(r0)
 		return
 	}
+	if pString == nil {
+		// some servers return S_FALSE without an enumerator when nothing matches
+		return nil, nil
+	}
+	trackAcquireName(pString, "IEnumString")
 	ppIEnumString := &IEnumString{pString}
-	defer func() {
-		ppIEnumString.Release()
-	}()
+	// the enumerator holds a server-side cursor, so it is released on every return path
+	defer ppIEnumString.Release()
 
-	for {
-		var batch []string
-		batch, err = ppIEnumString.Next(100)
-		if err != nil {
-			return nil, err
-		}
-		if len(batch) < 100 {
-			result = append(result, batch...)
-			break
-		}
-		result = append(result, batch...)
-	}
-	return result, nil
+	return drainStrings(ppIEnumString.Next)
 }
 
 // GetItemID retrieves the full item ID for a given browser item name.
@@ -163,7 +155,7 @@ func (v *IOPCBrowseServerAddressSpace) GetItemID(szItemDataID string) (szItemID
 		uintptr(unsafe.Pointer(&pString)),
 	)
 	if int32(r0) < 0 {
-		err = syscall.Errno(r0)
+		err = HRESULT(r0)
 		return
 	}
 	defer func() {
@@ -53,7 +53,7 @@ func (v *IOPCCommon) SetLocaleID(dwLcid uint32) (err error) {
 		uintptr(unsafe.Pointer(v.IUnknown)),
 		uintptr(dwLcid))
 	if int32(r0) < 0 {
-		err = syscall.Errno(r0)
+		err = HRESULT(r0)
 	}
 	return
 }
@@ -69,7 +69,7 @@ func (v *IOPCCommon) GetLocaleID() (pdwLcid uint32, err error) {
 		uintptr(unsafe.Pointer(v.IUnknown)),
 		uintptr(unsafe.Pointer(&pdwLcid)))
 	if int32(r0) < 0 {
-		err = syscall.Errno(r0)
+		err = HRESULT(r0)
 	}
 	return
 }
@@ -88,7 +88,7 @@ func (v *IOPCCommon) QueryAvailableLocaleIDs() (result []uint32, err error) {
 		uintptr(unsafe.Pointer(&pdwCount)),
 		uintptr(unsafe.Pointer(&pLcid)))
 	if int32(r0) < 0 {
-		err = syscall.Errno(r0)
+		err = HRESULT(r0)
 		return
 	}
 	defer func() {
@@ -105,25 +105,28 @@ func (v *IOPCCommon) QueryAvailableLocaleIDs() (result []uint32, err error) {
 }
 
 // GetErrorString retrieves a human-readable error string for a given HRESULT.
+// The string allocated by the server is freed before returning. Vendor-specific codes
+// for which the server has no text yield an empty string and a nil error.
 //
 // Example:
 //
-//	msg, err := common.GetErrorString(0x80040154)
-func (v *IOPCCommon) GetErrorString(dwError uint32) (str string, err error) {
+//	msg, err := common.GetErrorString(-1073479673) // 0xC0040007
+func (v *IOPCCommon) GetErrorString(dwError int32) (str string, err error) {
 	var pString *uint16
 	r0, _, _ := syscall.SyscallN(
 		v.Vtbl().GetErrorString,
 		uintptr(unsafe.Pointer(v.IUnknown)),
-		uintptr(dwError),
+		uintptr(uint32(dwError)),
 		uintptr(unsafe.Pointer(&pString)))
-	if int32(r0) < 0 {
-		err = syscall.Errno(r0)
-	}
 	defer func() {
 		if pString != nil {
 			CoTaskMemFree(unsafe.Pointer(pString))
 		}
 	}()
+	if int32(r0) < 0 {
+		err = HRESULT(r0)
+		return
+	}
 	str = windows.UTF16PtrToString(pString)
 	return
 }
@@ -144,7 +147,7 @@ func (v *IOPCCommon) SetClientName(szName string) (err error) {
 		uintptr(unsafe.Pointer(v.IUnknown)),
 		uintptr(unsafe.Pointer(pName)))
 	if r0 != 0 {
-		err = syscall.Errno(r0)
+		err = HRESULT(r0)
 	}
 	return
 }
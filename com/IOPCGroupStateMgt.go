@@ -59,7 +59,7 @@ func (sl *IOPCGroupStateMgt) GetState() (pUpdateRate uint32, pActive bool, ppNam
 		uintptr(unsafe.Pointer(&phClientGroup)),
 		uintptr(unsafe.Pointer(&phServerGroup)))
 	if r0 != 0 {
-		err = syscall.Errno(r0)
+		err = HRESULT(r0)
 		return
 	}
 	defer func() {
@@ -90,7 +90,7 @@ func (sl *IOPCGroupStateMgt) SetState(requestedUpdateRate *uint32, pActive *int3
 		uintptr(unsafe.Pointer(phClientGroup)),
 	)
 	if r0 != 0 {
-		err = syscall.Errno(r0)
+		err = HRESULT(r0)
 		return
 	}
 	return
@@ -112,7 +112,7 @@ func (sl *IOPCGroupStateMgt) SetName(szName string) error {
 		uintptr(unsafe.Pointer(pName)),
 	)
 	if r0 != 0 {
-		return syscall.Errno(r0)
+		return HRESULT(r0)
 	}
 	return nil
 }
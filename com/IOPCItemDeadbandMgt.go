@@ -0,0 +1,143 @@
+//go:build windows
+
+package com
+
+import (
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var IID_IOPCItemDeadbandMgt = windows.GUID{
+	Data1: 0x5946DA93,
+	Data2: 0x8B39,
+	Data3: 0x4ec8,
+	Data4: [8]byte{0xAB, 0x3D, 0xAA, 0x73, 0xDF, 0x5B, 0xC8, 0x6F},
+}
+
+// IOPCItemDeadbandMgtVtbl is the virtual function table for the IOPCItemDeadbandMgt interface.
+type IOPCItemDeadbandMgtVtbl struct {
+	IUnknownVtbl
+	// SetItemDeadband sets the percent deadband of one or more items.
+	SetItemDeadband uintptr
+	// GetItemDeadband retrieves the percent deadband of one or more items.
+	GetItemDeadband uintptr
+	// ClearItemDeadband makes one or more items use the group deadband again.
+	ClearItemDeadband uintptr
+}
+
+// IOPCItemDeadbandMgt allows clients to override the group deadband for individual items
+// as defined in the OPC Data Access Custom Interface Standard 3.0. It is an optional group interface.
+type IOPCItemDeadbandMgt struct {
+	// IUnknown is the underlying COM interface.
+	*IUnknown
+}
+
+func (sl *IOPCItemDeadbandMgt) Vtbl() *IOPCItemDeadbandMgtVtbl {
+	return (*IOPCItemDeadbandMgtVtbl)(unsafe.Pointer(sl.IUnknown.LpVtbl))
+}
+
+// SetItemDeadband sets the percent deadband of one or more items.
+//
+// Example:
+//
+//	errors, err := mgt.SetItemDeadband(serverHandles, []float32{0.5, 1.0})
+func (sl *IOPCItemDeadbandMgt) SetItemDeadband(phServer []uint32, pPercentDeadband []float32) ([]int32, error) {
+	if err := checkBatchLengths("deadbands", len(pPercentDeadband), len(phServer)); err != nil {
+		return nil, err
+	}
+	if len(phServer) == 0 {
+		return nil, nil
+	}
+	dwCount := uint32(len(phServer))
+	var pErrors unsafe.Pointer
+	r0, _, _ := syscall.SyscallN(
+		sl.Vtbl().SetItemDeadband,
+		uintptr(unsafe.Pointer(sl.IUnknown)),
+		uintptr(dwCount),
+		uintptr(unsafe.Pointer(&phServer[0])),
+		uintptr(unsafe.Pointer(&pPercentDeadband[0])),
+		uintptr(unsafe.Pointer(&pErrors)),
+	)
+	if int32(r0) < 0 {
+		return nil, HRESULT(r0)
+	}
+	defer func() {
+		CoTaskMemFree(pErrors)
+	}()
+	errors := make([]int32, dwCount)
+	for i := uint32(0); i < dwCount; i++ {
+		errors[i] = *(*int32)(unsafe.Pointer(uintptr(pErrors) + uintptr(i)*4))
+	}
+	return errors, nil
+}
+
+// GetItemDeadband retrieves the percent deadband of one or more items.
+//
+// Example:
+//
+//	deadbands, errors, err := mgt.GetItemDeadband(serverHandles)
+func (sl *IOPCItemDeadbandMgt) GetItemDeadband(phServer []uint32) ([]float32, []int32, error) {
+	if len(phServer) == 0 {
+		return nil, nil, nil
+	}
+	dwCount := uint32(len(phServer))
+	var pPercentDeadband unsafe.Pointer
+	var pErrors unsafe.Pointer
+	r0, _, _ := syscall.SyscallN(
+		sl.Vtbl().GetItemDeadband,
+		uintptr(unsafe.Pointer(sl.IUnknown)),
+		uintptr(dwCount),
+		uintptr(unsafe.Pointer(&phServer[0])),
+		uintptr(unsafe.Pointer(&pPercentDeadband)),
+		uintptr(unsafe.Pointer(&pErrors)),
+	)
+	if int32(r0) < 0 {
+		return nil, nil, HRESULT(r0)
+	}
+	defer func() {
+		CoTaskMemFree(pPercentDeadband)
+		CoTaskMemFree(pErrors)
+	}()
+	deadbands := make([]float32, dwCount)
+	errors := make([]int32, dwCount)
+	for i := uint32(0); i < dwCount; i++ {
+		errors[i] = *(*int32)(unsafe.Pointer(uintptr(pErrors) + uintptr(i)*4))
+		if errors[i] >= 0 {
+			deadbands[i] = *(*float32)(unsafe.Pointer(uintptr(pPercentDeadband) + uintptr(i)*4))
+		}
+	}
+	return deadbands, errors, nil
+}
+
+// ClearItemDeadband makes one or more items use the group deadband again.
+//
+// Example:
+//
+//	errors, err := mgt.ClearItemDeadband(serverHandles)
+func (sl *IOPCItemDeadbandMgt) ClearItemDeadband(phServer []uint32) ([]int32, error) {
+	if len(phServer) == 0 {
+		return nil, nil
+	}
+	dwCount := uint32(len(phServer))
+	var pErrors unsafe.Pointer
+	r0, _, _ := syscall.SyscallN(
+		sl.Vtbl().ClearItemDeadband,
+		uintptr(unsafe.Pointer(sl.IUnknown)),
+		uintptr(dwCount),
+		uintptr(unsafe.Pointer(&phServer[0])),
+		uintptr(unsafe.Pointer(&pErrors)),
+	)
+	if int32(r0) < 0 {
+		return nil, HRESULT(r0)
+	}
+	defer func() {
+		CoTaskMemFree(pErrors)
+	}()
+	errors := make([]int32, dwCount)
+	for i := uint32(0); i < dwCount; i++ {
+		errors[i] = *(*int32)(unsafe.Pointer(uintptr(pErrors) + uintptr(i)*4))
+	}
+	return errors, nil
+}
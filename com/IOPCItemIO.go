@@ -0,0 +1,186 @@
+//go:build windows
+
+package com
+
+import (
+	"runtime"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var IID_IOPCItemIO = windows.GUID{
+	Data1: 0x85C0B427,
+	Data2: 0x2893,
+	Data3: 0x4cbc,
+	Data4: [8]byte{0xBD, 0x78, 0xE5, 0xFC, 0x51, 0x46, 0xF0, 0x8F},
+}
+
+// IOPCItemIOVtbl is the virtual function table for the IOPCItemIO interface.
+type IOPCItemIOVtbl struct {
+	IUnknownVtbl
+	// Read reads one or more items by item ID.
+	Read uintptr
+	// WriteVQT writes values, qualities and timestamps to one or more items by item ID.
+	WriteVQT uintptr
+}
+
+// IOPCItemIO provides connectionless access to items by item ID, without creating a group, as defined
+// in the OPC Data Access Custom Interface Standard 3.0. It is an optional server interface.
+type IOPCItemIO struct {
+	// IUnknown is the underlying COM interface.
+	*IUnknown
+}
+
+func (sl *IOPCItemIO) Vtbl() *IOPCItemIOVtbl {
+	return (*IOPCItemIOVtbl)(unsafe.Pointer(sl.IUnknown.LpVtbl))
+}
+
+// TagOPCITEMVQT contains a value to write with an optional quality and timestamp.
+type TagOPCITEMVQT struct {
+	// VDataValue is the value to write.
+	VDataValue VARIANT
+	// BQualitySpecified tells whether WQuality is written.
+	BQualitySpecified int32
+	// WQuality is the quality to write.
+	WQuality uint16
+	// WReserved is reserved for future use.
+	WReserved uint16
+	// BTimeStampSpecified tells whether FtTimeStamp is written.
+	BTimeStampSpecified int32
+	// DwReserved is reserved for future use.
+	DwReserved uint32
+	// FtTimeStamp is the timestamp to write.
+	FtTimeStamp windows.Filetime
+}
+
+// Read reads one or more items by item ID.
+// A value whose VARIANT type cannot be converted is returned as *ErrUnsupportedVariant in its ItemState,
+// with the result code of the server unchanged.
+//
+// Parameters:
+//
+//	itemIDs: The fully qualified item IDs.
+//	maxAge: The maximum age in milliseconds of the cached value for each item; 0 reads from the device
+//	and 0xFFFFFFFF reads from the cache.
+//
+// Returns:
+//
+//	The item states, without client handles, and a slice of HRESULTs (as int32).
+//
+// Example:
+//
+//	states, errors, err := itemIO.Read([]string{"Random.Int4"}, []uint32{0})
+func (sl *IOPCItemIO) Read(itemIDs []string, maxAge []uint32) ([]*ItemState, []int32, error) {
+	if err := checkBatchLengths("max ages", len(maxAge), len(itemIDs)); err != nil {
+		return nil, nil, err
+	}
+	if len(itemIDs) == 0 {
+		return nil, nil, nil
+	}
+	names, err := utf16PtrsFromStrings(itemIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	count := uint32(len(itemIDs))
+	var pValues unsafe.Pointer
+	var pQualities unsafe.Pointer
+	var pTimeStamps unsafe.Pointer
+	var pErrors unsafe.Pointer
+	r0, _, _ := syscall.SyscallN(
+		sl.Vtbl().Read,
+		uintptr(unsafe.Pointer(sl.IUnknown)),
+		uintptr(count),
+		uintptr(unsafe.Pointer(&names[0])),
+		uintptr(unsafe.Pointer(&maxAge[0])),
+		uintptr(unsafe.Pointer(&pValues)),
+		uintptr(unsafe.Pointer(&pQualities)),
+		uintptr(unsafe.Pointer(&pTimeStamps)),
+		uintptr(unsafe.Pointer(&pErrors)),
+	)
+	runtime.KeepAlive(names)
+	if int32(r0) < 0 {
+		return nil, nil, HRESULT(r0)
+	}
+	defer func() {
+		CoTaskMemFree(pValues)
+		CoTaskMemFree(pQualities)
+		CoTaskMemFree(pTimeStamps)
+		CoTaskMemFree(pErrors)
+	}()
+	states := make([]*ItemState, count)
+	errors := make([]int32, count)
+	for i := uint32(0); i < count; i++ {
+		errNo := *(*int32)(unsafe.Pointer(uintptr(pErrors) + uintptr(i)*4))
+		variant := (*VARIANT)(unsafe.Pointer(uintptr(pValues) + uintptr(i)*unsafe.Sizeof(VARIANT{})))
+		if errNo >= 0 {
+			v := ValueOrUnsupported(variant)
+			ft := *(*windows.Filetime)(unsafe.Pointer(uintptr(pTimeStamps) + uintptr(i)*unsafe.Sizeof(windows.Filetime{})))
+			states[i] = &ItemState{
+				Value:     v,
+				Quality:   *(*uint16)(unsafe.Pointer(uintptr(pQualities) + uintptr(i)*2)),
+				Timestamp: time.Unix(0, ft.Nanoseconds()),
+			}
+		}
+		variant.Clear()
+		errors[i] = errNo
+	}
+	return states, errors, nil
+}
+
+// WriteVQT writes values, and optionally qualities and timestamps, to one or more items by item ID.
+//
+// Returns:
+//
+//	A slice of HRESULTs (as int32), one per item.
+//
+// Example:
+//
+//	errors, err := itemIO.WriteVQT([]string{"Bucket Brigade.Int4"}, vqts)
+func (sl *IOPCItemIO) WriteVQT(itemIDs []string, values []TagOPCITEMVQT) ([]int32, error) {
+	if err := checkBatchLengths("values", len(values), len(itemIDs)); err != nil {
+		return nil, err
+	}
+	if len(itemIDs) == 0 {
+		return nil, nil
+	}
+	names, err := utf16PtrsFromStrings(itemIDs)
+	if err != nil {
+		return nil, err
+	}
+	count := uint32(len(itemIDs))
+	var pErrors unsafe.Pointer
+	r0, _, _ := syscall.SyscallN(
+		sl.Vtbl().WriteVQT,
+		uintptr(unsafe.Pointer(sl.IUnknown)),
+		uintptr(count),
+		uintptr(unsafe.Pointer(&names[0])),
+		uintptr(unsafe.Pointer(&values[0])),
+		uintptr(unsafe.Pointer(&pErrors)),
+	)
+	runtime.KeepAlive(names)
+	if int32(r0) < 0 {
+		return nil, HRESULT(r0)
+	}
+	defer CoTaskMemFree(pErrors)
+	errors := make([]int32, count)
+	for i := uint32(0); i < count; i++ {
+		errors[i] = *(*int32)(unsafe.Pointer(uintptr(pErrors) + uintptr(i)*4))
+	}
+	return errors, nil
+}
+
+// utf16PtrsFromStrings converts strings to an array of null-terminated UTF-16 pointers.
+func utf16PtrsFromStrings(ss []string) ([]*uint16, error) {
+	ptrs := make([]*uint16, len(ss))
+	for i, s := range ss {
+		p, err := syscall.UTF16PtrFromString(s)
+		if err != nil {
+			return nil, err
+		}
+		ptrs[i] = p
+	}
+	return ptrs, nil
+}
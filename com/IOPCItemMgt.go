@@ -124,6 +124,9 @@ func (result *TagOPCITEMRESULT) CloneToStruct() TagOPCITEMRESULTStruct {
 //
 //	results, errors, err := mgt.AddItems([]com.TagOPCITEMDEF{{SzItemID: com.SysAllocStringLen("Random.Int4"), ...}})
 func (sl *IOPCItemMgt) AddItems(items []TagOPCITEMDEF) ([]TagOPCITEMRESULTStruct, []int32, error) {
+	if len(items) == 0 {
+		return nil, nil, nil
+	}
 	dwCount := uint32(len(items))
 	var pAddResults unsafe.Pointer
 	var pErrors unsafe.Pointer
@@ -136,7 +139,7 @@ func (sl *IOPCItemMgt) AddItems(items []TagOPCITEMDEF) ([]TagOPCITEMRESULTStruct
 		uintptr(unsafe.Pointer(&pErrors)),
 	)
 	if int32(r0) < 0 {
-		return nil, nil, syscall.Errno(r0)
+		return nil, nil, HRESULT(r0)
 	}
 	defer func() {
 		CoTaskMemFree(pAddResults)
@@ -160,6 +163,9 @@ func (sl *IOPCItemMgt) AddItems(items []TagOPCITEMDEF) ([]TagOPCITEMRESULTStruct
 //
 //	results, errors, err := mgt.ValidateItems(items, false)
 func (sl *IOPCItemMgt) ValidateItems(items []TagOPCITEMDEF, bBlobUpdate bool) ([]TagOPCITEMRESULTStruct, []int32, error) {
+	if len(items) == 0 {
+		return nil, nil, nil
+	}
 	dwCount := uint32(len(items))
 	var pValidationResults unsafe.Pointer
 	var pErrors unsafe.Pointer
@@ -173,7 +179,7 @@ func (sl *IOPCItemMgt) ValidateItems(items []TagOPCITEMDEF, bBlobUpdate bool) ([
 		uintptr(unsafe.Pointer(&pErrors)),
 	)
 	if int32(r0) < 0 {
-		return nil, nil, syscall.Errno(r0)
+		return nil, nil, HRESULT(r0)
 	}
 	defer func() {
 		CoTaskMemFree(pValidationResults)
@@ -201,6 +207,9 @@ func (sl *IOPCItemMgt) ValidateItems(items []TagOPCITEMDEF, bBlobUpdate bool) ([
 //
 //	errors, err := mgt.RemoveItems(serverHandles)
 func (sl *IOPCItemMgt) RemoveItems(phServer []uint32) ([]int32, error) {
+	if len(phServer) == 0 {
+		return nil, nil
+	}
 	dwCount := uint32(len(phServer))
 	var pErrors unsafe.Pointer
 	r0, _, _ := syscall.SyscallN(
@@ -211,7 +220,7 @@ func (sl *IOPCItemMgt) RemoveItems(phServer []uint32) ([]int32, error) {
 		uintptr(unsafe.Pointer(&pErrors)),
 	)
 	if int32(r0) < 0 {
-		return nil, syscall.Errno(r0)
+		return nil, HRESULT(r0)
 	}
 	defer func() {
 		CoTaskMemFree(pErrors)
@@ -230,6 +239,9 @@ func (sl *IOPCItemMgt) RemoveItems(phServer []uint32) ([]int32, error) {
 //
 //	errors, err := mgt.SetActiveState(serverHandles, true)
 func (sl *IOPCItemMgt) SetActiveState(phServer []uint32, bActive bool) ([]int32, error) {
+	if len(phServer) == 0 {
+		return nil, nil
+	}
 	dwCount := uint32(len(phServer))
 	var pErrors unsafe.Pointer
 	r0, _, _ := syscall.SyscallN(
@@ -241,7 +253,7 @@ func (sl *IOPCItemMgt) SetActiveState(phServer []uint32, bActive bool) ([]int32,
 		uintptr(unsafe.Pointer(&pErrors)),
 	)
 	if int32(r0) < 0 {
-		return nil, syscall.Errno(r0)
+		return nil, HRESULT(r0)
 	}
 	defer func() {
 		CoTaskMemFree(pErrors)
@@ -260,6 +272,12 @@ func (sl *IOPCItemMgt) SetActiveState(phServer []uint32, bActive bool) ([]int32,
 //
 //	errors, err := mgt.SetClientHandles(serverHandles, clientHandles)
 func (sl *IOPCItemMgt) SetClientHandles(phServer []uint32, phClient []uint32) ([]int32, error) {
+	if err := checkBatchLengths("client handles", len(phClient), len(phServer)); err != nil {
+		return nil, err
+	}
+	if len(phServer) == 0 {
+		return nil, nil
+	}
 	dwCount := uint32(len(phServer))
 	var pErrors unsafe.Pointer
 	r0, _, _ := syscall.SyscallN(
@@ -271,7 +289,7 @@ func (sl *IOPCItemMgt) SetClientHandles(phServer []uint32, phClient []uint32) ([
 		uintptr(unsafe.Pointer(&pErrors)),
 	)
 	if int32(r0) < 0 {
-		return nil, syscall.Errno(r0)
+		return nil, HRESULT(r0)
 	}
 	defer func() {
 		CoTaskMemFree(pErrors)
@@ -290,6 +308,12 @@ func (sl *IOPCItemMgt) SetClientHandles(phServer []uint32, phClient []uint32) ([
 //
 //	errors, err := mgt.SetDatatypes(serverHandles, requestedTypes)
 func (sl *IOPCItemMgt) SetDatatypes(phServer []uint32, pRequestedDatatypes []VT) ([]int32, error) {
+	if err := checkBatchLengths("data types", len(pRequestedDatatypes), len(phServer)); err != nil {
+		return nil, err
+	}
+	if len(phServer) == 0 {
+		return nil, nil
+	}
 	dwCount := uint32(len(phServer))
 	var pErrors unsafe.Pointer
 	r0, _, _ := syscall.SyscallN(
@@ -301,7 +325,7 @@ func (sl *IOPCItemMgt) SetDatatypes(phServer []uint32, pRequestedDatatypes []VT)
 		uintptr(unsafe.Pointer(&pErrors)),
 	)
 	if int32(r0) < 0 {
-		return nil, syscall.Errno(r0)
+		return nil, HRESULT(r0)
 	}
 	defer func() {
 		CoTaskMemFree(pErrors)
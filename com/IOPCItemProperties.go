@@ -71,7 +71,7 @@ func (v *IOPCItemProperties) QueryAvailableProperties(szItemID string) (ppProper
 		uintptr(unsafe.Pointer(&pDescriptions)),
 		uintptr(unsafe.Pointer(&pvtDataTypes)))
 	if r0 != 0 {
-		err = syscall.Errno(r0)
+		err = HRESULT(r0)
 		return
 	}
 	defer func() {
@@ -105,7 +105,13 @@ func (v *IOPCItemProperties) QueryAvailableProperties(szItemID string) (ppProper
 // Example:
 //
 //	values, errors, err := prop.GetItemProperties("Random.Int4", []uint32{1, 2})
+//
+// A property whose value cannot be converted keeps the server's error code and holds a
+// *ErrUnsupportedVariant carrying the raw VT in place of its value.
 func (v *IOPCItemProperties) GetItemProperties(szItemID string, propertyIDs []uint32) (ppvData []interface{}, ppErrors []int32, err error) {
+	if len(propertyIDs) == 0 {
+		return
+	}
 	var pData unsafe.Pointer
 	var pErrors unsafe.Pointer
 	var pName *uint16
@@ -123,7 +129,7 @@ func (v *IOPCItemProperties) GetItemProperties(szItemID string, propertyIDs []ui
 		uintptr(unsafe.Pointer(&pData)),
 		uintptr(unsafe.Pointer(&pErrors)))
 	if r0 != 0 {
-		return nil, nil, syscall.Errno(r0)
+		return nil, nil, HRESULT(r0)
 	}
 	defer func() {
 		CoTaskMemFree(pData)
@@ -138,13 +144,7 @@ func (v *IOPCItemProperties) GetItemProperties(szItemID string, propertyIDs []ui
 		variant := *(*VARIANT)(unsafe.Pointer(uintptr(pData) + uintptr(i)*unsafe.Sizeof(VARIANT{})))
 		errNo := *(*int32)(unsafe.Pointer(uintptr(pErrors) + uintptr(i)*4))
 		if errNo >= 0 {
-			v, err := variant.Value()
-			if err != nil {
-				errNo = int32(0x80004005 - 0x100000000) // E_FAIL
-				ppvData[i] = nil
-			} else {
-				ppvData[i] = v
-			}
+			ppvData[i] = ValueOrUnsupported(&variant)
 		}
 		variant.Clear()
 		ppErrors[i] = int32(errNo)
@@ -153,11 +153,16 @@ func (v *IOPCItemProperties) GetItemProperties(szItemID string, propertyIDs []ui
 }
 
 // LookupItemIDs provides the ItemIDs for one or more properties of an item.
+// When the server reports S_FALSE, some properties failed; their entries have a failed HRESULT and an
+// empty item ID, and their item ID pointers are not read since servers may leave them uninitialized.
 //
 // Example:
 //
 //	itemIDs, errors, err := prop.LookupItemIDs("Random.Int4", []uint32{1})
 func (v *IOPCItemProperties) LookupItemIDs(szItemID string, propertyIDs []uint32) (ppszNewItemIDs []string, ppErrors []int32, err error) {
+	if len(propertyIDs) == 0 {
+		return
+	}
 	var pNewIDs unsafe.Pointer
 	var pErrors unsafe.Pointer
 	var pName *uint16
@@ -175,24 +180,27 @@ func (v *IOPCItemProperties) LookupItemIDs(szItemID string, propertyIDs []uint32
 		uintptr(unsafe.Pointer(&pNewIDs)),
 		uintptr(unsafe.Pointer(&pErrors)))
 	if int32(r0) < 0 {
-		return nil, nil, syscall.Errno(r0)
+		return nil, nil, HRESULT(r0)
 	}
 	defer func() {
 		CoTaskMemFree(pNewIDs)
 		CoTaskMemFree(pErrors)
 	}()
-	if count == 0 {
-		return
+	if pErrors == nil {
+		return nil, nil, HRESULT(E_FAIL)
 	}
 	ppszNewItemIDs = make([]string, count)
 	ppErrors = make([]int32, count)
 	for i := 0; i < count; i++ {
 		errNo := *(*int32)(unsafe.Pointer(uintptr(pErrors) + uintptr(i)*4))
 		ppErrors[i] = int32(errNo)
-		if errNo < 0 {
+		if errNo < 0 || pNewIDs == nil {
 			continue
 		}
 		pwstr := *(**uint16)(unsafe.Pointer(uintptr(pNewIDs) + uintptr(i)*pointerSize))
+		if pwstr == nil {
+			continue
+		}
 		ppszNewItemIDs[i] = windows.UTF16PtrToString(pwstr)
 		CoTaskMemFree(unsafe.Pointer(pwstr))
 	}
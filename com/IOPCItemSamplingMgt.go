@@ -0,0 +1,235 @@
+//go:build windows
+
+package com
+
+import (
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var IID_IOPCItemSamplingMgt = windows.GUID{
+	Data1: 0x3E22D313,
+	Data2: 0xF08B,
+	Data3: 0x41a5,
+	Data4: [8]byte{0x86, 0xC8, 0x95, 0xE9, 0x5C, 0xB4, 0x9F, 0xFC},
+}
+
+// IOPCItemSamplingMgtVtbl is the virtual function table for the IOPCItemSamplingMgt interface.
+type IOPCItemSamplingMgtVtbl struct {
+	IUnknownVtbl
+	// SetItemSamplingRate sets the sampling rate of one or more items.
+	SetItemSamplingRate uintptr
+	// GetItemSamplingRate retrieves the sampling rate of one or more items.
+	GetItemSamplingRate uintptr
+	// ClearItemSamplingRate makes one or more items use the group update rate again.
+	ClearItemSamplingRate uintptr
+	// SetItemBufferEnable enables or disables buffering of samples for one or more items.
+	SetItemBufferEnable uintptr
+	// GetItemBufferEnable retrieves the buffering state of one or more items.
+	GetItemBufferEnable uintptr
+}
+
+// IOPCItemSamplingMgt allows clients to control the sampling rate and buffering of individual items
+// as defined in the OPC Data Access Custom Interface Standard 3.0. It is an optional group interface.
+type IOPCItemSamplingMgt struct {
+	// IUnknown is the underlying COM interface.
+	*IUnknown
+}
+
+func (sl *IOPCItemSamplingMgt) Vtbl() *IOPCItemSamplingMgtVtbl {
+	return (*IOPCItemSamplingMgtVtbl)(unsafe.Pointer(sl.IUnknown.LpVtbl))
+}
+
+// SetItemSamplingRate sets the sampling rate of one or more items.
+//
+// Returns:
+//
+//	The sampling rates the server will actually use and a slice of HRESULTs (as int32).
+//
+// Example:
+//
+//	revised, errors, err := mgt.SetItemSamplingRate(serverHandles, []uint32{100, 100})
+func (sl *IOPCItemSamplingMgt) SetItemSamplingRate(phServer []uint32, pdwRequestedSamplingRate []uint32) ([]uint32, []int32, error) {
+	if err := checkBatchLengths("sampling rates", len(pdwRequestedSamplingRate), len(phServer)); err != nil {
+		return nil, nil, err
+	}
+	if len(phServer) == 0 {
+		return nil, nil, nil
+	}
+	dwCount := uint32(len(phServer))
+	var pRevisedSamplingRate unsafe.Pointer
+	var pErrors unsafe.Pointer
+	r0, _, _ := syscall.SyscallN(
+		sl.Vtbl().SetItemSamplingRate,
+		uintptr(unsafe.Pointer(sl.IUnknown)),
+		uintptr(dwCount),
+		uintptr(unsafe.Pointer(&phServer[0])),
+		uintptr(unsafe.Pointer(&pdwRequestedSamplingRate[0])),
+		uintptr(unsafe.Pointer(&pRevisedSamplingRate)),
+		uintptr(unsafe.Pointer(&pErrors)),
+	)
+	if int32(r0) < 0 {
+		return nil, nil, HRESULT(r0)
+	}
+	defer func() {
+		CoTaskMemFree(pRevisedSamplingRate)
+		CoTaskMemFree(pErrors)
+	}()
+	revised := make([]uint32, dwCount)
+	errors := make([]int32, dwCount)
+	for i := uint32(0); i < dwCount; i++ {
+		errors[i] = *(*int32)(unsafe.Pointer(uintptr(pErrors) + uintptr(i)*4))
+		if errors[i] >= 0 {
+			revised[i] = *(*uint32)(unsafe.Pointer(uintptr(pRevisedSamplingRate) + uintptr(i)*4))
+		}
+	}
+	return revised, errors, nil
+}
+
+// GetItemSamplingRate retrieves the sampling rate of one or more items.
+//
+// Example:
+//
+//	rates, errors, err := mgt.GetItemSamplingRate(serverHandles)
+func (sl *IOPCItemSamplingMgt) GetItemSamplingRate(phServer []uint32) ([]uint32, []int32, error) {
+	if len(phServer) == 0 {
+		return nil, nil, nil
+	}
+	dwCount := uint32(len(phServer))
+	var pSamplingRate unsafe.Pointer
+	var pErrors unsafe.Pointer
+	r0, _, _ := syscall.SyscallN(
+		sl.Vtbl().GetItemSamplingRate,
+		uintptr(unsafe.Pointer(sl.IUnknown)),
+		uintptr(dwCount),
+		uintptr(unsafe.Pointer(&phServer[0])),
+		uintptr(unsafe.Pointer(&pSamplingRate)),
+		uintptr(unsafe.Pointer(&pErrors)),
+	)
+	if int32(r0) < 0 {
+		return nil, nil, HRESULT(r0)
+	}
+	defer func() {
+		CoTaskMemFree(pSamplingRate)
+		CoTaskMemFree(pErrors)
+	}()
+	rates := make([]uint32, dwCount)
+	errors := make([]int32, dwCount)
+	for i := uint32(0); i < dwCount; i++ {
+		errors[i] = *(*int32)(unsafe.Pointer(uintptr(pErrors) + uintptr(i)*4))
+		if errors[i] >= 0 {
+			rates[i] = *(*uint32)(unsafe.Pointer(uintptr(pSamplingRate) + uintptr(i)*4))
+		}
+	}
+	return rates, errors, nil
+}
+
+// ClearItemSamplingRate makes one or more items use the group update rate again.
+//
+// Example:
+//
+//	errors, err := mgt.ClearItemSamplingRate(serverHandles)
+func (sl *IOPCItemSamplingMgt) ClearItemSamplingRate(phServer []uint32) ([]int32, error) {
+	if len(phServer) == 0 {
+		return nil, nil
+	}
+	dwCount := uint32(len(phServer))
+	var pErrors unsafe.Pointer
+	r0, _, _ := syscall.SyscallN(
+		sl.Vtbl().ClearItemSamplingRate,
+		uintptr(unsafe.Pointer(sl.IUnknown)),
+		uintptr(dwCount),
+		uintptr(unsafe.Pointer(&phServer[0])),
+		uintptr(unsafe.Pointer(&pErrors)),
+	)
+	if int32(r0) < 0 {
+		return nil, HRESULT(r0)
+	}
+	defer func() {
+		CoTaskMemFree(pErrors)
+	}()
+	errors := make([]int32, dwCount)
+	for i := uint32(0); i < dwCount; i++ {
+		errors[i] = *(*int32)(unsafe.Pointer(uintptr(pErrors) + uintptr(i)*4))
+	}
+	return errors, nil
+}
+
+// SetItemBufferEnable enables or disables buffering of samples for one or more items.
+//
+// Example:
+//
+//	errors, err := mgt.SetItemBufferEnable(serverHandles, []bool{true, false})
+func (sl *IOPCItemSamplingMgt) SetItemBufferEnable(phServer []uint32, pbEnable []bool) ([]int32, error) {
+	if err := checkBatchLengths("enable flags", len(pbEnable), len(phServer)); err != nil {
+		return nil, err
+	}
+	if len(phServer) == 0 {
+		return nil, nil
+	}
+	dwCount := uint32(len(phServer))
+	enable := make([]int32, dwCount)
+	for i := range enable {
+		enable[i] = BoolToComBOOL(pbEnable[i])
+	}
+	var pErrors unsafe.Pointer
+	r0, _, _ := syscall.SyscallN(
+		sl.Vtbl().SetItemBufferEnable,
+		uintptr(unsafe.Pointer(sl.IUnknown)),
+		uintptr(dwCount),
+		uintptr(unsafe.Pointer(&phServer[0])),
+		uintptr(unsafe.Pointer(&enable[0])),
+		uintptr(unsafe.Pointer(&pErrors)),
+	)
+	if int32(r0) < 0 {
+		return nil, HRESULT(r0)
+	}
+	defer func() {
+		CoTaskMemFree(pErrors)
+	}()
+	errors := make([]int32, dwCount)
+	for i := uint32(0); i < dwCount; i++ {
+		errors[i] = *(*int32)(unsafe.Pointer(uintptr(pErrors) + uintptr(i)*4))
+	}
+	return errors, nil
+}
+
+// GetItemBufferEnable retrieves the buffering state of one or more items.
+//
+// Example:
+//
+//	enabled, errors, err := mgt.GetItemBufferEnable(serverHandles)
+func (sl *IOPCItemSamplingMgt) GetItemBufferEnable(phServer []uint32) ([]bool, []int32, error) {
+	if len(phServer) == 0 {
+		return nil, nil, nil
+	}
+	dwCount := uint32(len(phServer))
+	var pEnable unsafe.Pointer
+	var pErrors unsafe.Pointer
+	r0, _, _ := syscall.SyscallN(
+		sl.Vtbl().GetItemBufferEnable,
+		uintptr(unsafe.Pointer(sl.IUnknown)),
+		uintptr(dwCount),
+		uintptr(unsafe.Pointer(&phServer[0])),
+		uintptr(unsafe.Pointer(&pEnable)),
+		uintptr(unsafe.Pointer(&pErrors)),
+	)
+	if int32(r0) < 0 {
+		return nil, nil, HRESULT(r0)
+	}
+	defer func() {
+		CoTaskMemFree(pEnable)
+		CoTaskMemFree(pErrors)
+	}()
+	enabled := make([]bool, dwCount)
+	errors := make([]int32, dwCount)
+	for i := uint32(0); i < dwCount; i++ {
+		errors[i] = *(*int32)(unsafe.Pointer(uintptr(pErrors) + uintptr(i)*4))
+		if errors[i] >= 0 {
+			enabled[i] = *(*int32)(unsafe.Pointer(uintptr(pEnable) + uintptr(i)*4)) != 0
+		}
+	}
+	return enabled, errors, nil
+}
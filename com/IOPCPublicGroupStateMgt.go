@@ -0,0 +1,75 @@
+//go:build windows
+
+package com
+
+import (
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var IID_IOPCPublicGroupStateMgt = windows.GUID{
+	Data1: 0x39c13a51,
+	Data2: 0x011e,
+	Data3: 0x11d0,
+	Data4: [8]byte{0x96, 0x75, 0x00, 0x20, 0xaf, 0xd8, 0xad, 0xb3},
+}
+
+// IOPCPublicGroupStateMgtVtbl is the virtual function table for the IOPCPublicGroupStateMgt interface.
+type IOPCPublicGroupStateMgtVtbl struct {
+	IUnknownVtbl
+	// GetState reports whether the group is public.
+	GetState uintptr
+	// MoveToPublic converts a private group into a public group.
+	MoveToPublic uintptr
+}
+
+// IOPCPublicGroupStateMgt turns a private group into a public group, as defined in the OPC Data Access
+// Custom Interface Standard 2.05. It is an optional group interface of servers supporting public groups.
+type IOPCPublicGroupStateMgt struct {
+	// IUnknown is the underlying COM interface.
+	*IUnknown
+}
+
+func (v *IOPCPublicGroupStateMgt) Vtbl() *IOPCPublicGroupStateMgtVtbl {
+	return (*IOPCPublicGroupStateMgtVtbl)(unsafe.Pointer(v.IUnknown.LpVtbl))
+}
+
+// GetState reports whether the group is public.
+//
+// Example:
+//
+//	public, err := publicState.GetState()
+func (v *IOPCPublicGroupStateMgt) GetState() (pPublic bool, err error) {
+	var bPublic int32
+	r0, _, _ := syscall.SyscallN(
+		v.Vtbl().GetState,
+		uintptr(unsafe.Pointer(v.IUnknown)),
+		uintptr(unsafe.Pointer(&bPublic)),
+	)
+	if int32(r0) < 0 {
+		err = HRESULT(r0)
+		return
+	}
+	pPublic = bPublic != 0
+	return
+}
+
+// MoveToPublic converts the private group into a public group. The server fails it with
+// OPC_E_DUPLICATENAME if a public group of the same name exists.
+//
+// Example:
+//
+//	err := publicState.MoveToPublic()
+func (v *IOPCPublicGroupStateMgt) MoveToPublic() (err error) {
+	r0, _, _ := syscall.SyscallN(
+		v.Vtbl().MoveToPublic,
+		uintptr(unsafe.Pointer(v.IUnknown)),
+	)
+	if int32(r0) < 0 {
+		err = HRESULT(r0)
+		return
+	}
+	return
+}
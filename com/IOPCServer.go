@@ -93,9 +93,10 @@ func (v *IOPCServer) AddGroup(
 		uintptr(unsafe.Pointer(&pUnk)),
 	)
 	if int32(r0) < 0 {
-		err = syscall.Errno(r0)
+		err = HRESULT(r0)
 		return
 	}
+	trackAcquire(pUnk, riid)
 	ppUnk = pUnk
 	return
 }
@@ -178,7 +179,7 @@ func (v *IOPCServer) GetStatus() (status *ServerStatus, err error) {
 		uintptr(unsafe.Pointer(&pStatus)),
 	)
 	if int32(r0) < 0 {
-		err = syscall.Errno(r0)
+		err = HRESULT(r0)
 		return
 	}
 	defer func() {
@@ -205,6 +206,60 @@ func (v *IOPCServer) GetStatus() (status *ServerStatus, err error) {
 	return
 }
 
+// GetGroupByName returns the riid interface of the private group szName of this client connection.
+// The server fails it with E_INVALIDARG if the connection has no group of that name.
+//
+// Example:
+//
+//	pUnk, err := server.GetGroupByName("Group1", &com.IID_IOPCGroupStateMgt)
+func (v *IOPCServer) GetGroupByName(szName string, riid *windows.GUID) (ppUnk *IUnknown, err error) {
+	var pUnk *IUnknown
+	var pName *uint16
+	pName, err = syscall.UTF16PtrFromString(szName)
+	if err != nil {
+		return
+	}
+	r0, _, _ := syscall.SyscallN(
+		v.Vtbl().GetGroupByName,
+		uintptr(unsafe.Pointer(v.IUnknown)),
+		uintptr(unsafe.Pointer(pName)),
+		uintptr(unsafe.Pointer(riid)),
+		uintptr(unsafe.Pointer(&pUnk)),
+	)
+	if int32(r0) < 0 {
+		err = HRESULT(r0)
+		return
+	}
+	trackAcquire(pUnk, riid)
+	ppUnk = pUnk
+	return
+}
+
+// CreateGroupEnumerator returns the riid interface, IID_IEnumUnknown or IID_IEnumString, of an enumerator
+// over the groups in dwScope, one of the OPC_ENUM constants of the opcda package. The server returns no
+// enumerator, and ppUnk is nil, if the scope holds no groups.
+//
+// Example:
+//
+//	pUnk, err := server.CreateGroupEnumerator(6, &com.IID_IEnumUnknown) // OPC_ENUM_ALL
+func (v *IOPCServer) CreateGroupEnumerator(dwScope uint32, riid *windows.GUID) (ppUnk *IUnknown, err error) {
+	var pUnk *IUnknown
+	r0, _, _ := syscall.SyscallN(
+		v.Vtbl().CreateGroupEnumerator,
+		uintptr(unsafe.Pointer(v.IUnknown)),
+		uintptr(dwScope),
+		uintptr(unsafe.Pointer(riid)),
+		uintptr(unsafe.Pointer(&pUnk)),
+	)
+	if int32(r0) < 0 {
+		err = HRESULT(r0)
+		return
+	}
+	trackAcquire(pUnk, riid)
+	ppUnk = pUnk
+	return
+}
+
 // RemoveGroup removes an OPC group from the server.
 //
 // Example:
@@ -219,7 +274,7 @@ func (v *IOPCServer) RemoveGroup(hServerGroup uint32, bForce bool) (err error) {
 		0,
 	)
 	if int32(r0) < 0 {
-		err = syscall.Errno(r0)
+		err = HRESULT(r0)
 		return
 	}
 	return
@@ -55,14 +55,15 @@ func (sl *IOPCServerList) EnumClassesOfCategories(rgcatidImpl []windows.GUID, rg
 	cRequired := uint32(len(rgcatidReq))
 	var iUnknown *IUnknown
 	if cRequired == 0 {
-		r0, _, _ = syscall.SyscallN(sl.Vtbl().EnumClassesOfCategories, uintptr(unsafe.Pointer(sl.IUnknown)), uintptr(cImplemented), uintptr(unsafe.Pointer(&rgcatidImpl[0])), uintptr(0), uintptr(unsafe.Pointer(nil)), uintptr(unsafe.Pointer(&iUnknown)))
+		r0, _, _ = syscall.SyscallN(sl.Vtbl().EnumClassesOfCategories, uintptr(unsafe.Pointer(sl.IUnknown)), uintptr(cImplemented), uintptr(unsafe.Pointer(firstGUID(rgcatidImpl))), uintptr(0), uintptr(unsafe.Pointer(nil)), uintptr(unsafe.Pointer(&iUnknown)))
 	} else {
-		r0, _, _ = syscall.SyscallN(sl.Vtbl().EnumClassesOfCategories, uintptr(unsafe.Pointer(sl.IUnknown)), uintptr(cImplemented), uintptr(unsafe.Pointer(&rgcatidImpl[0])), uintptr(cRequired), uintptr(unsafe.Pointer(&rgcatidReq[0])), uintptr(unsafe.Pointer(&iUnknown)))
+		r0, _, _ = syscall.SyscallN(sl.Vtbl().EnumClassesOfCategories, uintptr(unsafe.Pointer(sl.IUnknown)), uintptr(cImplemented), uintptr(unsafe.Pointer(firstGUID(rgcatidImpl))), uintptr(cRequired), uintptr(unsafe.Pointer(&rgcatidReq[0])), uintptr(unsafe.Pointer(&iUnknown)))
 	}
 	if r0 != 0 {
-		err = syscall.Errno(r0)
+		err = HRESULT(r0)
 		return
 	}
+	trackAcquireName(iUnknown, "IEnumGUID")
 	ppenumClsid = &IEnumGUID{IUnknown: iUnknown}
 	return
 }
@@ -76,7 +77,7 @@ func (sl *IOPCServerList) GetClassDetails(guid *windows.GUID) (*uint16, *uint16,
 	var ppszProgID, ppszUserType *uint16
 	r0, _, _ := syscall.SyscallN(sl.Vtbl().GetClassDetails, uintptr(unsafe.Pointer(sl.IUnknown)), uintptr(unsafe.Pointer(guid)), uintptr(unsafe.Pointer(&ppszProgID)), uintptr(unsafe.Pointer(&ppszUserType)))
 	if r0 != 0 {
-		return nil, nil, syscall.Errno(r0)
+		return nil, nil, HRESULT(r0)
 	}
 	return ppszProgID, ppszUserType, nil
 }
@@ -94,7 +95,15 @@ func (sl *IOPCServerList) CLSIDFromProgID(szProgID string) (*windows.GUID, error
 	}
 	r0, _, _ := syscall.SyscallN(sl.Vtbl().CLSIDFromProgID, uintptr(unsafe.Pointer(sl.IUnknown)), uintptr(unsafe.Pointer(pProgID)), uintptr(unsafe.Pointer(&clsid)))
 	if r0 != 0 {
-		return nil, syscall.Errno(r0)
+		return nil, HRESULT(r0)
 	}
 	return &clsid, nil
 }
+
+// firstGUID returns the address of the first category ID, or nil when there is none.
+func firstGUID(cids []windows.GUID) *windows.GUID {
+	if len(cids) == 0 {
+		return nil
+	}
+	return &cids[0]
+}
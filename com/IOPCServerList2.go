@@ -37,14 +37,15 @@ func (sl *IOPCServerList2) EnumClassesOfCategories(rgcatidImpl []windows.GUID, r
 	cRequired := uint32(len(rgcatidReq))
 	var iUnknown *IUnknown
 	if cRequired == 0 {
-		r0, _, _ = syscall.SyscallN(sl.Vtbl().EnumClassesOfCategories, uintptr(unsafe.Pointer(sl.IUnknown)), uintptr(cImplemented), uintptr(unsafe.Pointer(&rgcatidImpl[0])), uintptr(0), uintptr(unsafe.Pointer(nil)), uintptr(unsafe.Pointer(&iUnknown)))
+		r0, _, _ = syscall.SyscallN(sl.Vtbl().EnumClassesOfCategories, uintptr(unsafe.Pointer(sl.IUnknown)), uintptr(cImplemented), uintptr(unsafe.Pointer(firstGUID(rgcatidImpl))), uintptr(0), uintptr(unsafe.Pointer(nil)), uintptr(unsafe.Pointer(&iUnknown)))
 	} else {
-		r0, _, _ = syscall.SyscallN(sl.Vtbl().EnumClassesOfCategories, uintptr(unsafe.Pointer(sl.IUnknown)), uintptr(cImplemented), uintptr(unsafe.Pointer(&rgcatidImpl[0])), uintptr(cRequired), uintptr(unsafe.Pointer(&rgcatidReq[0])), uintptr(unsafe.Pointer(&iUnknown)))
+		r0, _, _ = syscall.SyscallN(sl.Vtbl().EnumClassesOfCategories, uintptr(unsafe.Pointer(sl.IUnknown)), uintptr(cImplemented), uintptr(unsafe.Pointer(firstGUID(rgcatidImpl))), uintptr(cRequired), uintptr(unsafe.Pointer(&rgcatidReq[0])), uintptr(unsafe.Pointer(&iUnknown)))
 	}
 	if r0 != 0 {
-		err = syscall.Errno(r0)
+		err = HRESULT(r0)
 		return
 	}
+	trackAcquireName(iUnknown, "IEnumGUID")
 	ppenumClsid = &IEnumGUID{IUnknown: iUnknown}
 	return
 }
@@ -58,7 +59,7 @@ func (sl *IOPCServerList2) GetClassDetails(guid *windows.GUID) (*uint16, *uint16
 	var ppszProgID, ppszUserType, ppszVerIndProgID *uint16
 	r0, _, _ := syscall.SyscallN(sl.Vtbl().GetClassDetails, uintptr(unsafe.Pointer(sl.IUnknown)), uintptr(unsafe.Pointer(guid)), uintptr(unsafe.Pointer(&ppszProgID)), uintptr(unsafe.Pointer(&ppszUserType)), uintptr(unsafe.Pointer(&ppszVerIndProgID)))
 	if r0 != 0 {
-		return nil, nil, nil, syscall.Errno(r0)
+		return nil, nil, nil, HRESULT(r0)
 	}
 	return ppszProgID, ppszUserType, ppszVerIndProgID, nil
 }
@@ -76,7 +77,7 @@ func (sl *IOPCServerList2) CLSIDFromProgID(szProgID string) (*windows.GUID, erro
 	}
 	r0, _, _ := syscall.SyscallN(sl.Vtbl().CLSIDFromProgID, uintptr(unsafe.Pointer(sl.IUnknown)), uintptr(unsafe.Pointer(pProgID)), uintptr(unsafe.Pointer(&clsid)))
 	if r0 != 0 {
-		return nil, syscall.Errno(r0)
+		return nil, HRESULT(r0)
 	}
 	return &clsid, nil
 }
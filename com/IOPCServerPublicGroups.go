@@ -0,0 +1,87 @@
+//go:build windows
+
+package com
+
+import (
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var IID_IOPCServerPublicGroups = windows.GUID{
+	Data1: 0x39c13a4e,
+	Data2: 0x011e,
+	Data3: 0x11d0,
+	Data4: [8]byte{0x96, 0x75, 0x00, 0x20, 0xaf, 0xd8, 0xad, 0xb3},
+}
+
+// IOPCServerPublicGroupsVtbl is the virtual function table for the IOPCServerPublicGroups interface.
+type IOPCServerPublicGroupsVtbl struct {
+	IUnknownVtbl
+	// GetPublicGroupByName connects to an existing public group by its name.
+	GetPublicGroupByName uintptr
+	// RemovePublicGroup removes a public group from the server.
+	RemovePublicGroup uintptr
+}
+
+// IOPCServerPublicGroups gives access to the public groups of a server, which are defined on the server
+// and shared by its clients, as defined in the OPC Data Access Custom Interface Standard 2.05.
+// It is an optional server interface.
+type IOPCServerPublicGroups struct {
+	// IUnknown is the underlying COM interface.
+	*IUnknown
+}
+
+func (v *IOPCServerPublicGroups) Vtbl() *IOPCServerPublicGroupsVtbl {
+	return (*IOPCServerPublicGroupsVtbl)(unsafe.Pointer(v.IUnknown.LpVtbl))
+}
+
+// GetPublicGroupByName connects to the public group szName and returns the riid interface of the new
+// connection. Each call creates a connection of its own, which the client removes with IOPCServer.RemoveGroup.
+//
+// Example:
+//
+//	pUnk, err := publicGroups.GetPublicGroupByName("Plant", &com.IID_IOPCGroupStateMgt)
+func (v *IOPCServerPublicGroups) GetPublicGroupByName(szName string, riid *windows.GUID) (ppUnk *IUnknown, err error) {
+	var pUnk *IUnknown
+	var pName *uint16
+	pName, err = syscall.UTF16PtrFromString(szName)
+	if err != nil {
+		return
+	}
+	r0, _, _ := syscall.SyscallN(
+		v.Vtbl().GetPublicGroupByName,
+		uintptr(unsafe.Pointer(v.IUnknown)),
+		uintptr(unsafe.Pointer(pName)),
+		uintptr(unsafe.Pointer(riid)),
+		uintptr(unsafe.Pointer(&pUnk)),
+	)
+	if int32(r0) < 0 {
+		err = HRESULT(r0)
+		return
+	}
+	trackAcquire(pUnk, riid)
+	ppUnk = pUnk
+	return
+}
+
+// RemovePublicGroup removes the public group hServerGroup from the server. bForce removes it even while
+// clients are connected to it.
+//
+// Example:
+//
+//	err := publicGroups.RemovePublicGroup(hServerGroup, false)
+func (v *IOPCServerPublicGroups) RemovePublicGroup(hServerGroup uint32, bForce bool) (err error) {
+	r0, _, _ := syscall.SyscallN(
+		v.Vtbl().RemovePublicGroup,
+		uintptr(unsafe.Pointer(v.IUnknown)),
+		uintptr(hServerGroup),
+		uintptr(BoolToComBOOL(bForce)),
+	)
+	if int32(r0) < 0 {
+		err = HRESULT(r0)
+		return
+	}
+	return
+}
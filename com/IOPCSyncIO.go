@@ -67,6 +67,8 @@ type ItemState struct {
 }
 
 // Read performs a synchronous read of one or more items in the group.
+// A value whose VARIANT type cannot be converted is returned as *ErrUnsupportedVariant in its ItemState,
+// with the result code of the server unchanged.
 //
 // Parameters:
 //
@@ -77,6 +79,9 @@ type ItemState struct {
 //
 //	states, errors, err := syncIO.Read(com.OPC_DS_CACHE, serverHandles)
 func (sl *IOPCSyncIO) Read(source OPCDATASOURCE, serverHandles []uint32) ([]*ItemState, []int32, error) {
+	if len(serverHandles) == 0 {
+		return nil, nil, nil
+	}
 	var pErrors unsafe.Pointer
 	var pValues unsafe.Pointer
 	count := len(serverHandles)
@@ -90,7 +95,7 @@ func (sl *IOPCSyncIO) Read(source OPCDATASOURCE, serverHandles []uint32) ([]*Ite
 		uintptr(unsafe.Pointer(&pErrors)),
 	)
 	if int32(r0) < 0 {
-		return nil, nil, syscall.Errno(r0)
+		return nil, nil, HRESULT(r0)
 	}
 	defer func() {
 		CoTaskMemFree(pErrors)
@@ -102,22 +107,11 @@ func (sl *IOPCSyncIO) Read(source OPCDATASOURCE, serverHandles []uint32) ([]*Ite
 		errNo := *(*int32)(unsafe.Pointer(uintptr(pErrors) + uintptr(i)*4))
 		value := *(*TagOPCITEMSTATE)(unsafe.Pointer(uintptr(pValues) + uintptr(i)*unsafe.Sizeof(TagOPCITEMSTATE{})))
 		if errNo >= 0 {
-			v, err := value.VDataValue.Value()
-			if err != nil {
-				errNo = int32(0x80004005 - 0x100000000) // E_FAIL
-				returnValues[i] = &ItemState{
-					Value:        nil,
-					Quality:      value.WQuality,
-					Timestamp:    time.Unix(0, value.FTimestamp.Nanoseconds()),
-					ClientHandle: int32(value.HClient),
-				}
-			} else {
-				returnValues[i] = &ItemState{
-					Value:        v,
-					Quality:      value.WQuality,
-					Timestamp:    time.Unix(0, value.FTimestamp.Nanoseconds()),
-					ClientHandle: int32(value.HClient),
-				}
+			returnValues[i] = &ItemState{
+				Value:        ValueOrUnsupported(&value.VDataValue),
+				Quality:      value.WQuality,
+				Timestamp:    time.Unix(0, value.FTimestamp.Nanoseconds()),
+				ClientHandle: int32(value.HClient),
 			}
 		}
 		value.VDataValue.Clear()
@@ -131,12 +125,18 @@ func (sl *IOPCSyncIO) Read(source OPCDATASOURCE, serverHandles []uint32) ([]*Ite
 // Parameters:
 //
 //	serverHandles: Server handles of the items to write.
-//	values: A slice of VARIANTs containing the values to write.
+//	values: A slice of VARIANTs containing the values to write, one per server handle.
 //
 // Example:
 //
 //	errors, err := syncIO.Write(serverHandles, variants)
 func (sl *IOPCSyncIO) Write(serverHandles []uint32, values []VARIANT) ([]int32, error) {
+	if err := checkBatchLengths("values", len(values), len(serverHandles)); err != nil {
+		return nil, err
+	}
+	if len(serverHandles) == 0 {
+		return nil, nil
+	}
 	var pErrors unsafe.Pointer
 	count := len(serverHandles)
 	r0, _, _ := syscall.SyscallN(
@@ -148,7 +148,7 @@ func (sl *IOPCSyncIO) Write(serverHandles []uint32, values []VARIANT) ([]int32,
 		uintptr(unsafe.Pointer(&pErrors)),
 	)
 	if int32(r0) < 0 {
-		return nil, syscall.Errno(r0)
+		return nil, HRESULT(r0)
 	}
 	defer func() {
 		CoTaskMemFree(pErrors)
@@ -0,0 +1,145 @@
+//go:build windows
+
+package com
+
+import (
+	"syscall"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var IID_IOPCSyncIO2 = windows.GUID{
+	Data1: 0x730F5F0F,
+	Data2: 0x55B1,
+	Data3: 0x4c81,
+	Data4: [8]byte{0x9E, 0x18, 0xFF, 0x8A, 0x09, 0x04, 0xE1, 0xFA},
+}
+
+// IOPCSyncIO2Vtbl is the virtual function table for the IOPCSyncIO2 interface.
+type IOPCSyncIO2Vtbl struct {
+	IOPCSyncIOVtbl
+	// ReadMaxAge reads one or more items from the cache or the device, depending on the age of the cached values.
+	ReadMaxAge uintptr
+	// WriteVQT writes values, qualities and timestamps to one or more items.
+	WriteVQT uintptr
+}
+
+// IOPCSyncIO2 extends IOPCSyncIO with reads bounded by the age of the cached values, as defined in the
+// OPC Data Access Custom Interface Standard 3.0. It is an optional group interface.
+type IOPCSyncIO2 struct {
+	// IUnknown is the underlying COM interface.
+	*IUnknown
+}
+
+func (sl *IOPCSyncIO2) Vtbl() *IOPCSyncIO2Vtbl {
+	return (*IOPCSyncIO2Vtbl)(unsafe.Pointer(sl.IUnknown.LpVtbl))
+}
+
+// ReadMaxAge reads one or more items in the group. For each item the server returns its cached value if
+// it is not older than the maximum age, and reads the device otherwise.
+// A value whose VARIANT type cannot be converted is returned as *ErrUnsupportedVariant in its ItemState,
+// with the result code of the server unchanged.
+//
+// Parameters:
+//
+//	serverHandles: Server handles of the items to read.
+//	maxAge: The maximum age in milliseconds of the cached value for each item; 0 reads from the device
+//	and 0xFFFFFFFF reads from the cache.
+//
+// Returns:
+//
+//	The item states, without client handles, and a slice of HRESULTs (as int32).
+//
+// Example:
+//
+//	states, errors, err := syncIO2.ReadMaxAge(serverHandles, []uint32{1000, 1000})
+func (sl *IOPCSyncIO2) ReadMaxAge(serverHandles []uint32, maxAge []uint32) ([]*ItemState, []int32, error) {
+	if err := checkBatchLengths("max ages", len(maxAge), len(serverHandles)); err != nil {
+		return nil, nil, err
+	}
+	if len(serverHandles) == 0 {
+		return nil, nil, nil
+	}
+	count := uint32(len(serverHandles))
+	var pValues unsafe.Pointer
+	var pQualities unsafe.Pointer
+	var pTimeStamps unsafe.Pointer
+	var pErrors unsafe.Pointer
+	r0, _, _ := syscall.SyscallN(
+		sl.Vtbl().ReadMaxAge,
+		uintptr(unsafe.Pointer(sl.IUnknown)),
+		uintptr(count),
+		uintptr(unsafe.Pointer(&serverHandles[0])),
+		uintptr(unsafe.Pointer(&maxAge[0])),
+		uintptr(unsafe.Pointer(&pValues)),
+		uintptr(unsafe.Pointer(&pQualities)),
+		uintptr(unsafe.Pointer(&pTimeStamps)),
+		uintptr(unsafe.Pointer(&pErrors)),
+	)
+	if int32(r0) < 0 {
+		return nil, nil, HRESULT(r0)
+	}
+	defer func() {
+		CoTaskMemFree(pValues)
+		CoTaskMemFree(pQualities)
+		CoTaskMemFree(pTimeStamps)
+		CoTaskMemFree(pErrors)
+	}()
+	states := make([]*ItemState, count)
+	errors := make([]int32, count)
+	for i := uint32(0); i < count; i++ {
+		errNo := *(*int32)(unsafe.Pointer(uintptr(pErrors) + uintptr(i)*4))
+		variant := (*VARIANT)(unsafe.Pointer(uintptr(pValues) + uintptr(i)*unsafe.Sizeof(VARIANT{})))
+		if errNo >= 0 {
+			v := ValueOrUnsupported(variant)
+			ft := *(*windows.Filetime)(unsafe.Pointer(uintptr(pTimeStamps) + uintptr(i)*unsafe.Sizeof(windows.Filetime{})))
+			states[i] = &ItemState{
+				Value:     v,
+				Quality:   *(*uint16)(unsafe.Pointer(uintptr(pQualities) + uintptr(i)*2)),
+				Timestamp: time.Unix(0, ft.Nanoseconds()),
+			}
+		}
+		variant.Clear()
+		errors[i] = errNo
+	}
+	return states, errors, nil
+}
+
+// WriteVQT writes values, and optionally qualities and timestamps, to one or more items in the group.
+//
+// Returns:
+//
+//	A slice of HRESULTs (as int32), one per item.
+//
+// Example:
+//
+//	errors, err := syncIO2.WriteVQT(serverHandles, vqts)
+func (sl *IOPCSyncIO2) WriteVQT(serverHandles []uint32, values []TagOPCITEMVQT) ([]int32, error) {
+	if err := checkBatchLengths("values", len(values), len(serverHandles)); err != nil {
+		return nil, err
+	}
+	if len(serverHandles) == 0 {
+		return nil, nil
+	}
+	count := uint32(len(serverHandles))
+	var pErrors unsafe.Pointer
+	r0, _, _ := syscall.SyscallN(
+		sl.Vtbl().WriteVQT,
+		uintptr(unsafe.Pointer(sl.IUnknown)),
+		uintptr(count),
+		uintptr(unsafe.Pointer(&serverHandles[0])),
+		uintptr(unsafe.Pointer(&values[0])),
+		uintptr(unsafe.Pointer(&pErrors)),
+	)
+	if int32(r0) < 0 {
+		return nil, HRESULT(r0)
+	}
+	defer CoTaskMemFree(pErrors)
+	errors := make([]int32, count)
+	for i := uint32(0); i < count; i++ {
+		errors[i] = *(*int32)(unsafe.Pointer(uintptr(pErrors) + uintptr(i)*4))
+	}
+	return errors, nil
+}
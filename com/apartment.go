@@ -0,0 +1,137 @@
+//go:build windows
+
+package com
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+
+	"golang.org/x/sys/windows"
+)
+
+// ErrApartmentClosed is returned by Apartment.Do once the apartment was closed.
+var ErrApartmentClosed = errors.New("apartment closed")
+
+// Apartment runs functions on a single, dedicated OS thread with COM initialized on it.
+//
+// COM interface pointers belong to the apartment of the thread that obtained them. Goroutines move between
+// OS threads freely, so calls made from arbitrary goroutines reach the server from changing threads, which
+// some servers reject with RPC_E_WRONG_THREAD or mishandle silently. An Apartment locks one goroutine to
+// its thread with runtime.LockOSThread and executes every function passed to Do on that thread, one at a
+// time and in the order Do was called, so all calls through it come from one thread and never overlap.
+//
+// The thread joins the multithreaded apartment, as Initialize does. A single-threaded apartment would also
+// need a message loop on the thread to receive callbacks, which Apartment does not run; callbacks from the
+// server therefore arrive on RPC threads of the multithreaded apartment, not on the thread of the Apartment.
+type Apartment struct {
+	calls     chan func()
+	closed    chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+	threadID  uint32 // threadID is the OS thread of the apartment, set before NewApartmentWithInit returns.
+}
+
+// NewApartment starts the dedicated thread and initializes COM on it with InitializeWithResult and config.
+// Call Close once the COM objects used through the apartment have been released.
+//
+// Example:
+//
+//	config := com.DefaultInitConfig()
+//	config.TolerateExisting = true
+//	apartment, err := com.NewApartment(config)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer apartment.Close()
+//	err = apartment.Do(func() { status, err = server.GetStatus() })
+func NewApartment(config *InitConfig) (*Apartment, error) {
+	return NewApartmentWithInit(func() (func(), error) {
+		result, err := InitializeWithResult(config)
+		if err != nil {
+			return nil, err
+		}
+		if !result.NeedsUninitialize {
+			return func() {}, nil
+		}
+		return Uninitialize, nil
+	})
+}
+
+// NewApartmentWithInit starts the dedicated thread and calls init on it in place of the COM initialization
+// of NewApartment, for hosts that initialize COM their own way. init returns the function that undoes the
+// initialization; it is called on the same thread when the apartment is closed.
+func NewApartmentWithInit(init func() (uninit func(), err error)) (*Apartment, error) {
+	a := &Apartment{
+		calls:  make(chan func()),
+		closed: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	ready := make(chan error, 1)
+	go a.run(init, ready)
+	if err := <-ready; err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// run locks the calling goroutine to its thread, initializes COM and executes calls until Close.
+func (a *Apartment) run(init func() (func(), error), ready chan<- error) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	defer close(a.done)
+	a.threadID = windows.GetCurrentThreadId()
+	uninit, err := init()
+	if err != nil {
+		ready <- err
+		return
+	}
+	ready <- nil
+	defer uninit()
+	for {
+		select {
+		case fn := <-a.calls:
+			fn()
+		case <-a.closed:
+			return
+		}
+	}
+}
+
+// Do runs fn on the thread of the apartment and waits for it to return. Calls from several goroutines
+// are serialized. Called from a function running on the thread, such as from fn, Do runs fn directly.
+// It returns ErrApartmentClosed without running fn once the apartment was closed.
+func (a *Apartment) Do(fn func()) error {
+	if a == nil {
+		return errors.New("uninitialized apartment")
+	}
+	select {
+	case <-a.closed:
+		return ErrApartmentClosed
+	default:
+	}
+	// until the apartment is closed the thread is locked to its goroutine, so only that goroutine can be on it
+	if windows.GetCurrentThreadId() == a.threadID {
+		fn()
+		return nil
+	}
+	finished := make(chan struct{})
+	select {
+	case a.calls <- func() { defer close(finished); fn() }:
+	case <-a.closed:
+		return ErrApartmentClosed
+	}
+	<-finished
+	return nil
+}
+
+// Close stops the thread of the apartment after the running call, if any, and undoes the COM
+// initialization on it. It is safe to call Close more than once.
+func (a *Apartment) Close() error {
+	if a == nil {
+		return nil
+	}
+	a.closeOnce.Do(func() { close(a.closed) })
+	<-a.done
+	return nil
+}
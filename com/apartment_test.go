@@ -0,0 +1,70 @@
+//go:build windows
+
+package com
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/windows"
+)
+
+func TestApartment(t *testing.T) {
+	var initThread, uninitThread uint32
+	apartment, err := NewApartmentWithInit(func() (func(), error) {
+		initThread = windows.GetCurrentThreadId()
+		return func() { uninitThread = windows.GetCurrentThreadId() }, nil
+	})
+	assert.NoError(t, err)
+
+	var mu sync.Mutex
+	threads := make(map[uint32]bool)
+	var running, overlapped int
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, apartment.Do(func() {
+				mu.Lock()
+				threads[windows.GetCurrentThreadId()] = true
+				running++
+				if running > 1 {
+					overlapped++
+				}
+				mu.Unlock()
+				mu.Lock()
+				running--
+				mu.Unlock()
+			}))
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, map[uint32]bool{initThread: true}, threads)
+	assert.Zero(t, overlapped)
+
+	// a nested call runs on the thread directly instead of deadlocking
+	var nestedThread uint32
+	assert.NoError(t, apartment.Do(func() {
+		assert.NoError(t, apartment.Do(func() { nestedThread = windows.GetCurrentThreadId() }))
+	}))
+	assert.Equal(t, initThread, nestedThread)
+
+	assert.NoError(t, apartment.Close())
+	assert.NoError(t, apartment.Close())
+	assert.Equal(t, initThread, uninitThread)
+	assert.ErrorIs(t, apartment.Do(func() { t.Error("ran after Close") }), ErrApartmentClosed)
+}
+
+func TestApartment_InitError(t *testing.T) {
+	initErr := errors.New("CoInitializeEx failed")
+	apartment, err := NewApartmentWithInit(func() (func(), error) { return nil, initErr })
+	assert.ErrorIs(t, err, initErr)
+	assert.Nil(t, apartment)
+
+	var nilApartment *Apartment
+	assert.Error(t, nilApartment.Do(func() {}))
+	assert.NoError(t, nilApartment.Close())
+}
@@ -1,5 +1,6 @@
 // Package com provides thin wrappers for Windows COM and OLE Automation.
 // It is specifically tailored for interacting with OPC DA (Data Access) servers.
+// Batch methods given an empty slice return nil results and a nil error without calling the server.
 //go:build windows
 
 package com
@@ -7,6 +8,7 @@ package com
 import (
 	"errors"
 	"fmt"
+	"runtime"
 	"strings"
 	"syscall"
 	"unsafe"
@@ -18,6 +20,7 @@ var (
 	modOle32                    = windows.NewLazySystemDLL("ole32.dll")
 	procCoCreateInstanceEx      = modOle32.NewProc("CoCreateInstanceEx")
 	procCoInitializeSecurity    = modOle32.NewProc("CoInitializeSecurity")
+	procCoSetProxyBlanket       = modOle32.NewProc("CoSetProxyBlanket")
 	modOleaut32                 = windows.NewLazySystemDLL("oleaut32.dll")
 	procVariantClear            = modOleaut32.NewProc("VariantClear")
 	procVariantTimeToSystemTime = modOleaut32.NewProc("VariantTimeToSystemTime")
@@ -124,7 +127,7 @@ type MULTI_QI struct {
 func CoCreateInstanceEx(Clsid *windows.GUID, punkOuter *IUnknown, dwClsCtx CLSCTX, pServerInfo *COSERVERINFO, dwCount uint32, pResults *MULTI_QI) (ret error) {
 	r0, _, _ := syscall.SyscallN(procCoCreateInstanceEx.Addr(), uintptr(unsafe.Pointer(Clsid)), uintptr(unsafe.Pointer(punkOuter)), uintptr(dwClsCtx), uintptr(unsafe.Pointer(pServerInfo)), uintptr(dwCount), uintptr(unsafe.Pointer(pResults)))
 	if r0 != 0 {
-		ret = syscall.Errno(r0)
+		ret = HRESULT(r0)
 	}
 	return
 }
@@ -139,7 +142,7 @@ func CoCreateInstanceEx(Clsid *windows.GUID, punkOuter *IUnknown, dwClsCtx CLSCT
 func VariantClear(pvarg *VARIANT) (err error) {
 	r0, _, _ := syscall.SyscallN(procVariantClear.Addr(), uintptr(unsafe.Pointer(pvarg)))
 	if r0 != 0 {
-		err = syscall.Errno(r0)
+		err = HRESULT(r0)
 	}
 	return
 }
@@ -147,7 +150,7 @@ func VariantClear(pvarg *VARIANT) (err error) {
 func safeArrayGetVarType(safeArray *SafeArray) (varType uint16, err error) {
 	r0, _, _ := syscall.SyscallN(procSafeArrayGetVarType.Addr(), uintptr(unsafe.Pointer(safeArray)), uintptr(unsafe.Pointer(&varType)))
 	if r0 != 0 {
-		err = syscall.Errno(r0)
+		err = HRESULT(r0)
 	}
 	return
 }
@@ -160,7 +163,7 @@ func safeArrayGetLBound(safeArray *SafeArray, dimension uint32) (lowerBound int3
 		uintptr(unsafe.Pointer(&lowerBound)),
 	)
 	if r0 != 0 {
-		err = syscall.Errno(r0)
+		err = HRESULT(r0)
 	}
 	return
 }
@@ -173,7 +176,7 @@ func safeArrayGetUBound(safeArray *SafeArray, dimension uint32) (upperBound int3
 		uintptr(unsafe.Pointer(&upperBound)),
 	)
 	if r0 != 0 {
-		err = syscall.Errno(r0)
+		err = HRESULT(r0)
 	}
 	return
 }
@@ -185,7 +188,7 @@ func safeArrayGetElement(safeArray *SafeArray, index int32, pv unsafe.Pointer) (
 		uintptr(unsafe.Pointer(&index)),
 		uintptr(pv))
 	if int32(r0) < 0 {
-		err = syscall.Errno(r0)
+		err = HRESULT(r0)
 	}
 	return
 }
@@ -232,7 +235,7 @@ func safeArrayPutElement(safearray *SafeArray, index int64, element uintptr) (er
 		element,
 	)
 	if r0 != 0 {
-		err = syscall.Errno(r0)
+		err = HRESULT(r0)
 	}
 	return
 }
@@ -243,7 +246,7 @@ func SysFreeString(v *uint16) (err error) {
 		uintptr(unsafe.Pointer(v)),
 	)
 	if r0 != 0 {
-		err = syscall.Errno(r0)
+		err = HRESULT(r0)
 	}
 	return
 }
@@ -255,25 +258,201 @@ func SysFreeString(v *uint16) (err error) {
 //
 //	punk, err := com.MakeCOMObjectEx("remote-pc", com.CLSCTX_REMOTE_SERVER, clsid, iid)
 func MakeCOMObjectEx(hostname string, serverLocation CLSCTX, requestedClass *windows.GUID, requestedInterface *windows.GUID) (*IUnknown, error) {
-	reqInterface := MULTI_QI{
-		PIID: requestedInterface,
-		PItf: nil,
-		Hr:   0,
+	return MakeCOMObjectExAuth(hostname, serverLocation, requestedClass, requestedInterface, nil)
+}
+
+// MakeCOMObjectExAuth creates a COM object like MakeCOMObjectEx, passing authInfo in the COSERVERINFO
+// so the remote activation uses the given authentication settings instead of the process identity.
+// authInfo is ignored for CLSCTX_LOCAL_SERVER and may be nil to use the default settings.
+//
+// Example:
+//
+//	authInfo := com.NewCOAUTHINFO("user", "DOMAIN", "password")
+//	punk, err := com.MakeCOMObjectExAuth("remote-pc", com.CLSCTX_REMOTE_SERVER, clsid, iid, authInfo)
+func MakeCOMObjectExAuth(hostname string, serverLocation CLSCTX, requestedClass *windows.GUID, requestedInterface *windows.GUID, authInfo *COAUTHINFO) (*IUnknown, error) {
+	itfs, err := MakeCOMObjectMulti(hostname, serverLocation, requestedClass, []*windows.GUID{requestedInterface}, authInfo)
+	if err != nil {
+		var itfErr *InterfaceError
+		if errors.As(err, &itfErr) {
+			return nil, itfErr.Err
+		}
+		return nil, err
+	}
+	return itfs[0], nil
+}
+
+// InterfaceError reports an interface that MakeCOMObjectMulti could not obtain from the created object.
+type InterfaceError struct {
+	// IID is the identifier of the interface.
+	IID windows.GUID
+	// Err is the HRESULT returned for the interface.
+	Err error
+}
+
+// Error returns the interface and the reason it could not be obtained.
+func (e *InterfaceError) Error() string {
+	return fmt.Sprintf("query interface %s: %v", e.IID.String(), e.Err)
+}
+
+// Unwrap returns the HRESULT returned for the interface.
+func (e *InterfaceError) Unwrap() error {
+	return e.Err
+}
+
+// MakeCOMObjectMulti creates a COM object like MakeCOMObjectExAuth and acquires all the requested
+// interfaces in the same CoCreateInstanceEx call, saving one round trip per interface on remote servers.
+// The interfaces are returned in the order of requestedInterfaces. If any interface cannot be obtained,
+// the ones that were are released and the error is an *InterfaceError naming the first missing interface.
+//
+// Example:
+//
+//	itfs, err := com.MakeCOMObjectMulti("remote-pc", com.CLSCTX_REMOTE_SERVER, clsid, []*windows.GUID{&com.IID_IOPCServer, &com.IID_IOPCCommon}, nil)
+func MakeCOMObjectMulti(hostname string, serverLocation CLSCTX, requestedClass *windows.GUID, requestedInterfaces []*windows.GUID, authInfo *COAUTHINFO) ([]*IUnknown, error) {
+	if len(requestedInterfaces) == 0 {
+		return nil, errors.New("no interfaces requested")
+	}
+	results := make([]MULTI_QI, len(requestedInterfaces))
+	for i, iid := range requestedInterfaces {
+		results[i].PIID = iid
 	}
 	var serverInfoPtr *COSERVERINFO = nil
+	var name *uint16
 	if serverLocation != CLSCTX_LOCAL_SERVER {
+		var err error
+		name, err = windows.UTF16PtrFromString(hostname)
+		if err != nil {
+			return nil, err
+		}
 		serverInfoPtr = &COSERVERINFO{
-			PwszName: windows.StringToUTF16Ptr(hostname),
+			PwszName:  name,
+			PAuthInfo: authInfo,
 		}
 	}
-	err := CoCreateInstanceEx(requestedClass, nil, serverLocation, serverInfoPtr, 1, &reqInterface)
-	if err != nil {
+	err := CoCreateInstanceEx(requestedClass, nil, serverLocation, serverInfoPtr, uint32(len(results)), &results[0])
+	// the server name, auth info and requested IIDs are only referenced through uintptr during the call
+	runtime.KeepAlive(serverInfoPtr)
+	runtime.KeepAlive(name)
+	runtime.KeepAlive(authInfo)
+	runtime.KeepAlive(requestedInterfaces)
+	return collectMultiQI(results, err)
+}
+
+// collectMultiQI turns the results of CoCreateInstanceEx into the acquired interfaces. On a partial
+// failure, reported as CO_S_NOTALLINTERFACES, the acquired interfaces are released.
+func collectMultiQI(results []MULTI_QI, err error) ([]*IUnknown, error) {
+	if err != nil && err != HRESULT(CO_S_NOTALLINTERFACES) {
 		return nil, err
 	}
-	if reqInterface.Hr != 0 {
-		return nil, syscall.Errno(reqInterface.Hr)
+	var failed *InterfaceError
+	for _, r := range results {
+		if r.Hr != 0 && failed == nil {
+			failed = &InterfaceError{IID: *r.PIID, Err: HRESULT(uint32(r.Hr))}
+		}
+	}
+	if failed == nil {
+		itfs := make([]*IUnknown, len(results))
+		for i, r := range results {
+			itfs[i] = r.PItf
+			trackAcquire(r.PItf, r.PIID)
+		}
+		return itfs, nil
 	}
-	return reqInterface.PItf, nil
+	for _, r := range results {
+		if r.Hr == 0 && r.PItf != nil {
+			r.PItf.Release()
+		}
+	}
+	return nil, failed
+}
+
+// NewCOAUTHIDENTITY creates a Unicode COAUTHIDENTITY for the given account.
+// The returned structure references its own UTF-16 buffers and must be kept alive as long as COM may use it.
+func NewCOAUTHIDENTITY(username, domain, password string) *COAUTHIDENTITY {
+	user, _ := syscall.UTF16FromString(username)
+	dom, _ := syscall.UTF16FromString(domain)
+	pass, _ := syscall.UTF16FromString(password)
+	return &COAUTHIDENTITY{
+		User:           &user[0],
+		UserLength:     uint32(len(user) - 1),
+		Domain:         &dom[0],
+		DomainLength:   uint32(len(dom) - 1),
+		Password:       &pass[0],
+		PasswordLength: uint32(len(pass) - 1),
+		Flags:          SEC_WINNT_AUTH_IDENTITY_UNICODE,
+	}
+}
+
+// NewCOAUTHINFO creates a COAUTHINFO that authenticates remote activation and calls as the given account
+// using NTLM at RPC_C_AUTHN_LEVEL_CONNECT with impersonation allowed.
+//
+// Example:
+//
+//	authInfo := com.NewCOAUTHINFO("operator", "PLANT", "secret")
+func NewCOAUTHINFO(username, domain, password string) *COAUTHINFO {
+	return NewCOAUTHINFOWithIdentity(NewCOAUTHIDENTITY(username, domain, password))
+}
+
+// NewCOAUTHINFOWithIdentity creates a COAUTHINFO with the settings of NewCOAUTHINFO for an existing identity.
+//
+// Example:
+//
+//	authInfo := com.NewCOAUTHINFOWithIdentity(com.NewCOAUTHIDENTITY("operator", "PLANT", "secret"))
+func NewCOAUTHINFOWithIdentity(identity *COAUTHIDENTITY) *COAUTHINFO {
+	return &COAUTHINFO{
+		DwAuthnSvc:           RPC_C_AUTHN_WINNT,
+		DwAuthzSvc:           RPC_C_AUTHZ_NONE,
+		DwAuthnLevel:         RPC_C_AUTHN_LEVEL_CONNECT,
+		DwImpersonationLevel: RPC_C_IMP_LEVEL_IMPERSONATE,
+		PAuthIdentityData:    identity,
+		DwCapabilities:       EOAC_NONE,
+	}
+}
+
+// CoSetProxyBlanket applies the authentication settings of authInfo to an interface proxy,
+// so calls made through it use the same identity as the activation request.
+// Each interface proxy has its own blanket, so it must be set on every interface obtained from the server.
+//
+// Example:
+//
+//	err := com.CoSetProxyBlanket(punk, authInfo)
+func CoSetProxyBlanket(proxy *IUnknown, authInfo *COAUTHINFO) error {
+	if proxy == nil || authInfo == nil {
+		return errors.New("nil proxy or auth info")
+	}
+	r0, _, _ := syscall.SyscallN(
+		procCoSetProxyBlanket.Addr(),
+		uintptr(unsafe.Pointer(proxy)),
+		uintptr(authInfo.DwAuthnSvc),
+		uintptr(authInfo.DwAuthzSvc),
+		uintptr(unsafe.Pointer(authInfo.PwszServerPrincName)),
+		uintptr(authInfo.DwAuthnLevel),
+		uintptr(authInfo.DwImpersonationLevel),
+		uintptr(unsafe.Pointer(authInfo.PAuthIdentityData)),
+		uintptr(authInfo.DwCapabilities),
+	)
+	if r0 != 0 {
+		return HRESULT(r0)
+	}
+	return nil
+}
+
+// SetProxyBlanket sets the authentication level, impersonation level and identity used for calls made
+// through an interface proxy, authenticating with NTLM. It suits servers that require a stronger level,
+// such as RPC_C_AUTHN_LEVEL_PKT_INTEGRITY on hosts hardened against CVE-2021-26414, than the one set
+// process-wide with CoInitializeSecurity. A nil identity uses the identity of the process.
+//
+// Example:
+//
+//	err := com.SetProxyBlanket(punk, com.RPC_C_AUTHN_LEVEL_PKT_INTEGRITY, com.RPC_C_IMP_LEVEL_IMPERSONATE, nil)
+func SetProxyBlanket(proxy *IUnknown, authnLevel, impLevel uint32, identity *COAUTHIDENTITY) error {
+	return CoSetProxyBlanket(proxy, &COAUTHINFO{
+		DwAuthnSvc:           RPC_C_AUTHN_WINNT,
+		DwAuthzSvc:           RPC_C_AUTHZ_NONE,
+		DwAuthnLevel:         authnLevel,
+		DwImpersonationLevel: impLevel,
+		PAuthIdentityData:    identity,
+		DwCapabilities:       EOAC_NONE,
+	})
 }
 
 func IsLocal(host string) bool {
@@ -310,6 +489,81 @@ type InitConfig struct {
 	ImpLevel uint32
 	// Capabilities are additional security capabilities.
 	Capabilities uint32
+	// AuthnServices restricts the authentication services the process accepts for incoming calls, such
+	// as callbacks from servers. Nil lets COM choose, which is the default.
+	AuthnServices []SOLE_AUTHENTICATION_SERVICE
+	// PrincipalName is the server principal name registered for the entries of AuthnServices that have
+	// none. It is ignored when AuthnServices is nil.
+	PrincipalName string
+	// Identity is the default identity of outgoing calls, used for every service in AuthnServices,
+	// or for RPC_C_AUTHN_WINNT if AuthnServices is nil. Nil uses the identity of the process.
+	Identity *COAUTHIDENTITY
+	// TolerateExisting accepts COM state set up earlier by the host application: S_FALSE from
+	// CoInitializeEx and RPC_E_TOO_LATE from CoInitializeSecurity are treated as success, and
+	// RPC_E_CHANGED_MODE is reported as ErrApartmentMismatch.
+	TolerateExisting bool
+}
+
+// ErrApartmentMismatch is returned when the calling thread was already initialized in another apartment,
+// reported by CoInitializeEx as RPC_E_CHANGED_MODE. COM remains usable on the thread in the apartment
+// chosen by whoever initialized it first, so the caller may decide to carry on.
+var ErrApartmentMismatch = errors.New("COM already initialized in a different apartment on this thread")
+
+// InitResult reports the outcome of InitializeWithResult.
+type InitResult struct {
+	// NeedsUninitialize reports whether a COM reference was taken on the thread, which must be
+	// balanced with Uninitialize.
+	NeedsUninitialize bool
+	// SecurityAlreadySet reports whether the process security had already been set by the host,
+	// in which case the security settings of the config were not applied.
+	SecurityAlreadySet bool
+}
+
+// coInitializeEx, initializeSecurity and coUninitialize are the calls made by InitializeWithResult;
+// tests replace them.
+var (
+	coInitializeEx     = coInitialize
+	initializeSecurity = coInitializeSecurity
+	coUninitialize     = windows.CoUninitialize
+)
+
+// coInitialize calls CoInitializeEx and returns its non-zero result, including S_FALSE, as an HRESULT.
+func coInitialize(reserved uintptr, coInit uint32) error {
+	err := windows.CoInitializeEx(reserved, coInit)
+	if errno, ok := err.(syscall.Errno); ok {
+		return HRESULT(uint32(errno))
+	}
+	return err
+}
+
+// SOLE_AUTHENTICATION_SERVICE describes an authentication service accepted by CoInitializeSecurity.
+type SOLE_AUTHENTICATION_SERVICE struct {
+	// DwAuthnSvc is the authentication service, such as RPC_C_AUTHN_GSS_KERBEROS.
+	DwAuthnSvc uint32
+	// DwAuthzSvc is the authorization service.
+	DwAuthzSvc uint32
+	// PPrincipalName is the principal name to use with the service, or nil.
+	PPrincipalName *uint16
+	// Hr is set by CoInitializeSecurity to the result of registering the service.
+	Hr int32
+}
+
+// SOLE_AUTHENTICATION_INFO holds the default identity for one authentication service.
+type SOLE_AUTHENTICATION_INFO struct {
+	// DwAuthnSvc is the authentication service.
+	DwAuthnSvc uint32
+	// DwAuthzSvc is the authorization service.
+	DwAuthzSvc uint32
+	// PAuthInfo points to the identity, a COAUTHIDENTITY for NTLM and Kerberos.
+	PAuthInfo *COAUTHIDENTITY
+}
+
+// SOLE_AUTHENTICATION_LIST is the list of default identities passed to CoInitializeSecurity.
+type SOLE_AUTHENTICATION_LIST struct {
+	// CAuthInfo is the number of entries in AAuthInfo.
+	CAuthInfo uint32
+	// AAuthInfo points to the entries.
+	AAuthInfo *SOLE_AUTHENTICATION_INFO
 }
 
 func DefaultInitConfig() *InitConfig {
@@ -321,16 +575,49 @@ func DefaultInitConfig() *InitConfig {
 }
 
 func InitializeWithConfig(config *InitConfig) error {
-	err := windows.CoInitializeEx(0, windows.COINIT_MULTITHREADED)
-	if err != nil {
-		return fmt.Errorf("call CoInitializeEx error: %s", err)
+	_, err := InitializeWithResult(config)
+	return err
+}
+
+// InitializeWithResult initializes COM like InitializeWithConfig and also reports whether Uninitialize
+// must be called, which matters when config.TolerateExisting lets it share COM with a host application
+// that initialized the thread or the process security first. On error no reference is held, except for
+// ErrApartmentMismatch where NeedsUninitialize is false as well.
+//
+// Example:
+//
+//	config := com.DefaultInitConfig()
+//	config.TolerateExisting = true
+//	result, err := com.InitializeWithResult(config)
+//	if err != nil && !errors.Is(err, com.ErrApartmentMismatch) {
+//		log.Fatal(err)
+//	}
+//	if result.NeedsUninitialize {
+//		defer com.Uninitialize()
+//	}
+func InitializeWithResult(config *InitConfig) (InitResult, error) {
+	var result InitResult
+	err := coInitializeEx(0, windows.COINIT_MULTITHREADED)
+	switch {
+	case err == nil:
+	case config.TolerateExisting && err == HRESULT(S_FALSE):
+		// the thread was already in the multithreaded apartment; the call still took a reference
+	case config.TolerateExisting && err == HRESULT(RPC_E_CHANGED_MODE):
+		return result, fmt.Errorf("call CoInitializeEx error: %w: %w", ErrApartmentMismatch, err)
+	default:
+		return result, fmt.Errorf("call CoInitializeEx error: %s", err)
+	}
+	result.NeedsUninitialize = true
+	err = initializeSecurity(config)
+	if config.TolerateExisting && err == HRESULT(RPC_E_TOO_LATE) {
+		result.SecurityAlreadySet = true
+		err = nil
 	}
-	err = CoInitializeSecurity(config.AuthLevel, config.ImpLevel, config.Capabilities)
 	if err != nil {
-		Uninitialize()
-		return fmt.Errorf("call CoInitializeSecurity error: %s", err)
+		coUninitialize()
+		return InitResult{}, fmt.Errorf("call CoInitializeSecurity error: %s", err)
 	}
-	return nil
+	return result, nil
 }
 
 // Uninitialize closes the COM library on the current thread.
@@ -340,9 +627,19 @@ func InitializeWithConfig(config *InitConfig) error {
 //
 //	defer com.Uninitialize()
 func Uninitialize() {
+	logOutstandingRefs()
 	windows.CoUninitialize()
 }
 
+// checkBatchLengths returns an error unless a batch call passes one argument in got for each of its items,
+// since the server reads as many arguments as there are items.
+func checkBatchLengths(what string, got, items int) error {
+	if got != items {
+		return fmt.Errorf("got %d %s for %d items", got, what, items)
+	}
+	return nil
+}
+
 func IsEqualGUID(guid1 *windows.GUID, guid2 *windows.GUID) bool {
 	return guid1.Data1 == guid2.Data1 &&
 		guid1.Data2 == guid2.Data2 &&
@@ -357,20 +654,78 @@ func IsEqualGUID(guid1 *windows.GUID, guid2 *windows.GUID) bool {
 		guid1.Data4[7] == guid2.Data4[7]
 }
 
+// CoInitializeSecurity sets the process-wide security with the given levels, letting COM choose the
+// authentication services. Use InitializeWithConfig to set the services, principal name or identity.
 func CoInitializeSecurity(authnLevel, impLevel, capabilities uint32) (err error) {
-	cAuthSvc := int32(-1)
+	return coInitializeSecurity(&InitConfig{AuthLevel: authnLevel, ImpLevel: impLevel, Capabilities: capabilities})
+}
+
+// securityArguments holds the arguments of CoInitializeSecurity built from an InitConfig.
+type securityArguments struct {
+	cAuthSvc  int32
+	services  []SOLE_AUTHENTICATION_SERVICE
+	infos     []SOLE_AUTHENTICATION_INFO
+	authList  *SOLE_AUTHENTICATION_LIST
+	principal *uint16
+}
+
+// newSecurityArguments builds the CoInitializeSecurity arguments of config. The entries of
+// config.AuthnServices are copied so the caller's slice is left unchanged.
+func newSecurityArguments(config *InitConfig) (*securityArguments, error) {
+	args := &securityArguments{cAuthSvc: -1}
+	if config.AuthnServices != nil {
+		if config.PrincipalName != "" {
+			principal, err := syscall.UTF16PtrFromString(config.PrincipalName)
+			if err != nil {
+				return nil, err
+			}
+			args.principal = principal
+		}
+		args.cAuthSvc = int32(len(config.AuthnServices))
+		args.services = append([]SOLE_AUTHENTICATION_SERVICE(nil), config.AuthnServices...)
+		for i := range args.services {
+			if args.services[i].PPrincipalName == nil {
+				args.services[i].PPrincipalName = args.principal
+			}
+		}
+	}
+	if config.Identity != nil {
+		if len(config.AuthnServices) == 0 {
+			args.infos = []SOLE_AUTHENTICATION_INFO{{DwAuthnSvc: RPC_C_AUTHN_WINNT, DwAuthzSvc: RPC_C_AUTHZ_NONE, PAuthInfo: config.Identity}}
+		}
+		for _, service := range config.AuthnServices {
+			args.infos = append(args.infos, SOLE_AUTHENTICATION_INFO{DwAuthnSvc: service.DwAuthnSvc, DwAuthzSvc: service.DwAuthzSvc, PAuthInfo: config.Identity})
+		}
+		args.authList = &SOLE_AUTHENTICATION_LIST{CAuthInfo: uint32(len(args.infos)), AAuthInfo: &args.infos[0]}
+	}
+	return args, nil
+}
+
+// coInitializeSecurity calls CoInitializeSecurity with every setting of config.
+func coInitializeSecurity(config *InitConfig) error {
+	args, err := newSecurityArguments(config)
+	if err != nil {
+		return err
+	}
+	var asAuthSvc *SOLE_AUTHENTICATION_SERVICE
+	if len(args.services) > 0 {
+		asAuthSvc = &args.services[0]
+	}
 	r0, _, _ := procCoInitializeSecurity.Call(
 		uintptr(0),
-		uintptr(cAuthSvc),
-		uintptr(0),
+		uintptr(args.cAuthSvc),
+		uintptr(unsafe.Pointer(asAuthSvc)),
 		uintptr(0),
-		uintptr(authnLevel),
-		uintptr(impLevel),
-		uintptr(0),
-		uintptr(capabilities),
+		uintptr(config.AuthLevel),
+		uintptr(config.ImpLevel),
+		uintptr(unsafe.Pointer(args.authList)),
+		uintptr(config.Capabilities),
 		uintptr(0))
+	// the services, principal and identities are only referenced through uintptr during the call
+	runtime.KeepAlive(args)
+	runtime.KeepAlive(config)
 	if r0 != 0 {
-		err = syscall.Errno(r0)
+		return HRESULT(r0)
 	}
-	return
+	return nil
 }
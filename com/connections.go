@@ -0,0 +1,176 @@
+//go:build windows
+
+package com
+
+import (
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	// CONNECT_E_NOCONNECTION is returned by Unadvise for a cookie that is not connected.
+	CONNECT_E_NOCONNECTION = 0x80040200
+	// CONNECT_E_ADVISELIMIT is returned by Advise when the connection point accepts no more connections.
+	CONNECT_E_ADVISELIMIT = 0x80040201
+)
+
+// IEnumConnectionPointsVtbl is the virtual function table for the IEnumConnectionPoints interface.
+type IEnumConnectionPointsVtbl struct {
+	IUnknownVtbl
+	// Next retrieves the next connection points in the enumeration sequence.
+	Next uintptr
+	// Skip skips over connection points in the enumeration sequence.
+	Skip uintptr
+	// Reset resets the enumeration sequence to the beginning.
+	Reset uintptr
+	// Clone creates a new enumerator with the same enumeration state.
+	Clone uintptr
+}
+
+// IEnumConnectionPoints enumerates the connection points of a connectable object.
+type IEnumConnectionPoints struct {
+	// IUnknown is the underlying COM interface.
+	*IUnknown
+}
+
+func (ie *IEnumConnectionPoints) Vtbl() *IEnumConnectionPointsVtbl {
+	return (*IEnumConnectionPointsVtbl)(unsafe.Pointer(ie.IUnknown.LpVtbl))
+}
+
+// Next retrieves the next connection point, returning false at the end of the enumeration.
+func (ie *IEnumConnectionPoints) Next() (*IConnectionPoint, bool, error) {
+	var iUnknown *IUnknown
+	var fetched uint32
+	r0, _, _ := syscall.SyscallN(ie.Vtbl().Next, uintptr(unsafe.Pointer(ie.IUnknown)), uintptr(1), uintptr(unsafe.Pointer(&iUnknown)), uintptr(unsafe.Pointer(&fetched)))
+	if int32(r0) < 0 {
+		return nil, false, HRESULT(r0)
+	}
+	if fetched == 0 || iUnknown == nil {
+		return nil, false, nil
+	}
+	trackAcquireName(iUnknown, "IConnectionPoint")
+	return &IConnectionPoint{iUnknown}, true, nil
+}
+
+// CONNECTDATA describes a connection of a connection point.
+type CONNECTDATA struct {
+	// PUnk is the sink of the connection; the receiver of an enumeration must release it.
+	PUnk *IUnknown
+	// DwCookie is the cookie returned by Advise for the connection.
+	DwCookie uint32
+}
+
+// IEnumConnectionsVtbl is the virtual function table for the IEnumConnections interface.
+type IEnumConnectionsVtbl struct {
+	IUnknownVtbl
+	// Next retrieves the next connections in the enumeration sequence.
+	Next uintptr
+	// Skip skips over connections in the enumeration sequence.
+	Skip uintptr
+	// Reset resets the enumeration sequence to the beginning.
+	Reset uintptr
+	// Clone creates a new enumerator with the same enumeration state.
+	Clone uintptr
+}
+
+// IEnumConnections enumerates the connections of a connection point.
+type IEnumConnections struct {
+	// IUnknown is the underlying COM interface.
+	*IUnknown
+}
+
+func (ie *IEnumConnections) Vtbl() *IEnumConnectionsVtbl {
+	return (*IEnumConnectionsVtbl)(unsafe.Pointer(ie.IUnknown.LpVtbl))
+}
+
+// Next retrieves the next connection, returning false at the end of the enumeration.
+func (ie *IEnumConnections) Next() (CONNECTDATA, bool, error) {
+	var data CONNECTDATA
+	var fetched uint32
+	r0, _, _ := syscall.SyscallN(ie.Vtbl().Next, uintptr(unsafe.Pointer(ie.IUnknown)), uintptr(1), uintptr(unsafe.Pointer(&data)), uintptr(unsafe.Pointer(&fetched)))
+	if int32(r0) < 0 {
+		return CONNECTDATA{}, false, HRESULT(r0)
+	}
+	if fetched == 0 {
+		return CONNECTDATA{}, false, nil
+	}
+	trackAcquireName(data.PUnk, "IUnknown")
+	return data, true, nil
+}
+
+// EnumConnectionPoints returns all connection points of the object. The caller must release them.
+//
+// Example:
+//
+//	points, err := container.EnumConnectionPoints()
+//	for _, point := range points {
+//		defer point.Release()
+//	}
+func (c *IConnectionPointContainer) EnumConnectionPoints() ([]*IConnectionPoint, error) {
+	var iUnknown *IUnknown
+	r0, _, _ := syscall.SyscallN(c.Vtbl().EnumConnectionPoints, uintptr(unsafe.Pointer(c.IUnknown)), uintptr(unsafe.Pointer(&iUnknown)))
+	if int32(r0) < 0 {
+		return nil, HRESULT(r0)
+	}
+	trackAcquireName(iUnknown, "IEnumConnectionPoints")
+	iEnum := &IEnumConnectionPoints{iUnknown}
+	defer iEnum.Release()
+	var points []*IConnectionPoint
+	for {
+		point, ok, err := iEnum.Next()
+		if err != nil {
+			for _, p := range points {
+				p.Release()
+			}
+			return nil, err
+		}
+		if !ok {
+			return points, nil
+		}
+		points = append(points, point)
+	}
+}
+
+// GetConnectionInterface returns the IID of the outgoing interface of the connection point.
+func (p *IConnectionPoint) GetConnectionInterface() (windows.GUID, error) {
+	var iid windows.GUID
+	r0, _, _ := syscall.SyscallN(p.Vtbl().GetConnectionInterface, uintptr(unsafe.Pointer(p.IUnknown)), uintptr(unsafe.Pointer(&iid)))
+	if int32(r0) < 0 {
+		return windows.GUID{}, HRESULT(r0)
+	}
+	return iid, nil
+}
+
+// EnumConnections returns the cookies of the current connections of the connection point. The sinks of
+// the connections are released before returning. Connection points may not implement the enumeration
+// and return E_NOTIMPL.
+//
+// Example:
+//
+//	cookies, err := point.EnumConnections()
+func (p *IConnectionPoint) EnumConnections() ([]uint32, error) {
+	var iUnknown *IUnknown
+	r0, _, _ := syscall.SyscallN(p.Vtbl().EnumConnections, uintptr(unsafe.Pointer(p.IUnknown)), uintptr(unsafe.Pointer(&iUnknown)))
+	if int32(r0) < 0 {
+		return nil, HRESULT(r0)
+	}
+	trackAcquireName(iUnknown, "IEnumConnections")
+	iEnum := &IEnumConnections{iUnknown}
+	defer iEnum.Release()
+	var cookies []uint32
+	for {
+		data, ok, err := iEnum.Next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return cookies, nil
+		}
+		if data.PUnk != nil {
+			data.PUnk.Release()
+		}
+		cookies = append(cookies, data.DwCookie)
+	}
+}
@@ -62,6 +62,7 @@ const (
 
 const (
 	S_OK           = 0x00000000
+	S_FALSE        = 0x00000001
 	E_UNEXPECTED   = 0x8000FFFF
 	E_NOTIMPL      = 0x80004001
 	E_OUTOFMEMORY  = 0x8007000E
@@ -74,7 +75,11 @@ const (
 	E_ACCESSDENIED = 0x80070005
 	E_PENDING      = 0x8000000A
 
-	CO_E_CLASSSTRING = 0x800401F3
+	CO_E_CLASSSTRING      = 0x800401F3
+	CO_E_NOTINITIALIZED   = 0x800401F0
+	CO_S_NOTALLINTERFACES = 0x00080012
+	RPC_E_CHANGED_MODE    = 0x80010106
+	RPC_E_TOO_LATE        = 0x80010119
 )
 
 // authentication level constants
@@ -122,3 +127,18 @@ const (
 	SEC_WINNT_AUTH_IDENTITY_ANSI    uint32 = 0x1
 	SEC_WINNT_AUTH_IDENTITY_UNICODE uint32 = 0x2
 )
+
+// authentication service constants
+const (
+	RPC_C_AUTHN_NONE          uint32 = 0
+	RPC_C_AUTHN_GSS_NEGOTIATE uint32 = 9
+	RPC_C_AUTHN_WINNT         uint32 = 10
+	RPC_C_AUTHN_GSS_KERBEROS  uint32 = 16
+	RPC_C_AUTHN_DEFAULT       uint32 = 0xFFFFFFFF
+)
+
+// authorization service constants
+const (
+	RPC_C_AUTHZ_NONE    uint32 = 0
+	RPC_C_AUTHZ_DEFAULT uint32 = 0xFFFFFFFF
+)
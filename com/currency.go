@@ -0,0 +1,131 @@
+//go:build windows
+
+package com
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"unsafe"
+)
+
+// currencyScale is the number of currency units per unit of a Currency value's whole part.
+const currencyScale = 10000
+
+// maxDecimalScale is the largest power-of-ten scale a DECIMAL can have.
+const maxDecimalScale = 28
+
+// decimalNegative is the sign byte of a negative DECIMAL.
+const decimalNegative = 0x80
+
+// Currency is the value of a VT_CY variant, a fixed-point number counting ten-thousandths of a unit, so
+// Currency(123456) is 12.3456. VARIANT.Value returns VT_CY values as Currency, and NewVariant writes a
+// Currency as VT_CY.
+type Currency int64
+
+// NewCurrency returns the Currency nearest to f. It fails for NaN, infinities and values outside the
+// range of VT_CY, about ±922337203685477.
+//
+// Example:
+//
+//	setpoint, err := com.NewCurrency(12.5)
+//	if err == nil {
+//		err = item.Write(setpoint)
+//	}
+func NewCurrency(f float64) (Currency, error) {
+	scaled := math.Round(f * currencyScale)
+	if math.IsNaN(scaled) || scaled < math.MinInt64 || scaled >= math.MaxInt64 {
+		return 0, fmt.Errorf("%v is outside the range of VT_CY", f)
+	}
+	return Currency(scaled), nil
+}
+
+// Float64 returns c as a float64, which may round values with more than 15 significant digits.
+func (c Currency) Float64() float64 {
+	return float64(c) / currencyScale
+}
+
+// String returns c with its four decimal places, such as "-12.3400".
+func (c Currency) String() string {
+	sign := ""
+	magnitude := uint64(c)
+	if c < 0 {
+		sign = "-"
+		magnitude = uint64(-c)
+	}
+	return fmt.Sprintf("%s%d.%04d", sign, magnitude/currencyScale, magnitude%currencyScale)
+}
+
+// decimal is the layout of the OLE Automation DECIMAL type, which overlays a whole VARIANT: a 96-bit
+// unsigned integer in hi32 and lo64, divided by 10 to the power of scale and negated if sign is set.
+type decimal struct {
+	wReserved uint16 // wReserved overlaps the VT of the VARIANT.
+	scale     byte
+	sign      byte
+	hi32      uint32
+	lo64      uint64
+}
+
+// decimalOf returns the DECIMAL held by a VT_DECIMAL variant.
+func decimalOf(v *VARIANT) *decimal {
+	return (*decimal)(unsafe.Pointer(v))
+}
+
+// rat returns the exact value of d.
+func (d *decimal) rat() (*big.Rat, error) {
+	if d.scale > maxDecimalScale {
+		return nil, fmt.Errorf("DECIMAL scale %d exceeds %d", d.scale, maxDecimalScale)
+	}
+	mantissa := new(big.Int).SetUint64(uint64(d.hi32))
+	mantissa.Lsh(mantissa, 64)
+	mantissa.Or(mantissa, new(big.Int).SetUint64(d.lo64))
+	if d.sign&decimalNegative != 0 {
+		mantissa.Neg(mantissa)
+	}
+	return new(big.Rat).SetFrac(mantissa, pow10(int(d.scale))), nil
+}
+
+// newDecimal returns the DECIMAL of r with the smallest scale that represents r exactly. Values needing more
+// than 28 decimal places, or more digits than 96 bits hold at their scale, are rounded half away from zero
+// to the largest scale that fits.
+func newDecimal(r *big.Rat) (decimal, error) {
+	if r == nil {
+		return decimal{}, errors.New("nil DECIMAL value")
+	}
+	scale := 0
+	for scale < maxDecimalScale && !new(big.Rat).Mul(r, new(big.Rat).SetInt(pow10(scale))).IsInt() {
+		scale++
+	}
+	mantissa := roundScaled(r, scale)
+	for mantissa.BitLen() > 96 && scale > 0 {
+		scale--
+		mantissa = roundScaled(r, scale)
+	}
+	if mantissa.BitLen() > 96 {
+		return decimal{}, fmt.Errorf("%s is outside the range of VT_DECIMAL", r.String())
+	}
+	d := decimal{scale: byte(scale)}
+	if r.Sign() < 0 {
+		d.sign = decimalNegative
+	}
+	d.lo64 = new(big.Int).And(mantissa, new(big.Int).SetUint64(math.MaxUint64)).Uint64()
+	d.hi32 = uint32(new(big.Int).Rsh(mantissa, 64).Uint64())
+	return d, nil
+}
+
+// roundScaled returns the magnitude of r times 10 to the power of scale, rounded half away from zero.
+func roundScaled(r *big.Rat, scale int) *big.Int {
+	num := new(big.Int).Abs(r.Num())
+	num.Mul(num, pow10(scale))
+	den := r.Denom()
+	// (2*num + den) / (2*den) rounds the magnitude half up
+	num.Lsh(num, 1)
+	num.Add(num, den)
+	return num.Quo(num, new(big.Int).Lsh(den, 1))
+}
+
+// pow10 returns 10 to the power of n.
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
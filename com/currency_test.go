@@ -0,0 +1,96 @@
+//go:build windows
+
+package com
+
+import (
+	"math"
+	"math/big"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// decimalVariant returns a VT_DECIMAL variant holding the given DECIMAL fields.
+func decimalVariant(scale, sign byte, hi32 uint32, lo64 uint64) *VARIANT {
+	v := &VARIANT{}
+	*decimalOf(v) = decimal{scale: scale, sign: sign, hi32: hi32, lo64: lo64}
+	v.VT = VT_DECIMAL
+	return v
+}
+
+func TestVARIANT_Value_Currency(t *testing.T) {
+	got, err := (&VARIANT{VT: VT_CY, Val: 123456}).Value()
+	assert.NoError(t, err)
+	assert.Equal(t, Currency(123456), got)
+	assert.Equal(t, "12.3456", got.(Currency).String())
+	assert.InDelta(t, 12.3456, got.(Currency).Float64(), 1e-12)
+
+	cy := int64(-15000)
+	got, err = byRefVariant(VT_CY, unsafe.Pointer(&cy)).Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "-1.5000", got.(Currency).String())
+	assert.Equal(t, "-922337203685477.5808", Currency(math.MinInt64).String())
+}
+
+func TestVARIANT_Value_Decimal(t *testing.T) {
+	tests := []struct {
+		name    string
+		variant *VARIANT
+		want    *big.Rat
+	}{
+		{"negative with scale", decimalVariant(2, decimalNegative, 0, 12345), big.NewRat(-12345, 100)},
+		{"high word", decimalVariant(0, 0, 1, 0), new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), 64))},
+		{"max", decimalVariant(0, 0, math.MaxUint32, math.MaxUint64), new(big.Rat).SetInt(new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 96), big.NewInt(1)))},
+		{"zero", decimalVariant(28, 0, 0, 0), new(big.Rat)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.variant.Value()
+			assert.NoError(t, err)
+			assert.Equal(t, 0, tt.want.Cmp(got.(*big.Rat)), "got %v", got)
+		})
+	}
+
+	_, err := decimalVariant(29, 0, 0, 1).Value()
+	assert.Error(t, err, "scales above 28 are invalid")
+
+	d := decimal{scale: 1, lo64: 5}
+	got, err := byRefVariant(VT_DECIMAL, unsafe.Pointer(&d)).Value()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, big.NewRat(1, 2).Cmp(got.(*big.Rat)))
+}
+
+func TestNewVariant_CurrencyAndDecimal(t *testing.T) {
+	cy, err := NewCurrency(-12.34)
+	assert.NoError(t, err)
+	assert.Equal(t, Currency(-123400), cy)
+	vw, err := NewVariant(cy)
+	assert.NoError(t, err)
+	assert.Equal(t, VT_CY, vw.Variant.VT)
+	assert.Equal(t, int64(-123400), vw.Variant.Val)
+
+	for _, r := range []*big.Rat{big.NewRat(-12345, 100), big.NewRat(1, 3), new(big.Rat)} {
+		vw, err = NewVariant(r)
+		assert.NoError(t, err)
+		assert.Equal(t, VT_DECIMAL, vw.Variant.VT)
+		got, err := vw.Variant.Value()
+		assert.NoError(t, err)
+		want := r
+		if r.Cmp(big.NewRat(1, 3)) == 0 {
+			// 1/3 is rounded to the 28 decimal places of a DECIMAL
+			want, _ = new(big.Rat).SetString("0.3333333333333333333333333333")
+		}
+		assert.Equal(t, 0, want.Cmp(got.(*big.Rat)), "got %v for %v", got, r)
+	}
+	d, err := newDecimal(big.NewRat(-12345, 100))
+	assert.NoError(t, err)
+	assert.Equal(t, decimal{scale: 2, sign: decimalNegative, lo64: 12345}, d)
+
+	_, err = NewVariant(new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), 96)))
+	assert.Error(t, err, "2^96 does not fit a DECIMAL")
+	_, err = NewCurrency(math.NaN())
+	assert.Error(t, err)
+	_, err = NewCurrency(1e15)
+	assert.Error(t, err)
+}
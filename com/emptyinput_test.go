@@ -0,0 +1,190 @@
+//go:build windows
+
+package com
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/windows"
+)
+
+// The interfaces below wrap a nil IUnknown: any call that reached the server would panic.
+func TestBatchMethods_EmptyInput(t *testing.T) {
+	tests := []struct {
+		name string
+		call func() (int, error)
+	}{
+		{"IOPCSyncIO.Read", func() (int, error) {
+			states, errs, err := (&IOPCSyncIO{}).Read(OPCDATASOURCE(1), nil)
+			return len(states) + len(errs), err
+		}},
+		{"IOPCSyncIO.Write", func() (int, error) {
+			errs, err := (&IOPCSyncIO{}).Write([]uint32{}, []VARIANT{})
+			return len(errs), err
+		}},
+		{"IOPCAsyncIO2.Read", func() (int, error) {
+			cancelID, errs, err := (&IOPCAsyncIO2{}).Read(nil, 1)
+			return int(cancelID) + len(errs), err
+		}},
+		{"IOPCAsyncIO2.Write", func() (int, error) {
+			cancelID, errs, err := (&IOPCAsyncIO2{}).Write(nil, nil, 1)
+			return int(cancelID) + len(errs), err
+		}},
+		{"IOPCItemMgt.AddItems", func() (int, error) {
+			results, errs, err := (&IOPCItemMgt{}).AddItems([]TagOPCITEMDEF{})
+			return len(results) + len(errs), err
+		}},
+		{"IOPCItemMgt.ValidateItems", func() (int, error) {
+			results, errs, err := (&IOPCItemMgt{}).ValidateItems(nil, false)
+			return len(results) + len(errs), err
+		}},
+		{"IOPCItemMgt.RemoveItems", func() (int, error) {
+			errs, err := (&IOPCItemMgt{}).RemoveItems(nil)
+			return len(errs), err
+		}},
+		{"IOPCItemMgt.SetActiveState", func() (int, error) {
+			errs, err := (&IOPCItemMgt{}).SetActiveState(nil, true)
+			return len(errs), err
+		}},
+		{"IOPCItemMgt.SetClientHandles", func() (int, error) {
+			errs, err := (&IOPCItemMgt{}).SetClientHandles(nil, nil)
+			return len(errs), err
+		}},
+		{"IOPCItemMgt.SetDatatypes", func() (int, error) {
+			errs, err := (&IOPCItemMgt{}).SetDatatypes(nil, nil)
+			return len(errs), err
+		}},
+		{"IOPCItemProperties.GetItemProperties", func() (int, error) {
+			data, errs, err := (&IOPCItemProperties{}).GetItemProperties("Random.Int4", nil)
+			return len(data) + len(errs), err
+		}},
+		{"IOPCItemProperties.LookupItemIDs", func() (int, error) {
+			ids, errs, err := (&IOPCItemProperties{}).LookupItemIDs("Random.Int4", []uint32{})
+			return len(ids) + len(errs), err
+		}},
+		{"IOPCItemSamplingMgt.SetItemSamplingRate", func() (int, error) {
+			rates, errs, err := (&IOPCItemSamplingMgt{}).SetItemSamplingRate(nil, nil)
+			return len(rates) + len(errs), err
+		}},
+		{"IOPCItemSamplingMgt.GetItemSamplingRate", func() (int, error) {
+			rates, errs, err := (&IOPCItemSamplingMgt{}).GetItemSamplingRate(nil)
+			return len(rates) + len(errs), err
+		}},
+		{"IOPCItemSamplingMgt.ClearItemSamplingRate", func() (int, error) {
+			errs, err := (&IOPCItemSamplingMgt{}).ClearItemSamplingRate(nil)
+			return len(errs), err
+		}},
+		{"IOPCItemSamplingMgt.SetItemBufferEnable", func() (int, error) {
+			errs, err := (&IOPCItemSamplingMgt{}).SetItemBufferEnable(nil, nil)
+			return len(errs), err
+		}},
+		{"IOPCItemSamplingMgt.GetItemBufferEnable", func() (int, error) {
+			enabled, errs, err := (&IOPCItemSamplingMgt{}).GetItemBufferEnable(nil)
+			return len(enabled) + len(errs), err
+		}},
+		{"IOPCItemDeadbandMgt.SetItemDeadband", func() (int, error) {
+			errs, err := (&IOPCItemDeadbandMgt{}).SetItemDeadband(nil, nil)
+			return len(errs), err
+		}},
+		{"IOPCItemDeadbandMgt.GetItemDeadband", func() (int, error) {
+			deadbands, errs, err := (&IOPCItemDeadbandMgt{}).GetItemDeadband(nil)
+			return len(deadbands) + len(errs), err
+		}},
+		{"IOPCItemDeadbandMgt.ClearItemDeadband", func() (int, error) {
+			errs, err := (&IOPCItemDeadbandMgt{}).ClearItemDeadband(nil)
+			return len(errs), err
+		}},
+		{"IOPCItemIO.Read", func() (int, error) {
+			states, errs, err := (&IOPCItemIO{}).Read(nil, nil)
+			return len(states) + len(errs), err
+		}},
+		{"IOPCItemIO.WriteVQT", func() (int, error) {
+			errs, err := (&IOPCItemIO{}).WriteVQT([]string{}, nil)
+			return len(errs), err
+		}},
+		{"IEnumString.Next", func() (int, error) {
+			items, err := (&IEnumString{}).Next(0)
+			return len(items), err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var n int
+			var err error
+			assert.NotPanics(t, func() { n, err = tt.call() })
+			assert.NoError(t, err)
+			assert.Zero(t, n)
+		})
+	}
+}
+
+// Batches with fewer or more values than items fail before reaching the server.
+func TestBatchMethods_MismatchedInput(t *testing.T) {
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"IOPCSyncIO.Write", func() error {
+			_, err := (&IOPCSyncIO{}).Write([]uint32{1, 2}, []VARIANT{{}})
+			return err
+		}},
+		{"IOPCSyncIO.Write without values", func() error {
+			_, err := (&IOPCSyncIO{}).Write([]uint32{1}, nil)
+			return err
+		}},
+		{"IOPCAsyncIO2.Write", func() error {
+			_, _, err := (&IOPCAsyncIO2{}).Write([]uint32{1}, []VARIANT{{}, {}}, 1)
+			return err
+		}},
+		{"IOPCSyncIO2.ReadMaxAge", func() error {
+			_, _, err := (&IOPCSyncIO2{}).ReadMaxAge([]uint32{1}, nil)
+			return err
+		}},
+		{"IOPCSyncIO2.WriteVQT", func() error {
+			_, err := (&IOPCSyncIO2{}).WriteVQT([]uint32{1, 2}, []TagOPCITEMVQT{{}})
+			return err
+		}},
+		{"IOPCItemMgt.SetClientHandles", func() error {
+			_, err := (&IOPCItemMgt{}).SetClientHandles([]uint32{1, 2}, []uint32{1})
+			return err
+		}},
+		{"IOPCItemMgt.SetDatatypes", func() error {
+			_, err := (&IOPCItemMgt{}).SetDatatypes([]uint32{1}, nil)
+			return err
+		}},
+		{"IOPCItemDeadbandMgt.SetItemDeadband", func() error {
+			_, err := (&IOPCItemDeadbandMgt{}).SetItemDeadband([]uint32{1}, []float32{0.5, 1})
+			return err
+		}},
+		{"IOPCItemSamplingMgt.SetItemSamplingRate", func() error {
+			_, _, err := (&IOPCItemSamplingMgt{}).SetItemSamplingRate([]uint32{1, 2}, []uint32{100})
+			return err
+		}},
+		{"IOPCItemSamplingMgt.SetItemBufferEnable", func() error {
+			_, err := (&IOPCItemSamplingMgt{}).SetItemBufferEnable([]uint32{1}, nil)
+			return err
+		}},
+		{"IOPCItemIO.Read", func() error {
+			_, _, err := (&IOPCItemIO{}).Read([]string{"a"}, nil)
+			return err
+		}},
+		{"IOPCItemIO.WriteVQT", func() error {
+			_, err := (&IOPCItemIO{}).WriteVQT([]string{"a"}, nil)
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			assert.NotPanics(t, func() { err = tt.call() })
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestFirstGUID(t *testing.T) {
+	assert.Nil(t, firstGUID(nil))
+	cids := []windows.GUID{IID_IOPCItemMgt}
+	assert.Equal(t, &cids[0], firstGUID(cids))
+}
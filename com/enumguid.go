@@ -36,7 +36,7 @@ func (ie *IEnumGUID) Vtbl() *IEnumGUIDVtbl {
 func (ie *IEnumGUID) Next(celt uint32, rgelt *windows.GUID, pceltFetched *uint32) error {
 	r0, _, _ := syscall.SyscallN(ie.Vtbl().Next, uintptr(unsafe.Pointer(ie.IUnknown)), uintptr(celt), uintptr(unsafe.Pointer(rgelt)), uintptr(unsafe.Pointer(pceltFetched)))
 	if r0 != 0 {
-		return syscall.Errno(r0)
+		return HRESULT(r0)
 	}
 	return nil
 }
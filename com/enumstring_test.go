@@ -0,0 +1,38 @@
+//go:build windows
+
+package com
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrainStrings(t *testing.T) {
+	// a full batch that ends the enumeration, and short batches that do not
+	batches := [][]string{make([]string, enumStringBatch), {"a", "b"}, {"c"}, nil}
+	calls := 0
+	result, err := drainStrings(func(celt uint32) ([]string, error) {
+		assert.Equal(t, uint32(enumStringBatch), celt)
+		batch := batches[calls]
+		calls++
+		return batch, nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, result, enumStringBatch+3)
+	assert.Equal(t, []string{"a", "b", "c"}, result[enumStringBatch:])
+	assert.Equal(t, 4, calls)
+}
+
+func TestDrainStrings_Error(t *testing.T) {
+	calls := 0
+	result, err := drainStrings(func(celt uint32) ([]string, error) {
+		calls++
+		if calls == 2 {
+			return nil, HRESULT(E_FAIL)
+		}
+		return []string{"a"}, nil
+	})
+	assert.ErrorIs(t, err, HRESULT(E_FAIL))
+	assert.Nil(t, result)
+}
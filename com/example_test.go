@@ -47,6 +47,13 @@ func ExampleMakeCOMObjectEx() {
 	*/
 }
 
+func ExampleNewCOAUTHINFO() {
+	// Build authentication settings for a remote server in another domain.
+	authInfo := com.NewCOAUTHINFO("operator", "PLANT", "secret")
+	fmt.Println(authInfo.DwAuthnSvc == com.RPC_C_AUTHN_WINNT, authInfo.PAuthIdentityData.UserLength, authInfo.PAuthIdentityData.DomainLength)
+	// Output: true 8 5
+}
+
 func ExampleIOPCServer_AddGroup() {
 	// This is a conceptual example as it requires a live OPC server.
 	/*
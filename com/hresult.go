@@ -0,0 +1,67 @@
+//go:build windows
+
+package com
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+// HRESULT is the status code returned by COM methods and APIs. The wrappers in this package return it
+// as the error of a failed call. errors.Is matches it against an HRESULT or a syscall.Errno with the
+// same code, so checks written against syscall.Errno keep working.
+//
+// Example:
+//
+//	var hr com.HRESULT
+//	if errors.As(err, &hr) && hr == com.E_NOINTERFACE {
+//		// fall back to the OPC DA 2.0 interface
+//	}
+type HRESULT uint32
+
+// Error returns the code as 0x%08X followed by its system description, when Windows has one.
+func (hr HRESULT) Error() string {
+	if msg := hr.message(); msg != "" {
+		return fmt.Sprintf("0x%08X: %s", uint32(hr), msg)
+	}
+	return fmt.Sprintf("0x%08X", uint32(hr))
+}
+
+// Failed reports whether hr is a failure code, that is whether its severity bit is set.
+func (hr HRESULT) Failed() bool {
+	return int32(hr) < 0
+}
+
+// Is reports whether target is a syscall.Errno with the same code.
+func (hr HRESULT) Is(target error) bool {
+	errno, ok := target.(syscall.Errno)
+	return ok && uint32(errno) == uint32(hr)
+}
+
+// message returns the system description of hr, or an empty string if there is none.
+func (hr HRESULT) message() string {
+	var buf [512]uint16
+	n, err := windows.FormatMessage(windows.FORMAT_MESSAGE_FROM_SYSTEM|windows.FORMAT_MESSAGE_IGNORE_INSERTS, 0, uint32(hr), 0, buf[:], nil)
+	if err != nil || n == 0 {
+		return ""
+	}
+	return strings.TrimSpace(windows.UTF16ToString(buf[:n]))
+}
+
+// HResultOf returns the code carried by err or any error it wraps: an HRESULT, or a syscall.Errno
+// holding one, as returned by callers that predate HRESULT.
+func HResultOf(err error) (HRESULT, bool) {
+	var hr HRESULT
+	if errors.As(err, &hr) {
+		return hr, true
+	}
+	var errno syscall.Errno
+	if errors.As(err, &errno) {
+		return HRESULT(uint32(errno)), true
+	}
+	return 0, false
+}
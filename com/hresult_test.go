@@ -0,0 +1,34 @@
+//go:build windows
+
+package com
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHRESULT(t *testing.T) {
+	hr := HRESULT(E_NOINTERFACE)
+	assert.True(t, hr.Failed())
+	assert.False(t, HRESULT(S_FALSE).Failed())
+	assert.Regexp(t, `^0x80004002(: .+)?$`, hr.Error())
+	assert.Equal(t, "0xC0040007", HRESULT(0xC0040007).Error()[:10])
+
+	wrapped := fmt.Errorf("query: %w", hr)
+	assert.ErrorIs(t, wrapped, HRESULT(E_NOINTERFACE))
+	assert.ErrorIs(t, wrapped, syscall.Errno(E_NOINTERFACE))
+	assert.NotErrorIs(t, wrapped, syscall.Errno(E_FAIL))
+
+	code, ok := HResultOf(wrapped)
+	assert.True(t, ok)
+	assert.Equal(t, hr, code)
+	code, ok = HResultOf(fmt.Errorf("legacy: %w", syscall.Errno(E_FAIL)))
+	assert.True(t, ok)
+	assert.Equal(t, HRESULT(E_FAIL), code)
+	_, ok = HResultOf(errors.New("plain"))
+	assert.False(t, ok)
+}
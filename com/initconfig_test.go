@@ -0,0 +1,75 @@
+//go:build windows
+
+package com
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/windows"
+)
+
+func TestNewSecurityArguments(t *testing.T) {
+	args, err := newSecurityArguments(DefaultInitConfig())
+	assert.NoError(t, err)
+	assert.Equal(t, int32(-1), args.cAuthSvc)
+	assert.Nil(t, args.services)
+	assert.Nil(t, args.authList)
+
+	own, _ := windows.UTF16PtrFromString("HOST/own")
+	identity := NewCOAUTHIDENTITY("svc-opc", "PLANT", "secret")
+	config := DefaultInitConfig()
+	config.AuthnServices = []SOLE_AUTHENTICATION_SERVICE{
+		{DwAuthnSvc: RPC_C_AUTHN_GSS_KERBEROS, DwAuthzSvc: RPC_C_AUTHZ_NONE},
+		{DwAuthnSvc: RPC_C_AUTHN_WINNT, DwAuthzSvc: RPC_C_AUTHZ_NONE, PPrincipalName: own},
+	}
+	config.PrincipalName = "HOST/opc-gateway.plant.local"
+	config.Identity = identity
+	args, err = newSecurityArguments(config)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), args.cAuthSvc)
+	assert.Equal(t, "HOST/opc-gateway.plant.local", windows.UTF16PtrToString(args.services[0].PPrincipalName))
+	assert.Same(t, own, args.services[1].PPrincipalName)
+	assert.Nil(t, config.AuthnServices[0].PPrincipalName, "the caller's services must not be modified")
+	assert.Equal(t, uint32(2), args.authList.CAuthInfo)
+	assert.Equal(t, RPC_C_AUTHN_GSS_KERBEROS, args.infos[0].DwAuthnSvc)
+	assert.Same(t, identity, args.infos[1].PAuthInfo)
+
+	args, err = newSecurityArguments(&InitConfig{Identity: identity})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(-1), args.cAuthSvc)
+	assert.Equal(t, []SOLE_AUTHENTICATION_INFO{{DwAuthnSvc: RPC_C_AUTHN_WINNT, PAuthInfo: identity}}, args.infos)
+}
+
+func TestInitializeWithResult_TolerateExisting(t *testing.T) {
+	defer func(i func(uintptr, uint32) error, s func(*InitConfig) error, u func()) {
+		coInitializeEx, initializeSecurity, coUninitialize = i, s, u
+	}(coInitializeEx, initializeSecurity, coUninitialize)
+	var initErr, securityErr error
+	uninitialized := 0
+	coInitializeEx = func(reserved uintptr, coInit uint32) error { return initErr }
+	initializeSecurity = func(config *InitConfig) error { return securityErr }
+	coUninitialize = func() { uninitialized++ }
+
+	config := DefaultInitConfig()
+	initErr, securityErr = HRESULT(S_FALSE), HRESULT(RPC_E_TOO_LATE)
+	_, err := InitializeWithResult(config)
+	assert.Error(t, err)
+
+	config.TolerateExisting = true
+	result, err := InitializeWithResult(config)
+	assert.NoError(t, err)
+	assert.Equal(t, InitResult{NeedsUninitialize: true, SecurityAlreadySet: true}, result)
+	assert.Equal(t, 0, uninitialized)
+
+	initErr = HRESULT(RPC_E_CHANGED_MODE)
+	result, err = InitializeWithResult(config)
+	assert.ErrorIs(t, err, ErrApartmentMismatch)
+	assert.False(t, result.NeedsUninitialize)
+
+	initErr, securityErr = nil, HRESULT(E_ACCESSDENIED)
+	result, err = InitializeWithResult(config)
+	assert.Error(t, err)
+	assert.False(t, result.NeedsUninitialize)
+	assert.Equal(t, 1, uninitialized)
+}
@@ -50,8 +50,10 @@ func (v *IUnknown) Vtbl() *IUnknownVtbl {
 func (v *IUnknown) QueryInterface(riid *windows.GUID, ppvObject unsafe.Pointer) (ret error) {
 	r0, _, _ := syscall.SyscallN(v.Vtbl().QueryInterface, uintptr(unsafe.Pointer(v)), uintptr(unsafe.Pointer(riid)), uintptr(ppvObject))
 	if r0 != 0 {
-		ret = syscall.Errno(r0)
+		ret = HRESULT(r0)
+		return
 	}
+	trackAcquire(*(**IUnknown)(ppvObject), riid)
 	return
 }
 
@@ -59,6 +61,7 @@ func (v *IUnknown) QueryInterface(riid *windows.GUID, ppvObject unsafe.Pointer)
 //
 // ULONG Release();
 func (v *IUnknown) Release() uint32 {
+	trackRelease(v)
 	ret, _, _ := syscall.SyscallN(v.Vtbl().Release, uintptr(unsafe.Pointer(v)))
 	return uint32(ret)
 }
@@ -0,0 +1,77 @@
+//go:build windows
+
+package com
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	modKernel32          = windows.NewLazySystemDLL("kernel32.dll")
+	procLCIDToLocaleName = modKernel32.NewProc("LCIDToLocaleName")
+	procGetLocaleInfoEx  = modKernel32.NewProc("GetLocaleInfoEx")
+)
+
+// Locale information types for GetLocaleInfoEx.
+const (
+	LOCALE_SLOCALIZEDDISPLAYNAME = 0x00000002 // display name in the language of the user interface
+	LOCALE_SENGLISHDISPLAYNAME   = 0x00000072 // display name in English, such as "German (Germany)"
+	LOCALE_SNATIVEDISPLAYNAME    = 0x00000073 // display name in the language of the locale itself
+)
+
+// LOCALE_ALLOW_NEUTRAL_NAMES lets LCIDToLocaleName return neutral names such as "de".
+const LOCALE_ALLOW_NEUTRAL_NAMES = 0x08000000
+
+// localeNameMaxLength is LOCALE_NAME_MAX_LENGTH, the buffer size for a locale name including the terminator.
+const localeNameMaxLength = 85
+
+// LCIDToLocaleName returns the name, such as "de-DE", of a locale identifier.
+// It fails for identifiers that are not known to the system.
+//
+// Example:
+//
+//	name, err := com.LCIDToLocaleName(0x0407) // "de-DE"
+func LCIDToLocaleName(lcid uint32) (string, error) {
+	var buf [localeNameMaxLength]uint16
+	r0, _, e1 := procLCIDToLocaleName.Call(
+		uintptr(lcid),
+		uintptr(unsafe.Pointer(&buf[0])),
+		uintptr(len(buf)),
+		uintptr(LOCALE_ALLOW_NEUTRAL_NAMES),
+	)
+	if r0 == 0 {
+		return "", e1
+	}
+	return windows.UTF16ToString(buf[:r0]), nil
+}
+
+// GetLocaleInfoEx returns information of type lcType, such as LOCALE_SENGLISHDISPLAYNAME, about the
+// locale with the given name.
+//
+// Example:
+//
+//	name, err := com.GetLocaleInfoEx("de-DE", com.LOCALE_SENGLISHDISPLAYNAME) // "German (Germany)"
+func GetLocaleInfoEx(localeName string, lcType uint32) (string, error) {
+	pName, err := windows.UTF16PtrFromString(localeName)
+	if err != nil {
+		return "", err
+	}
+	// the first call returns the required buffer size, including the terminator
+	r0, _, e1 := procGetLocaleInfoEx.Call(uintptr(unsafe.Pointer(pName)), uintptr(lcType), 0, 0)
+	if r0 == 0 {
+		return "", e1
+	}
+	buf := make([]uint16, r0)
+	r0, _, e1 = procGetLocaleInfoEx.Call(
+		uintptr(unsafe.Pointer(pName)),
+		uintptr(lcType),
+		uintptr(unsafe.Pointer(&buf[0])),
+		uintptr(len(buf)),
+	)
+	if r0 == 0 {
+		return "", e1
+	}
+	return windows.UTF16ToString(buf[:r0]), nil
+}
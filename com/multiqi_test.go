@@ -0,0 +1,36 @@
+//go:build windows
+
+package com
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollectMultiQI(t *testing.T) {
+	server, common := &IUnknown{}, &IUnknown{}
+
+	itfs, err := collectMultiQI([]MULTI_QI{
+		{PIID: &IID_IOPCServer, PItf: server},
+		{PIID: &IID_IOPCCommon, PItf: common},
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []*IUnknown{server, common}, itfs)
+
+	_, err = collectMultiQI([]MULTI_QI{{PIID: &IID_IOPCServer}}, HRESULT(E_FAIL))
+	assert.ErrorIs(t, err, HRESULT(E_FAIL))
+
+	// a failure for every interface leaves nothing to release
+	noInterface := uint32(E_NOINTERFACE)
+	_, err = collectMultiQI([]MULTI_QI{
+		{PIID: &IID_IOPCServer, Hr: int32(noInterface)},
+		{PIID: &IID_IOPCCommon, Hr: int32(noInterface)},
+	}, HRESULT(CO_S_NOTALLINTERFACES))
+	var itfErr *InterfaceError
+	assert.True(t, errors.As(err, &itfErr))
+	assert.Equal(t, IID_IOPCServer, itfErr.IID)
+	assert.ErrorIs(t, err, HRESULT(E_NOINTERFACE))
+	assert.Contains(t, err.Error(), IID_IOPCServer.String())
+}
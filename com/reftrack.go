@@ -0,0 +1,150 @@
+//go:build windows
+
+package com
+
+import (
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/sys/windows"
+)
+
+// refTracking enables the interface reference counters; while it is off, tracking costs one atomic load.
+var refTracking atomic.Bool
+
+// refs holds the interface references acquired while tracking is enabled.
+var refs = struct {
+	sync.Mutex
+	byPointer map[*IUnknown]*trackedRef
+}{byPointer: make(map[*IUnknown]*trackedRef)}
+
+// trackedRef counts the outstanding references of one interface pointer.
+type trackedRef struct {
+	name  string
+	count int
+}
+
+// iidNames names the interfaces of this package in RefSnapshot.
+var iidNames = map[windows.GUID]string{
+	*IID_IUnknown:                    "IUnknown",
+	IID_IConnectionPointContainer:    "IConnectionPointContainer",
+	IID_IEnumUnknown:                 "IEnumUnknown",
+	IID_IOPCAsyncIO2:                 "IOPCAsyncIO2",
+	IID_IOPCBrowse:                   "IOPCBrowse",
+	IID_IOPCBrowseServerAddressSpace: "IOPCBrowseServerAddressSpace",
+	IID_IOPCCommon:                   "IOPCCommon",
+	IID_IOPCGroupStateMgt:            "IOPCGroupStateMgt",
+	IID_IOPCItemDeadbandMgt:          "IOPCItemDeadbandMgt",
+	IID_IOPCItemIO:                   "IOPCItemIO",
+	IID_IOPCItemMgt:                  "IOPCItemMgt",
+	IID_IOPCItemProperties:           "IOPCItemProperties",
+	IID_IOPCItemSamplingMgt:          "IOPCItemSamplingMgt",
+	IID_IOPCPublicGroupStateMgt:      "IOPCPublicGroupStateMgt",
+	IID_IOPCServer:                   "IOPCServer",
+	IID_IOPCServerList:               "IOPCServerList",
+	IID_IOPCServerPublicGroups:       "IOPCServerPublicGroups",
+	IID_IOPCServerList2:              "IOPCServerList2",
+	IID_IOPCSyncIO:                   "IOPCSyncIO",
+	IID_IOPCSyncIO2:                  "IOPCSyncIO2",
+}
+
+// EnableRefTracking turns the debug counters of interface references on or off. While enabled, every
+// interface acquired by MakeCOMObjectEx and its variants, QueryInterface and the wrappers of this package
+// is counted per interface until it is released, and Uninitialize logs the references still outstanding.
+// Enabling or disabling resets the counters. Interfaces acquired while tracking was off are not counted.
+//
+// Example:
+//
+//	com.EnableRefTracking(true)
+//	// ... use the server
+//	fmt.Println(com.RefSnapshot())
+func EnableRefTracking(enabled bool) {
+	refs.Lock()
+	defer refs.Unlock()
+	refs.byPointer = make(map[*IUnknown]*trackedRef)
+	refTracking.Store(enabled)
+}
+
+// RefSnapshot returns the number of outstanding references per interface, keyed by interface name, or by
+// IID for interfaces this package does not define. Interfaces without outstanding references are omitted.
+// It is empty unless EnableRefTracking(true) was called.
+func RefSnapshot() map[string]int {
+	refs.Lock()
+	defer refs.Unlock()
+	snapshot := make(map[string]int)
+	for _, ref := range refs.byPointer {
+		snapshot[ref.name] += ref.count
+	}
+	return snapshot
+}
+
+// iidName returns the name of an interface for the reference counters.
+func iidName(iid *windows.GUID) string {
+	if iid == nil {
+		return "IUnknown"
+	}
+	if name, ok := iidNames[*iid]; ok {
+		return name
+	}
+	return iid.String()
+}
+
+// trackAcquire counts a reference to an interface obtained as iid.
+func trackAcquire(itf *IUnknown, iid *windows.GUID) {
+	if !refTracking.Load() || itf == nil {
+		return
+	}
+	trackAcquireName(itf, iidName(iid))
+}
+
+// trackAcquireName counts a reference to an interface with the given name.
+func trackAcquireName(itf *IUnknown, name string) {
+	if !refTracking.Load() || itf == nil {
+		return
+	}
+	refs.Lock()
+	defer refs.Unlock()
+	if ref, ok := refs.byPointer[itf]; ok {
+		ref.count++
+		return
+	}
+	refs.byPointer[itf] = &trackedRef{name: name, count: 1}
+}
+
+// trackRelease counts the release of a reference. References that were not counted are ignored.
+func trackRelease(itf *IUnknown) {
+	if !refTracking.Load() {
+		return
+	}
+	refs.Lock()
+	defer refs.Unlock()
+	ref, ok := refs.byPointer[itf]
+	if !ok {
+		return
+	}
+	ref.count--
+	if ref.count <= 0 {
+		delete(refs.byPointer, itf)
+	}
+}
+
+// logOutstandingRefs logs the references still outstanding when tracking is enabled.
+func logOutstandingRefs() {
+	if !refTracking.Load() {
+		return
+	}
+	snapshot := RefSnapshot()
+	if len(snapshot) == 0 {
+		return
+	}
+	names := make([]string, 0, len(snapshot))
+	for name, count := range snapshot {
+		names = append(names, name+"="+strconv.Itoa(count))
+	}
+	sort.Strings(names)
+	log.Printf("com: uninitialize with outstanding interface references: %s", strings.Join(names, ", "))
+}
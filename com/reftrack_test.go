@@ -0,0 +1,37 @@
+//go:build windows
+
+package com
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/windows"
+)
+
+func TestRefTracking(t *testing.T) {
+	defer EnableRefTracking(false)
+	server, group := &IUnknown{}, &IUnknown{}
+
+	trackAcquire(server, &IID_IOPCServer)
+	assert.Empty(t, RefSnapshot(), "nothing is counted while tracking is disabled")
+
+	EnableRefTracking(true)
+	trackAcquire(server, &IID_IOPCServer)
+	trackAcquire(server, &IID_IOPCServer)
+	trackAcquire(group, &IID_IOPCGroupStateMgt)
+	trackAcquireName(&IUnknown{}, "IEnumString")
+	assert.Equal(t, map[string]int{"IOPCServer": 2, "IOPCGroupStateMgt": 1, "IEnumString": 1}, RefSnapshot())
+
+	trackRelease(server)
+	trackRelease(group)
+	trackRelease(&IUnknown{}) // not counted
+	assert.Equal(t, map[string]int{"IOPCServer": 1, "IEnumString": 1}, RefSnapshot())
+
+	custom := windows.GUID{Data1: 0x12345678}
+	trackAcquire(group, &custom)
+	assert.Equal(t, 1, RefSnapshot()[custom.String()])
+
+	EnableRefTracking(true)
+	assert.Empty(t, RefSnapshot(), "enabling resets the counters")
+}
@@ -4,6 +4,7 @@ package com
 
 import (
 	"fmt"
+	"math/big"
 	"time"
 	"unsafe"
 
@@ -224,6 +225,31 @@ func (s *SafeArray) ToValueArray() (interface{}, error) {
 			values[i] = date
 		}
 		return values, nil
+	case VT_CY:
+		values := make([]Currency, totalElements)
+		for i := int32(0); i < totalElements; i++ {
+			var v Currency
+			err = safeArrayGetElement(s, i, unsafe.Pointer(&v))
+			if err != nil {
+				return nil, err
+			}
+			values[i] = v
+		}
+		return values, nil
+	case VT_DECIMAL:
+		values := make([]*big.Rat, totalElements)
+		for i := int32(0); i < totalElements; i++ {
+			var d decimal
+			err = safeArrayGetElement(s, i, unsafe.Pointer(&d))
+			if err != nil {
+				return nil, err
+			}
+			values[i], err = d.rat()
+			if err != nil {
+				return nil, err
+			}
+		}
+		return values, nil
 	default:
 		return nil, fmt.Errorf("unknown value type %x", VT(vt))
 	}
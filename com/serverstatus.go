@@ -0,0 +1,61 @@
+//go:build windows
+
+package com
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// String returns the name of the state as in the OPC DA specification, such as "RUNNING".
+func (s OPCServerState) String() string {
+	switch s {
+	case 1:
+		return "RUNNING"
+	case 2:
+		return "FAILED"
+	case 3:
+		return "NOCONFIG"
+	case 4:
+		return "SUSPENDED"
+	case 5:
+		return "TEST"
+	case 6:
+		return "COMM_FAULT"
+	}
+	return fmt.Sprintf("OPCServerState(%d)", uint32(s))
+}
+
+// serverStatusJSON is the wire format of ServerStatus.
+type serverStatusJSON struct {
+	StartTime       string `json:"startTime"`
+	CurrentTime     string `json:"currentTime"`
+	LastUpdateTime  string `json:"lastUpdateTime"`
+	ServerState     uint32 `json:"serverState"`
+	ServerStateName string `json:"serverStateName"`
+	GroupCount      uint32 `json:"groupCount"`
+	BandWidth       uint32 `json:"bandWidth"`
+	MajorVersion    uint16 `json:"majorVersion"`
+	MinorVersion    uint16 `json:"minorVersion"`
+	BuildNumber     uint16 `json:"buildNumber"`
+	VendorInfo      string `json:"vendorInfo"`
+}
+
+// MarshalJSON encodes the status in a stable wire format for health endpoints: times in RFC 3339 UTC,
+// the state both as its number and its name, and without the Reserved field.
+func (s ServerStatus) MarshalJSON() ([]byte, error) {
+	return json.Marshal(serverStatusJSON{
+		StartTime:       s.StartTime.UTC().Format(time.RFC3339Nano),
+		CurrentTime:     s.CurrentTime.UTC().Format(time.RFC3339Nano),
+		LastUpdateTime:  s.LastUpdateTime.UTC().Format(time.RFC3339Nano),
+		ServerState:     uint32(s.ServerState),
+		ServerStateName: s.ServerState.String(),
+		GroupCount:      s.GroupCount,
+		BandWidth:       s.BandWidth,
+		MajorVersion:    s.MajorVersion,
+		MinorVersion:    s.MinorVersion,
+		BuildNumber:     s.BuildNumber,
+		VendorInfo:      s.VendorInfo,
+	})
+}
@@ -0,0 +1,32 @@
+//go:build windows
+
+package com
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerStatus_MarshalJSON(t *testing.T) {
+	local := time.FixedZone("CEST", 2*60*60)
+	status := &ServerStatus{
+		StartTime:   time.Date(2024, 5, 1, 8, 0, 0, 0, local),
+		ServerState: 6,
+		Reserved:    7,
+		VendorInfo:  "Vendor",
+	}
+	b, err := json.Marshal(status)
+	assert.NoError(t, err)
+	var got map[string]interface{}
+	assert.NoError(t, json.Unmarshal(b, &got))
+	assert.Equal(t, "2024-05-01T06:00:00Z", got["startTime"])
+	assert.Equal(t, float64(6), got["serverState"])
+	assert.Equal(t, "COMM_FAULT", got["serverStateName"])
+	assert.NotContains(t, got, "reserved")
+	assert.NotContains(t, got, "Reserved")
+
+	assert.Equal(t, "OPCServerState(9)", OPCServerState(9).String())
+}
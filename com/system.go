@@ -37,7 +37,7 @@ func (p *IConnectionPoint) Vtbl() *IConnectionPointVtbl {
 func (p *IConnectionPoint) Advise(pUnkSink *IUnknown) (cookie uint32, err error) {
 	r0, _, _ := syscall.SyscallN(p.Vtbl().Advise, uintptr(unsafe.Pointer(p.IUnknown)), uintptr(unsafe.Pointer(pUnkSink)), uintptr(unsafe.Pointer(&cookie)))
 	if int32(r0) < 0 {
-		err = syscall.Errno(r0)
+		err = HRESULT(r0)
 	}
 	return
 }
@@ -45,7 +45,7 @@ func (p *IConnectionPoint) Advise(pUnkSink *IUnknown) (cookie uint32, err error)
 func (p *IConnectionPoint) Unadvise(dwCookie uint32) error {
 	r0, _, _ := syscall.SyscallN(p.Vtbl().Unadvise, uintptr(unsafe.Pointer(p.IUnknown)), uintptr(dwCookie))
 	if int32(r0) < 0 {
-		return syscall.Errno(r0)
+		return HRESULT(r0)
 	}
 	return nil
 }
@@ -86,7 +86,8 @@ func (c *IConnectionPointContainer) FindConnectionPoint(riid *windows.GUID) (*IC
 		uintptr(unsafe.Pointer(&iUnknown)),
 	)
 	if int32(r0) < 0 {
-		return nil, syscall.Errno(r0)
+		return nil, HRESULT(r0)
 	}
+	trackAcquireName(iUnknown, "IConnectionPoint")
 	return &IConnectionPoint{iUnknown}, nil
 }
@@ -3,8 +3,10 @@
 package com
 
 import (
+	"errors"
 	"fmt"
 	"math"
+	"math/big"
 	"time"
 	"unsafe"
 
@@ -19,8 +21,111 @@ func (v *VARIANT) IsArray() bool {
 	return v.VT&VT_ARRAY == VT_ARRAY
 }
 
+// ErrUnsupportedVariant is returned when a VARIANT holds a type that cannot be converted to a Go value,
+// or when the conversion of its payload fails.
+type ErrUnsupportedVariant struct {
+	// VT is the raw variant type, including any VT_ARRAY or VT_BYREF flags.
+	VT VT
+	// Err is the underlying conversion error, if any.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *ErrUnsupportedVariant) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("unsupported VARIANT type 0x%04x: %v", uint16(e.VT), e.Err)
+	}
+	return fmt.Sprintf("unsupported VARIANT type 0x%04x", uint16(e.VT))
+}
+
+// Unwrap returns the underlying conversion error.
+func (e *ErrUnsupportedVariant) Unwrap() error {
+	return e.Err
+}
+
+// byRefSize returns the number of bytes referenced by a VT_BYREF variant of the given base type,
+// or 0 if the type is not supported by reference.
+func byRefSize(vt VT) uintptr {
+	if vt&VT_ARRAY == VT_ARRAY {
+		return unsafe.Sizeof(uintptr(0))
+	}
+	switch vt {
+	case VT_I1, VT_UI1:
+		return 1
+	case VT_I2, VT_UI2, VT_BOOL:
+		return 2
+	case VT_I4, VT_UI4, VT_INT, VT_UINT, VT_R4, VT_ERROR:
+		return 4
+	case VT_I8, VT_UI8, VT_R8, VT_DATE, VT_CY:
+		return 8
+	case VT_BSTR:
+		return unsafe.Sizeof(uintptr(0))
+	}
+	return 0
+}
+
+// deref returns a copy of a VT_BYREF variant holding the referenced value directly.
+// The copy shares any BSTR or SAFEARRAY with the original and must not be cleared.
+func (v *VARIANT) deref() (*VARIANT, error) {
+	p := *(*unsafe.Pointer)(unsafe.Pointer(&v.Val))
+	if p == nil {
+		return nil, &ErrUnsupportedVariant{VT: v.VT, Err: fmt.Errorf("nil reference")}
+	}
+	base := v.VT &^ VT_BYREF
+	if base == VT_VARIANT {
+		return (*VARIANT)(p), nil
+	}
+	if base == VT_DECIMAL {
+		out := &VARIANT{}
+		*decimalOf(out) = *(*decimal)(p)
+		out.VT = VT_DECIMAL
+		return out, nil
+	}
+	out := &VARIANT{VT: base}
+	switch byRefSize(base) {
+	case 1:
+		out.Val = int64(*(*uint8)(p))
+	case 2:
+		out.Val = int64(*(*uint16)(p))
+	case 4:
+		out.Val = int64(*(*uint32)(p))
+	case 8:
+		out.Val = *(*int64)(p)
+	default:
+		return nil, &ErrUnsupportedVariant{VT: v.VT}
+	}
+	if base == VT_BSTR || base&VT_ARRAY == VT_ARRAY {
+		*(*unsafe.Pointer)(unsafe.Pointer(&out.Val)) = *(*unsafe.Pointer)(p)
+	}
+	return out, nil
+}
+
+// ValueOrUnsupported converts a VARIANT read from the server or passed to a callback. A value that cannot
+// be converted is returned as *ErrUnsupportedVariant so the caller can report it for that entry alone.
+//
+// Example:
+//
+//	v := com.ValueOrUnsupported(&variant)
+//	if unsupported, ok := v.(*com.ErrUnsupportedVariant); ok {
+//		log.Printf("VT %d not supported", unsupported.VT)
+//	}
+func ValueOrUnsupported(variant *VARIANT) interface{} {
+	v, err := variant.Value()
+	if err != nil {
+		var unsupported *ErrUnsupportedVariant
+		if errors.As(err, &unsupported) {
+			return unsupported
+		}
+		return &ErrUnsupportedVariant{VT: variant.VT, Err: err}
+	}
+	return v
+}
+
 // Value returns the value held by the VARIANT as a Go interface{} and an error if conversion fails.
-// It handles basic types, strings, dates, and arrays.
+// It handles basic types, strings, dates, error codes, arrays and VT_BYREF references to them.
+// VT_CY values are returned as Currency and VT_DECIMAL values as an exact *big.Rat. VT_DATE values, scalar,
+// by reference or in arrays, are converted with GetVariantDate to a time.Time in UTC.
+// Types it cannot convert are reported as *ErrUnsupportedVariant.
 //
 // Example:
 //
@@ -35,11 +140,21 @@ func (v *VARIANT) Value() (interface{}, error) {
 	if v.VT == VT_EMPTY || v.VT == VT_NULL {
 		return nil, nil
 	}
+	if v.VT&VT_BYREF == VT_BYREF {
+		ref, err := v.deref()
+		if err != nil {
+			return nil, err
+		}
+		return ref.Value()
+	}
 	if v.IsArray() {
 		safeArray := *(**SafeArray)(unsafe.Pointer(&v.Val))
+		if safeArray == nil {
+			return nil, &ErrUnsupportedVariant{VT: v.VT, Err: fmt.Errorf("nil SAFEARRAY")}
+		}
 		values, err := safeArray.ToValueArray()
 		if err != nil {
-			return nil, err
+			return nil, &ErrUnsupportedVariant{VT: v.VT, Err: err}
 		}
 		return values, nil
 	}
@@ -74,13 +189,23 @@ func (v *VARIANT) Value() (interface{}, error) {
 		d := uint64(v.Val)
 		date, err := GetVariantDate(d)
 		if err != nil {
-			return nil, err
+			return nil, &ErrUnsupportedVariant{VT: v.VT, Err: err}
 		}
 		return date, nil
 	case VT_BOOL:
 		return (v.Val & 0xffff) != 0, nil
+	case VT_ERROR:
+		return int32(v.Val), nil
+	case VT_CY:
+		return Currency(v.Val), nil
+	case VT_DECIMAL:
+		r, err := decimalOf(v).rat()
+		if err != nil {
+			return nil, &ErrUnsupportedVariant{VT: v.VT, Err: err}
+		}
+		return r, nil
 	}
-	return nil, nil
+	return nil, &ErrUnsupportedVariant{VT: v.VT}
 }
 
 // VariantWrapper wraps a VARIANT and provides helper methods for setting and clearing values.
@@ -283,6 +408,24 @@ func (vw *VariantWrapper) SetValue(val interface{}) error {
 			safeArrayPutElement(array, int64(i), uintptr(unsafe.Pointer(&date)))
 		}
 		v.Val = int64(uintptr(unsafe.Pointer(array)))
+	case Currency:
+		v.VT = VT_CY
+		v.Val = int64(val.(Currency))
+	case []Currency:
+		v.VT = VT_ARRAY | VT_CY
+		values := val.([]Currency)
+		array, _ := safeArrayCreateVector(VT_CY, 0, uint32(len(values)))
+		for i, value := range values {
+			safeArrayPutElement(array, int64(i), uintptr(unsafe.Pointer(&value)))
+		}
+		v.Val = int64(uintptr(unsafe.Pointer(array)))
+	case *big.Rat:
+		d, err := newDecimal(val.(*big.Rat))
+		if err != nil {
+			return err
+		}
+		*decimalOf(v) = d
+		v.VT = VT_DECIMAL
 	case bool:
 		v.VT = VT_BOOL
 		if val.(bool) {
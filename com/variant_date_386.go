@@ -14,7 +14,9 @@ import (
 const ONETHOUSANDMILLISECONDS = 0.0000115740740740
 const OneMilliSecond = ONETHOUSANDMILLISECONDS / 1000
 
-// GetVariantDate converts COM Variant Time value to Go time.Time.
+// GetVariantDate converts COM Variant Time value to Go time.Time, rounded to the millisecond.
+// A variant time carries no time zone; its wall clock is returned in time.UTC, as OPC timestamps are UTC.
+// For servers that report local time, reinterpret the result with time.Date and time.Local.
 func GetVariantDate(value uint64) (time.Time, error) {
 	halfSecond := ONETHOUSANDMILLISECONDS / 2.0
 	dVariantTime := math.Float64frombits(value)
@@ -47,8 +49,10 @@ func GetVariantDate(value uint64) (time.Time, error) {
 	return time.Now(), errors.New("Could not convert to time, passing current time.")
 }
 
-// TimeToVariantDate converts a Go time.Time to a COM Variant Time value.
+// TimeToVariantDate converts a Go time.Time to a COM Variant Time value holding its UTC wall clock,
+// truncated to the millisecond. It is the inverse of GetVariantDate.
 func TimeToVariantDate(t time.Time) (uint64, error) {
+	t = t.UTC()
 	var st syscall.Systemtime
 	st.Year = uint16(t.Year())
 	st.Month = uint16(t.Month())
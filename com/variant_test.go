@@ -0,0 +1,125 @@
+//go:build windows
+
+package com
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/windows"
+)
+
+func byRefVariant(vt VT, p unsafe.Pointer) *VARIANT {
+	v := &VARIANT{VT: vt | VT_BYREF}
+	*(*unsafe.Pointer)(unsafe.Pointer(&v.Val)) = p
+	return v
+}
+
+func TestVARIANT_Value_ByRefScalars(t *testing.T) {
+	i1 := int8(-5)
+	ui2 := uint16(65535)
+	i4 := int32(-123456)
+	ui4 := uint32(4000000000)
+	i8 := int64(-1 << 40)
+	r4 := float32(1.5)
+	r8 := math.Pi
+	vbTrue := int16(-1)
+	scode := int32(-2147467259)
+	bstr := windows.StringToUTF16Ptr("Kelvin")
+
+	tests := []struct {
+		name    string
+		variant *VARIANT
+		want    interface{}
+	}{
+		{"I1", byRefVariant(VT_I1, unsafe.Pointer(&i1)), int8(-5)},
+		{"UI2", byRefVariant(VT_UI2, unsafe.Pointer(&ui2)), uint16(65535)},
+		{"I4", byRefVariant(VT_I4, unsafe.Pointer(&i4)), int32(-123456)},
+		{"UI4", byRefVariant(VT_UI4, unsafe.Pointer(&ui4)), uint32(4000000000)},
+		{"I8", byRefVariant(VT_I8, unsafe.Pointer(&i8)), int64(-1 << 40)},
+		{"R4", byRefVariant(VT_R4, unsafe.Pointer(&r4)), float32(1.5)},
+		{"R8", byRefVariant(VT_R8, unsafe.Pointer(&r8)), math.Pi},
+		{"BOOL", byRefVariant(VT_BOOL, unsafe.Pointer(&vbTrue)), true},
+		{"ERROR", byRefVariant(VT_ERROR, unsafe.Pointer(&scode)), int32(-2147467259)},
+		{"BSTR", byRefVariant(VT_BSTR, unsafe.Pointer(&bstr)), "Kelvin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.variant.Value()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestVARIANT_Value_ByRefVariant(t *testing.T) {
+	inner := VARIANT{VT: VT_I2, Val: 42}
+	got, err := byRefVariant(VT_VARIANT, unsafe.Pointer(&inner)).Value()
+	assert.NoError(t, err)
+	assert.Equal(t, int16(42), got)
+}
+
+func TestVARIANT_Value_Date(t *testing.T) {
+	// 2024-01-02 03:04:05 is 45293.1278356... days after the variant time epoch of 1899-12-30
+	date := math.Float64bits(45293.0 + (3*3600+4*60+5)/86400.0)
+	want := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	got, err := (&VARIANT{VT: VT_DATE, Val: int64(date)}).Value()
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	got, err = byRefVariant(VT_DATE, unsafe.Pointer(&date)).Value()
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	// property values such as OPC_PROPERTY_TIMESTAMP take the same path
+	assert.Equal(t, want, ValueOrUnsupported(&VARIANT{VT: VT_DATE, Val: int64(date)}))
+}
+
+func TestVARIANT_Value_Unsupported(t *testing.T) {
+	tests := []struct {
+		name    string
+		variant *VARIANT
+		vt      VT
+	}{
+		{"UNKNOWN", &VARIANT{VT: VT_UNKNOWN}, VT_UNKNOWN},
+		{"DISPATCH", &VARIANT{VT: VT_DISPATCH}, VT_DISPATCH},
+		{"nil reference", &VARIANT{VT: VT_I4 | VT_BYREF}, VT_I4 | VT_BYREF},
+		{"nil array", &VARIANT{VT: VT_R8 | VT_ARRAY}, VT_R8 | VT_ARRAY},
+		{"RECORD by reference", byRefVariant(VT_RECORD, unsafe.Pointer(new(int64))), VT_RECORD | VT_BYREF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.variant.Value()
+			assert.Nil(t, got)
+			var unsupported *ErrUnsupportedVariant
+			if assert.True(t, errors.As(err, &unsupported)) {
+				assert.Equal(t, tt.vt, unsupported.VT)
+			}
+		})
+	}
+}
+
+func TestPropertyValue_KeepsSiblings(t *testing.T) {
+	i4 := int32(7)
+	variants := []*VARIANT{
+		{VT: VT_R8, Val: int64(math.Float64bits(2.5))},
+		{VT: VT_DISPATCH, Val: 1},
+		byRefVariant(VT_I4, unsafe.Pointer(&i4)),
+	}
+	values := make([]interface{}, len(variants))
+	for i, v := range variants {
+		values[i] = ValueOrUnsupported(v)
+	}
+	assert.Equal(t, 2.5, values[0])
+	unsupported, ok := values[1].(*ErrUnsupportedVariant)
+	if assert.True(t, ok) {
+		assert.Equal(t, VT_DISPATCH, unsupported.VT)
+		assert.Contains(t, unsupported.Error(), "0x0009")
+	}
+	assert.Equal(t, int32(7), values[2])
+}
@@ -0,0 +1,225 @@
+//go:build windows
+
+package com
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"time"
+)
+
+// integerBounds are the ranges of the integer variant types. VT_INT and VT_UINT are 32-bit in a VARIANT.
+var integerBounds = map[VT][2]*big.Int{
+	VT_I1:   {big.NewInt(math.MinInt8), big.NewInt(math.MaxInt8)},
+	VT_UI1:  {big.NewInt(0), big.NewInt(math.MaxUint8)},
+	VT_I2:   {big.NewInt(math.MinInt16), big.NewInt(math.MaxInt16)},
+	VT_UI2:  {big.NewInt(0), big.NewInt(math.MaxUint16)},
+	VT_I4:   {big.NewInt(math.MinInt32), big.NewInt(math.MaxInt32)},
+	VT_UI4:  {big.NewInt(0), big.NewInt(math.MaxUint32)},
+	VT_I8:   {big.NewInt(math.MinInt64), big.NewInt(math.MaxInt64)},
+	VT_UI8:  {big.NewInt(0), new(big.Int).SetUint64(math.MaxUint64)},
+	VT_INT:  {big.NewInt(math.MinInt32), big.NewInt(math.MaxInt32)},
+	VT_UINT: {big.NewInt(0), big.NewInt(math.MaxUint32)},
+}
+
+// typedGoTypes are the Go types NewVariant writes as the scalar variant types NewVariantTyped supports.
+var typedGoTypes = map[VT]reflect.Type{
+	VT_I1:      reflect.TypeFor[int8](),
+	VT_UI1:     reflect.TypeFor[uint8](),
+	VT_I2:      reflect.TypeFor[int16](),
+	VT_UI2:     reflect.TypeFor[uint16](),
+	VT_I4:      reflect.TypeFor[int32](),
+	VT_UI4:     reflect.TypeFor[uint32](),
+	VT_I8:      reflect.TypeFor[int64](),
+	VT_UI8:     reflect.TypeFor[uint64](),
+	VT_INT:     reflect.TypeFor[int](),
+	VT_UINT:    reflect.TypeFor[uint](),
+	VT_R4:      reflect.TypeFor[float32](),
+	VT_R8:      reflect.TypeFor[float64](),
+	VT_CY:      reflect.TypeFor[Currency](),
+	VT_DECIMAL: reflect.TypeFor[*big.Rat](),
+	VT_BOOL:    reflect.TypeFor[bool](),
+	VT_BSTR:    reflect.TypeFor[string](),
+	VT_DATE:    reflect.TypeFor[time.Time](),
+}
+
+// NewVariantTyped creates a VariantWrapper holding val as the variant type vt instead of the type NewVariant
+// infers from the Go type, for servers that expect a particular type on the wire.
+//
+// Numbers of any Go numeric type, Currency and *big.Rat convert to any numeric vt: VT_I1 to VT_UI8, VT_INT,
+// VT_UINT, VT_R4, VT_R8, VT_CY and VT_DECIMAL. The conversion fails if the value is outside the range of vt
+// or, for the integer types, has a fraction; floating-point targets and VT_CY round to their precision.
+// VT_BOOL, VT_BSTR and VT_DATE take bool, string and time.Time values only. For VT_ARRAY types val must be a
+// slice, whose elements are converted one by one; arrays of VT_DECIMAL are not supported.
+//
+// Example:
+//
+//	// the server expects VT_R4 for a setpoint held as an int
+//	vw, err := com.NewVariantTyped(setpoint, com.VT_R4)
+//	if err == nil {
+//		defer vw.Clear()
+//	}
+func NewVariantTyped(val interface{}, vt VT) (*VariantWrapper, error) {
+	converted, err := coerceToVT(val, vt)
+	if err != nil {
+		return nil, err
+	}
+	return NewVariant(converted)
+}
+
+// coerceToVT converts val to the Go type NewVariant writes as vt.
+func coerceToVT(val interface{}, vt VT) (interface{}, error) {
+	isSlice := val != nil && reflect.TypeOf(val).Kind() == reflect.Slice
+	if vt&VT_ARRAY != VT_ARRAY {
+		if isSlice {
+			return nil, fmt.Errorf("cannot write %T as the scalar VT 0x%04X", val, uint16(vt))
+		}
+		return coerceScalar(val, vt)
+	}
+	elem := vt &^ VT_ARRAY
+	goType, ok := typedGoTypes[elem]
+	if !ok || elem == VT_DECIMAL {
+		return nil, fmt.Errorf("unsupported array VT 0x%04X", uint16(vt))
+	}
+	if !isSlice {
+		return nil, fmt.Errorf("cannot write %T as the array VT 0x%04X", val, uint16(vt))
+	}
+	values := reflect.ValueOf(val)
+	out := reflect.MakeSlice(reflect.SliceOf(goType), values.Len(), values.Len())
+	for i := 0; i < values.Len(); i++ {
+		v, err := coerceScalar(values.Index(i).Interface(), elem)
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		out.Index(i).Set(reflect.ValueOf(v))
+	}
+	return out.Interface(), nil
+}
+
+// coerceScalar converts val to the Go type NewVariant writes as the scalar type vt.
+//
+//gocyclo:ignore
+func coerceScalar(val interface{}, vt VT) (interface{}, error) {
+	switch vt {
+	case VT_BOOL, VT_BSTR, VT_DATE:
+		if val == nil || reflect.TypeOf(val) != typedGoTypes[vt] {
+			return nil, fmt.Errorf("cannot convert %T to VT 0x%04X", val, uint16(vt))
+		}
+		return val, nil
+	case VT_R4, VT_R8:
+		// floating-point values, including NaN and infinities, convert directly
+		switch f := val.(type) {
+		case float32:
+			if vt == VT_R4 {
+				return f, nil
+			}
+			return float64(f), nil
+		case float64:
+			if vt == VT_R8 {
+				return f, nil
+			}
+			if !math.IsInf(f, 0) && !math.IsNaN(f) && math.Abs(f) > math.MaxFloat32 {
+				return nil, fmt.Errorf("%v is outside the range of VT 0x%04X", f, uint16(vt))
+			}
+			return float32(f), nil
+		}
+	}
+	if _, ok := typedGoTypes[vt]; !ok {
+		return nil, fmt.Errorf("unsupported VT 0x%04X", uint16(vt))
+	}
+	r, err := numberToRat(val)
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert %T to VT 0x%04X: %w", val, uint16(vt), err)
+	}
+	switch vt {
+	case VT_R4:
+		f, _ := r.Float32()
+		if math.IsInf(float64(f), 0) {
+			return nil, fmt.Errorf("%s is outside the range of VT 0x%04X", r.RatString(), uint16(vt))
+		}
+		return f, nil
+	case VT_R8:
+		f, _ := r.Float64()
+		if math.IsInf(f, 0) {
+			return nil, fmt.Errorf("%s is outside the range of VT 0x%04X", r.RatString(), uint16(vt))
+		}
+		return f, nil
+	case VT_CY:
+		scaled := roundScaled(r, 4)
+		if r.Sign() < 0 {
+			scaled.Neg(scaled)
+		}
+		if !scaled.IsInt64() {
+			return nil, fmt.Errorf("%s is outside the range of VT_CY", r.RatString())
+		}
+		return Currency(scaled.Int64()), nil
+	case VT_DECIMAL:
+		return r, nil
+	}
+	if !r.IsInt() {
+		return nil, fmt.Errorf("%s has a fraction and cannot be written as the integer VT 0x%04X", r.RatString(), uint16(vt))
+	}
+	n := r.Num()
+	bounds := integerBounds[vt]
+	if n.Cmp(bounds[0]) < 0 || n.Cmp(bounds[1]) > 0 {
+		return nil, fmt.Errorf("%s is outside the range of VT 0x%04X", n.String(), uint16(vt))
+	}
+	switch vt {
+	case VT_I1:
+		return int8(n.Int64()), nil
+	case VT_UI1:
+		return uint8(n.Uint64()), nil
+	case VT_I2:
+		return int16(n.Int64()), nil
+	case VT_UI2:
+		return uint16(n.Uint64()), nil
+	case VT_I4:
+		return int32(n.Int64()), nil
+	case VT_UI4:
+		return uint32(n.Uint64()), nil
+	case VT_I8:
+		return n.Int64(), nil
+	case VT_UI8:
+		return n.Uint64(), nil
+	case VT_INT:
+		return int(n.Int64()), nil
+	default: // VT_UINT
+		return uint(n.Uint64()), nil
+	}
+}
+
+// numberToRat returns the exact value of a Go number, Currency or *big.Rat.
+func numberToRat(val interface{}) (*big.Rat, error) {
+	switch v := val.(type) {
+	case Currency:
+		return big.NewRat(int64(v), currencyScale), nil
+	case *big.Rat:
+		if v == nil {
+			return nil, fmt.Errorf("nil *big.Rat")
+		}
+		return new(big.Rat).Set(v), nil
+	case float32:
+		return floatToRat(float64(v))
+	case float64:
+		return floatToRat(v)
+	}
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return new(big.Rat).SetInt64(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Rat).SetUint64(rv.Uint()), nil
+	}
+	return nil, fmt.Errorf("not a number")
+}
+
+// floatToRat returns the exact value of a finite float.
+func floatToRat(f float64) (*big.Rat, error) {
+	r := new(big.Rat).SetFloat64(f)
+	if r == nil {
+		return nil, fmt.Errorf("%v is not finite", f)
+	}
+	return r, nil
+}
@@ -0,0 +1,94 @@
+//go:build windows
+
+package com
+
+import (
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoerceToVT(t *testing.T) {
+	tests := []struct {
+		name string
+		val  interface{}
+		vt   VT
+		want interface{}
+	}{
+		{"int as R4", 3, VT_R4, float32(3)},
+		{"integral float as I2", float64(2), VT_I2, int16(2)},
+		{"uint64 max as UI8", uint64(math.MaxUint64), VT_UI8, uint64(math.MaxUint64)},
+		{"float as CY", 12.34567, VT_CY, Currency(123457)},
+		{"currency as R8", Currency(15000), VT_R8, 1.5},
+		{"rational as R8", big.NewRat(1, 4), VT_R8, 0.25},
+		{"int as DECIMAL", int64(7), VT_DECIMAL, big.NewRat(7, 1)},
+		{"float32 NaN as R8", float32(math.NaN()), VT_R8, math.NaN()},
+		{"bool", true, VT_BOOL, true},
+		{"ints as R8 array", []int{1, 2}, VT_ARRAY | VT_R8, []float64{1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := coerceToVT(tt.val, tt.vt)
+			assert.NoError(t, err)
+			switch want := tt.want.(type) {
+			case *big.Rat:
+				assert.Equal(t, 0, want.Cmp(got.(*big.Rat)))
+			case float64:
+				if math.IsNaN(want) {
+					assert.True(t, math.IsNaN(got.(float64)))
+					return
+				}
+				assert.Equal(t, want, got)
+			default:
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCoerceToVT_Impossible(t *testing.T) {
+	tests := []struct {
+		name string
+		val  interface{}
+		vt   VT
+	}{
+		{"fraction as I4", 1.5, VT_I4},
+		{"overflow UI1", 300, VT_UI1},
+		{"negative UI4", -1, VT_UI4},
+		{"overflow R4", 1e300, VT_R4},
+		{"infinity as I4", math.Inf(1), VT_I4},
+		{"string as I4", "42", VT_I4},
+		{"number as BSTR", 42, VT_BSTR},
+		{"number as DATE", 42, VT_DATE},
+		{"time as R8", time.Now(), VT_R8},
+		{"nil", nil, VT_I4},
+		{"slice as scalar", []int{1}, VT_I4},
+		{"scalar as array", 5, VT_ARRAY | VT_I4},
+		{"fraction in array", []float64{1, 1.5}, VT_ARRAY | VT_I4},
+		{"DECIMAL array", []int{1}, VT_ARRAY | VT_DECIMAL},
+		{"unsupported VT", 1, VT_DISPATCH},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := coerceToVT(tt.val, tt.vt)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNewVariantTyped(t *testing.T) {
+	vw, err := NewVariantTyped(3, VT_R4)
+	if assert.NoError(t, err) {
+		defer vw.Clear()
+		assert.Equal(t, VT_R4, vw.Variant.VT)
+		got, err := vw.Variant.Value()
+		assert.NoError(t, err)
+		assert.Equal(t, float32(3), got)
+	}
+
+	_, err = NewVariantTyped(70000, VT_I2)
+	assert.Error(t, err)
+}
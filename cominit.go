@@ -0,0 +1,80 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/wends155/opcda/com"
+)
+
+// ErrCOMNotInitialized is returned by Connect and its variants when COM has not been initialized, which
+// the server reports as CO_E_NOTINITIALIZED. Call com.Initialize before connecting, or enable
+// SetAutoInitializeCOM.
+var ErrCOMNotInitialized = errors.New("COM is not initialized: call com.Initialize() on this goroutine first")
+
+// autoInitializeCOM enables the initialization of COM by connect, see SetAutoInitializeCOM.
+var autoInitializeCOM atomic.Bool
+
+// autoInit records whether connect has initialized COM for the process.
+var autoInit struct {
+	sync.Mutex
+	done bool
+}
+
+// SetAutoInitializeCOM makes Connect and its variants initialize COM themselves when they find it not
+// initialized, instead of returning ErrCOMNotInitialized. COM is initialized once for the whole process
+// in the multithreaded apartment, with com.DefaultInitConfig and tolerating a host that initialized it
+// first, and stays initialized until the process exits. Applications that need their own security
+// settings or apartment should call com.InitializeWithConfig instead. It is disabled by default.
+//
+// Example:
+//
+//	opcda.SetAutoInitializeCOM(true)
+//	server, err := opcda.Connect("Matrikon.OPC.Simulation.1", "localhost")
+func SetAutoInitializeCOM(enabled bool) {
+	autoInitializeCOM.Store(enabled)
+}
+
+// connect establishes a connection to the OPC server with the COM factories, authenticating remote calls
+// with authInfo when it is not nil.
+func connect(progID, node string, authInfo *com.COAUTHINFO) (*OPCServer, error) {
+	return comFactories().connect(progID, node, authInfo)
+}
+
+// connectCOM establishes a connection with f.dial. A failure because COM is not initialized is reported as
+// ErrCOMNotInitialized, or repaired by initializing COM and connecting again if SetAutoInitializeCOM is
+// enabled.
+func (f *connectionFactories) connectCOM(progID, node string, authInfo *com.COAUTHINFO) (*OPCServer, error) {
+	server, err := f.dial(progID, node, authInfo)
+	if err == nil || !errors.Is(err, com.HRESULT(com.CO_E_NOTINITIALIZED)) {
+		return server, err
+	}
+	if !autoInitializeCOM.Load() {
+		return nil, fmt.Errorf("%w: %w", ErrCOMNotInitialized, err)
+	}
+	if initErr := initializeProcessCOM(); initErr != nil {
+		return nil, fmt.Errorf("%w: automatic initialization failed: %w", ErrCOMNotInitialized, initErr)
+	}
+	return f.dial(progID, node, authInfo)
+}
+
+// initializeProcessCOM initializes COM in the multithreaded apartment the first time it is called. The
+// reference is never released, so every thread of the process shares the apartment implicitly.
+func initializeProcessCOM() error {
+	autoInit.Lock()
+	defer autoInit.Unlock()
+	if autoInit.done {
+		return nil
+	}
+	config := com.DefaultInitConfig()
+	config.TolerateExisting = true
+	if _, err := initializeCOM(config); err != nil {
+		return err
+	}
+	autoInit.done = true
+	return nil
+}
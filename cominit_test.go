@@ -0,0 +1,94 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wends155/opcda/com"
+)
+
+// swapProcessCOM restores the process-wide initialization of COM by connect after a test.
+func swapProcessCOM(t *testing.T) {
+	oldInit, oldEnabled := initializeCOM, autoInitializeCOM.Load()
+	t.Cleanup(func() {
+		initializeCOM = oldInit
+		autoInitializeCOM.Store(oldEnabled)
+		autoInit.Lock()
+		autoInit.done = false
+		autoInit.Unlock()
+	})
+}
+
+func TestConnect_COMNotInitialized_Mocked(t *testing.T) {
+	notInitialized := NewOPCWrapperError("make com object OPC server", com.HRESULT(com.CO_E_NOTINITIALIZED))
+	dials := 0
+	swapProcessCOM(t)
+	f := comFactories()
+	f.dial = func(progID, node string, authInfo *com.COAUTHINFO) (*OPCServer, error) {
+		dials++
+		return nil, notInitialized
+	}
+	initializeCOM = func(config *com.InitConfig) (com.InitResult, error) {
+		t.Fatal("COM must not be initialized unless enabled")
+		return com.InitResult{}, nil
+	}
+
+	_, err := f.connect("Mock.Server", "localhost", nil)
+	assert.ErrorIs(t, err, ErrCOMNotInitialized)
+	assert.ErrorIs(t, err, com.HRESULT(com.CO_E_NOTINITIALIZED))
+	assert.Contains(t, err.Error(), "com.Initialize()")
+	assert.Equal(t, 1, dials)
+
+	other := errors.New("access denied")
+	f.dial = func(progID, node string, authInfo *com.COAUTHINFO) (*OPCServer, error) {
+		return nil, other
+	}
+	_, err = f.connect("Mock.Server", "localhost", nil)
+	assert.Same(t, other, err)
+}
+
+func TestConnect_AutoInitializeCOM_Mocked(t *testing.T) {
+	initialized := false
+	server := newOPCServerWithProvider(&mockServerProvider{}, "mock", "localhost")
+	swapProcessCOM(t)
+	f := comFactories()
+	f.dial = func(progID, node string, authInfo *com.COAUTHINFO) (*OPCServer, error) {
+		if !initialized {
+			return nil, fmt.Errorf("resolve: %w", com.HRESULT(com.CO_E_NOTINITIALIZED))
+		}
+		return server, nil
+	}
+	inits := 0
+	initializeCOM = func(config *com.InitConfig) (com.InitResult, error) {
+		inits++
+		assert.True(t, config.TolerateExisting)
+		initialized = true
+		return com.InitResult{NeedsUninitialize: true}, nil
+	}
+	SetAutoInitializeCOM(true)
+
+	s, err := f.connect("Mock.Server", "localhost", nil)
+	assert.NoError(t, err)
+	assert.Same(t, server, s)
+	assert.Equal(t, 1, inits)
+
+	// once initialized, COM is not initialized again
+	initialized = false
+	_, err = f.connect("Mock.Server", "localhost", nil)
+	assert.ErrorIs(t, err, com.HRESULT(com.CO_E_NOTINITIALIZED))
+	assert.Equal(t, 1, inits)
+
+	autoInit.Lock()
+	autoInit.done = false
+	autoInit.Unlock()
+	initializeCOM = func(config *com.InitConfig) (com.InitResult, error) {
+		return com.InitResult{}, com.ErrApartmentMismatch
+	}
+	_, err = f.connect("Mock.Server", "localhost", nil)
+	assert.ErrorIs(t, err, ErrCOMNotInitialized)
+	assert.ErrorIs(t, err, com.ErrApartmentMismatch)
+}
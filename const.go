@@ -24,6 +24,14 @@ var IID_CATID_OPCDAServer20 = windows.GUID{
 	Data4: [8]byte{0xB2, 0xC8, 0x00, 0x60, 0x08, 0x3B, 0xA1, 0xFB},
 }
 
+// IID_CATID_OPCDAServer30 is the CATID for OPC DA 3.0 servers.
+var IID_CATID_OPCDAServer30 = windows.GUID{
+	Data1: 0xCC603642,
+	Data2: 0x66D7,
+	Data3: 0x48F1,
+	Data4: [8]byte{0xB6, 0x9A, 0xB6, 0x25, 0xE7, 0x36, 0x52, 0xD7},
+}
+
 // IID_IOPCShutdown is the GUID for the IOPCShutdown interface.
 var IID_IOPCShutdown = windows.GUID{
 	Data1: 0xF31DFDE1,
@@ -40,6 +48,21 @@ const (
 	OPC_WRITEABLE = 0x2
 )
 
+const (
+	// OPC_QUALITY_MASK selects the major quality bits (GOOD, UNCERTAIN, BAD).
+	OPC_QUALITY_MASK uint16 = 0xC0
+	// OPC_STATUS_MASK selects the quality and substatus bits, excluding the limit bits.
+	OPC_STATUS_MASK uint16 = 0xFC
+	// OPC_QUALITY_BAD indicates that the value is not useful.
+	OPC_QUALITY_BAD uint16 = 0x00
+	// OPC_QUALITY_UNCERTAIN indicates that the quality of the value is uncertain.
+	OPC_QUALITY_UNCERTAIN uint16 = 0x40
+	// OPC_QUALITY_GOOD indicates that the quality of the value is good.
+	OPC_QUALITY_GOOD uint16 = 0xC0
+	// OPC_QUALITY_WAITING_FOR_INITIAL_DATA indicates that the server has not yet produced a value for an item since it was activated.
+	OPC_QUALITY_WAITING_FOR_INITIAL_DATA uint16 = 0x20
+)
+
 const (
 	// OPC_DS_CACHE indicates that the data should be read from the cache.
 	OPC_DS_CACHE com.OPCDATASOURCE = 1
@@ -101,3 +124,33 @@ const (
 	// OPC_ENUM_ALL indicates all enumerations.
 	OPC_ENUM_ALL = OPC_ENUM_PUBLIC + 1
 )
+
+// OPC_BANDWIDTH_NOT_SUPPORTED is the bandwidth reported by servers that do not measure their bandwidth usage.
+const OPC_BANDWIDTH_NOT_SUPPORTED = uint32(0xFFFFFFFF)
+
+const (
+	// OPC_PROPERTY_DATATYPE is the canonical data type of the item.
+	OPC_PROPERTY_DATATYPE PropertyID = 1
+	// OPC_PROPERTY_VALUE is the current value of the item.
+	OPC_PROPERTY_VALUE PropertyID = 2
+	// OPC_PROPERTY_QUALITY is the current quality of the item.
+	OPC_PROPERTY_QUALITY PropertyID = 3
+	// OPC_PROPERTY_TIMESTAMP is the timestamp of the current value of the item.
+	OPC_PROPERTY_TIMESTAMP PropertyID = 4
+	// OPC_PROPERTY_ACCESS_RIGHTS is the access rights of the item.
+	OPC_PROPERTY_ACCESS_RIGHTS PropertyID = 5
+	// OPC_PROPERTY_SCAN_RATE is the fastest rate at which the server can obtain data for the item.
+	OPC_PROPERTY_SCAN_RATE PropertyID = 6
+	// OPC_PROPERTY_EU_UNITS is the engineering units of the item, such as "DEGC".
+	OPC_PROPERTY_EU_UNITS PropertyID = 100
+	// OPC_PROPERTY_DESCRIPTION is the description of the item.
+	OPC_PROPERTY_DESCRIPTION PropertyID = 101
+	// OPC_PROPERTY_HIGH_EU is the upper value of the normal operating range of the item.
+	OPC_PROPERTY_HIGH_EU PropertyID = 102
+	// OPC_PROPERTY_LOW_EU is the lower value of the normal operating range of the item.
+	OPC_PROPERTY_LOW_EU PropertyID = 103
+	// OPC_PROPERTY_HIGH_IR is the upper value of the instrument range of the item.
+	OPC_PROPERTY_HIGH_IR PropertyID = 104
+	// OPC_PROPERTY_LOW_IR is the lower value of the instrument range of the item.
+	OPC_PROPERTY_LOW_IR PropertyID = 105
+)
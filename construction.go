@@ -0,0 +1,59 @@
+//go:build windows
+
+package opcda
+
+import (
+	"unsafe"
+
+	"github.com/wends155/opcda/com"
+	"golang.org/x/sys/windows"
+)
+
+// queryInterface, releaseInterface, coSetProxyBlanket and makeServerObject make the COM calls of object
+// construction; tests replace them with counting mocks.
+var (
+	queryInterface    = queryCOMInterface
+	releaseInterface  = func(itf *com.IUnknown) { itf.Release() }
+	coSetProxyBlanket = com.CoSetProxyBlanket
+	makeServerObject  = com.MakeCOMObjectMulti
+)
+
+// queryCOMInterface queries an interface of a COM object.
+func queryCOMInterface(itf *com.IUnknown, iid *windows.GUID) (*com.IUnknown, error) {
+	var out *com.IUnknown
+	err := itf.QueryInterface(iid, unsafe.Pointer(&out))
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// cleanup collects the release functions of the resources acquired while an object is constructed.
+// Each resource is added as soon as it is acquired, so a failure at any later step releases everything
+// acquired before it, whether or not ownership has already moved into a half-built struct.
+type cleanup []func()
+
+// add registers the release function of a resource.
+func (c *cleanup) add(release func()) {
+	*c = append(*c, release)
+}
+
+// addInterface registers an acquired interface for release.
+func (c *cleanup) addInterface(itf *com.IUnknown) {
+	c.add(func() { releaseInterface(itf) })
+}
+
+// run releases the registered resources in reverse order of acquisition.
+func (c *cleanup) run() {
+	for i := len(*c) - 1; i >= 0; i-- {
+		(*c)[i]()
+	}
+	*c = nil
+}
+
+// done runs the cleanup if *err is set on return from the constructor; use it with defer and a named error.
+func (c *cleanup) done(err *error) {
+	if *err != nil {
+		c.run()
+	}
+}
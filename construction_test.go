@@ -0,0 +1,178 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wends155/opcda/com"
+	"golang.org/x/sys/windows"
+)
+
+// countingCOM hands out fake interfaces and counts their releases, failing the construction step selected
+// by failQuery or failBlanket.
+type countingCOM struct {
+	failQuery   *windows.GUID
+	failBlanket int // failBlanket is the 1-based CoSetProxyBlanket call that fails; 0 never fails.
+	blankets    int
+	acquired    []*com.IUnknown
+	released    map[*com.IUnknown]int
+}
+
+// newInterface returns a fake interface and records it as acquired.
+func (c *countingCOM) newInterface() *com.IUnknown {
+	itf := &com.IUnknown{}
+	c.acquired = append(c.acquired, itf)
+	return itf
+}
+
+// swapConstructionCOM routes the COM calls of object construction to c for a test.
+func swapConstructionCOM(t *testing.T, c *countingCOM) {
+	oldQuery, oldRelease, oldBlanket := queryInterface, releaseInterface, coSetProxyBlanket
+	t.Cleanup(func() { queryInterface, releaseInterface, coSetProxyBlanket = oldQuery, oldRelease, oldBlanket })
+	c.released = make(map[*com.IUnknown]int)
+	queryInterface = func(itf *com.IUnknown, iid *windows.GUID) (*com.IUnknown, error) {
+		if (c.failQuery != nil && *iid == *c.failQuery) || *iid == com.IID_IOPCItemSamplingMgt || *iid == com.IID_IOPCItemDeadbandMgt {
+			return nil, com.HRESULT(com.E_NOINTERFACE)
+		}
+		return c.newInterface(), nil
+	}
+	releaseInterface = func(itf *com.IUnknown) { c.released[itf]++ }
+	coSetProxyBlanket = func(proxy *com.IUnknown, authInfo *com.COAUTHINFO) error {
+		c.blankets++
+		if c.blankets == c.failBlanket {
+			return errors.New("access denied")
+		}
+		return nil
+	}
+}
+
+// assertAllReleased checks that every acquired interface was released exactly once.
+func (c *countingCOM) assertAllReleased(t *testing.T) {
+	t.Helper()
+	for i, itf := range c.acquired {
+		assert.Equal(t, 1, c.released[itf], "interface %d", i)
+	}
+	assert.Len(t, c.released, len(c.acquired))
+}
+
+func TestNewOPCGroup_ReleasesOnFailure_Mocked(t *testing.T) {
+	tests := []struct {
+		name        string
+		failQuery   *windows.GUID
+		failBlanket int
+		acquired    int
+	}{
+		{name: "IOPCSyncIO", failQuery: &com.IID_IOPCSyncIO, acquired: 0},
+		{name: "IOPCAsyncIO2", failQuery: &com.IID_IOPCAsyncIO2, acquired: 1},
+		{name: "IOPCItemMgt", failQuery: &com.IID_IOPCItemMgt, acquired: 2},
+		{name: "blanket group", failBlanket: 1, acquired: 3},
+		{name: "blanket IOPCSyncIO", failBlanket: 2, acquired: 3},
+		{name: "blanket IOPCAsyncIO2", failBlanket: 3, acquired: 3},
+		{name: "blanket IOPCItemMgt", failBlanket: 4, acquired: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &countingCOM{failQuery: tt.failQuery, failBlanket: tt.failBlanket}
+			swapConstructionCOM(t, c)
+			server := newOPCServerWithProvider(&mockServerProvider{}, "mock", "plant-pc")
+			server.authInfo = com.NewCOAUTHINFO("operator", "PLANT", "secret")
+			groupUnknown := &com.IUnknown{}
+
+			g, err := NewOPCGroup(server.groups, groupUnknown, 1, 2, "g", 1000)
+			assert.Error(t, err)
+			assert.Nil(t, g)
+			assert.Len(t, c.acquired, tt.acquired)
+			c.assertAllReleased(t)
+			assert.Zero(t, c.released[groupUnknown], "the group object stays owned by the caller")
+		})
+	}
+}
+
+func TestNewOPCGroup_KeepsInterfacesOnSuccess_Mocked(t *testing.T) {
+	c := &countingCOM{}
+	swapConstructionCOM(t, c)
+	server := newOPCServerWithProvider(&mockServerProvider{}, "mock", "localhost")
+
+	g, err := NewOPCGroup(server.groups, &com.IUnknown{}, 1, 2, "g", 1000)
+	assert.NoError(t, err)
+	assert.NotNil(t, g.items)
+	assert.Len(t, c.acquired, 3)
+	assert.Empty(t, c.released)
+}
+
+func TestDial_ReleasesOnFailure_Mocked(t *testing.T) {
+	tests := []struct {
+		name        string
+		makeErr     error
+		failBlanket int
+		acquired    int
+	}{
+		{name: "activation", makeErr: errors.New("server unavailable"), acquired: 0},
+		{name: "blanket IOPCServer", failBlanket: 1, acquired: 3},
+		{name: "blanket IOPCCommon", failBlanket: 2, acquired: 3},
+		{name: "blanket IOPCItemProperties", failBlanket: 3, acquired: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &countingCOM{failBlanket: tt.failBlanket}
+			swapConstructionCOM(t, c)
+			defer func(fn func(string, com.CLSCTX, *windows.GUID, []*windows.GUID, *com.COAUTHINFO) ([]*com.IUnknown, error)) {
+				makeServerObject = fn
+			}(makeServerObject)
+			makeServerObject = func(node string, location com.CLSCTX, clsid *windows.GUID, iids []*windows.GUID, authInfo *com.COAUTHINFO) ([]*com.IUnknown, error) {
+				if tt.makeErr != nil {
+					return nil, tt.makeErr
+				}
+				itfs := make([]*com.IUnknown, len(iids))
+				for i := range iids {
+					itfs[i] = c.newInterface()
+				}
+				return itfs, nil
+			}
+
+			server, err := comFactories().dial("{6E6170F0-FF2D-11D2-8087-00105AA8F840}", "plant-pc", com.NewCOAUTHINFO("operator", "PLANT", "secret"))
+			assert.Error(t, err)
+			assert.Nil(t, server)
+			assert.Len(t, c.acquired, tt.acquired)
+			c.assertAllReleased(t)
+		})
+	}
+}
+
+func TestNewOPCBrowser_ReleasesOnFailure_Mocked(t *testing.T) {
+	tests := []struct {
+		name        string
+		queryErr    error
+		failBlanket int
+		acquired    int
+	}{
+		{name: "IOPCBrowseServerAddressSpace", queryErr: com.HRESULT(com.E_NOINTERFACE), acquired: 0},
+		{name: "blanket IOPCBrowseServerAddressSpace", failBlanket: 1, acquired: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &countingCOM{failBlanket: tt.failBlanket}
+			swapConstructionCOM(t, c)
+			server := newOPCServerWithProvider(&mockServerProvider{
+				QueryInterfaceFn: func(iid *windows.GUID, ppv unsafe.Pointer) error {
+					if tt.queryErr != nil {
+						return tt.queryErr
+					}
+					*(**com.IUnknown)(ppv) = c.newInterface()
+					return nil
+				},
+			}, "mock", "plant-pc")
+			server.authInfo = com.NewCOAUTHINFO("operator", "PLANT", "secret")
+
+			b, err := NewOPCBrowser(server)
+			assert.Error(t, err)
+			assert.Nil(t, b)
+			assert.Len(t, c.acquired, tt.acquired)
+			c.assertAllReleased(t)
+		})
+	}
+}
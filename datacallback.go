@@ -105,10 +105,12 @@ type CDataChangeCallBackData struct {
 	Qualities         []uint16
 	TimeStamps        []time.Time
 	Errors            []int32
+	ReceivedAt        time.Time
 }
 
 // DataOnDataChange handles the OnDataChange COM callback.
 func DataOnDataChange(this unsafe.Pointer, dwTransid uint32, hGroup uint32, hrMasterquality int32, hrMastererror int32, dwCount uint32, phClientItems unsafe.Pointer, pvValues unsafe.Pointer, pwQualities unsafe.Pointer, pftTimeStamps unsafe.Pointer, pErrors unsafe.Pointer) uintptr {
+	receivedAt := time.Now()
 	er := (*DataEventReceiver)(this)
 	clientHandles := make([]uint32, dwCount)
 	values := make([]interface{}, dwCount)
@@ -118,11 +120,7 @@ func DataOnDataChange(this unsafe.Pointer, dwTransid uint32, hGroup uint32, hrMa
 	for i := 0; i < int(dwCount); i++ {
 		clientHandles[i] = *(*uint32)(unsafe.Pointer(uintptr(phClientItems) + uintptr(i)*unsafe.Sizeof(uint32(0))))
 		variant := *(*com.VARIANT)(unsafe.Pointer(uintptr(pvValues) + uintptr(i)*unsafe.Sizeof(com.VARIANT{})))
-		v, err := variant.Value()
-		if err != nil {
-			v = nil
-		}
-		values[i] = v
+		values[i] = com.ValueOrUnsupported(&variant)
 		qualities[i] = *(*uint16)(unsafe.Pointer(uintptr(pwQualities) + uintptr(i)*unsafe.Sizeof(uint16(0))))
 		ft := *(*windows.Filetime)(unsafe.Pointer(uintptr(pftTimeStamps) + uintptr(i)*unsafe.Sizeof(windows.Filetime{})))
 		timestamps[i] = time.Unix(0, ft.Nanoseconds())
@@ -138,6 +136,7 @@ func DataOnDataChange(this unsafe.Pointer, dwTransid uint32, hGroup uint32, hrMa
 		Qualities:         qualities,
 		TimeStamps:        timestamps,
 		Errors:            errors,
+		ReceivedAt:        receivedAt,
 	}
 	er.dataChangeReceiver <- cb
 	return com.S_OK
@@ -167,11 +166,7 @@ func DataOnReadComplete(this unsafe.Pointer, dwTransid uint32, hGroup uint32, hr
 	for i := 0; i < int(dwCount); i++ {
 		clientHandles[i] = *(*uint32)(unsafe.Pointer(uintptr(phClientItems) + uintptr(i)*unsafe.Sizeof(uint32(0))))
 		variant := *(*com.VARIANT)(unsafe.Pointer(uintptr(pvValues) + uintptr(i)*unsafe.Sizeof(com.VARIANT{})))
-		v, err := variant.Value()
-		if err != nil {
-			v = nil
-		}
-		values[i] = v
+		values[i] = com.ValueOrUnsupported(&variant)
 		qualities[i] = *(*uint16)(unsafe.Pointer(uintptr(pwQualities) + uintptr(i)*unsafe.Sizeof(uint16(0))))
 		ft := *(*windows.Filetime)(unsafe.Pointer(uintptr(pftTimeStamps) + uintptr(i)*unsafe.Sizeof(windows.Filetime{})))
 		timestamps[i] = time.Unix(0, ft.Nanoseconds())
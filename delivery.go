@@ -0,0 +1,122 @@
+//go:build windows
+
+package opcda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// DeliveryPolicy selects what happens to a data change event when the channel of a subscriber is full.
+type DeliveryPolicy int
+
+const (
+	// DeliveryDropNewest discards the new event and keeps the queued ones. It is the default.
+	DeliveryDropNewest DeliveryPolicy = iota
+	// DeliveryDropOldest discards the oldest queued event to make room, so the channel works as a ring
+	// buffer holding the latest events. It needs a buffered channel.
+	DeliveryDropOldest
+	// DeliveryBlock waits until the subscriber takes the event. While it waits no other subscriber of the
+	// group receives events, so use it only for consumers that keep up.
+	DeliveryBlock
+)
+
+// String returns the name of the policy.
+func (p DeliveryPolicy) String() string {
+	switch p {
+	case DeliveryDropNewest:
+		return "DropNewest"
+	case DeliveryDropOldest:
+		return "DropOldest"
+	case DeliveryBlock:
+		return "Block"
+	}
+	return fmt.Sprintf("DeliveryPolicy(%d)", int(p))
+}
+
+// RegisterDataChangeWithPolicy registers ch to receive data change events like RegisterDataChange, with
+// policy deciding what happens when ch is full. Options such as WithInitialSnapshot adjust the registration.
+// DeliveryDropOldest is rejected for an unbuffered channel, which has no queued event to drop.
+//
+// Example:
+//
+//	alarms := make(chan *opcda.DataChangeCallBackData, 16)
+//	err := group.RegisterDataChangeWithPolicy(alarms, opcda.DeliveryBlock)
+func (g *OPCGroup) RegisterDataChangeWithPolicy(ch chan *DataChangeCallBackData, policy DeliveryPolicy, options ...DataChangeOption) error {
+	if g == nil {
+		return errors.New("uninitialized group")
+	}
+	if policy < DeliveryDropNewest || policy > DeliveryBlock {
+		return fmt.Errorf("invalid delivery policy %d", int(policy))
+	}
+	if policy == DeliveryDropOldest && cap(ch) == 0 {
+		return errors.New("DeliveryDropOldest needs a buffered channel")
+	}
+	var opts dataChangeOptions
+	for _, option := range options {
+		option(&opts)
+	}
+	var gate *snapshotGate
+	err := g.adviseAnd(func() {
+		g.dataChangeList = append(g.dataChangeList, ch)
+		if policy != DeliveryDropNewest {
+			if g.dataChangePolicies == nil {
+				g.dataChangePolicies = make(map[chan *DataChangeCallBackData]DeliveryPolicy)
+			}
+			g.dataChangePolicies[ch] = policy
+		}
+		if opts.usableQuality {
+			if g.dataChangeUsable == nil {
+				g.dataChangeUsable = make(map[chan *DataChangeCallBackData]bool)
+			}
+			g.dataChangeUsable[ch] = true
+		}
+		if opts.initialSnapshot {
+			gate = g.addGate(ch, policy)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if gate != nil {
+		g.startSnapshot(ch, gate, opts.maxAge)
+	}
+	return nil
+}
+
+// deliverDataChange sends data to ch according to policy. A delivery waiting for the subscriber gives up
+// when ctx is done.
+func (g *OPCGroup) deliverDataChange(ctx context.Context, ch chan *DataChangeCallBackData, data *DataChangeCallBackData, policy DeliveryPolicy) {
+	done := ctx.Done()
+	switch policy {
+	case DeliveryBlock:
+		select {
+		case ch <- data:
+		case <-done:
+			g.dataChangeDropped.Add(1)
+		}
+	case DeliveryDropOldest:
+		for {
+			select {
+			case ch <- data:
+				return
+			case <-done:
+				g.dataChangeDropped.Add(1)
+				return
+			default:
+			}
+			select {
+			case <-ch:
+				g.dataChangeDropped.Add(1)
+			default:
+			}
+		}
+	default:
+		select {
+		case ch <- data:
+		default:
+			g.dataChangeDropped.Add(1)
+		}
+	}
+}
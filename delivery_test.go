@@ -0,0 +1,137 @@
+//go:build windows
+
+package opcda
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wends155/opcda/com"
+)
+
+func newDeliveryTestGroup(policies map[chan *DataChangeCallBackData]DeliveryPolicy) *OPCGroup {
+	group := &OPCGroup{provider: &mockServerProvider{}, dataChangePolicies: policies}
+	for ch := range policies {
+		group.dataChangeList = append(group.dataChangeList, ch)
+	}
+	return group
+}
+
+func TestOPCGroup_DataChangeDelivery_Mocked(t *testing.T) {
+	newest := make(chan *DataChangeCallBackData, 2)
+	oldest := make(chan *DataChangeCallBackData, 2)
+	group := newDeliveryTestGroup(map[chan *DataChangeCallBackData]DeliveryPolicy{
+		newest: DeliveryDropNewest,
+		oldest: DeliveryDropOldest,
+	})
+	for id := uint32(1); id <= 4; id++ {
+		group.fireDataChange(context.Background(), &CDataChangeCallBackData{GroupHandle: id})
+	}
+	assert.Equal(t, uint32(1), (<-newest).GroupHandle)
+	assert.Equal(t, uint32(2), (<-newest).GroupHandle)
+	assert.Equal(t, uint32(3), (<-oldest).GroupHandle)
+	assert.Equal(t, uint32(4), (<-oldest).GroupHandle)
+}
+
+func TestOPCGroup_DataChangeDelivery_UnsupportedVariant_Mocked(t *testing.T) {
+	ch := make(chan *DataChangeCallBackData, 1)
+	group := newDeliveryTestGroup(map[chan *DataChangeCallBackData]DeliveryPolicy{ch: DeliveryDropNewest})
+	group.fireDataChange(context.Background(), &CDataChangeCallBackData{
+		ItemClientHandles: []uint32{1, 2},
+		Values:            []interface{}{1.5, &com.ErrUnsupportedVariant{VT: com.VT_UNKNOWN}},
+		Qualities:         []uint16{192, 192},
+		TimeStamps:        make([]time.Time, 2),
+		Errors:            []int32{0, 0},
+	})
+	data := <-ch
+	assert.Equal(t, 1.5, data.Values[0])
+	assert.NoError(t, data.Errors[0])
+	assert.Equal(t, com.VT_UNKNOWN, data.Values[1])
+	var unsupported *com.ErrUnsupportedVariant
+	assert.ErrorAs(t, data.Errors[1], &unsupported)
+}
+
+func TestOPCGroup_DataChangeDelivery_Block_Mocked(t *testing.T) {
+	blocking := make(chan *DataChangeCallBackData)
+	group := newDeliveryTestGroup(map[chan *DataChangeCallBackData]DeliveryPolicy{blocking: DeliveryBlock})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		group.fireDataChange(ctx, &CDataChangeCallBackData{GroupHandle: 1})
+		group.fireDataChange(ctx, &CDataChangeCallBackData{GroupHandle: 2})
+		close(done)
+	}()
+	select {
+	case data := <-blocking:
+		assert.Equal(t, uint32(1), data.GroupHandle)
+	case <-time.After(time.Second):
+		t.Fatal("blocked delivery did not arrive")
+	}
+	// the second event waits for the consumer until the callback loop stops
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("blocked delivery was not released by the group context")
+	}
+}
+
+func TestOPCGroup_DataChangeDelivery_DropOldestUnbuffered_Mocked(t *testing.T) {
+	unbuffered := make(chan *DataChangeCallBackData)
+	group := &OPCGroup{}
+	assert.Error(t, group.RegisterDataChangeWithPolicy(unbuffered, DeliveryDropOldest))
+	assert.Empty(t, group.dataChangeList)
+
+	// a delivery that cannot make room ends with the group instead of spinning on
+	group = newDeliveryTestGroup(map[chan *DataChangeCallBackData]DeliveryPolicy{unbuffered: DeliveryDropOldest})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		group.fireDataChange(ctx, &CDataChangeCallBackData{GroupHandle: 1})
+		close(done)
+	}()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("drop-oldest delivery was not released by the group context")
+	}
+	assert.Equal(t, uint64(1), group.CallbackStats().DataChangeDropped)
+}
+
+func TestOPCGroup_RegisterDataChangeWithPolicy_Invalid(t *testing.T) {
+	group := &OPCGroup{}
+	assert.Error(t, group.RegisterDataChangeWithPolicy(make(chan *DataChangeCallBackData), DeliveryPolicy(7)))
+	assert.Equal(t, "DeliveryPolicy(7)", DeliveryPolicy(7).String())
+	assert.Equal(t, "Block", DeliveryBlock.String())
+
+	ch := make(chan *DataChangeCallBackData)
+	group = newDeliveryTestGroup(map[chan *DataChangeCallBackData]DeliveryPolicy{ch: DeliveryBlock})
+	assert.NoError(t, group.UnregisterDataChange(ch))
+	assert.Empty(t, group.dataChangePolicies)
+}
+
+func TestOPCGroup_CallbackStats_Mocked(t *testing.T) {
+	newest := make(chan *DataChangeCallBackData, 2)
+	oldest := make(chan *DataChangeCallBackData, 2)
+	group := newDeliveryTestGroup(map[chan *DataChangeCallBackData]DeliveryPolicy{
+		newest: DeliveryDropNewest,
+		oldest: DeliveryDropOldest,
+	})
+	reads := make(chan *ReadCompleteCallBackData, 1)
+	writes := make(chan *WriteCompleteCallBackData, 1)
+	group.readCompleteList = append(group.readCompleteList, reads)
+	group.writeCompleteList = append(group.writeCompleteList, writes)
+	for id := uint32(1); id <= 4; id++ {
+		group.fireDataChange(context.Background(), &CDataChangeCallBackData{GroupHandle: id})
+		group.fireReadComplete(&CReadCompleteCallBackData{GroupHandle: id})
+	}
+	group.fireWriteComplete(&CWriteCompleteCallBackData{})
+
+	assert.Equal(t, CallbackStats{DataChangeDropped: 4, ReadCompleteDropped: 3}, group.CallbackStats())
+	var nilGroup *OPCGroup
+	assert.Equal(t, CallbackStats{}, nilGroup.CallbackStats())
+}
@@ -0,0 +1,138 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// deviceRefresher issues periodic OPC_DS_DEVICE refreshes on a group. The requests of all subscriptions
+// of the group are coalesced into one loop running at the shortest requested interval.
+type deviceRefresher struct {
+	mu        sync.Mutex
+	intervals map[uint64]time.Duration // intervals are the requested intervals by request ID.
+	nextID    uint64
+	stop      chan struct{} // stop ends the running loop; nil when no loop runs.
+	done      chan struct{} // done is closed when the running loop has ended.
+	paused    bool          // paused is set while the group interfaces are released; no loop runs until rebind.
+}
+
+// ForceDeviceRefreshEvery makes the group issue AsyncRefresh(OPC_DS_DEVICE) every interval, so
+// subscriptions that read from the cache also get ground-truth updates from the device on a schedule.
+// The refreshed values arrive through the data change callbacks like any other update, so the group
+// must be active and advised.
+//
+// Each call registers its own cadence; when several are registered on a group, a single loop refreshes
+// at the shortest interval. The returned function removes the registration and may be called more than
+// once. Refresh errors are ignored; the next tick tries again. The schedule survives Reconnect. It fails
+// on a released group, whose registrations only resume once Reconnect binds it again.
+//
+// Example:
+//
+//	stop, err := group.ForceDeviceRefreshEvery(time.Minute)
+//	if err == nil {
+//		defer stop()
+//	}
+func (g *OPCGroup) ForceDeviceRefreshEvery(interval time.Duration) (func(), error) {
+	if g == nil || g.groupProvider == nil {
+		return nil, errors.New("uninitialized group")
+	}
+	if interval <= 0 {
+		return nil, errors.New("device refresh interval must be positive")
+	}
+	r := &g.deviceRefresh
+	r.mu.Lock()
+	if r.paused || g.State() == GroupReleased {
+		r.mu.Unlock()
+		return nil, errors.New("group released")
+	}
+	if r.intervals == nil {
+		r.intervals = make(map[uint64]time.Duration)
+	}
+	r.nextID++
+	id := r.nextID
+	r.intervals[id] = interval
+	g.restartDeviceRefreshLocked()
+	r.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			delete(r.intervals, id)
+			g.restartDeviceRefreshLocked()
+		})
+	}, nil
+}
+
+// restartDeviceRefreshLocked stops the running refresh loop, if any, and starts one at the shortest
+// registered interval unless the refreshes are paused. The caller must hold g.deviceRefresh.mu.
+func (g *OPCGroup) restartDeviceRefreshLocked() {
+	r := &g.deviceRefresh
+	g.stopDeviceRefreshLocked()
+	if r.paused {
+		return
+	}
+	var interval time.Duration
+	for _, d := range r.intervals {
+		if interval == 0 || d < interval {
+			interval = d
+		}
+	}
+	if interval == 0 {
+		return
+	}
+	r.stop = make(chan struct{})
+	r.done = make(chan struct{})
+	go g.deviceRefreshLoop(interval, r.stop, r.done)
+}
+
+// stopDeviceRefreshLocked stops the running refresh loop and waits for it to end.
+// The caller must hold g.deviceRefresh.mu.
+func (g *OPCGroup) stopDeviceRefreshLocked() {
+	r := &g.deviceRefresh
+	if r.stop == nil {
+		return
+	}
+	close(r.stop)
+	<-r.done
+	r.stop, r.done = nil, nil
+}
+
+// deviceRefreshLoop refreshes the group from the device every interval until stop is closed.
+func (g *OPCGroup) deviceRefreshLoop(interval time.Duration, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			// a loop blocked on the in-flight limit must not hold up Release
+			g.asyncRefresh(OPC_DS_DEVICE, g.nextAwaitTransactionID(), stop)
+		}
+	}
+}
+
+// pauseDeviceRefresh stops the refresh loop while the group interfaces are released, keeping the
+// registrations so resumeDeviceRefresh can restart it. Removing a registration does not restart the loop
+// until then.
+func (g *OPCGroup) pauseDeviceRefresh() {
+	g.deviceRefresh.mu.Lock()
+	defer g.deviceRefresh.mu.Unlock()
+	g.deviceRefresh.paused = true
+	g.stopDeviceRefreshLocked()
+}
+
+// resumeDeviceRefresh restarts the refresh loop of a rebound group if refreshes are registered.
+// Only rebind calls it, once the group has new interfaces.
+func (g *OPCGroup) resumeDeviceRefresh() {
+	g.deviceRefresh.mu.Lock()
+	defer g.deviceRefresh.mu.Unlock()
+	g.deviceRefresh.paused = false
+	g.restartDeviceRefreshLocked()
+}
@@ -0,0 +1,113 @@
+//go:build windows
+
+package opcda
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wends155/opcda/com"
+)
+
+func TestOPCGroup_ForceDeviceRefreshEvery_Mocked(t *testing.T) {
+	var refreshes atomic.Int32
+	server := newOPCServerWithProvider(&mockServerProvider{}, "mock", "localhost")
+	group := newInflightTestGroup(server, &mockGroupProvider{
+		AsyncRefreshFn: func(source com.OPCDATASOURCE, transactionID uint32) (uint32, error) {
+			assert.Equal(t, OPC_DS_DEVICE, source)
+			refreshes.Add(1)
+			return transactionID, nil
+		},
+	})
+
+	_, err := group.ForceDeviceRefreshEvery(0)
+	assert.Error(t, err)
+
+	stopSlow, err := group.ForceDeviceRefreshEvery(time.Hour)
+	assert.NoError(t, err)
+	stopFast, err := group.ForceDeviceRefreshEvery(5 * time.Millisecond)
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool { return refreshes.Load() >= 2 }, time.Second, time.Millisecond)
+
+	// the remaining registration refreshes hourly
+	stopFast()
+	stopFast()
+	n := refreshes.Load()
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, n, refreshes.Load())
+
+	stopSlow()
+	group.deviceRefresh.mu.Lock()
+	assert.Nil(t, group.deviceRefresh.stop)
+	group.deviceRefresh.mu.Unlock()
+}
+
+func TestOPCGroup_ForceDeviceRefreshEvery_PausedByRelease_Mocked(t *testing.T) {
+	server := newOPCServerWithProvider(&mockServerProvider{}, "mock", "localhost")
+	group := newInflightTestGroup(server, &mockGroupProvider{})
+
+	stop, err := group.ForceDeviceRefreshEvery(time.Hour)
+	assert.NoError(t, err)
+	defer stop()
+	stopOther, err := group.ForceDeviceRefreshEvery(time.Hour)
+	assert.NoError(t, err)
+	group.Release()
+	group.deviceRefresh.mu.Lock()
+	assert.Nil(t, group.deviceRefresh.stop)
+	assert.Len(t, group.deviceRefresh.intervals, 2, "registrations are kept for Reconnect")
+	group.deviceRefresh.mu.Unlock()
+
+	// removing a registration of a released group must not restart the loop on its released interfaces
+	stopOther()
+	group.deviceRefresh.mu.Lock()
+	assert.Nil(t, group.deviceRefresh.stop)
+	group.deviceRefresh.mu.Unlock()
+	_, err = group.ForceDeviceRefreshEvery(time.Hour)
+	assert.Error(t, err)
+
+	group.resumeDeviceRefresh()
+	group.deviceRefresh.mu.Lock()
+	assert.NotNil(t, group.deviceRefresh.stop)
+	group.deviceRefresh.mu.Unlock()
+}
+
+func TestOPCGroup_ForceDeviceRefreshEvery_ReleaseCancelsPermitWait_Mocked(t *testing.T) {
+	server := newOPCServerWithProvider(&mockServerProvider{}, "mock", "localhost")
+	assert.NoError(t, server.SetMaxInflightAsync(1))
+	var refreshes atomic.Int32
+	group := newInflightTestGroup(server, &mockGroupProvider{
+		AsyncRefreshFn: func(source com.OPCDATASOURCE, transactionID uint32) (uint32, error) {
+			refreshes.Add(1)
+			return transactionID, nil
+		},
+	})
+	// another group holds the only permit, so the refresh loop blocks waiting for one
+	other := newInflightTestGroup(server, &mockGroupProvider{
+		AsyncReadFn: func(serverHandles []uint32, transactionID uint32) (uint32, []int32, error) {
+			return transactionID, []int32{0}, nil
+		},
+	})
+	other.serverGroupHandle = 8
+	_, _, err := other.AsyncRead([]uint32{1}, 1)
+	assert.NoError(t, err)
+
+	stop, err := group.ForceDeviceRefreshEvery(time.Millisecond)
+	assert.NoError(t, err)
+	defer stop()
+	assert.Eventually(t, func() bool { return server.GetAsyncStats().Waiting == 1 }, time.Second, time.Millisecond)
+
+	released := make(chan struct{})
+	go func() {
+		group.Release()
+		close(released)
+	}()
+	select {
+	case <-released:
+	case <-time.After(time.Second):
+		t.Fatal("Release waited for the in-flight permit of the refresh loop")
+	}
+	assert.Zero(t, refreshes.Load())
+	assert.Zero(t, server.GetAsyncStats().Waiting)
+}
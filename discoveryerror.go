@@ -0,0 +1,103 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"github.com/wends155/opcda/com"
+	"strings"
+)
+
+// DiscoveryStage identifies one of the strategies used to find OPC servers.
+type DiscoveryStage string
+
+const (
+	// StageServerListV2 queries the IOPCServerList2 interface of OpcEnum.
+	StageServerListV2 DiscoveryStage = "ServerListV2"
+	// StageServerListV1 queries the IOPCServerList interface of OpcEnum.
+	StageServerListV1 DiscoveryStage = "ServerListV1"
+	// StageRegistry reads the class registrations from the registry.
+	StageRegistry DiscoveryStage = "Registry"
+)
+
+// eAccessDenied is E_ACCESSDENIED, returned when DCOM refuses the launch or access.
+const eAccessDenied = com.HRESULT(com.E_ACCESSDENIED)
+
+// StageError is the failure of one discovery stage.
+type StageError struct {
+	Stage DiscoveryStage
+	Err   error
+}
+
+// Error returns the stage and its error.
+func (e *StageError) Error() string {
+	return string(e.Stage) + ": " + e.Err.Error()
+}
+
+// Unwrap returns the error of the stage.
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+// DiscoveryError is returned by GetOPCServers and the ProgID lookup of Connect when every discovery stage
+// failed. It records the error of each stage that ran, in order, and works with errors.Is and errors.As
+// through all of them.
+//
+// Example:
+//
+//	var discoveryErr *opcda.DiscoveryError
+//	if errors.As(err, &discoveryErr) && discoveryErr.ServerListMissing() {
+//		fmt.Println("install the OPC Core Components (OpcEnum) on", node)
+//	}
+type DiscoveryError struct {
+	// Op describes what was being looked up.
+	Op string
+	// Stages holds the failure of every stage that ran.
+	Stages []*StageError
+}
+
+// add records the failure of a stage.
+func (e *DiscoveryError) add(stage DiscoveryStage, err error) {
+	e.Stages = append(e.Stages, &StageError{Stage: stage, Err: err})
+}
+
+// Error lists the failures of all stages.
+func (e *DiscoveryError) Error() string {
+	parts := make([]string, len(e.Stages))
+	for i, stage := range e.Stages {
+		parts[i] = stage.Error()
+	}
+	return e.Op + " failed: " + strings.Join(parts, "; ")
+}
+
+// Unwrap returns the failures of the stages.
+func (e *DiscoveryError) Unwrap() []error {
+	errs := make([]error, len(e.Stages))
+	for i, stage := range e.Stages {
+		errs[i] = stage
+	}
+	return errs
+}
+
+// Err returns the error of a stage, or nil if the stage did not run.
+func (e *DiscoveryError) Err(stage DiscoveryStage) error {
+	for _, s := range e.Stages {
+		if s.Stage == stage {
+			return s.Err
+		}
+	}
+	return nil
+}
+
+// ServerListMissing reports whether both server list stages failed because the OpcEnum class is not
+// registered on the node, which means the OPC Core Components are not installed.
+func (e *DiscoveryError) ServerListMissing() bool {
+	v2, v1 := e.Err(StageServerListV2), e.Err(StageServerListV1)
+	return v2 != nil && v1 != nil && errors.Is(v2, regdbEClassNotReg) && errors.Is(v1, regdbEClassNotReg)
+}
+
+// AccessDenied reports whether any stage was refused with E_ACCESSDENIED, which usually calls for DCOM
+// launch and access permissions or explicit credentials.
+func (e *DiscoveryError) AccessDenied() bool {
+	return errors.Is(e, eAccessDenied)
+}
@@ -0,0 +1,66 @@
+//go:build windows
+
+package opcda
+
+import (
+	"context"
+	"errors"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wends155/opcda/com"
+)
+
+func TestGetOPCServers_DiscoveryError_Mocked(t *testing.T) {
+	notRegistered := func(string, *com.COAUTHINFO) (serverListProvider, error) {
+		return nil, NewOPCWrapperError("make com object", regdbEClassNotReg)
+	}
+	denied := func(string) ([]*ServerInfo, error) {
+		return nil, eAccessDenied
+	}
+	swapServerEnumeration(t, notRegistered, notRegistered, denied)
+
+	_, err := GetOPCServers("localhost")
+	var discoveryErr *DiscoveryError
+	assert.True(t, errors.As(err, &discoveryErr))
+	assert.Len(t, discoveryErr.Stages, 3)
+	assert.True(t, discoveryErr.ServerListMissing())
+	assert.True(t, discoveryErr.AccessDenied())
+	assert.ErrorIs(t, err, regdbEClassNotReg)
+	assert.ErrorIs(t, discoveryErr.Err(StageRegistry), eAccessDenied)
+
+	_, err = GetOPCServers("localhost", WithoutRegistry())
+	assert.True(t, errors.As(err, &discoveryErr))
+	assert.Len(t, discoveryErr.Stages, 2)
+	assert.Nil(t, discoveryErr.Err(StageRegistry))
+	assert.False(t, discoveryErr.AccessDenied())
+}
+
+func TestGetOPCServers_DiscoveryError_Cancelled_Mocked(t *testing.T) {
+	failing := func(string, *com.COAUTHINFO) (serverListProvider, error) {
+		return nil, errors.New("unavailable")
+	}
+	swapServerEnumeration(t, failing, failing, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := GetOPCServersContext(ctx, "localhost")
+	var discoveryErr *DiscoveryError
+	assert.False(t, errors.As(err, &discoveryErr))
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestDiscoveryError_Error(t *testing.T) {
+	discoveryErr := &DiscoveryError{Op: "resolve ProgID Vendor.Server.1"}
+	discoveryErr.add(StageServerListV2, syscall.Errno(1))
+	discoveryErr.add(StageServerListV1, regdbEClassNotReg)
+
+	assert.Contains(t, discoveryErr.Error(), "resolve ProgID Vendor.Server.1 failed")
+	assert.Contains(t, discoveryErr.Error(), "ServerListV2: ")
+	assert.Contains(t, discoveryErr.Error(), "ServerListV1: ")
+	assert.False(t, discoveryErr.ServerListMissing())
+	var stageErr *StageError
+	assert.True(t, errors.As(discoveryErr, &stageErr))
+	assert.Equal(t, StageServerListV2, stageErr.Stage)
+}
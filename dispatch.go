@@ -0,0 +1,91 @@
+//go:build windows
+
+package opcda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// DispatchMode selects how the callback loop of a group hands the callbacks of the server to subscribers.
+type DispatchMode int32
+
+const (
+	// DispatchSerial handles all callbacks on one goroutine, in the order they arrive. A subscriber that
+	// blocks delivery, such as a data change channel registered with DeliveryBlock, delays the read, write
+	// and cancel completions behind it. It is the default.
+	DispatchSerial DispatchMode = iota
+	// DispatchPerKind handles data changes, read completions, write completions and cancel completions on
+	// one goroutine each, so at most four goroutines dispatch the callbacks of the group. Callbacks of one
+	// kind keep their order, so every subscriber still sees its events in the order of the server; callbacks
+	// of different kinds are delivered independently, so for example a write completion may reach its
+	// subscriber before a data change the server sent earlier.
+	DispatchPerKind
+)
+
+// String returns the name of the mode.
+func (m DispatchMode) String() string {
+	switch m {
+	case DispatchSerial:
+		return "Serial"
+	case DispatchPerKind:
+		return "PerKind"
+	}
+	return fmt.Sprintf("DispatchMode(%d)", int32(m))
+}
+
+// SetDispatchMode sets how callbacks are dispatched to subscribers. The mode takes effect when the callback
+// loop starts, that is on the first registration of a subscriber after the group was created or unadvised,
+// so set it before registering.
+//
+// Example:
+//
+//	// keep write confirmations flowing while a slow consumer processes data changes
+//	err := group.SetDispatchMode(opcda.DispatchPerKind)
+//	err = group.RegisterDataChangeWithPolicy(samples, opcda.DeliveryBlock)
+//	err = group.RegisterWriteComplete(confirmations)
+func (g *OPCGroup) SetDispatchMode(mode DispatchMode) error {
+	if g == nil {
+		return errors.New("uninitialized group")
+	}
+	if mode < DispatchSerial || mode > DispatchPerKind {
+		return fmt.Errorf("invalid dispatch mode %d", int32(mode))
+	}
+	g.dispatchMode.Store(int32(mode))
+	return nil
+}
+
+// GetDispatchMode returns the dispatch mode set with SetDispatchMode.
+func (g *OPCGroup) GetDispatchMode() DispatchMode {
+	if g == nil {
+		return DispatchSerial
+	}
+	return DispatchMode(g.dispatchMode.Load())
+}
+
+// loopPerKind dispatches each kind of callback on a goroutine of its own and returns once all of them
+// returned.
+func (g *OPCGroup) loopPerKind(ctx context.Context, dataChangeCB chan *CDataChangeCallBackData, readCB chan *CReadCompleteCallBackData, writeCB chan *CWriteCompleteCallBackData, cancelCB chan *CCancelCompleteCallBackData) {
+	var wg sync.WaitGroup
+	wg.Add(4)
+	fireDataChange := func(cbData *CDataChangeCallBackData) { g.fireDataChange(ctx, cbData) }
+	go func() { defer wg.Done(); dispatchKind(ctx, dataChangeCB, fireDataChange) }()
+	go func() { defer wg.Done(); dispatchKind(ctx, readCB, g.fireReadComplete) }()
+	go func() { defer wg.Done(); dispatchKind(ctx, writeCB, g.fireWriteComplete) }()
+	go func() { defer wg.Done(); dispatchKind(ctx, cancelCB, g.fireCancelComplete) }()
+	wg.Wait()
+}
+
+// dispatchKind calls fire with the callbacks received on ch until ctx is done.
+func dispatchKind[T any](ctx context.Context, ch chan T, fire func(T)) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case cbData := <-ch:
+			fire(cbData)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+//go:build windows
+
+package opcda
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// startDispatchTestGroup starts the callback loop of a group with a data change subscriber that never
+// reads and a write completion subscriber, and returns the callback channels of the server side.
+func startDispatchTestGroup(t *testing.T, mode DispatchMode) (*OPCGroup, chan *CDataChangeCallBackData, chan *CWriteCompleteCallBackData, chan *WriteCompleteCallBackData) {
+	group := &OPCGroup{provider: &mockServerProvider{}, event: &DataEventReceiver{}}
+	assert.NoError(t, group.SetDispatchMode(mode))
+	assert.NoError(t, group.RegisterDataChangeWithPolicy(make(chan *DataChangeCallBackData), DeliveryBlock))
+	confirmations := make(chan *WriteCompleteCallBackData, 1)
+	assert.NoError(t, group.RegisterWriteComplete(confirmations))
+	dataChangeCB := make(chan *CDataChangeCallBackData, 1)
+	writeCB := make(chan *CWriteCompleteCallBackData, 1)
+	group.startLoop(dataChangeCB, make(chan *CReadCompleteCallBackData), writeCB, make(chan *CCancelCompleteCallBackData))
+	return group, dataChangeCB, writeCB, confirmations
+}
+
+func TestOPCGroup_DispatchPerKind_WriteCompleteNotStalled(t *testing.T) {
+	group, dataChangeCB, writeCB, confirmations := startDispatchTestGroup(t, DispatchPerKind)
+	defer group.stopCallbackLoop()
+
+	dataChangeCB <- &CDataChangeCallBackData{}
+	writeCB <- &CWriteCompleteCallBackData{TransID: 3}
+	select {
+	case data := <-confirmations:
+		assert.Equal(t, uint32(3), data.TransID)
+	case <-time.After(time.Second):
+		t.Fatal("write completion stalled behind a blocked data change subscriber")
+	}
+}
+
+func TestOPCGroup_DispatchSerial_WriteCompleteWaits(t *testing.T) {
+	group, dataChangeCB, writeCB, confirmations := startDispatchTestGroup(t, DispatchSerial)
+	defer group.stopCallbackLoop()
+
+	dataChangeCB <- &CDataChangeCallBackData{}
+	assert.Eventually(t, func() bool { return len(dataChangeCB) == 0 }, time.Second, time.Millisecond)
+	writeCB <- &CWriteCompleteCallBackData{TransID: 3}
+	select {
+	case <-confirmations:
+		t.Fatal("serial dispatch delivered a write completion while a data change was blocked")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestOPCGroup_SetDispatchMode(t *testing.T) {
+	group := &OPCGroup{}
+	assert.Equal(t, DispatchSerial, group.GetDispatchMode())
+	assert.NoError(t, group.SetDispatchMode(DispatchPerKind))
+	assert.Equal(t, DispatchPerKind, group.GetDispatchMode())
+	assert.Error(t, group.SetDispatchMode(DispatchMode(7)))
+	assert.Equal(t, "DispatchMode(7)", DispatchMode(7).String())
+
+	var nilGroup *OPCGroup
+	assert.Error(t, nilGroup.SetDispatchMode(DispatchSerial))
+}
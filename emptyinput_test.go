@@ -0,0 +1,178 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wends155/opcda/com"
+)
+
+// newEmptyInputTestGroup returns a group whose providers fail the test when the server is called.
+func newEmptyInputTestGroup(t *testing.T) *OPCGroup {
+	called := func(method string) {
+		t.Errorf("%s called with empty or mismatched input", method)
+	}
+	server := newOPCServerWithProvider(&mockServerProvider{
+		GetItemPropertiesFn: func(itemID string, propertyIDs []uint32) ([]interface{}, []int32, error) {
+			called("GetItemProperties")
+			return nil, nil, nil
+		},
+		LookupItemIDsFn: func(itemID string, propertyIDs []uint32) ([]string, []int32, error) {
+			called("LookupItemIDs")
+			return nil, nil, nil
+		},
+	}, "mock", "localhost")
+	group := newInflightTestGroup(server, &mockGroupProvider{
+		SyncReadFn: func(source com.OPCDATASOURCE, serverHandles []uint32) ([]*com.ItemState, []int32, error) {
+			called("SyncRead")
+			return nil, nil, nil
+		},
+		SyncWriteFn: func(serverHandles []uint32, values []com.VARIANT) ([]int32, error) {
+			called("SyncWrite")
+			return nil, nil
+		},
+		AsyncReadFn: func(serverHandles []uint32, transactionID uint32) (uint32, []int32, error) {
+			called("AsyncRead")
+			return 0, nil, nil
+		},
+		AsyncWriteFn: func(serverHandles []uint32, values []com.VARIANT, transactionID uint32) (uint32, []int32, error) {
+			called("AsyncWrite")
+			return 0, nil, nil
+		},
+	})
+	group.items = NewOPCItems(group, &mockItemMgtProvider{
+		AddItemsFn: func(items []com.TagOPCITEMDEF) ([]com.TagOPCITEMRESULTStruct, []int32, error) {
+			called("AddItems")
+			return nil, nil, nil
+		},
+		ValidateItemsFn: func(items []com.TagOPCITEMDEF, bBlob bool) ([]com.TagOPCITEMRESULTStruct, []int32, error) {
+			called("ValidateItems")
+			return nil, nil, nil
+		},
+		RemoveItemsFn: func(serverHandles []uint32) ([]int32, error) {
+			called("RemoveItems")
+			return nil, nil
+		},
+		SetActiveStateFn: func(serverHandles []uint32, bActive bool) ([]int32, error) {
+			called("SetActiveState")
+			return nil, nil
+		},
+	}, server.provider)
+	group.items.items = []*OPCItem{{parent: group.items, serverHandle: 1}}
+	return group
+}
+
+func TestBatchAPIs_EmptyInput_Mocked(t *testing.T) {
+	active := true
+	tests := []struct {
+		name string
+		call func(g *OPCGroup) (int, error)
+	}{
+		{"OPCGroup.SyncRead", func(g *OPCGroup) (int, error) {
+			values, errs, err := g.SyncRead(OPC_DS_CACHE, nil)
+			return len(values) + len(errs), err
+		}},
+		{"OPCGroup.SyncWrite", func(g *OPCGroup) (int, error) {
+			errs, err := g.SyncWrite([]uint32{}, []interface{}{})
+			return len(errs), err
+		}},
+		{"OPCGroup.AsyncRead", func(g *OPCGroup) (int, error) {
+			cancelID, errs, err := g.AsyncRead(nil, 1)
+			return int(cancelID) + len(errs), err
+		}},
+		{"OPCGroup.AsyncWrite", func(g *OPCGroup) (int, error) {
+			cancelID, errs, err := g.AsyncWrite(nil, nil, 1)
+			return int(cancelID) + len(errs), err
+		}},
+		{"OPCGroup.ApplyProfile", func(g *OPCGroup) (int, error) {
+			results, err := g.ApplyProfile(nil, ItemProfile{Active: &active})
+			return len(results), err
+		}},
+		{"OPCItems.AddItems", func(g *OPCGroup) (int, error) {
+			items, errs, err := g.items.AddItems([]string{})
+			return len(items) + len(errs), err
+		}},
+		{"OPCItems.AddItemsWithOptions", func(g *OPCGroup) (int, error) {
+			items, errs, err := g.items.AddItemsWithOptions(nil)
+			return len(items) + len(errs), err
+		}},
+		{"OPCItems.Validate", func(g *OPCGroup) (int, error) {
+			errs, err := g.items.Validate(nil, nil, nil)
+			return len(errs), err
+		}},
+		{"OPCItems.Remove", func(g *OPCGroup) (int, error) {
+			g.items.Remove(nil)
+			return g.items.GetCount() - 1, nil
+		}},
+		{"OPCItems.SetActive", func(g *OPCGroup) (int, error) {
+			return len(g.items.SetActive(nil, true)), nil
+		}},
+		{"OPCItems.SetClientHandles", func(g *OPCGroup) (int, error) {
+			return len(g.items.SetClientHandles(nil, nil)), nil
+		}},
+		{"OPCItems.SetDataTypes", func(g *OPCGroup) (int, error) {
+			return len(g.items.SetDataTypes(nil, nil)), nil
+		}},
+		{"OPCServer.GetItemProperties", func(g *OPCGroup) (int, error) {
+			data, errs, err := g.parent.parent.GetItemProperties("Random.Int4", nil)
+			return len(data) + len(errs), err
+		}},
+		{"OPCServer.LookupItemIDs", func(g *OPCGroup) (int, error) {
+			ids, err := g.parent.parent.LookupItemIDs("Random.Int4", []uint32{})
+			return len(ids), err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newEmptyInputTestGroup(t)
+			var n int
+			var err error
+			assert.NotPanics(t, func() { n, err = tt.call(g) })
+			assert.NoError(t, err)
+			assert.Zero(t, n)
+			assert.Equal(t, 0, g.parent.parent.GetAsyncStats().Inflight)
+		})
+	}
+}
+
+func TestBatchAPIs_MismatchedInput_Mocked(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(g *OPCGroup) error
+	}{
+		{"OPCGroup.SyncWrite without values", func(g *OPCGroup) error {
+			_, err := g.SyncWrite([]uint32{1}, nil)
+			return err
+		}},
+		{"OPCGroup.SyncWrite with fewer values", func(g *OPCGroup) error {
+			_, err := g.SyncWrite([]uint32{1, 2}, []interface{}{int32(1)})
+			return err
+		}},
+		{"OPCGroup.AsyncWrite without values", func(g *OPCGroup) error {
+			_, _, err := g.AsyncWrite([]uint32{1}, nil, 1)
+			return err
+		}},
+		{"OPCGroup.AsyncWrite with more values", func(g *OPCGroup) error {
+			_, _, err := g.AsyncWrite([]uint32{1}, []interface{}{int32(1), int32(2)}, 1)
+			return err
+		}},
+		{"OPCItems.SetClientHandles with more handles", func(g *OPCGroup) error {
+			return errors.Join(g.items.SetClientHandles([]uint32{1}, []uint32{10, 11})...)
+		}},
+		{"OPCItems.SetDataTypes without types", func(g *OPCGroup) error {
+			return errors.Join(g.items.SetDataTypes([]uint32{1}, nil)...)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newEmptyInputTestGroup(t)
+			var err error
+			assert.NotPanics(t, func() { err = tt.call(g) })
+			assert.Error(t, err)
+			assert.Equal(t, 0, g.parent.parent.GetAsyncStats().Inflight)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+//go:build windows
+
+package opcda
+
+// errorMessage returns the message of an error code as reported by GetErrorString through provider.
+// Messages are cached per connection, so repeated failures with the same code cost one round trip to the
+// server. Failed lookups are not cached and return an empty message.
+func (s *OPCServer) errorMessage(provider serverProvider, errorCode int32) string {
+	if msg, ok := s.errorStrings.Load(errorCode); ok {
+		return msg.(string)
+	}
+	msg, err := provider.GetErrorString(uint32(errorCode))
+	if err != nil {
+		return ""
+	}
+	s.errorStrings.Store(errorCode, msg)
+	return msg
+}
+
+// clearErrorMessages forgets the cached error messages, which depend on the connection and its locale.
+func (s *OPCServer) clearErrorMessages() {
+	s.errorStrings.Clear()
+}
+
+// newOPCError returns the OPCError of a failed error code, with the message looked up through provider
+// and cached on server. Objects that are not attached to a server look the message up every time.
+func newOPCError(server *OPCServer, provider serverProvider, errorCode int32) error {
+	if provider == nil {
+		return &OPCError{ErrorCode: errorCode, ErrorMessage: "uninitialized common interface"}
+	}
+	if server == nil {
+		errStr, _ := provider.GetErrorString(uint32(errorCode))
+		return &OPCError{ErrorCode: errorCode, ErrorMessage: errStr}
+	}
+	return &OPCError{ErrorCode: errorCode, ErrorMessage: server.errorMessage(provider, errorCode)}
+}
+
+// server returns the server of the group, or nil if the group is not attached to one.
+func (g *OPCGroup) server() *OPCServer {
+	if g == nil || g.parent == nil {
+		return nil
+	}
+	return g.parent.parent
+}
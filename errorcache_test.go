@@ -0,0 +1,96 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wends155/opcda/com"
+)
+
+func TestOPCServer_ErrorMessageCache_Mocked(t *testing.T) {
+	var lookups int
+	provider := &mockServerProvider{
+		GetErrorStringFn: func(errorCode uint32) (string, error) {
+			lookups++
+			return "The item ID is not defined in the server address space.", nil
+		},
+	}
+	server := newOPCServerWithProvider(provider, "mock", "localhost")
+	group := newInflightTestGroup(server, &mockGroupProvider{})
+	group.items = NewOPCItems(group, nil, provider)
+	item := &OPCItem{parent: group.items, provider: provider}
+
+	for _, err := range []error{group.getError(-1073479673), group.items.getError(-1073479673), item.getError(-1073479673)} {
+		var opcErr *OPCError
+		assert.ErrorAs(t, err, &opcErr)
+		assert.Equal(t, "The item ID is not defined in the server address space.", opcErr.ErrorMessage)
+	}
+	assert.Equal(t, 1, lookups, "the cache must be consulted before the provider")
+
+	assert.NoError(t, server.SetLocaleID(0x0407))
+	group.getError(-1073479673)
+	assert.Equal(t, 2, lookups, "SetLocaleID must clear the cache")
+}
+
+func TestOPCServer_ErrorMessageCache_FailedLookup_Mocked(t *testing.T) {
+	var lookups int
+	provider := &mockServerProvider{
+		GetErrorStringFn: func(errorCode uint32) (string, error) {
+			lookups++
+			return "", errors.New("server busy")
+		},
+	}
+	server := newOPCServerWithProvider(provider, "mock", "localhost")
+	group := newInflightTestGroup(server, &mockGroupProvider{})
+
+	group.getError(-1)
+	group.getError(-1)
+	assert.Equal(t, 2, lookups, "failed lookups must not be cached")
+}
+
+func TestOPCServer_ErrorMessageCache_ClearedOnReconnect_Mocked(t *testing.T) {
+	var oldLookups, newLookups int
+	server := newOPCServerWithProvider(&mockServerProvider{
+		GetErrorStringFn: func(errorCode uint32) (string, error) {
+			oldLookups++
+			return "old", nil
+		},
+	}, "mock", "localhost")
+	server.factories.connect = func(progID, node string, authInfo *com.COAUTHINFO) (*OPCServer, error) {
+		return newOPCServerWithProvider(&mockServerProvider{
+			GetErrorStringFn: func(errorCode uint32) (string, error) {
+				newLookups++
+				return "new", nil
+			},
+		}, progID, node), nil
+	}
+
+	errs := server.errors([]int32{-1})
+	assert.EqualError(t, errs[0], (&OPCError{ErrorCode: -1, ErrorMessage: "old"}).Error())
+	assert.NoError(t, server.Reconnect())
+	errs = server.errors([]int32{-1})
+	assert.EqualError(t, errs[0], (&OPCError{ErrorCode: -1, ErrorMessage: "new"}).Error())
+	assert.Equal(t, 1, oldLookups)
+	assert.Equal(t, 1, newLookups)
+}
+
+func BenchmarkOPCGroup_getError_Cached(b *testing.B) {
+	var lookups int
+	provider := &mockServerProvider{
+		GetErrorStringFn: func(errorCode uint32) (string, error) {
+			lookups++
+			return "Bad type", nil
+		},
+	}
+	server := newOPCServerWithProvider(provider, "mock", "localhost")
+	group := newInflightTestGroup(server, &mockGroupProvider{})
+
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = group.getError(-1073479676)
+	}
+	b.ReportMetric(float64(lookups), "lookups")
+}
@@ -0,0 +1,62 @@
+//go:build windows
+
+package opcda
+
+import (
+	"github.com/wends155/opcda/com"
+)
+
+// connectionFactories create the objects of a connection that its server, group and item providers do
+// not, such as the connection itself when it is re-established or cloned and the groups added to it.
+// Every connection holds its own factories, shared with its OPCGroups, so tests inject mocks into one
+// connection like they inject providers with newOPCServerWithProvider.
+type connectionFactories struct {
+	// connect connects to a server for Reconnect and Clone.
+	connect func(progID, node string, authInfo *com.COAUTHINFO) (*OPCServer, error)
+	// dial makes a single connection attempt for connect.
+	dial func(progID, node string, authInfo *com.COAUTHINFO) (*OPCServer, error)
+	// newGroup binds an OPCGroup to a group added to the server.
+	newGroup func(gs *OPCGroups, iUnknown *com.IUnknown, clientGroupHandle, serverGroupHandle uint32, groupName string, revisedUpdateRate uint32) (*OPCGroup, error)
+	// queryItemIO acquires the IOPCItemIO interface of the server.
+	queryItemIO func(provider serverProvider, authInfo *com.COAUTHINFO) (itemIOProvider, error)
+	// queryBrowse3 acquires the IOPCBrowse interface of the server.
+	queryBrowse3 func(provider serverProvider, authInfo *com.COAUTHINFO) (browse3Provider, error)
+	// newBrowser creates the OPCBrowser NamespaceSeparator falls back to.
+	newBrowser func(parent *OPCServer) (*OPCBrowser, error)
+	// querySyncIO2 acquires the IOPCSyncIO2 interface of a group.
+	querySyncIO2 func(provider groupProvider, authInfo *com.COAUTHINFO) (syncIO2Provider, error)
+	// openConnectionPoints acquires the connection points of a group.
+	openConnectionPoints func(provider groupProvider, authInfo *com.COAUTHINFO) (connectionPointsProvider, error)
+	// queryPublicGroups acquires the IOPCServerPublicGroups interface of the server.
+	queryPublicGroups func(provider serverProvider, authInfo *com.COAUTHINFO) (publicGroupsProvider, error)
+	// moveToPublic converts a private group into a public group.
+	moveToPublic func(g *OPCGroup) error
+	// newGroupEnumerator wraps the IEnumUnknown returned by CreateGroupEnumerator.
+	newGroupEnumerator func(ppUnk *com.IUnknown, authInfo *com.COAUTHINFO) (groupEnumerator, error)
+}
+
+// comFactories returns the factories that create the COM objects of a connection.
+func comFactories() *connectionFactories {
+	f := &connectionFactories{
+		newGroup:             NewOPCGroup,
+		queryItemIO:          queryComItemIO,
+		queryBrowse3:         queryComBrowse3,
+		newBrowser:           NewOPCBrowser,
+		querySyncIO2:         queryComSyncIO2,
+		openConnectionPoints: openComConnectionPoints,
+		queryPublicGroups:    queryComPublicGroups,
+		moveToPublic:         moveComGroupToPublic,
+		newGroupEnumerator:   newComGroupEnumerator,
+	}
+	f.connect = f.connectCOM
+	f.dial = f.dialCOM
+	return f
+}
+
+// factories returns the factories of the connection of the group.
+func (g *OPCGroup) factories() *connectionFactories {
+	if g.parent == nil || g.parent.factories == nil {
+		return comFactories()
+	}
+	return g.parent.factories
+}
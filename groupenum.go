@@ -0,0 +1,177 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"github.com/wends155/opcda/com"
+)
+
+// GroupSummary describes a group that exists on the server, as reported by EnumerateServerGroups.
+type GroupSummary struct {
+	// Name is the name of the group.
+	Name string
+	// ServerHandle is the handle of the group on the server, as passed to OPCGroups.Remove.
+	ServerHandle uint32
+	// UpdateRate is the revised update rate of the group in milliseconds.
+	UpdateRate uint32
+	// Active is the active state of the group.
+	Active bool
+}
+
+// groupEnumBatch is the number of groups requested per Next call when the group enumerator is drained.
+const groupEnumBatch = 100
+
+// groupStateReader reads the state of an enumerated group. It is satisfied by comGroupProvider.
+type groupStateReader interface {
+	// GetState retrieves the current state of the group.
+	GetState() (updateRate uint32, active bool, name string, timeBias int32, deadband float32, localeID uint32, clientHandle uint32, serverHandle uint32, err error)
+	// Release releases the interface of the group.
+	Release()
+}
+
+// groupEnumerator enumerates the groups of a server.
+type groupEnumerator interface {
+	// Next returns up to celt further groups; an empty result ends the enumeration.
+	Next(celt uint32) ([]groupStateReader, error)
+	// Release releases the enumerator.
+	Release()
+}
+
+// comGroupEnumerator is the groupEnumerator of an IEnumUnknown returned by CreateGroupEnumerator.
+type comGroupEnumerator struct {
+	enum     *com.IEnumUnknown
+	authInfo *com.COAUTHINFO
+}
+
+// Next returns the IOPCGroupStateMgt interfaces of up to celt further groups. The groups already acquired
+// are released if one of them fails.
+func (e *comGroupEnumerator) Next(celt uint32) ([]groupStateReader, error) {
+	objects, err := e.enum.Next(celt)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for _, object := range objects {
+			object.Release()
+		}
+	}()
+	groups := make([]groupStateReader, 0, len(objects))
+	for _, object := range objects {
+		var iUnknown *com.IUnknown
+		err = object.QueryInterface(&com.IID_IOPCGroupStateMgt, unsafe.Pointer(&iUnknown))
+		if err == nil {
+			err = setProxyBlanket(iUnknown, e.authInfo)
+			if err != nil {
+				iUnknown.Release()
+				err = NewOPCWrapperError("set proxy blanket IOPCGroupStateMgt", err)
+			}
+		}
+		if err != nil {
+			for _, group := range groups {
+				group.Release()
+			}
+			return nil, err
+		}
+		groups = append(groups, &comGroupProvider{groupStateMgt: &com.IOPCGroupStateMgt{IUnknown: iUnknown}})
+	}
+	return groups, nil
+}
+
+// Release releases the enumerator.
+func (e *comGroupEnumerator) Release() {
+	e.enum.Release()
+}
+
+// newComGroupEnumerator applies authInfo to the enumerator proxy and wraps it. ppUnk is released on failure.
+func newComGroupEnumerator(ppUnk *com.IUnknown, authInfo *com.COAUTHINFO) (groupEnumerator, error) {
+	err := setProxyBlanket(ppUnk, authInfo)
+	if err != nil {
+		ppUnk.Release()
+		return nil, NewOPCWrapperError("set proxy blanket IEnumUnknown", err)
+	}
+	return &comGroupEnumerator{enum: &com.IEnumUnknown{IUnknown: ppUnk}, authInfo: authInfo}, nil
+}
+
+// EnumerateServerGroups lists the groups of the server in scope, one of the OPC_ENUM constants, with their
+// name, server handle, update rate and active state read from each group with GetState. OPC_ENUM_PRIVATE
+// lists the private groups, OPC_ENUM_PUBLIC the public groups and OPC_ENUM_ALL both; the _CONNECTIONS
+// scopes list only the groups that have a callback connection.
+//
+// The private groups listed are those of this connection, including groups created through the COM
+// interfaces directly and missing from GetOPCGroups. Groups belong to the connection that created them,
+// and servers do not show the private groups of other clients, so the groups of a crashed client are
+// normally not listed; the server removes them itself once COM reports the client gone, which takes a few
+// minutes. Public groups are visible to every client.
+//
+// Example:
+//
+//	groups, err := server.EnumerateServerGroups(opcda.OPC_ENUM_ALL)
+//	if err != nil {
+//		return err
+//	}
+//	for _, group := range groups {
+//		log.Printf("%s: %d ms, active %v", group.Name, group.UpdateRate, group.Active)
+//	}
+func (s *OPCServer) EnumerateServerGroups(scope int) ([]GroupSummary, error) {
+	if s == nil || s.provider == nil {
+		return nil, errors.New("uninitialized server connection")
+	}
+	if scope < OPC_ENUM_PRIVATE_CONNECTIONS || scope > OPC_ENUM_ALL {
+		return nil, fmt.Errorf("invalid group enumeration scope %d", scope)
+	}
+	// the enumerator and the groups it returns are called on the thread of a pinned runtime as well
+	if p, ok := s.provider.(*pinnedServerProvider); ok {
+		var summaries []GroupSummary
+		var err error
+		if doErr := p.runtime.do(func() {
+			summaries, err = enumerateGroups(p.provider, s.factories.newGroupEnumerator, uint32(scope), s.authInfo)
+		}); doErr != nil {
+			return nil, doErr
+		}
+		return summaries, err
+	}
+	return enumerateGroups(s.provider, s.factories.newGroupEnumerator, uint32(scope), s.authInfo)
+}
+
+// enumerateGroups drains the group enumerator of provider for scope, wrapped with newEnumerator, and reads
+// the state of every group.
+func enumerateGroups(provider serverProvider, newEnumerator func(*com.IUnknown, *com.COAUTHINFO) (groupEnumerator, error), scope uint32, authInfo *com.COAUTHINFO) ([]GroupSummary, error) {
+	ppUnk, err := provider.CreateGroupEnumerator(scope, &com.IID_IEnumUnknown)
+	if err != nil {
+		return nil, err
+	}
+	// servers return no enumerator when the scope holds no groups
+	if ppUnk == nil {
+		return nil, nil
+	}
+	enum, err := newEnumerator(ppUnk, authInfo)
+	if err != nil {
+		return nil, err
+	}
+	defer enum.Release()
+	var summaries []GroupSummary
+	for {
+		groups, err := enum.Next(groupEnumBatch)
+		if err != nil {
+			return nil, err
+		}
+		if len(groups) == 0 {
+			return summaries, nil
+		}
+		for i, group := range groups {
+			updateRate, active, name, _, _, _, _, serverHandle, err := group.GetState()
+			if err != nil {
+				for _, rest := range groups[i:] {
+					rest.Release()
+				}
+				return nil, err
+			}
+			group.Release()
+			summaries = append(summaries, GroupSummary{Name: name, ServerHandle: serverHandle, UpdateRate: updateRate, Active: active})
+		}
+	}
+}
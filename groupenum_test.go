@@ -0,0 +1,120 @@
+//go:build windows
+
+package opcda
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wends155/opcda/com"
+	"golang.org/x/sys/windows"
+)
+
+// mockGroupEnumerator returns its batches from Next in order and then an empty batch.
+type mockGroupEnumerator struct {
+	batches  [][]groupStateReader
+	released bool
+}
+
+func (m *mockGroupEnumerator) Next(celt uint32) ([]groupStateReader, error) {
+	if len(m.batches) == 0 {
+		return nil, nil
+	}
+	batch := m.batches[0]
+	m.batches = m.batches[1:]
+	return batch, nil
+}
+
+func (m *mockGroupEnumerator) Release() {
+	m.released = true
+}
+
+// enumeratedGroup returns a group reporting the given state that counts its releases.
+func enumeratedGroup(name string, serverHandle, updateRate uint32, active bool, released *int) groupStateReader {
+	return &mockGroupProvider{
+		GetStateFn: func() (uint32, bool, string, int32, float32, uint32, uint32, uint32, error) {
+			return updateRate, active, name, 0, 0, 1033, 0, serverHandle, nil
+		},
+		ReleaseFn: func() { *released++ },
+	}
+}
+
+func TestOPCServer_EnumerateServerGroups_Mocked(t *testing.T) {
+	var scope uint32
+	var iid *windows.GUID
+	provider := &mockServerProvider{
+		CreateGroupEnumeratorFn: func(s uint32, i *windows.GUID) (*com.IUnknown, error) {
+			scope, iid = s, i
+			return &com.IUnknown{}, nil
+		},
+	}
+	released := 0
+	enum := &mockGroupEnumerator{batches: [][]groupStateReader{
+		{enumeratedGroup("Fast", 1, 100, true, &released), enumeratedGroup("Slow", 2, 5000, false, &released)},
+		{enumeratedGroup("Public", 3, 1000, true, &released)},
+	}}
+	server := newOPCServerWithProvider(provider, "mock", "localhost")
+	server.factories.newGroupEnumerator = func(*com.IUnknown, *com.COAUTHINFO) (groupEnumerator, error) {
+		return enum, nil
+	}
+
+	groups, err := server.EnumerateServerGroups(OPC_ENUM_ALL)
+	assert.NoError(t, err)
+	assert.Equal(t, []GroupSummary{
+		{Name: "Fast", ServerHandle: 1, UpdateRate: 100, Active: true},
+		{Name: "Slow", ServerHandle: 2, UpdateRate: 5000, Active: false},
+		{Name: "Public", ServerHandle: 3, UpdateRate: 1000, Active: true},
+	}, groups)
+	assert.Equal(t, uint32(OPC_ENUM_ALL), scope)
+	assert.Equal(t, &com.IID_IEnumUnknown, iid)
+	assert.Equal(t, 3, released, "every enumerated group must be released")
+	assert.True(t, enum.released)
+}
+
+func TestOPCServer_EnumerateServerGroups_Errors_Mocked(t *testing.T) {
+	provider := &mockServerProvider{}
+	server := newOPCServerWithProvider(provider, "mock", "localhost")
+
+	// no enumerator means no groups
+	groups, err := server.EnumerateServerGroups(OPC_ENUM_PRIVATE)
+	assert.NoError(t, err)
+	assert.Empty(t, groups)
+
+	_, err = server.EnumerateServerGroups(0)
+	assert.Error(t, err)
+	_, err = server.EnumerateServerGroups(OPC_ENUM_ALL + 1)
+	assert.Error(t, err)
+
+	provider.CreateGroupEnumeratorFn = func(uint32, *windows.GUID) (*com.IUnknown, error) {
+		return nil, com.HRESULT(com.E_INVALIDARG)
+	}
+	_, err = server.EnumerateServerGroups(OPC_ENUM_ALL)
+	assert.ErrorIs(t, err, com.HRESULT(com.E_INVALIDARG))
+
+	// a group failing GetState fails the enumeration and the remaining groups are released
+	provider.CreateGroupEnumeratorFn = func(uint32, *windows.GUID) (*com.IUnknown, error) {
+		return &com.IUnknown{}, nil
+	}
+	released := 0
+	failing := &mockGroupProvider{
+		GetStateFn: func() (uint32, bool, string, int32, float32, uint32, uint32, uint32, error) {
+			return 0, false, "", 0, 0, 0, 0, 0, com.HRESULT(com.E_FAIL)
+		},
+		ReleaseFn: func() { released++ },
+	}
+	enum := &mockGroupEnumerator{batches: [][]groupStateReader{
+		{enumeratedGroup("A", 1, 100, true, &released), failing, enumeratedGroup("B", 2, 100, true, &released)},
+	}}
+	server.factories.newGroupEnumerator = func(*com.IUnknown, *com.COAUTHINFO) (groupEnumerator, error) {
+		return enum, nil
+	}
+	groups, err = server.EnumerateServerGroups(OPC_ENUM_ALL)
+	assert.ErrorIs(t, err, com.HRESULT(com.E_FAIL))
+	assert.Nil(t, groups)
+	assert.Equal(t, 3, released)
+	assert.True(t, enum.released)
+
+	var nilServer *OPCServer
+	_, err = nilServer.EnumerateServerGroups(OPC_ENUM_ALL)
+	assert.Error(t, err)
+}
@@ -0,0 +1,119 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/wends155/opcda/com"
+)
+
+// GroupState is the lifecycle state of an OPCGroup.
+//
+// A group added by OPCGroups.Add or AddWithOptions, or bound by Attach or ConnectPublic, starts in
+// GroupActive or GroupInactive, following the active state it was created with or read from the server.
+// Activate and Deactivate move it between GroupActive and GroupInactive, and Release, which
+// OPCGroups.Remove calls, moves it to GroupReleased. A released group cannot be activated or
+// deactivated. Reconnect rebinds released groups of the connection to the state they were last
+// requested in.
+//
+//	GroupCreated  --Activate-->   GroupActive
+//	GroupCreated  --Deactivate--> GroupInactive
+//	GroupActive   <--Activate/Deactivate--> GroupInactive
+//	any state     --Release-->    GroupReleased
+type GroupState int32
+
+const (
+	// GroupCreated is the state of a group that is not bound to a group on the server yet.
+	GroupCreated GroupState = iota
+	// GroupActive is the state of a group activated with Activate.
+	GroupActive
+	// GroupInactive is the state of a group deactivated with Deactivate.
+	GroupInactive
+	// GroupReleased is the state of a group whose interfaces were released; it cannot be used any more.
+	GroupReleased
+)
+
+// String returns the name of the state.
+func (s GroupState) String() string {
+	switch s {
+	case GroupCreated:
+		return "Created"
+	case GroupActive:
+		return "Active"
+	case GroupInactive:
+		return "Inactive"
+	case GroupReleased:
+		return "Released"
+	}
+	return fmt.Sprintf("GroupState(%d)", int32(s))
+}
+
+// ErrInvalidGroupTransition is wrapped by the errors of Activate, Deactivate and SetIsActive when the
+// group cannot make the transition from its current state.
+var ErrInvalidGroupTransition = errors.New("invalid group state transition")
+
+// State returns the lifecycle state of the group.
+func (g *OPCGroup) State() GroupState {
+	if g == nil {
+		return GroupReleased
+	}
+	g.stateLock.Lock()
+	defer g.stateLock.Unlock()
+	return g.state
+}
+
+// Activate makes the group active, so the server updates its active items and sends data changes.
+// Activating an active group does nothing. It fails with ErrInvalidGroupTransition on a released group.
+func (g *OPCGroup) Activate() error {
+	return g.transition(GroupActive)
+}
+
+// Deactivate makes the group inactive, so the server stops updating it. Deactivating an inactive group
+// does nothing. It fails with ErrInvalidGroupTransition on a released group.
+func (g *OPCGroup) Deactivate() error {
+	return g.transition(GroupInactive)
+}
+
+// transition moves the group to GroupActive or GroupInactive, setting the active state on the server.
+// The state is unchanged if the server call fails.
+func (g *OPCGroup) transition(to GroupState) error {
+	if g == nil || g.groupProvider == nil {
+		return errors.New("uninitialized group")
+	}
+	g.stateLock.Lock()
+	defer g.stateLock.Unlock()
+	if g.state == GroupReleased {
+		return fmt.Errorf("%w: %v to %v", ErrInvalidGroupTransition, g.state, to)
+	}
+	if g.state == to {
+		return nil
+	}
+	active := to == GroupActive
+	v := com.BoolToComBOOL(active)
+	_, err := g.groupProvider.SetState(nil, &v, nil, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	g.settle(active)
+	return nil
+}
+
+// settleState records active, the active state of the group on the server after it was added or bound,
+// as its requested activity and derives its state from it.
+func (g *OPCGroup) settleState(active bool) {
+	g.stateLock.Lock()
+	defer g.stateLock.Unlock()
+	g.settle(active)
+}
+
+// settle records active as the requested activity of the group and moves it to GroupActive or
+// GroupInactive. Every change of activity goes through it. The caller must hold g.stateLock.
+func (g *OPCGroup) settle(active bool) {
+	g.requested.active = active
+	g.state = GroupInactive
+	if active {
+		g.state = GroupActive
+	}
+}
@@ -0,0 +1,84 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wends155/opcda/com"
+	"golang.org/x/sys/windows"
+)
+
+func TestOPCGroup_StateTransitions_Mocked(t *testing.T) {
+	var calls []bool
+	var setErr error
+	server := newOPCServerWithProvider(&mockServerProvider{}, "mock", "localhost")
+	group := newInflightTestGroup(server, &mockGroupProvider{
+		SetStateFn: func(pRequestedUpdateRate *uint32, pActive *int32, pTimeBias *int32, pPercentDeadband *float32, pLCID *uint32, phClientGroup *uint32) (uint32, error) {
+			calls = append(calls, *pActive == com.BoolToComBOOL(true))
+			return 0, setErr
+		},
+	})
+	assert.Equal(t, GroupCreated, group.State())
+
+	assert.NoError(t, group.Activate())
+	assert.Equal(t, GroupActive, group.State())
+	assert.NoError(t, group.SetIsActive(true), "activating an active group does nothing")
+	assert.NoError(t, group.Deactivate())
+	assert.Equal(t, GroupInactive, group.State())
+	assert.Equal(t, []bool{true, false}, calls)
+
+	setErr = errors.New("server busy")
+	assert.EqualError(t, group.Activate(), "server busy")
+	assert.Equal(t, GroupInactive, group.State(), "a failed call keeps the state")
+
+	group.Release()
+	assert.Equal(t, GroupReleased, group.State())
+	err := group.Activate()
+	assert.ErrorIs(t, err, ErrInvalidGroupTransition)
+	assert.EqualError(t, err, "invalid group state transition: Released to Active")
+	assert.ErrorIs(t, group.SetIsActive(false), ErrInvalidGroupTransition)
+	assert.Len(t, calls, 3)
+
+	assert.Equal(t, GroupReleased, (*OPCGroup)(nil).State())
+	assert.Equal(t, "GroupState(9)", GroupState(9).String())
+}
+
+func TestOPCGroups_Add_InitialState_Mocked(t *testing.T) {
+	var setCalls int
+	server := newOPCServerWithProvider(&mockServerProvider{
+		AddGroupFn: func(name string, active bool, updateRate uint32, clientGroup uint32, timeBias *int32, deadband *float32, localeID uint32, iid *windows.GUID) (uint32, uint32, *com.IUnknown, error) {
+			return 7, 100, nil, nil
+		},
+	}, "mock", "localhost")
+	server.factories.newGroup = func(gs *OPCGroups, iUnknown *com.IUnknown, clientGroupHandle uint32, serverGroupHandle uint32, groupName string, revisedUpdateRate uint32) (*OPCGroup, error) {
+		return &OPCGroup{
+			parent: gs,
+			groupProvider: &mockGroupProvider{
+				SetStateFn: func(pRequestedUpdateRate *uint32, pActive *int32, pTimeBias *int32, pPercentDeadband *float32, pLCID *uint32, phClientGroup *uint32) (uint32, error) {
+					setCalls++
+					return 0, nil
+				},
+			},
+			groupName: groupName,
+		}, nil
+	}
+	groups := server.GetOPCGroups()
+
+	active, err := groups.AddWithOptions("active", GroupOptions{Active: true})
+	assert.NoError(t, err)
+	assert.Equal(t, GroupActive, active.State())
+	assert.NoError(t, active.Activate(), "activating a group added active does nothing")
+	assert.Zero(t, setCalls)
+
+	groups.SetDefaultGroupIsActive(false)
+	inactive, err := groups.Add("inactive")
+	assert.NoError(t, err)
+	assert.Equal(t, GroupInactive, inactive.State())
+	assert.NoError(t, inactive.Activate())
+	assert.Equal(t, GroupActive, inactive.State())
+	assert.True(t, inactive.requested.active)
+	assert.Equal(t, 1, setCalls)
+}
@@ -0,0 +1,128 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"time"
+	"unsafe"
+
+	"github.com/wends155/opcda/com"
+	"golang.org/x/sys/windows"
+)
+
+// Health is a snapshot of the state of a server connection in a stable wire format for health endpoints.
+// Its JSON shape is part of the API; times are RFC 3339 and zero times are omitted.
+type Health struct {
+	// ProgID and Node identify the server.
+	ProgID string `json:"progID"`
+	Node   string `json:"node"`
+	// ConnectedSince is the time the current connection was established, including by Reconnect.
+	ConnectedSince time.Time `json:"connectedSince,omitzero"`
+	// LastSuccessfulCall is the time the server last answered a status request.
+	LastSuccessfulCall time.Time `json:"lastSuccessfulCall,omitzero"`
+	// Capabilities lists the optional interfaces the server implements, such as "IOPCBrowse".
+	Capabilities []string `json:"capabilities"`
+	// Status is the status reported by the server; nil if the status request failed.
+	Status *com.ServerStatus `json:"status,omitempty"`
+}
+
+// healthCapabilities are the optional server interfaces reported in Health.Capabilities.
+var healthCapabilities = []struct {
+	name string
+	iid  *windows.GUID
+}{
+	{"IOPCBrowseServerAddressSpace", &com.IID_IOPCBrowseServerAddressSpace},
+	{"IOPCBrowse", &com.IID_IOPCBrowse},
+	{"IOPCItemIO", &com.IID_IOPCItemIO},
+	{"IConnectionPointContainer", &com.IID_IConnectionPointContainer},
+}
+
+// HealthSnapshot returns the health of a server connection, combining the status reported by the server
+// with the metadata of the connection. If the status request fails, the snapshot is still returned,
+// without Status, together with the error, so LastSuccessfulCall shows how long the server has been silent.
+func HealthSnapshot(server *OPCServer) (*Health, error) {
+	if server == nil || server.provider == nil {
+		return nil, errors.New("uninitialized server connection")
+	}
+	health := &Health{
+		ProgID:         server.Name,
+		Node:           server.Node,
+		ConnectedSince: unixNanoTime(server.connectedSince.Load()),
+		Capabilities:   server.capabilities(),
+	}
+	status, err := server.getStatus()
+	health.LastSuccessfulCall = unixNanoTime(server.lastCall.Load())
+	if err != nil {
+		return health, err
+	}
+	health.Status = status
+	return health, nil
+}
+
+// ServerStateError is returned by Ping when the server answers but is not in the OPC_STATUS_RUNNING state.
+type ServerStateError struct {
+	// State is the state the server reported, such as OPC_STATUS_FAILED.
+	State com.OPCServerState
+}
+
+// Error returns the state the server reported.
+func (e *ServerStateError) Error() string {
+	return "server is not running: state " + e.State.String()
+}
+
+// Ping is a liveness probe for health endpoints. It returns nil if the server answers a status request
+// and reports OPC_STATUS_RUNNING, a *ServerStateError if it answers in any other state, such as
+// OPC_STATUS_FAILED, OPC_STATUS_SUSPENDED or OPC_STATUS_COMM_FAULT, and the error of the call otherwise.
+//
+// Example:
+//
+//	var stateErr *opcda.ServerStateError
+//	if err := server.Ping(); errors.As(err, &stateErr) {
+//		log.Printf("server up but %v", stateErr.State)
+//	}
+func (s *OPCServer) Ping() error {
+	if s == nil || s.provider == nil {
+		return errors.New("uninitialized server connection")
+	}
+	status, err := s.getStatus()
+	if err != nil {
+		return err
+	}
+	if status.ServerState != OPC_STATUS_RUNNING {
+		return &ServerStateError{State: status.ServerState}
+	}
+	return nil
+}
+
+// getStatus returns the status of the server and records the time of successful calls.
+func (s *OPCServer) getStatus() (*com.ServerStatus, error) {
+	status, err := s.provider.GetStatus()
+	if err != nil {
+		return nil, err
+	}
+	s.lastCall.Store(time.Now().UnixNano())
+	return status, nil
+}
+
+// capabilities returns the names of the optional interfaces in healthCapabilities the server implements.
+func (s *OPCServer) capabilities() []string {
+	capabilities := []string{}
+	for _, c := range healthCapabilities {
+		var itf *com.IUnknown
+		if s.provider.QueryInterface(c.iid, unsafe.Pointer(&itf)) != nil || itf == nil {
+			continue
+		}
+		releaseInterface(itf)
+		capabilities = append(capabilities, c.name)
+	}
+	return capabilities
+}
+
+// unixNanoTime converts Unix nanoseconds to a time, mapping 0 to the zero time.
+func unixNanoTime(ns int64) time.Time {
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns).UTC()
+}
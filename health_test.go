@@ -0,0 +1,114 @@
+//go:build windows
+
+package opcda
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wends155/opcda/com"
+	"golang.org/x/sys/windows"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// newHealthTestServer returns a server implementing IOPCBrowseServerAddressSpace and IConnectionPointContainer.
+func newHealthTestServer(t *testing.T, status *com.ServerStatus, statusErr error) *OPCServer {
+	released := 0
+	oldRelease := releaseInterface
+	t.Cleanup(func() {
+		releaseInterface = oldRelease
+		assert.Equal(t, 2, released, "probed interfaces must be released")
+	})
+	releaseInterface = func(itf *com.IUnknown) { released++ }
+	return newOPCServerWithProvider(&mockServerProvider{
+		QueryInterfaceFn: func(iid *windows.GUID, ppv unsafe.Pointer) error {
+			if *iid == com.IID_IOPCBrowseServerAddressSpace || *iid == com.IID_IConnectionPointContainer {
+				*(**com.IUnknown)(ppv) = &com.IUnknown{}
+				return nil
+			}
+			return com.HRESULT(com.E_NOINTERFACE)
+		},
+		GetStatusFn: func() (*com.ServerStatus, error) {
+			return status, statusErr
+		},
+	}, "Vendor.Server.1", "plant-pc")
+}
+
+func TestHealthSnapshot_Golden_Mocked(t *testing.T) {
+	server := newHealthTestServer(t, &com.ServerStatus{
+		StartTime:      time.Date(2024, 5, 1, 6, 0, 0, 0, time.UTC),
+		CurrentTime:    time.Date(2024, 5, 1, 8, 30, 0, 500000000, time.UTC),
+		LastUpdateTime: time.Date(2024, 5, 1, 8, 29, 59, 0, time.UTC),
+		ServerState:    OPC_STATUS_RUNNING,
+		GroupCount:     3,
+		BandWidth:      OPC_BANDWIDTH_NOT_SUPPORTED,
+		MajorVersion:   2,
+		MinorVersion:   5,
+		BuildNumber:    118,
+		Reserved:       7,
+		VendorInfo:     "Vendor OPC DA Server",
+	}, nil)
+	server.connectedSince.Store(time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC).UnixNano())
+
+	before := time.Now()
+	health, err := HealthSnapshot(server)
+	assert.NoError(t, err)
+	assert.False(t, health.LastSuccessfulCall.Before(before.Truncate(time.Second)))
+	health.LastSuccessfulCall = time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)
+
+	got, err := json.MarshalIndent(health, "", "  ")
+	assert.NoError(t, err)
+	got = append(got, '\n')
+	golden := filepath.Join("testdata", "health.golden")
+	if *updateGolden {
+		assert.NoError(t, os.WriteFile(golden, got, 0o644))
+	}
+	want, err := os.ReadFile(golden)
+	assert.NoError(t, err)
+	assert.Equal(t, string(want), string(got), "the Health wire format changed; run with -update if intended")
+}
+
+func TestHealthSnapshot_StatusFails_Mocked(t *testing.T) {
+	server := newHealthTestServer(t, nil, errors.New("RPC server unavailable"))
+	server.lastCall.Store(time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC).UnixNano())
+
+	health, err := HealthSnapshot(server)
+	assert.EqualError(t, err, "RPC server unavailable")
+	assert.Nil(t, health.Status)
+	assert.Equal(t, time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC), health.LastSuccessfulCall)
+	assert.Equal(t, []string{"IOPCBrowseServerAddressSpace", "IConnectionPointContainer"}, health.Capabilities)
+}
+
+func TestOPCServer_Ping_Mocked(t *testing.T) {
+	var status *com.ServerStatus
+	var statusErr error
+	server := newOPCServerWithProvider(&mockServerProvider{
+		GetStatusFn: func() (*com.ServerStatus, error) {
+			return status, statusErr
+		},
+	}, "mock", "localhost")
+
+	status = &com.ServerStatus{ServerState: OPC_STATUS_RUNNING}
+	assert.NoError(t, server.Ping())
+	assert.NotZero(t, server.lastCall.Load())
+
+	for _, state := range []com.OPCServerState{OPC_STATUS_FAILED, OPC_STATUS_SUSPENDED, OPC_STATUS_COMM_FAULT} {
+		status = &com.ServerStatus{ServerState: state}
+		var stateErr *ServerStateError
+		assert.ErrorAs(t, server.Ping(), &stateErr)
+		assert.Equal(t, state, stateErr.State)
+	}
+
+	statusErr = com.HRESULT(0x800706BA)
+	assert.ErrorIs(t, server.Ping(), com.HRESULT(0x800706BA))
+
+	assert.Error(t, (*OPCServer)(nil).Ping())
+}
@@ -0,0 +1,229 @@
+//go:build windows
+
+package opcda
+
+import (
+	"context"
+	"time"
+)
+
+// DataChangeOption configures RegisterDataChangeWithPolicy.
+type DataChangeOption func(*dataChangeOptions)
+
+// dataChangeOptions holds the settings applied by DataChangeOption values.
+type dataChangeOptions struct {
+	initialSnapshot bool
+	maxAge          time.Duration
+	usableQuality   bool
+}
+
+// WithInitialSnapshot makes the new subscriber receive the current values of the active items of the group
+// as a data change marked Initial before any live update. If every active item holds a value read no more
+// than maxAge ago, the snapshot is served from the item values; otherwise the group issues an AsyncRefresh
+// from the server cache and delivers its result to the new subscriber only. Live updates arriving in the
+// meantime are held back and delivered after the snapshot. If the refresh fails or does not complete
+// within a few seconds, the item values are delivered as they are.
+//
+// The snapshot is delivered from a goroutine of its own, so registering does not wait for the subscriber
+// to receive it. Under DeliveryDropNewest the snapshot waits as long as for a refresh for a subscriber that
+// does not receive yet, such as the reader of an unbuffered channel started after registering.
+//
+// Example:
+//
+//	ch := make(chan *opcda.DataChangeCallBackData, 16)
+//	err := group.RegisterDataChangeWithPolicy(ch, opcda.DeliveryDropNewest, opcda.WithInitialSnapshot(time.Minute))
+func WithInitialSnapshot(maxAge time.Duration) DataChangeOption {
+	return func(o *dataChangeOptions) {
+		o.initialSnapshot = true
+		o.maxAge = maxAge
+	}
+}
+
+// initialSnapshotTimeout bounds how long a subscriber waits for the refresh of its initial snapshot.
+var initialSnapshotTimeout = 10 * time.Second
+
+// snapshotGate holds back the live updates of a subscriber until its initial snapshot is delivered.
+// Its fields are guarded by the callbackLock of the group.
+type snapshotGate struct {
+	policy        DeliveryPolicy
+	ctx           context.Context // ctx is the context of the callback loop the subscriber registered with.
+	transactionID uint32          // transactionID is the AsyncRefresh transaction of the snapshot, or 0.
+	opening       bool            // opening is set once the snapshot is being delivered.
+	timer         *time.Timer
+	backlog       []*DataChangeCallBackData
+}
+
+// addGate starts holding back live updates for ch. The caller must hold callbackLock.
+func (g *OPCGroup) addGate(ch chan *DataChangeCallBackData, policy DeliveryPolicy) *snapshotGate {
+	if g.dataChangeGates == nil {
+		g.dataChangeGates = make(map[chan *DataChangeCallBackData]*snapshotGate)
+	}
+	gate := &snapshotGate{policy: policy, ctx: g.ctx}
+	if gate.ctx == nil {
+		gate.ctx = context.Background()
+	}
+	g.dataChangeGates[ch] = gate
+	return gate
+}
+
+// dropGate discards the gate of ch and its held-back updates. The caller must hold callbackLock.
+func (g *OPCGroup) dropGate(ch chan *DataChangeCallBackData) {
+	if gate := g.dataChangeGates[ch]; gate != nil && gate.timer != nil {
+		gate.timer.Stop()
+	}
+	delete(g.dataChangeGates, ch)
+}
+
+// snapshotOwner returns the subscriber whose initial snapshot is the refresh transaction, or nil.
+// The caller must hold callbackLock.
+func (g *OPCGroup) snapshotOwner(transactionID uint32) chan *DataChangeCallBackData {
+	if transactionID == 0 {
+		return nil
+	}
+	for ch, gate := range g.dataChangeGates {
+		if gate.transactionID == transactionID && !gate.opening {
+			return ch
+		}
+	}
+	return nil
+}
+
+// startSnapshot delivers the initial snapshot of ch from the item values if they are fresh enough, and
+// otherwise requests it with an AsyncRefresh whose completion opens the gate.
+func (g *OPCGroup) startSnapshot(ch chan *DataChangeCallBackData, gate *snapshotGate, maxAge time.Duration) {
+	data, fresh := g.itemSnapshot(maxAge)
+	if fresh {
+		go g.openGate(ch, data)
+		return
+	}
+	transactionID := g.nextAwaitTransactionID()
+	g.callbackLock.Lock()
+	gate.transactionID = transactionID
+	g.callbackLock.Unlock()
+	_, err := g.AsyncRefresh(OPC_DS_CACHE, transactionID)
+	if err != nil {
+		go g.openGate(ch, data)
+		return
+	}
+	g.callbackLock.Lock()
+	if !gate.opening {
+		gate.timer = time.AfterFunc(initialSnapshotTimeout, func() {
+			data, _ := g.itemSnapshot(0)
+			g.openGate(ch, data)
+		})
+	}
+	g.callbackLock.Unlock()
+}
+
+// openGate delivers the initial snapshot to ch, followed by the updates held back meanwhile, and then
+// lets live updates through. Only the first call for a gate delivers anything. It may wait for the
+// subscriber, so callers run it on a goroutine of its own.
+func (g *OPCGroup) openGate(ch chan *DataChangeCallBackData, snapshot *DataChangeCallBackData) {
+	g.callbackLock.Lock()
+	gate := g.dataChangeGates[ch]
+	if gate == nil || gate.opening {
+		g.callbackLock.Unlock()
+		return
+	}
+	gate.opening = true
+	if gate.timer != nil {
+		gate.timer.Stop()
+	}
+	usable := g.dataChangeUsable[ch]
+	g.callbackLock.Unlock()
+
+	if usable {
+		snapshot = g.usableOnly(snapshot)
+	}
+	g.deliverSnapshot(gate.ctx, ch, snapshot, gate.policy)
+	for {
+		g.callbackLock.Lock()
+		if g.dataChangeGates[ch] != gate {
+			// unregistered meanwhile
+			g.callbackLock.Unlock()
+			return
+		}
+		backlog := gate.backlog
+		gate.backlog = nil
+		if len(backlog) == 0 {
+			delete(g.dataChangeGates, ch)
+			g.callbackLock.Unlock()
+			return
+		}
+		g.callbackLock.Unlock()
+		for _, data := range backlog {
+			if usable {
+				if data = g.usableOnly(data); len(data.ItemClientHandles) == 0 {
+					continue
+				}
+			}
+			g.deliverDataChange(gate.ctx, ch, data, gate.policy)
+		}
+	}
+}
+
+// deliverSnapshot sends the initial snapshot to ch according to policy, except that under
+// DeliveryDropNewest it waits up to initialSnapshotTimeout for the subscriber to receive it.
+func (g *OPCGroup) deliverSnapshot(ctx context.Context, ch chan *DataChangeCallBackData, snapshot *DataChangeCallBackData, policy DeliveryPolicy) {
+	if policy != DeliveryDropNewest {
+		g.deliverDataChange(ctx, ch, snapshot, policy)
+		return
+	}
+	timer := time.NewTimer(initialSnapshotTimeout)
+	defer timer.Stop()
+	select {
+	case ch <- snapshot:
+	case <-ctx.Done():
+		g.dataChangeDropped.Add(1)
+	case <-timer.C:
+		g.dataChangeDropped.Add(1)
+	}
+}
+
+// openAllGates opens every pending gate with the current item values, for example when the group is
+// released and no refresh will complete.
+func (g *OPCGroup) openAllGates() {
+	g.callbackLock.Lock()
+	pending := make([]chan *DataChangeCallBackData, 0, len(g.dataChangeGates))
+	for ch := range g.dataChangeGates {
+		pending = append(pending, ch)
+	}
+	g.callbackLock.Unlock()
+	for _, ch := range pending {
+		data, _ := g.itemSnapshot(0)
+		go g.openGate(ch, data)
+	}
+}
+
+// itemSnapshot builds an initial snapshot from the last values read for the active items of the group.
+// fresh reports whether every value was read no more than maxAge ago; it is false if maxAge is not positive.
+func (g *OPCGroup) itemSnapshot(maxAge time.Duration) (data *DataChangeCallBackData, fresh bool) {
+	now := time.Now()
+	data = &DataChangeCallBackData{
+		GroupHandle: g.clientGroupHandle,
+		ReceivedAt:  now,
+		Initial:     true,
+	}
+	fresh = maxAge > 0
+	if g.items == nil {
+		return data, fresh
+	}
+	g.items.RLock()
+	items := append([]*OPCItem(nil), g.items.items...)
+	g.items.RUnlock()
+	for _, item := range items {
+		if !item.GetIsActive() {
+			continue
+		}
+		value, quality, timestamp := item.Snapshot()
+		if timestamp.IsZero() || now.Sub(timestamp) > maxAge {
+			fresh = false
+		}
+		data.ItemClientHandles = append(data.ItemClientHandles, item.GetClientHandle())
+		data.Values = append(data.Values, value)
+		data.Qualities = append(data.Qualities, quality)
+		data.TimeStamps = append(data.TimeStamps, timestamp)
+		data.Errors = append(data.Errors, nil)
+	}
+	return data, fresh
+}
@@ -0,0 +1,123 @@
+//go:build windows
+
+package opcda
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wends155/opcda/com"
+)
+
+func newSnapshotTestGroup(refresh func(source com.OPCDATASOURCE, transactionID uint32) (uint32, error)) *OPCGroup {
+	group := &OPCGroup{provider: &mockServerProvider{}, event: &DataEventReceiver{}, clientGroupHandle: 4}
+	group.groupProvider = &mockGroupProvider{AsyncRefreshFn: refresh}
+	group.items = NewOPCItems(group, &mockItemMgtProvider{}, group.provider)
+	group.items.items = []*OPCItem{
+		{clientHandle: 1, isActive: true, value: int32(7), quality: OPC_QUALITY_GOOD, timestamp: time.Now()},
+		{clientHandle: 2, isActive: false},
+	}
+	return group
+}
+
+func TestOPCGroup_InitialSnapshot_FromCache_Mocked(t *testing.T) {
+	group := newSnapshotTestGroup(func(source com.OPCDATASOURCE, transactionID uint32) (uint32, error) {
+		t.Fatal("fresh values must not be refreshed")
+		return 0, nil
+	})
+	ch := make(chan *DataChangeCallBackData, 4)
+	assert.NoError(t, group.RegisterDataChangeWithPolicy(ch, DeliveryDropNewest, WithInitialSnapshot(time.Minute)))
+	group.fireDataChange(context.Background(), &CDataChangeCallBackData{ItemClientHandles: []uint32{1}, Values: []interface{}{int32(8)}})
+
+	initial := <-ch
+	assert.True(t, initial.Initial)
+	assert.Equal(t, uint32(4), initial.GroupHandle)
+	assert.Equal(t, []uint32{1}, initial.ItemClientHandles)
+	assert.Equal(t, []interface{}{int32(7)}, initial.Values)
+	live := <-ch
+	assert.False(t, live.Initial)
+	assert.Equal(t, []interface{}{int32(8)}, live.Values)
+	assert.Eventually(t, func() bool {
+		group.callbackLock.Lock()
+		defer group.callbackLock.Unlock()
+		return len(group.dataChangeGates) == 0
+	}, time.Second, time.Millisecond)
+}
+
+func TestOPCGroup_InitialSnapshot_Refresh_Mocked(t *testing.T) {
+	var refreshID uint32
+	group := newSnapshotTestGroup(func(source com.OPCDATASOURCE, transactionID uint32) (uint32, error) {
+		assert.Equal(t, OPC_DS_CACHE, source)
+		refreshID = transactionID
+		return 1, nil
+	})
+	existing := make(chan *DataChangeCallBackData, 4)
+	assert.NoError(t, group.RegisterDataChange(existing))
+	ch := make(chan *DataChangeCallBackData, 4)
+	assert.NoError(t, group.RegisterDataChangeWithPolicy(ch, DeliveryDropNewest, WithInitialSnapshot(0)))
+	assert.NotZero(t, refreshID)
+
+	// a live update arriving before the refresh completes is held back
+	group.fireDataChange(context.Background(), &CDataChangeCallBackData{ItemClientHandles: []uint32{1}, Values: []interface{}{int32(8)}})
+	assert.Len(t, ch, 0)
+	assert.Len(t, existing, 1)
+
+	group.fireDataChange(context.Background(), &CDataChangeCallBackData{TransID: refreshID, ItemClientHandles: []uint32{1}, Values: []interface{}{int32(9)}})
+	initial := <-ch
+	assert.True(t, initial.Initial)
+	assert.Equal(t, []interface{}{int32(9)}, initial.Values)
+	live := <-ch
+	assert.Equal(t, []interface{}{int32(8)}, live.Values)
+	// the refresh is not delivered to the other subscriber
+	assert.Len(t, existing, 1)
+
+	group.fireDataChange(context.Background(), &CDataChangeCallBackData{ItemClientHandles: []uint32{1}, Values: []interface{}{int32(10)}})
+	assert.Eventually(t, func() bool { return len(ch) == 1 }, time.Second, time.Millisecond)
+	assert.Len(t, existing, 2)
+}
+
+func TestOPCGroup_InitialSnapshot_Unbuffered_Mocked(t *testing.T) {
+	for _, policy := range []DeliveryPolicy{DeliveryDropNewest, DeliveryBlock} {
+		group := newSnapshotTestGroup(func(source com.OPCDATASOURCE, transactionID uint32) (uint32, error) {
+			t.Fatal("fresh values must not be refreshed")
+			return 0, nil
+		})
+		ch := make(chan *DataChangeCallBackData)
+		// registering returns before anyone receives from the channel
+		registered := make(chan error, 1)
+		go func() { registered <- group.RegisterDataChangeWithPolicy(ch, policy, WithInitialSnapshot(time.Minute)) }()
+		select {
+		case err := <-registered:
+			assert.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatalf("registering with policy %d waited for the snapshot to be received", policy)
+		}
+		select {
+		case initial := <-ch:
+			assert.True(t, initial.Initial)
+			assert.Equal(t, []interface{}{int32(7)}, initial.Values)
+		case <-time.After(time.Second):
+			t.Fatalf("initial snapshot with policy %d was lost", policy)
+		}
+	}
+}
+
+func TestOPCGroup_InitialSnapshot_RefreshTimeout_Mocked(t *testing.T) {
+	old := initialSnapshotTimeout
+	t.Cleanup(func() { initialSnapshotTimeout = old })
+	initialSnapshotTimeout = 10 * time.Millisecond
+	group := newSnapshotTestGroup(func(source com.OPCDATASOURCE, transactionID uint32) (uint32, error) {
+		return 1, nil
+	})
+	ch := make(chan *DataChangeCallBackData, 4)
+	assert.NoError(t, group.RegisterDataChangeWithPolicy(ch, DeliveryDropNewest, WithInitialSnapshot(0)))
+	select {
+	case initial := <-ch:
+		assert.True(t, initial.Initial)
+		assert.Equal(t, []interface{}{int32(7)}, initial.Values)
+	case <-time.After(time.Second):
+		t.Fatal("initial snapshot was not delivered after the refresh timed out")
+	}
+}
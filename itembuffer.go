@@ -0,0 +1,119 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// ItemValue is a value of an item with its quality and timestamp, as kept by the item buffer.
+type ItemValue struct {
+	Value     interface{}
+	Quality   uint16
+	Timestamp time.Time
+}
+
+// valueRing is a fixed size ring of the most recent values of an item.
+type valueRing struct {
+	values []ItemValue
+	next   int
+	full   bool
+}
+
+// push stores a value, overwriting the oldest one when the ring is full.
+func (r *valueRing) push(v ItemValue) {
+	r.values[r.next] = v
+	r.next++
+	if r.next == len(r.values) {
+		r.next = 0
+		r.full = true
+	}
+}
+
+// snapshot returns the stored values from the oldest to the newest.
+func (r *valueRing) snapshot() []ItemValue {
+	if !r.full {
+		return append([]ItemValue(nil), r.values[:r.next]...)
+	}
+	out := make([]ItemValue, 0, len(r.values))
+	out = append(out, r.values[r.next:]...)
+	return append(out, r.values[:r.next]...)
+}
+
+// EnableBuffer keeps the last size values reported for the item by the data change callbacks of its
+// group, including the completions of AsyncRefresh, so derivative or debounce logic can look at recent
+// history without buffering the callback stream itself. Entries reported with an item error are not
+// buffered. Calling it again resizes the buffer and clears it; a size of zero disables buffering.
+// The group must be advised, for example by registering a data change channel, for values to arrive.
+func (i *OPCItem) EnableBuffer(size int) error {
+	if i == nil {
+		return errors.New("uninitialized item")
+	}
+	if size < 0 {
+		return errors.New("negative buffer size")
+	}
+	i.Lock()
+	was := i.buffer != nil
+	if size == 0 {
+		i.buffer = nil
+	} else {
+		i.buffer = &valueRing{values: make([]ItemValue, size)}
+	}
+	now := i.buffer != nil
+	i.Unlock()
+	if i.parent != nil && was != now {
+		if now {
+			atomic.AddInt32(&i.parent.buffered, 1)
+		} else {
+			atomic.AddInt32(&i.parent.buffered, -1)
+		}
+	}
+	return nil
+}
+
+// Buffer returns the buffered values of the item from the oldest to the newest, or nil if buffering is
+// not enabled.
+func (i *OPCItem) Buffer() []ItemValue {
+	if i == nil {
+		return nil
+	}
+	i.RLock()
+	defer i.RUnlock()
+	if i.buffer == nil {
+		return nil
+	}
+	return i.buffer.snapshot()
+}
+
+// bufferDataChange appends the entries of a data change to the buffers of their items.
+func (g *OPCGroup) bufferDataChange(data *DataChangeCallBackData) {
+	if g.items == nil || atomic.LoadInt32(&g.items.buffered) == 0 {
+		return
+	}
+	for idx, handle := range data.ItemClientHandles {
+		if idx < len(data.Errors) && data.Errors[idx] != nil {
+			continue
+		}
+		item, ok := g.items.itemByClientHandle(handle)
+		if !ok {
+			continue
+		}
+		var v ItemValue
+		if idx < len(data.Values) {
+			v.Value = data.Values[idx]
+		}
+		if idx < len(data.Qualities) {
+			v.Quality = data.Qualities[idx]
+		}
+		if idx < len(data.TimeStamps) {
+			v.Timestamp = data.TimeStamps[idx]
+		}
+		item.Lock()
+		if item.buffer != nil {
+			item.buffer.push(v)
+		}
+		item.Unlock()
+	}
+}
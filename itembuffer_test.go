@@ -0,0 +1,64 @@
+//go:build windows
+
+package opcda
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wends155/opcda/com"
+)
+
+func TestOPCItem_Buffer_Mocked(t *testing.T) {
+	group := &OPCGroup{groupProvider: &mockGroupProvider{}}
+	group.items = NewOPCItems(group, &mockItemMgtProvider{
+		AddItemsFn: func(defs []com.TagOPCITEMDEF) ([]com.TagOPCITEMRESULTStruct, []int32, error) {
+			return make([]com.TagOPCITEMRESULTStruct, len(defs)), make([]int32, len(defs)), nil
+		},
+	}, &mockServerProvider{})
+	added, _, err := group.items.AddItemsWithOptions([]ItemDef{{Tag: "a", ClientHandle: 1}, {Tag: "b", ClientHandle: 2}})
+	assert.NoError(t, err)
+	buffered, plain := added[0], added[1]
+
+	assert.Nil(t, buffered.Buffer())
+	assert.Error(t, buffered.EnableBuffer(-1))
+	assert.NoError(t, buffered.EnableBuffer(3))
+	assert.Empty(t, buffered.Buffer())
+
+	start := time.Now()
+	for n := 0; n < 5; n++ {
+		group.fireDataChange(context.Background(), &CDataChangeCallBackData{
+			ItemClientHandles: []uint32{1, 2},
+			Values:            []interface{}{int32(n), int32(n)},
+			Qualities:         []uint16{OPC_QUALITY_GOOD, OPC_QUALITY_GOOD},
+			TimeStamps:        []time.Time{start.Add(time.Duration(n) * time.Second), start},
+			Errors:            []int32{0, 0},
+		})
+	}
+	// entries reported with an error are not buffered
+	group.fireDataChange(context.Background(), &CDataChangeCallBackData{
+		ItemClientHandles: []uint32{1},
+		Values:            []interface{}{nil},
+		Qualities:         []uint16{OPC_QUALITY_BAD},
+		TimeStamps:        []time.Time{start},
+		Errors:            []int32{int32(OPCBadType)},
+	})
+
+	values := buffered.Buffer()
+	assert.Len(t, values, 3)
+	for n, v := range values {
+		assert.Equal(t, int32(n+2), v.Value)
+		assert.Equal(t, uint16(OPC_QUALITY_GOOD), v.Quality)
+		assert.Equal(t, start.Add(time.Duration(n+2)*time.Second), v.Timestamp)
+	}
+	assert.Nil(t, plain.Buffer())
+
+	assert.NoError(t, buffered.EnableBuffer(0))
+	assert.Nil(t, buffered.Buffer())
+	assert.Equal(t, int32(0), group.items.buffered)
+
+	var nilItem *OPCItem
+	assert.Error(t, nilItem.EnableBuffer(1))
+}
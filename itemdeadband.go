@@ -0,0 +1,84 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrItemDeadbandNotSupported is returned by the per-item deadband methods when the group of the item
+// does not expose IOPCItemDeadbandMgt, an optional OPC DA 3.0 interface.
+var ErrItemDeadbandNotSupported = errors.New("server does not support IOPCItemDeadbandMgt")
+
+// SetDeadband sets the percent deadband of the item, overriding the deadband of its group.
+// The percent applies to the EU range of analog items and must be between 0 and 100.
+//
+// Example:
+//
+//	err := item.SetDeadband(2)
+//	if errors.Is(err, opcda.ErrItemDeadbandNotSupported) {
+//		// fall back to group.SetDeadband
+//	}
+func (i *OPCItem) SetDeadband(percent float32) error {
+	g, err := i.deadbandGroup()
+	if err != nil {
+		return err
+	}
+	if percent < 0 || percent > 100 {
+		return fmt.Errorf("invalid deadband %g: must be between 0 and 100", percent)
+	}
+	errs, err := g.deadbandMgt.SetItemDeadband([]uint32{i.GetServerHandle()}, []float32{percent})
+	if err != nil {
+		return err
+	}
+	if errs[0] < 0 {
+		return i.getError(errs[0])
+	}
+	return nil
+}
+
+// GetDeadband returns the percent deadband set on the item with SetDeadband. Servers report an
+// error (OPC_E_DEADBANDNOTSET) when the item uses the deadband of its group.
+func (i *OPCItem) GetDeadband() (float32, error) {
+	g, err := i.deadbandGroup()
+	if err != nil {
+		return 0, err
+	}
+	deadbands, errs, err := g.deadbandMgt.GetItemDeadband([]uint32{i.GetServerHandle()})
+	if err != nil {
+		return 0, err
+	}
+	if errs[0] < 0 {
+		return 0, i.getError(errs[0])
+	}
+	return deadbands[0], nil
+}
+
+// ClearDeadband removes the deadband set on the item, so it uses the deadband of its group again.
+func (i *OPCItem) ClearDeadband() error {
+	g, err := i.deadbandGroup()
+	if err != nil {
+		return err
+	}
+	errs, err := g.deadbandMgt.ClearItemDeadband([]uint32{i.GetServerHandle()})
+	if err != nil {
+		return err
+	}
+	if errs[0] < 0 {
+		return i.getError(errs[0])
+	}
+	return nil
+}
+
+// deadbandGroup returns the group of the item if it supports per-item deadbands.
+func (i *OPCItem) deadbandGroup() (*OPCGroup, error) {
+	if i == nil || i.parent == nil || i.parent.parent == nil {
+		return nil, errors.New("uninitialized item")
+	}
+	g := i.parent.parent
+	if g.deadbandMgt == nil {
+		return nil, ErrItemDeadbandNotSupported
+	}
+	return g, nil
+}
@@ -0,0 +1,51 @@
+//go:build windows
+
+package opcda
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOPCItem_Deadband_Mocked(t *testing.T) {
+	deadbands := map[uint32]float32{}
+	group := &OPCGroup{deadbandMgt: &mockItemDeadbandMgtProvider{
+		SetItemDeadbandFn: func(serverHandles []uint32, values []float32) ([]int32, error) {
+			deadbands[serverHandles[0]] = values[0]
+			return []int32{0}, nil
+		},
+		GetItemDeadbandFn: func(serverHandles []uint32) ([]float32, []int32, error) {
+			value, ok := deadbands[serverHandles[0]]
+			if !ok {
+				return []float32{0}, []int32{int32(OPCInvalidHandle)}, nil
+			}
+			return []float32{value}, []int32{0}, nil
+		},
+		ClearItemDeadbandFn: func(serverHandles []uint32) ([]int32, error) {
+			delete(deadbands, serverHandles[0])
+			return []int32{0}, nil
+		},
+	}}
+	item := &OPCItem{parent: &OPCItems{parent: group}, provider: &mockServerProvider{}, serverHandle: 3}
+
+	assert.NoError(t, item.SetDeadband(2))
+	deadband, err := item.GetDeadband()
+	assert.NoError(t, err)
+	assert.Equal(t, float32(2), deadband)
+	assert.Error(t, item.SetDeadband(101))
+
+	assert.NoError(t, item.ClearDeadband())
+	_, err = item.GetDeadband()
+	var opcErr *OPCError
+	assert.ErrorAs(t, err, &opcErr)
+
+	group.deadbandMgt = nil
+	assert.ErrorIs(t, item.SetDeadband(2), ErrItemDeadbandNotSupported)
+	_, err = item.GetDeadband()
+	assert.ErrorIs(t, err, ErrItemDeadbandNotSupported)
+	assert.ErrorIs(t, item.ClearDeadband(), ErrItemDeadbandNotSupported)
+
+	var nilItem *OPCItem
+	assert.Error(t, nilItem.SetDeadband(1))
+}
@@ -0,0 +1,155 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/wends155/opcda/com"
+)
+
+// ItemInfo describes an item as reported by its standard properties.
+// A property the server does not report keeps its zero value and has its error in Errors.
+type ItemInfo struct {
+	// CanonicalDataType is the native data type of the item (property 1).
+	CanonicalDataType com.VT
+	// AccessRights is a combination of OPC_READABLE and OPC_WRITEABLE (property 5).
+	AccessRights uint32
+	// EUUnits is the engineering units of the item (property 100).
+	EUUnits string
+	// Description is the description of the item (property 101).
+	Description string
+	// HighEU and LowEU bound the normal operating range of the item (properties 102 and 103).
+	HighEU, LowEU float64
+	// HighIR and LowIR bound the instrument range of the item (properties 104 and 105).
+	HighIR, LowIR float64
+	// Errors holds the error of every property that could not be read or converted.
+	Errors map[PropertyID]error
+}
+
+// inspectProperties are the properties read by OPCItem.Inspect, in request order.
+var inspectProperties = []PropertyID{
+	OPC_PROPERTY_DATATYPE,
+	OPC_PROPERTY_ACCESS_RIGHTS,
+	OPC_PROPERTY_EU_UNITS,
+	OPC_PROPERTY_DESCRIPTION,
+	OPC_PROPERTY_HIGH_EU,
+	OPC_PROPERTY_LOW_EU,
+	OPC_PROPERTY_HIGH_IR,
+	OPC_PROPERTY_LOW_IR,
+}
+
+// Inspect reads the canonical data type, access rights, engineering units, description and the EU and
+// instrument ranges of the item with a single GetItemProperties call. Properties the server does not
+// support are reported in ItemInfo.Errors; the returned error is only set when the call itself fails.
+//
+// Example:
+//
+//	info, err := item.Inspect()
+//	fmt.Printf("%s [%s] %g..%g\n", info.Description, info.EUUnits, info.LowEU, info.HighEU)
+func (i *OPCItem) Inspect() (ItemInfo, error) {
+	if i == nil || i.provider == nil {
+		return ItemInfo{}, errors.New("uninitialized item")
+	}
+	return readItemInfo(i.provider, i.tag, i.getError)
+}
+
+// readItemInfo reads the standard properties of an item with a single GetItemProperties call,
+// converting failed property codes with getError.
+func readItemInfo(provider serverProvider, itemID string, getError func(int32) error) (ItemInfo, error) {
+	ids := make([]uint32, len(inspectProperties))
+	for j, id := range inspectProperties {
+		ids[j] = uint32(id)
+	}
+	data, errs, err := provider.GetItemProperties(itemID, ids)
+	if err != nil {
+		return ItemInfo{}, err
+	}
+	info := ItemInfo{Errors: make(map[PropertyID]error)}
+	for j, id := range inspectProperties {
+		if j >= len(data) || j >= len(errs) {
+			info.Errors[id] = errors.New("property not returned")
+			continue
+		}
+		if errs[j] < 0 {
+			info.Errors[id] = getError(errs[j])
+			continue
+		}
+		if err := info.set(id, data[j]); err != nil {
+			info.Errors[id] = err
+		}
+	}
+	return info, nil
+}
+
+// set stores the value of a standard property in the matching field.
+func (info *ItemInfo) set(id PropertyID, value interface{}) error {
+	if unsupported, ok := value.(*com.ErrUnsupportedVariant); ok {
+		return unsupported
+	}
+	var ok bool
+	switch id {
+	case OPC_PROPERTY_DATATYPE:
+		var v int64
+		v, ok = propertyInt(value)
+		info.CanonicalDataType = com.VT(v)
+	case OPC_PROPERTY_ACCESS_RIGHTS:
+		var v int64
+		v, ok = propertyInt(value)
+		info.AccessRights = uint32(v)
+	case OPC_PROPERTY_EU_UNITS:
+		info.EUUnits, ok = value.(string)
+	case OPC_PROPERTY_DESCRIPTION:
+		info.Description, ok = value.(string)
+	case OPC_PROPERTY_HIGH_EU:
+		info.HighEU, ok = propertyFloat(value)
+	case OPC_PROPERTY_LOW_EU:
+		info.LowEU, ok = propertyFloat(value)
+	case OPC_PROPERTY_HIGH_IR:
+		info.HighIR, ok = propertyFloat(value)
+	case OPC_PROPERTY_LOW_IR:
+		info.LowIR, ok = propertyFloat(value)
+	}
+	if !ok {
+		return fmt.Errorf("unexpected type %T of property %d", value, id)
+	}
+	return nil
+}
+
+// propertyInt converts an integer property value of any width.
+func propertyInt(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		return int64(v), true
+	}
+	return 0, false
+}
+
+// propertyFloat converts a numeric property value; servers report ranges as VT_R8, VT_R4, VT_CY or integers.
+func propertyFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case com.Currency:
+		return v.Float64(), true
+	}
+	i, ok := propertyInt(value)
+	return float64(i), ok
+}
@@ -0,0 +1,62 @@
+//go:build windows
+
+package opcda
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wends155/opcda/com"
+)
+
+func TestOPCItem_Inspect_Mocked(t *testing.T) {
+	calls := 0
+	item := &OPCItem{
+		tag: "Random.Real8",
+		provider: &mockServerProvider{
+			GetItemPropertiesFn: func(itemID string, propertyIDs []uint32) ([]interface{}, []int32, error) {
+				calls++
+				assert.Equal(t, "Random.Real8", itemID)
+				assert.Equal(t, []uint32{1, 5, 100, 101, 102, 103, 104, 105}, propertyIDs)
+				return []interface{}{int16(com.VT_R8), int32(3), "DEGC", "Boiler temperature", float64(120), float32(-20), int32(150), nil},
+					[]int32{0, 0, 0, 0, 0, 0, 0, int32(OPCInvalidPID)}, nil
+			},
+		},
+	}
+	info, err := item.Inspect()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, com.VT_R8, info.CanonicalDataType)
+	assert.Equal(t, OPC_READABLE|OPC_WRITEABLE, info.AccessRights)
+	assert.Equal(t, "DEGC", info.EUUnits)
+	assert.Equal(t, "Boiler temperature", info.Description)
+	assert.Equal(t, float64(120), info.HighEU)
+	assert.Equal(t, float64(-20), info.LowEU)
+	assert.Equal(t, float64(150), info.HighIR)
+	assert.Zero(t, info.LowIR)
+	assert.Len(t, info.Errors, 1)
+	assert.Error(t, info.Errors[OPC_PROPERTY_LOW_IR])
+
+	var nilItem *OPCItem
+	_, err = nilItem.Inspect()
+	assert.Error(t, err)
+}
+
+func TestOPCItem_Inspect_UnexpectedTypes_Mocked(t *testing.T) {
+	item := &OPCItem{
+		provider: &mockServerProvider{
+			GetItemPropertiesFn: func(itemID string, propertyIDs []uint32) ([]interface{}, []int32, error) {
+				data := make([]interface{}, len(propertyIDs))
+				data[2] = int32(7)
+				data[4] = &com.ErrUnsupportedVariant{VT: com.VT_CY}
+				return data, make([]int32, len(propertyIDs)), nil
+			},
+		},
+	}
+	info, err := item.Inspect()
+	assert.NoError(t, err)
+	assert.ErrorContains(t, info.Errors[OPC_PROPERTY_EU_UNITS], "unexpected type int32")
+	var unsupported *com.ErrUnsupportedVariant
+	assert.ErrorAs(t, info.Errors[OPC_PROPERTY_HIGH_EU], &unsupported)
+	assert.Len(t, info.Errors, 8)
+}
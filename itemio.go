@@ -0,0 +1,315 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+	"unsafe"
+
+	"github.com/wends155/opcda/com"
+)
+
+// ErrItemIONotSupported is returned by ReadItems when the server does not implement the OPC DA 3.0
+// IOPCItemIO interface. Such servers can only be read through a group.
+var ErrItemIONotSupported = errors.New("server does not support IOPCItemIO")
+
+// itemIOProvider defines the internal contract for connectionless item access.
+// It abstracts the optional IOPCItemIO server interface to allow for mocking and testing.
+type itemIOProvider interface {
+	// Read reads the items with the given maximum ages in milliseconds.
+	Read(itemIDs []string, maxAge []uint32) ([]*com.ItemState, []int32, error)
+	// WriteVQT writes values, qualities and timestamps to the items.
+	WriteVQT(itemIDs []string, values []com.TagOPCITEMVQT) ([]int32, error)
+	// Release releases the COM resources associated with the provider.
+	Release()
+}
+
+// comItemIOProvider is the concrete implementation of itemIOProvider using COM.
+type comItemIOProvider struct {
+	itemIO *com.IOPCItemIO
+}
+
+// Read reads the items with the given maximum ages in milliseconds.
+func (p *comItemIOProvider) Read(itemIDs []string, maxAge []uint32) ([]*com.ItemState, []int32, error) {
+	return p.itemIO.Read(itemIDs, maxAge)
+}
+
+// WriteVQT writes values, qualities and timestamps to the items.
+func (p *comItemIOProvider) WriteVQT(itemIDs []string, values []com.TagOPCITEMVQT) ([]int32, error) {
+	return p.itemIO.WriteVQT(itemIDs, values)
+}
+
+// Release releases the COM resources associated with the provider.
+func (p *comItemIOProvider) Release() {
+	p.itemIO.Release()
+}
+
+// queryComItemIO queries the server for IOPCItemIO and applies authInfo to the new proxy.
+func queryComItemIO(provider serverProvider, authInfo *com.COAUTHINFO) (itemIOProvider, error) {
+	var iUnknown *com.IUnknown
+	err := provider.QueryInterface(&com.IID_IOPCItemIO, unsafe.Pointer(&iUnknown))
+	if err != nil {
+		return nil, err
+	}
+	err = setProxyBlanket(iUnknown, authInfo)
+	if err != nil {
+		iUnknown.Release()
+		return nil, NewOPCWrapperError("set proxy blanket IOPCItemIO", err)
+	}
+	return &comItemIOProvider{itemIO: &com.IOPCItemIO{IUnknown: iUnknown}}, nil
+}
+
+// ItemResult is the outcome of reading one item by item ID.
+type ItemResult struct {
+	// ItemID is the item that was read.
+	ItemID string
+	// Value, Quality and Timestamp are the value read; they are zero when Err is set.
+	Value     interface{}
+	Quality   uint16
+	Timestamp time.Time
+	// Err is the error the server reported for the item.
+	Err error
+}
+
+// itemIO returns the IOPCItemIO interface of the server, querying it on first use. The outcome of
+// the query is cached until the connection is closed or re-established.
+func (s *OPCServer) itemIO() (itemIOProvider, error) {
+	s.itemIOLock.Lock()
+	defer s.itemIOLock.Unlock()
+	if s.itemIOProvider == nil && s.itemIOErr == nil {
+		s.itemIOProvider, s.itemIOErr = s.pinnedItemIO()
+		if errors.Is(s.itemIOErr, com.HRESULT(com.E_NOINTERFACE)) {
+			s.itemIOErr = fmt.Errorf("%w: %w", ErrItemIONotSupported, s.itemIOErr)
+		}
+	}
+	return s.itemIOProvider, s.itemIOErr
+}
+
+// releaseItemIO releases the IOPCItemIO interface and forgets the outcome of the query.
+func (s *OPCServer) releaseItemIO() {
+	s.itemIOLock.Lock()
+	defer s.itemIOLock.Unlock()
+	if s.itemIOProvider != nil {
+		s.itemIOProvider.Release()
+	}
+	s.itemIOProvider, s.itemIOErr = nil, nil
+}
+
+// ReadItems reads items by item ID directly from the server, without creating a group, using the
+// OPC DA 3.0 IOPCItemIO interface. Every value is read from the device. It suits occasional reads;
+// items read repeatedly are cheaper to read through a group.
+// Servers that only implement OPC DA 2.0 return an error wrapping ErrItemIONotSupported.
+// An empty itemIDs returns nil results without calling the server.
+//
+// Example:
+//
+//	results, err := server.ReadItems([]string{"Random.Int4", "Random.Real8"})
+//	if errors.Is(err, opcda.ErrItemIONotSupported) {
+//		// fall back to a temporary group
+//	}
+func (s *OPCServer) ReadItems(itemIDs []string) ([]ItemResult, error) {
+	return s.ReadItemsFrom(itemIDs, OPC_DS_DEVICE)
+}
+
+// ReadItemsFrom reads items by item ID like ReadItems, from the given data source: OPC_DS_DEVICE reads
+// every value from the device, OPC_DS_CACHE accepts the value cached by the server, however old.
+// It lets a single call, such as a commissioning check, read the device while other reads use the cache.
+func (s *OPCServer) ReadItemsFrom(itemIDs []string, source com.OPCDATASOURCE) ([]ItemResult, error) {
+	if s == nil || s.provider == nil {
+		return nil, errors.New("uninitialized server connection")
+	}
+	if len(itemIDs) == 0 {
+		return nil, nil
+	}
+	itemIO, err := s.itemIO()
+	if err != nil {
+		return nil, err
+	}
+	states, errs, err := itemIO.Read(itemIDs, sourceMaxAge(len(itemIDs), source))
+	if err != nil {
+		return nil, err
+	}
+	itemErrors := s.errors(errs)
+	results := make([]ItemResult, len(itemIDs))
+	for i, itemID := range itemIDs {
+		results[i].ItemID = itemID
+		if i < len(itemErrors) && itemErrors[i] != nil {
+			results[i].Err = itemErrors[i]
+			continue
+		}
+		if i < len(states) && states[i] != nil {
+			results[i].Value, results[i].Err = splitUnsupported(states[i].Value)
+			results[i].Quality = states[i].Quality
+			results[i].Timestamp = states[i].Timestamp
+		}
+	}
+	return results, nil
+}
+
+// sourceMaxAge returns the IOPCItemIO max ages that read n items from source.
+func sourceMaxAge(n int, source com.OPCDATASOURCE) []uint32 {
+	maxAge := make([]uint32, n)
+	if source == OPC_DS_CACHE {
+		for i := range maxAge {
+			maxAge[i] = math.MaxUint32
+		}
+	}
+	return maxAge
+}
+
+// RawItemResult is the outcome of reading one item with ReadRaw, exactly as the server reported it.
+type RawItemResult struct {
+	// ItemID is the item that was read.
+	ItemID string
+	// Value and Timestamp are the value read.
+	Value     interface{}
+	Timestamp time.Time
+	// Quality is the complete 16-bit quality word, including the substatus, limit and vendor bits.
+	Quality uint16
+	// Code is the result code the server reported for the item, such as S_OK or OPC_E_UNKNOWNITEMID.
+	Code int32
+}
+
+// ReadRaw reads items by item ID like ReadItemsFrom, passing through what the server reported without
+// interpretation: the complete quality word, and the value, timestamp and result code of every item even
+// when the code is a failure. No error messages are looked up. It suits bridges that re-emit values to
+// another OPC server and must preserve the quality word bit for bit; write it back with WriteItems.
+func (s *OPCServer) ReadRaw(itemIDs []string, source com.OPCDATASOURCE) ([]RawItemResult, error) {
+	if s == nil || s.provider == nil {
+		return nil, errors.New("uninitialized server connection")
+	}
+	if len(itemIDs) == 0 {
+		return nil, nil
+	}
+	itemIO, err := s.itemIO()
+	if err != nil {
+		return nil, err
+	}
+	states, codes, err := itemIO.Read(itemIDs, sourceMaxAge(len(itemIDs), source))
+	if err != nil {
+		return nil, err
+	}
+	results := make([]RawItemResult, len(itemIDs))
+	for i, itemID := range itemIDs {
+		results[i].ItemID = itemID
+		if i < len(codes) {
+			results[i].Code = codes[i]
+		}
+		if i < len(states) && states[i] != nil {
+			// a value that cannot be converted is passed through as its raw VARIANT type
+			results[i].Value, _ = splitUnsupported(states[i].Value)
+			results[i].Quality = states[i].Quality
+			results[i].Timestamp = states[i].Timestamp
+		}
+	}
+	return results, nil
+}
+
+// WriteItems writes values to items by item ID directly from the server, without creating a group, using
+// the OPC DA 3.0 IOPCItemIO interface. A nil Quality or Timestamp leaves it to the server. It suits
+// occasional writes such as setpoint pushes; items written repeatedly are cheaper to write through a group.
+//
+// Servers that only implement OPC DA 2.0 are written through a temporary group that is removed afterwards.
+// Such servers cannot write quality or timestamp, so items with either set fail with ErrNotSupported.
+//
+// The returned map has an entry for every item, nil if the item was written. An empty values returns nil
+// without calling the server.
+//
+// Example:
+//
+//	errs, err := server.WriteItems(map[string]opcda.OPCVQT{"Setpoints.Flow": {Value: float32(12.5)}})
+func (s *OPCServer) WriteItems(values map[string]OPCVQT) (map[string]error, error) {
+	if s == nil || s.provider == nil {
+		return nil, errors.New("uninitialized server connection")
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+	itemIDs := make([]string, 0, len(values))
+	for itemID := range values {
+		itemIDs = append(itemIDs, itemID)
+	}
+	sort.Strings(itemIDs)
+	vqts := make([]OPCVQT, len(itemIDs))
+	for i, itemID := range itemIDs {
+		vqts[i] = values[itemID]
+	}
+	itemIO, err := s.itemIO()
+	if errors.Is(err, ErrItemIONotSupported) {
+		return s.writeItemsInGroup(itemIDs, vqts)
+	}
+	if err != nil {
+		return nil, err
+	}
+	itemVQTs, clearVariants, err := toItemVQTs(vqts)
+	if err != nil {
+		return nil, err
+	}
+	defer clearVariants()
+	errs, err := itemIO.WriteVQT(itemIDs, itemVQTs)
+	if err != nil {
+		return nil, err
+	}
+	itemErrors := s.errors(errs)
+	results := make(map[string]error, len(itemIDs))
+	for i, itemID := range itemIDs {
+		results[itemID] = nil
+		if i < len(itemErrors) {
+			results[itemID] = itemErrors[i]
+		}
+	}
+	return results, nil
+}
+
+// writeItemsInGroup writes values to items through a temporary group, for servers without IOPCItemIO.
+func (s *OPCServer) writeItemsInGroup(itemIDs []string, vqts []OPCVQT) (results map[string]error, err error) {
+	if s.groups == nil {
+		return nil, errors.New("uninitialized server connection")
+	}
+	group, err := s.addDetachedGroup()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = errors.Join(err, s.removeDetachedGroup(group))
+		if err != nil {
+			results = nil
+		}
+	}()
+	items, addErrs, err := group.items.AddItems(itemIDs)
+	if err != nil {
+		return nil, err
+	}
+	results = make(map[string]error, len(itemIDs))
+	var serverHandles []uint32
+	var writeIDs []string
+	var writeValues []interface{}
+	for i, itemID := range itemIDs {
+		switch {
+		case addErrs[i] != nil:
+			results[itemID] = addErrs[i]
+		case vqts[i].Quality != nil || vqts[i].Timestamp != nil:
+			// IOPCSyncIO only writes values
+			results[itemID] = ErrNotSupported
+		default:
+			results[itemID] = nil
+			serverHandles = append(serverHandles, items[i].serverHandle)
+			writeIDs = append(writeIDs, itemID)
+			writeValues = append(writeValues, vqts[i].Value)
+		}
+	}
+	writeErrs, err := group.SyncWrite(serverHandles, writeValues)
+	if err != nil {
+		return nil, err
+	}
+	for i, itemID := range writeIDs {
+		if i < len(writeErrs) {
+			results[itemID] = writeErrs[i]
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,205 @@
+//go:build windows
+
+package opcda
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wends155/opcda/com"
+	"golang.org/x/sys/windows"
+)
+
+func TestOPCServer_ReadItems_Mocked(t *testing.T) {
+	now := time.Now()
+	queries, released := 0, 0
+	itemIO := &mockItemIOProvider{
+		ReadFn: func(itemIDs []string, maxAge []uint32) ([]*com.ItemState, []int32, error) {
+			assert.Equal(t, []uint32{0, 0}, maxAge)
+			return []*com.ItemState{{Value: int32(7), Quality: OPC_QUALITY_GOOD, Timestamp: now}, nil},
+				[]int32{0, int32(OPCUnknownItemID)}, nil
+		},
+		ReleaseFn: func() { released++ },
+	}
+	server := newOPCServerWithProvider(&mockServerProvider{}, "mock", "localhost")
+	server.factories.queryItemIO = func(serverProvider, *com.COAUTHINFO) (itemIOProvider, error) {
+		queries++
+		return itemIO, nil
+	}
+
+	results, err := server.ReadItems(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, results)
+	assert.Zero(t, queries)
+
+	for n := 0; n < 2; n++ {
+		results, err = server.ReadItems([]string{"a", "b"})
+		assert.NoError(t, err)
+		assert.Equal(t, ItemResult{ItemID: "a", Value: int32(7), Quality: OPC_QUALITY_GOOD, Timestamp: now}, results[0])
+		assert.Equal(t, "b", results[1].ItemID)
+		assert.Error(t, results[1].Err)
+	}
+	assert.Equal(t, 1, queries)
+
+	assert.NoError(t, server.Disconnect())
+	assert.Equal(t, 1, released)
+}
+
+func TestOPCServer_ReadItems_NotSupported_Mocked(t *testing.T) {
+	queries := 0
+	server := newOPCServerWithProvider(&mockServerProvider{}, "mock", "localhost")
+	server.factories.queryItemIO = func(serverProvider, *com.COAUTHINFO) (itemIOProvider, error) {
+		queries++
+		return nil, com.HRESULT(com.E_NOINTERFACE)
+	}
+
+	for n := 0; n < 2; n++ {
+		_, err := server.ReadItems([]string{"a"})
+		assert.ErrorIs(t, err, ErrItemIONotSupported)
+		assert.ErrorIs(t, err, com.HRESULT(com.E_NOINTERFACE))
+	}
+	assert.Equal(t, 1, queries)
+
+	var nilServer *OPCServer
+	_, err := nilServer.ReadItems([]string{"a"})
+	assert.Error(t, err)
+}
+
+func TestOPCServer_ReadRaw_QualityRoundTrip_Mocked(t *testing.T) {
+	now := time.Now()
+	// uncertain, last usable value, high limit, with vendor bits set in the high byte
+	const quality = uint16(0xA5<<8) | OPC_QUALITY_UNCERTAIN | 0x04<<2 | 0x02
+	var written []com.TagOPCITEMVQT
+	server := newOPCServerWithProvider(&mockServerProvider{
+		GetErrorStringFn: func(errorCode uint32) (string, error) {
+			t.Error("ReadRaw must not look up error messages")
+			return "", nil
+		},
+	}, "mock", "localhost")
+	server.factories.queryItemIO = func(serverProvider, *com.COAUTHINFO) (itemIOProvider, error) {
+		return &mockItemIOProvider{
+			ReadFn: func(itemIDs []string, maxAge []uint32) ([]*com.ItemState, []int32, error) {
+				assert.Equal(t, []uint32{math.MaxUint32, math.MaxUint32}, maxAge)
+				return []*com.ItemState{{Value: int32(7), Quality: quality, Timestamp: now}, {Quality: OPC_QUALITY_BAD}},
+					[]int32{0, int32(OPCUnknownItemID)}, nil
+			},
+			WriteVQTFn: func(itemIDs []string, values []com.TagOPCITEMVQT) ([]int32, error) {
+				written = values
+				return make([]int32, len(itemIDs)), nil
+			},
+		}, nil
+	}
+
+	results, err := server.ReadRaw([]string{"a", "b"}, OPC_DS_CACHE)
+	assert.NoError(t, err)
+	assert.Equal(t, []RawItemResult{
+		{ItemID: "a", Value: int32(7), Quality: quality, Timestamp: now},
+		{ItemID: "b", Quality: OPC_QUALITY_BAD, Code: int32(OPCUnknownItemID)},
+	}, results)
+
+	q := results[0].Quality
+	_, err = server.WriteItems(map[string]OPCVQT{"a": {Value: results[0].Value, Quality: &q}})
+	assert.NoError(t, err)
+	if assert.Len(t, written, 1) {
+		assert.Equal(t, quality, written[0].WQuality)
+	}
+
+	results, err = server.ReadRaw(nil, OPC_DS_DEVICE)
+	assert.NoError(t, err)
+	assert.Nil(t, results)
+}
+
+func TestOPCServer_WriteItems_Mocked(t *testing.T) {
+	quality := OPC_QUALITY_UNCERTAIN
+	server := newOPCServerWithProvider(&mockServerProvider{}, "mock", "localhost")
+	server.factories.queryItemIO = func(serverProvider, *com.COAUTHINFO) (itemIOProvider, error) {
+		return &mockItemIOProvider{
+			WriteVQTFn: func(itemIDs []string, values []com.TagOPCITEMVQT) ([]int32, error) {
+				assert.Equal(t, []string{"a", "b"}, itemIDs)
+				assert.Equal(t, com.BoolToComBOOL(false), values[0].BQualitySpecified)
+				assert.Equal(t, com.BoolToComBOOL(true), values[1].BQualitySpecified)
+				assert.Equal(t, quality, values[1].WQuality)
+				return []int32{0, int32(OPCBadRights)}, nil
+			},
+		}, nil
+	}
+
+	errs, err := server.WriteItems(map[string]OPCVQT{"b": {Value: int32(2), Quality: &quality}, "a": {Value: int32(1)}})
+	assert.NoError(t, err)
+	assert.Len(t, errs, 2)
+	assert.NoError(t, errs["a"])
+	assert.ErrorIs(t, errs["b"], ErrBadRights)
+
+	errs, err = server.WriteItems(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, errs)
+}
+
+func TestOPCServer_WriteItems_TemporaryGroup_Mocked(t *testing.T) {
+	added, removed := 0, 0
+	server := newOPCServerWithProvider(&mockServerProvider{
+		AddGroupFn: func(name string, active bool, updateRate uint32, clientGroup uint32, timeBias *int32, deadband *float32, localeID uint32, iid *windows.GUID) (uint32, uint32, *com.IUnknown, error) {
+			added++
+			return 9, updateRate, nil, nil
+		},
+		RemoveGroupFn: func(serverGroup uint32, force bool) error {
+			removed++
+			assert.Equal(t, uint32(9), serverGroup)
+			return nil
+		},
+	}, "mock", "localhost")
+	server.factories.queryItemIO = func(serverProvider, *com.COAUTHINFO) (itemIOProvider, error) {
+		return nil, com.HRESULT(com.E_NOINTERFACE)
+	}
+	server.factories.newGroup = func(gs *OPCGroups, iUnknown *com.IUnknown, clientGroupHandle uint32, serverGroupHandle uint32, groupName string, revisedUpdateRate uint32) (*OPCGroup, error) {
+		g := &OPCGroup{parent: gs, provider: gs.provider, serverGroupHandle: serverGroupHandle}
+		g.groupProvider = &mockGroupProvider{
+			SyncWriteFn: func(serverHandles []uint32, values []com.VARIANT) ([]int32, error) {
+				assert.Equal(t, []uint32{101}, serverHandles)
+				return []int32{0}, nil
+			},
+		}
+		g.items = NewOPCItems(g, &mockItemMgtProvider{
+			AddItemsFn: func(items []com.TagOPCITEMDEF) ([]com.TagOPCITEMRESULTStruct, []int32, error) {
+				return []com.TagOPCITEMRESULTStruct{{Server: 101}, {Server: 102}, {}},
+					[]int32{0, 0, int32(OPCUnknownItemID)}, nil
+			},
+		}, gs.provider)
+		return g, nil
+	}
+	now := time.Now()
+
+	errs, err := server.WriteItems(map[string]OPCVQT{
+		"a":       {Value: int32(1)},
+		"b":       {Value: int32(2), Timestamp: &now},
+		"missing": {Value: int32(3)},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, errs["a"])
+	assert.ErrorIs(t, errs["b"], ErrNotSupported)
+	assert.ErrorIs(t, errs["missing"], ErrUnknownItemID)
+	assert.Equal(t, 1, added)
+	assert.Equal(t, 1, removed)
+	assert.Zero(t, server.groups.GetCount())
+}
+
+func TestOPCServer_ReadItemsFrom_Mocked(t *testing.T) {
+	var maxAges [][]uint32
+	server := newOPCServerWithProvider(&mockServerProvider{}, "mock", "localhost")
+	server.factories.queryItemIO = func(serverProvider, *com.COAUTHINFO) (itemIOProvider, error) {
+		return &mockItemIOProvider{
+			ReadFn: func(itemIDs []string, maxAge []uint32) ([]*com.ItemState, []int32, error) {
+				maxAges = append(maxAges, maxAge)
+				return make([]*com.ItemState, len(itemIDs)), make([]int32, len(itemIDs)), nil
+			},
+		}, nil
+	}
+
+	_, err := server.ReadItemsFrom([]string{"a"}, OPC_DS_CACHE)
+	assert.NoError(t, err)
+	_, err = server.ReadItemsFrom([]string{"a"}, OPC_DS_DEVICE)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]uint32{{0xFFFFFFFF}, {0}}, maxAges)
+}
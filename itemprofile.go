@@ -0,0 +1,271 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+
+	"github.com/wends155/opcda/com"
+)
+
+// itemSamplingMgtProvider defines the internal contract for per-item sampling rates and buffering.
+// It abstracts the optional IOPCItemSamplingMgt group interface to allow for mocking and testing.
+type itemSamplingMgtProvider interface {
+	// SetItemSamplingRate sets the sampling rate of the specified items and returns the revised rates.
+	SetItemSamplingRate(serverHandles []uint32, samplingRates []uint32) ([]uint32, []int32, error)
+	// GetItemSamplingRate retrieves the sampling rate of the specified items.
+	GetItemSamplingRate(serverHandles []uint32) ([]uint32, []int32, error)
+	// ClearItemSamplingRate makes the specified items use the group update rate again.
+	ClearItemSamplingRate(serverHandles []uint32) ([]int32, error)
+	// SetItemBufferEnable enables or disables buffering for the specified items.
+	SetItemBufferEnable(serverHandles []uint32, enable []bool) ([]int32, error)
+	// GetItemBufferEnable retrieves the buffering state of the specified items.
+	GetItemBufferEnable(serverHandles []uint32) ([]bool, []int32, error)
+	// Release releases the COM resources associated with the provider.
+	Release()
+}
+
+// comItemSamplingMgtProvider is the concrete implementation of itemSamplingMgtProvider using COM.
+type comItemSamplingMgtProvider struct {
+	samplingMgt *com.IOPCItemSamplingMgt
+}
+
+// SetItemSamplingRate sets the sampling rate of the specified items and returns the revised rates.
+func (p *comItemSamplingMgtProvider) SetItemSamplingRate(serverHandles []uint32, samplingRates []uint32) ([]uint32, []int32, error) {
+	return p.samplingMgt.SetItemSamplingRate(serverHandles, samplingRates)
+}
+
+// GetItemSamplingRate retrieves the sampling rate of the specified items.
+func (p *comItemSamplingMgtProvider) GetItemSamplingRate(serverHandles []uint32) ([]uint32, []int32, error) {
+	return p.samplingMgt.GetItemSamplingRate(serverHandles)
+}
+
+// ClearItemSamplingRate makes the specified items use the group update rate again.
+func (p *comItemSamplingMgtProvider) ClearItemSamplingRate(serverHandles []uint32) ([]int32, error) {
+	return p.samplingMgt.ClearItemSamplingRate(serverHandles)
+}
+
+// SetItemBufferEnable enables or disables buffering for the specified items.
+func (p *comItemSamplingMgtProvider) SetItemBufferEnable(serverHandles []uint32, enable []bool) ([]int32, error) {
+	return p.samplingMgt.SetItemBufferEnable(serverHandles, enable)
+}
+
+// GetItemBufferEnable retrieves the buffering state of the specified items.
+func (p *comItemSamplingMgtProvider) GetItemBufferEnable(serverHandles []uint32) ([]bool, []int32, error) {
+	return p.samplingMgt.GetItemBufferEnable(serverHandles)
+}
+
+// Release releases the COM resources associated with the provider.
+func (p *comItemSamplingMgtProvider) Release() {
+	p.samplingMgt.Release()
+}
+
+// itemDeadbandMgtProvider defines the internal contract for per-item deadbands.
+// It abstracts the optional IOPCItemDeadbandMgt group interface to allow for mocking and testing.
+type itemDeadbandMgtProvider interface {
+	// SetItemDeadband sets the percent deadband of the specified items.
+	SetItemDeadband(serverHandles []uint32, deadbands []float32) ([]int32, error)
+	// GetItemDeadband retrieves the percent deadband of the specified items.
+	GetItemDeadband(serverHandles []uint32) ([]float32, []int32, error)
+	// ClearItemDeadband makes the specified items use the group deadband again.
+	ClearItemDeadband(serverHandles []uint32) ([]int32, error)
+	// Release releases the COM resources associated with the provider.
+	Release()
+}
+
+// comItemDeadbandMgtProvider is the concrete implementation of itemDeadbandMgtProvider using COM.
+type comItemDeadbandMgtProvider struct {
+	deadbandMgt *com.IOPCItemDeadbandMgt
+}
+
+// SetItemDeadband sets the percent deadband of the specified items.
+func (p *comItemDeadbandMgtProvider) SetItemDeadband(serverHandles []uint32, deadbands []float32) ([]int32, error) {
+	return p.deadbandMgt.SetItemDeadband(serverHandles, deadbands)
+}
+
+// GetItemDeadband retrieves the percent deadband of the specified items.
+func (p *comItemDeadbandMgtProvider) GetItemDeadband(serverHandles []uint32) ([]float32, []int32, error) {
+	return p.deadbandMgt.GetItemDeadband(serverHandles)
+}
+
+// ClearItemDeadband makes the specified items use the group deadband again.
+func (p *comItemDeadbandMgtProvider) ClearItemDeadband(serverHandles []uint32) ([]int32, error) {
+	return p.deadbandMgt.ClearItemDeadband(serverHandles)
+}
+
+// Release releases the COM resources associated with the provider.
+func (p *comItemDeadbandMgtProvider) Release() {
+	p.deadbandMgt.Release()
+}
+
+// ItemProfile bundles the per-item update settings that are spread over several OPC interfaces.
+// Nil fields are left unchanged.
+type ItemProfile struct {
+	// SamplingRate is the requested sampling rate in milliseconds (IOPCItemSamplingMgt).
+	SamplingRate *uint32
+	// Deadband is the requested percent deadband (IOPCItemDeadbandMgt).
+	Deadband *float32
+	// BufferEnabled requests buffering of samples between updates (IOPCItemSamplingMgt).
+	BufferEnabled *bool
+	// Active is the requested active state (IOPCItemMgt).
+	Active *bool
+}
+
+// ProfilePart identifies one setting of an ItemProfile.
+type ProfilePart string
+
+const (
+	// ProfileSamplingRate identifies ItemProfile.SamplingRate.
+	ProfileSamplingRate ProfilePart = "SamplingRate"
+	// ProfileDeadband identifies ItemProfile.Deadband.
+	ProfileDeadband ProfilePart = "Deadband"
+	// ProfileBufferEnabled identifies ItemProfile.BufferEnabled.
+	ProfileBufferEnabled ProfilePart = "BufferEnabled"
+	// ProfileActive identifies ItemProfile.Active.
+	ProfileActive ProfilePart = "Active"
+)
+
+// ProfileResult reports how the server handled an ItemProfile for one item.
+type ProfileResult struct {
+	// RevisedSamplingRate is the sampling rate the server will use, if a sampling rate was accepted.
+	RevisedSamplingRate uint32
+	// Unsupported lists the requested parts the server does not implement.
+	Unsupported []ProfilePart
+	// Errors holds the error reported by the server for each rejected part.
+	Errors map[ProfilePart]error
+}
+
+// Err returns the first error reported for the profile, or nil if every supported part was accepted.
+func (r *ProfileResult) Err() error {
+	if r == nil {
+		return nil
+	}
+	for _, part := range []ProfilePart{ProfileSamplingRate, ProfileBufferEnabled, ProfileDeadband, ProfileActive} {
+		if err := r.Errors[part]; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setError records the error for a part of the profile.
+func (r *ProfileResult) setError(part ProfilePart, err error) {
+	if r.Errors == nil {
+		r.Errors = make(map[ProfilePart]error)
+	}
+	r.Errors[part] = err
+}
+
+// isNotImplemented reports whether a call-level error means the server does not implement the method.
+func isNotImplemented(err error) bool {
+	return errors.Is(err, com.HRESULT(com.E_NOTIMPL))
+}
+
+// ApplyProfile applies the same ItemProfile to the items identified by serverHandles.
+// Each setting is applied with a single batched call to the interface that owns it: sampling rate and
+// buffering through IOPCItemSamplingMgt, deadband through IOPCItemDeadbandMgt and the active state
+// through IOPCItemMgt. Activation is applied last so items start reporting with the new settings.
+// Parts the server does not implement are listed in ProfileResult.Unsupported instead of failing the call.
+func (g *OPCGroup) ApplyProfile(serverHandles []uint32, p ItemProfile) ([]*ProfileResult, error) {
+	if g == nil || g.items == nil || g.items.itemMgtProvider == nil {
+		return nil, errors.New("uninitialized group")
+	}
+	results := make([]*ProfileResult, len(serverHandles))
+	for i := range results {
+		results[i] = &ProfileResult{}
+	}
+	if len(serverHandles) == 0 {
+		return results, nil
+	}
+	// apply records the outcome of one batched call for every item.
+	apply := func(part ProfilePart, errs []int32, err error) {
+		for i, r := range results {
+			switch {
+			case isNotImplemented(err):
+				r.Unsupported = append(r.Unsupported, part)
+			case err != nil:
+				r.setError(part, err)
+			case errs[i] < 0:
+				r.setError(part, g.getError(errs[i]))
+			}
+		}
+	}
+	unsupported := func(part ProfilePart) {
+		for _, r := range results {
+			r.Unsupported = append(r.Unsupported, part)
+		}
+	}
+	if p.SamplingRate != nil {
+		if g.samplingMgt == nil {
+			unsupported(ProfileSamplingRate)
+		} else {
+			rates := make([]uint32, len(serverHandles))
+			for i := range rates {
+				rates[i] = *p.SamplingRate
+			}
+			revised, errs, err := g.samplingMgt.SetItemSamplingRate(serverHandles, rates)
+			apply(ProfileSamplingRate, errs, err)
+			if err == nil {
+				for i, r := range results {
+					if errs[i] >= 0 {
+						r.RevisedSamplingRate = revised[i]
+					}
+				}
+			}
+		}
+	}
+	if p.BufferEnabled != nil {
+		if g.samplingMgt == nil {
+			unsupported(ProfileBufferEnabled)
+		} else {
+			enable := make([]bool, len(serverHandles))
+			for i := range enable {
+				enable[i] = *p.BufferEnabled
+			}
+			errs, err := g.samplingMgt.SetItemBufferEnable(serverHandles, enable)
+			apply(ProfileBufferEnabled, errs, err)
+		}
+	}
+	if p.Deadband != nil {
+		if g.deadbandMgt == nil {
+			unsupported(ProfileDeadband)
+		} else {
+			deadbands := make([]float32, len(serverHandles))
+			for i := range deadbands {
+				deadbands[i] = *p.Deadband
+			}
+			errs, err := g.deadbandMgt.SetItemDeadband(serverHandles, deadbands)
+			apply(ProfileDeadband, errs, err)
+		}
+	}
+	if p.Active != nil {
+		errs, err := g.items.itemMgtProvider.SetActiveState(serverHandles, *p.Active)
+		apply(ProfileActive, errs, err)
+		if err == nil {
+			for i, handle := range serverHandles {
+				if errs[i] < 0 {
+					continue
+				}
+				if item, err := g.items.GetOPCItem(handle); err == nil {
+					item.Lock()
+					item.isActive = *p.Active
+					item.Unlock()
+				}
+			}
+		}
+	}
+	return results, nil
+}
+
+// ApplyProfile applies an ItemProfile to the item through its parent group.
+// See OPCGroup.ApplyProfile for how each part is applied and reported.
+func (i *OPCItem) ApplyProfile(p ItemProfile) (*ProfileResult, error) {
+	if i == nil || i.parent == nil || i.parent.parent == nil {
+		return nil, errors.New("uninitialized item")
+	}
+	results, err := i.parent.parent.ApplyProfile([]uint32{i.serverHandle}, p)
+	if err != nil {
+		return nil, err
+	}
+	return results[0], nil
+}
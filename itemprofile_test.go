@@ -0,0 +1,65 @@
+//go:build windows
+
+package opcda
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wends155/opcda/com"
+)
+
+func TestOPCGroup_ApplyProfile_Mocked(t *testing.T) {
+	samplingCalls := 0
+	sampling := &mockItemSamplingMgtProvider{
+		SetItemSamplingRateFn: func(serverHandles []uint32, samplingRates []uint32) ([]uint32, []int32, error) {
+			samplingCalls++
+			assert.Equal(t, []uint32{1, 2}, serverHandles)
+			assert.Equal(t, []uint32{100, 100}, samplingRates)
+			return []uint32{250, 0}, []int32{0, int32(OPCInvalidHandle)}, nil
+		},
+		SetItemBufferEnableFn: func(serverHandles []uint32, enable []bool) ([]int32, error) {
+			return nil, com.HRESULT(com.E_NOTIMPL)
+		},
+	}
+	var activeHandles []uint32
+	itemMgt := &mockItemMgtProvider{
+		SetActiveStateFn: func(serverHandles []uint32, bActive bool) ([]int32, error) {
+			activeHandles = serverHandles
+			return []int32{0, 0}, nil
+		},
+	}
+	group := &OPCGroup{
+		provider:    &mockServerProvider{},
+		samplingMgt: sampling,
+	}
+	group.items = NewOPCItems(group, itemMgt, group.provider)
+	item := &OPCItem{parent: group.items, serverHandle: 1}
+	group.items.items = []*OPCItem{item, {parent: group.items, serverHandle: 2}}
+
+	rate := uint32(100)
+	deadband := float32(1.5)
+	buffer := true
+	active := true
+	results, err := group.ApplyProfile([]uint32{1, 2}, ItemProfile{
+		SamplingRate:  &rate,
+		Deadband:      &deadband,
+		BufferEnabled: &buffer,
+		Active:        &active,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, 1, samplingCalls)
+	assert.Equal(t, []uint32{1, 2}, activeHandles)
+
+	assert.Equal(t, uint32(250), results[0].RevisedSamplingRate)
+	assert.NoError(t, results[0].Err())
+	assert.ElementsMatch(t, []ProfilePart{ProfileBufferEnabled, ProfileDeadband}, results[0].Unsupported)
+	assert.Error(t, results[1].Err())
+	assert.Error(t, results[1].Errors[ProfileSamplingRate])
+	assert.True(t, item.GetIsActive())
+
+	result, err := item.ApplyProfile(ItemProfile{Deadband: &deadband})
+	assert.NoError(t, err)
+	assert.Equal(t, []ProfilePart{ProfileDeadband}, result.Unsupported)
+}
@@ -0,0 +1,117 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+)
+
+// ErrItemSamplingNotSupported is returned by the per-item sampling methods when the group of the item
+// does not expose IOPCItemSamplingMgt, an optional OPC DA 3.0 interface.
+var ErrItemSamplingNotSupported = errors.New("server does not support IOPCItemSamplingMgt")
+
+// SetSamplingRate asks the server to sample the item every ms milliseconds, independently of the update
+// rate of its group, and returns the rate the server will actually use. Servers revise rates they cannot
+// honor to the closest supported one, so compare the returned rate with the requested one when it matters.
+// Sampling faster than the group update rate is mostly useful together with SetBufferEnabled.
+//
+// Example:
+//
+//	revised, err := item.SetSamplingRate(100)
+//	if err == nil && revised != 100 {
+//		log.Printf("server samples %s every %d ms", item.GetItemID(), revised)
+//	}
+func (i *OPCItem) SetSamplingRate(ms uint32) (uint32, error) {
+	g, err := i.samplingGroup()
+	if err != nil {
+		return 0, err
+	}
+	revised, errs, err := g.samplingMgt.SetItemSamplingRate([]uint32{i.GetServerHandle()}, []uint32{ms})
+	if err != nil {
+		return 0, err
+	}
+	if errs[0] < 0 {
+		return 0, i.getError(errs[0])
+	}
+	return revised[0], nil
+}
+
+// GetSamplingRate returns the sampling rate set on the item with SetSamplingRate, as revised by the server.
+// Servers report an error (OPC_E_RATENOTSET) when the item uses the update rate of its group.
+func (i *OPCItem) GetSamplingRate() (uint32, error) {
+	g, err := i.samplingGroup()
+	if err != nil {
+		return 0, err
+	}
+	rates, errs, err := g.samplingMgt.GetItemSamplingRate([]uint32{i.GetServerHandle()})
+	if err != nil {
+		return 0, err
+	}
+	if errs[0] < 0 {
+		return 0, i.getError(errs[0])
+	}
+	return rates[0], nil
+}
+
+// ClearSamplingRate removes the sampling rate set on the item, so it is sampled at the update rate of its
+// group again.
+func (i *OPCItem) ClearSamplingRate() error {
+	g, err := i.samplingGroup()
+	if err != nil {
+		return err
+	}
+	errs, err := g.samplingMgt.ClearItemSamplingRate([]uint32{i.GetServerHandle()})
+	if err != nil {
+		return err
+	}
+	if errs[0] < 0 {
+		return i.getError(errs[0])
+	}
+	return nil
+}
+
+// SetBufferEnabled enables or disables buffering of the item on the server. With buffering enabled, the
+// server keeps every sample taken between two updates of the group and reports all of them in the next
+// data change, instead of only the latest.
+func (i *OPCItem) SetBufferEnabled(enabled bool) error {
+	g, err := i.samplingGroup()
+	if err != nil {
+		return err
+	}
+	errs, err := g.samplingMgt.SetItemBufferEnable([]uint32{i.GetServerHandle()}, []bool{enabled})
+	if err != nil {
+		return err
+	}
+	if errs[0] < 0 {
+		return i.getError(errs[0])
+	}
+	return nil
+}
+
+// GetBufferEnabled reports whether buffering of the item is enabled on the server.
+func (i *OPCItem) GetBufferEnabled() (bool, error) {
+	g, err := i.samplingGroup()
+	if err != nil {
+		return false, err
+	}
+	enabled, errs, err := g.samplingMgt.GetItemBufferEnable([]uint32{i.GetServerHandle()})
+	if err != nil {
+		return false, err
+	}
+	if errs[0] < 0 {
+		return false, i.getError(errs[0])
+	}
+	return enabled[0], nil
+}
+
+// samplingGroup returns the group of the item if it supports per-item sampling rates.
+func (i *OPCItem) samplingGroup() (*OPCGroup, error) {
+	if i == nil || i.parent == nil || i.parent.parent == nil {
+		return nil, errors.New("uninitialized item")
+	}
+	g := i.parent.parent
+	if g.samplingMgt == nil {
+		return nil, ErrItemSamplingNotSupported
+	}
+	return g, nil
+}
@@ -0,0 +1,66 @@
+//go:build windows
+
+package opcda
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOPCItem_SamplingRate_Mocked(t *testing.T) {
+	rates := map[uint32]uint32{}
+	buffered := map[uint32]bool{}
+	group := &OPCGroup{samplingMgt: &mockItemSamplingMgtProvider{
+		SetItemSamplingRateFn: func(serverHandles []uint32, samplingRates []uint32) ([]uint32, []int32, error) {
+			// the server samples at most every 250 ms
+			revised := max(samplingRates[0], 250)
+			rates[serverHandles[0]] = revised
+			return []uint32{revised}, []int32{int32(OPCUnsupportedRate)}, nil
+		},
+		GetItemSamplingRateFn: func(serverHandles []uint32) ([]uint32, []int32, error) {
+			rate, ok := rates[serverHandles[0]]
+			if !ok {
+				return []uint32{0}, []int32{int32(OPCInvalidHandle)}, nil
+			}
+			return []uint32{rate}, []int32{0}, nil
+		},
+		ClearItemSamplingRateFn: func(serverHandles []uint32) ([]int32, error) {
+			delete(rates, serverHandles[0])
+			return []int32{0}, nil
+		},
+		SetItemBufferEnableFn: func(serverHandles []uint32, enable []bool) ([]int32, error) {
+			buffered[serverHandles[0]] = enable[0]
+			return []int32{0}, nil
+		},
+		GetItemBufferEnableFn: func(serverHandles []uint32) ([]bool, []int32, error) {
+			return []bool{buffered[serverHandles[0]]}, []int32{0}, nil
+		},
+	}}
+	item := &OPCItem{parent: &OPCItems{parent: group}, provider: &mockServerProvider{}, serverHandle: 3}
+
+	revised, err := item.SetSamplingRate(100)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(250), revised)
+	rate, err := item.GetSamplingRate()
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(250), rate)
+
+	assert.NoError(t, item.ClearSamplingRate())
+	_, err = item.GetSamplingRate()
+	assert.Error(t, err)
+
+	assert.NoError(t, item.SetBufferEnabled(true))
+	enabled, err := item.GetBufferEnabled()
+	assert.NoError(t, err)
+	assert.True(t, enabled)
+
+	group.samplingMgt = nil
+	_, err = item.SetSamplingRate(100)
+	assert.ErrorIs(t, err, ErrItemSamplingNotSupported)
+	assert.ErrorIs(t, item.SetBufferEnabled(true), ErrItemSamplingNotSupported)
+
+	var nilItem *OPCItem
+	_, err = nilItem.SetSamplingRate(1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,47 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrCallRejected is wrapped by the errors of OPCItem.Read and OPCItem.Write when the server answers
+// RPC_E_CALL_REJECTED. Some servers reject concurrent calls on the same item; enabling
+// OPCGroup.SetSerializeItemIO makes the items of the group issue one call at a time.
+var ErrCallRejected = errors.New("server rejected the call, possibly because of concurrent calls on the item (see OPCGroup.SetSerializeItemIO)")
+
+// SetSerializeItemIO controls whether OPCItem.Read and OPCItem.Write calls on the same item of the group
+// wait for each other. Calls on different items are never serialized. It is off by default, in which
+// case concurrent calls on one item reach the server together.
+func (g *OPCGroup) SetSerializeItemIO(enabled bool) error {
+	if g == nil {
+		return errors.New("uninitialized group")
+	}
+	g.serializeItemIO.Store(enabled)
+	return nil
+}
+
+// GetSerializeItemIO reports whether calls on the same item of the group are serialized.
+func (g *OPCGroup) GetSerializeItemIO() bool {
+	return g != nil && g.serializeItemIO.Load()
+}
+
+// beginIO takes the operation lock of the item when its group serializes item calls and returns the
+// function that releases it.
+func (i *OPCItem) beginIO() func() {
+	if i.parent == nil || !i.parent.parent.GetSerializeItemIO() {
+		return func() {}
+	}
+	i.ioLock.Lock()
+	return i.ioLock.Unlock
+}
+
+// translateCallRejected marks RPC_E_CALL_REJECTED errors with ErrCallRejected.
+func translateCallRejected(err error) error {
+	if errors.Is(err, rpcECallRejected) {
+		return fmt.Errorf("%w: %w", ErrCallRejected, err)
+	}
+	return err
+}
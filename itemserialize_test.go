@@ -0,0 +1,89 @@
+//go:build windows
+
+package opcda
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wends155/opcda/com"
+)
+
+// newRejectingItems returns two items of a group whose server rejects overlapping calls on the same item.
+func newRejectingItems() (*OPCGroup, []*OPCItem) {
+	var inflight [2]int32
+	enter := func(serverHandle uint32) bool {
+		defer atomic.AddInt32(&inflight[serverHandle], -1)
+		if atomic.AddInt32(&inflight[serverHandle], 1) > 1 {
+			return false
+		}
+		time.Sleep(100 * time.Microsecond)
+		return true
+	}
+	provider := &mockGroupProvider{
+		SyncReadFn: func(source com.OPCDATASOURCE, serverHandles []uint32) ([]*com.ItemState, []int32, error) {
+			if !enter(serverHandles[0]) {
+				return nil, nil, rpcECallRejected
+			}
+			return []*com.ItemState{{Value: int32(1)}}, []int32{0}, nil
+		},
+		SyncWriteFn: func(serverHandles []uint32, values []com.VARIANT) ([]int32, error) {
+			if !enter(serverHandles[0]) {
+				return nil, rpcECallRejected
+			}
+			return []int32{0}, nil
+		},
+	}
+	group := &OPCGroup{groupProvider: provider}
+	group.items = NewOPCItems(group, &mockItemMgtProvider{}, &mockServerProvider{})
+	items := make([]*OPCItem, 2)
+	for n := range items {
+		items[n] = &OPCItem{groupProvider: provider, serverHandle: uint32(n), parent: group.items}
+	}
+	return group, items
+}
+
+// hammer reads and writes the items from several goroutines and returns the number of rejected calls.
+func hammer(t *testing.T, items []*OPCItem) int32 {
+	var rejected int32
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(item *OPCItem, write bool) {
+			defer wg.Done()
+			for n := 0; n < 50; n++ {
+				var err error
+				if write {
+					err = item.Write(int32(n))
+				} else {
+					_, _, _, err = item.Read(OPC_DS_CACHE)
+				}
+				if err != nil {
+					assert.ErrorIs(t, err, ErrCallRejected)
+					assert.ErrorIs(t, err, rpcECallRejected)
+					atomic.AddInt32(&rejected, 1)
+				}
+			}
+		}(items[w%2], w%4 < 2)
+	}
+	wg.Wait()
+	return rejected
+}
+
+func TestOPCItem_SerializeItemIO_Mocked(t *testing.T) {
+	group, items := newRejectingItems()
+	assert.False(t, group.GetSerializeItemIO())
+	// without serialization overlapping calls may be rejected; every rejection is translated
+	hammer(t, items)
+
+	assert.NoError(t, group.SetSerializeItemIO(true))
+	assert.True(t, group.GetSerializeItemIO())
+	assert.Zero(t, hammer(t, items))
+
+	var nilGroup *OPCGroup
+	assert.Error(t, nilGroup.SetSerializeItemIO(true))
+	assert.False(t, nilGroup.GetSerializeItemIO())
+}
@@ -0,0 +1,115 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+// latencyWindowSize is the number of recent samples the latency statistics are computed over.
+const latencyWindowSize = 1024
+
+// fileTimeEpoch is the time a zero FILETIME converts to; servers report it for items without a timestamp.
+var fileTimeEpoch = time.Unix(0, new(windows.Filetime).Nanoseconds())
+
+// LatencyStats summarizes the delay between the server timestamp of an item value and the moment its
+// data change callback was received, over the most recent samples of a group.
+type LatencyStats struct {
+	// Count is the number of samples in the window.
+	Count int
+	// Min, Avg and Max are the smallest, mean and largest latency in the window.
+	Min time.Duration
+	Avg time.Duration
+	Max time.Duration
+	// P99 is the 99th percentile latency in the window.
+	P99 time.Duration
+}
+
+// latencyWindow is a rolling window of latency samples.
+type latencyWindow struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+}
+
+// add records a sample, replacing the oldest one when the window is full.
+func (w *latencyWindow) add(d time.Duration) {
+	if len(w.samples) < latencyWindowSize {
+		w.samples = append(w.samples, d)
+		return
+	}
+	w.samples[w.next] = d
+	w.next = (w.next + 1) % latencyWindowSize
+}
+
+// stats computes the statistics of the samples in the window.
+func (w *latencyWindow) stats() LatencyStats {
+	w.mu.Lock()
+	sorted := append([]time.Duration(nil), w.samples...)
+	w.mu.Unlock()
+	if len(sorted) == 0 {
+		return LatencyStats{}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var sum time.Duration
+	for _, d := range sorted {
+		sum += d
+	}
+	p99 := int(math.Ceil(float64(len(sorted))*0.99)) - 1
+	return LatencyStats{
+		Count: len(sorted),
+		Min:   sorted[0],
+		Avg:   sum / time.Duration(len(sorted)),
+		Max:   sorted[len(sorted)-1],
+		P99:   sorted[p99],
+	}
+}
+
+// hasTimestamp reports whether the server reported a timestamp.
+func hasTimestamp(ts time.Time) bool {
+	return !ts.IsZero() && !ts.Equal(fileTimeEpoch)
+}
+
+// recordLatency adds the latency of every timestamped entry of a data change to the window of the group.
+func (g *OPCGroup) recordLatency(data *DataChangeCallBackData) {
+	receivedAt := data.ReceivedAt
+	if receivedAt.IsZero() {
+		receivedAt = time.Now()
+	}
+	g.latency.mu.Lock()
+	defer g.latency.mu.Unlock()
+	for _, ts := range data.TimeStamps {
+		if hasTimestamp(ts) {
+			g.latency.add(receivedAt.Sub(ts))
+		}
+	}
+}
+
+// GetLatencyStats returns the delivery latency of the data changes of the group, measured from the
+// server timestamp of each item value to the moment the callback reached the client, over the last
+// 1024 values. Values without a timestamp are not counted. Latencies include any offset between the
+// server and client clocks and may be negative when the server clock is ahead.
+func (g *OPCGroup) GetLatencyStats() (LatencyStats, error) {
+	if g == nil {
+		return LatencyStats{}, errors.New("uninitialized group")
+	}
+	return g.latency.stats(), nil
+}
+
+// ResetLatencyStats discards the latency samples of the group.
+func (g *OPCGroup) ResetLatencyStats() error {
+	if g == nil {
+		return errors.New("uninitialized group")
+	}
+	g.latency.mu.Lock()
+	defer g.latency.mu.Unlock()
+	g.latency.samples = nil
+	g.latency.next = 0
+	return nil
+}
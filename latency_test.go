@@ -0,0 +1,58 @@
+//go:build windows
+
+package opcda
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOPCGroup_LatencyStats(t *testing.T) {
+	group := &OPCGroup{}
+	receivedAt := time.Now()
+	group.fireDataChange(context.Background(), &CDataChangeCallBackData{
+		ItemClientHandles: []uint32{1, 2, 3, 4},
+		Values:            []interface{}{1, 2, 3, 4},
+		Qualities:         []uint16{OPC_QUALITY_GOOD, OPC_QUALITY_GOOD, OPC_QUALITY_GOOD, OPC_QUALITY_GOOD},
+		TimeStamps: []time.Time{
+			receivedAt.Add(-10 * time.Millisecond),
+			receivedAt.Add(-30 * time.Millisecond),
+			fileTimeEpoch,
+			{},
+		},
+		Errors:     []int32{0, 0, 0, 0},
+		ReceivedAt: receivedAt,
+	})
+
+	stats, err := group.GetLatencyStats()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, stats.Count)
+	assert.Equal(t, 10*time.Millisecond, stats.Min)
+	assert.Equal(t, 20*time.Millisecond, stats.Avg)
+	assert.Equal(t, 30*time.Millisecond, stats.Max)
+	assert.Equal(t, 30*time.Millisecond, stats.P99)
+
+	assert.NoError(t, group.ResetLatencyStats())
+	stats, err = group.GetLatencyStats()
+	assert.NoError(t, err)
+	assert.Equal(t, LatencyStats{}, stats)
+
+	var nilGroup *OPCGroup
+	_, err = nilGroup.GetLatencyStats()
+	assert.Error(t, err)
+}
+
+func TestLatencyWindow_Rolling(t *testing.T) {
+	var w latencyWindow
+	for n := 1; n <= latencyWindowSize+100; n++ {
+		w.add(time.Duration(n))
+	}
+	stats := w.stats()
+	assert.Equal(t, latencyWindowSize, stats.Count)
+	assert.Equal(t, time.Duration(101), stats.Min)
+	assert.Equal(t, time.Duration(latencyWindowSize+100), stats.Max)
+	assert.Equal(t, time.Duration(101+1013), stats.P99)
+}
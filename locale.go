@@ -0,0 +1,56 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+
+	"github.com/wends155/opcda/com"
+)
+
+// LocaleInfo describes a locale supported by a server.
+type LocaleInfo struct {
+	// ID is the locale identifier (LCID) to pass to SetLocaleID.
+	ID uint32
+	// Name is the locale name, such as "de-DE"; empty if the system does not know the identifier.
+	Name string
+	// EnglishName is the display name in English, such as "German (Germany)"; empty if it cannot be resolved.
+	EnglishName string
+}
+
+// localeName and localeEnglishName resolve the names of a locale; tests replace them with mocks.
+var (
+	localeName        = com.LCIDToLocaleName
+	localeEnglishName = func(name string) (string, error) {
+		return com.GetLocaleInfoEx(name, com.LOCALE_SENGLISHDISPLAYNAME)
+	}
+)
+
+// QueryAvailableLocales returns the locales available for this server/client session with their names,
+// for example to present a language picker. Unknown or custom identifiers are returned with empty names.
+func (s *OPCServer) QueryAvailableLocales() ([]LocaleInfo, error) {
+	if s == nil || s.provider == nil {
+		return nil, errors.New("uninitialized server connection")
+	}
+	ids, err := s.provider.QueryAvailableLocaleIDs()
+	if err != nil {
+		return nil, err
+	}
+	locales := make([]LocaleInfo, len(ids))
+	for i, id := range ids {
+		locales[i] = newLocaleInfo(id)
+	}
+	return locales, nil
+}
+
+// newLocaleInfo resolves the names of a locale identifier, leaving them empty if they cannot be resolved.
+func newLocaleInfo(id uint32) LocaleInfo {
+	info := LocaleInfo{ID: id}
+	name, err := localeName(id)
+	if err != nil || name == "" {
+		return info
+	}
+	info.Name = name
+	info.EnglishName, _ = localeEnglishName(name)
+	return info
+}
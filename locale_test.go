@@ -0,0 +1,47 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOPCServer_QueryAvailableLocales_Mocked(t *testing.T) {
+	defer func(name func(uint32) (string, error), english func(string) (string, error)) {
+		localeName, localeEnglishName = name, english
+	}(localeName, localeEnglishName)
+	localeName = func(lcid uint32) (string, error) {
+		switch lcid {
+		case 0x0409:
+			return "en-US", nil
+		case 0x0407:
+			return "de-DE", nil
+		}
+		return "", errors.New("the parameter is incorrect")
+	}
+	localeEnglishName = func(name string) (string, error) {
+		if name == "de-DE" {
+			return "German (Germany)", nil
+		}
+		return "", errors.New("no display name")
+	}
+	server := newOPCServerWithProvider(&mockServerProvider{
+		QueryAvailableLocaleIDsFn: func() ([]uint32, error) {
+			return []uint32{0x0407, 0x0409, 0x2000}, nil
+		},
+	}, "mock", "localhost")
+
+	locales, err := server.QueryAvailableLocales()
+	assert.NoError(t, err)
+	assert.Equal(t, []LocaleInfo{
+		{ID: 0x0407, Name: "de-DE", EnglishName: "German (Germany)"},
+		{ID: 0x0409, Name: "en-US"},
+		{ID: 0x2000},
+	}, locales)
+
+	_, err = (*OPCServer)(nil).QueryAvailableLocales()
+	assert.Error(t, err)
+}
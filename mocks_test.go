@@ -3,6 +3,8 @@
 package opcda
 
 import (
+	"errors"
+	"sort"
 	"unsafe"
 
 	"github.com/wends155/opcda/com"
@@ -21,9 +23,13 @@ type mockServerProvider struct {
 	GetItemPropertiesFn        func(itemID string, propertyIDs []uint32) ([]interface{}, []int32, error)
 	LookupItemIDsFn            func(itemID string, propertyIDs []uint32) ([]string, []int32, error)
 	AddGroupFn                 func(name string, active bool, updateRate uint32, clientGroup uint32, timeBias *int32, deadband *float32, localeID uint32, iid *windows.GUID) (uint32, uint32, *com.IUnknown, error)
+	GetGroupByNameFn           func(name string, iid *windows.GUID) (*com.IUnknown, error)
+	CreateGroupEnumeratorFn    func(scope uint32, iid *windows.GUID) (*com.IUnknown, error)
 	RemoveGroupFn              func(serverGroup uint32, force bool) error
 	ReleaseFn                  func()
 	QueryInterfaceFn           func(iid *windows.GUID, ppv unsafe.Pointer) error
+	AdviseShutdownFn           func(sink *com.IUnknown) (uint32, error)
+	UnadviseShutdownFn         func(cookie uint32) error
 }
 
 func (m *mockServerProvider) GetStatus() (*com.ServerStatus, error) {
@@ -96,6 +102,20 @@ func (m *mockServerProvider) AddGroup(name string, active bool, updateRate uint3
 	return 0, updateRate, nil, nil
 }
 
+func (m *mockServerProvider) GetGroupByName(name string, iid *windows.GUID) (*com.IUnknown, error) {
+	if m.GetGroupByNameFn != nil {
+		return m.GetGroupByNameFn(name, iid)
+	}
+	return nil, nil
+}
+
+func (m *mockServerProvider) CreateGroupEnumerator(scope uint32, iid *windows.GUID) (*com.IUnknown, error) {
+	if m.CreateGroupEnumeratorFn != nil {
+		return m.CreateGroupEnumeratorFn(scope, iid)
+	}
+	return nil, nil
+}
+
 func (m *mockServerProvider) RemoveGroup(serverGroup uint32, force bool) error {
 	if m.RemoveGroupFn != nil {
 		return m.RemoveGroupFn(serverGroup, force)
@@ -116,6 +136,20 @@ func (m *mockServerProvider) QueryInterface(iid *windows.GUID, ppv unsafe.Pointe
 	return nil
 }
 
+func (m *mockServerProvider) AdviseShutdown(sink *com.IUnknown) (uint32, error) {
+	if m.AdviseShutdownFn != nil {
+		return m.AdviseShutdownFn(sink)
+	}
+	return 1, nil
+}
+
+func (m *mockServerProvider) UnadviseShutdown(cookie uint32) error {
+	if m.UnadviseShutdownFn != nil {
+		return m.UnadviseShutdownFn(cookie)
+	}
+	return nil
+}
+
 // mockGroupProvider implements the groupProvider interface for testing.
 type mockGroupProvider struct {
 	SetNameFn        func(name string) error
@@ -268,3 +302,330 @@ func (m *mockItemMgtProvider) Release() {
 		m.ReleaseFn()
 	}
 }
+
+// mockCommon implements the commonInterface interface for testing.
+type mockCommon struct {
+	GetErrorStringFn          func(dwError int32) (string, error)
+	GetLocaleIDFn             func() (uint32, error)
+	SetLocaleIDFn             func(dwLcid uint32) error
+	SetClientNameFn           func(szName string) error
+	QueryAvailableLocaleIDsFn func() ([]uint32, error)
+}
+
+func (m *mockCommon) GetErrorString(dwError int32) (string, error) {
+	if m.GetErrorStringFn != nil {
+		return m.GetErrorStringFn(dwError)
+	}
+	return "", nil
+}
+
+func (m *mockCommon) GetLocaleID() (uint32, error) {
+	if m.GetLocaleIDFn != nil {
+		return m.GetLocaleIDFn()
+	}
+	return 0, nil
+}
+
+func (m *mockCommon) SetLocaleID(dwLcid uint32) error {
+	if m.SetLocaleIDFn != nil {
+		return m.SetLocaleIDFn(dwLcid)
+	}
+	return nil
+}
+
+func (m *mockCommon) SetClientName(szName string) error {
+	if m.SetClientNameFn != nil {
+		return m.SetClientNameFn(szName)
+	}
+	return nil
+}
+
+func (m *mockCommon) QueryAvailableLocaleIDs() ([]uint32, error) {
+	if m.QueryAvailableLocaleIDsFn != nil {
+		return m.QueryAvailableLocaleIDsFn()
+	}
+	return nil, nil
+}
+
+func (m *mockCommon) Release() uint32 {
+	return 0
+}
+
+// mockItemSamplingMgtProvider implements the itemSamplingMgtProvider interface for testing.
+type mockItemSamplingMgtProvider struct {
+	SetItemSamplingRateFn   func(serverHandles []uint32, samplingRates []uint32) ([]uint32, []int32, error)
+	GetItemSamplingRateFn   func(serverHandles []uint32) ([]uint32, []int32, error)
+	ClearItemSamplingRateFn func(serverHandles []uint32) ([]int32, error)
+	SetItemBufferEnableFn   func(serverHandles []uint32, enable []bool) ([]int32, error)
+	GetItemBufferEnableFn   func(serverHandles []uint32) ([]bool, []int32, error)
+	ReleaseFn               func()
+}
+
+func (m *mockItemSamplingMgtProvider) SetItemSamplingRate(serverHandles []uint32, samplingRates []uint32) ([]uint32, []int32, error) {
+	if m.SetItemSamplingRateFn != nil {
+		return m.SetItemSamplingRateFn(serverHandles, samplingRates)
+	}
+	return samplingRates, make([]int32, len(serverHandles)), nil
+}
+
+func (m *mockItemSamplingMgtProvider) GetItemSamplingRate(serverHandles []uint32) ([]uint32, []int32, error) {
+	if m.GetItemSamplingRateFn != nil {
+		return m.GetItemSamplingRateFn(serverHandles)
+	}
+	return make([]uint32, len(serverHandles)), make([]int32, len(serverHandles)), nil
+}
+
+func (m *mockItemSamplingMgtProvider) ClearItemSamplingRate(serverHandles []uint32) ([]int32, error) {
+	if m.ClearItemSamplingRateFn != nil {
+		return m.ClearItemSamplingRateFn(serverHandles)
+	}
+	return make([]int32, len(serverHandles)), nil
+}
+
+func (m *mockItemSamplingMgtProvider) SetItemBufferEnable(serverHandles []uint32, enable []bool) ([]int32, error) {
+	if m.SetItemBufferEnableFn != nil {
+		return m.SetItemBufferEnableFn(serverHandles, enable)
+	}
+	return make([]int32, len(serverHandles)), nil
+}
+
+func (m *mockItemSamplingMgtProvider) GetItemBufferEnable(serverHandles []uint32) ([]bool, []int32, error) {
+	if m.GetItemBufferEnableFn != nil {
+		return m.GetItemBufferEnableFn(serverHandles)
+	}
+	return make([]bool, len(serverHandles)), make([]int32, len(serverHandles)), nil
+}
+
+func (m *mockItemSamplingMgtProvider) Release() {
+	if m.ReleaseFn != nil {
+		m.ReleaseFn()
+	}
+}
+
+// mockItemDeadbandMgtProvider implements the itemDeadbandMgtProvider interface for testing.
+type mockItemDeadbandMgtProvider struct {
+	SetItemDeadbandFn   func(serverHandles []uint32, deadbands []float32) ([]int32, error)
+	GetItemDeadbandFn   func(serverHandles []uint32) ([]float32, []int32, error)
+	ClearItemDeadbandFn func(serverHandles []uint32) ([]int32, error)
+	ReleaseFn           func()
+}
+
+func (m *mockItemDeadbandMgtProvider) SetItemDeadband(serverHandles []uint32, deadbands []float32) ([]int32, error) {
+	if m.SetItemDeadbandFn != nil {
+		return m.SetItemDeadbandFn(serverHandles, deadbands)
+	}
+	return make([]int32, len(serverHandles)), nil
+}
+
+func (m *mockItemDeadbandMgtProvider) GetItemDeadband(serverHandles []uint32) ([]float32, []int32, error) {
+	if m.GetItemDeadbandFn != nil {
+		return m.GetItemDeadbandFn(serverHandles)
+	}
+	return make([]float32, len(serverHandles)), make([]int32, len(serverHandles)), nil
+}
+
+func (m *mockItemDeadbandMgtProvider) ClearItemDeadband(serverHandles []uint32) ([]int32, error) {
+	if m.ClearItemDeadbandFn != nil {
+		return m.ClearItemDeadbandFn(serverHandles)
+	}
+	return make([]int32, len(serverHandles)), nil
+}
+
+func (m *mockItemDeadbandMgtProvider) Release() {
+	if m.ReleaseFn != nil {
+		m.ReleaseFn()
+	}
+}
+
+// mockBrowse3Provider is a mock implementation of browse3Provider.
+type mockBrowse3Provider struct {
+	BrowseFn  func(itemID string, options com.BrowseOptions) ([]com.BrowseElement, error)
+	ReleaseFn func()
+}
+
+func (m *mockBrowse3Provider) Browse(itemID string, options com.BrowseOptions) ([]com.BrowseElement, error) {
+	if m.BrowseFn != nil {
+		return m.BrowseFn(itemID, options)
+	}
+	return nil, nil
+}
+
+func (m *mockBrowse3Provider) Release() {
+	if m.ReleaseFn != nil {
+		m.ReleaseFn()
+	}
+}
+
+// mockItemIOProvider is a mock implementation of itemIOProvider.
+type mockItemIOProvider struct {
+	ReadFn     func(itemIDs []string, maxAge []uint32) ([]*com.ItemState, []int32, error)
+	WriteVQTFn func(itemIDs []string, values []com.TagOPCITEMVQT) ([]int32, error)
+	ReleaseFn  func()
+}
+
+func (m *mockItemIOProvider) Read(itemIDs []string, maxAge []uint32) ([]*com.ItemState, []int32, error) {
+	if m.ReadFn != nil {
+		return m.ReadFn(itemIDs, maxAge)
+	}
+	return make([]*com.ItemState, len(itemIDs)), make([]int32, len(itemIDs)), nil
+}
+
+func (m *mockItemIOProvider) WriteVQT(itemIDs []string, values []com.TagOPCITEMVQT) ([]int32, error) {
+	if m.WriteVQTFn != nil {
+		return m.WriteVQTFn(itemIDs, values)
+	}
+	return make([]int32, len(itemIDs)), nil
+}
+
+func (m *mockItemIOProvider) Release() {
+	if m.ReleaseFn != nil {
+		m.ReleaseFn()
+	}
+}
+
+// mockServerListProvider is a mock implementation of serverListProvider.
+type mockServerListProvider struct {
+	EnumClassesOfCategoriesFn func(cids []windows.GUID) ([]windows.GUID, error)
+	GetClassDetailsFn         func(classID *windows.GUID) (string, string, string, error)
+	ReleaseFn                 func()
+}
+
+func (m *mockServerListProvider) EnumClassesOfCategories(cids []windows.GUID) ([]windows.GUID, error) {
+	if m.EnumClassesOfCategoriesFn != nil {
+		return m.EnumClassesOfCategoriesFn(cids)
+	}
+	return nil, nil
+}
+
+func (m *mockServerListProvider) GetClassDetails(classID *windows.GUID) (string, string, string, error) {
+	if m.GetClassDetailsFn != nil {
+		return m.GetClassDetailsFn(classID)
+	}
+	return "", "", "", nil
+}
+
+func (m *mockServerListProvider) Release() {
+	if m.ReleaseFn != nil {
+		m.ReleaseFn()
+	}
+}
+
+// mockRegistryView is a mock implementation of registryView backed by maps.
+type mockRegistryView struct {
+	classes map[string]string // classes maps ProgIDs to CLSID strings.
+	opc     map[string]bool   // opc marks the ProgIDs with an OPC subkey.
+	closed  int
+}
+
+func (m *mockRegistryView) SubKeyNames() ([]string, error) {
+	names := make([]string, 0, len(m.classes))
+	for name := range m.classes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (m *mockRegistryView) ProgIDCLSID(progID string, requireOPC bool) (string, error) {
+	clsid, ok := m.classes[progID]
+	if !ok || (requireOPC && !m.opc[progID]) {
+		return "", errors.New("not found")
+	}
+	return clsid, nil
+}
+
+func (m *mockRegistryView) Close() {
+	m.closed++
+}
+
+// mockSyncIO2Provider is a mock implementation of syncIO2Provider.
+type mockSyncIO2Provider struct {
+	ReadMaxAgeFn func(serverHandles []uint32, maxAge []uint32) ([]*com.ItemState, []int32, error)
+	WriteVQTFn   func(serverHandles []uint32, values []com.TagOPCITEMVQT) ([]int32, error)
+	ReleaseFn    func()
+}
+
+func (m *mockSyncIO2Provider) ReadMaxAge(serverHandles []uint32, maxAge []uint32) ([]*com.ItemState, []int32, error) {
+	if m.ReadMaxAgeFn != nil {
+		return m.ReadMaxAgeFn(serverHandles, maxAge)
+	}
+	return make([]*com.ItemState, len(serverHandles)), make([]int32, len(serverHandles)), nil
+}
+
+func (m *mockSyncIO2Provider) WriteVQT(serverHandles []uint32, values []com.TagOPCITEMVQT) ([]int32, error) {
+	if m.WriteVQTFn != nil {
+		return m.WriteVQTFn(serverHandles, values)
+	}
+	return make([]int32, len(serverHandles)), nil
+}
+
+func (m *mockSyncIO2Provider) Release() {
+	if m.ReleaseFn != nil {
+		m.ReleaseFn()
+	}
+}
+
+// mockConnectionPointsProvider is a mock implementation of connectionPointsProvider.
+type mockConnectionPointsProvider struct {
+	ConnectionsFn func() ([]ConnectionInfo, error)
+	UnadviseFn    func(iid windows.GUID, cookie uint32) error
+	ReleaseFn     func()
+}
+
+func (m *mockConnectionPointsProvider) Connections() ([]ConnectionInfo, error) {
+	if m.ConnectionsFn != nil {
+		return m.ConnectionsFn()
+	}
+	return nil, nil
+}
+
+func (m *mockConnectionPointsProvider) Unadvise(iid windows.GUID, cookie uint32) error {
+	if m.UnadviseFn != nil {
+		return m.UnadviseFn(iid, cookie)
+	}
+	return nil
+}
+
+func (m *mockConnectionPointsProvider) Release() {
+	if m.ReleaseFn != nil {
+		m.ReleaseFn()
+	}
+}
+
+// mockDataCallbackPoint is a mock implementation of dataCallbackPoint.
+type mockDataCallbackPoint struct {
+	UnadviseFn func(cookie uint32) error
+	ReleaseFn  func()
+}
+
+func (m *mockDataCallbackPoint) Unadvise(cookie uint32) error {
+	if m.UnadviseFn != nil {
+		return m.UnadviseFn(cookie)
+	}
+	return nil
+}
+
+func (m *mockDataCallbackPoint) Release() {
+	if m.ReleaseFn != nil {
+		m.ReleaseFn()
+	}
+}
+
+// mockPublicGroupsProvider is a mock implementation of publicGroupsProvider.
+type mockPublicGroupsProvider struct {
+	GetPublicGroupByNameFn func(name string) (*com.IUnknown, error)
+	ReleaseFn              func()
+}
+
+func (m *mockPublicGroupsProvider) GetPublicGroupByName(name string) (*com.IUnknown, error) {
+	if m.GetPublicGroupByNameFn != nil {
+		return m.GetPublicGroupByNameFn(name)
+	}
+	return nil, nil
+}
+
+func (m *mockPublicGroupsProvider) Release() {
+	if m.ReleaseFn != nil {
+		m.ReleaseFn()
+	}
+}
@@ -0,0 +1,125 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/wends155/opcda/com"
+)
+
+// onValueBuffer is the capacity of the data change channel of an OnValue callback.
+const onValueBuffer = 16
+
+// OnValue calls fn with every value of item reported by the data change callbacks of its group, converted
+// to T, together with its quality and timestamp. Updates of the other items of the group are ignored.
+//
+// A value of type T is passed as is, a numeric value is converted to a numeric T with the Go conversion
+// rules, with com.Currency and *big.Rat values converted by the number they represent, and a nil value, as
+// sent with bad quality, is passed as the zero T so the quality still reaches fn. Updates that carry an error
+// or hold a value that cannot be converted to T are skipped.
+//
+// fn is called on a goroutine of its own, one update at a time and in the order of the callbacks. The
+// subscription is registered with RegisterDataChange, so updates are dropped while fn falls behind by more
+// than a few callbacks. The returned function ends the callbacks; it may be called more than once and from
+// fn, and a call of fn in progress completes.
+//
+// Example:
+//
+//	stop, err := opcda.OnValue(tempItem, func(celsius float64, quality uint16, ts time.Time) {
+//		if quality&opcda.OPC_QUALITY_MASK == opcda.OPC_QUALITY_GOOD {
+//			controller.Update(celsius, ts)
+//		}
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	defer stop()
+func OnValue[T any](item *OPCItem, fn func(T, uint16, time.Time)) (func(), error) {
+	if item == nil || item.parent == nil || item.parent.parent == nil {
+		return nil, errors.New("uninitialized item")
+	}
+	if fn == nil {
+		return nil, errors.New("nil callback")
+	}
+	group := item.parent.parent
+	ch := make(chan *DataChangeCallBackData, onValueBuffer)
+	err := group.RegisterDataChange(ch)
+	if err != nil {
+		return nil, err
+	}
+	done := make(chan struct{})
+	go forwardValues(group, item, ch, done, fn)
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			group.UnregisterDataChange(ch)
+			close(done)
+		})
+	}
+	return stop, nil
+}
+
+// forwardValues calls fn with the updates of item received on ch until done is closed.
+func forwardValues[T any](group *OPCGroup, item *OPCItem, ch chan *DataChangeCallBackData, done chan struct{}, fn func(T, uint16, time.Time)) {
+	for {
+		select {
+		case <-done:
+			return
+		case data := <-ch:
+			update, ok := group.ResolveDataChange(data)[item]
+			if !ok || update.Err != nil {
+				continue
+			}
+			value, ok := coerceValue[T](update.Value)
+			if !ok {
+				continue
+			}
+			select {
+			case <-done:
+				return
+			default:
+			}
+			fn(value, update.Quality, update.Timestamp)
+		}
+	}
+}
+
+// coerceValue converts a value of a callback to T, reporting whether it could. nil converts to the zero T.
+func coerceValue[T any](v interface{}) (T, bool) {
+	var zero T
+	if v == nil {
+		return zero, true
+	}
+	if t, ok := v.(T); ok {
+		return t, true
+	}
+	// fixed-point and decimal values convert by their numeric value, not their representation
+	switch n := v.(type) {
+	case com.Currency:
+		v = n.Float64()
+	case *big.Rat:
+		v, _ = n.Float64()
+	}
+	rv := reflect.ValueOf(v)
+	target := reflect.TypeFor[T]()
+	if !isNumericKind(rv.Kind()) || !isNumericKind(target.Kind()) {
+		return zero, false
+	}
+	return rv.Convert(target).Interface().(T), true
+}
+
+// isNumericKind reports whether k is an integer or floating-point kind.
+func isNumericKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+//go:build windows
+
+package opcda
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// onValueUpdate is one call of an OnValue callback.
+type onValueUpdate struct {
+	value   float64
+	quality uint16
+	ts      time.Time
+}
+
+func TestOnValue_Mocked(t *testing.T) {
+	group := &OPCGroup{provider: &mockServerProvider{}, event: &DataEventReceiver{}}
+	group.items = NewOPCItems(group, &mockItemMgtProvider{}, group.provider)
+	temp := &OPCItem{parent: group.items, clientHandle: 1}
+	group.items.items = []*OPCItem{temp, {parent: group.items, clientHandle: 2}}
+	group.items.reindex()
+
+	updates := make(chan onValueUpdate, 8)
+	stop, err := OnValue(temp, func(v float64, quality uint16, ts time.Time) {
+		updates <- onValueUpdate{v, quality, ts}
+	})
+	assert.NoError(t, err)
+
+	now := time.Now()
+	fire := func(handles []uint32, values []interface{}, errs []int32) {
+		group.fireDataChange(context.Background(), &CDataChangeCallBackData{
+			ItemClientHandles: handles,
+			Values:            values,
+			Qualities:         make([]uint16, len(handles)),
+			TimeStamps:        []time.Time{now, now},
+			Errors:            errs,
+		})
+	}
+	fire([]uint32{2, 1}, []interface{}{"other item", float32(21.5)}, []int32{0, 0})
+	fire([]uint32{1}, []interface{}{"not a number"}, []int32{0})
+	fire([]uint32{1}, []interface{}{float64(1)}, []int32{int32(OPCBadRights)})
+	fire([]uint32{1}, []interface{}{nil}, []int32{0})
+	fire([]uint32{1}, []interface{}{int32(22)}, []int32{0})
+
+	assert.Equal(t, onValueUpdate{21.5, 0, now}, <-updates)
+	assert.Equal(t, onValueUpdate{0, 0, now}, <-updates, "a nil value is passed as the zero value")
+	assert.Equal(t, onValueUpdate{22, 0, now}, <-updates)
+
+	stop()
+	stop()
+	group.callbackLock.Lock()
+	assert.Empty(t, group.dataChangeList)
+	group.callbackLock.Unlock()
+
+	_, err = OnValue(&OPCItem{}, func(float64, uint16, time.Time) {})
+	assert.Error(t, err)
+	_, err = OnValue[float64](temp, nil)
+	assert.Error(t, err)
+}
+
+func TestCoerceValue(t *testing.T) {
+	f, ok := coerceValue[float64](float32(1.5))
+	assert.True(t, ok)
+	assert.Equal(t, 1.5, f)
+	i, ok := coerceValue[int64](uint16(7))
+	assert.True(t, ok)
+	assert.Equal(t, int64(7), i)
+	s, ok := coerceValue[string]("text")
+	assert.True(t, ok)
+	assert.Equal(t, "text", s)
+	_, ok = coerceValue[string](int32(65))
+	assert.False(t, ok, "numbers must not be converted to strings")
+	v, ok := coerceValue[interface{}](errors.New("x"))
+	assert.True(t, ok)
+	assert.Error(t, v.(error))
+}
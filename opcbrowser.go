@@ -4,6 +4,8 @@ package opcda
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"unsafe"
 
 	"github.com/wends155/opcda/com"
@@ -77,12 +79,28 @@ func NewOPCBrowser(parent *OPCServer) (*OPCBrowser, error) {
 	if parent == nil || parent.provider == nil {
 		return nil, errors.New("parent server is nil or uninitialized")
 	}
+	provider, err := parent.browser()
+	if err != nil {
+		return nil, err
+	}
+	return newOPCBrowserWithProvider(provider, parent), nil
+}
+
+// queryComBrowser acquires the IOPCBrowseServerAddressSpace interface of parent and applies its authInfo.
+func queryComBrowser(parent *OPCServer) (p browserProvider, err error) {
+	var c cleanup
+	defer c.done(&err)
 	var iBrowseServerAddressSpace *com.IUnknown
-	err := parent.provider.QueryInterface(&com.IID_IOPCBrowseServerAddressSpace, unsafe.Pointer(&iBrowseServerAddressSpace))
+	err = parent.provider.QueryInterface(&com.IID_IOPCBrowseServerAddressSpace, unsafe.Pointer(&iBrowseServerAddressSpace))
 	if err != nil {
 		return nil, NewOPCWrapperError("query interface IOPCBrowseServerAddressSpace", err)
 	}
-	return newOPCBrowserWithProvider(&comBrowserProvider{iBrowseServerAddressSpace: &com.IOPCBrowseServerAddressSpace{IUnknown: iBrowseServerAddressSpace}}, parent), nil
+	c.addInterface(iBrowseServerAddressSpace)
+	err = setProxyBlanket(iBrowseServerAddressSpace, parent.authInfo)
+	if err != nil {
+		return nil, NewOPCWrapperError("set proxy blanket IOPCBrowseServerAddressSpace", err)
+	}
+	return &comBrowserProvider{iBrowseServerAddressSpace: &com.IOPCBrowseServerAddressSpace{IUnknown: iBrowseServerAddressSpace}}, nil
 }
 
 // newOPCBrowserWithProvider creates a new OPCBrowser with a specific provider (internal).
@@ -238,11 +256,18 @@ func (b *OPCBrowser) MoveDown(name string) error {
 	return b.provider.ChangeBrowsePosition(OPC_BROWSE_DOWN, name)
 }
 
-// MoveTo moves to an absolute position.
+// MoveTo moves to an absolute position given by the names of the branches below the root.
+// When the namespace separator of the server is known, because DetectSeparator or
+// OPCServer.NamespaceSeparator found it, MoveTo jumps to the item ID of the position with a single
+// OPC_BROWSE_TO call; an empty branches moves to the root the same way. It walks down from the root branch
+// by branch only when the separator is unknown or the server rejects OPC_BROWSE_TO.
 func (b *OPCBrowser) MoveTo(branches []string) error {
 	if b == nil || b.provider == nil {
 		return errors.New("uninitialized browser")
 	}
+	if itemID, ok := b.absolutePosition(branches); ok && b.provider.ChangeBrowsePosition(OPC_BROWSE_TO, itemID) == nil {
+		return nil
+	}
 	b.MoveToRoot()
 	for _, branch := range branches {
 		err := b.MoveDown(branch)
@@ -253,6 +278,33 @@ func (b *OPCBrowser) MoveTo(branches []string) error {
 	return nil
 }
 
+// absolutePosition builds the item ID of the position below the root given by branches, if the namespace
+// separator of the server is known.
+func (b *OPCBrowser) absolutePosition(branches []string) (string, bool) {
+	if len(branches) == 0 {
+		return "", true
+	}
+	if b.parent == nil {
+		return "", false
+	}
+	sep := b.parent.separator.Load()
+	if sep == nil {
+		return "", false
+	}
+	return strings.Join(branches, *sep), true
+}
+
+// MoveToItemID moves directly to the branch with the fully qualified item ID, or to the root if itemID
+// is empty, with OPC_BROWSE_TO. Unlike MoveTo it does not depend on branch names, which some servers
+// display differently from the components of their item IDs. Servers that do not support absolute
+// positioning return an error.
+func (b *OPCBrowser) MoveToItemID(itemID string) error {
+	if b == nil || b.provider == nil {
+		return errors.New("uninitialized browser")
+	}
+	return b.provider.ChangeBrowsePosition(OPC_BROWSE_TO, itemID)
+}
+
 // GetItemID gives a name and returns a valid ItemID that can be passed to OPCItems Add method.
 func (b *OPCBrowser) GetItemID(leaf string) (string, error) {
 	if b == nil || b.provider == nil {
@@ -261,6 +313,193 @@ func (b *OPCBrowser) GetItemID(leaf string) (string, error) {
 	return b.provider.GetItemID(leaf)
 }
 
+// ErrUnknownSeparator is returned by DetectSeparator when the namespace separator of the server cannot be inferred,
+// for example because the address space is flat or has no branch with leaves.
+var ErrUnknownSeparator = errors.New("unknown namespace separator")
+
+// separatorSearchLimit bounds the number of branches DetectSeparator visits.
+const separatorSearchLimit = 100
+
+// DetectSeparator infers the string the server puts between the components of an item ID, such as "." or "/".
+// The DA specification does not expose it, so DetectSeparator looks for a branch below the current browse
+// position that contains a leaf and compares the item ID of the leaf with the item ID of the branch.
+// The browse position is restored afterwards. The result is cached on the server, so later calls do not browse.
+// ErrUnknownSeparator is returned when the separator cannot be inferred; callers should then treat item IDs
+// as opaque strings.
+//
+// Example:
+//
+//	sep, err := browser.DetectSeparator()
+//	if errors.Is(err, opcda.ErrUnknownSeparator) {
+//		// use the full item IDs as they are
+//	}
+func (b *OPCBrowser) DetectSeparator() (string, error) {
+	if b == nil || b.provider == nil {
+		return "", errors.New("uninitialized browser")
+	}
+	if b.parent != nil {
+		if sep := b.parent.separator.Load(); sep != nil {
+			return *sep, nil
+		}
+	}
+	organization, err := b.provider.QueryOrganization()
+	if err != nil {
+		return "", err
+	}
+	if organization != OPC_NS_HIERARCHIAL {
+		return "", ErrUnknownSeparator
+	}
+	budget := separatorSearchLimit
+	sep, err := b.findSeparator("", &budget)
+	if err != nil {
+		return "", err
+	}
+	if sep == "" {
+		return "", ErrUnknownSeparator
+	}
+	if b.parent != nil {
+		b.parent.separator.Store(&sep)
+	}
+	return sep, nil
+}
+
+// findSeparator searches the current browse position and the branches below it for a leaf whose item ID
+// reveals the separator. branch is the name of the current position, or "" at the starting position.
+// Every branch entered is left again, so the browse position is unchanged when it returns.
+func (b *OPCBrowser) findSeparator(branch string, budget *int) (string, error) {
+	if branch != "" {
+		leaves, err := b.provider.BrowseOPCItemIDs(OPC_LEAF, "", 0, 0)
+		if err == nil && len(leaves) > 0 {
+			if sep := b.separatorFromLeaf(branch, leaves[0]); sep != "" {
+				return sep, nil
+			}
+		}
+	}
+	branches, err := b.provider.BrowseOPCItemIDs(OPC_BRANCH, "", 0, 0)
+	if err != nil {
+		return "", nil
+	}
+	for _, name := range branches {
+		if *budget <= 0 {
+			return "", nil
+		}
+		*budget--
+		if b.provider.ChangeBrowsePosition(OPC_BROWSE_DOWN, name) != nil {
+			continue
+		}
+		sep, findErr := b.findSeparator(name, budget)
+		err = b.provider.ChangeBrowsePosition(OPC_BROWSE_UP, "")
+		if err != nil {
+			return "", NewOPCWrapperError("restore browse position", err)
+		}
+		if findErr != nil || sep != "" {
+			return sep, findErr
+		}
+	}
+	return "", nil
+}
+
+// separatorFromLeaf infers the separator from the item IDs of the current branch and one of its leaves.
+// It returns "" when the item IDs do not reveal it.
+func (b *OPCBrowser) separatorFromLeaf(branch, leaf string) string {
+	itemID, err := b.provider.GetItemID(leaf)
+	if err != nil || !strings.HasSuffix(itemID, leaf) {
+		return ""
+	}
+	prefix := strings.TrimSuffix(itemID, leaf)
+	if branchID, err := b.provider.GetItemID(""); err == nil && branchID != "" && strings.HasPrefix(prefix, branchID) {
+		return prefix[len(branchID):]
+	}
+	if i := strings.LastIndex(prefix, branch); i >= 0 {
+		return prefix[i+len(branch):]
+	}
+	return ""
+}
+
+// WalkAll calls fn for every branch and leaf below the current browse position, depth first, with the fully
+// qualified item ID of each and whether it is a branch. A branch is reported before its contents.
+// The filter, data type and access rights of the browser apply as in ShowBranches and ShowLeafs, so the filter
+// also limits the branches that are entered. On a flat address space WalkAll reports the leaves of OPC_FLAT.
+// The browse position is restored before WalkAll returns. If fn returns an error, the walk stops and WalkAll
+// returns that error.
+//
+// Example:
+//
+//	err := browser.WalkAll(func(itemID string, isBranch bool) error {
+//		if !isBranch {
+//			itemIDs = append(itemIDs, itemID)
+//		}
+//		return nil
+//	})
+func (b *OPCBrowser) WalkAll(fn func(itemID string, isBranch bool) error) error {
+	if b == nil || b.provider == nil {
+		return errors.New("uninitialized browser")
+	}
+	organization, err := b.provider.QueryOrganization()
+	if err != nil {
+		return err
+	}
+	if organization != OPC_NS_HIERARCHIAL {
+		leaves, err := b.provider.BrowseOPCItemIDs(OPC_FLAT, b.filter, b.dataType, b.accessRights)
+		if err != nil {
+			return err
+		}
+		return b.walkLeaves(leaves, fn)
+	}
+	return b.walk(fn)
+}
+
+// walk reports the branches and leaves at the current browse position and below it. Every branch entered is
+// left again, so the browse position is unchanged when it returns.
+func (b *OPCBrowser) walk(fn func(itemID string, isBranch bool) error) error {
+	branches, err := b.provider.BrowseOPCItemIDs(OPC_BRANCH, b.filter, b.dataType, b.accessRights)
+	if err != nil {
+		return err
+	}
+	for _, name := range branches {
+		itemID, err := b.provider.GetItemID(name)
+		if err != nil {
+			return fmt.Errorf("get item ID of branch %q: %w", name, err)
+		}
+		err = fn(itemID, true)
+		if err != nil {
+			return err
+		}
+		err = b.provider.ChangeBrowsePosition(OPC_BROWSE_DOWN, name)
+		if err != nil {
+			return fmt.Errorf("move down to %q: %w", name, err)
+		}
+		walkErr := b.walk(fn)
+		err = b.provider.ChangeBrowsePosition(OPC_BROWSE_UP, "")
+		if err != nil {
+			return NewOPCWrapperError("restore browse position", err)
+		}
+		if walkErr != nil {
+			return walkErr
+		}
+	}
+	leaves, err := b.provider.BrowseOPCItemIDs(OPC_LEAF, b.filter, b.dataType, b.accessRights)
+	if err != nil {
+		return err
+	}
+	return b.walkLeaves(leaves, fn)
+}
+
+// walkLeaves reports the leaves at the current browse position.
+func (b *OPCBrowser) walkLeaves(leaves []string, fn func(itemID string, isBranch bool) error) error {
+	for _, name := range leaves {
+		itemID, err := b.provider.GetItemID(name)
+		if err != nil {
+			return fmt.Errorf("get item ID of leaf %q: %w", name, err)
+		}
+		err = fn(itemID, false)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Release releases the OPCBrowser.
 func (b *OPCBrowser) Release() {
 	if b == nil || b.provider == nil {
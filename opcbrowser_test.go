@@ -5,6 +5,7 @@ package opcda
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -169,3 +170,150 @@ func ExampleOPCServer_CreateBrowser_error() {
 	}
 	// Output: Caught expected browser creation error
 }
+
+// separatorBrowserProvider builds item IDs of the mock address space with a custom separator and counts browses.
+type separatorBrowserProvider struct {
+	*mockBrowserProvider
+	sep          string
+	organization com.OPCNAMESPACETYPE
+	browses      int
+}
+
+func (m *separatorBrowserProvider) GetItemID(leaf string) (string, error) {
+	id, err := m.mockBrowserProvider.GetItemID(leaf)
+	return strings.ReplaceAll(id, ".", m.sep), err
+}
+
+func (m *separatorBrowserProvider) QueryOrganization() (com.OPCNAMESPACETYPE, error) {
+	return m.organization, nil
+}
+
+func (m *separatorBrowserProvider) BrowseOPCItemIDs(filterType com.OPCBROWSETYPE, filter string, dataType uint16, accessRights uint32) ([]string, error) {
+	m.browses++
+	return m.mockBrowserProvider.BrowseOPCItemIDs(filterType, filter, dataType, accessRights)
+}
+
+func TestOPCBrowser_DetectSeparator_Mocked(t *testing.T) {
+	for _, sep := range []string{".", "/", "::"} {
+		t.Run(sep, func(t *testing.T) {
+			server := newOPCServerWithProvider(&mockServerProvider{}, "mock", "localhost")
+			mock := &separatorBrowserProvider{mockBrowserProvider: newMockBrowserProvider(), sep: sep, organization: OPC_NS_HIERARCHIAL}
+			browser := newOPCBrowserWithProvider(mock, server)
+
+			detected, err := browser.DetectSeparator()
+			assert.NoError(t, err)
+			assert.Equal(t, sep, detected)
+			pos, _ := browser.GetCurrentPosition()
+			assert.Equal(t, "", pos)
+
+			browses := mock.browses
+			detected, err = newOPCBrowserWithProvider(mock, server).DetectSeparator()
+			assert.NoError(t, err)
+			assert.Equal(t, sep, detected)
+			assert.Equal(t, browses, mock.browses)
+		})
+	}
+}
+
+func TestOPCBrowser_DetectSeparator_Unknown_Mocked(t *testing.T) {
+	flat := &separatorBrowserProvider{mockBrowserProvider: newMockBrowserProvider(), sep: ".", organization: OPC_NS_FLAT}
+	_, err := newOPCBrowserWithProvider(flat, nil).DetectSeparator()
+	assert.ErrorIs(t, err, ErrUnknownSeparator)
+
+	noLeaves := newMockBrowserProvider()
+	noLeaves.leaves = map[string][]string{"": {"RootItem1"}}
+	_, err = newOPCBrowserWithProvider(noLeaves, nil).DetectSeparator()
+	assert.ErrorIs(t, err, ErrUnknownSeparator)
+	assert.Equal(t, "", noLeaves.currentPath)
+}
+
+// browseToProvider counts position changes of the mock address space and can reject OPC_BROWSE_TO.
+type browseToProvider struct {
+	*mockBrowserProvider
+	rejectBrowseTo bool
+	changes        int
+}
+
+func (m *browseToProvider) ChangeBrowsePosition(dir com.OPCBROWSEDIRECTION, name string) error {
+	m.changes++
+	if dir == OPC_BROWSE_TO && m.rejectBrowseTo {
+		return errors.New("not supported")
+	}
+	return m.mockBrowserProvider.ChangeBrowsePosition(dir, name)
+}
+
+func TestOPCBrowser_MoveTo_BrowseTo_Mocked(t *testing.T) {
+	server := newOPCServerWithProvider(&mockServerProvider{}, "mock", "localhost")
+	mock := &browseToProvider{mockBrowserProvider: newMockBrowserProvider()}
+	browser := newOPCBrowserWithProvider(mock, server)
+
+	// without a known separator MoveTo walks from the root
+	assert.NoError(t, browser.MoveTo([]string{"Folder1", "SubFolder1"}))
+	assert.Equal(t, "SubFolder1", mock.currentPath)
+	assert.Greater(t, mock.changes, 1)
+
+	sep := "/"
+	server.separator.Store(&sep)
+	mock.changes = 0
+	assert.NoError(t, browser.MoveTo([]string{"Folder1", "SubFolder1"}))
+	assert.Equal(t, "Folder1/SubFolder1", mock.currentPath)
+	assert.Equal(t, 1, mock.changes)
+
+	mock.rejectBrowseTo = true
+	mock.currentPath = ""
+	assert.NoError(t, browser.MoveTo([]string{"Folder1"}))
+	assert.Equal(t, "Folder1", mock.currentPath)
+	assert.Error(t, browser.MoveToItemID("Folder1"))
+
+	mock.rejectBrowseTo = false
+	assert.NoError(t, browser.MoveToItemID("Folder2"))
+	assert.Equal(t, "Folder2", mock.currentPath)
+}
+
+func TestOPCBrowser_WalkAll_Mocked(t *testing.T) {
+	mock := newMockBrowserProvider()
+	browser := newOPCBrowserWithProvider(mock, nil)
+
+	var visited []string
+	err := browser.WalkAll(func(itemID string, isBranch bool) error {
+		if isBranch {
+			itemID += "/"
+		}
+		visited = append(visited, itemID)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"Folder1/", "Folder1.SubFolder1/", "SubFolder1.SubItem1",
+		"Folder1.Item1", "Folder1.Item2",
+		"Folder2/",
+		"RootItem1",
+	}, visited)
+	assert.Equal(t, "", mock.currentPath)
+
+	stop := errors.New("stop")
+	err = browser.WalkAll(func(itemID string, isBranch bool) error {
+		if itemID == "SubFolder1.SubItem1" {
+			return stop
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, "", mock.currentPath, "the browse position must be restored when fn stops the walk")
+}
+
+func TestOPCBrowser_WalkAll_Flat_Mocked(t *testing.T) {
+	flat := &separatorBrowserProvider{mockBrowserProvider: newMockBrowserProvider(), sep: ".", organization: OPC_NS_FLAT}
+	var visited []string
+	err := newOPCBrowserWithProvider(flat, nil).WalkAll(func(itemID string, isBranch bool) error {
+		assert.False(t, isBranch)
+		visited = append(visited, itemID)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"RootItem1"}, visited)
+	assert.Equal(t, 1, flat.browses)
+
+	var nilBrowser *OPCBrowser
+	assert.Error(t, nilBrowser.WalkAll(func(string, bool) error { return nil }))
+}
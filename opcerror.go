@@ -3,22 +3,75 @@
 package opcda
 
 import (
+	"errors"
 	"fmt"
+
+	"github.com/wends155/opcda/com"
 )
 
+// OPCError is an error code reported by the server for an item or operation, with the message the server
+// returned for it. Errors with a standard OPC DA code wrap the matching sentinel, such as ErrUnknownItemID,
+// so they can be checked with errors.Is while ErrorMessage keeps the vendor text for logging.
 type OPCError struct {
 	ErrorCode    int32
 	ErrorMessage string
 }
 
+// Error returns the HRESULT of e as eight hex digits, followed by the server message or, without one,
+// the standard description of the code.
 func (e *OPCError) Error() string {
-	if e.ErrorMessage == "" {
-		if msg, ok := opcErrors[e.ErrorCode]; ok {
-			return fmt.Errorf("OPCError [0x%x]: %s", uint32(e.ErrorCode), msg).Error()
+	msg := e.ErrorMessage
+	if msg == "" {
+		var ok bool
+		if msg, ok = opcErrors[e.ErrorCode]; !ok {
+			msg = "unknown error"
 		}
-		return fmt.Errorf("OPCError [0x%x]: %s", uint32(e.ErrorCode), "unknown error").Error()
 	}
-	return fmt.Errorf("OPCError [0x%x]: %s", uint32(e.ErrorCode), e.ErrorMessage).Error()
+	return fmt.Sprintf("OPCError [0x%08X]: %s", uint32(e.ErrorCode), msg)
+}
+
+// HRESULT returns the error code of e as a com.HRESULT.
+func (e *OPCError) HRESULT() com.HRESULT {
+	return com.HRESULT(uint32(e.ErrorCode))
+}
+
+// IsSuccess reports whether the code of e is a success code, such as S_FALSE or OPC_S_CLAMP, that some
+// servers report for partial success.
+func (e *OPCError) IsSuccess() bool {
+	return e.ErrorCode >= 0
+}
+
+// IsGood reports whether the code of e is S_OK.
+func (e *OPCError) IsGood() bool {
+	return e.ErrorCode == 0
+}
+
+// Is reports whether target is a com.HRESULT or syscall.Errno with the error code of e.
+func (e *OPCError) Is(target error) bool {
+	code, ok := com.HResultOf(target)
+	return ok && uint32(code) == uint32(e.ErrorCode)
+}
+
+// Unwrap returns the sentinel error of the standard OPC DA code of e, or nil if the code is not one of them.
+func (e *OPCError) Unwrap() error {
+	sentinel, ok := opcSentinels[e.ErrorCode]
+	if !ok || sentinel == e {
+		return nil
+	}
+	return sentinel
+}
+
+// errorCode returns the numeric code carried by err: a com.HRESULT returned by a COM call, a
+// syscall.Errno or the code of an OPCError, found anywhere in the chain.
+func errorCode(err error) (com.HRESULT, bool) {
+	if code, ok := com.HResultOf(err); ok {
+		return code, true
+	}
+	var opcErr *OPCError
+	if errors.As(err, &opcErr) {
+		return com.HRESULT(uint32(opcErr.ErrorCode)), true
+	}
+	return 0, false
 }
 
 var opcErrors = map[int32]string{
@@ -38,6 +91,14 @@ var opcErrors = map[int32]string{
 	int32(OPCInvalidConfig):   "The server's configuration file is an invalid format",
 	int32(OPCNotFound):        "Requested Object was not found",
 	int32(OPCInvalidPID):      "The passed property ID is not valid for the item",
+
+	int32(OPCDeadbandNotSet):           "The item deadband has not been set for this item",
+	int32(OPCDeadbandNotSupported):     "The item does not support deadband",
+	int32(OPCNoBuffering):              "The server does not support buffering of data items that are collected at a faster rate than the group update rate",
+	int32(OPCInvalidContinuationPoint): "The continuation point is not valid",
+	int32(OPCDataQueueOverflow):        "Not every detected change has been returned since the server's buffer reached its limit and had to purge out the oldest data",
+	int32(OPCRateNotSet):               "There is no sampling rate set for the specified item",
+	int32(OPCNotSupported):             "The server does not support writing of quality and/or timestamp",
 }
 
 var (
@@ -57,8 +118,57 @@ var (
 	OPCInvalidConfig   = uint32(0xC0040010)
 	OPCNotFound        = uint32(0xC0040011)
 	OPCInvalidPID      = uint32(0xC0040203)
+
+	OPCDeadbandNotSet           = uint32(0xC0040400)
+	OPCDeadbandNotSupported     = uint32(0xC0040401)
+	OPCNoBuffering              = uint32(0xC0040402)
+	OPCInvalidContinuationPoint = uint32(0xC0040403)
+	OPCDataQueueOverflow        = uint32(0x00040404)
+	OPCRateNotSet               = uint32(0xC0040405)
+	OPCNotSupported             = uint32(0xC0040406)
 )
 
+// Sentinel errors for the standard OPC DA failure codes. The OPCError values returned for failed items
+// and operations wrap them, so callers can test for a condition with errors.Is:
+//
+//	if errors.Is(err, opcda.ErrUnknownItemID) {
+//		// the item no longer exists in the server address space
+//	}
+//
+// Success codes such as OPC_S_CLAMP are not failures and have no sentinel.
+var (
+	ErrInvalidHandle            = newOPCSentinel(OPCInvalidHandle)            // OPC_E_INVALIDHANDLE
+	ErrBadType                  = newOPCSentinel(OPCBadType)                  // OPC_E_BADTYPE
+	ErrPublic                   = newOPCSentinel(OPCPublic)                   // OPC_E_PUBLIC
+	ErrBadRights                = newOPCSentinel(OPCBadRights)                // OPC_E_BADRIGHTS
+	ErrUnknownItemID            = newOPCSentinel(OPCUnknownItemID)            // OPC_E_UNKNOWNITEMID
+	ErrInvalidItemID            = newOPCSentinel(OPCInvalidItemID)            // OPC_E_INVALIDITEMID
+	ErrInvalidFilter            = newOPCSentinel(OPCInvalidFilter)            // OPC_E_INVALIDFILTER
+	ErrUnknownPath              = newOPCSentinel(OPCUnknownPath)              // OPC_E_UNKNOWNPATH
+	ErrRange                    = newOPCSentinel(OPCRange)                    // OPC_E_RANGE
+	ErrDuplicateName            = newOPCSentinel(OPCDuplicateName)            // OPC_E_DUPLICATENAME
+	ErrInvalidConfig            = newOPCSentinel(OPCInvalidConfig)            // OPC_E_INVALIDCONFIG
+	ErrNotFound                 = newOPCSentinel(OPCNotFound)                 // OPC_E_NOTFOUND
+	ErrInvalidPID               = newOPCSentinel(OPCInvalidPID)               // OPC_E_INVALID_PID
+	ErrDeadbandNotSet           = newOPCSentinel(OPCDeadbandNotSet)           // OPC_E_DEADBANDNOTSET
+	ErrDeadbandNotSupported     = newOPCSentinel(OPCDeadbandNotSupported)     // OPC_E_DEADBANDNOTSUPPORTED
+	ErrNoBuffering              = newOPCSentinel(OPCNoBuffering)              // OPC_E_NOBUFFERING
+	ErrInvalidContinuationPoint = newOPCSentinel(OPCInvalidContinuationPoint) // OPC_E_INVALIDCONTINUATIONPOINT
+	ErrRateNotSet               = newOPCSentinel(OPCRateNotSet)               // OPC_E_RATENOTSET
+	ErrNotSupported             = newOPCSentinel(OPCNotSupported)             // OPC_E_NOTSUPPORTED
+)
+
+// opcSentinels maps the standard OPC DA failure codes to their sentinel errors.
+var opcSentinels = map[int32]*OPCError{}
+
+// newOPCSentinel creates the sentinel error of a standard OPC DA code and registers it in opcSentinels.
+// Its message is the standard description of the code.
+func newOPCSentinel(code uint32) *OPCError {
+	e := &OPCError{ErrorCode: int32(code)}
+	opcSentinels[e.ErrorCode] = e
+	return e
+}
+
 type OPCWrapperError struct {
 	Err  error
 	Info string
@@ -3,11 +3,13 @@
 package opcda
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/wends155/opcda/com"
 )
 
 func TestOPCError_Error(t *testing.T) {
@@ -26,7 +28,7 @@ func TestOPCError_Error(t *testing.T) {
 				ErrorCode:    int32(16),
 				ErrorMessage: "Unspecified error",
 			},
-			want: "OPCError [0x10]: Unspecified error",
+			want: "OPCError [0x00000010]: Unspecified error",
 		},
 		{
 			name: "TestOPCError_Error",
@@ -34,7 +36,7 @@ func TestOPCError_Error(t *testing.T) {
 				ErrorCode:    int32(-1073479679),
 				ErrorMessage: "",
 			},
-			want: "OPCError [0xc0040001]: The value of the handle is invalid",
+			want: "OPCError [0xC0040001]: The value of the handle is invalid",
 		},
 		{
 			name: "TestOPCError_Error",
@@ -42,7 +44,7 @@ func TestOPCError_Error(t *testing.T) {
 				ErrorCode:    int32(-1),
 				ErrorMessage: "",
 			},
-			want: "OPCError [0xffffffff]: unknown error",
+			want: "OPCError [0xFFFFFFFF]: unknown error",
 		},
 	}
 	for _, tt := range tests {
@@ -174,3 +176,59 @@ func TestOPCWrapperError_Unwrap(t *testing.T) {
 		})
 	}
 }
+
+func TestOPCError_Is(t *testing.T) {
+	err := fmt.Errorf("read: %w", &OPCError{ErrorCode: int32(OPCUnknownItemID)})
+	assert.ErrorIs(t, err, com.HRESULT(OPCUnknownItemID))
+	assert.NotErrorIs(t, err, com.HRESULT(OPCInvalidHandle))
+
+	code, ok := errorCode(err)
+	assert.True(t, ok)
+	assert.Equal(t, com.HRESULT(OPCUnknownItemID), code)
+	code, ok = errorCode(NewOPCWrapperError("add group", com.HRESULT(com.E_FAIL)))
+	assert.True(t, ok)
+	assert.Equal(t, com.HRESULT(com.E_FAIL), code)
+	_, ok = errorCode(errors.New("plain"))
+	assert.False(t, ok)
+}
+
+func TestOPCError_Sentinels(t *testing.T) {
+	err := fmt.Errorf("write: %w", &OPCError{ErrorCode: int32(OPCBadRights), ErrorMessage: "Vendor: item is read only"})
+	assert.ErrorIs(t, err, ErrBadRights)
+	assert.NotErrorIs(t, err, ErrUnknownItemID)
+	var opcErr *OPCError
+	assert.ErrorAs(t, err, &opcErr)
+	assert.Equal(t, "Vendor: item is read only", opcErr.ErrorMessage)
+
+	assert.NoError(t, (&OPCError{ErrorCode: int32(OPCClamp)}).Unwrap())
+	assert.NoError(t, ErrUnknownItemID.Unwrap())
+	assert.ErrorIs(t, ErrUnknownItemID, com.HRESULT(OPCUnknownItemID))
+	assert.Contains(t, ErrDeadbandNotSet.Error(), "deadband has not been set")
+}
+
+func TestOPCGroup_getError_Sentinel_Mocked(t *testing.T) {
+	server := newOPCServerWithProvider(&mockServerProvider{
+		GetErrorStringFn: func(errorCode uint32) (string, error) {
+			return "Unknown tag", nil
+		},
+	}, "mock", "localhost")
+	group := newInflightTestGroup(server, &mockGroupProvider{})
+
+	err := group.getError(int32(OPCUnknownItemID))
+	assert.ErrorIs(t, err, ErrUnknownItemID)
+	assert.EqualError(t, err, "OPCError [0xC0040007]: Unknown tag")
+}
+
+func TestOPCError_HRESULT(t *testing.T) {
+	err := fmt.Errorf("add items: %w", &OPCError{ErrorCode: int32(OPCUnknownItemID)})
+	var opcErr *OPCError
+	assert.True(t, errors.As(err, &opcErr))
+	assert.Equal(t, com.HRESULT(OPCUnknownItemID), opcErr.HRESULT())
+	assert.False(t, opcErr.IsSuccess())
+	assert.False(t, opcErr.IsGood())
+
+	clamp := &OPCError{ErrorCode: int32(OPCClamp)}
+	assert.True(t, clamp.IsSuccess())
+	assert.False(t, clamp.IsGood())
+	assert.True(t, (&OPCError{}).IsGood())
+}
@@ -6,7 +6,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 	"unsafe"
 
@@ -116,25 +118,94 @@ type OPCGroup struct {
 	parent             *OPCGroups
 	provider           serverProvider
 	groupProvider      groupProvider
+	samplingMgt        itemSamplingMgtProvider
+	deadbandMgt        itemDeadbandMgtProvider
 	clientGroupHandle  uint32
 	serverGroupHandle  uint32
 	groupName          string
 	revisedUpdateRate  uint32
 	items              *OPCItems
 	callbackLock       sync.Mutex
-	container          *com.IConnectionPointContainer
-	point              *com.IConnectionPoint
+	point              dataCallbackPoint
 	event              *DataEventReceiver
 	cookie             uint32
 	ctx                context.Context
 	cancel             context.CancelFunc
+	loopDone           chan struct{} // loopDone is closed when the callback loop returns.
 	dataChangeList     []chan *DataChangeCallBackData
+	dataChangePolicies map[chan *DataChangeCallBackData]DeliveryPolicy
+	dataChangeUsable   map[chan *DataChangeCallBackData]bool // dataChangeUsable marks subscribers registered WithUsableQuality.
 	readCompleteList   []chan *ReadCompleteCallBackData
 	writeCompleteList  []chan *WriteCompleteCallBackData
 	cancelCompleteList []chan *CancelCompleteCallBackData
+	awaiters           map[uint32]chan interface{}
+	valueWaiters       map[chan struct{}]uint32 // valueWaiters maps the channels of awaitValue to their client handles.
+	awaitTransID       uint32
+	requested          groupState
+	uncertainPolicy    int32
+	autoUnadvise       bool
+	handleResolver     atomic.Pointer[HandleResolver]
+	latency            latencyWindow
+
+	// callback events dropped per kind, reported by CallbackStats
+	dataChangeDropped    atomic.Uint64
+	readCompleteDropped  atomic.Uint64
+	writeCompleteDropped atomic.Uint64
+
+	public bool // public tells whether the group is a public group, so Reconnect connects to it by name.
+
+	dispatchMode atomic.Int32 // dispatchMode is the DispatchMode of the callback loop.
+
+	serializeItemIO atomic.Bool // serializeItemIO makes item Read and Write calls wait for each other.
+
+	cancelTransactions map[uint32]uint32 // cancelTransactions maps cancel IDs of outstanding async transactions to their transaction IDs.
+
+	dataChangeGates map[chan *DataChangeCallBackData]*snapshotGate // dataChangeGates hold back live updates until initial snapshots are delivered.
+
+	syncIO2Lock     sync.Mutex      // syncIO2Lock guards the lazily queried IOPCSyncIO2 interface.
+	syncIO2Provider syncIO2Provider // syncIO2Provider is the IOPCSyncIO2 interface, once queried.
+	syncIO2Err      error           // syncIO2Err is the error of the IOPCSyncIO2 query, once queried.
+
+	allowForceUnadvise atomic.Bool // allowForceUnadvise enables disconnecting other callback connections of the group.
+
+	deviceRefresh deviceRefresher // deviceRefresh issues the periodic device refreshes requested with ForceDeviceRefreshEvery.
+
+	stateLock sync.Mutex // stateLock serializes the transitions of state.
+	state     GroupState // state is the lifecycle state of the group; zero means GroupCreated.
+}
+
+// groupState holds the group settings requested by the client, used to recreate the group on Reconnect.
+type groupState struct {
+	active     bool
+	updateRate uint32
+	timeBias   int32
+	deadband   float32
+	localeID   uint32
+
+	timeBiasSet bool // timeBiasSet tells whether timeBias was chosen by the client rather than left to the server.
+	deadbandSet bool // deadbandSet tells whether deadband was chosen by the client rather than left to the server.
+}
+
+// timeBiasArg returns the time bias to request from AddGroup, or nil to leave it to the server.
+func (s *groupState) timeBiasArg() *int32 {
+	if !s.timeBiasSet {
+		return nil
+	}
+	timeBias := s.timeBias
+	return &timeBias
+}
+
+// deadbandArg returns the deadband to request from AddGroup, or nil to leave it to the server.
+func (s *groupState) deadbandArg() *float32 {
+	if !s.deadbandSet {
+		return nil
+	}
+	deadband := s.deadband
+	return &deadband
 }
 
 // NewOPCGroup creates a new OPCGroup instance.
+// If any step fails, the interfaces queried so far are released; iUnknown stays owned by the caller.
 func NewOPCGroup(
 	opcGroups *OPCGroups,
 	iUnknown *com.IUnknown,
@@ -142,30 +213,34 @@ func NewOPCGroup(
 	serverGroupHandle uint32,
 	groupName string,
 	revisedUpdateRate uint32,
-) (*OPCGroup, error) {
+) (o *OPCGroup, err error) {
 	if iUnknown == nil {
 		return nil, errors.New("nil interface")
 	}
-	var iUnknownSyncIO *com.IUnknown
-	err := iUnknown.QueryInterface(&com.IID_IOPCSyncIO, unsafe.Pointer(&iUnknownSyncIO))
+	var c cleanup
+	defer c.done(&err)
+	iUnknownSyncIO, err := queryInterface(iUnknown, &com.IID_IOPCSyncIO)
 	if err != nil {
 		return nil, NewOPCWrapperError("query interface IOPCSyncIO", err)
 	}
-	var iUnknownAsyncIO2 *com.IUnknown
-	err = iUnknown.QueryInterface(&com.IID_IOPCAsyncIO2, unsafe.Pointer(&iUnknownAsyncIO2))
+	c.addInterface(iUnknownSyncIO)
+	iUnknownAsyncIO2, err := queryInterface(iUnknown, &com.IID_IOPCAsyncIO2)
 	if err != nil {
-		iUnknownSyncIO.Release()
 		return nil, NewOPCWrapperError("query interface IOPCAsyncIO2", err)
 	}
-	var iUnknownItemMgt *com.IUnknown
-	err = iUnknown.QueryInterface(&com.IID_IOPCItemMgt, unsafe.Pointer(&iUnknownItemMgt))
+	c.addInterface(iUnknownAsyncIO2)
+	iUnknownItemMgt, err := queryInterface(iUnknown, &com.IID_IOPCItemMgt)
 	if err != nil {
-		iUnknownSyncIO.Release()
-		iUnknownAsyncIO2.Release()
 		return nil, NewOPCWrapperError("query interface IOPCItemMgt", err)
 	}
+	c.addInterface(iUnknownItemMgt)
+	authInfo := opcGroups.authInfo()
+	err = setProxyBlankets(authInfo, iUnknown, iUnknownSyncIO, iUnknownAsyncIO2, iUnknownItemMgt)
+	if err != nil {
+		return nil, NewOPCWrapperError("set proxy blanket group", err)
+	}
 
-	o := &OPCGroup{
+	o = &OPCGroup{
 		parent: opcGroups,
 		groupProvider: &comGroupProvider{
 			groupStateMgt: &com.IOPCGroupStateMgt{IUnknown: iUnknown},
@@ -178,11 +253,34 @@ func NewOPCGroup(
 		revisedUpdateRate: revisedUpdateRate,
 		provider:          opcGroups.provider,
 	}
+	// IOPCItemSamplingMgt and IOPCItemDeadbandMgt are optional (OPC DA 3.0); a nil provider means unsupported.
+	if iUnknownSamplingMgt := queryOptionalInterface(iUnknown, &com.IID_IOPCItemSamplingMgt, authInfo); iUnknownSamplingMgt != nil {
+		c.addInterface(iUnknownSamplingMgt)
+		o.samplingMgt = &comItemSamplingMgtProvider{samplingMgt: &com.IOPCItemSamplingMgt{IUnknown: iUnknownSamplingMgt}}
+	}
+	if iUnknownDeadbandMgt := queryOptionalInterface(iUnknown, &com.IID_IOPCItemDeadbandMgt, authInfo); iUnknownDeadbandMgt != nil {
+		c.addInterface(iUnknownDeadbandMgt)
+		o.deadbandMgt = &comItemDeadbandMgtProvider{deadbandMgt: &com.IOPCItemDeadbandMgt{IUnknown: iUnknownDeadbandMgt}}
+	}
 	itemMgt := &comItemMgtProvider{itemMgt: &com.IOPCItemMgt{IUnknown: iUnknownItemMgt}}
 	o.items = NewOPCItems(o, itemMgt, opcGroups.provider)
 	return o, nil
 }
 
+// queryOptionalInterface queries an optional interface of a group and applies authInfo to it.
+// It returns nil if the group does not implement the interface or the proxy cannot be secured.
+func queryOptionalInterface(iUnknown *com.IUnknown, iid *windows.GUID, authInfo *com.COAUTHINFO) *com.IUnknown {
+	itf, err := queryInterface(iUnknown, iid)
+	if err != nil || itf == nil {
+		return nil
+	}
+	if setProxyBlanket(itf, authInfo) != nil {
+		releaseInterface(itf)
+		return nil
+	}
+	return itf
+}
+
 // GetParent returns a reference to the parent OPCServer object.
 func (g *OPCGroup) GetParent() *OPCGroups {
 	if g == nil {
@@ -224,14 +322,13 @@ func (g *OPCGroup) GetIsActive() bool {
 	return b
 }
 
-// SetIsActive sets whether the group is active.
+// SetIsActive sets whether the group is active. It is equivalent to Activate or Deactivate and fails
+// with ErrInvalidGroupTransition on a released group.
 func (g *OPCGroup) SetIsActive(isActive bool) error {
-	if g == nil || g.groupProvider == nil {
-		return errors.New("uninitialized group")
+	if isActive {
+		return g.Activate()
 	}
-	v := com.BoolToComBOOL(isActive)
-	_, err := g.groupProvider.SetState(nil, &v, nil, nil, nil, nil)
-	return err
+	return g.Deactivate()
 }
 
 // GetClientHandle returns the client handle associated with the group.
@@ -278,7 +375,11 @@ func (g *OPCGroup) SetLocaleID(id uint32) error {
 		return errors.New("uninitialized group")
 	}
 	_, err := g.groupProvider.SetState(nil, nil, nil, nil, &id, nil)
-	return err
+	if err != nil {
+		return err
+	}
+	g.requested.localeID = id
+	return nil
 }
 
 // GetTimeBias returns the time bias for the group.
@@ -297,7 +398,12 @@ func (g *OPCGroup) SetTimeBias(timeBias int32) error {
 		return errors.New("uninitialized group")
 	}
 	_, err := g.groupProvider.SetState(nil, nil, &timeBias, nil, nil, nil)
-	return err
+	if err != nil {
+		return err
+	}
+	g.requested.timeBias = timeBias
+	g.requested.timeBiasSet = true
+	return nil
 }
 
 // GetDeadband returns the deadband for the group.
@@ -316,7 +422,12 @@ func (g *OPCGroup) SetDeadband(deadband float32) error {
 		return errors.New("uninitialized group")
 	}
 	_, err := g.groupProvider.SetState(nil, nil, nil, &deadband, nil, nil)
-	return err
+	if err != nil {
+		return err
+	}
+	g.requested.deadband = deadband
+	g.requested.deadbandSet = true
+	return nil
 }
 
 // GetUpdateRate returns the update rate for the group.
@@ -339,8 +450,31 @@ func (g *OPCGroup) SetUpdateRate(updateRate uint32) error {
 	if g == nil || g.groupProvider == nil {
 		return errors.New("uninitialized group")
 	}
-	_, err := g.groupProvider.SetState(&updateRate, nil, nil, nil, nil, nil)
-	return err
+	revised, err := g.groupProvider.SetState(&updateRate, nil, nil, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	g.requested.updateRate = updateRate
+	g.revisedUpdateRate = revised
+	return nil
+}
+
+// GetRequestedUpdateRate returns the update rate, in milliseconds, that was last requested for the group
+// when it was added or by SetUpdateRate.
+func (g *OPCGroup) GetRequestedUpdateRate() uint32 {
+	if g == nil {
+		return 0
+	}
+	return g.requested.updateRate
+}
+
+// GetRevisedUpdateRate returns the update rate, in milliseconds, the server chose for the group when it was
+// added or when the rate was last changed by SetUpdateRate. Unlike GetUpdateRate it does not call the server.
+func (g *OPCGroup) GetRevisedUpdateRate() uint32 {
+	if g == nil {
+		return 0
+	}
+	return g.revisedUpdateRate
 }
 
 // OPCItems A collection of OPCItem objects
@@ -352,10 +486,20 @@ func (g *OPCGroup) OPCItems() *OPCItems {
 }
 
 // SyncRead reads the value, quality and timestamp information for one or more items in a group.
+// Device reads on a group that also feeds a subscription can disturb the update cycle of some servers.
+// Keep one-shot OPC_DS_DEVICE reads in a separate inactive group and subscriptions in an active one;
+// device reads do not require the items or the group to be active. OPCServer.ReadDevice reads through
+// such a group maintained by the server connection.
+// A value whose VARIANT type cannot be converted is returned as its raw com.VT, with a
+// *com.ErrUnsupportedVariant in the matching error entry; the other items are unaffected.
+// An empty serverHandles returns empty results without calling the server.
 func (g *OPCGroup) SyncRead(source com.OPCDATASOURCE, serverHandles []uint32) ([]*com.ItemState, []error, error) {
 	if g == nil || g.groupProvider == nil {
 		return nil, nil, errors.New("uninitialized group")
 	}
+	if len(serverHandles) == 0 {
+		return nil, nil, nil
+	}
 	values, errList, err := g.groupProvider.SyncRead(source, serverHandles)
 	if err != nil {
 		return nil, nil, err
@@ -367,15 +511,23 @@ func (g *OPCGroup) SyncRead(source com.OPCDATASOURCE, serverHandles []uint32) ([
 			resultErrs[i] = g.getError(e)
 		}
 	}
-
+	splitUnsupportedStates(values, resultErrs)
 	return values, resultErrs, nil
 }
 
 // SyncWrite Writes values to one or more items in a group
+// values must hold one value per server handle. An empty serverHandles returns empty results without
+// calling the server.
 func (g *OPCGroup) SyncWrite(serverHandles []uint32, values []interface{}) ([]error, error) {
 	if g == nil || g.groupProvider == nil {
 		return nil, errors.New("uninitialized group")
 	}
+	if len(values) != len(serverHandles) {
+		return nil, fmt.Errorf("got %d values for %d server handles", len(values), len(serverHandles))
+	}
+	if len(serverHandles) == 0 {
+		return nil, nil
+	}
 	variants := make([]com.VARIANT, len(values))
 	variantWrappers := make([]*com.VariantWrapper, len(values))
 	defer func() {
@@ -409,26 +561,70 @@ func (g *OPCGroup) SyncWrite(serverHandles []uint32, values []interface{}) ([]er
 	return errs, nil
 }
 
+// stopCallbackLoop stops forwarding callbacks to subscribers and waits for the callback loop to return,
+// so nothing is dispatched while the group is torn down. It must not be called from the callback loop.
+func (g *OPCGroup) stopCallbackLoop() {
+	if g == nil {
+		return
+	}
+	g.callbackLock.Lock()
+	cancel, done := g.cancel, g.loopDone
+	g.callbackLock.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	waitLoop(done)
+}
+
+// waitLoop waits for a stopped callback loop to return. The caller must not hold callbackLock, which the
+// loop takes to dispatch.
+func waitLoop(done chan struct{}) {
+	if done != nil {
+		<-done
+	}
+}
+
 // Release Releases the resources used by the group
 func (g *OPCGroup) Release() {
 	if g == nil {
 		return
 	}
+	g.stateLock.Lock()
+	g.state = GroupReleased
+	g.stateLock.Unlock()
 	if g.event != nil {
-		g.point.Unadvise(g.cookie)
-		g.point.Release()
-		g.container.Release()
+		g.runtime().call(func() error {
+			g.point.Unadvise(g.cookie)
+			g.point.Release()
+			return nil
+		})
 		g.event = nil
 	}
 	if g.cancel != nil {
 		g.cancel()
 	}
+	if l := g.inflightLimiter(); l != nil {
+		l.releaseGroup(g.serverGroupHandle)
+	}
+	g.pauseDeviceRefresh()
 	if g.items != nil {
 		g.items.Release()
 	}
+	if g.samplingMgt != nil {
+		g.samplingMgt.Release()
+	}
+	if g.deadbandMgt != nil {
+		g.deadbandMgt.Release()
+	}
+	g.releaseSyncIO2()
 	if g.groupProvider != nil {
 		g.groupProvider.Release()
 	}
+	// outstanding transactions do not survive the released interfaces
+	g.callbackLock.Lock()
+	g.cancelTransactions = nil
+	g.callbackLock.Unlock()
+	g.openAllGates()
 }
 
 type DataChangeCallBackData struct {
@@ -441,21 +637,17 @@ type DataChangeCallBackData struct {
 	Qualities         []uint16
 	TimeStamps        []time.Time
 	Errors            []error
+	// ReceivedAt is when the callback reached the client, before it was queued for dispatch.
+	ReceivedAt time.Time
+	// Initial marks the synthetic data change carrying the current values, delivered once to a
+	// subscriber registered with WithInitialSnapshot before any live update.
+	Initial bool
 }
 
 // RegisterDataChange Register to receive data change events
+// Events that do not fit into ch are dropped; use RegisterDataChangeWithPolicy to choose another policy.
 func (g *OPCGroup) RegisterDataChange(ch chan *DataChangeCallBackData) error {
-	if g == nil {
-		return errors.New("uninitialized group")
-	}
-	err := g.advise()
-	if err != nil {
-		return err
-	}
-	g.callbackLock.Lock()
-	defer g.callbackLock.Unlock()
-	g.dataChangeList = append(g.dataChangeList, ch)
-	return nil
+	return g.RegisterDataChangeWithPolicy(ch, DeliveryDropNewest)
 }
 
 // RegisterReadComplete Register to receive read complete events
@@ -463,14 +655,9 @@ func (g *OPCGroup) RegisterReadComplete(ch chan *ReadCompleteCallBackData) error
 	if g == nil {
 		return errors.New("uninitialized group")
 	}
-	err := g.advise()
-	if err != nil {
-		return err
-	}
-	g.callbackLock.Lock()
-	defer g.callbackLock.Unlock()
-	g.readCompleteList = append(g.readCompleteList, ch)
-	return nil
+	return g.adviseAnd(func() {
+		g.readCompleteList = append(g.readCompleteList, ch)
+	})
 }
 
 // RegisterWriteComplete Register to receive write complete events
@@ -478,14 +665,9 @@ func (g *OPCGroup) RegisterWriteComplete(ch chan *WriteCompleteCallBackData) err
 	if g == nil {
 		return errors.New("uninitialized group")
 	}
-	err := g.advise()
-	if err != nil {
-		return err
-	}
-	g.callbackLock.Lock()
-	defer g.callbackLock.Unlock()
-	g.writeCompleteList = append(g.writeCompleteList, ch)
-	return nil
+	return g.adviseAnd(func() {
+		g.writeCompleteList = append(g.writeCompleteList, ch)
+	})
 }
 
 // RegisterCancelComplete Register to receive cancel complete events
@@ -493,16 +675,111 @@ func (g *OPCGroup) RegisterCancelComplete(ch chan *CancelCompleteCallBackData) e
 	if g == nil {
 		return errors.New("uninitialized group")
 	}
-	err := g.advise()
-	if err != nil {
-		return err
+	return g.adviseAnd(func() {
+		g.cancelCompleteList = append(g.cancelCompleteList, ch)
+	})
+}
+
+// ErrChannelNotRegistered is returned when unregistering a channel that is not registered with the group.
+var ErrChannelNotRegistered = errors.New("channel not registered")
+
+// UnregisterDataChange stops sending data change events to a channel registered with RegisterDataChange.
+// The channel is not closed.
+func (g *OPCGroup) UnregisterDataChange(ch chan *DataChangeCallBackData) error {
+	if g == nil {
+		return errors.New("uninitialized group")
+	}
+	return g.unregister(func() (ok bool) {
+		g.dataChangeList, ok = removeChannel(g.dataChangeList, ch)
+		if !slices.Contains(g.dataChangeList, ch) {
+			delete(g.dataChangePolicies, ch)
+			delete(g.dataChangeUsable, ch)
+			g.dropGate(ch)
+		}
+		return
+	})
+}
+
+// UnregisterReadComplete stops sending read complete events to a channel registered with RegisterReadComplete.
+// The channel is not closed.
+func (g *OPCGroup) UnregisterReadComplete(ch chan *ReadCompleteCallBackData) error {
+	if g == nil {
+		return errors.New("uninitialized group")
+	}
+	return g.unregister(func() (ok bool) {
+		g.readCompleteList, ok = removeChannel(g.readCompleteList, ch)
+		return
+	})
+}
+
+// UnregisterWriteComplete stops sending write complete events to a channel registered with RegisterWriteComplete.
+// The channel is not closed.
+func (g *OPCGroup) UnregisterWriteComplete(ch chan *WriteCompleteCallBackData) error {
+	if g == nil {
+		return errors.New("uninitialized group")
+	}
+	return g.unregister(func() (ok bool) {
+		g.writeCompleteList, ok = removeChannel(g.writeCompleteList, ch)
+		return
+	})
+}
+
+// UnregisterCancelComplete stops sending cancel complete events to a channel registered with RegisterCancelComplete.
+// The channel is not closed.
+func (g *OPCGroup) UnregisterCancelComplete(ch chan *CancelCompleteCallBackData) error {
+	if g == nil {
+		return errors.New("uninitialized group")
+	}
+	return g.unregister(func() (ok bool) {
+		g.cancelCompleteList, ok = removeChannel(g.cancelCompleteList, ch)
+		return
+	})
+}
+
+// SetAutoUnadvise controls whether the group stops the server callbacks when the last registered channel
+// is unregistered and no awaited operation is pending. It is off by default; registering a channel again
+// re-advises the group. Leave it off while async operations are issued without a registered channel,
+// since their completions are then no longer delivered.
+func (g *OPCGroup) SetAutoUnadvise(enabled bool) error {
+	if g == nil {
+		return errors.New("uninitialized group")
 	}
 	g.callbackLock.Lock()
 	defer g.callbackLock.Unlock()
-	g.cancelCompleteList = append(g.cancelCompleteList, ch)
+	g.autoUnadvise = enabled
 	return nil
 }
 
+// removeChannel removes the first occurrence of ch from list, reporting whether it was found.
+func removeChannel[T any](list []chan T, ch chan T) ([]chan T, bool) {
+	for i, c := range list {
+		if c == ch {
+			return append(list[:i], list[i+1:]...), true
+		}
+	}
+	return list, false
+}
+
+// unregister removes a channel with remove, which runs under callbackLock and reports whether the channel
+// was registered. When the group has become idle and auto-unadvise is enabled, the server callbacks are
+// stopped and unregister waits for the callback loop to return, so it must not be called from the loop.
+func (g *OPCGroup) unregister(remove func() bool) error {
+	g.callbackLock.Lock()
+	if !remove() {
+		g.callbackLock.Unlock()
+		return ErrChannelNotRegistered
+	}
+	if !g.autoUnadvise || g.event == nil ||
+		len(g.dataChangeList)+len(g.readCompleteList)+len(g.writeCompleteList)+len(g.cancelCompleteList)+len(g.awaiters) > 0 {
+		g.callbackLock.Unlock()
+		return nil
+	}
+	done, err := g.unadvise()
+	g.callbackLock.Unlock()
+	waitLoop(done)
+	return err
+}
+
 type ReadCompleteCallBackData struct {
 	TransID           uint32
 	GroupHandle       uint32
@@ -528,15 +805,35 @@ type CancelCompleteCallBackData struct {
 	GroupHandle uint32
 }
 
-func (g *OPCGroup) advise() (err error) {
+// advise connects the data callback of the group unless it is connected already.
+func (g *OPCGroup) advise() error {
+	return g.adviseAnd(nil)
+}
+
+// adviseAnd connects the data callback of the group unless it is connected already and then calls
+// register, if not nil, under the same hold of callbackLock, so the group cannot be unadvised before what
+// register adds counts as a subscriber.
+func (g *OPCGroup) adviseAnd(register func()) error {
 	if g == nil || g.groupProvider == nil {
 		return errors.New("uninitialized group")
 	}
 	g.callbackLock.Lock()
 	defer g.callbackLock.Unlock()
-	if g.event != nil {
-		return nil
+	if g.event == nil {
+		err := g.runtime().call(g.adviseLocked)
+		if err != nil {
+			return err
+		}
+	}
+	if register != nil {
+		register()
 	}
+	return nil
+}
+
+// adviseLocked connects the data callback of the group and starts its callback loop. The caller must hold
+// callbackLock.
+func (g *OPCGroup) adviseLocked() (err error) {
 	var iUnknownContainer *com.IUnknown
 	err = g.groupProvider.QueryInterface(&com.IID_IConnectionPointContainer, unsafe.Pointer(&iUnknownContainer))
 	if err != nil {
@@ -547,12 +844,26 @@ func (g *OPCGroup) advise() (err error) {
 			iUnknownContainer.Release()
 		}
 	}()
+	authInfo := g.parent.authInfo()
+	err = setProxyBlanket(iUnknownContainer, authInfo)
+	if err != nil {
+		return NewOPCWrapperError("set proxy blanket IConnectionPointContainer", err)
+	}
 	container := &com.IConnectionPointContainer{IUnknown: iUnknownContainer}
 	var point *com.IConnectionPoint
 	point, err = container.FindConnectionPoint(&IID_IOPCDataCallback)
 	if err != nil {
 		return
 	}
+	defer func() {
+		if err != nil {
+			point.Release()
+		}
+	}()
+	err = setProxyBlanket(point.IUnknown, authInfo)
+	if err != nil {
+		return NewOPCWrapperError("set proxy blanket IConnectionPoint", err)
+	}
 	dataChangeCB := make(chan *CDataChangeCallBackData, 100)
 	readCB := make(chan *CReadCompleteCallBackData, 100)
 	writeCB := make(chan *CWriteCompleteCallBackData, 100)
@@ -560,25 +871,83 @@ func (g *OPCGroup) advise() (err error) {
 	event := NewDataEventReceiver(dataChangeCB, readCB, writeCB, cancelCB)
 	var cookie uint32
 	cookie, err = point.Advise((*com.IUnknown)(unsafe.Pointer(event)))
+	if errors.Is(err, com.HRESULT(com.CONNECT_E_ADVISELIMIT)) && g.allowForceUnadvise.Load() {
+		// stale connections of crashed clients fill the connection point
+		if _, forceErr := g.forceUnadvise(); forceErr == nil {
+			cookie, err = point.Advise((*com.IUnknown)(unsafe.Pointer(event)))
+		}
+	}
 	if err != nil {
 		return
 	}
-	g.ctx, g.cancel = context.WithCancel(context.Background())
-	go g.loop(g.ctx, dataChangeCB, readCB, writeCB, cancelCB)
-	g.container = container
-	g.point = point
+	g.startLoop(dataChangeCB, readCB, writeCB, cancelCB)
+	g.point = &comDataCallbackPoint{container: container, point: point}
 	g.event = event
 	g.cookie = cookie
 	return
 }
 
+// unadvise disconnects the data callback and stops the callback loop. It returns the channel closed when
+// the loop has returned, which the caller waits on with waitLoop after releasing callbackLock. The caller
+// must hold callbackLock.
+func (g *OPCGroup) unadvise() (chan struct{}, error) {
+	err := g.runtime().call(func() error {
+		err := g.point.Unadvise(g.cookie)
+		g.point.Release()
+		return err
+	})
+	g.point = nil
+	g.event = nil
+	g.cookie = 0
+	if g.cancel != nil {
+		g.cancel()
+	}
+	return g.loopDone, err
+}
+
+// detachCallbacks unadvises the data callback of an advised group and waits for its callback loop to return,
+// so the server holds no reference to the group through the connection point. It reports whether the group
+// was advised.
+func (g *OPCGroup) detachCallbacks() bool {
+	g.callbackLock.Lock()
+	advised := g.event != nil
+	var done chan struct{}
+	if advised {
+		done, _ = g.unadvise()
+	}
+	g.callbackLock.Unlock()
+	waitLoop(done)
+	return advised
+}
+
+// startLoop starts the goroutine forwarding callbacks to subscribers. Its context derives from the
+// root context of the server, so Disconnect stops it even before the group is released. Each loop
+// passes its own context on to the deliveries it makes. The caller must hold callbackLock.
+func (g *OPCGroup) startLoop(dataChangeCB chan *CDataChangeCallBackData, readCB chan *CReadCompleteCallBackData, writeCB chan *CWriteCompleteCallBackData, cancelCB chan *CCancelCompleteCallBackData) {
+	parent := context.Background()
+	if g.parent != nil {
+		parent = g.parent.parent.rootContext()
+	}
+	g.ctx, g.cancel = context.WithCancel(parent)
+	ctx, done := g.ctx, make(chan struct{})
+	g.loopDone = done
+	go func() {
+		defer close(done)
+		if g.GetDispatchMode() == DispatchPerKind {
+			g.loopPerKind(ctx, dataChangeCB, readCB, writeCB, cancelCB)
+			return
+		}
+		g.loop(ctx, dataChangeCB, readCB, writeCB, cancelCB)
+	}()
+}
+
 func (g *OPCGroup) loop(ctx context.Context, dataChangeCB chan *CDataChangeCallBackData, readCB chan *CReadCompleteCallBackData, writeCB chan *CWriteCompleteCallBackData, cancelCB chan *CCancelCompleteCallBackData) {
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case cbData := <-dataChangeCB:
-			g.fireDataChange(cbData)
+			g.fireDataChange(ctx, cbData)
 		case cbData := <-readCB:
 			g.fireReadComplete(cbData)
 		case cbData := <-writeCB:
@@ -589,10 +958,16 @@ func (g *OPCGroup) loop(ctx context.Context, dataChangeCB chan *CDataChangeCallB
 	}
 }
 
-func (g *OPCGroup) fireDataChange(cbData *CDataChangeCallBackData) {
+// fireDataChange forwards a data change callback to the subscribers. Deliveries that wait for a subscriber
+// give up when ctx, the context of the callback loop, is done.
+func (g *OPCGroup) fireDataChange(ctx context.Context, cbData *CDataChangeCallBackData) {
 	if g == nil {
 		return
 	}
+	if cbData.TransID != 0 {
+		// a non-zero transaction ID marks the completion of an AsyncRefresh
+		g.completeTransaction(cbData.TransID)
+	}
 	masterError := error(nil)
 	if (cbData.MasterErr) < 0 {
 		masterError = g.getError(cbData.MasterErr)
@@ -603,6 +978,7 @@ func (g *OPCGroup) fireDataChange(cbData *CDataChangeCallBackData) {
 			itemErrors[i] = g.getError(e)
 		}
 	}
+	splitUnsupportedValues(cbData.Values, itemErrors)
 	data := &DataChangeCallBackData{
 		TransID:           cbData.TransID,
 		GroupHandle:       cbData.GroupHandle,
@@ -613,17 +989,47 @@ func (g *OPCGroup) fireDataChange(cbData *CDataChangeCallBackData) {
 		Qualities:         cbData.Qualities,
 		TimeStamps:        cbData.TimeStamps,
 		Errors:            itemErrors,
+		ReceivedAt:        cbData.ReceivedAt,
+	}
+	g.recordLatency(data)
+	g.bufferDataChange(data)
+	g.notifyValueWaiters(data)
+	if data.TransID != 0 {
+		g.notifyAwaiter(data.TransID, data)
 	}
 	g.callbackLock.Lock()
-	listeners := make([]chan *DataChangeCallBackData, len(g.dataChangeList))
-	copy(listeners, g.dataChangeList)
+	owner := g.snapshotOwner(data.TransID)
+	var listeners []chan *DataChangeCallBackData
+	var policies []DeliveryPolicy
+	var usable []bool
+	// the refresh of an initial snapshot is delivered to its subscriber only
+	if owner == nil {
+		for _, ch := range g.dataChangeList {
+			if gate := g.dataChangeGates[ch]; gate != nil {
+				gate.backlog = append(gate.backlog, data)
+				continue
+			}
+			listeners = append(listeners, ch)
+			policies = append(policies, g.dataChangePolicies[ch])
+			usable = append(usable, g.dataChangeUsable[ch])
+		}
+	}
 	g.callbackLock.Unlock()
 
-	for _, backData := range listeners {
-		select {
-		case backData <- data:
-		default:
+	if owner != nil {
+		initial := *data
+		initial.Initial = true
+		go g.openGate(owner, &initial)
+		return
+	}
+	for i, backData := range listeners {
+		delivered := data
+		if usable[i] {
+			if delivered = g.usableOnly(data); len(delivered.ItemClientHandles) == 0 {
+				continue
+			}
 		}
+		g.deliverDataChange(ctx, backData, delivered, policies[i])
 	}
 }
 
@@ -631,6 +1037,7 @@ func (g *OPCGroup) fireReadComplete(cbData *CReadCompleteCallBackData) {
 	if g == nil {
 		return
 	}
+	g.completeTransaction(cbData.TransID)
 	masterError := error(nil)
 	if (cbData.MasterErr) < 0 {
 		masterError = g.getError(cbData.MasterErr)
@@ -641,6 +1048,7 @@ func (g *OPCGroup) fireReadComplete(cbData *CReadCompleteCallBackData) {
 			itemErrors[i] = g.getError(e)
 		}
 	}
+	splitUnsupportedValues(cbData.Values, itemErrors)
 	data := &ReadCompleteCallBackData{
 		TransID:           cbData.TransID,
 		GroupHandle:       cbData.GroupHandle,
@@ -652,6 +1060,7 @@ func (g *OPCGroup) fireReadComplete(cbData *CReadCompleteCallBackData) {
 		TimeStamps:        cbData.TimeStamps,
 		Errors:            itemErrors,
 	}
+	g.notifyAwaiter(data.TransID, data)
 	g.callbackLock.Lock()
 	listeners := make([]chan *ReadCompleteCallBackData, len(g.readCompleteList))
 	copy(listeners, g.readCompleteList)
@@ -661,6 +1070,7 @@ func (g *OPCGroup) fireReadComplete(cbData *CReadCompleteCallBackData) {
 		select {
 		case backData <- data:
 		default:
+			g.readCompleteDropped.Add(1)
 		}
 	}
 }
@@ -669,6 +1079,7 @@ func (g *OPCGroup) fireWriteComplete(cbData *CWriteCompleteCallBackData) {
 	if g == nil {
 		return
 	}
+	g.completeTransaction(cbData.TransID)
 	masterError := error(nil)
 	if (cbData.MasterErr) < 0 {
 		masterError = g.getError(cbData.MasterErr)
@@ -686,6 +1097,7 @@ func (g *OPCGroup) fireWriteComplete(cbData *CWriteCompleteCallBackData) {
 		ItemClientHandles: cbData.ItemClientHandles,
 		Errors:            itemErrors,
 	}
+	g.notifyAwaiter(data.TransID, data)
 	g.callbackLock.Lock()
 	listeners := make([]chan *WriteCompleteCallBackData, len(g.writeCompleteList))
 	copy(listeners, g.writeCompleteList)
@@ -695,6 +1107,7 @@ func (g *OPCGroup) fireWriteComplete(cbData *CWriteCompleteCallBackData) {
 		select {
 		case backData <- data:
 		default:
+			g.writeCompleteDropped.Add(1)
 		}
 	}
 }
@@ -703,10 +1116,12 @@ func (g *OPCGroup) fireCancelComplete(cbData *CCancelCompleteCallBackData) {
 	if g == nil {
 		return
 	}
+	g.completeTransaction(cbData.TransID)
 	data := &CancelCompleteCallBackData{
 		TransID:     cbData.TransID,
 		GroupHandle: cbData.GroupHandle,
 	}
+	g.notifyAwaiter(data.TransID, data)
 	g.callbackLock.Lock()
 	listeners := make([]chan *CancelCompleteCallBackData, len(g.cancelCompleteList))
 	copy(listeners, g.cancelCompleteList)
@@ -718,6 +1133,8 @@ func (g *OPCGroup) fireCancelComplete(cbData *CCancelCompleteCallBackData) {
 }
 
 // AsyncRead Read one or more items in a group. The results are returned via the AsyncReadComplete event associated with the OPCGroup object.
+// An empty serverHandles returns a zero cancel ID and empty results without calling the server or
+// taking an in-flight slot; no completion event is fired.
 func (g *OPCGroup) AsyncRead(
 	serverHandles []uint32,
 	clientTransactionID uint32,
@@ -725,14 +1142,26 @@ func (g *OPCGroup) AsyncRead(
 	if g == nil || g.groupProvider == nil {
 		return 0, nil, errors.New("uninitialized group")
 	}
+	if len(serverHandles) == 0 {
+		return 0, nil, nil
+	}
+	err = g.acquireInflight(clientTransactionID)
+	if err != nil {
+		return 0, nil, err
+	}
 	var es []int32
 	cancelID, es, err = g.groupProvider.AsyncRead(
 		serverHandles,
 		clientTransactionID,
 	)
 	if err != nil {
+		g.releaseInflight(clientTransactionID)
 		return
 	}
+	g.releaseInflightIfNoneAccepted(clientTransactionID, es)
+	if anyAccepted(es) {
+		g.trackCancel(cancelID, clientTransactionID)
+	}
 	errs = make([]error, len(es))
 	for i, e := range es {
 		if e < 0 {
@@ -743,6 +1172,8 @@ func (g *OPCGroup) AsyncRead(
 }
 
 // AsyncWrite Write one or more items in a group. The results are returned via the AsyncWriteComplete event associated with the OPCGroup object.
+// values must hold one value per server handle. An empty serverHandles returns a zero cancel ID and empty
+// results without calling the server or taking an in-flight slot; no completion event is fired.
 func (g *OPCGroup) AsyncWrite(
 	serverHandles []uint32,
 	values []interface{},
@@ -751,6 +1182,12 @@ func (g *OPCGroup) AsyncWrite(
 	if g == nil || g.groupProvider == nil {
 		return 0, nil, errors.New("uninitialized group")
 	}
+	if len(values) != len(serverHandles) {
+		return 0, nil, fmt.Errorf("got %d values for %d server handles", len(values), len(serverHandles))
+	}
+	if len(serverHandles) == 0 {
+		return 0, nil, nil
+	}
 	variants := make([]com.VARIANT, len(values))
 	variantWrappers := make([]*com.VariantWrapper, len(values))
 
@@ -767,6 +1204,10 @@ func (g *OPCGroup) AsyncWrite(
 		variantWrappers[i] = variant
 		variants[i] = *variant.Variant
 	}
+	err = g.acquireInflight(clientTransactionID)
+	if err != nil {
+		return 0, nil, err
+	}
 	var es []int32
 	cancelID, es, err = g.groupProvider.AsyncWrite(
 		serverHandles,
@@ -774,8 +1215,13 @@ func (g *OPCGroup) AsyncWrite(
 		clientTransactionID,
 	)
 	if err != nil {
+		g.releaseInflight(clientTransactionID)
 		return
 	}
+	g.releaseInflightIfNoneAccepted(clientTransactionID, es)
+	if anyAccepted(es) {
+		g.trackCancel(cancelID, clientTransactionID)
+	}
 	errs = make([]error, len(es))
 	for i, e := range es {
 		if e < 0 {
@@ -788,6 +1234,10 @@ func (g *OPCGroup) AsyncWrite(
 // AsyncRefresh Generate an event for all active items in the group (whether they have changed or not). Inactive
 // items are not included in the callback. The results are returned via the DataChange event
 // associated with the OPCGroup object.
+//
+// The refresh completion is told apart from subscription data changes by its non-zero transaction ID.
+// A refresh with clientTransactionID 0 therefore takes no in-flight permit of SetMaxInflightAsync and
+// cannot be cancelled with AsyncCancelAwait.
 func (g *OPCGroup) AsyncRefresh(
 	source com.OPCDATASOURCE,
 	clientTransactionID uint32,
@@ -795,13 +1245,79 @@ func (g *OPCGroup) AsyncRefresh(
 	if g == nil || g.groupProvider == nil {
 		return 0, errors.New("uninitialized group")
 	}
+	return g.asyncRefresh(source, clientTransactionID, nil)
+}
+
+// asyncRefresh is AsyncRefresh with a wait for an in-flight permit that ends when cancel is closed.
+func (g *OPCGroup) asyncRefresh(source com.OPCDATASOURCE, clientTransactionID uint32, cancel <-chan struct{}) (cancelID uint32, err error) {
+	if clientTransactionID == 0 {
+		// the completion of the refresh cannot be recognized to return a permit
+		return g.groupProvider.AsyncRefresh(source, clientTransactionID)
+	}
+	err = g.acquireInflightUntil(clientTransactionID, cancel)
+	if err != nil {
+		return 0, err
+	}
 	cancelID, err = g.groupProvider.AsyncRefresh(
 		source,
 		clientTransactionID,
 	)
+	if err != nil {
+		g.releaseInflight(clientTransactionID)
+		return
+	}
+	g.trackCancel(cancelID, clientTransactionID)
 	return
 }
 
+// inflightLimiter returns the in-flight limiter of the server that owns the group, or nil.
+func (g *OPCGroup) inflightLimiter() *asyncLimiter {
+	if g.parent == nil || g.parent.parent == nil {
+		return nil
+	}
+	return g.parent.parent.inflight
+}
+
+// acquireInflight takes a server-wide in-flight permit for an async transaction of the group.
+// Permits are keyed by the server group handle, which unlike the client handle cannot change.
+func (g *OPCGroup) acquireInflight(transactionID uint32) error {
+	return g.acquireInflightUntil(transactionID, nil)
+}
+
+// acquireInflightUntil is acquireInflight with a wait for a permit that ends when cancel is closed.
+func (g *OPCGroup) acquireInflightUntil(transactionID uint32, cancel <-chan struct{}) error {
+	l := g.inflightLimiter()
+	if l == nil {
+		return nil
+	}
+	return l.acquireUntil(g.serverGroupHandle, transactionID, cancel)
+}
+
+// releaseInflight returns the in-flight permit of an async transaction of the group.
+func (g *OPCGroup) releaseInflight(transactionID uint32) {
+	if l := g.inflightLimiter(); l != nil {
+		l.release(g.serverGroupHandle, transactionID)
+	}
+}
+
+// releaseInflightIfNoneAccepted returns the permit when every item was rejected,
+// because the server sends no completion for such a transaction.
+func (g *OPCGroup) releaseInflightIfNoneAccepted(transactionID uint32, errs []int32) {
+	if !anyAccepted(errs) {
+		g.releaseInflight(transactionID)
+	}
+}
+
+// anyAccepted reports whether the server accepted at least one item of an async transaction.
+func anyAccepted(errs []int32) bool {
+	for _, e := range errs {
+		if e >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
 // AsyncCancel Request that the server cancel an outstanding transaction. An AsyncCancelComplete event will
 // occur indicating whether or not the cancel succeeded.
 func (g *OPCGroup) AsyncCancel(cancelID uint32) error {
@@ -812,12 +1328,8 @@ func (g *OPCGroup) AsyncCancel(cancelID uint32) error {
 }
 
 func (g *OPCGroup) getError(errorCode int32) error {
-	if g == nil || g.provider == nil {
-		return &OPCError{ErrorCode: errorCode, ErrorMessage: "uninitialized common interface"}
-	}
-	errStr, _ := g.provider.GetErrorString(uint32(errorCode))
-	return &OPCError{
-		ErrorCode:    errorCode,
-		ErrorMessage: errStr,
+	if g == nil {
+		return newOPCError(nil, nil, errorCode)
 	}
+	return newOPCError(g.server(), g.provider, errorCode)
 }
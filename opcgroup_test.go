@@ -3,9 +3,14 @@
 package opcda
 
 import (
+	"context"
+	"math"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/wends155/opcda/com"
+	"golang.org/x/sys/windows"
 )
 
 func TestOPCGroup_SetName_Mocked(t *testing.T) {
@@ -35,3 +40,431 @@ func TestOPCGroup_IsActive_Mocked(t *testing.T) {
 	}
 	assert.False(t, group.GetIsActive())
 }
+
+func TestOPCGroups_Add_RevisedUpdateRate_Mocked(t *testing.T) {
+	provider := &mockServerProvider{
+		AddGroupFn: func(name string, active bool, updateRate uint32, clientGroup uint32, timeBias *int32, deadband *float32, localeID uint32, iid *windows.GUID) (uint32, uint32, *com.IUnknown, error) {
+			assert.Equal(t, uint32(250), updateRate)
+			return 1, 1000, nil, nil
+		},
+	}
+	server := newOPCServerWithProvider(provider, "mock", "localhost")
+	server.factories.newGroup = func(gs *OPCGroups, iUnknown *com.IUnknown, clientGroupHandle uint32, serverGroupHandle uint32, groupName string, revisedUpdateRate uint32) (*OPCGroup, error) {
+		return &OPCGroup{
+			parent:            gs,
+			groupProvider:     &mockGroupProvider{},
+			groupName:         groupName,
+			clientGroupHandle: clientGroupHandle,
+			serverGroupHandle: serverGroupHandle,
+			revisedUpdateRate: revisedUpdateRate,
+		}, nil
+	}
+	groups := server.GetOPCGroups()
+	groups.SetDefaultGroupUpdateRate(250)
+
+	group, err := groups.Add("g1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(250), group.GetRequestedUpdateRate())
+	assert.Equal(t, uint32(1000), group.GetRevisedUpdateRate())
+}
+
+func TestOPCGroups_Add_Defaults_Mocked(t *testing.T) {
+	var timeBiases []*int32
+	var deadbands []*float32
+	provider := &mockServerProvider{
+		AddGroupFn: func(name string, active bool, updateRate uint32, clientGroup uint32, timeBias *int32, deadband *float32, localeID uint32, iid *windows.GUID) (uint32, uint32, *com.IUnknown, error) {
+			assert.False(t, active)
+			assert.Equal(t, uint32(0x0407), localeID)
+			timeBiases = append(timeBiases, timeBias)
+			deadbands = append(deadbands, deadband)
+			return clientGroup, updateRate, nil, nil
+		},
+	}
+	server := newOPCServerWithProvider(provider, "mock", "localhost")
+	server.factories.newGroup = func(gs *OPCGroups, iUnknown *com.IUnknown, clientGroupHandle uint32, serverGroupHandle uint32, groupName string, revisedUpdateRate uint32) (*OPCGroup, error) {
+		return &OPCGroup{parent: gs, groupProvider: &mockGroupProvider{}, serverGroupHandle: serverGroupHandle}, nil
+	}
+	groups := server.GetOPCGroups()
+	groups.SetDefaultGroupIsActive(false)
+	groups.SetDefaultGroupLocaleID(0x0407)
+
+	_, err := groups.Add("server defaults")
+	assert.NoError(t, err)
+	assert.Nil(t, timeBiases[0], "an unset time bias is left to the server")
+	assert.Nil(t, deadbands[0], "an unset deadband is left to the server")
+
+	assert.Error(t, groups.SetDefaultGroupDeadband(-1))
+	assert.Error(t, groups.SetDefaultGroupDeadband(100.5))
+	assert.Error(t, groups.SetDefaultGroupDeadband(float32(math.NaN())))
+	assert.NoError(t, groups.SetDefaultGroupDeadband(2.5))
+	groups.SetDefaultGroupTimeBias(-60)
+	group, err := groups.Add("configured")
+	assert.NoError(t, err)
+	if assert.NotNil(t, timeBiases[1]) && assert.NotNil(t, deadbands[1]) {
+		assert.Equal(t, int32(-60), *timeBiases[1])
+		assert.Equal(t, float32(2.5), *deadbands[1])
+	}
+	assert.Equal(t, float32(2.5), group.requested.deadband)
+	assert.True(t, group.requested.deadbandSet)
+}
+
+func TestOPCGroups_AddWithOptions_Mocked(t *testing.T) {
+	var calls int
+	provider := &mockServerProvider{
+		AddGroupFn: func(name string, active bool, updateRate uint32, clientGroup uint32, timeBias *int32, deadband *float32, localeID uint32, iid *windows.GUID) (uint32, uint32, *com.IUnknown, error) {
+			calls++
+			assert.Equal(t, "fast", name)
+			assert.True(t, active)
+			assert.Equal(t, uint32(0), updateRate)
+			assert.Equal(t, uint32(42), clientGroup)
+			assert.Nil(t, timeBias)
+			if assert.NotNil(t, deadband) {
+				assert.Equal(t, float32(0.5), *deadband)
+			}
+			assert.Equal(t, uint32(0x0409), localeID)
+			return 7, 100, nil, nil
+		},
+	}
+	server := newOPCServerWithProvider(provider, "mock", "localhost")
+	server.factories.newGroup = func(gs *OPCGroups, iUnknown *com.IUnknown, clientGroupHandle uint32, serverGroupHandle uint32, groupName string, revisedUpdateRate uint32) (*OPCGroup, error) {
+		return &OPCGroup{
+			parent:            gs,
+			groupProvider:     &mockGroupProvider{},
+			groupName:         groupName,
+			clientGroupHandle: clientGroupHandle,
+			serverGroupHandle: serverGroupHandle,
+			revisedUpdateRate: revisedUpdateRate,
+		}, nil
+	}
+	groups := server.GetOPCGroups()
+	groups.SetDefaultGroupTimeBias(-60)
+
+	deadband := float32(0.5)
+	group, err := groups.AddWithOptions("fast", GroupOptions{Deadband: &deadband, Active: true, LocaleID: 0x0409, ClientHandle: 42})
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(100), group.GetRevisedUpdateRate())
+	assert.Equal(t, uint32(42), group.GetClientHandle())
+	assert.True(t, group.requested.deadbandSet)
+	assert.False(t, group.requested.timeBiasSet, "the defaults of the collection are not applied")
+	assert.Equal(t, 1, groups.GetCount())
+
+	outside := float32(101)
+	_, err = groups.AddWithOptions("bad", GroupOptions{Deadband: &outside})
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls, "an invalid deadband must not reach the server")
+}
+
+func TestOPCGroups_Attach_Mocked(t *testing.T) {
+	var lookups []string
+	provider := &mockServerProvider{
+		GetGroupByNameFn: func(name string, iid *windows.GUID) (*com.IUnknown, error) {
+			lookups = append(lookups, name)
+			assert.Equal(t, com.IID_IOPCGroupStateMgt, *iid)
+			if name != "orphan" {
+				return nil, com.HRESULT(com.E_INVALIDARG)
+			}
+			return nil, nil
+		},
+	}
+	server := newOPCServerWithProvider(provider, "mock", "localhost")
+	server.factories.newGroup = func(gs *OPCGroups, iUnknown *com.IUnknown, clientGroupHandle uint32, serverGroupHandle uint32, groupName string, revisedUpdateRate uint32) (*OPCGroup, error) {
+		return &OPCGroup{
+			parent:    gs,
+			groupName: groupName,
+			groupProvider: &mockGroupProvider{
+				GetStateFn: func() (uint32, bool, string, int32, float32, uint32, uint32, uint32, error) {
+					return 250, false, groupName, 0, 0, 0x0409, 5, 42, nil
+				},
+			},
+		}, nil
+	}
+	groups := server.GetOPCGroups()
+
+	group, err := groups.Attach("orphan")
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(42), group.GetServerHandle())
+	assert.Equal(t, uint32(5), group.GetClientHandle())
+	assert.Equal(t, uint32(250), group.GetRevisedUpdateRate())
+	assert.False(t, group.requested.active)
+	assert.False(t, group.IsPublic())
+
+	again, err := groups.Attach("orphan")
+	assert.NoError(t, err)
+	assert.Same(t, group, again, "a group of the collection is returned without a server call")
+	assert.Equal(t, 1, groups.GetCount())
+
+	_, err = groups.Attach("missing")
+	assert.Equal(t, error(com.HRESULT(com.E_INVALIDARG)), err)
+	assert.Equal(t, []string{"orphan", "missing"}, lookups)
+	assert.Equal(t, 1, groups.GetCount())
+}
+
+func TestOPCGroup_SetUpdateRate_Revised_Mocked(t *testing.T) {
+	group := &OPCGroup{
+		groupProvider: &mockGroupProvider{
+			SetStateFn: func(pRequestedUpdateRate *uint32, pActive *int32, pTimeBias *int32, pPercentDeadband *float32, pLCID *uint32, phClientGroup *uint32) (uint32, error) {
+				return 500, nil
+			},
+		},
+		revisedUpdateRate: 1000,
+	}
+	assert.NoError(t, group.SetUpdateRate(100))
+	assert.Equal(t, uint32(100), group.GetRequestedUpdateRate())
+	assert.Equal(t, uint32(500), group.GetRevisedUpdateRate())
+
+	var nilGroup *OPCGroup
+	assert.Equal(t, uint32(0), nilGroup.GetRevisedUpdateRate())
+	assert.Equal(t, uint32(0), nilGroup.GetRequestedUpdateRate())
+}
+
+func TestOPCGroup_UnregisterCallbacks_Mocked(t *testing.T) {
+	group := &OPCGroup{provider: &mockServerProvider{}}
+	kept := make(chan *DataChangeCallBackData, 1)
+	dropped := make(chan *DataChangeCallBackData, 1)
+	group.dataChangeList = []chan *DataChangeCallBackData{dropped, kept}
+	read := make(chan *ReadCompleteCallBackData, 1)
+	group.readCompleteList = []chan *ReadCompleteCallBackData{read}
+	write := make(chan *WriteCompleteCallBackData, 1)
+	group.writeCompleteList = []chan *WriteCompleteCallBackData{write}
+	cancel := make(chan *CancelCompleteCallBackData, 1)
+	group.cancelCompleteList = []chan *CancelCompleteCallBackData{cancel}
+
+	assert.NoError(t, group.UnregisterDataChange(dropped))
+	assert.ErrorIs(t, group.UnregisterDataChange(dropped), ErrChannelNotRegistered)
+	group.fireDataChange(context.Background(), &CDataChangeCallBackData{})
+	assert.Len(t, kept, 1)
+	assert.Len(t, dropped, 0)
+
+	assert.NoError(t, group.SetAutoUnadvise(true))
+	assert.NoError(t, group.UnregisterReadComplete(read))
+	assert.NoError(t, group.UnregisterWriteComplete(write))
+	assert.NoError(t, group.UnregisterCancelComplete(cancel))
+	assert.NoError(t, group.UnregisterDataChange(kept))
+	group.fireReadComplete(&CReadCompleteCallBackData{})
+	group.fireWriteComplete(&CWriteCompleteCallBackData{})
+	group.fireCancelComplete(&CCancelCompleteCallBackData{})
+	assert.Len(t, read, 0)
+	assert.Len(t, write, 0)
+	assert.Len(t, cancel, 0)
+
+	var nilGroup *OPCGroup
+	assert.Error(t, nilGroup.UnregisterDataChange(kept))
+	assert.Error(t, nilGroup.SetAutoUnadvise(true))
+}
+
+func TestOPCGroup_AutoUnadvise_Mocked(t *testing.T) {
+	var unadvised []uint32
+	released := 0
+	group := &OPCGroup{provider: &mockServerProvider{}, groupProvider: &mockGroupProvider{}}
+	group.point = &mockDataCallbackPoint{
+		UnadviseFn: func(cookie uint32) error {
+			unadvised = append(unadvised, cookie)
+			return nil
+		},
+		ReleaseFn: func() { released++ },
+	}
+	group.event = &DataEventReceiver{}
+	group.cookie = 7
+	dataChangeCB := make(chan *CDataChangeCallBackData, 2)
+	group.callbackLock.Lock()
+	group.startLoop(dataChangeCB, make(chan *CReadCompleteCallBackData), make(chan *CWriteCompleteCallBackData), make(chan *CCancelCompleteCallBackData))
+	loopDone := group.loopDone
+	group.callbackLock.Unlock()
+
+	ch := make(chan *DataChangeCallBackData, 1)
+	assert.NoError(t, group.RegisterDataChangeWithPolicy(ch, DeliveryBlock))
+	assert.NoError(t, group.SetAutoUnadvise(true))
+	// the second event blocks the loop until the group is unadvised
+	dataChangeCB <- &CDataChangeCallBackData{GroupHandle: 1}
+	dataChangeCB <- &CDataChangeCallBackData{GroupHandle: 2}
+	assert.Eventually(t, func() bool { return len(ch) == 1 && len(dataChangeCB) == 0 }, time.Second, time.Millisecond)
+
+	assert.NoError(t, group.UnregisterDataChange(ch))
+	assert.Equal(t, []uint32{7}, unadvised)
+	assert.Equal(t, 1, released)
+	assert.Nil(t, group.event)
+	select {
+	case <-loopDone:
+	default:
+		t.Fatal("callback loop still running after unadvise")
+	}
+	assert.Equal(t, uint32(1), (<-ch).GroupHandle)
+}
+
+func TestOPCGroups_Remove_Mocked(t *testing.T) {
+	publicErr := com.HRESULT(OPCPublic)
+	var removed []uint32
+	var forced []bool
+	server := newOPCServerWithProvider(&mockServerProvider{
+		RemoveGroupFn: func(serverGroup uint32, force bool) error {
+			if serverGroup == 2 {
+				return publicErr
+			}
+			removed = append(removed, serverGroup)
+			forced = append(forced, force)
+			return nil
+		},
+	}, "mock", "localhost")
+	newLoopTestGroups(server, 3)
+	groups := server.GetOPCGroups()
+	first := groups.groups[0]
+	var loopStoppedBeforeRelease bool
+	first.groupProvider = &mockGroupProvider{ReleaseFn: func() {
+		select {
+		case <-first.loopDone:
+			loopStoppedBeforeRelease = true
+		default:
+		}
+	}}
+
+	assert.NoError(t, groups.Remove(1, false))
+	assert.True(t, loopStoppedBeforeRelease, "the callback loop must stop before the interfaces are released")
+	assert.Equal(t, GroupReleased, first.State())
+
+	err := groups.Remove(2, true)
+	assert.Equal(t, error(publicErr), err, "OPC_E_PUBLIC must be passed through untouched")
+	assert.Equal(t, 2, groups.GetCount())
+	assert.Error(t, groups.Remove(1, true))
+
+	errs := groups.RemoveAll(true)
+	if assert.Len(t, errs, 1) {
+		assert.ErrorIs(t, errs[0], publicErr)
+	}
+	assert.Equal(t, 1, groups.GetCount(), "groups the server refused to remove stay in the collection")
+	assert.Equal(t, []uint32{1, 3}, removed)
+	assert.Equal(t, []bool{false, true}, forced)
+}
+
+func TestOPCGroups_RemoveOPCGroup_Mocked(t *testing.T) {
+	inUse := com.HRESULT(com.E_FAIL)
+	var forced []bool
+	refuse := true
+	server := newOPCServerWithProvider(&mockServerProvider{
+		RemoveGroupFn: func(serverGroup uint32, force bool) error {
+			forced = append(forced, force)
+			if refuse {
+				return inUse
+			}
+			return nil
+		},
+	}, "mock", "localhost")
+	newLoopTestGroups(server, 2)
+	groups := server.GetOPCGroups()
+	group := groups.groups[1]
+
+	assert.Equal(t, error(inUse), groups.RemoveOPCGroup(group))
+	assert.Equal(t, 2, groups.GetCount())
+	assert.NotEqual(t, GroupReleased, group.State())
+
+	refuse = false
+	assert.NoError(t, groups.RemoveOPCGroup(group))
+	assert.Equal(t, 1, groups.GetCount())
+	assert.Equal(t, GroupReleased, group.State())
+	assert.Equal(t, []bool{false, false}, forced)
+
+	assert.Error(t, groups.RemoveOPCGroup(group), "a removed group is no longer in the collection")
+	assert.Error(t, groups.RemoveOPCGroup(nil))
+}
+
+func TestOPCGroups_RemoveByName_Mocked(t *testing.T) {
+	inUse := com.HRESULT(com.E_FAIL)
+	var forced []bool
+	refuse := true
+	server := newOPCServerWithProvider(&mockServerProvider{
+		RemoveGroupFn: func(serverGroup uint32, force bool) error {
+			forced = append(forced, force)
+			if refuse {
+				return inUse
+			}
+			return nil
+		},
+	}, "mock", "localhost")
+	newLoopTestGroups(server, 2)
+	groups := server.GetOPCGroups()
+	group := groups.groups[1]
+	group.groupName = "referenced"
+
+	assert.Equal(t, error(inUse), groups.RemoveByName("referenced"), "the refusal of the server must be returned")
+	assert.Equal(t, 2, groups.GetCount())
+	assert.Same(t, group, groups.groups[1])
+	assert.NotEqual(t, GroupReleased, group.State())
+
+	refuse = false
+	assert.NoError(t, groups.RemoveByName("referenced"))
+	assert.Equal(t, 1, groups.GetCount())
+	assert.Equal(t, GroupReleased, group.State())
+	assert.Equal(t, []bool{false, false}, forced)
+	assert.Error(t, groups.RemoveByName("referenced"))
+}
+
+func TestOPCGroups_Enumerate_Mocked(t *testing.T) {
+	server := newOPCServerWithProvider(&mockServerProvider{}, "mock", "localhost")
+	groups := server.GetOPCGroups()
+	groups.groups = []*OPCGroup{{groupName: "fast"}, {groupName: "slow"}}
+
+	assert.Equal(t, 2, groups.GetCount())
+	g, err := groups.Item(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "slow", g.groupName)
+	_, err = groups.Item(2)
+	assert.Error(t, err)
+	g, err = groups.ItemByName("fast")
+	assert.NoError(t, err)
+	assert.Same(t, groups.groups[0], g)
+	_, err = groups.ItemByName("missing")
+	assert.Error(t, err)
+
+	var nilGroups *OPCGroups
+	assert.Zero(t, nilGroups.GetCount())
+	_, err = nilGroups.Item(0)
+	assert.Error(t, err)
+}
+
+func TestOPCGroups_Iterate_Mocked(t *testing.T) {
+	server := newOPCServerWithProvider(&mockServerProvider{}, "mock", "localhost")
+	groups := server.GetOPCGroups()
+	groups.groups = []*OPCGroup{{groupName: "fast"}, {groupName: "slow"}}
+
+	snapshot := groups.All()
+	assert.Equal(t, groups.groups, snapshot)
+	snapshot[0] = nil
+	assert.NotNil(t, groups.groups[0], "the snapshot must not alias the collection")
+
+	var names []string
+	for group := range groups.Groups() {
+		names = append(names, group.groupName)
+		groups.groups = groups.groups[1:]
+	}
+	assert.Equal(t, []string{"fast", "slow"}, names, "removing groups while iterating must not skip any")
+	for range groups.Groups() {
+		t.Fatal("the collection is empty")
+	}
+
+	var nilGroups *OPCGroups
+	assert.Nil(t, nilGroups.All())
+	for range nilGroups.Groups() {
+		t.Fatal("a nil collection has no groups")
+	}
+}
+
+func TestOPCGroup_SyncRead_UnsupportedVariant_Mocked(t *testing.T) {
+	group := &OPCGroup{groupProvider: &mockGroupProvider{
+		SyncReadFn: func(source com.OPCDATASOURCE, serverHandles []uint32) ([]*com.ItemState, []int32, error) {
+			return []*com.ItemState{
+				{Value: int32(5), Quality: 192},
+				{Value: &com.ErrUnsupportedVariant{VT: com.VT_UNKNOWN}, Quality: 192},
+			}, []int32{0, 0}, nil
+		},
+	}}
+
+	states, errs, err := group.SyncRead(OPC_DS_CACHE, []uint32{1, 2})
+	assert.NoError(t, err, "an unconvertible value must not fail the whole read")
+	assert.Equal(t, int32(5), states[0].Value)
+	assert.NoError(t, errs[0])
+	assert.Equal(t, com.VT_UNKNOWN, states[1].Value)
+	assert.Equal(t, uint16(192), states[1].Quality)
+	var unsupported *com.ErrUnsupportedVariant
+	if assert.ErrorAs(t, errs[1], &unsupported) {
+		assert.Equal(t, com.VT_UNKNOWN, unsupported.VT)
+	}
+}
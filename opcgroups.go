@@ -4,6 +4,8 @@ package opcda
 
 import (
 	"errors"
+	"fmt"
+	"iter"
 	"sync"
 	"sync/atomic"
 
@@ -13,6 +15,7 @@ import (
 type OPCGroups struct {
 	provider               serverProvider
 	parent                 *OPCServer
+	factories              *connectionFactories
 	groupID                uint32
 	defaultActive          bool
 	defaultGroupUpdateRate uint32
@@ -21,15 +24,23 @@ type OPCGroups struct {
 	defaultGroupTimeBias   int32
 	groups                 []*OPCGroup
 	sync.RWMutex
+
+	defaultDeadbandSet bool // defaultDeadbandSet tells whether SetDefaultGroupDeadband was called.
+	defaultTimeBiasSet bool // defaultTimeBiasSet tells whether SetDefaultGroupTimeBias was called.
 }
 
 func NewOPCGroups(opcServer *OPCServer) *OPCGroups {
 	if opcServer == nil {
 		return nil
 	}
+	factories := opcServer.factories
+	if factories == nil {
+		factories = comFactories()
+	}
 	return &OPCGroups{
 		parent:                 opcServer,
 		provider:               opcServer.provider,
+		factories:              factories,
 		defaultActive:          true,
 		defaultGroupUpdateRate: uint32(1000),
 		defaultDeadband:        float32(0.0),
@@ -46,6 +57,14 @@ func (gs *OPCGroups) GetParent() *OPCServer {
 	return gs.parent
 }
 
+// authInfo returns the authentication settings of the connection, or nil for the process defaults.
+func (gs *OPCGroups) authInfo() *com.COAUTHINFO {
+	if gs == nil || gs.parent == nil {
+		return nil
+	}
+	return gs.parent.authInfo
+}
+
 // GetDefaultGroupIsActive get the default active state for OPCGroups created using Groups.Add
 func (gs *OPCGroups) GetDefaultGroupIsActive() bool {
 	if gs == nil {
@@ -86,12 +105,18 @@ func (gs *OPCGroups) GetDefaultGroupDeadband() float32 {
 	return gs.defaultDeadband
 }
 
-// SetDefaultGroupDeadband set the default deadband for OPCGroups created using Groups.Add
-func (gs *OPCGroups) SetDefaultGroupDeadband(defaultDeadband float32) {
+// SetDefaultGroupDeadband set the default deadband for OPCGroups created using Groups.Add, in percent of
+// full scale from 0 to 100. Until it is called, Add leaves the deadband to the server.
+func (gs *OPCGroups) SetDefaultGroupDeadband(defaultDeadband float32) error {
 	if gs == nil {
-		return
+		return errors.New("uninitialized groups")
+	}
+	if !(defaultDeadband >= 0 && defaultDeadband <= 100) {
+		return fmt.Errorf("deadband %v is outside 0 to 100 percent", defaultDeadband)
 	}
 	gs.defaultDeadband = defaultDeadband
+	gs.defaultDeadbandSet = true
+	return nil
 }
 
 // GetDefaultGroupLocaleID get the default locale for OPCGroups created using Groups.Add.
@@ -119,11 +144,13 @@ func (gs *OPCGroups) GetDefaultGroupTimeBias() int32 {
 }
 
 // SetDefaultGroupTimeBias set the default time bias for OPCGroups created using Groups.Add.
+// Until it is called, Add leaves the time bias to the server.
 func (gs *OPCGroups) SetDefaultGroupTimeBias(defaultGroupTimeBias int32) {
 	if gs == nil {
 		return
 	}
 	gs.defaultGroupTimeBias = defaultGroupTimeBias
+	gs.defaultTimeBiasSet = true
 }
 
 // GetCount Required property for collections.
@@ -149,6 +176,44 @@ func (gs *OPCGroups) Item(index int32) (*OPCGroup, error) {
 	return gs.groups[index], nil
 }
 
+// All returns the groups of the collection in the order they were added. The slice is a copy, so
+// ranging over it needs no lock and is not affected by groups added or removed meanwhile.
+//
+// Example:
+//
+//	for _, group := range server.GetOPCGroups().All() {
+//		fmt.Println(group.GetName(), group.GetRevisedUpdateRate())
+//	}
+func (gs *OPCGroups) All() []*OPCGroup {
+	if gs == nil {
+		return nil
+	}
+	gs.RLock()
+	defer gs.RUnlock()
+	return append([]*OPCGroup(nil), gs.groups...)
+}
+
+// Groups returns an iterator over the groups of the collection in the order they were added. It ranges
+// over a snapshot taken by All when the iteration starts, so groups may be added or removed in the loop body.
+//
+// Example:
+//
+//	groups := server.GetOPCGroups()
+//	for group := range groups.Groups() {
+//		if !group.GetIsActive() {
+//			groups.RemoveOPCGroup(group)
+//		}
+//	}
+func (gs *OPCGroups) Groups() iter.Seq[*OPCGroup] {
+	return func(yield func(*OPCGroup) bool) {
+		for _, group := range gs.All() {
+			if !yield(group) {
+				return
+			}
+		}
+	}
+}
+
 // ItemByName Returns an OPCGroup by name
 func (gs *OPCGroups) ItemByName(name string) (*OPCGroup, error) {
 	if gs == nil {
@@ -165,35 +230,177 @@ func (gs *OPCGroups) ItemByName(name string) (*OPCGroup, error) {
 }
 
 // Add Creates a new OPCGroup object and adds it to the collections
+// The group is requested with the default update rate of the collection; the server may revise it.
+// Both rates are available from the returned group through GetRequestedUpdateRate and GetRevisedUpdateRate.
+// The active state, locale and the deadband and time bias set with SetDefaultGroupDeadband and
+// SetDefaultGroupTimeBias are the defaults of the collection; an unset deadband or time bias is left to the server.
 func (gs *OPCGroups) Add(szName string) (*OPCGroup, error) {
 	if gs == nil || gs.provider == nil {
 		return nil, errors.New("uninitialized groups or failed server connection")
 	}
 	gs.Lock()
 	defer gs.Unlock()
-	hClientGroup := atomic.AddUint32(&gs.groupID, 1)
+	return gs.add(szName, atomic.AddUint32(&gs.groupID, 1), groupState{
+		active:      gs.defaultActive,
+		updateRate:  gs.defaultGroupUpdateRate,
+		timeBias:    gs.defaultGroupTimeBias,
+		deadband:    gs.defaultDeadband,
+		localeID:    gs.defaultLocaleID,
+		timeBiasSet: gs.defaultTimeBiasSet,
+		deadbandSet: gs.defaultDeadbandSet,
+	})
+}
+
+// GroupOptions are the parameters of IOPCServer::AddGroup for OPCGroups.AddWithOptions.
+type GroupOptions struct {
+	// UpdateRate is the requested update rate in milliseconds; 0 asks the server for its fastest rate.
+	UpdateRate uint32
+	// Deadband is the percent deadband from 0 to 100; nil leaves it to the server.
+	Deadband *float32
+	// TimeBias is the time bias in minutes; nil leaves it to the server.
+	TimeBias *int32
+	// Active creates the group active.
+	Active bool
+	// LocaleID is the locale of the group.
+	LocaleID uint32
+	// ClientHandle is the client handle of the group; 0 lets the collection assign one, as Add does.
+	ClientHandle uint32
+}
+
+// AddWithOptions creates a new OPCGroup with the parameters of opts, without the defaults of the collection,
+// and adds it to the collection. The server may revise the update rate; the granted rate is available from
+// GetRevisedUpdateRate of the returned group.
+//
+// Example:
+//
+//	deadband := float32(0.5)
+//	group, err := server.GetOPCGroups().AddWithOptions("fast", opcda.GroupOptions{
+//		UpdateRate: 250,
+//		Deadband:   &deadband,
+//		Active:     true,
+//		LocaleID:   0x0409,
+//	})
+func (gs *OPCGroups) AddWithOptions(name string, opts GroupOptions) (*OPCGroup, error) {
+	if gs == nil || gs.provider == nil {
+		return nil, errors.New("uninitialized groups or failed server connection")
+	}
+	requested := groupState{
+		active:     opts.Active,
+		updateRate: opts.UpdateRate,
+		localeID:   opts.LocaleID,
+	}
+	if opts.Deadband != nil {
+		if !(*opts.Deadband >= 0 && *opts.Deadband <= 100) {
+			return nil, fmt.Errorf("deadband %v is outside 0 to 100 percent", *opts.Deadband)
+		}
+		requested.deadband = *opts.Deadband
+		requested.deadbandSet = true
+	}
+	if opts.TimeBias != nil {
+		requested.timeBias = *opts.TimeBias
+		requested.timeBiasSet = true
+	}
+	gs.Lock()
+	defer gs.Unlock()
+	hClientGroup := opts.ClientHandle
+	if hClientGroup == 0 {
+		hClientGroup = atomic.AddUint32(&gs.groupID, 1)
+	}
+	return gs.add(name, hClientGroup, requested)
+}
+
+// add creates the group on the server with requested and appends it to the collection. The caller must hold gs.
+func (gs *OPCGroups) add(name string, hClientGroup uint32, requested groupState) (*OPCGroup, error) {
 	phServerGroup, pRevisedUpdateRate, ppUnk, err := gs.provider.AddGroup(
-		szName,
-		gs.defaultActive,
-		gs.defaultGroupUpdateRate,
+		name,
+		requested.active,
+		requested.updateRate,
 		hClientGroup,
-		&gs.defaultGroupTimeBias,
-		&gs.defaultDeadband,
-		gs.defaultLocaleID,
+		requested.timeBiasArg(),
+		requested.deadbandArg(),
+		requested.localeID,
 		&com.IID_IOPCGroupStateMgt,
 	)
 	if err != nil {
 		return nil, err
 	}
-	opcGroup, err := NewOPCGroup(gs, ppUnk, hClientGroup, phServerGroup, szName, pRevisedUpdateRate)
+	opcGroup, err := gs.bindGroup(ppUnk, hClientGroup, phServerGroup, name, pRevisedUpdateRate)
 	if err != nil {
 		ppUnk.Release()
 		return nil, err
 	}
+	opcGroup.requested = requested
+	opcGroup.settleState(requested.active)
 	gs.groups = append(gs.groups, opcGroup)
 	return opcGroup, nil
 }
 
+// Attach binds an OPCGroup to the group name that exists on the server but is missing from the collection,
+// such as a group created through the COM interfaces directly, and adds it to the collection. Its server
+// handle, update rate and state are read from the server with GetState. If the collection holds a group of
+// that name, it is returned instead. Groups belong to the connection that created them, so groups of other
+// clients cannot be attached; share them as public groups and use ConnectPublic. If the server has no such
+// group, its error, usually E_INVALIDARG, is returned unchanged.
+//
+// Example:
+//
+//	group, err := server.GetOPCGroups().Attach("Group1")
+//	if err != nil {
+//		return err
+//	}
+func (gs *OPCGroups) Attach(name string) (*OPCGroup, error) {
+	if gs == nil || gs.provider == nil {
+		return nil, errors.New("uninitialized groups or failed server connection")
+	}
+	gs.Lock()
+	defer gs.Unlock()
+	for _, v := range gs.groups {
+		if v.groupName == name {
+			return v, nil
+		}
+	}
+	ppUnk, err := gs.provider.GetGroupByName(name, &com.IID_IOPCGroupStateMgt)
+	if err != nil {
+		return nil, err
+	}
+	group, err := gs.bindExisting(ppUnk, name)
+	if err != nil {
+		return nil, err
+	}
+	gs.groups = append(gs.groups, group)
+	return group, nil
+}
+
+// bindExisting binds a new OPCGroup to ppUnk, the IOPCGroupStateMgt interface of a group that exists on the
+// server, and reads its handles, update rate and state with GetState. ppUnk is released if binding fails.
+// The caller must hold gs.
+func (gs *OPCGroups) bindExisting(ppUnk *com.IUnknown, name string) (*OPCGroup, error) {
+	group, err := gs.bindGroup(ppUnk, 0, 0, name, 0)
+	if err != nil {
+		ppUnk.Release()
+		return nil, err
+	}
+	updateRate, active, _, timeBias, deadband, localeID, clientHandle, serverHandle, err := group.groupProvider.GetState()
+	if err != nil {
+		group.Release()
+		return nil, err
+	}
+	group.clientGroupHandle = clientHandle
+	group.serverGroupHandle = serverHandle
+	group.revisedUpdateRate = updateRate
+	group.requested = groupState{
+		active:      active,
+		updateRate:  updateRate,
+		timeBias:    timeBias,
+		deadband:    deadband,
+		localeID:    localeID,
+		timeBiasSet: true,
+		deadbandSet: true,
+	}
+	group.settleState(active)
+	return group, nil
+}
+
 // GetOPCGroupByName Returns an OPCGroup by name
 func (gs *OPCGroups) GetOPCGroupByName(name string) (*OPCGroup, error) {
 	if gs == nil {
@@ -217,8 +424,11 @@ func (gs *OPCGroups) GetOPCGroup(serverHandle uint32) (*OPCGroup, error) {
 	return nil, errors.New("not found")
 }
 
-// Remove Removes an OPCGroup from the collection
-func (gs *OPCGroups) Remove(serverHandle uint32) error {
+// Remove removes the group with the server handle from the server, stops its callback loop, releases it and
+// drops it from the collection. force removes the group on the server even if it is still referenced there.
+// If the server refuses, for example with OPC_E_PUBLIC for a public group, its error is returned unchanged
+// and the group stays in the collection, still usable.
+func (gs *OPCGroups) Remove(serverHandle uint32, force bool) error {
 	if gs == nil {
 		return errors.New("uninitialized groups")
 	}
@@ -226,26 +436,73 @@ func (gs *OPCGroups) Remove(serverHandle uint32) error {
 	defer gs.Unlock()
 	for i, v := range gs.groups {
 		if v.serverGroupHandle == serverHandle {
-			err := gs.doRemove(serverHandle)
-			if err != nil {
-				return err
-			}
-			v.Release()
-			gs.groups = append(gs.groups[:i], gs.groups[i+1:]...)
-			return nil
+			return gs.removeAt(i, force)
 		}
 	}
 	return errors.New("not found")
 }
 
-func (gs *OPCGroups) doRemove(serverHandle uint32) error {
+// RemoveOPCGroup removes group from the server without forcing, releases it and drops it from the collection,
+// so GetCount no longer counts it. The data callback of the group is unadvised first, so the connection point
+// holds no reference that keeps the server from removing the group. If the server refuses, its error is
+// returned unchanged, the data callback is advised again and the group stays in the collection.
+func (gs *OPCGroups) RemoveOPCGroup(group *OPCGroup) error {
+	if gs == nil {
+		return errors.New("uninitialized groups")
+	}
+	if group == nil {
+		return errors.New("uninitialized group")
+	}
+	gs.Lock()
+	defer gs.Unlock()
+	for i, v := range gs.groups {
+		if v == group {
+			return gs.removeAt(i, false)
+		}
+	}
+	return errors.New("not found")
+}
+
+// removeAt removes the group at index i from the server and the collection. The caller must hold gs.
+func (gs *OPCGroups) removeAt(i int, force bool) error {
+	err := gs.removeFromServer(gs.groups[i], force)
+	if err != nil {
+		return err
+	}
+	gs.groups = append(gs.groups[:i], gs.groups[i+1:]...)
+	return nil
+}
+
+// removeFromServer removes g from the server and releases it. The data callback is unadvised and the
+// callback loop stopped before the group is removed, and the interfaces of the group are released only
+// afterwards, so no callback is dispatched on released interfaces. If the server refuses, g is advised
+// again and left usable.
+func (gs *OPCGroups) removeFromServer(g *OPCGroup, force bool) error {
+	advised := g.detachCallbacks()
+	err := gs.doRemove(g.serverGroupHandle, force)
+	if err != nil {
+		if advised {
+			if adviseErr := g.advise(); adviseErr != nil {
+				return errors.Join(err, fmt.Errorf("advise group %q again: %w", g.groupName, adviseErr))
+			}
+		}
+		return err
+	}
+	g.stopCallbackLoop()
+	g.Release()
+	return nil
+}
+
+func (gs *OPCGroups) doRemove(serverHandle uint32, force bool) error {
 	if gs == nil || gs.provider == nil {
 		return errors.New("uninitialized groups or failed server connection")
 	}
-	return gs.provider.RemoveGroup(serverHandle, true)
+	return gs.provider.RemoveGroup(serverHandle, force)
 }
 
-// RemoveByName Removes an OPCGroup from the collection by name
+// RemoveByName removes the group with the name like RemoveOPCGroup, without forcing. If the server refuses,
+// for example because the group is still referenced there, its error is returned unchanged and the group
+// stays in the collection.
 func (gs *OPCGroups) RemoveByName(name string) error {
 	if gs == nil {
 		return errors.New("uninitialized groups")
@@ -254,31 +511,55 @@ func (gs *OPCGroups) RemoveByName(name string) error {
 	defer gs.Unlock()
 	for i, v := range gs.groups {
 		if v.groupName == name {
-			err := gs.doRemove(v.GetServerHandle())
-			if err != nil {
-				return err
-			}
-			v.Release()
-			gs.groups = append(gs.groups[:i], gs.groups[i+1:]...)
-			return nil
+			return gs.removeAt(i, false)
 		}
 	}
 	return errors.New("not found")
 }
 
-// RemoveAll Removes all OPCGroups from the collection
-func (gs *OPCGroups) RemoveAll() error {
+// RemoveAll removes every group like Remove and returns the errors of the groups the server refused to
+// remove, each naming its group. Those groups stay in the collection.
+func (gs *OPCGroups) RemoveAll(force bool) []error {
 	if gs == nil {
 		return nil
 	}
 	gs.Lock()
 	defer gs.Unlock()
+	var errs []error
+	remaining := gs.groups[:0]
 	for _, v := range gs.groups {
-		gs.doRemove(v.GetServerHandle())
+		err := gs.removeFromServer(v, force)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("remove group %q: %w", v.GetName(), err))
+			remaining = append(remaining, v)
+		}
+	}
+	clear(gs.groups[len(remaining):])
+	gs.groups = remaining
+	return errs
+}
+
+// removeAllFromServer stops the callback loop of every group, removes it from the server and releases it.
+// Unlike RemoveAll it reports the groups the server failed to remove, joined with errors.Join.
+func (gs *OPCGroups) removeAllFromServer(force bool) error {
+	if gs == nil {
+		return nil
+	}
+	gs.Lock()
+	defer gs.Unlock()
+	var errs []error
+	for _, v := range gs.groups {
+		v.stopCallbackLoop()
+		if gs.provider != nil {
+			err := gs.provider.RemoveGroup(v.GetServerHandle(), force)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("remove group %q: %w", v.GetName(), err))
+			}
+		}
 		v.Release()
 	}
 	gs.groups = nil
-	return nil
+	return errors.Join(errs...)
 }
 
 // Release Releases the resources used by the collection and the items it contains.
@@ -233,9 +233,9 @@ func TestOPCGroups_AddGroup(t *testing.T) {
 	assert.Equal(t, group, g4)
 	_, err = groups.GetOPCGroup(group.GetServerHandle() + 1)
 	assert.Error(t, err)
-	err = groups.Remove(group.GetServerHandle())
+	err = groups.Remove(group.GetServerHandle(), true)
 	assert.NoError(t, err)
-	err = groups.Remove(group.GetServerHandle())
+	err = groups.Remove(group.GetServerHandle(), true)
 	assert.Error(t, err)
 	_, err = groups.Add("test")
 	assert.NoError(t, err)
@@ -248,7 +248,6 @@ func TestOPCGroups_AddGroup(t *testing.T) {
 	_, err = groups.Add("test")
 	assert.NoError(t, err)
 	assert.Equal(t, 1, groups.GetCount())
-	err = groups.RemoveAll()
-	assert.NoError(t, err)
+	assert.Empty(t, groups.RemoveAll(true))
 	assert.Equal(t, 0, groups.GetCount())
 }
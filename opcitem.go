@@ -4,6 +4,7 @@ package opcda
 
 import (
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -28,6 +29,8 @@ type OPCItem struct {
 	requestedDataType com.VT
 	nativeDataType    com.VT
 	parent            *OPCItems
+	buffer            *valueRing
+	ioLock            sync.Mutex // ioLock serializes Read and Write when the group asks for it.
 }
 
 // GetParent returns a reference to the parent OPCItems object.
@@ -46,11 +49,15 @@ func (i *OPCItem) GetClientHandle() uint32 {
 	return i.clientHandle
 }
 
-// SetClientHandle sets the client handle for the item.
+// SetClientHandle sets the client handle for the item. The handle must not be used by another item of the
+// group; if it is, ErrDuplicateClientHandle is returned and the handle is left unchanged.
 func (i *OPCItem) SetClientHandle(clientHandle uint32) error {
 	if i == nil || i.itemMgtProvider == nil {
 		return errors.New("uninitialized item")
 	}
+	if i.parent != nil && i.parent.clientHandleTaken(i, clientHandle) {
+		return fmt.Errorf("%w: %d (%s)", ErrDuplicateClientHandle, clientHandle, i.tag)
+	}
 	errs, err := i.itemMgtProvider.SetClientHandles([]uint32{i.serverHandle}, []uint32{clientHandle})
 	if err != nil {
 		return err
@@ -59,8 +66,12 @@ func (i *OPCItem) SetClientHandle(clientHandle uint32) error {
 		return i.getError(errs[0])
 	}
 	i.Lock()
+	oldHandle := i.clientHandle
 	i.clientHandle = clientHandle
 	i.Unlock()
+	if i.parent != nil {
+		i.parent.moveClientHandle(i, oldHandle, clientHandle)
+	}
 	return nil
 }
 
@@ -121,17 +132,22 @@ func (i *OPCItem) SetRequestedDataType(requestedDataType com.VT) error {
 	if i == nil || i.itemMgtProvider == nil {
 		return errors.New("uninitialized item")
 	}
-	errs, err := i.itemMgtProvider.SetDatatypes([]uint32{i.serverHandle}, []com.VT{requestedDataType})
+	var server *OPCServer
+	if i.parent != nil {
+		server = i.parent.parent.server()
+	}
+	errs, err := setDatatypes(i.itemMgtProvider, server, i.getError, []*OPCItem{i}, []com.VT{requestedDataType})
 	if err != nil {
 		return err
 	}
-	if errs[0] < 0 {
-		return i.getError(errs[0])
-	}
+	return errs[0]
+}
+
+// setRequestedDataType records a requested data type the server accepted.
+func (i *OPCItem) setRequestedDataType(requestedDataType com.VT) {
 	i.Lock()
 	i.requestedDataType = requestedDataType
 	i.Unlock()
-	return nil
 }
 
 // SetIsActive sets the active state for the item.
@@ -152,6 +168,73 @@ func (i *OPCItem) SetIsActive(isActive bool) error {
 	return nil
 }
 
+// Activate makes the item active so that the server starts maintaining its cached value.
+func (i *OPCItem) Activate() error {
+	return i.SetIsActive(true)
+}
+
+// activateReadPollInterval is the delay between reads while ActivateAndRead waits for the first value
+// of an item whose group has no data callback connected.
+var activateReadPollInterval = 50 * time.Millisecond
+
+// ActivateAndRead activates the item and reads it once the server has produced a first value.
+// A freshly activated item reports OPC_QUALITY_WAITING_FOR_INITIAL_DATA until the server completes
+// its first scan. If the data callback of the parent group is connected, because a subscriber is
+// registered, the item is read again when a data change delivers its first value; otherwise the read
+// is repeated every 50 ms. The wait is twice the revised update rate of the parent group, or one second
+// if the group is unknown. When the wait expires the last read result is returned as is.
+func (i *OPCItem) ActivateAndRead(source com.OPCDATASOURCE) (interface{}, uint16, time.Time, error) {
+	if i == nil || i.itemMgtProvider == nil || i.groupProvider == nil {
+		return nil, 0, time.Time{}, errors.New("uninitialized item")
+	}
+	var changed chan struct{}
+	if i.parent != nil && i.parent.parent != nil {
+		// registered before activating, so the first data change cannot be missed
+		g := i.parent.parent
+		if changed = g.awaitValue(i.GetClientHandle()); changed != nil {
+			defer g.unawaitValue(changed)
+		}
+	}
+	err := i.Activate()
+	if err != nil {
+		return nil, 0, time.Time{}, err
+	}
+	deadline := time.Now().Add(i.initialValueWait())
+	for {
+		val, qual, ts, err := i.Read(source)
+		if err != nil {
+			return nil, 0, time.Time{}, err
+		}
+		if qual&OPC_STATUS_MASK != OPC_QUALITY_WAITING_FOR_INITIAL_DATA || !time.Now().Before(deadline) {
+			return val, qual, ts, nil
+		}
+		waitInitialValue(changed, deadline)
+	}
+}
+
+// waitInitialValue waits until changed reports the first value of an item or deadline passes. Without
+// changed it waits activateReadPollInterval.
+func waitInitialValue(changed <-chan struct{}, deadline time.Time) {
+	if changed == nil {
+		time.Sleep(activateReadPollInterval)
+		return
+	}
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	select {
+	case <-changed:
+	case <-timer.C:
+	}
+}
+
+// initialValueWait returns how long ActivateAndRead waits for the first value of the item.
+func (i *OPCItem) initialValueWait() time.Duration {
+	if i.parent != nil && i.parent.parent != nil && i.parent.parent.revisedUpdateRate > 0 {
+		return 2 * time.Duration(i.parent.parent.revisedUpdateRate) * time.Millisecond
+	}
+	return time.Second
+}
+
 // GetValue returns the latest value read from the server.
 func (i *OPCItem) GetValue() interface{} {
 	if i == nil {
@@ -182,6 +265,18 @@ func (i *OPCItem) GetTimestamp() time.Time {
 	return i.timestamp
 }
 
+// Snapshot returns the latest value, quality and timestamp read from the server as one consistent
+// triple. Calling GetValue, GetQuality and GetTimestamp one after another may mix the results of
+// concurrent reads.
+func (i *OPCItem) Snapshot() (value interface{}, quality uint16, timestamp time.Time) {
+	if i == nil {
+		return nil, 0, time.Time{}
+	}
+	i.RLock()
+	defer i.RUnlock()
+	return i.value, i.quality, i.timestamp
+}
+
 // GetCanonicalDataType returns the canonical data type for the item.
 func (i *OPCItem) GetCanonicalDataType() com.VT {
 	if i == nil {
@@ -255,20 +350,26 @@ func NewOPCItem(
 }
 
 // Read reads the value, quality and timestamp for the item.
+// If the server rejects the call the error wraps ErrCallRejected. A value whose VARIANT type cannot be
+// converted is returned as its raw com.VT with a *com.ErrUnsupportedVariant, and is not cached.
 func (i *OPCItem) Read(source com.OPCDATASOURCE) (interface{}, uint16, time.Time, error) {
 	if i == nil || i.groupProvider == nil {
 		return nil, 0, time.Time{}, errors.New("uninitialized item")
 	}
+	defer i.beginIO()()
 	values, errs, err := i.groupProvider.SyncRead(source, []uint32{i.serverHandle})
 	if err != nil {
-		return nil, 0, time.Time{}, err
+		return nil, 0, time.Time{}, translateCallRejected(err)
 	}
 	if errs[0] < 0 {
 		return nil, 0, time.Time{}, i.getError(errs[0])
 	}
-	val := values[0].Value
+	val, err := splitUnsupported(values[0].Value)
 	qual := values[0].Quality
 	ts := values[0].Timestamp
+	if err != nil {
+		return val, qual, ts, err
+	}
 
 	i.Lock()
 	i.value = val
@@ -279,6 +380,8 @@ func (i *OPCItem) Read(source com.OPCDATASOURCE) (interface{}, uint16, time.Time
 }
 
 // Write writes a value to the item.
+// A scalar value for an array item, or an array value for a scalar item, is rejected with ErrShapeMismatch.
+// If the server rejects the call the error wraps ErrCallRejected.
 func (i *OPCItem) Write(value interface{}) error {
 	if i == nil || i.groupProvider == nil {
 		return errors.New("uninitialized item")
@@ -288,25 +391,76 @@ func (i *OPCItem) Write(value interface{}) error {
 		return err
 	}
 	defer variant.Clear()
-	errs, err := i.groupProvider.SyncWrite([]uint32{i.serverHandle}, []com.VARIANT{*variant.Variant})
+	err = i.checkShape(variant.Variant)
 	if err != nil {
 		return err
 	}
+	defer i.beginIO()()
+	errs, err := i.groupProvider.SyncWrite([]uint32{i.serverHandle}, []com.VARIANT{*variant.Variant})
+	if err != nil {
+		return translateCallRejected(err)
+	}
 	if errs[0] < 0 {
 		return i.getError(errs[0])
 	}
 	return nil
 }
 
-func (i *OPCItem) getError(errorCode int32) error {
-	if i == nil || i.provider == nil {
-		return &OPCError{ErrorCode: errorCode, ErrorMessage: "uninitialized common interface"}
+// WriteWithTimeout writes a value to the item with an async write and waits up to timeout for the
+// write to complete. If no completion arrives in time the transaction is cancelled with AsyncCancel
+// and ErrAsyncTimeout is returned, so a stuck device cannot block the caller for the full DCOM timeout.
+// Values of the wrong shape are rejected with ErrShapeMismatch as in Write.
+func (i *OPCItem) WriteWithTimeout(value interface{}, timeout time.Duration) error {
+	if i == nil || i.parent == nil || i.parent.parent == nil {
+		return errors.New("uninitialized item")
 	}
-	errStr, _ := i.provider.GetErrorString(uint32(errorCode))
-	return &OPCError{
-		ErrorCode:    errorCode,
-		ErrorMessage: errStr,
+	err := i.checkValueShape(value)
+	if err != nil {
+		return err
+	}
+	g := i.parent.parent
+	transactionID := g.nextAwaitTransactionID()
+	var done chan interface{}
+	err = g.adviseAnd(func() { done = g.awaitLocked(transactionID) })
+	if err != nil {
+		return err
+	}
+	defer g.unawait(transactionID)
+	cancelID, errs, err := g.AsyncWrite([]uint32{i.serverHandle}, []interface{}{value}, transactionID)
+	if err != nil {
+		return err
+	}
+	if errs[0] != nil {
+		return errs[0]
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case data := <-done:
+			result, ok := data.(*WriteCompleteCallBackData)
+			if !ok {
+				continue
+			}
+			if len(result.Errors) > 0 && result.Errors[0] != nil {
+				return result.Errors[0]
+			}
+			return result.MasterErr
+		case <-timer.C:
+			cancelErr := g.AsyncCancel(cancelID)
+			if cancelErr != nil {
+				return errors.Join(ErrAsyncTimeout, cancelErr)
+			}
+			return ErrAsyncTimeout
+		}
+	}
+}
+
+func (i *OPCItem) getError(errorCode int32) error {
+	if i == nil || i.parent == nil {
+		return newOPCError(nil, i.provider, errorCode)
 	}
+	return newOPCError(i.parent.parent.server(), i.provider, errorCode)
 }
 
 // Release Releases the OPCItem object
@@ -3,11 +3,15 @@
 package opcda
 
 import (
+	"context"
+	"fmt"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/wends155/opcda/com"
+	"golang.org/x/sys/windows"
 )
 
 func TestOPCItem_Read_Mocked(t *testing.T) {
@@ -32,6 +36,63 @@ func TestOPCItem_Read_Mocked(t *testing.T) {
 	assert.Equal(t, 123.45, val)
 	assert.Equal(t, uint16(192), q)
 	assert.Equal(t, now, ts)
+
+	val, q, ts = item.Snapshot()
+	assert.Equal(t, 123.45, val)
+	assert.Equal(t, uint16(192), q)
+	assert.Equal(t, now, ts)
+}
+
+func TestOPCItem_Read_UnsupportedVariant_Mocked(t *testing.T) {
+	item := &OPCItem{
+		groupProvider: &mockGroupProvider{
+			SyncReadFn: func(source com.OPCDATASOURCE, serverHandles []uint32) ([]*com.ItemState, []int32, error) {
+				return []*com.ItemState{{Value: &com.ErrUnsupportedVariant{VT: com.VT_DISPATCH}, Quality: 192}}, []int32{0}, nil
+			},
+		},
+		serverHandle: 1,
+	}
+	val, q, _, err := item.Read(OPC_DS_CACHE)
+	var unsupported *com.ErrUnsupportedVariant
+	assert.ErrorAs(t, err, &unsupported)
+	assert.Equal(t, com.VT_DISPATCH, val)
+	assert.Equal(t, uint16(192), q)
+	val, _, _ = item.Snapshot()
+	assert.Nil(t, val, "an unconvertible value is not cached")
+}
+
+func TestOPCItem_Snapshot_Concurrent_Mocked(t *testing.T) {
+	base := time.Now()
+	var reads int32
+	item := &OPCItem{
+		groupProvider: &mockGroupProvider{
+			SyncReadFn: func(source com.OPCDATASOURCE, serverHandles []uint32) ([]*com.ItemState, []int32, error) {
+				n := atomic.AddInt32(&reads, 1)
+				return []*com.ItemState{{Value: n, Quality: uint16(n), Timestamp: base.Add(time.Duration(n))}}, []int32{0}, nil
+			},
+		},
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for n := 0; n < 1000; n++ {
+			_, _, _, _ = item.Read(OPC_DS_CACHE)
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		val, q, ts := item.Snapshot()
+		if val == nil {
+			continue
+		}
+		n := val.(int32)
+		assert.Equal(t, uint16(n), q)
+		assert.Equal(t, base.Add(time.Duration(n)), ts)
+	}
 }
 
 func TestOPCItem_Write_Mocked(t *testing.T) {
@@ -48,3 +109,256 @@ func TestOPCItem_Write_Mocked(t *testing.T) {
 	err := item.Write(float64(1.23))
 	assert.NoError(t, err)
 }
+
+func TestOPCItem_ActivateAndRead_Mocked(t *testing.T) {
+	now := time.Now()
+	var activated bool
+	mockItemMgt := &mockItemMgtProvider{
+		SetActiveStateFn: func(serverHandles []uint32, bActive bool) ([]int32, error) {
+			activated = bActive
+			return []int32{0}, nil
+		},
+	}
+	reads := 0
+	mockGroup := &mockGroupProvider{
+		SyncReadFn: func(source com.OPCDATASOURCE, serverHandles []uint32) ([]*com.ItemState, []int32, error) {
+			assert.True(t, activated)
+			reads++
+			quality := OPC_QUALITY_WAITING_FOR_INITIAL_DATA
+			if reads > 1 {
+				quality = OPC_QUALITY_GOOD
+			}
+			return []*com.ItemState{{Value: int32(7), Quality: quality, Timestamp: now}}, []int32{0}, nil
+		},
+	}
+	item := &OPCItem{
+		itemMgtProvider: mockItemMgt,
+		groupProvider:   mockGroup,
+		serverHandle:    1,
+	}
+	val, q, ts, err := item.ActivateAndRead(OPC_DS_CACHE)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, reads)
+	assert.Equal(t, int32(7), val)
+	assert.Equal(t, OPC_QUALITY_GOOD, q)
+	assert.Equal(t, now, ts)
+	assert.True(t, item.GetIsActive())
+}
+
+func TestOPCItem_ActivateAndRead_DataChange_Mocked(t *testing.T) {
+	group := &OPCGroup{provider: &mockServerProvider{}, event: &DataEventReceiver{}, revisedUpdateRate: 60000}
+	reads := 0
+	group.groupProvider = &mockGroupProvider{
+		SyncReadFn: func(source com.OPCDATASOURCE, serverHandles []uint32) ([]*com.ItemState, []int32, error) {
+			reads++
+			quality := OPC_QUALITY_WAITING_FOR_INITIAL_DATA
+			if reads == 1 {
+				go group.fireDataChange(context.Background(), &CDataChangeCallBackData{
+					ItemClientHandles: []uint32{5},
+					Values:            []interface{}{int32(7)},
+					Qualities:         []uint16{OPC_QUALITY_GOOD},
+					TimeStamps:        []time.Time{time.Now()},
+					Errors:            []int32{0},
+				})
+			} else {
+				quality = OPC_QUALITY_GOOD
+			}
+			return []*com.ItemState{{Value: int32(7), Quality: quality}}, []int32{0}, nil
+		},
+	}
+	item := &OPCItem{
+		parent: &OPCItems{parent: group},
+		itemMgtProvider: &mockItemMgtProvider{
+			SetActiveStateFn: func(serverHandles []uint32, bActive bool) ([]int32, error) {
+				return []int32{0}, nil
+			},
+		},
+		groupProvider: group.groupProvider,
+		serverHandle:  1,
+		clientHandle:  5,
+	}
+	start := time.Now()
+	_, q, _, err := item.ActivateAndRead(OPC_DS_CACHE)
+	assert.NoError(t, err)
+	assert.Equal(t, OPC_QUALITY_GOOD, q)
+	assert.Equal(t, 2, reads)
+	assert.Less(t, time.Since(start), 10*time.Second, "the data change ends the wait of two minutes")
+	assert.Empty(t, group.valueWaiters)
+}
+
+func TestOPCItem_WriteWithTimeout_Mocked(t *testing.T) {
+	group := &OPCGroup{provider: &mockServerProvider{}, event: &DataEventReceiver{}}
+	group.groupProvider = &mockGroupProvider{
+		AsyncWriteFn: func(serverHandles []uint32, values []com.VARIANT, transactionID uint32) (uint32, []int32, error) {
+			assert.Equal(t, []uint32{1}, serverHandles)
+			go group.fireWriteComplete(&CWriteCompleteCallBackData{TransID: transactionID, Errors: []int32{0}})
+			return 9, []int32{0}, nil
+		},
+		AsyncCancelFn: func(cancelID uint32) error {
+			t.Fatal("completed write must not be cancelled")
+			return nil
+		},
+	}
+	item := &OPCItem{parent: &OPCItems{parent: group}, serverHandle: 1}
+	assert.NoError(t, item.WriteWithTimeout(int32(5), time.Second))
+	assert.Empty(t, group.awaiters)
+}
+
+func TestOPCItem_WriteWithTimeout_Cancel_Mocked(t *testing.T) {
+	var cancelled []uint32
+	group := &OPCGroup{provider: &mockServerProvider{}, event: &DataEventReceiver{}}
+	group.groupProvider = &mockGroupProvider{
+		AsyncWriteFn: func(serverHandles []uint32, values []com.VARIANT, transactionID uint32) (uint32, []int32, error) {
+			return 9, []int32{0}, nil
+		},
+		AsyncCancelFn: func(cancelID uint32) error {
+			cancelled = append(cancelled, cancelID)
+			return nil
+		},
+	}
+	item := &OPCItem{parent: &OPCItems{parent: group}, serverHandle: 1}
+	err := item.WriteWithTimeout(int32(5), 10*time.Millisecond)
+	assert.ErrorIs(t, err, ErrAsyncTimeout)
+	assert.Equal(t, []uint32{9}, cancelled)
+}
+
+func TestOPCItems_AddItemsWithOptions_Mocked(t *testing.T) {
+	group := &OPCGroup{groupProvider: &mockGroupProvider{}}
+	var received []com.TagOPCITEMDEF
+	items := NewOPCItems(group, &mockItemMgtProvider{
+		AddItemsFn: func(defs []com.TagOPCITEMDEF) ([]com.TagOPCITEMRESULTStruct, []int32, error) {
+			received = defs
+			return []com.TagOPCITEMRESULTStruct{{Server: 1, NativeType: uint16(com.VT_R8)}, {}, {Server: 3}},
+				[]int32{0, int32(OPCUnknownItemID), 0}, nil
+		},
+	}, &mockServerProvider{})
+	items.SetDefaultAccessPath("default")
+
+	added, errs, err := items.AddItemsWithOptions([]ItemDef{
+		{Tag: "Tank.Level", AccessPath: "PLC1", Active: true, RequestedDataType: com.VT_R4, ClientHandle: 100},
+		{Tag: "Missing"},
+		{Tag: "Tank.Temp", AccessPath: "PLC2"},
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, received, 3) {
+		assert.Equal(t, "PLC1", windows.UTF16PtrToString(received[0].SzAccessPath))
+		assert.Equal(t, int32(1), received[0].BActive)
+		assert.Equal(t, uint16(com.VT_R4), received[0].VtRequested)
+		assert.Equal(t, uint32(100), received[0].HClient)
+		assert.Equal(t, "PLC2", windows.UTF16PtrToString(received[2].SzAccessPath))
+		assert.Equal(t, int32(0), received[2].BActive)
+		assert.Equal(t, uint16(com.VT_EMPTY), received[2].VtRequested)
+		assert.NotZero(t, received[2].HClient)
+		assert.NotEqual(t, received[1].HClient, received[2].HClient)
+	}
+	assert.NoError(t, errs[0])
+	assert.Error(t, errs[1])
+	assert.Nil(t, added[1])
+	assert.Equal(t, com.VT_R4, added[0].GetRequestedDataType())
+	assert.Equal(t, "PLC1", added[0].GetAccessPath())
+	assert.True(t, added[0].GetIsActive())
+	assert.Equal(t, uint32(100), added[0].GetClientHandle())
+	assert.False(t, added[2].GetIsActive())
+	assert.Equal(t, 2, items.GetCount())
+}
+
+func TestOPCItems_AddItems_UsesDefaults_Mocked(t *testing.T) {
+	group := &OPCGroup{groupProvider: &mockGroupProvider{}}
+	var received []com.TagOPCITEMDEF
+	items := NewOPCItems(group, &mockItemMgtProvider{
+		AddItemsFn: func(defs []com.TagOPCITEMDEF) ([]com.TagOPCITEMRESULTStruct, []int32, error) {
+			received = defs
+			return make([]com.TagOPCITEMRESULTStruct, len(defs)), make([]int32, len(defs)), nil
+		},
+	}, &mockServerProvider{})
+	items.SetDefaultAccessPath("path")
+	items.SetDefaultRequestedDataType(com.VT_I4)
+	items.SetDefaultActive(false)
+	assert.Equal(t, ItemDef{AccessPath: "path", RequestedDataType: com.VT_I4}, items.GetDefaults())
+
+	added, _, err := items.AddItems([]string{"a", "b"})
+	assert.NoError(t, err)
+	for i, def := range received {
+		assert.Equal(t, "path", windows.UTF16PtrToString(def.SzAccessPath))
+		assert.Equal(t, uint16(com.VT_I4), def.VtRequested)
+		assert.Equal(t, int32(0), def.BActive)
+		assert.Equal(t, def.HClient, added[i].GetClientHandle())
+	}
+}
+
+func TestOPCItems_AddItems_ConcurrentDefaults_Mocked(t *testing.T) {
+	group := &OPCGroup{groupProvider: &mockGroupProvider{}}
+	items := NewOPCItems(group, &mockItemMgtProvider{
+		AddItemsFn: func(defs []com.TagOPCITEMDEF) ([]com.TagOPCITEMRESULTStruct, []int32, error) {
+			for _, def := range defs[1:] {
+				assert.Equal(t, windows.UTF16PtrToString(defs[0].SzAccessPath), windows.UTF16PtrToString(def.SzAccessPath))
+				assert.Equal(t, defs[0].BActive, def.BActive)
+				assert.Equal(t, defs[0].VtRequested, def.VtRequested)
+			}
+			return make([]com.TagOPCITEMRESULTStruct, len(defs)), make([]int32, len(defs)), nil
+		},
+	}, &mockServerProvider{})
+	tags := make([]string, 200)
+	for i := range tags {
+		tags[i] = fmt.Sprintf("tag%d", i)
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			items.SetDefaultAccessPath(fmt.Sprintf("path%d", i))
+			items.SetDefaultActive(i%2 == 0)
+			items.SetDefaultRequestedDataType(com.VT(i % 2 * int(com.VT_I4)))
+			_ = items.GetDefaults()
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		_, _, err := items.AddItems(tags)
+		assert.NoError(t, err)
+	}
+	close(stop)
+	<-done
+	assert.Equal(t, 20*len(tags), items.GetCount())
+}
+
+func TestOPCItems_AddItemsWithOptions_ClientHandles_Mocked(t *testing.T) {
+	group := &OPCGroup{groupProvider: &mockGroupProvider{}}
+	calls := 0
+	var received []com.TagOPCITEMDEF
+	items := NewOPCItems(group, &mockItemMgtProvider{
+		AddItemsFn: func(defs []com.TagOPCITEMDEF) ([]com.TagOPCITEMRESULTStruct, []int32, error) {
+			calls++
+			received = defs
+			return make([]com.TagOPCITEMRESULTStruct, len(defs)), make([]int32, len(defs)), nil
+		},
+	}, &mockServerProvider{})
+
+	_, _, err := items.AddItemsWithOptions([]ItemDef{{Tag: "a", ClientHandle: 1}, {Tag: "b", ClientHandle: 2}})
+	assert.NoError(t, err)
+
+	_, _, err = items.AddItemsWithOptions([]ItemDef{{Tag: "c", ClientHandle: 2}})
+	assert.ErrorIs(t, err, ErrDuplicateClientHandle)
+	_, _, err = items.AddItemsWithOptions([]ItemDef{{Tag: "c", ClientHandle: 7}, {Tag: "d", ClientHandle: 7}})
+	assert.ErrorIs(t, err, ErrDuplicateClientHandle)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, 2, items.GetCount())
+
+	// generated handles skip the handles chosen by the caller
+	added, _, err := items.AddItemsWithOptions([]ItemDef{{Tag: "e"}, {Tag: "f", ClientHandle: 3}, {Tag: "g"}})
+	assert.NoError(t, err)
+	handles := []uint32{received[0].HClient, received[1].HClient, received[2].HClient}
+	assert.Equal(t, uint32(3), handles[1])
+	seen := map[uint32]bool{1: true, 2: true}
+	for i, h := range handles {
+		assert.False(t, seen[h], "handle %d reused", h)
+		seen[h] = true
+		assert.Equal(t, h, added[i].GetClientHandle())
+	}
+}
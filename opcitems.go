@@ -4,6 +4,7 @@ package opcda
 
 import (
 	"errors"
+	"fmt"
 	"sync"
 	"sync/atomic"
 
@@ -81,6 +82,8 @@ type OPCItems struct {
 	defaultAccessPath        string
 	defaultActive            bool
 	items                    []*OPCItem
+	byClientHandle           map[uint32]*OPCItem
+	buffered                 int32 // number of items with a buffer, read atomically
 	sync.RWMutex
 }
 
@@ -113,6 +116,8 @@ func (is *OPCItems) GetDefaultRequestedDataType() com.VT {
 	if is == nil {
 		return com.VT_EMPTY
 	}
+	is.RLock()
+	defer is.RUnlock()
 	return is.defaultRequestedDataType
 }
 
@@ -121,6 +126,8 @@ func (is *OPCItems) SetDefaultRequestedDataType(defaultRequestedDataType com.VT)
 	if is == nil {
 		return
 	}
+	is.Lock()
+	defer is.Unlock()
 	is.defaultRequestedDataType = defaultRequestedDataType
 }
 
@@ -129,6 +136,8 @@ func (is *OPCItems) GetDefaultAccessPath() string {
 	if is == nil {
 		return ""
 	}
+	is.RLock()
+	defer is.RUnlock()
 	return is.defaultAccessPath
 }
 
@@ -147,6 +156,8 @@ func (is *OPCItems) GetDefaultActive() bool {
 	if is == nil {
 		return false
 	}
+	is.RLock()
+	defer is.RUnlock()
 	return is.defaultActive
 }
 
@@ -155,9 +166,26 @@ func (is *OPCItems) SetDefaultActive(defaultActive bool) {
 	if is == nil {
 		return
 	}
+	is.Lock()
+	defer is.Unlock()
 	is.defaultActive = defaultActive
 }
 
+// GetDefaults returns the default access path, active state and requested data type of the collection
+// as one consistent snapshot. Tag and ClientHandle of the returned ItemDef are empty.
+func (is *OPCItems) GetDefaults() ItemDef {
+	if is == nil {
+		return ItemDef{}
+	}
+	is.RLock()
+	defer is.RUnlock()
+	return ItemDef{
+		AccessPath:        is.defaultAccessPath,
+		Active:            is.defaultActive,
+		RequestedDataType: is.defaultRequestedDataType,
+	}
+}
+
 // GetCount returns the number of items in the collection.
 func (is *OPCItems) GetCount() int {
 	if is == nil {
@@ -226,30 +254,95 @@ func (is *OPCItems) AddItem(tag string) (*OPCItem, error) {
 	return items[0], nil
 }
 
-// AddItems adds multiple items to the collection.
+// ItemDef describes an item to add with AddItemsWithOptions.
+type ItemDef struct {
+	// Tag is the fully qualified item ID.
+	Tag string
+	// AccessPath is the access path the server should use, or "" to let the server choose.
+	AccessPath string
+	// Active is the initial active state of the item.
+	Active bool
+	// RequestedDataType is the data type the server should return values in; VT_EMPTY requests the native type.
+	RequestedDataType com.VT
+	// ClientHandle is the client handle of the item; 0 assigns the next handle of the collection.
+	ClientHandle uint32
+}
+
+// AddItems adds multiple items to the collection using the default access path, active state and
+// requested data type of the collection. The defaults are read once, so every item of the batch uses
+// the same values even if they are changed while the items are added.
 func (is *OPCItems) AddItems(tags []string) ([]*OPCItem, []error, error) {
 	if is == nil || is.itemMgtProvider == nil {
 		return nil, nil, errors.New("uninitialized items or failed group connection")
 	}
+	defaults := is.GetDefaults()
+	defs := make([]ItemDef, len(tags))
+	for i, tag := range tags {
+		defs[i] = defaults
+		defs[i].Tag = tag
+	}
+	return is.AddItemsWithOptions(defs)
+}
+
+// ErrDuplicateClientHandle is returned by AddItemsWithOptions and SetClientHandle when a client handle is
+// already used by an item of the group or is given to more than one item of the batch.
+var ErrDuplicateClientHandle = errors.New("duplicate item client handle")
+
+// AddItemsWithOptions adds multiple items to the collection, each with its own access path, active state,
+// requested data type and client handle. The collection defaults are not used.
+// Client handles chosen by the caller are reported as ItemClientHandles in the data callbacks and must be
+// unique within the group; if any is not, no item is added and ErrDuplicateClientHandle is returned.
+// Generated handles never collide with handles in use.
+// The returned slices are parallel to defs; an item the server rejects is nil with its error set.
+// An empty defs returns empty results without calling the server.
+func (is *OPCItems) AddItemsWithOptions(defs []ItemDef) ([]*OPCItem, []error, error) {
+	if is == nil || is.itemMgtProvider == nil {
+		return nil, nil, errors.New("uninitialized items or failed group connection")
+	}
+	if len(defs) == 0 {
+		return nil, nil, nil
+	}
+	return is.addItems(defs, nil)
+}
+
+// addItems adds the items of defs, filling the report entries parallel to defs when entries is not nil.
+func (is *OPCItems) addItems(defs []ItemDef, entries []AddItemsReportEntry) ([]*OPCItem, []error, error) {
 	is.Lock()
 	defer is.Unlock()
-	accessPath := is.defaultAccessPath
-	active := is.defaultActive
-	dt := is.defaultRequestedDataType
-	items := is.createDefinitions(tags, accessPath, active, dt)
+	used, err := is.clientHandlesInUse(defs)
+	if err != nil {
+		return nil, nil, err
+	}
+	items := is.createDefinitions(defs, used)
 	results, errs, err := is.itemMgtProvider.AddItems(items)
 	if err != nil {
 		return nil, nil, err
 	}
-	var resultErrors = make([]error, len(tags))
-	var opcItems = make([]*OPCItem, len(tags))
-	for j := 0; j < len(tags); j++ {
+	var resultErrors = make([]error, len(defs))
+	var opcItems = make([]*OPCItem, len(defs))
+	for j, def := range defs {
+		if entries != nil {
+			entries[j] = AddItemsReportEntry{
+				Tag:           def.Tag,
+				ClientHandle:  items[j].HClient,
+				HRESULT:       errs[j],
+				Stage:         ItemStageAdd,
+				CanonicalType: com.VT(results[j].NativeType),
+				AccessRights:  results[j].AccessRights,
+			}
+		}
 		if errs[j] < 0 {
 			resultErrors[j] = is.getError(errs[j])
+			if entries != nil {
+				entries[j].ClientHandle = 0
+				entries[j].Message = resultErrors[j].Error()
+			}
 		} else {
-			item := NewOPCItem(is, tags[j], results[j], items[j].HClient, accessPath, active)
+			item := NewOPCItem(is, def.Tag, results[j], items[j].HClient, def.AccessPath, def.Active)
+			item.requestedDataType = def.RequestedDataType
 			opcItems[j] = item
 			is.items = append(is.items, item)
+			is.indexItem(item)
 		}
 	}
 	return opcItems, resultErrors, nil
@@ -279,6 +372,11 @@ func (is *OPCItems) Remove(serverHandles []uint32) {
 	}
 
 	is.items = newItems
+	for _, item := range removedItems {
+		if is.byClientHandle[item.clientHandle] == item {
+			delete(is.byClientHandle, item.clientHandle)
+		}
+	}
 
 	if len(removedHandles) > 0 {
 		if is.itemMgtProvider != nil {
@@ -291,10 +389,15 @@ func (is *OPCItems) Remove(serverHandles []uint32) {
 }
 
 // Validate determines if one or more OPCItems could be successfully created via the Add method (but does not add them).
+// An empty tags returns empty results without calling the server.
 func (is *OPCItems) Validate(tags []string, requestedDataTypes *[]com.VT, accessPaths *[]string) ([]error, error) {
 	if is == nil || is.itemMgtProvider == nil {
 		return nil, errors.New("uninitialized items or failed group connection")
 	}
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	defaultRequestedDataType := is.GetDefaultRequestedDataType()
 	var definitions []com.TagOPCITEMDEF
 	for i, v := range tags {
 		cHandle := atomic.AddUint32(&is.itemID, 1)
@@ -305,7 +408,7 @@ func (is *OPCItems) Validate(tags []string, requestedDataTypes *[]com.VT, access
 			HClient:      cHandle,
 			DwBlobSize:   0,
 			PBlob:        nil,
-			VtRequested:  uint16(is.defaultRequestedDataType),
+			VtRequested:  uint16(defaultRequestedDataType),
 		}
 		if requestedDataTypes != nil {
 			item.VtRequested = uint16((*requestedDataTypes)[i])
@@ -348,12 +451,17 @@ func (is *OPCItems) SetActive(serverHandles []uint32, active bool) []error {
 	return resultErrors
 }
 
-// SetClientHandles changes the client handles for one or more items in the collection.
+// SetClientHandles changes the client handles for one or more items in the collection, one item after the
+// other. A handle used by another item of the group, including one given earlier in the same call, fails
+// for its item with ErrDuplicateClientHandle.
 func (is *OPCItems) SetClientHandles(serverHandles []uint32, clientHandles []uint32) []error {
 	if is == nil {
 		return nil
 	}
 	resultErrors := make([]error, len(serverHandles))
+	if len(clientHandles) != len(serverHandles) {
+		return fillErrors(resultErrors, fmt.Errorf("got %d client handles for %d server handles", len(clientHandles), len(serverHandles)))
+	}
 	for i, handle := range serverHandles {
 		item, err := is.GetOPCItem(handle)
 		if err != nil {
@@ -374,20 +482,44 @@ func (is *OPCItems) SetDataTypes(serverHandles []uint32, requestedDataTypes []co
 		return nil
 	}
 	resultErrors := make([]error, len(serverHandles))
+	if len(requestedDataTypes) != len(serverHandles) {
+		return fillErrors(resultErrors, fmt.Errorf("got %d data types for %d server handles", len(requestedDataTypes), len(serverHandles)))
+	}
+	var items []*OPCItem
+	var types []com.VT
+	var indices []int
 	for i, handle := range serverHandles {
 		item, err := is.GetOPCItem(handle)
 		if err != nil {
 			resultErrors[i] = err
 			continue
 		}
-		err = item.SetRequestedDataType(requestedDataTypes[i])
+		items = append(items, item)
+		types = append(types, requestedDataTypes[i])
+		indices = append(indices, i)
+	}
+	if len(items) == 0 {
+		return resultErrors
+	}
+	errs, err := setDatatypes(is.itemMgtProvider, is.parent.server(), is.getError, items, types)
+	for n, i := range indices {
 		if err != nil {
 			resultErrors[i] = err
+			continue
 		}
+		resultErrors[i] = errs[n]
 	}
 	return resultErrors
 }
 
+// fillErrors sets every entry of errs to err and returns errs.
+func fillErrors(errs []error, err error) []error {
+	for i := range errs {
+		errs[i] = err
+	}
+	return errs
+}
+
 // Release releases the OPCItems collection and all associated resources.
 func (is *OPCItems) Release() {
 	if is == nil {
@@ -401,33 +533,106 @@ func (is *OPCItems) Release() {
 	}
 }
 
-func (is *OPCItems) createDefinitions(tags []string, accessPath string, active bool, requestedDataType com.VT) []com.TagOPCITEMDEF {
+// indexItem records the item under its client handle. The caller must hold is.
+func (is *OPCItems) indexItem(item *OPCItem) {
+	if is.byClientHandle == nil {
+		is.byClientHandle = make(map[uint32]*OPCItem)
+	}
+	is.byClientHandle[item.clientHandle] = item
+}
+
+// reindex rebuilds the client handle index from the items of the collection. The caller must hold is.
+func (is *OPCItems) reindex() {
+	is.byClientHandle = make(map[uint32]*OPCItem, len(is.items))
+	for _, item := range is.items {
+		is.byClientHandle[item.clientHandle] = item
+	}
+}
+
+// moveClientHandle re-indexes an item of the collection after its client handle changed.
+func (is *OPCItems) moveClientHandle(item *OPCItem, oldHandle, newHandle uint32) {
+	is.Lock()
+	defer is.Unlock()
+	if is.byClientHandle[oldHandle] == item {
+		delete(is.byClientHandle, oldHandle)
+	}
+	if is.byClientHandle == nil {
+		is.byClientHandle = make(map[uint32]*OPCItem)
+	}
+	is.byClientHandle[newHandle] = item
+}
+
+// clientHandleTaken reports whether an item of the collection other than item uses the client handle.
+func (is *OPCItems) clientHandleTaken(item *OPCItem, clientHandle uint32) bool {
+	is.RLock()
+	defer is.RUnlock()
+	for _, other := range is.items {
+		if other != item && other.GetClientHandle() == clientHandle {
+			return true
+		}
+	}
+	return false
+}
+
+// itemByClientHandle returns the item of the collection with the client handle.
+func (is *OPCItems) itemByClientHandle(clientHandle uint32) (*OPCItem, bool) {
+	is.RLock()
+	defer is.RUnlock()
+	item, ok := is.byClientHandle[clientHandle]
+	return item, ok
+}
+
+// clientHandlesInUse returns the client handles of the items of the collection and the handles chosen in defs,
+// failing if a chosen handle is already taken. The caller must hold is.
+func (is *OPCItems) clientHandlesInUse(defs []ItemDef) (map[uint32]struct{}, error) {
+	used := make(map[uint32]struct{}, len(is.items)+len(defs))
+	for _, item := range is.items {
+		used[item.GetClientHandle()] = struct{}{}
+	}
+	for _, def := range defs {
+		if def.ClientHandle == 0 {
+			continue
+		}
+		if _, ok := used[def.ClientHandle]; ok {
+			return nil, fmt.Errorf("%w: %d (%s)", ErrDuplicateClientHandle, def.ClientHandle, def.Tag)
+		}
+		used[def.ClientHandle] = struct{}{}
+	}
+	return used, nil
+}
+
+// createDefinitions builds the COM item definitions for defs, assigning client handles not in used where
+// none is given.
+func (is *OPCItems) createDefinitions(defs []ItemDef, used map[uint32]struct{}) []com.TagOPCITEMDEF {
 	var definitions []com.TagOPCITEMDEF
 	if is == nil {
 		return nil
 	}
-	for _, v := range tags {
-		cHandle := atomic.AddUint32(&is.itemID, 1)
+	for _, def := range defs {
+		cHandle := def.ClientHandle
+		for cHandle == 0 {
+			cHandle = atomic.AddUint32(&is.itemID, 1)
+			if _, ok := used[cHandle]; ok {
+				cHandle = 0
+			}
+		}
+		used[cHandle] = struct{}{}
 		definitions = append(definitions, com.TagOPCITEMDEF{
-			SzAccessPath: windows.StringToUTF16Ptr(accessPath),
-			SzItemID:     windows.StringToUTF16Ptr(v),
-			BActive:      com.BoolToComBOOL(active),
+			SzAccessPath: windows.StringToUTF16Ptr(def.AccessPath),
+			SzItemID:     windows.StringToUTF16Ptr(def.Tag),
+			BActive:      com.BoolToComBOOL(def.Active),
 			HClient:      cHandle,
 			DwBlobSize:   0,
 			PBlob:        nil,
-			VtRequested:  uint16(requestedDataType),
+			VtRequested:  uint16(def.RequestedDataType),
 		})
 	}
 	return definitions
 }
 
 func (is *OPCItems) getError(errorCode int32) error {
-	if is == nil || is.provider == nil {
-		return &OPCError{ErrorCode: errorCode, ErrorMessage: "uninitialized common interface"}
-	}
-	errStr, _ := is.provider.GetErrorString(uint32(errorCode))
-	return &OPCError{
-		ErrorCode:    errorCode,
-		ErrorMessage: errStr,
+	if is == nil {
+		return newOPCError(nil, nil, errorCode)
 	}
+	return newOPCError(is.parent.server(), is.provider, errorCode)
 }
@@ -3,8 +3,15 @@
 package opcda
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"math"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 	"unsafe"
 
@@ -18,81 +25,175 @@ import (
 type OPCServer struct {
 	provider   serverProvider
 	groups     *OPCGroups
-	Name       string     // Name is the ProgID of the server.
-	Node       string     // Node is the network node name where the server resides.
-	clientName string     // clientName is the name of the client application.
-	location   com.CLSCTX // location indicates whether the server is local or remote.
+	Name       string          // Name is the ProgID of the server.
+	Node       string          // Node is the network node name where the server resides.
+	clientName string          // clientName is the name of the client application.
+	location   com.CLSCTX      // location indicates whether the server is local or remote.
+	authInfo   *com.COAUTHINFO // authInfo holds the credentials used by ConnectWithCredentials, if any.
 
-	container *com.IConnectionPointContainer // container manages connection points.
-	point     *com.IConnectionPoint          // point is the specific connection point.
-	event     *ShutdownEventReceiver         // event receives shutdown notifications.
-	cookie    uint32                         // cookie identifies the advisory connection.
+	event  *ShutdownEventReceiver // event receives shutdown notifications.
+	cookie uint32                 // cookie identifies the advisory connection.
+
+	shutdownDropped uint64 // shutdownDropped counts shutdown notifications no subscriber could take.
+
+	inflight *asyncLimiter // inflight limits the async operations of all groups.
+
+	ctx    context.Context    // ctx is the root context of the connection; group callback loops derive from it.
+	cancel context.CancelFunc // cancel stops ctx and everything derived from it.
+
+	separator atomic.Pointer[string] // separator caches the namespace separator found by OPCBrowser.DetectSeparator.
+
+	itemIOLock     sync.Mutex     // itemIOLock guards the lazily queried IOPCItemIO interface.
+	itemIOProvider itemIOProvider // itemIOProvider is the IOPCItemIO interface, once queried.
+	itemIOErr      error          // itemIOErr is the error of the IOPCItemIO query, once queried.
+
+	reconnectLock  sync.Mutex // reconnectLock guards reconnectHooks.
+	reconnectHooks []func()   // reconnectHooks are called after every Reconnect.
+
+	scratchLock sync.Mutex // scratchLock guards scratch.
+	scratch     *OPCGroup  // scratch is the inactive group of TagInfo and ReadDevice, once created.
+
+	pinned *PinnedRuntime // pinned is the runtime whose thread makes the COM calls of the connection, if any.
+
+	factories *connectionFactories // factories create the objects of the connection beyond its providers.
+
+	errorStrings sync.Map // errorStrings caches the messages of GetErrorString by error code.
+
+	connectedSince atomic.Int64 // connectedSince is the time the connection was established, in Unix nanoseconds.
+	lastCall       atomic.Int64 // lastCall is the time of the last successful status call, in Unix nanoseconds.
+
+	quirksLock sync.Mutex   // quirksLock guards quirks and quirkLog.
+	quirks     Quirks       // quirks are the workarounds enabled with SetQuirks.
+	quirkLog   []QuirkEntry // quirkLog holds the most recent applications of workarounds.
 }
 
 // Connect establishes a connection to the OPC server.
 // It returns an OPCServer instance and an error if connection fails.
 func Connect(progID, node string) (opcServer *OPCServer, err error) {
+	return connect(progID, node, nil)
+}
+
+// ConnectWithCredentials establishes a connection to a remote OPC server as the given Windows account
+// instead of the identity of the calling process. The credentials are used for the DCOM activation
+// request and for calls made through the server interfaces. They are ignored when node is the local machine.
+//
+// Example:
+//
+//	server, err := opcda.ConnectWithCredentials("Matrikon.OPC.Simulation.1", "plant-pc", "operator", "PLANT", "secret")
+func ConnectWithCredentials(progID, node, username, domain, password string) (opcServer *OPCServer, err error) {
+	return connect(progID, node, com.NewCOAUTHINFO(username, domain, password))
+}
+
+// ConnectWithAuthInfo establishes a connection to a remote OPC server with explicit DCOM authentication
+// settings. They are used for the activation request and set on the proxy of every interface obtained
+// from the server, its groups and their connection points, so a connection can use a stronger level
+// than the process-wide CoInitializeSecurity settings. authInfo is ignored when node is the local
+// machine and must stay unchanged while the connection is in use.
+//
+// Example:
+//
+//	authInfo := com.NewCOAUTHINFO("operator", "PLANT", "secret")
+//	authInfo.DwAuthnLevel = com.RPC_C_AUTHN_LEVEL_PKT_INTEGRITY
+//	server, err := opcda.ConnectWithAuthInfo("Matrikon.OPC.Simulation.1", "plant-pc", authInfo)
+func ConnectWithAuthInfo(progID, node string, authInfo *com.COAUTHINFO) (opcServer *OPCServer, err error) {
+	if authInfo == nil {
+		return nil, errors.New("nil auth info")
+	}
+	return connect(progID, node, authInfo)
+}
+
+// connectInterfaces are the interfaces of the server object acquired by connect, named in connectInterfaceNames.
+var (
+	connectInterfaces     = []*windows.GUID{&com.IID_IOPCServer, &com.IID_IOPCCommon, &com.IID_IOPCItemProperties}
+	connectInterfaceNames = []string{"IOPCServer", "IOPCCommon", "IOPCItemProperties"}
+)
+
+// dialCOM establishes a connection to the OPC server, authenticating remote calls with authInfo when it is
+// not nil. The connection uses the factories f.
+func (f *connectionFactories) dialCOM(progID, node string, authInfo *com.COAUTHINFO) (opcServer *OPCServer, err error) {
 	location := com.CLSCTX_LOCAL_SERVER
 	if !com.IsLocal(node) {
 		location = com.CLSCTX_REMOTE_SERVER
 	}
-	clsid, err := getClsID(progID, node, location)
-	if err != nil {
-		return nil, NewOPCWrapperError("get clsid", err)
-	}
-	iUnknownServer, err := com.MakeCOMObjectEx(node, location, clsid, &com.IID_IOPCServer)
-	if err != nil {
-		return nil, NewOPCWrapperError("make com object IOPCServer", err)
+	if location == com.CLSCTX_LOCAL_SERVER {
+		authInfo = nil
 	}
-	defer func() {
+	var c cleanup
+	defer c.done(&err)
+	// all interfaces are requested in the activation call, saving a round trip each on remote servers
+	var itfs []*com.IUnknown
+	err = withResolvedServer(progID, node, location, authInfo, getClsID, func(clsid *windows.GUID) error {
+		acquired, err := makeServerObject(node, location, clsid, connectInterfaces, authInfo)
 		if err != nil {
-			iUnknownServer.Release()
+			return NewOPCWrapperError("make com object OPC server", err)
 		}
-	}()
-	var iUnknownCommon *com.IUnknown
-	err = iUnknownServer.QueryInterface(&com.IID_IOPCCommon, unsafe.Pointer(&iUnknownCommon))
-	if err != nil {
-		return nil, NewOPCWrapperError("server query interface IOPCCommon", err)
-	}
-	defer func() {
-		if err != nil {
-			iUnknownCommon.Release()
+		itfs = acquired
+		for _, itf := range acquired {
+			c.addInterface(itf)
 		}
-	}()
-	var iUnknownItemProperties *com.IUnknown
-	err = iUnknownServer.QueryInterface(&com.IID_IOPCItemProperties, unsafe.Pointer(&iUnknownItemProperties))
+		return nil
+	})
 	if err != nil {
-		return nil, NewOPCWrapperError("server query interface IOPCItemProperties", err)
+		return nil, err
 	}
-	defer func() {
+	for i, itf := range itfs {
+		err = setProxyBlanket(itf, authInfo)
 		if err != nil {
-			iUnknownItemProperties.Release()
+			return nil, NewOPCWrapperError("set proxy blanket "+connectInterfaceNames[i], err)
 		}
-	}()
-	server := &com.IOPCServer{IUnknown: iUnknownServer}
-	common := &com.IOPCCommon{IUnknown: iUnknownCommon}
-	itemProperties := &com.IOPCItemProperties{IUnknown: iUnknownItemProperties}
+	}
+	server := &com.IOPCServer{IUnknown: itfs[0]}
+	common := &com.IOPCCommon{IUnknown: itfs[1]}
+	itemProperties := &com.IOPCItemProperties{IUnknown: itfs[2]}
 	opcServer = &OPCServer{
 		provider: &comServerProvider{
 			iServer:       server,
 			iCommon:       common,
 			iItemProperty: itemProperties,
+			authInfo:      authInfo,
 		},
-		Name:     progID,
-		Node:     node,
-		location: location,
-	}
+		Name:      progID,
+		Node:      node,
+		location:  location,
+		authInfo:  authInfo,
+		factories: f,
+		inflight:  newAsyncLimiter(),
+	}
+	opcServer.connectedSince.Store(time.Now().UnixNano())
+	opcServer.ctx, opcServer.cancel = context.WithCancel(context.Background())
 	opcServer.groups = NewOPCGroups(opcServer)
 	return opcServer, nil
 }
 
+// setProxyBlanket applies authInfo to an interface proxy. It does nothing when authInfo is nil.
+func setProxyBlanket(proxy *com.IUnknown, authInfo *com.COAUTHINFO) error {
+	if authInfo == nil {
+		return nil
+	}
+	return coSetProxyBlanket(proxy, authInfo)
+}
+
+// setProxyBlankets applies authInfo to several interface proxies, stopping at the first failure.
+func setProxyBlankets(authInfo *com.COAUTHINFO, proxies ...*com.IUnknown) error {
+	for _, proxy := range proxies {
+		if err := setProxyBlanket(proxy, authInfo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // newOPCServerWithProvider creates a new OPCServer with a specific provider (used for testing).
 func newOPCServerWithProvider(provider serverProvider, name string, node string) *OPCServer {
 	s := &OPCServer{
-		provider: provider,
-		Name:     name,
-		Node:     node,
-	}
+		provider:  provider,
+		Name:      name,
+		Node:      node,
+		factories: comFactories(),
+		inflight:  newAsyncLimiter(),
+	}
+	s.connectedSince.Store(time.Now().UnixNano())
+	s.ctx, s.cancel = context.WithCancel(context.Background())
 	s.groups = NewOPCGroups(s)
 	return s
 }
@@ -101,36 +202,42 @@ func newOPCServerWithProvider(provider serverProvider, name string, node string)
 // 1. IOPCServerList2 (V2) - Modern interface with category filtering.
 // 2. IOPCServerList (V1) - Legacy interface.
 // 3. Windows Registry - Direct lookup.
-func getClsID(progID, node string, location com.CLSCTX) (clsid *windows.GUID, err error) {
-	var errorList []error
+// authInfo authenticates the server list lookups and may be nil; the registry lookup always uses the process identity.
+// When every method fails the error is a *DiscoveryError.
+func getClsID(progID, node string, location com.CLSCTX, authInfo *com.COAUTHINFO) (clsid *windows.GUID, err error) {
+	discoveryErr := &DiscoveryError{Op: "resolve ProgID " + progID}
 	// try get clsid from server list
-	clsid, err = getClsIDFromServerListV2(progID, node, location)
+	clsid, err = getClsIDFromServerListV2(progID, node, location, authInfo)
 	if err == nil {
 		return clsid, nil
 	}
-	errorList = append(errorList, fmt.Errorf("get clsid from server list v2 error: %v", err))
+	discoveryErr.add(StageServerListV2, err)
 	// try v1
-	clsid, err = getClsIDFromServerListV1(progID, node, location)
+	clsid, err = getClsIDFromServerListV1(progID, node, location, authInfo)
 	if err == nil {
 		return clsid, nil
 	}
-	errorList = append(errorList, fmt.Errorf("get clsid from server list v1 error: %v", err))
+	discoveryErr.add(StageServerListV1, err)
 	// try get clsid from windows reg
 	clsid, err = getClsIDFromReg(progID, node)
 	if err == nil {
 		return clsid, nil
 	}
-	errorList = append(errorList, fmt.Errorf("get clsid from reg error: %v", err))
-	return nil, errors.Join(errorList...)
+	discoveryErr.add(StageRegistry, err)
+	return nil, discoveryErr
 }
 
 // getClsIDFromServerListV2 attempts to get CLSID using the modern IOPCServerList2 interface (OPC DA 2.0+).
-func getClsIDFromServerListV2(progID, node string, location com.CLSCTX) (*windows.GUID, error) {
-	iCatInfo, err := com.MakeCOMObjectEx(node, location, &com.CLSID_OpcServerList, &com.IID_IOPCServerList2)
+func getClsIDFromServerListV2(progID, node string, location com.CLSCTX, authInfo *com.COAUTHINFO) (*windows.GUID, error) {
+	iCatInfo, err := com.MakeCOMObjectExAuth(node, location, &com.CLSID_OpcServerList, &com.IID_IOPCServerList2, authInfo)
 	if err != nil {
 		return nil, err
 	}
 	defer iCatInfo.Release()
+	err = setProxyBlanket(iCatInfo, authInfo)
+	if err != nil {
+		return nil, err
+	}
 	sl := &com.IOPCServerList2{IUnknown: iCatInfo}
 	clsid, err := sl.CLSIDFromProgID(progID)
 	if err != nil {
@@ -140,12 +247,16 @@ func getClsIDFromServerListV2(progID, node string, location com.CLSCTX) (*window
 }
 
 // getClsIDFromServerListV1 attempts to get CLSID using the legacy IOPCServerList interface (OPC DA 1.0).
-func getClsIDFromServerListV1(progID, node string, location com.CLSCTX) (*windows.GUID, error) {
-	iCatInfo, err := com.MakeCOMObjectEx(node, location, &com.CLSID_OpcServerList, &com.IID_IOPCServerList)
+func getClsIDFromServerListV1(progID, node string, location com.CLSCTX, authInfo *com.COAUTHINFO) (*windows.GUID, error) {
+	iCatInfo, err := com.MakeCOMObjectExAuth(node, location, &com.CLSID_OpcServerList, &com.IID_IOPCServerList, authInfo)
 	if err != nil {
 		return nil, err
 	}
 	defer iCatInfo.Release()
+	err = setProxyBlanket(iCatInfo, authInfo)
+	if err != nil {
+		return nil, err
+	}
 	sl := &com.IOPCServerList{IUnknown: iCatInfo}
 	clsid, err := sl.CLSIDFromProgID(progID)
 	if err != nil {
@@ -154,23 +265,15 @@ func getClsIDFromServerListV1(progID, node string, location com.CLSCTX) (*window
 	return clsid, nil
 }
 
-// getClsIDFromReg retrieves CLSID directly from Windows Registry.
+// getClsIDFromReg retrieves CLSID directly from Windows Registry, looking in HKEY_CLASSES_ROOT and the
+// 64-bit and 32-bit machine classes in turn.
 func getClsIDFromReg(progID, node string) (*windows.GUID, error) {
-	var clsid *windows.GUID
-	var err error
-	hKey, err := registry.OpenRemoteKey(node, registry.CLASSES_ROOT)
-	if err != nil {
-		return nil, err
-	}
-	defer hKey.Close()
-
-	hProgIDKey, err := registry.OpenKey(hKey, progID, registry.READ)
+	views, err := openRegistryViews(node)
 	if err != nil {
 		return nil, err
 	}
-	defer hProgIDKey.Close()
-	_, clsid, err = getClsidFromProgIDKey(hProgIDKey)
-	return clsid, err
+	defer closeRegistryViews(views)
+	return clsIDFromViews(views, progID)
 }
 
 // getClsidFromProgIDKey helper to extract CLSID string and GUID from a registry key.
@@ -194,187 +297,297 @@ type ServerInfo struct {
 	ClsStr       string        // ClsStr is the CLSID string representation.
 	VerIndProgID string        // VerIndProgID is the Version Independent ProgID.
 	ClsID        *windows.GUID // ClsID is the unique Class ID of the server.
+	Description  string        // Description is the user type name reported by the server list, if any.
 }
 
-// GetOPCServers enumerates available OPC servers on a node.
-// It employs a fallback strategy: IOPCServerList2 (V2) -> IOPCServerList (V1) -> Registry.
-func GetOPCServers(node string) ([]*ServerInfo, error) {
-	var errorList []error
-	result, err := getServersFromOpcServerListV2(node)
-	if err == nil {
-		return result, nil
-	}
-	errorList = append(errorList, fmt.Errorf("get servers from opc server list v2 error: %v", err))
-	// try v1
-	result, err = getServersFromOpcServerListV1(node)
-	if err == nil {
-		return result, nil
+// DAVersion selects an OPC DA specification version when enumerating servers.
+type DAVersion int
+
+const (
+	// DAVersion10 selects servers registered in the OPC DA 1.0 category.
+	DAVersion10 DAVersion = iota + 1
+	// DAVersion20 selects servers registered in the OPC DA 2.0 category.
+	DAVersion20
+	// DAVersion30 selects servers registered in the OPC DA 3.0 category.
+	DAVersion30
+)
+
+// category returns the component category CATID of the version.
+func (v DAVersion) category() (windows.GUID, bool) {
+	switch v {
+	case DAVersion10:
+		return IID_CATID_OPCDAServer10, true
+	case DAVersion20:
+		return IID_CATID_OPCDAServer20, true
+	case DAVersion30:
+		return IID_CATID_OPCDAServer30, true
+	}
+	return windows.GUID{}, false
+}
+
+// ServerListOption configures GetOPCServers and GetOPCServersContext.
+type ServerListOption func(*serverListOptions)
+
+// serverListOptions holds the settings applied by ServerListOption values.
+type serverListOptions struct {
+	versions     []DAVersion
+	skipRegistry bool
+	withAuth     bool
+	auth         *com.COAUTHIDENTITY
+}
+
+// WithDAVersions restricts GetOPCServers to servers registered for the given OPC DA versions.
+// By default servers of all versions are listed.
+func WithDAVersions(versions ...DAVersion) ServerListOption {
+	return func(o *serverListOptions) {
+		o.versions = append([]DAVersion{}, versions...)
 	}
-	errorList = append(errorList, fmt.Errorf("get servers from opc server list v1 error: %v", err))
-	// try windows reg
-	result, err = getServersFromReg(node)
-	if err == nil {
-		return result, nil
+}
+
+// WithoutRegistry disables the registry fallback used when neither server list interface is available.
+func WithoutRegistry() ServerListOption {
+	return func(o *serverListOptions) {
+		o.skipRegistry = true
 	}
-	errorList = append(errorList, fmt.Errorf("get servers from reg error: %v", err))
-	return nil, errors.Join(errorList...)
 }
 
-// getServersFromOpcServerListV2 enumerates servers using the modern IOPCServerList2 interface (OPC DA 2.0+).
-func getServersFromOpcServerListV2(node string) ([]*ServerInfo, error) {
-	location := com.CLSCTX_LOCAL_SERVER
-	if !com.IsLocal(node) {
-		location = com.CLSCTX_REMOTE_SERVER
+// WithAuth authenticates the server list lookups on a remote node as auth instead of the process identity,
+// for example with a local account of a workgroup machine. It is ignored for the local node. The registry
+// fallback cannot carry the credentials and is not used. A nil auth makes the enumeration fail.
+//
+// Example:
+//
+//	servers, err := opcda.GetOPCServers("plant-pc", opcda.WithAuth(com.NewCOAUTHIDENTITY("operator", "PLANT-PC", "secret")))
+func WithAuth(auth *com.COAUTHIDENTITY) ServerListOption {
+	return func(o *serverListOptions) {
+		o.withAuth = true
+		o.auth = auth
 	}
-	iCatInfo, err := com.MakeCOMObjectEx(node, location, &com.CLSID_OpcServerList, &com.IID_IOPCServerList2)
-	if err != nil {
-		return nil, NewOPCWrapperError("make com object IOPCServerListV2", err)
+}
+
+// serverCategories returns the CATIDs to enumerate for the options, without duplicates.
+func (o *serverListOptions) serverCategories() ([]windows.GUID, error) {
+	versions := o.versions
+	if versions == nil {
+		versions = []DAVersion{DAVersion10, DAVersion20, DAVersion30}
+	}
+	var cids []windows.GUID
+	seen := make(map[DAVersion]bool, len(versions))
+	for _, v := range versions {
+		cid, ok := v.category()
+		if !ok {
+			return nil, fmt.Errorf("unknown OPC DA version %d", v)
+		}
+		if seen[v] {
+			continue
+		}
+		seen[v] = true
+		cids = append(cids, cid)
 	}
-	cids := []windows.GUID{IID_CATID_OPCDAServer10, IID_CATID_OPCDAServer20}
-	defer iCatInfo.Release()
-	sl := &com.IOPCServerList2{IUnknown: iCatInfo}
-	iEnum, err := sl.EnumClassesOfCategories(cids, nil)
-	if err != nil {
-		return nil, NewOPCWrapperError("enum classes of categories with IOPCServerListV2", err)
+	if len(cids) == 0 {
+		return nil, errors.New("no OPC DA version selected")
 	}
-	defer iEnum.Release()
-	var result []*ServerInfo
-	for {
-		var classID windows.GUID
-		var actual uint32
-		err = iEnum.Next(1, &classID, &actual)
-		if err != nil {
-			break
-		}
-		server, err := getServer(sl, &classID)
-		if err != nil {
-			return nil, NewOPCWrapperError("IOPCServerListV2 getServer", err)
+	return cids, nil
+}
+
+// dedupeServers drops servers whose class ID was already listed, keeping the first occurrence.
+// Servers registered in several categories may be reported once per category.
+func dedupeServers(servers []*ServerInfo) []*ServerInfo {
+	seen := make(map[string]bool, len(servers))
+	result := servers[:0]
+	for _, server := range servers {
+		key := strings.ToUpper(server.ClsStr)
+		if seen[key] {
+			continue
 		}
+		seen[key] = true
 		result = append(result, server)
 	}
-	return result, nil
+	return result
 }
 
-// getServersFromOpcServerListV1 enumerates servers using the legacy IOPCServerList interface (OPC DA 1.0).
-func getServersFromOpcServerListV1(node string) ([]*ServerInfo, error) {
-	location := com.CLSCTX_LOCAL_SERVER
-	if !com.IsLocal(node) {
-		location = com.CLSCTX_REMOTE_SERVER
-	}
-	iCatInfo, err := com.MakeCOMObjectEx(node, location, &com.CLSID_OpcServerList, &com.IID_IOPCServerList)
-	if err != nil {
-		return nil, NewOPCWrapperError("make com object IOPCServerListV1", err)
-	}
-	cids := []windows.GUID{IID_CATID_OPCDAServer10, IID_CATID_OPCDAServer20}
-	defer iCatInfo.Release()
-	sl := &com.IOPCServerList{IUnknown: iCatInfo}
-	iEnum, err := sl.EnumClassesOfCategories(cids, nil)
+// GetOPCServers enumerates available OPC servers on a node.
+// It employs a fallback strategy: IOPCServerList2 (V2) -> IOPCServerList (V1) -> Registry.
+// Servers of the OPC DA 1.0, 2.0 and 3.0 categories are listed unless restricted with WithDAVersions;
+// the registry fallback does not record versions and lists every OPC server it finds.
+// Each server is listed once even if it is registered in several categories. Servers found through the
+// server list carry the user type reported by the server list as Description; the registry fallback
+// leaves it empty. WithoutRegistry and WithAuth change the fallback chain.
+// If every stage fails, the error is a *DiscoveryError holding the failure of each stage.
+//
+// Example:
+//
+//	servers, err := opcda.GetOPCServers("localhost", opcda.WithDAVersions(opcda.DAVersion30), opcda.WithoutRegistry())
+func GetOPCServers(node string, options ...ServerListOption) ([]*ServerInfo, error) {
+	return GetOPCServersContext(context.Background(), node, options...)
+}
+
+// serversFromRegistry scans the registry of a node for OPC servers; tests replace it with a mock.
+var serversFromRegistry = getServersFromReg
+
+// GetOPCServersContext enumerates available OPC servers on a node like GetOPCServers, giving up when ctx is
+// done instead of waiting for the DCOM timeout of an unreachable node. Each fallback stage checks ctx before
+// it starts and runs in its own goroutine, which initializes COM in the multithreaded apartment on its
+// thread, so the calling goroutine needs no COM of its own for the enumeration. A stage abandoned by
+// cancellation still releases its COM objects when it eventually completes. The returned error wraps
+// ctx.Err() with the stage that was in flight: ServerList2, ServerList1 or registry.
+func GetOPCServersContext(ctx context.Context, node string, options ...ServerListOption) ([]*ServerInfo, error) {
+	var opts serverListOptions
+	for _, option := range options {
+		option(&opts)
+	}
+	return getOPCServers(ctx, node, &opts)
+}
+
+// getOPCServers runs the server enumeration fallback chain, honouring cancellation of ctx.
+// When every stage fails the error is a *DiscoveryError.
+func getOPCServers(ctx context.Context, node string, opts *serverListOptions) ([]*ServerInfo, error) {
+	cids, err := opts.serverCategories()
 	if err != nil {
-		return nil, NewOPCWrapperError("enum classes of categories with IOPCServerListV1", err)
+		return nil, err
 	}
-	defer iEnum.Release()
-	var result []*ServerInfo
-	for {
-		var classID windows.GUID
-		var actual uint32
-		err = iEnum.Next(1, &classID, &actual)
-		if err != nil {
-			break
+	var authInfo *com.COAUTHINFO
+	if opts.withAuth {
+		if opts.auth == nil {
+			return nil, errors.New("nil auth identity")
 		}
-		server, err := getServerV1(sl, &classID)
-		if err != nil {
-			return nil, NewOPCWrapperError("IOPCServerListV1 getServer", err)
+		if !com.IsLocal(node) {
+			authInfo = com.NewCOAUTHINFOWithIdentity(opts.auth)
 		}
-		result = append(result, server)
 	}
-	return result, nil
+	discoveryErr := &DiscoveryError{Op: "enumerate servers on " + strconv.Quote(node)}
+	result, err := runEnumerationStage(ctx, "ServerList2", func() ([]*ServerInfo, error) {
+		return getServersFromOpcServerListV2(node, cids, authInfo)
+	})
+	if err == nil {
+		return dedupeServers(result), nil
+	}
+	if ctx.Err() != nil {
+		return nil, err
+	}
+	discoveryErr.add(StageServerListV2, err)
+	// try v1
+	result, err = runEnumerationStage(ctx, "ServerList1", func() ([]*ServerInfo, error) {
+		return getServersFromOpcServerListV1(node, cids, authInfo)
+	})
+	if err == nil {
+		return dedupeServers(result), nil
+	}
+	if ctx.Err() != nil {
+		return nil, err
+	}
+	discoveryErr.add(StageServerListV1, err)
+	if opts.skipRegistry || authInfo != nil {
+		return nil, discoveryErr
+	}
+	// try windows reg
+	result, err = runEnumerationStage(ctx, "registry", func() ([]*ServerInfo, error) {
+		return serversFromRegistry(node)
+	})
+	if err == nil {
+		return dedupeServers(result), nil
+	}
+	if ctx.Err() != nil {
+		return nil, err
+	}
+	discoveryErr.add(StageRegistry, err)
+	return nil, discoveryErr
 }
 
-// getServersFromReg enumerates servers by scanning the registry (last resort fallback method).
-func getServersFromReg(node string) ([]*ServerInfo, error) {
-	var result []*ServerInfo
-	var hKey registry.Key
-	var err error
-	if node == "" || node == "localhost" {
-		hKey = registry.CLASSES_ROOT
-	} else {
-		hKey, err = registry.OpenRemoteKey(node, registry.CLASSES_ROOT)
-		if err != nil {
-			return nil, err
+// enumerationResult carries the outcome of an enumeration stage out of its goroutine.
+type enumerationResult struct {
+	servers []*ServerInfo
+	err     error
+}
+
+// runEnumerationStage runs one enumeration stage. With a cancellable ctx the stage runs in a goroutine
+// that is abandoned when ctx is done; the stage releases its own COM objects, so a late completion leaks
+// nothing. The error of a cancelled stage wraps ctx.Err() with the stage name.
+func runEnumerationStage(ctx context.Context, stage string, enumerate func() ([]*ServerInfo, error)) ([]*ServerInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, NewOPCWrapperError("enumerate servers with "+stage, err)
+	}
+	if ctx.Done() == nil {
+		return enumerate()
+	}
+	done := make(chan enumerationResult, 1)
+	go func() {
+		// the goroutine may run on a thread that has not joined COM
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		uninitialize, err := initCOMThread()
+		if err != nil && !errors.Is(err, com.ErrApartmentMismatch) {
+			done <- enumerationResult{err: NewOPCWrapperError("enumerate servers with "+stage, err)}
+			return
 		}
-		defer hKey.Close()
-	}
-	tsKeys, _ := hKey.ReadSubKeyNames(-1)
-	for _, tsKey := range tsKeys {
-		info := getServersFromKey(hKey, tsKey)
-		if info != nil {
-			result = append(result, info)
+		if uninitialize != nil {
+			defer uninitialize()
 		}
+		servers, err := enumerate()
+		done <- enumerationResult{servers: servers, err: err}
+	}()
+	select {
+	case r := <-done:
+		return r.servers, r.err
+	case <-ctx.Done():
+		return nil, NewOPCWrapperError("enumerate servers with "+stage, ctx.Err())
 	}
-	return result, nil
 }
 
-// getServersFromKey helper to extract server info from a registry key.
-func getServersFromKey(hKey registry.Key, progID string) *ServerInfo {
-	hProgIDKey, err := registry.OpenKey(hKey, progID, registry.READ)
+// getServersFromOpcServerListV2 enumerates servers of the given categories using the modern IOPCServerList2 interface (OPC DA 2.0+).
+func getServersFromOpcServerListV2(node string, cids []windows.GUID, authInfo *com.COAUTHINFO) ([]*ServerInfo, error) {
+	sl, err := newServerList2Provider(node, authInfo)
 	if err != nil {
-		return nil
-	}
-	defer hProgIDKey.Close()
-	hOPCKey, err := registry.OpenKey(hProgIDKey, "OPC", registry.READ)
-	if err != nil {
-		return nil
+		return nil, err
 	}
-	defer hOPCKey.Close()
-	clsidStr, clsid, err := getClsidFromProgIDKey(hProgIDKey)
+	defer sl.Release()
+	return listServers(sl, cids, "IOPCServerListV2")
+}
+
+// getServersFromOpcServerListV1 enumerates servers of the given categories using the legacy IOPCServerList interface (OPC DA 1.0).
+func getServersFromOpcServerListV1(node string, cids []windows.GUID, authInfo *com.COAUTHINFO) ([]*ServerInfo, error) {
+	sl, err := newServerListProvider(node, authInfo)
 	if err != nil {
-		return nil
-	}
-	return &ServerInfo{
-		ProgID:       progID,
-		ClsStr:       clsidStr,
-		VerIndProgID: progID,
-		ClsID:        clsid,
+		return nil, err
 	}
+	defer sl.Release()
+	return listServers(sl, cids, "IOPCServerListV1")
 }
 
-// getServer helper to extract details from IOPCServerList2.
-func getServer(sl *com.IOPCServerList2, classID *windows.GUID) (*ServerInfo, error) {
-	progID, userType, VerIndProgID, err := sl.GetClassDetails(classID)
+// listServers enumerates the servers of the given categories and reads their class details.
+func listServers(sl serverListProvider, cids []windows.GUID, name string) ([]*ServerInfo, error) {
+	classIDs, err := sl.EnumClassesOfCategories(cids)
 	if err != nil {
-		return nil, fmt.Errorf("FAILED to get prog ID from class ID: %w", err)
+		return nil, NewOPCWrapperError("enum classes of categories with "+name, err)
 	}
-	defer func() {
-		com.CoTaskMemFree(unsafe.Pointer(progID))
-		com.CoTaskMemFree(unsafe.Pointer(userType))
-		com.CoTaskMemFree(unsafe.Pointer(VerIndProgID))
-	}()
-	clsStr := classID.String()
-	return &ServerInfo{
-		ProgID:       windows.UTF16PtrToString(progID),
-		ClsStr:       clsStr,
-		ClsID:        classID,
-		VerIndProgID: windows.UTF16PtrToString(VerIndProgID),
-	}, nil
+	var result []*ServerInfo
+	for i := range classIDs {
+		classID := &classIDs[i]
+		progID, userType, verIndProgID, err := sl.GetClassDetails(classID)
+		if err != nil {
+			return nil, NewOPCWrapperError(name+" getServer", fmt.Errorf("FAILED to get prog ID from class ID: %w", err))
+		}
+		result = append(result, &ServerInfo{
+			ProgID:       progID,
+			ClsStr:       classID.String(),
+			ClsID:        classID,
+			VerIndProgID: verIndProgID,
+			Description:  userType,
+		})
+	}
+	return result, nil
 }
 
-// getServerV1 helper to extract details from IOPCServerList.
-func getServerV1(sl *com.IOPCServerList, classID *windows.GUID) (*ServerInfo, error) {
-	progID, userType, err := sl.GetClassDetails(classID)
+// getServersFromReg enumerates servers by scanning the registry (last resort fallback method).
+// HKEY_CLASSES_ROOT, HKEY_LOCAL_MACHINE\SOFTWARE\Classes and its WOW6432Node counterpart are scanned, so
+// 32-bit servers on 64-bit Windows are found too; each CLSID is listed once.
+func getServersFromReg(node string) ([]*ServerInfo, error) {
+	views, err := openRegistryViews(node)
 	if err != nil {
-		return nil, fmt.Errorf("FAILED to get prog ID from class ID: %w", err)
+		return nil, err
 	}
-	defer func() {
-		com.CoTaskMemFree(unsafe.Pointer(progID))
-		com.CoTaskMemFree(unsafe.Pointer(userType))
-	}()
-	clsStr := classID.String()
-	return &ServerInfo{
-		ProgID:       windows.UTF16PtrToString(progID),
-		ClsStr:       clsStr,
-		ClsID:        classID,
-		VerIndProgID: "",
-	}, nil
+	defer closeRegistryViews(views)
+	return serversFromViews(views), nil
 }
 
 // GetLocaleID returns the current locale ID.
@@ -391,7 +604,7 @@ func (s *OPCServer) GetStartTime() (time.Time, error) {
 	if s == nil || s.provider == nil {
 		return time.Time{}, errors.New("uninitialized server connection")
 	}
-	status, err := s.provider.GetStatus()
+	status, err := s.getStatus()
 	if err != nil {
 		return time.Time{}, err
 	}
@@ -403,7 +616,7 @@ func (s *OPCServer) GetCurrentTime() (time.Time, error) {
 	if s == nil || s.provider == nil {
 		return time.Time{}, errors.New("uninitialized server connection")
 	}
-	status, err := s.provider.GetStatus()
+	status, err := s.getStatus()
 	if err != nil {
 		return time.Time{}, err
 	}
@@ -415,7 +628,7 @@ func (s *OPCServer) GetLastUpdateTime() (time.Time, error) {
 	if s == nil || s.provider == nil {
 		return time.Time{}, errors.New("uninitialized server connection")
 	}
-	status, err := s.provider.GetStatus()
+	status, err := s.getStatus()
 	if err != nil {
 		return time.Time{}, err
 	}
@@ -427,7 +640,7 @@ func (s *OPCServer) GetMajorVersion() (uint16, error) {
 	if s == nil || s.provider == nil {
 		return 0, errors.New("uninitialized server connection")
 	}
-	status, err := s.provider.GetStatus()
+	status, err := s.getStatus()
 	if err != nil {
 		return 0, err
 	}
@@ -439,7 +652,7 @@ func (s *OPCServer) GetMinorVersion() (uint16, error) {
 	if s == nil || s.provider == nil {
 		return 0, errors.New("uninitialized server connection")
 	}
-	status, err := s.provider.GetStatus()
+	status, err := s.getStatus()
 	if err != nil {
 		return 0, err
 	}
@@ -451,7 +664,7 @@ func (s *OPCServer) GetBuildNumber() (uint16, error) {
 	if s == nil || s.provider == nil {
 		return 0, errors.New("uninitialized server connection")
 	}
-	status, err := s.provider.GetStatus()
+	status, err := s.getStatus()
 	if err != nil {
 		return 0, err
 	}
@@ -463,7 +676,7 @@ func (s *OPCServer) GetVendorInfo() (string, error) {
 	if s == nil || s.provider == nil {
 		return "", errors.New("uninitialized server connection")
 	}
-	status, err := s.provider.GetStatus()
+	status, err := s.getStatus()
 	if err != nil {
 		return "", err
 	}
@@ -475,7 +688,7 @@ func (s *OPCServer) GetServerState() (com.OPCServerState, error) {
 	if s == nil || s.provider == nil {
 		return 0, errors.New("uninitialized server connection")
 	}
-	status, err := s.provider.GetStatus()
+	status, err := s.getStatus()
 	if err != nil {
 		return 0, err
 	}
@@ -487,21 +700,87 @@ func (s *OPCServer) SetLocaleID(localeID uint32) error {
 	if s == nil || s.provider == nil {
 		return errors.New("uninitialized server connection")
 	}
-	return s.provider.SetLocaleID(localeID)
+	err := s.provider.SetLocaleID(localeID)
+	// error messages are localized
+	s.clearErrorMessages()
+	return err
 }
 
-// GetBandwidth returns the bandwidth of the server.
+// GetBandwidth returns the bandwidth of the server as reported in its status.
+// Servers that do not measure bandwidth report OPC_BANDWIDTH_NOT_SUPPORTED (0xFFFFFFFF);
+// use GetBandwidthInfo to tell this apart from a real value.
 func (s *OPCServer) GetBandwidth() (uint32, error) {
 	if s == nil || s.provider == nil {
 		return 0, errors.New("uninitialized server connection")
 	}
-	status, err := s.provider.GetStatus()
+	status, err := s.getStatus()
 	if err != nil {
 		return 0, err
 	}
 	return status.BandWidth, nil
 }
 
+// GetBandwidthInfo returns the bandwidth of the server, as a percentage of the available bandwidth, and
+// whether the server reports it at all. If the server reports OPC_BANDWIDTH_NOT_SUPPORTED, supported is
+// false and bandwidth is 0, so a bandwidth of 0 with supported true means the server is idle.
+func (s *OPCServer) GetBandwidthInfo() (bandwidth uint32, supported bool, err error) {
+	bandwidth, err = s.GetBandwidth()
+	if err != nil {
+		return 0, false, err
+	}
+	if bandwidth == OPC_BANDWIDTH_NOT_SUPPORTED {
+		return 0, false, nil
+	}
+	return bandwidth, true, nil
+}
+
+// GetGroupCount returns the number of groups the server currently holds for all of its clients.
+func (s *OPCServer) GetGroupCount() (uint32, error) {
+	if s == nil || s.provider == nil {
+		return 0, errors.New("uninitialized server connection")
+	}
+	status, err := s.getStatus()
+	if err != nil {
+		return 0, err
+	}
+	return status.GroupCount, nil
+}
+
+// SupportedUpdateRateRange returns the fastest and slowest update rates the server accepts, in milliseconds.
+// The server does not publish these limits, so they are probed by adding two temporary inactive groups
+// requesting a rate of 1 ms and math.MaxUint32 ms and reading the revised rates. Both groups are removed
+// before returning.
+func (s *OPCServer) SupportedUpdateRateRange() (fastest, slowest uint32, err error) {
+	if s == nil || s.provider == nil {
+		return 0, 0, errors.New("uninitialized server connection")
+	}
+	fastest, err = s.probeUpdateRate(1)
+	if err != nil {
+		return 0, 0, err
+	}
+	slowest, err = s.probeUpdateRate(math.MaxUint32)
+	if err != nil {
+		return 0, 0, err
+	}
+	return fastest, slowest, nil
+}
+
+// probeUpdateRate adds a temporary inactive group with the requested update rate and returns the rate revised by the server.
+func (s *OPCServer) probeUpdateRate(requested uint32) (revised uint32, err error) {
+	serverGroup, revised, ppUnk, err := s.provider.AddGroup("", false, requested, 0, nil, nil, 0, &com.IID_IOPCGroupStateMgt)
+	if err != nil {
+		return 0, NewOPCWrapperError("probe update rate add group", err)
+	}
+	if ppUnk != nil {
+		ppUnk.Release()
+	}
+	err = s.provider.RemoveGroup(serverGroup, true)
+	if err != nil {
+		return 0, NewOPCWrapperError("probe update rate remove group", err)
+	}
+	return revised, nil
+}
+
 // GetOPCGroups returns the collection of OPCGroup objects.
 func (s *OPCServer) GetOPCGroups() *OPCGroups {
 	if s == nil {
@@ -588,31 +867,131 @@ func (s *OPCServer) QueryAvailableProperties(itemID string) (pPropertyIDs []uint
 }
 
 // GetItemProperties returns a list of the current data values for the passed ID codes.
+// A property whose value has a VARIANT type that cannot be converted is returned as its raw com.VT,
+// with a *com.ErrUnsupportedVariant in the matching itemErrors entry; the other properties are unaffected.
+// With Quirks.RetryPropertiesIndividually enabled, such properties and those failing with E_FAIL are requested
+// again on their own; properties that still fail have a *QuirkError in their itemErrors entry.
+// An empty propertyIDs returns empty results without calling the server.
 func (s *OPCServer) GetItemProperties(itemID string, propertyIDs []uint32) (data []interface{}, itemErrors []error, err error) {
 	if s == nil || s.provider == nil {
 		return nil, nil, errors.New("uninitialized server connection")
 	}
+	if len(propertyIDs) == 0 {
+		return nil, nil, nil
+	}
 	var errs []int32
 	data, errs, err = s.provider.GetItemProperties(itemID, propertyIDs)
 	if err != nil {
 		return nil, nil, err
 	}
 	itemErrors = s.errors(errs)
+	splitUnsupportedValues(data, itemErrors)
+	if s.GetQuirks().RetryPropertiesIndividually {
+		s.retryProperties(itemID, propertyIDs, errs, data, itemErrors)
+	}
 	return data, itemErrors, nil
 }
 
-// LookupItemIDs returns a list of ItemIDs (if available) for each of the passed ID codes.
-// have not tested because simulator return error
-func (s *OPCServer) LookupItemIDs(itemID string, propertyIDs []uint32) ([]string, []error, error) {
+// PropertyItemID is the outcome of looking up the item ID of one property of an item.
+type PropertyItemID struct {
+	// PropertyID is the property that was looked up.
+	PropertyID uint32
+	// ItemID is the item ID of the property; it is empty when Err is set.
+	ItemID string
+	// Err is the error the server reported for the property, such as OPC_E_INVALID_PID for properties
+	// without an item ID of their own.
+	Err error
+}
+
+// LookupItemIDs returns, for each of the passed property IDs, the item ID that can be added to a group
+// to access that property of the item directly. Properties the server cannot map, which includes IDs 1 to 6
+// describing the item itself, have Err set instead of failing the call; servers report them with S_FALSE.
+// An empty propertyIDs returns nil without calling the server.
+//
+// Example:
+//
+//	ids, err := server.LookupItemIDs("Random.Int4", []uint32{100, 101})
+//	for _, id := range ids {
+//		if id.Err == nil {
+//			fmt.Println(id.PropertyID, id.ItemID)
+//		}
+//	}
+func (s *OPCServer) LookupItemIDs(itemID string, propertyIDs []uint32) ([]PropertyItemID, error) {
 	if s == nil || s.provider == nil {
-		return nil, nil, errors.New("uninitialized server connection")
+		return nil, errors.New("uninitialized server connection")
+	}
+	if len(propertyIDs) == 0 {
+		return nil, nil
 	}
-	ItemIDs, errs, err := s.provider.LookupItemIDs(itemID, propertyIDs)
+	itemIDs, errs, err := s.provider.LookupItemIDs(itemID, propertyIDs)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
-	itemErrors := s.errors(errs)
-	return ItemIDs, itemErrors, nil
+	if len(errs) != len(propertyIDs) {
+		return nil, fmt.Errorf("server returned %d errors for %d properties", len(errs), len(propertyIDs))
+	}
+	results := make([]PropertyItemID, len(propertyIDs))
+	for i, id := range propertyIDs {
+		results[i] = PropertyItemID{PropertyID: id}
+		if errs[i] < 0 {
+			results[i].Err = s.getError(errs[i])
+			continue
+		}
+		// a server may return no item ID array, or a nil entry, for a property without errors
+		if i < len(itemIDs) {
+			results[i].ItemID = itemIDs[i]
+		}
+	}
+	return results, nil
+}
+
+// PropertyID identifies an OPC item property, such as 100 for the EU units.
+type PropertyID uint32
+
+// firstLookupPropertyID is the lowest property ID that may be passed to LookupItemIDs. IDs 1 to 6
+// describe the item itself (data type, value, quality, timestamp, access rights and scan rate) and
+// have no item IDs of their own.
+const firstLookupPropertyID = 7
+
+// GetItemPropertyItemIDs returns, for each available property of the item, the item ID that can be added
+// to a group to read or subscribe to that property directly. Properties without an item ID of their own
+// are left out of the map, as are properties 1 to 6 which describe the item itself.
+//
+// Example:
+//
+//	ids, err := server.GetItemPropertyItemIDs("Random.Int4")
+//	if err == nil {
+//		euUnitsItemID := ids[100]
+//	}
+func (s *OPCServer) GetItemPropertyItemIDs(itemID string) (map[PropertyID]string, error) {
+	if s == nil || s.provider == nil {
+		return nil, errors.New("uninitialized server connection")
+	}
+	available, _, _, err := s.provider.QueryAvailableProperties(itemID)
+	if err != nil {
+		return nil, err
+	}
+	propertyIDs := make([]uint32, 0, len(available))
+	for _, id := range available {
+		if id >= firstLookupPropertyID {
+			propertyIDs = append(propertyIDs, id)
+		}
+	}
+	result := make(map[PropertyID]string, len(propertyIDs))
+	if len(propertyIDs) == 0 {
+		return result, nil
+	}
+	itemIDs, errs, err := s.provider.LookupItemIDs(itemID, propertyIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i, id := range propertyIDs {
+		if i >= len(itemIDs) || i >= len(errs) || errs[i] < 0 || itemIDs[i] == "" {
+			continue
+		}
+		result[PropertyID(id)] = itemIDs[i]
+	}
+	return result, nil
 }
 
 // errors converts raw error codes to OPCError structs.
@@ -620,76 +999,180 @@ func (s *OPCServer) errors(errs []int32) []error {
 	errors := make([]error, len(errs))
 	for i, e := range errs {
 		if e < 0 {
-			errStr, _ := s.GetErrorString(e)
-			errors[i] = &OPCError{
-				ErrorCode:    e,
-				ErrorMessage: errStr,
-			}
+			errors[i] = newOPCError(s, s.provider, e)
 		}
 	}
 	return errors
 }
 
 // RegisterServerShutDown registers server shut down event.
+// The first registration advises the IOPCShutdown connection point of the server.
+// Delivery does not block; reasons are dropped if the channel is full and counted by DroppedShutdownEvents.
 func (s *OPCServer) RegisterServerShutDown(ch chan string) error {
+	if s == nil || s.provider == nil {
+		return errors.New("uninitialized server connection")
+	}
+	err := s.adviseShutdown()
+	if err != nil {
+		return err
+	}
+	s.event.AddReceiver(ch)
+	return nil
+}
+
+// UnregisterServerShutDown removes a channel registered with RegisterServerShutDown.
+// When the last channel is removed the connection point is unadvised and released,
+// so a later RegisterServerShutDown advises the server again.
+func (s *OPCServer) UnregisterServerShutDown(ch chan string) error {
 	if s == nil || s.provider == nil {
 		return errors.New("uninitialized server connection")
 	}
 	if s.event == nil {
-		var err error
-		var iUnknownContainer *com.IUnknown
-		var point *com.IConnectionPoint
-		var cookie uint32
+		return errors.New("channel not registered for server shutdown")
+	}
+	found, remaining := s.event.RemoveReceiver(ch)
+	if !found {
+		return errors.New("channel not registered for server shutdown")
+	}
+	return s.unadviseShutdownIfUnused(remaining)
+}
 
-		err = s.provider.QueryInterface(&com.IID_IConnectionPointContainer, unsafe.Pointer(&iUnknownContainer))
-		if err != nil {
-			return NewOPCWrapperError("query interface IConnectionPointContainer", err)
-		}
-		defer func() {
-			if err != nil {
-				iUnknownContainer.Release()
-			}
-		}()
-		container := &com.IConnectionPointContainer{IUnknown: iUnknownContainer}
-		point, err = container.FindConnectionPoint(&IID_IOPCShutdown)
-		if err != nil {
-			return NewOPCWrapperError("container find connect point", err)
-		}
-		defer func() {
-			if err != nil {
-				point.Release()
-			}
-		}()
-		event := NewShutdownEventReceiver()
-		cookie, err = point.Advise((*com.IUnknown)(unsafe.Pointer(event)))
-		if err != nil {
-			return NewOPCWrapperError("point advise", err)
-		}
-		s.container = container
-		s.point = point
-		s.event = event
-		s.cookie = cookie
+// RegisterShutdown registers a channel that receives a ShutdownEvent when the server requests a shutdown.
+// The event carries the originating server, so channels of several servers can be fanned into one.
+// Like RegisterServerShutDown, delivery does not block; events are dropped if the channel is full
+// and counted by DroppedShutdownEvents.
+func (s *OPCServer) RegisterShutdown(ch chan ShutdownEvent) error {
+	if s == nil || s.provider == nil {
+		return errors.New("uninitialized server connection")
 	}
-	s.event.AddReceiver(ch)
+	err := s.adviseShutdown()
+	if err != nil {
+		return err
+	}
+	s.event.AddEventReceiver(ch)
 	return nil
 }
 
+// UnregisterShutdown removes a channel registered with RegisterShutdown.
+// When no shutdown channel of either kind remains the connection point is unadvised and released.
+func (s *OPCServer) UnregisterShutdown(ch chan ShutdownEvent) error {
+	if s == nil || s.provider == nil {
+		return errors.New("uninitialized server connection")
+	}
+	if s.event == nil {
+		return errors.New("channel not registered for server shutdown")
+	}
+	found, remaining := s.event.RemoveEventReceiver(ch)
+	if !found {
+		return errors.New("channel not registered for server shutdown")
+	}
+	return s.unadviseShutdownIfUnused(remaining)
+}
+
+// adviseShutdown advises the IOPCShutdown connection point unless it is already advised.
+func (s *OPCServer) adviseShutdown() error {
+	if s.event != nil {
+		return nil
+	}
+	event := NewShutdownEventReceiver()
+	event.server = s
+	event.dropped = &s.shutdownDropped
+	cookie, err := s.provider.AdviseShutdown((*com.IUnknown)(unsafe.Pointer(event)))
+	if err != nil {
+		return err
+	}
+	event.start(s.rootContext())
+	s.event = event
+	s.cookie = cookie
+	return nil
+}
+
+// unadviseShutdownIfUnused unadvises the IOPCShutdown connection point when no receiver remains.
+func (s *OPCServer) unadviseShutdownIfUnused(remaining int) error {
+	if remaining > 0 {
+		return nil
+	}
+	err := s.provider.UnadviseShutdown(s.cookie)
+	s.event.stop()
+	s.event = nil
+	s.cookie = 0
+	if err != nil {
+		return NewOPCWrapperError("point unadvise", err)
+	}
+	return nil
+}
+
+// DroppedShutdownEvents returns how many shutdown notifications were lost because the internal
+// buffer or a subscriber channel was full, or because delivery stopped with Disconnect or the removal of
+// the last subscriber before they were delivered. A notification missed by several subscribers counts
+// once per subscriber.
+func (s *OPCServer) DroppedShutdownEvents() uint64 {
+	if s == nil {
+		return 0
+	}
+	return atomic.LoadUint64(&s.shutdownDropped)
+}
+
+// DisconnectOption configures Disconnect.
+type DisconnectOption func(*disconnectOptions)
+
+// disconnectOptions holds the settings applied by DisconnectOption values.
+type disconnectOptions struct {
+	removeGroups bool
+	force        bool
+}
+
+// WithRemoveGroups makes Disconnect remove every group from the server with RemoveGroup before
+// releasing it, instead of leaving the server to reclaim them when DCOM notices the client is gone.
+// force is passed to RemoveGroup and removes groups that are still referenced.
+func WithRemoveGroups(force bool) DisconnectOption {
+	return func(o *disconnectOptions) {
+		o.removeGroups = true
+		o.force = force
+	}
+}
+
+// rootContext returns the root context of the connection, or a background context for servers
+// that were not created by Connect.
+func (s *OPCServer) rootContext() context.Context {
+	if s == nil || s.ctx == nil {
+		return context.Background()
+	}
+	return s.ctx
+}
+
 // Disconnect disconnects from the OPC server.
-func (s *OPCServer) Disconnect() error {
+// It first cancels the root context of the connection, which stops the callback loops of every group and
+// the shutdown receiver, then releases the COM interfaces.
+// By default it only releases the local COM references; see WithRemoveGroups to also remove the groups
+// from the server. Group removal errors are joined with the unadvise error in the returned error.
+func (s *OPCServer) Disconnect(options ...DisconnectOption) error {
 	if s == nil {
 		return nil
 	}
-	var err error
-	if s.point != nil {
-		err = s.point.Unadvise(s.cookie)
-		s.point.Release()
+	var opts disconnectOptions
+	for _, option := range options {
+		option(&opts)
+	}
+	if s.cancel != nil {
+		s.cancel()
 	}
-	if s.container != nil {
-		s.container.Release()
+	var err error
+	if s.event != nil && s.provider != nil {
+		err = s.provider.UnadviseShutdown(s.cookie)
+		s.event.stop()
+		s.event = nil
+		s.cookie = 0
 	}
 	if s.groups != nil {
-		s.groups.Release()
+		if opts.removeGroups {
+			err = errors.Join(err, s.groups.removeAllFromServer(opts.force))
+		} else {
+			s.groups.Release()
+		}
 	}
+	err = errors.Join(err, s.releaseScratch())
+	s.releaseItemIO()
 	if s.provider != nil {
 		s.provider.Release()
 	}
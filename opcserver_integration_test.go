@@ -49,7 +49,7 @@ func TestServers(t *testing.T) {
 }
 
 func TestServersFromOpcV1(t *testing.T) {
-	serverInfos, err := getServersFromOpcServerListV1(TestHost)
+	serverInfos, err := getServersFromOpcServerListV1(TestHost, []windows.GUID{IID_CATID_OPCDAServer10, IID_CATID_OPCDAServer20}, nil)
 	assert.NoError(t, err)
 	assert.Greater(t, len(serverInfos), 0)
 	for i := 0; i < len(serverInfos); i++ {
@@ -61,7 +61,7 @@ func TestServersFromOpcV1(t *testing.T) {
 }
 
 func TestServersFromOpcV2(t *testing.T) {
-	serverInfos, err := getServersFromOpcServerListV2(TestHost)
+	serverInfos, err := getServersFromOpcServerListV2(TestHost, []windows.GUID{IID_CATID_OPCDAServer10, IID_CATID_OPCDAServer20}, nil)
 	assert.NoError(t, err)
 	assert.Greater(t, len(serverInfos), 0)
 	for i := 0; i < len(serverInfos); i++ {
@@ -73,10 +73,10 @@ func TestServersFromOpcV2(t *testing.T) {
 }
 
 func TestServersFromOPCMixed(t *testing.T) {
-	serverInfosV1, err := getServersFromOpcServerListV1(TestHost)
+	serverInfosV1, err := getServersFromOpcServerListV1(TestHost, []windows.GUID{IID_CATID_OPCDAServer10, IID_CATID_OPCDAServer20}, nil)
 	assert.NoError(t, err)
 	assert.Greater(t, len(serverInfosV1), 0)
-	serverInfosV2, err := getServersFromOpcServerListV2(TestHost)
+	serverInfosV2, err := getServersFromOpcServerListV2(TestHost, []windows.GUID{IID_CATID_OPCDAServer10, IID_CATID_OPCDAServer20}, nil)
 	assert.NoError(t, err)
 	assert.Greater(t, len(serverInfosV2), 0)
 	assert.Equal(t, len(serverInfosV1), len(serverInfosV2))
@@ -339,13 +339,11 @@ func TestOpcServer_LookupItemIDs(t *testing.T) {
 	assert.Equal(t, len(ppPropertyIDs), 14)
 	assert.Equal(t, len(ppDescriptions), 14)
 	assert.Equal(t, len(ppvtDataTypes), 14)
-	itemIDs, errors, err := server.LookupItemIDs(TestBoolItem, ppPropertyIDs)
+	itemIDs, err := server.LookupItemIDs(TestBoolItem, ppPropertyIDs)
 	assert.NoError(t, err)
 	assert.Equal(t, len(itemIDs), 14)
-	assert.Equal(t, len(errors), 14)
-	assert.Equal(t, len(itemIDs), len(errors))
 	for i := 0; i < 9; i++ {
-		assert.Error(t, errors[i])
+		assert.Error(t, itemIDs[i].Err)
 	}
 	expected := []string{
 		"Triangle Waves.Boolean",
@@ -355,7 +353,9 @@ func TestOpcServer_LookupItemIDs(t *testing.T) {
 		"Bucket Brigade.Boolean",
 	}
 	for i := 9; i < 14; i++ {
-		assert.Equal(t, expected[i-9], itemIDs[i])
+		assert.NoError(t, itemIDs[i].Err)
+		assert.Equal(t, ppPropertyIDs[i], itemIDs[i].PropertyID)
+		assert.Equal(t, expected[i-9], itemIDs[i].ItemID)
 	}
 }
 
@@ -538,7 +538,7 @@ func Test_getClsIDFromServerList(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := getClsIDFromServerListV2(tt.args.progID, tt.args.node, tt.args.location)
+			got, err := getClsIDFromServerListV2(tt.args.progID, tt.args.node, tt.args.location, nil)
 			if !tt.wantErr(t, err, fmt.Sprintf("getClsIDFromServerListV2(%v, %v, %v)", tt.args.progID, tt.args.node, tt.args.location)) {
 				return
 			}
@@ -3,10 +3,17 @@
 package opcda
 
 import (
+	"context"
+	"errors"
+	"math"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/wends155/opcda/com"
+	"golang.org/x/sys/windows"
 )
 
 func TestOPCServer_GetServerState_Mocked(t *testing.T) {
@@ -34,3 +41,656 @@ func TestOPCServer_GetLocaleID_Mocked(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, uint32(1033), id)
 }
+
+func TestOPCServer_UnregisterServerShutDown_Mocked(t *testing.T) {
+	var advised, unadvised []uint32
+	nextCookie := uint32(0)
+	mock := &mockServerProvider{
+		AdviseShutdownFn: func(sink *com.IUnknown) (uint32, error) {
+			assert.NotNil(t, sink)
+			nextCookie++
+			advised = append(advised, nextCookie)
+			return nextCookie, nil
+		},
+		UnadviseShutdownFn: func(cookie uint32) error {
+			unadvised = append(unadvised, cookie)
+			return nil
+		},
+	}
+	server := newOPCServerWithProvider(mock, "mock", "localhost")
+	ch1 := make(chan string, 1)
+	ch2 := make(chan string, 1)
+
+	assert.NoError(t, server.RegisterServerShutDown(ch1))
+	assert.NoError(t, server.RegisterServerShutDown(ch2))
+	assert.Equal(t, []uint32{1}, advised)
+
+	assert.NoError(t, server.UnregisterServerShutDown(ch1))
+	assert.Empty(t, unadvised)
+	assert.Error(t, server.UnregisterServerShutDown(ch1))
+
+	assert.NoError(t, server.UnregisterServerShutDown(ch2))
+	assert.Equal(t, []uint32{1}, unadvised)
+	assert.Nil(t, server.event)
+
+	assert.NoError(t, server.RegisterServerShutDown(ch1))
+	assert.Equal(t, []uint32{1, 2}, advised)
+	assert.NoError(t, server.Disconnect())
+	assert.Equal(t, []uint32{1, 2}, unadvised)
+}
+
+func TestOPCServer_SupportedUpdateRateRange_Mocked(t *testing.T) {
+	var requested []uint32
+	var removed []uint32
+	mock := &mockServerProvider{
+		AddGroupFn: func(name string, active bool, updateRate uint32, clientGroup uint32, timeBias *int32, deadband *float32, localeID uint32, iid *windows.GUID) (uint32, uint32, *com.IUnknown, error) {
+			assert.False(t, active)
+			requested = append(requested, updateRate)
+			revised := updateRate
+			if revised < 50 {
+				revised = 50
+			}
+			if revised > 3600000 {
+				revised = 3600000
+			}
+			return uint32(len(requested)), revised, nil, nil
+		},
+		RemoveGroupFn: func(serverGroup uint32, force bool) error {
+			removed = append(removed, serverGroup)
+			return nil
+		},
+	}
+	server := newOPCServerWithProvider(mock, "mock", "localhost")
+	fastest, slowest, err := server.SupportedUpdateRateRange()
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(50), fastest)
+	assert.Equal(t, uint32(3600000), slowest)
+	assert.Equal(t, []uint32{1, math.MaxUint32}, requested)
+	assert.Equal(t, []uint32{1, 2}, removed)
+}
+
+func TestOPCServer_RegisterShutdown_Mocked(t *testing.T) {
+	advised := 0
+	unadvised := 0
+	mock := &mockServerProvider{
+		AdviseShutdownFn: func(sink *com.IUnknown) (uint32, error) {
+			advised++
+			return 1, nil
+		},
+		UnadviseShutdownFn: func(cookie uint32) error {
+			unadvised++
+			return nil
+		},
+	}
+	server := newOPCServerWithProvider(mock, "mock", "localhost")
+	legacy := make(chan string, 1)
+	events := make(chan ShutdownEvent, 1)
+	assert.NoError(t, server.RegisterServerShutDown(legacy))
+	assert.NoError(t, server.RegisterShutdown(events))
+	assert.Equal(t, 1, advised)
+
+	at := time.Now()
+	server.event.deliver(ShutdownEvent{Reason: "maintenance", At: at, Server: server.event.server})
+	assert.Equal(t, "maintenance", <-legacy)
+	event := <-events
+	assert.Equal(t, "maintenance", event.Reason)
+	assert.Equal(t, at, event.At)
+	assert.Same(t, server, event.Server)
+
+	assert.NoError(t, server.UnregisterServerShutDown(legacy))
+	assert.Equal(t, 0, unadvised)
+	assert.NoError(t, server.UnregisterShutdown(events))
+	assert.Equal(t, 1, unadvised)
+}
+
+func TestOPCServer_Disconnect_WithRemoveGroups_Mocked(t *testing.T) {
+	var removed []uint32
+	mock := &mockServerProvider{
+		RemoveGroupFn: func(serverGroup uint32, force bool) error {
+			assert.True(t, force)
+			removed = append(removed, serverGroup)
+			if serverGroup == 2 {
+				return errors.New("in use")
+			}
+			return nil
+		},
+	}
+	server := newOPCServerWithProvider(mock, "mock", "localhost")
+	cancelled := false
+	server.groups.groups = []*OPCGroup{
+		{parent: server.groups, serverGroupHandle: 1, groupName: "g1", cancel: func() { cancelled = true }},
+		{parent: server.groups, serverGroupHandle: 2, groupName: "g2"},
+	}
+	err := server.Disconnect(WithRemoveGroups(true))
+	assert.ErrorContains(t, err, `remove group "g2"`)
+	assert.Equal(t, []uint32{1, 2}, removed)
+	assert.True(t, cancelled)
+	assert.Empty(t, server.groups.groups)
+}
+
+func TestOPCServer_Disconnect_KeepsGroupsByDefault_Mocked(t *testing.T) {
+	mock := &mockServerProvider{
+		RemoveGroupFn: func(serverGroup uint32, force bool) error {
+			t.Fatal("RemoveGroup must not be called by a plain Disconnect")
+			return nil
+		},
+	}
+	server := newOPCServerWithProvider(mock, "mock", "localhost")
+	server.groups.groups = []*OPCGroup{{parent: server.groups, serverGroupHandle: 1}}
+	assert.NoError(t, server.Disconnect())
+}
+
+func TestOPCServer_GetItemProperties_UnsupportedVariant_Mocked(t *testing.T) {
+	mock := &mockServerProvider{
+		GetItemPropertiesFn: func(itemID string, propertyIDs []uint32) ([]interface{}, []int32, error) {
+			return []interface{}{
+				int32(1),
+				&com.ErrUnsupportedVariant{VT: com.VT_CY},
+				nil,
+			}, []int32{0, 0, int32(OPCInvalidPID)}, nil
+		},
+	}
+	server := newOPCServerWithProvider(mock, "mock", "localhost")
+	data, errs, err := server.GetItemProperties("Random.Int4", []uint32{1, 2, 9999})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), data[0])
+	assert.NoError(t, errs[0])
+
+	assert.Equal(t, com.VT_CY, data[1])
+	var unsupported *com.ErrUnsupportedVariant
+	if assert.ErrorAs(t, errs[1], &unsupported) {
+		assert.Equal(t, com.VT_CY, unsupported.VT)
+	}
+
+	assert.Nil(t, data[2])
+	assert.Error(t, errs[2])
+}
+
+func TestOPCServer_GetItemProperties_Timestamp_Mocked(t *testing.T) {
+	want := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	mock := &mockServerProvider{
+		GetItemPropertiesFn: func(itemID string, propertyIDs []uint32) ([]interface{}, []int32, error) {
+			date, err := com.TimeToVariantDate(want.In(time.FixedZone("UTC+2", 2*3600)))
+			if err != nil {
+				return nil, nil, err
+			}
+			value, err := (&com.VARIANT{VT: com.VT_DATE, Val: int64(date)}).Value()
+			if err != nil {
+				return nil, nil, err
+			}
+			return []interface{}{value}, []int32{0}, nil
+		},
+	}
+	server := newOPCServerWithProvider(mock, "mock", "localhost")
+	data, errs, err := server.GetItemProperties("Random.Int4", []uint32{uint32(OPC_PROPERTY_TIMESTAMP)})
+	assert.NoError(t, err)
+	assert.NoError(t, errs[0])
+	timestamp, ok := data[0].(time.Time)
+	if assert.True(t, ok, "a VT_DATE property must be returned as time.Time") {
+		assert.True(t, want.Equal(timestamp))
+		assert.Equal(t, time.UTC, timestamp.Location())
+	}
+}
+
+func TestOPCServer_GetBandwidthInfo_Mocked(t *testing.T) {
+	bandwidth := uint32(0)
+	mock := &mockServerProvider{
+		GetStatusFn: func() (*com.ServerStatus, error) {
+			return &com.ServerStatus{BandWidth: bandwidth}, nil
+		},
+	}
+	server := newOPCServerWithProvider(mock, "mock", "localhost")
+
+	value, supported, err := server.GetBandwidthInfo()
+	assert.NoError(t, err)
+	assert.True(t, supported)
+	assert.Equal(t, uint32(0), value)
+
+	bandwidth = 42
+	value, supported, err = server.GetBandwidthInfo()
+	assert.NoError(t, err)
+	assert.True(t, supported)
+	assert.Equal(t, uint32(42), value)
+
+	bandwidth = OPC_BANDWIDTH_NOT_SUPPORTED
+	value, supported, err = server.GetBandwidthInfo()
+	assert.NoError(t, err)
+	assert.False(t, supported)
+	assert.Equal(t, uint32(0), value)
+
+	raw, err := server.GetBandwidth()
+	assert.NoError(t, err)
+	assert.Equal(t, OPC_BANDWIDTH_NOT_SUPPORTED, raw)
+}
+
+func TestOPCServer_GetGroupCount_Mocked(t *testing.T) {
+	mock := &mockServerProvider{
+		GetStatusFn: func() (*com.ServerStatus, error) {
+			return &com.ServerStatus{GroupCount: 3}, nil
+		},
+	}
+	server := newOPCServerWithProvider(mock, "mock", "localhost")
+	count, err := server.GetGroupCount()
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(3), count)
+
+	mock.GetStatusFn = func() (*com.ServerStatus, error) {
+		return nil, errors.New("status failed")
+	}
+	_, err = server.GetGroupCount()
+	assert.Error(t, err)
+	_, _, err = server.GetBandwidthInfo()
+	assert.Error(t, err)
+
+	var nilServer *OPCServer
+	_, err = nilServer.GetGroupCount()
+	assert.Error(t, err)
+}
+
+func TestOPCServer_LookupItemIDs_Mocked(t *testing.T) {
+	mock := &mockServerProvider{
+		LookupItemIDsFn: func(itemID string, propertyIDs []uint32) ([]string, []int32, error) {
+			assert.Equal(t, "Tank.Level", itemID)
+			// S_FALSE: property 1 has no item ID and the entry of 101 came back as a nil pointer
+			return []string{"", "Tank.Level.EU", ""},
+				[]int32{int32(OPCInvalidPID), 0, 0}, nil
+		},
+		GetErrorStringFn: func(errorCode uint32) (string, error) {
+			return "invalid property", nil
+		},
+	}
+	server := newOPCServerWithProvider(mock, "mock", "localhost")
+
+	ids, err := server.LookupItemIDs("Tank.Level", []uint32{1, 100, 101})
+	assert.NoError(t, err)
+	assert.Len(t, ids, 3)
+	assert.Equal(t, uint32(1), ids[0].PropertyID)
+	assert.Empty(t, ids[0].ItemID)
+	assert.ErrorIs(t, ids[0].Err, com.HRESULT(OPCInvalidPID))
+	assert.Equal(t, PropertyItemID{PropertyID: 100, ItemID: "Tank.Level.EU"}, ids[1])
+	assert.Equal(t, PropertyItemID{PropertyID: 101}, ids[2])
+
+	mock.LookupItemIDsFn = func(itemID string, propertyIDs []uint32) ([]string, []int32, error) {
+		return nil, []int32{0}, nil
+	}
+	ids, err = server.LookupItemIDs("Tank.Level", []uint32{100})
+	assert.NoError(t, err)
+	assert.Equal(t, []PropertyItemID{{PropertyID: 100}}, ids)
+
+	mock.LookupItemIDsFn = func(itemID string, propertyIDs []uint32) ([]string, []int32, error) {
+		return nil, nil, nil
+	}
+	_, err = server.LookupItemIDs("Tank.Level", []uint32{100})
+	assert.Error(t, err)
+
+	var nilServer *OPCServer
+	_, err = nilServer.LookupItemIDs("Tank.Level", []uint32{100})
+	assert.Error(t, err)
+}
+
+func TestOPCServer_GetItemPropertyItemIDs_Mocked(t *testing.T) {
+	var looked []uint32
+	mock := &mockServerProvider{
+		QueryAvailablePropertiesFn: func(itemID string) ([]uint32, []string, []uint16, error) {
+			return []uint32{1, 2, 3, 4, 5, 6, 100, 101, 102},
+				[]string{"Type", "Value", "Quality", "Timestamp", "Rights", "Scan", "EU Units", "Description", "High EU"},
+				make([]uint16, 9), nil
+		},
+		LookupItemIDsFn: func(itemID string, propertyIDs []uint32) ([]string, []int32, error) {
+			looked = propertyIDs
+			return []string{itemID + ".EU", "", itemID + ".High"},
+				[]int32{0, int32(OPCInvalidPID), 0}, nil
+		},
+	}
+	server := newOPCServerWithProvider(mock, "mock", "localhost")
+	ids, err := server.GetItemPropertyItemIDs("Tank.Level")
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{100, 101, 102}, looked)
+	assert.Equal(t, map[PropertyID]string{
+		100: "Tank.Level.EU",
+		102: "Tank.Level.High",
+	}, ids)
+}
+
+func TestOPCServer_GetItemPropertyItemIDs_Errors_Mocked(t *testing.T) {
+	lookups := 0
+	mock := &mockServerProvider{
+		QueryAvailablePropertiesFn: func(itemID string) ([]uint32, []string, []uint16, error) {
+			return []uint32{1, 2}, []string{"Type", "Value"}, make([]uint16, 2), nil
+		},
+		LookupItemIDsFn: func(itemID string, propertyIDs []uint32) ([]string, []int32, error) {
+			lookups++
+			return nil, nil, errors.New("lookup failed")
+		},
+	}
+	server := newOPCServerWithProvider(mock, "mock", "localhost")
+	ids, err := server.GetItemPropertyItemIDs("Tank.Level")
+	assert.NoError(t, err)
+	assert.Empty(t, ids)
+	assert.Equal(t, 0, lookups)
+
+	mock.QueryAvailablePropertiesFn = func(itemID string) ([]uint32, []string, []uint16, error) {
+		return []uint32{100}, []string{"EU Units"}, make([]uint16, 1), nil
+	}
+	_, err = server.GetItemPropertyItemIDs("Tank.Level")
+	assert.EqualError(t, err, "lookup failed")
+
+	mock.QueryAvailablePropertiesFn = func(itemID string) ([]uint32, []string, []uint16, error) {
+		return nil, nil, nil, errors.New("unknown item")
+	}
+	_, err = server.GetItemPropertyItemIDs("Tank.Level")
+	assert.EqualError(t, err, "unknown item")
+}
+
+func TestServerListOptions_Categories(t *testing.T) {
+	var opts serverListOptions
+	cids, err := opts.serverCategories()
+	assert.NoError(t, err)
+	assert.Equal(t, []windows.GUID{IID_CATID_OPCDAServer10, IID_CATID_OPCDAServer20, IID_CATID_OPCDAServer30}, cids)
+
+	WithDAVersions(DAVersion30, DAVersion20, DAVersion30)(&opts)
+	cids, err = opts.serverCategories()
+	assert.NoError(t, err)
+	assert.Equal(t, []windows.GUID{IID_CATID_OPCDAServer30, IID_CATID_OPCDAServer20}, cids)
+
+	WithDAVersions()(&opts)
+	_, err = opts.serverCategories()
+	assert.Error(t, err)
+
+	WithDAVersions(DAVersion(4))(&opts)
+	_, err = opts.serverCategories()
+	assert.ErrorContains(t, err, "unknown OPC DA version 4")
+
+	_, err = GetOPCServers("localhost", WithDAVersions())
+	assert.Error(t, err)
+}
+
+func TestDedupeServers(t *testing.T) {
+	servers := []*ServerInfo{
+		{ProgID: "A.1", ClsStr: "{CC603642-66D7-48F1-B69A-B625E73652D7}"},
+		{ProgID: "B.1", ClsStr: "{63D5F432-CFE4-11D1-B2C8-0060083BA1FB}"},
+		{ProgID: "A.1", ClsStr: "{cc603642-66d7-48f1-b69a-b625e73652d7}"},
+	}
+	result := dedupeServers(servers)
+	assert.Len(t, result, 2)
+	assert.Equal(t, "A.1", result[0].ProgID)
+	assert.Equal(t, "B.1", result[1].ProgID)
+	assert.Empty(t, dedupeServers(nil))
+}
+
+// runningGoroutines counts the goroutines whose stack contains fn, in the spirit of goleak.
+func runningGoroutines(fn string) int {
+	buf := make([]byte, 1<<20)
+	n := runtime.Stack(buf, true)
+	return strings.Count(string(buf[:n]), fn+"(")
+}
+
+func newLoopTestGroups(server *OPCServer, n int) {
+	for i := 0; i < n; i++ {
+		g := &OPCGroup{
+			parent:            server.groups,
+			provider:          server.provider,
+			groupProvider:     &mockGroupProvider{},
+			serverGroupHandle: uint32(i + 1),
+		}
+		g.startLoop(make(chan *CDataChangeCallBackData), make(chan *CReadCompleteCallBackData),
+			make(chan *CWriteCompleteCallBackData), make(chan *CCancelCompleteCallBackData))
+		server.groups.groups = append(server.groups.groups, g)
+	}
+}
+
+func TestOPCServer_Disconnect_StopsGroupLoops_Mocked(t *testing.T) {
+	const groupLoop = "opcda.(*OPCGroup).loop"
+	const shutdownLoop = "opcda.(*ShutdownEventReceiver).loop"
+	baseGroups := runningGoroutines(groupLoop)
+	baseShutdown := runningGoroutines(shutdownLoop)
+
+	server := newOPCServerWithProvider(&mockServerProvider{}, "mock", "localhost")
+	newLoopTestGroups(server, 3)
+	assert.NoError(t, server.RegisterShutdown(make(chan ShutdownEvent, 1)))
+	assert.Eventually(t, func() bool { return runningGoroutines(groupLoop) == baseGroups+3 }, time.Second, time.Millisecond)
+	assert.Equal(t, baseShutdown+1, runningGoroutines(shutdownLoop))
+
+	assert.NoError(t, server.Disconnect())
+	assert.Eventually(t, func() bool {
+		return runningGoroutines(groupLoop) == baseGroups && runningGoroutines(shutdownLoop) == baseShutdown
+	}, time.Second, time.Millisecond)
+}
+
+func TestOPCServer_RootContext_StopsGroupLoopsBeforeRelease_Mocked(t *testing.T) {
+	const groupLoop = "opcda.(*OPCGroup).loop"
+	base := runningGoroutines(groupLoop)
+
+	server := newOPCServerWithProvider(&mockServerProvider{}, "mock", "localhost")
+	newLoopTestGroups(server, 2)
+	assert.Eventually(t, func() bool { return runningGoroutines(groupLoop) == base+2 }, time.Second, time.Millisecond)
+
+	// cancelling the root alone, without releasing any group, must end every loop
+	server.cancel()
+	assert.Eventually(t, func() bool { return runningGoroutines(groupLoop) == base }, time.Second, time.Millisecond)
+	for _, g := range server.groups.groups {
+		assert.Error(t, g.ctx.Err())
+	}
+}
+
+// swapServerEnumeration replaces the server list constructors and the registry scan for a test.
+func swapServerEnumeration(t *testing.T, v2, v1 func(string, *com.COAUTHINFO) (serverListProvider, error), reg func(string) ([]*ServerInfo, error)) {
+	oldV2, oldV1, oldReg := newServerList2Provider, newServerListProvider, serversFromRegistry
+	t.Cleanup(func() {
+		newServerList2Provider, newServerListProvider, serversFromRegistry = oldV2, oldV1, oldReg
+	})
+	newServerList2Provider, newServerListProvider, serversFromRegistry = v2, v1, reg
+}
+
+func newMockServerList(userType, verIndProgID string, released *int) *mockServerListProvider {
+	classID := IID_CATID_OPCDAServer30
+	return &mockServerListProvider{
+		EnumClassesOfCategoriesFn: func(cids []windows.GUID) ([]windows.GUID, error) {
+			return []windows.GUID{classID, classID}, nil
+		},
+		GetClassDetailsFn: func(id *windows.GUID) (string, string, string, error) {
+			return "Vendor.Server.1", userType, verIndProgID, nil
+		},
+		ReleaseFn: func() { *released++ },
+	}
+}
+
+func TestGetOPCServers_Description_Mocked(t *testing.T) {
+	unavailable := func(string, *com.COAUTHINFO) (serverListProvider, error) {
+		return nil, errors.New("class not registered")
+	}
+	registry := func(string) ([]*ServerInfo, error) {
+		return []*ServerInfo{{ProgID: "Vendor.Server.1", ClsStr: IID_CATID_OPCDAServer30.String()}}, nil
+	}
+
+	t.Run("V2", func(t *testing.T) {
+		released := 0
+		swapServerEnumeration(t, func(string, *com.COAUTHINFO) (serverListProvider, error) {
+			return newMockServerList("Vendor OPC Server", "Vendor.Server", &released), nil
+		}, unavailable, registry)
+		servers, err := GetOPCServers("localhost")
+		assert.NoError(t, err)
+		if assert.Len(t, servers, 1) {
+			assert.Equal(t, "Vendor OPC Server", servers[0].Description)
+			assert.Equal(t, "Vendor.Server", servers[0].VerIndProgID)
+		}
+		assert.Equal(t, 1, released)
+	})
+
+	t.Run("V1", func(t *testing.T) {
+		released := 0
+		swapServerEnumeration(t, unavailable, func(string, *com.COAUTHINFO) (serverListProvider, error) {
+			return newMockServerList("Vendor OPC Server", "", &released), nil
+		}, registry)
+		servers, err := GetOPCServers("localhost", WithDAVersions(DAVersion30))
+		assert.NoError(t, err)
+		if assert.Len(t, servers, 1) {
+			assert.Equal(t, "Vendor OPC Server", servers[0].Description)
+			assert.Empty(t, servers[0].VerIndProgID)
+		}
+		assert.Equal(t, 1, released)
+	})
+
+	t.Run("Registry", func(t *testing.T) {
+		swapServerEnumeration(t, unavailable, unavailable, registry)
+		servers, err := GetOPCServers("localhost")
+		assert.NoError(t, err)
+		if assert.Len(t, servers, 1) {
+			assert.Empty(t, servers[0].Description)
+		}
+
+		servers, err = GetOPCServers("localhost", WithoutRegistry())
+		assert.Error(t, err)
+		assert.Nil(t, servers)
+	})
+}
+
+func TestGetOPCServers_Categories_Mocked(t *testing.T) {
+	var requested []windows.GUID
+	released := 0
+	list := newMockServerList("", "", &released)
+	list.EnumClassesOfCategoriesFn = func(cids []windows.GUID) ([]windows.GUID, error) {
+		requested = cids
+		return nil, nil
+	}
+	swapServerEnumeration(t, func(string, *com.COAUTHINFO) (serverListProvider, error) { return list, nil }, nil, nil)
+
+	_, err := GetOPCServers("localhost", WithDAVersions(DAVersion20))
+	assert.NoError(t, err)
+	assert.Equal(t, []windows.GUID{IID_CATID_OPCDAServer20}, requested)
+
+	_, err = GetOPCServersContext(context.Background(), "localhost", WithDAVersions(DAVersion10, DAVersion30))
+	assert.NoError(t, err)
+	assert.Equal(t, []windows.GUID{IID_CATID_OPCDAServer10, IID_CATID_OPCDAServer30}, requested)
+}
+
+func TestGetOPCServersContext_Cancel_Mocked(t *testing.T) {
+	unblock := make(chan struct{})
+	released := make(chan struct{}, 1)
+	blocking := &mockServerListProvider{
+		EnumClassesOfCategoriesFn: func(cids []windows.GUID) ([]windows.GUID, error) {
+			<-unblock
+			return nil, nil
+		},
+		ReleaseFn: func() { released <- struct{}{} },
+	}
+	v1Calls := 0
+	swapServerEnumeration(t, func(string, *com.COAUTHINFO) (serverListProvider, error) {
+		return blocking, nil
+	}, func(string, *com.COAUTHINFO) (serverListProvider, error) {
+		v1Calls++
+		return nil, errors.New("unexpected")
+	}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	servers, err := GetOPCServersContext(ctx, "unreachable")
+	assert.Nil(t, servers)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorContains(t, err, "ServerList2")
+	assert.Equal(t, 0, v1Calls)
+
+	// the abandoned stage still releases its server list when it completes
+	close(unblock)
+	select {
+	case <-released:
+	case <-time.After(time.Second):
+		t.Fatal("abandoned enumeration did not release the server list")
+	}
+}
+
+func TestGetOPCServersContext_StageDeadline_Mocked(t *testing.T) {
+	unblock := make(chan struct{})
+	defer close(unblock)
+	swapServerEnumeration(t, func(string, *com.COAUTHINFO) (serverListProvider, error) {
+		return nil, errors.New("class not registered")
+	}, func(string, *com.COAUTHINFO) (serverListProvider, error) {
+		<-unblock
+		return nil, errors.New("late")
+	}, func(string) ([]*ServerInfo, error) {
+		t.Error("registry scanned after the deadline")
+		return nil, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := GetOPCServersContext(ctx, "unreachable")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "ServerList1")
+
+	_, err = GetOPCServersContext(ctx, "unreachable")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "ServerList2")
+}
+
+func TestGetOPCServersContext_Completes_Mocked(t *testing.T) {
+	var initThread, listThread uint32
+	uninitialized := 0
+	swapPinnedCOM(t, &initThread, &uninitialized)
+	released := 0
+	swapServerEnumeration(t, func(string, *com.COAUTHINFO) (serverListProvider, error) {
+		listThread = windows.GetCurrentThreadId()
+		return newMockServerList("Vendor OPC Server", "Vendor.Server", &released), nil
+	}, nil, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	servers, err := GetOPCServersContext(ctx, "localhost")
+	assert.NoError(t, err)
+	assert.Len(t, servers, 1)
+	assert.Equal(t, 1, released)
+	// the stage initialized COM on the thread it enumerated on
+	assert.NotZero(t, initThread)
+	assert.Equal(t, initThread, listThread)
+}
+
+func TestGetOPCServers_WithAuth_Mocked(t *testing.T) {
+	identity := com.NewCOAUTHIDENTITY("operator", "PLANT-PC", "secret")
+	var received []*com.COAUTHINFO
+	failing := func(node string, authInfo *com.COAUTHINFO) (serverListProvider, error) {
+		received = append(received, authInfo)
+		return nil, errors.New("access denied")
+	}
+	swapServerEnumeration(t, failing, failing, func(string) ([]*ServerInfo, error) {
+		t.Error("registry scanned with explicit credentials")
+		return nil, nil
+	})
+	_, err := GetOPCServers("plant-pc", WithAuth(identity))
+	assert.Error(t, err)
+	assert.Len(t, received, 2)
+	for _, authInfo := range received {
+		assert.NotNil(t, authInfo)
+		assert.Same(t, identity, authInfo.PAuthIdentityData)
+	}
+
+	released := 0
+	received = nil
+	swapServerEnumeration(t, func(node string, authInfo *com.COAUTHINFO) (serverListProvider, error) {
+		received = append(received, authInfo)
+		return newMockServerList("Vendor OPC Server", "Vendor.Server", &released), nil
+	}, nil, nil)
+	servers, err := GetOPCServers("localhost", WithAuth(identity))
+	assert.NoError(t, err)
+	assert.Len(t, servers, 1)
+	assert.Equal(t, []*com.COAUTHINFO{nil}, received)
+
+	_, err = GetOPCServers("plant-pc", WithAuth(nil))
+	assert.Error(t, err)
+}
+
+func TestConnectWithAuthInfo_Nil(t *testing.T) {
+	_, err := ConnectWithAuthInfo("Vendor.Server.1", "plant-pc", nil)
+	assert.Error(t, err)
+}
+
+func TestOPCGroups_AuthInfo(t *testing.T) {
+	authInfo := com.NewCOAUTHINFO("operator", "PLANT", "secret")
+	server := newOPCServerWithProvider(&mockServerProvider{}, "mock", "plant-pc")
+	assert.Nil(t, server.groups.authInfo())
+	server.authInfo = authInfo
+	assert.Same(t, authInfo, server.groups.authInfo())
+	var groups *OPCGroups
+	assert.Nil(t, groups.authInfo())
+}
@@ -0,0 +1,476 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"unsafe"
+
+	"github.com/wends155/opcda/com"
+	"golang.org/x/sys/windows"
+)
+
+// ErrRuntimeClosed is returned for calls made through a PinnedRuntime after it was closed.
+var ErrRuntimeClosed = errors.New("pinned runtime closed")
+
+// initializeCOM and uninitializeCOM set up and tear down COM on the thread of a PinnedRuntime;
+// tests replace them.
+var (
+	initializeCOM   = com.InitializeWithResult
+	uninitializeCOM = com.Uninitialize
+)
+
+// PinnedRuntime runs COM calls on a single, dedicated OS thread, for servers that misbehave unless every
+// call comes from the same thread. Servers connected with PinnedRuntime.Connect route every call of their
+// server, group and item management interfaces through the thread, including the optional interfaces,
+// browsing, public groups and the connection points of the callbacks; their public API is unchanged.
+//
+// Without a PinnedRuntime, calls are made on whatever thread the calling goroutine runs on, and the
+// callback loops of groups make their calls from their own goroutines. See com.Apartment for the
+// threading model a PinnedRuntime enforces.
+type PinnedRuntime struct {
+	apartment *com.Apartment
+}
+
+// NewPinnedRuntime starts the dedicated thread and initializes COM on it in the multithreaded apartment,
+// accepting process security already set by the host. Call Close once every server connected through
+// the runtime has been disconnected.
+//
+// Example:
+//
+//	rt, err := opcda.NewPinnedRuntime()
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer rt.Close()
+//	server, err := rt.Connect("Matrikon.OPC.Simulation.1", "localhost")
+func NewPinnedRuntime() (*PinnedRuntime, error) {
+	apartment, err := com.NewApartmentWithInit(initCOMThread)
+	if err != nil {
+		return nil, err
+	}
+	return NewPinnedRuntimeOn(apartment), nil
+}
+
+// NewPinnedRuntimeOn creates a PinnedRuntime that makes its calls through an existing apartment, so the
+// servers connected through it share the thread with other COM calls of the application. The runtime
+// takes over the apartment: Close closes it.
+func NewPinnedRuntimeOn(apartment *com.Apartment) *PinnedRuntime {
+	return &PinnedRuntime{apartment: apartment}
+}
+
+// initCOMThread initializes COM in the multithreaded apartment on the locked thread of a PinnedRuntime or
+// an enumeration stage, and returns the function that uninitializes it again.
+func initCOMThread() (func(), error) {
+	config := com.DefaultInitConfig()
+	config.TolerateExisting = true
+	result, err := initializeCOM(config)
+	if err != nil {
+		return nil, err
+	}
+	if !result.NeedsUninitialize {
+		return func() {}, nil
+	}
+	return uninitializeCOM, nil
+}
+
+// Apartment returns the apartment whose thread the runtime makes its calls on.
+func (r *PinnedRuntime) Apartment() *com.Apartment {
+	if r == nil {
+		return nil
+	}
+	return r.apartment
+}
+
+// do runs fn on the thread of the runtime and waits for it to return.
+func (r *PinnedRuntime) do(fn func()) error {
+	err := r.apartment.Do(fn)
+	if errors.Is(err, com.ErrApartmentClosed) {
+		return ErrRuntimeClosed
+	}
+	return err
+}
+
+// call runs fn on the thread of the runtime and returns its error. Without a runtime fn runs directly.
+func (r *PinnedRuntime) call(fn func() error) error {
+	if r == nil {
+		return fn()
+	}
+	var err error
+	if doErr := r.do(func() { err = fn() }); doErr != nil {
+		return doErr
+	}
+	return err
+}
+
+// Close stops the thread of the runtime and uninitializes COM on it. Later calls of servers connected
+// through the runtime fail with ErrRuntimeClosed.
+func (r *PinnedRuntime) Close() error {
+	if r == nil {
+		return nil
+	}
+	return r.apartment.Close()
+}
+
+// Connect connects to an OPC server like Connect, making every call of the connection on the thread of
+// the runtime. Reconnect keeps using the runtime.
+func (r *PinnedRuntime) Connect(progID, node string) (*OPCServer, error) {
+	if r == nil {
+		return nil, errors.New("uninitialized pinned runtime")
+	}
+	return r.connect(comFactories(), progID, node, nil)
+}
+
+// connect connects with f on the thread of the runtime and routes the calls of the new server through it.
+func (r *PinnedRuntime) connect(f *connectionFactories, progID, node string, authInfo *com.COAUTHINFO) (*OPCServer, error) {
+	var s *OPCServer
+	var err error
+	if doErr := r.do(func() { s, err = f.connect(progID, node, authInfo) }); doErr != nil {
+		return nil, doErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.pinned = r
+	s.provider = &pinnedServerProvider{runtime: r, provider: s.provider}
+	if s.groups != nil {
+		s.groups.provider = s.provider
+	}
+	return s, nil
+}
+
+// bindGroup creates the OPCGroup of a group added to the server, routing its calls through the pinned
+// runtime of the server if it has one.
+func (gs *OPCGroups) bindGroup(iUnknown *com.IUnknown, clientGroupHandle, serverGroupHandle uint32, groupName string, revisedUpdateRate uint32) (*OPCGroup, error) {
+	var r *PinnedRuntime
+	if gs.parent != nil {
+		r = gs.parent.pinned
+	}
+	if r == nil {
+		return gs.factories.newGroup(gs, iUnknown, clientGroupHandle, serverGroupHandle, groupName, revisedUpdateRate)
+	}
+	var g *OPCGroup
+	var err error
+	if doErr := r.do(func() {
+		g, err = gs.factories.newGroup(gs, iUnknown, clientGroupHandle, serverGroupHandle, groupName, revisedUpdateRate)
+	}); doErr != nil {
+		return nil, doErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	g.groupProvider = &pinnedGroupProvider{runtime: r, provider: g.groupProvider}
+	if g.items != nil {
+		g.items.itemMgtProvider = &pinnedItemMgtProvider{runtime: r, provider: g.items.itemMgtProvider}
+	}
+	if g.samplingMgt != nil {
+		g.samplingMgt = &pinnedItemSamplingMgtProvider{runtime: r, provider: g.samplingMgt}
+	}
+	if g.deadbandMgt != nil {
+		g.deadbandMgt = &pinnedItemDeadbandMgtProvider{runtime: r, provider: g.deadbandMgt}
+	}
+	return g, nil
+}
+
+// runtime returns the pinned runtime of the server of the group, or nil if it has none.
+func (g *OPCGroup) runtime() *PinnedRuntime {
+	if g.parent == nil || g.parent.parent == nil {
+		return nil
+	}
+	return g.parent.parent.pinned
+}
+
+// pinnedServerProvider forwards the calls of a serverProvider to the thread of a PinnedRuntime.
+type pinnedServerProvider struct {
+	runtime  *PinnedRuntime
+	provider serverProvider
+}
+
+// GetStatus retrieves the current status of the OPC server.
+func (p *pinnedServerProvider) GetStatus() (status *com.ServerStatus, err error) {
+	if doErr := p.runtime.do(func() { status, err = p.provider.GetStatus() }); doErr != nil {
+		return nil, doErr
+	}
+	return
+}
+
+// GetErrorString converts an error code to a readable string.
+func (p *pinnedServerProvider) GetErrorString(errorCode uint32) (s string, err error) {
+	if doErr := p.runtime.do(func() { s, err = p.provider.GetErrorString(errorCode) }); doErr != nil {
+		return "", doErr
+	}
+	return
+}
+
+// GetLocaleID retrieves the current locale identifier for the server.
+func (p *pinnedServerProvider) GetLocaleID() (localeID uint32, err error) {
+	if doErr := p.runtime.do(func() { localeID, err = p.provider.GetLocaleID() }); doErr != nil {
+		return 0, doErr
+	}
+	return
+}
+
+// SetLocaleID sets the locale identifier for the server.
+func (p *pinnedServerProvider) SetLocaleID(localeID uint32) (err error) {
+	if doErr := p.runtime.do(func() { err = p.provider.SetLocaleID(localeID) }); doErr != nil {
+		return doErr
+	}
+	return
+}
+
+// SetClientName sets the name of the client application.
+func (p *pinnedServerProvider) SetClientName(clientName string) (err error) {
+	if doErr := p.runtime.do(func() { err = p.provider.SetClientName(clientName) }); doErr != nil {
+		return doErr
+	}
+	return
+}
+
+// QueryAvailableLocaleIDs returns a list of available locale identifiers.
+func (p *pinnedServerProvider) QueryAvailableLocaleIDs() (localeIDs []uint32, err error) {
+	if doErr := p.runtime.do(func() { localeIDs, err = p.provider.QueryAvailableLocaleIDs() }); doErr != nil {
+		return nil, doErr
+	}
+	return
+}
+
+// QueryAvailableProperties returns a list of ID codes and descriptions for the available properties of an item.
+func (p *pinnedServerProvider) QueryAvailableProperties(itemID string) (ids []uint32, descriptions []string, types []uint16, err error) {
+	if doErr := p.runtime.do(func() { ids, descriptions, types, err = p.provider.QueryAvailableProperties(itemID) }); doErr != nil {
+		return nil, nil, nil, doErr
+	}
+	return
+}
+
+// GetItemProperties returns a list of the current data values for the passed ID codes.
+func (p *pinnedServerProvider) GetItemProperties(itemID string, propertyIDs []uint32) (data []interface{}, errs []int32, err error) {
+	if doErr := p.runtime.do(func() { data, errs, err = p.provider.GetItemProperties(itemID, propertyIDs) }); doErr != nil {
+		return nil, nil, doErr
+	}
+	return
+}
+
+// LookupItemIDs returns a list of item IDs for each of the passed ID codes.
+func (p *pinnedServerProvider) LookupItemIDs(itemID string, propertyIDs []uint32) (itemIDs []string, errs []int32, err error) {
+	if doErr := p.runtime.do(func() { itemIDs, errs, err = p.provider.LookupItemIDs(itemID, propertyIDs) }); doErr != nil {
+		return nil, nil, doErr
+	}
+	return
+}
+
+// AddGroup creates a new OPC group with the specified parameters.
+func (p *pinnedServerProvider) AddGroup(name string, active bool, updateRate uint32, clientGroup uint32, timeBias *int32, deadband *float32, localeID uint32, iid *windows.GUID) (serverGroup uint32, revisedUpdateRate uint32, ppUnk *com.IUnknown, err error) {
+	if doErr := p.runtime.do(func() {
+		serverGroup, revisedUpdateRate, ppUnk, err = p.provider.AddGroup(name, active, updateRate, clientGroup, timeBias, deadband, localeID, iid)
+	}); doErr != nil {
+		return 0, 0, nil, doErr
+	}
+	return
+}
+
+// GetGroupByName returns an interface of an existing group of this connection by name.
+func (p *pinnedServerProvider) GetGroupByName(name string, iid *windows.GUID) (ppUnk *com.IUnknown, err error) {
+	if doErr := p.runtime.do(func() { ppUnk, err = p.provider.GetGroupByName(name, iid) }); doErr != nil {
+		return nil, doErr
+	}
+	return
+}
+
+// CreateGroupEnumerator returns an interface of an enumerator over the groups in scope.
+func (p *pinnedServerProvider) CreateGroupEnumerator(scope uint32, iid *windows.GUID) (ppUnk *com.IUnknown, err error) {
+	if doErr := p.runtime.do(func() { ppUnk, err = p.provider.CreateGroupEnumerator(scope, iid) }); doErr != nil {
+		return nil, doErr
+	}
+	return
+}
+
+// RemoveGroup removes the specified group from the server.
+func (p *pinnedServerProvider) RemoveGroup(serverGroup uint32, force bool) (err error) {
+	if doErr := p.runtime.do(func() { err = p.provider.RemoveGroup(serverGroup, force) }); doErr != nil {
+		return doErr
+	}
+	return
+}
+
+// Release releases the COM resources associated with the provider. It does nothing once the runtime is closed.
+func (p *pinnedServerProvider) Release() {
+	p.runtime.do(p.provider.Release)
+}
+
+// QueryInterface queries the server for a specific interface.
+func (p *pinnedServerProvider) QueryInterface(iid *windows.GUID, ppv unsafe.Pointer) (err error) {
+	if doErr := p.runtime.do(func() { err = p.provider.QueryInterface(iid, ppv) }); doErr != nil {
+		return doErr
+	}
+	return
+}
+
+// AdviseShutdown connects sink to the IOPCShutdown connection point of the server.
+func (p *pinnedServerProvider) AdviseShutdown(sink *com.IUnknown) (cookie uint32, err error) {
+	if doErr := p.runtime.do(func() { cookie, err = p.provider.AdviseShutdown(sink) }); doErr != nil {
+		return 0, doErr
+	}
+	return
+}
+
+// UnadviseShutdown disconnects the sink identified by cookie and releases the connection point.
+func (p *pinnedServerProvider) UnadviseShutdown(cookie uint32) (err error) {
+	if doErr := p.runtime.do(func() { err = p.provider.UnadviseShutdown(cookie) }); doErr != nil {
+		return doErr
+	}
+	return
+}
+
+// pinnedGroupProvider forwards the calls of a groupProvider to the thread of a PinnedRuntime.
+type pinnedGroupProvider struct {
+	runtime  *PinnedRuntime
+	provider groupProvider
+}
+
+// SetName sets the name of the group.
+func (p *pinnedGroupProvider) SetName(name string) (err error) {
+	if doErr := p.runtime.do(func() { err = p.provider.SetName(name) }); doErr != nil {
+		return doErr
+	}
+	return
+}
+
+// GetState retrieves the current state of the group.
+func (p *pinnedGroupProvider) GetState() (updateRate uint32, active bool, name string, timeBias int32, deadband float32, localeID uint32, clientHandle uint32, serverHandle uint32, err error) {
+	if doErr := p.runtime.do(func() {
+		updateRate, active, name, timeBias, deadband, localeID, clientHandle, serverHandle, err = p.provider.GetState()
+	}); doErr != nil {
+		return 0, false, "", 0, 0, 0, 0, 0, doErr
+	}
+	return
+}
+
+// SetState sets the state of the group.
+func (p *pinnedGroupProvider) SetState(pRequestedUpdateRate *uint32, pActive *int32, pTimeBias *int32, pPercentDeadband *float32, pLCID *uint32, phClientGroup *uint32) (revised uint32, err error) {
+	if doErr := p.runtime.do(func() {
+		revised, err = p.provider.SetState(pRequestedUpdateRate, pActive, pTimeBias, pPercentDeadband, pLCID, phClientGroup)
+	}); doErr != nil {
+		return 0, doErr
+	}
+	return
+}
+
+// SyncRead performs a synchronous read of item values.
+func (p *pinnedGroupProvider) SyncRead(source com.OPCDATASOURCE, serverHandles []uint32) (states []*com.ItemState, errs []int32, err error) {
+	if doErr := p.runtime.do(func() { states, errs, err = p.provider.SyncRead(source, serverHandles) }); doErr != nil {
+		return nil, nil, doErr
+	}
+	return
+}
+
+// SyncWrite performs a synchronous write of item values.
+func (p *pinnedGroupProvider) SyncWrite(serverHandles []uint32, values []com.VARIANT) (errs []int32, err error) {
+	if doErr := p.runtime.do(func() { errs, err = p.provider.SyncWrite(serverHandles, values) }); doErr != nil {
+		return nil, doErr
+	}
+	return
+}
+
+// AsyncRead performs an asynchronous read of item values.
+func (p *pinnedGroupProvider) AsyncRead(serverHandles []uint32, transactionID uint32) (cancelID uint32, errs []int32, err error) {
+	if doErr := p.runtime.do(func() { cancelID, errs, err = p.provider.AsyncRead(serverHandles, transactionID) }); doErr != nil {
+		return 0, nil, doErr
+	}
+	return
+}
+
+// AsyncWrite performs an asynchronous write of item values.
+func (p *pinnedGroupProvider) AsyncWrite(serverHandles []uint32, values []com.VARIANT, transactionID uint32) (cancelID uint32, errs []int32, err error) {
+	if doErr := p.runtime.do(func() { cancelID, errs, err = p.provider.AsyncWrite(serverHandles, values, transactionID) }); doErr != nil {
+		return 0, nil, doErr
+	}
+	return
+}
+
+// AsyncRefresh forces a callback with current data for all active items.
+func (p *pinnedGroupProvider) AsyncRefresh(source com.OPCDATASOURCE, transactionID uint32) (cancelID uint32, err error) {
+	if doErr := p.runtime.do(func() { cancelID, err = p.provider.AsyncRefresh(source, transactionID) }); doErr != nil {
+		return 0, doErr
+	}
+	return
+}
+
+// AsyncCancel cancels an outstanding asynchronous operation.
+func (p *pinnedGroupProvider) AsyncCancel(cancelID uint32) (err error) {
+	if doErr := p.runtime.do(func() { err = p.provider.AsyncCancel(cancelID) }); doErr != nil {
+		return doErr
+	}
+	return
+}
+
+// QueryInterface queries the group for a specific interface.
+func (p *pinnedGroupProvider) QueryInterface(iid *windows.GUID, ppv unsafe.Pointer) (err error) {
+	if doErr := p.runtime.do(func() { err = p.provider.QueryInterface(iid, ppv) }); doErr != nil {
+		return doErr
+	}
+	return
+}
+
+// Release releases the COM resources associated with the provider. It does nothing once the runtime is closed.
+func (p *pinnedGroupProvider) Release() {
+	p.runtime.do(p.provider.Release)
+}
+
+// pinnedItemMgtProvider forwards the calls of an itemMgtProvider to the thread of a PinnedRuntime.
+type pinnedItemMgtProvider struct {
+	runtime  *PinnedRuntime
+	provider itemMgtProvider
+}
+
+// AddItems adds items to the group.
+func (p *pinnedItemMgtProvider) AddItems(items []com.TagOPCITEMDEF) (results []com.TagOPCITEMRESULTStruct, errs []int32, err error) {
+	if doErr := p.runtime.do(func() { results, errs, err = p.provider.AddItems(items) }); doErr != nil {
+		return nil, nil, doErr
+	}
+	return
+}
+
+// ValidateItems validates items without adding them.
+func (p *pinnedItemMgtProvider) ValidateItems(items []com.TagOPCITEMDEF, bBlob bool) (results []com.TagOPCITEMRESULTStruct, errs []int32, err error) {
+	if doErr := p.runtime.do(func() { results, errs, err = p.provider.ValidateItems(items, bBlob) }); doErr != nil {
+		return nil, nil, doErr
+	}
+	return
+}
+
+// RemoveItems removes items from the group.
+func (p *pinnedItemMgtProvider) RemoveItems(serverHandles []uint32) (errs []int32, err error) {
+	if doErr := p.runtime.do(func() { errs, err = p.provider.RemoveItems(serverHandles) }); doErr != nil {
+		return nil, doErr
+	}
+	return
+}
+
+// SetActiveState sets the active state for the specified items.
+func (p *pinnedItemMgtProvider) SetActiveState(serverHandles []uint32, bActive bool) (errs []int32, err error) {
+	if doErr := p.runtime.do(func() { errs, err = p.provider.SetActiveState(serverHandles, bActive) }); doErr != nil {
+		return nil, doErr
+	}
+	return
+}
+
+// SetClientHandles sets the client handles for the specified items.
+func (p *pinnedItemMgtProvider) SetClientHandles(serverHandles []uint32, clientHandles []uint32) (errs []int32, err error) {
+	if doErr := p.runtime.do(func() { errs, err = p.provider.SetClientHandles(serverHandles, clientHandles) }); doErr != nil {
+		return nil, doErr
+	}
+	return
+}
+
+// SetDatatypes sets the requested data types for the specified items.
+func (p *pinnedItemMgtProvider) SetDatatypes(serverHandles []uint32, requestedDataTypes []com.VT) (errs []int32, err error) {
+	if doErr := p.runtime.do(func() { errs, err = p.provider.SetDatatypes(serverHandles, requestedDataTypes) }); doErr != nil {
+		return nil, doErr
+	}
+	return
+}
+
+// Release releases the COM resources associated with the provider. It does nothing once the runtime is closed.
+func (p *pinnedItemMgtProvider) Release() {
+	p.runtime.do(p.provider.Release)
+}
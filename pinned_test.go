@@ -0,0 +1,173 @@
+//go:build windows
+
+package opcda
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wends155/opcda/com"
+	"golang.org/x/sys/windows"
+)
+
+func swapPinnedCOM(t *testing.T, initThread *uint32, uninitialized *int) {
+	oldInit, oldUninit := initializeCOM, uninitializeCOM
+	t.Cleanup(func() { initializeCOM, uninitializeCOM = oldInit, oldUninit })
+	initializeCOM = func(config *com.InitConfig) (com.InitResult, error) {
+		assert.True(t, config.TolerateExisting)
+		*initThread = windows.GetCurrentThreadId()
+		return com.InitResult{NeedsUninitialize: true}, nil
+	}
+	uninitializeCOM = func() { *uninitialized++ }
+}
+
+func TestPinnedRuntime_Mocked(t *testing.T) {
+	var initThread uint32
+	uninitialized := 0
+	swapPinnedCOM(t, &initThread, &uninitialized)
+
+	var mu sync.Mutex
+	threads := map[uint32]bool{}
+	record := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		threads[windows.GetCurrentThreadId()] = true
+	}
+	provider := &mockServerProvider{
+		GetStatusFn: func() (*com.ServerStatus, error) {
+			record()
+			return &com.ServerStatus{}, nil
+		},
+		AddGroupFn: func(name string, active bool, updateRate uint32, clientGroup uint32, timeBias *int32, deadband *float32, localeID uint32, iid *windows.GUID) (uint32, uint32, *com.IUnknown, error) {
+			record()
+			return 1, updateRate, nil, nil
+		},
+	}
+	// the connection keeps the factories it was connected with, like a COM connection
+	f := comFactories()
+	f.connect = func(progID, node string, authInfo *com.COAUTHINFO) (*OPCServer, error) {
+		record()
+		s := newOPCServerWithProvider(provider, progID, node)
+		*s.factories = *f
+		return s, nil
+	}
+	f.newGroup = func(gs *OPCGroups, iUnknown *com.IUnknown, clientGroupHandle uint32, serverGroupHandle uint32, groupName string, revisedUpdateRate uint32) (*OPCGroup, error) {
+		record()
+		g := &OPCGroup{
+			parent:   gs,
+			provider: gs.provider,
+			groupProvider: &mockGroupProvider{
+				SetNameFn: func(name string) error {
+					record()
+					return nil
+				},
+			},
+			groupName: groupName,
+		}
+		g.items = NewOPCItems(g, &mockItemMgtProvider{
+			ValidateItemsFn: func(items []com.TagOPCITEMDEF, bBlob bool) ([]com.TagOPCITEMRESULTStruct, []int32, error) {
+				record()
+				return make([]com.TagOPCITEMRESULTStruct, len(items)), make([]int32, len(items)), nil
+			},
+		}, gs.provider)
+		return g, nil
+	}
+
+	rt, err := NewPinnedRuntime()
+	assert.NoError(t, err)
+	server, err := rt.connect(f, "mock", "localhost", nil)
+	assert.NoError(t, err)
+	_, err = server.GetStartTime()
+	assert.NoError(t, err)
+	group, err := server.GetOPCGroups().Add("g1")
+	assert.NoError(t, err)
+	assert.NoError(t, group.SetName("g2"))
+	_, err = group.OPCItems().Validate([]string{"a"}, nil, nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[uint32]bool{initThread: true}, threads)
+
+	assert.NoError(t, rt.Close())
+	assert.NoError(t, rt.Close())
+	assert.Equal(t, 1, uninitialized)
+	_, err = server.GetStartTime()
+	assert.ErrorIs(t, err, ErrRuntimeClosed)
+}
+
+func TestPinnedRuntime_SharedApartment_Mocked(t *testing.T) {
+	apartment, err := com.NewApartmentWithInit(func() (func(), error) { return func() {}, nil })
+	assert.NoError(t, err)
+	rt := NewPinnedRuntimeOn(apartment)
+	assert.Same(t, apartment, rt.Apartment())
+
+	var thread uint32
+	assert.NoError(t, apartment.Do(func() { thread = windows.GetCurrentThreadId() }))
+	var pinnedThread uint32
+	assert.NoError(t, rt.do(func() { pinnedThread = windows.GetCurrentThreadId() }))
+	assert.Equal(t, thread, pinnedThread)
+
+	assert.NoError(t, rt.Close())
+	assert.ErrorIs(t, rt.do(func() {}), ErrRuntimeClosed)
+	assert.ErrorIs(t, apartment.Do(func() {}), com.ErrApartmentClosed)
+}
+
+func TestPinnedRuntime_OptionalInterfaces_Mocked(t *testing.T) {
+	apartment, err := com.NewApartmentWithInit(func() (func(), error) { return func() {}, nil })
+	assert.NoError(t, err)
+	rt := NewPinnedRuntimeOn(apartment)
+	defer rt.Close()
+	var pinnedThread uint32
+	assert.NoError(t, rt.do(func() { pinnedThread = windows.GetCurrentThreadId() }))
+
+	var mu sync.Mutex
+	threads := map[uint32]bool{}
+	record := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		threads[windows.GetCurrentThreadId()] = true
+	}
+	f := comFactories()
+	f.connect = func(progID, node string, authInfo *com.COAUTHINFO) (*OPCServer, error) {
+		record()
+		s := newOPCServerWithProvider(&mockServerProvider{}, progID, node)
+		*s.factories = *f
+		return s, nil
+	}
+	f.queryBrowse3 = func(serverProvider, *com.COAUTHINFO) (browse3Provider, error) {
+		record()
+		return &mockBrowse3Provider{
+			BrowseFn: func(string, com.BrowseOptions) ([]com.BrowseElement, error) {
+				record()
+				return nil, nil
+			},
+			ReleaseFn: record,
+		}, nil
+	}
+	f.querySyncIO2 = func(groupProvider, *com.COAUTHINFO) (syncIO2Provider, error) {
+		record()
+		return &mockSyncIO2Provider{
+			ReadMaxAgeFn: func(serverHandles []uint32, maxAge []uint32) ([]*com.ItemState, []int32, error) {
+				record()
+				return []*com.ItemState{{}}, []int32{0}, nil
+			},
+			ReleaseFn: record,
+		}, nil
+	}
+
+	server, err := rt.connect(f, "mock", "localhost", nil)
+	assert.NoError(t, err)
+	_, err = server.BrowseFlat("", "*")
+	assert.NoError(t, err)
+	group := &OPCGroup{parent: server.GetOPCGroups(), groupProvider: &mockGroupProvider{}}
+	_, _, err = group.ReadMaxAge([]uint32{1}, []uint32{0})
+	assert.NoError(t, err)
+	group.Release()
+
+	// a clone connects through the runtime of the original connection
+	clone, err := server.Clone()
+	assert.NoError(t, err)
+	assert.Same(t, rt, clone.pinned)
+
+	assert.Equal(t, map[uint32]bool{pinnedThread: true}, threads)
+}
@@ -0,0 +1,326 @@
+//go:build windows
+
+package opcda
+
+import (
+	"github.com/wends155/opcda/com"
+	"golang.org/x/sys/windows"
+)
+
+// pinOptional acquires an optional interface with query on the thread of r and routes the calls of the
+// result through the thread with wrap. Without a runtime query runs directly and the result is not wrapped.
+func pinOptional[T any](r *PinnedRuntime, query func() (T, error), wrap func(T) T) (T, error) {
+	var result T
+	err := r.call(func() (err error) {
+		result, err = query()
+		return
+	})
+	if err != nil || r == nil {
+		return result, err
+	}
+	return wrap(result), nil
+}
+
+// browse3 acquires the IOPCBrowse interface of the server on the thread of its pinned runtime, if any.
+func (s *OPCServer) browse3() (browse3Provider, error) {
+	return pinOptional(s.pinned, func() (browse3Provider, error) { return s.factories.queryBrowse3(s.provider, s.authInfo) },
+		func(p browse3Provider) browse3Provider { return &pinnedBrowse3Provider{runtime: s.pinned, provider: p} })
+}
+
+// browser acquires the IOPCBrowseServerAddressSpace interface of the server on the thread of its pinned
+// runtime, if any.
+func (s *OPCServer) browser() (browserProvider, error) {
+	return pinOptional(s.pinned, func() (browserProvider, error) { return queryComBrowser(s) },
+		func(p browserProvider) browserProvider { return &pinnedBrowserProvider{runtime: s.pinned, provider: p} })
+}
+
+// pinnedItemIO acquires the IOPCItemIO interface of the server on the thread of its pinned runtime, if any.
+func (s *OPCServer) pinnedItemIO() (itemIOProvider, error) {
+	return pinOptional(s.pinned, func() (itemIOProvider, error) { return s.factories.queryItemIO(s.provider, s.authInfo) },
+		func(p itemIOProvider) itemIOProvider { return &pinnedItemIOProvider{runtime: s.pinned, provider: p} })
+}
+
+// publicGroups acquires the IOPCServerPublicGroups interface of the server on the thread of its pinned
+// runtime, if any.
+func (gs *OPCGroups) publicGroups() (publicGroupsProvider, error) {
+	var r *PinnedRuntime
+	if gs.parent != nil {
+		r = gs.parent.pinned
+	}
+	return pinOptional(r, func() (publicGroupsProvider, error) {
+		return gs.factories.queryPublicGroups(gs.provider, gs.authInfo())
+	},
+		func(p publicGroupsProvider) publicGroupsProvider {
+			return &pinnedPublicGroupsProvider{runtime: r, provider: p}
+		})
+}
+
+// pinnedSyncIO2 acquires the IOPCSyncIO2 interface of the group on the thread of its pinned runtime, if any.
+func (g *OPCGroup) pinnedSyncIO2() (syncIO2Provider, error) {
+	r := g.runtime()
+	return pinOptional(r, func() (syncIO2Provider, error) {
+		return g.factories().querySyncIO2(g.groupProvider, g.parent.authInfo())
+	},
+		func(p syncIO2Provider) syncIO2Provider { return &pinnedSyncIO2Provider{runtime: r, provider: p} })
+}
+
+// connectionPoints acquires the connection points of the group on the thread of its pinned runtime, if any.
+func (g *OPCGroup) connectionPoints() (connectionPointsProvider, error) {
+	r := g.runtime()
+	return pinOptional(r, func() (connectionPointsProvider, error) {
+		return g.factories().openConnectionPoints(g.groupProvider, g.parent.authInfo())
+	},
+		func(p connectionPointsProvider) connectionPointsProvider {
+			return &pinnedConnectionPoints{runtime: r, provider: p}
+		})
+}
+
+// pinnedBrowse3Provider forwards the calls of a browse3Provider to the thread of a PinnedRuntime.
+type pinnedBrowse3Provider struct {
+	runtime  *PinnedRuntime
+	provider browse3Provider
+}
+
+// Browse returns the elements below a branch.
+func (p *pinnedBrowse3Provider) Browse(itemID string, options com.BrowseOptions) (elements []com.BrowseElement, err error) {
+	if doErr := p.runtime.do(func() { elements, err = p.provider.Browse(itemID, options) }); doErr != nil {
+		return nil, doErr
+	}
+	return
+}
+
+// Release releases the COM resources associated with the provider. It does nothing once the runtime is closed.
+func (p *pinnedBrowse3Provider) Release() {
+	p.runtime.do(p.provider.Release)
+}
+
+// pinnedItemIOProvider forwards the calls of a itemIOProvider to the thread of a PinnedRuntime.
+type pinnedItemIOProvider struct {
+	runtime  *PinnedRuntime
+	provider itemIOProvider
+}
+
+// Read reads the items with the given maximum ages in milliseconds.
+func (p *pinnedItemIOProvider) Read(itemIDs []string, maxAge []uint32) (states []*com.ItemState, errs []int32, err error) {
+	if doErr := p.runtime.do(func() { states, errs, err = p.provider.Read(itemIDs, maxAge) }); doErr != nil {
+		return nil, nil, doErr
+	}
+	return
+}
+
+// WriteVQT writes values, qualities and timestamps to the items.
+func (p *pinnedItemIOProvider) WriteVQT(itemIDs []string, values []com.TagOPCITEMVQT) (errs []int32, err error) {
+	if doErr := p.runtime.do(func() { errs, err = p.provider.WriteVQT(itemIDs, values) }); doErr != nil {
+		return nil, doErr
+	}
+	return
+}
+
+// Release releases the COM resources associated with the provider. It does nothing once the runtime is closed.
+func (p *pinnedItemIOProvider) Release() {
+	p.runtime.do(p.provider.Release)
+}
+
+// pinnedSyncIO2Provider forwards the calls of a syncIO2Provider to the thread of a PinnedRuntime.
+type pinnedSyncIO2Provider struct {
+	runtime  *PinnedRuntime
+	provider syncIO2Provider
+}
+
+// ReadMaxAge reads the items with the given maximum ages in milliseconds.
+func (p *pinnedSyncIO2Provider) ReadMaxAge(serverHandles []uint32, maxAge []uint32) (states []*com.ItemState, errs []int32, err error) {
+	if doErr := p.runtime.do(func() { states, errs, err = p.provider.ReadMaxAge(serverHandles, maxAge) }); doErr != nil {
+		return nil, nil, doErr
+	}
+	return
+}
+
+// WriteVQT writes values, qualities and timestamps to the items.
+func (p *pinnedSyncIO2Provider) WriteVQT(serverHandles []uint32, values []com.TagOPCITEMVQT) (errs []int32, err error) {
+	if doErr := p.runtime.do(func() { errs, err = p.provider.WriteVQT(serverHandles, values) }); doErr != nil {
+		return nil, doErr
+	}
+	return
+}
+
+// Release releases the COM resources associated with the provider. It does nothing once the runtime is closed.
+func (p *pinnedSyncIO2Provider) Release() {
+	p.runtime.do(p.provider.Release)
+}
+
+// pinnedItemSamplingMgtProvider forwards the calls of a itemSamplingMgtProvider to the thread of a PinnedRuntime.
+type pinnedItemSamplingMgtProvider struct {
+	runtime  *PinnedRuntime
+	provider itemSamplingMgtProvider
+}
+
+// SetItemSamplingRate sets the sampling rate of the specified items and returns the revised rates.
+func (p *pinnedItemSamplingMgtProvider) SetItemSamplingRate(serverHandles []uint32, samplingRates []uint32) (revised []uint32, errs []int32, err error) {
+	if doErr := p.runtime.do(func() { revised, errs, err = p.provider.SetItemSamplingRate(serverHandles, samplingRates) }); doErr != nil {
+		return nil, nil, doErr
+	}
+	return
+}
+
+// GetItemSamplingRate retrieves the sampling rate of the specified items.
+func (p *pinnedItemSamplingMgtProvider) GetItemSamplingRate(serverHandles []uint32) (rates []uint32, errs []int32, err error) {
+	if doErr := p.runtime.do(func() { rates, errs, err = p.provider.GetItemSamplingRate(serverHandles) }); doErr != nil {
+		return nil, nil, doErr
+	}
+	return
+}
+
+// ClearItemSamplingRate makes the specified items use the group update rate again.
+func (p *pinnedItemSamplingMgtProvider) ClearItemSamplingRate(serverHandles []uint32) (errs []int32, err error) {
+	if doErr := p.runtime.do(func() { errs, err = p.provider.ClearItemSamplingRate(serverHandles) }); doErr != nil {
+		return nil, doErr
+	}
+	return
+}
+
+// SetItemBufferEnable enables or disables buffering for the specified items.
+func (p *pinnedItemSamplingMgtProvider) SetItemBufferEnable(serverHandles []uint32, enable []bool) (errs []int32, err error) {
+	if doErr := p.runtime.do(func() { errs, err = p.provider.SetItemBufferEnable(serverHandles, enable) }); doErr != nil {
+		return nil, doErr
+	}
+	return
+}
+
+// GetItemBufferEnable retrieves the buffering state of the specified items.
+func (p *pinnedItemSamplingMgtProvider) GetItemBufferEnable(serverHandles []uint32) (enabled []bool, errs []int32, err error) {
+	if doErr := p.runtime.do(func() { enabled, errs, err = p.provider.GetItemBufferEnable(serverHandles) }); doErr != nil {
+		return nil, nil, doErr
+	}
+	return
+}
+
+// Release releases the COM resources associated with the provider. It does nothing once the runtime is closed.
+func (p *pinnedItemSamplingMgtProvider) Release() {
+	p.runtime.do(p.provider.Release)
+}
+
+// pinnedItemDeadbandMgtProvider forwards the calls of a itemDeadbandMgtProvider to the thread of a PinnedRuntime.
+type pinnedItemDeadbandMgtProvider struct {
+	runtime  *PinnedRuntime
+	provider itemDeadbandMgtProvider
+}
+
+// SetItemDeadband sets the percent deadband of the specified items.
+func (p *pinnedItemDeadbandMgtProvider) SetItemDeadband(serverHandles []uint32, deadbands []float32) (errs []int32, err error) {
+	if doErr := p.runtime.do(func() { errs, err = p.provider.SetItemDeadband(serverHandles, deadbands) }); doErr != nil {
+		return nil, doErr
+	}
+	return
+}
+
+// GetItemDeadband retrieves the percent deadband of the specified items.
+func (p *pinnedItemDeadbandMgtProvider) GetItemDeadband(serverHandles []uint32) (deadbands []float32, errs []int32, err error) {
+	if doErr := p.runtime.do(func() { deadbands, errs, err = p.provider.GetItemDeadband(serverHandles) }); doErr != nil {
+		return nil, nil, doErr
+	}
+	return
+}
+
+// ClearItemDeadband makes the specified items use the group deadband again.
+func (p *pinnedItemDeadbandMgtProvider) ClearItemDeadband(serverHandles []uint32) (errs []int32, err error) {
+	if doErr := p.runtime.do(func() { errs, err = p.provider.ClearItemDeadband(serverHandles) }); doErr != nil {
+		return nil, doErr
+	}
+	return
+}
+
+// Release releases the COM resources associated with the provider. It does nothing once the runtime is closed.
+func (p *pinnedItemDeadbandMgtProvider) Release() {
+	p.runtime.do(p.provider.Release)
+}
+
+// pinnedBrowserProvider forwards the calls of a browserProvider to the thread of a PinnedRuntime.
+type pinnedBrowserProvider struct {
+	runtime  *PinnedRuntime
+	provider browserProvider
+}
+
+// GetItemID retrieves the item ID for the specified item data ID.
+func (p *pinnedBrowserProvider) GetItemID(szItemDataID string) (itemID string, err error) {
+	if doErr := p.runtime.do(func() { itemID, err = p.provider.GetItemID(szItemDataID) }); doErr != nil {
+		return "", doErr
+	}
+	return
+}
+
+// QueryOrganization retrieves the organization of the address space.
+func (p *pinnedBrowserProvider) QueryOrganization() (organization com.OPCNAMESPACETYPE, err error) {
+	if doErr := p.runtime.do(func() { organization, err = p.provider.QueryOrganization() }); doErr != nil {
+		return 0, doErr
+	}
+	return
+}
+
+// BrowseOPCItemIDs browses the address space for item IDs.
+func (p *pinnedBrowserProvider) BrowseOPCItemIDs(dwBrowseFilterType com.OPCBROWSETYPE, szFilterCriteria string, vtDataTypeFilter uint16, dwAccessRightsFilter uint32) (itemIDs []string, err error) {
+	if doErr := p.runtime.do(func() {
+		itemIDs, err = p.provider.BrowseOPCItemIDs(dwBrowseFilterType, szFilterCriteria, vtDataTypeFilter, dwAccessRightsFilter)
+	}); doErr != nil {
+		return nil, doErr
+	}
+	return
+}
+
+// ChangeBrowsePosition changes the current browse position.
+func (p *pinnedBrowserProvider) ChangeBrowsePosition(dwBrowseDirection com.OPCBROWSEDIRECTION, szString string) (err error) {
+	if doErr := p.runtime.do(func() { err = p.provider.ChangeBrowsePosition(dwBrowseDirection, szString) }); doErr != nil {
+		return doErr
+	}
+	return
+}
+
+// Release releases the COM resources associated with the provider. It does nothing once the runtime is closed.
+func (p *pinnedBrowserProvider) Release() {
+	p.runtime.do(p.provider.Release)
+}
+
+// pinnedPublicGroupsProvider forwards the calls of a publicGroupsProvider to the thread of a PinnedRuntime.
+type pinnedPublicGroupsProvider struct {
+	runtime  *PinnedRuntime
+	provider publicGroupsProvider
+}
+
+// GetPublicGroupByName connects to the public group name and returns its IOPCGroupStateMgt interface.
+func (p *pinnedPublicGroupsProvider) GetPublicGroupByName(name string) (ppUnk *com.IUnknown, err error) {
+	if doErr := p.runtime.do(func() { ppUnk, err = p.provider.GetPublicGroupByName(name) }); doErr != nil {
+		return nil, doErr
+	}
+	return
+}
+
+// Release releases the COM resources associated with the provider. It does nothing once the runtime is closed.
+func (p *pinnedPublicGroupsProvider) Release() {
+	p.runtime.do(p.provider.Release)
+}
+
+// pinnedConnectionPoints forwards the calls of a connectionPointsProvider to the thread of a PinnedRuntime.
+type pinnedConnectionPoints struct {
+	runtime  *PinnedRuntime
+	provider connectionPointsProvider
+}
+
+// Connections lists the connections of every connection point.
+func (p *pinnedConnectionPoints) Connections() (connections []ConnectionInfo, err error) {
+	if doErr := p.runtime.do(func() { connections, err = p.provider.Connections() }); doErr != nil {
+		return nil, doErr
+	}
+	return
+}
+
+// Unadvise disconnects a connection of the connection point of the interface.
+func (p *pinnedConnectionPoints) Unadvise(iid windows.GUID, cookie uint32) (err error) {
+	if doErr := p.runtime.do(func() { err = p.provider.Unadvise(iid, cookie) }); doErr != nil {
+		return doErr
+	}
+	return
+}
+
+// Release releases the COM resources associated with the provider. It does nothing once the runtime is closed.
+func (p *pinnedConnectionPoints) Release() {
+	p.runtime.do(p.provider.Release)
+}
@@ -0,0 +1,71 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/wends155/opcda/com"
+)
+
+// retryProperties applies the RetryPropertiesIndividually workaround to the properties of a GetItemProperties
+// call that could not be decoded or failed with E_FAIL, replacing their entries in data and itemErrors.
+func (s *OPCServer) retryProperties(itemID string, propertyIDs []uint32, codes []int32, data []interface{}, itemErrors []error) {
+	for i, id := range propertyIDs {
+		if i >= len(data) || i >= len(itemErrors) {
+			break
+		}
+		var unsupported *com.ErrUnsupportedVariant
+		failed := errors.As(itemErrors[i], &unsupported) || (i < len(codes) && uint32(codes[i]) == com.E_FAIL)
+		if !failed {
+			continue
+		}
+		value, err := s.retryProperty(itemID, id, itemErrors[i])
+		if err != nil {
+			itemErrors[i] = &QuirkError{Quirk: QuirkRetryPropertiesIndividually, Err: err}
+			s.logQuirk(QuirkRetryPropertiesIndividually, fmt.Sprintf("property %d of %q not recovered: %v", id, itemID, err))
+			continue
+		}
+		data[i], itemErrors[i] = value, nil
+		s.logQuirk(QuirkRetryPropertiesIndividually, fmt.Sprintf("property %d of %q recovered", id, itemID))
+	}
+}
+
+// retryProperty requests property id of itemID on its own and, if that fails too, reads the item ID the
+// server maps the property to from the device. It returns the error of the last attempt, or failed if no
+// attempt reported one.
+func (s *OPCServer) retryProperty(itemID string, id uint32, failed error) (interface{}, error) {
+	err := failed
+	data, codes, callErr := s.provider.GetItemProperties(itemID, []uint32{id})
+	switch {
+	case callErr != nil:
+		err = callErr
+	case len(data) != 1 || len(codes) != 1:
+	case codes[0] < 0:
+		err = s.getError(codes[0])
+	default:
+		unsupported, undecoded := data[0].(*com.ErrUnsupportedVariant)
+		if !undecoded {
+			return data[0], nil
+		}
+		err = unsupported
+	}
+
+	// properties 1 to 6 describe the item itself and have no item ID of their own
+	itemIDs, lookupCodes, lookupErr := s.provider.LookupItemIDs(itemID, []uint32{id})
+	if lookupErr != nil || len(itemIDs) != 1 || len(lookupCodes) != 1 || lookupCodes[0] < 0 || itemIDs[0] == "" {
+		return nil, err
+	}
+	results, readErr := s.ReadItemsFrom(itemIDs, OPC_DS_DEVICE)
+	if readErr != nil {
+		return nil, fmt.Errorf("%w; read %q: %w", err, itemIDs[0], readErr)
+	}
+	if len(results) != 1 {
+		return nil, err
+	}
+	if results[0].Err != nil {
+		return nil, fmt.Errorf("%w; read %q: %w", err, itemIDs[0], results[0].Err)
+	}
+	return results[0].Value, nil
+}
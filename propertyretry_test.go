@@ -0,0 +1,77 @@
+//go:build windows
+
+package opcda
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wends155/opcda/com"
+)
+
+const eFail = int32(-2147467259) // E_FAIL
+
+// newPropertyRetryServer returns a server whose properties 2, 100 and 101 fail in a batch. Alone, property 2
+// decodes, while 100 and 101 still fail; 100 maps to the item ID "Item.EU", which reads 42.
+func newPropertyRetryServer(t *testing.T, quirks Quirks) (*OPCServer, *int) {
+	calls := 0
+	server := newOPCServerWithProvider(&mockServerProvider{
+		GetItemPropertiesFn: func(itemID string, propertyIDs []uint32) ([]interface{}, []int32, error) {
+			calls++
+			if len(propertyIDs) > 1 {
+				return []interface{}{int32(1), &com.ErrUnsupportedVariant{VT: com.VT_CY}, nil, nil},
+					[]int32{0, 0, eFail, eFail}, nil
+			}
+			if propertyIDs[0] == 2 {
+				return []interface{}{"decoded"}, []int32{0}, nil
+			}
+			return []interface{}{nil}, []int32{eFail}, nil
+		},
+		LookupItemIDsFn: func(itemID string, propertyIDs []uint32) ([]string, []int32, error) {
+			if propertyIDs[0] == 100 {
+				return []string{"Item.EU"}, []int32{0}, nil
+			}
+			return []string{""}, []int32{int32(OPCInvalidPID)}, nil
+		},
+	}, "mock", "localhost")
+	assert.NoError(t, server.SetQuirks(quirks))
+	server.factories.queryItemIO = func(serverProvider, *com.COAUTHINFO) (itemIOProvider, error) {
+		return &mockItemIOProvider{
+			ReadFn: func(itemIDs []string, maxAge []uint32) ([]*com.ItemState, []int32, error) {
+				assert.Equal(t, []string{"Item.EU"}, itemIDs)
+				return []*com.ItemState{{Value: float64(42), Quality: OPC_QUALITY_GOOD}}, []int32{0}, nil
+			},
+		}, nil
+	}
+	return server, &calls
+}
+
+func TestOPCServer_GetItemProperties_RetryIndividually_Mocked(t *testing.T) {
+	server, calls := newPropertyRetryServer(t, Quirks{RetryPropertiesIndividually: true})
+
+	data, errs, err := server.GetItemProperties("Item", []uint32{1, 2, 100, 101})
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{int32(1), "decoded", float64(42), nil}, data)
+	assert.NoError(t, errs[0])
+	assert.NoError(t, errs[1])
+	assert.NoError(t, errs[2], "a property failing alone is read through its item ID")
+	var quirkErr *QuirkError
+	if assert.ErrorAs(t, errs[3], &quirkErr) {
+		assert.Equal(t, QuirkRetryPropertiesIndividually, quirkErr.Quirk)
+	}
+	assert.Equal(t, 4, *calls)
+	assert.Len(t, server.QuirkLog(), 3)
+}
+
+func TestOPCServer_GetItemProperties_RetryOff_Mocked(t *testing.T) {
+	server, calls := newPropertyRetryServer(t, Quirks{})
+
+	data, errs, err := server.GetItemProperties("Item", []uint32{1, 2, 100, 101})
+	assert.NoError(t, err)
+	assert.Equal(t, com.VT_CY, data[1])
+	var unsupported *com.ErrUnsupportedVariant
+	assert.ErrorAs(t, errs[1], &unsupported)
+	assert.Error(t, errs[2])
+	assert.Equal(t, 1, *calls, "well-behaved servers must not see extra calls")
+	assert.Empty(t, server.QuirkLog())
+}
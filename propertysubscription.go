@@ -0,0 +1,117 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// propertySubscriptionBuffer is the capacity of the channels of a property subscription.
+const propertySubscriptionBuffer = 16
+
+// SubscribeProperty streams the changes of a property of the item, such as the limits of a dynamic EU
+// range (OPC_PROPERTY_HIGH_EU and OPC_PROPERTY_LOW_EU), on servers that expose properties as items of
+// their own. It looks up the item ID of the property with LookupItemIDs, adds it to a new active group
+// with the update rate rate in milliseconds, and sends every new value of the property to the returned
+// channel, starting with the current one. Updates that carry an error are skipped.
+//
+// The returned function ends the subscription: it removes the group and closes the channel. It must be
+// called to release the group and may be called more than once. Values are not dropped, so the channel
+// must be drained until then.
+//
+// Example:
+//
+//	highs, stop, err := item.SubscribeProperty(opcda.OPC_PROPERTY_HIGH_EU, 1000)
+//	if err != nil {
+//		return err
+//	}
+//	defer stop()
+//	for high := range highs {
+//		log.Printf("high EU of %s is now %v", item.GetItemID(), high)
+//	}
+func (i *OPCItem) SubscribeProperty(prop PropertyID, rate uint32) (<-chan interface{}, func(), error) {
+	if i == nil || i.parent == nil || i.parent.parent == nil || i.parent.parent.parent == nil || i.parent.parent.parent.parent == nil {
+		return nil, nil, errors.New("uninitialized item")
+	}
+	gs := i.parent.parent.parent
+	ids, err := gs.parent.LookupItemIDs(i.tag, []uint32{uint32(prop)})
+	if err != nil {
+		return nil, nil, err
+	}
+	if ids[0].Err != nil {
+		return nil, nil, fmt.Errorf("look up item ID of property %d of %q: %w", prop, i.tag, ids[0].Err)
+	}
+	if ids[0].ItemID == "" {
+		return nil, nil, fmt.Errorf("property %d of %q has no item ID", prop, i.tag)
+	}
+
+	group, err := gs.Add("")
+	if err != nil {
+		return nil, nil, err
+	}
+	ch := make(chan *DataChangeCallBackData, propertySubscriptionBuffer)
+	item, err := subscribePropertyItem(group, ids[0].ItemID, rate, ch)
+	if err != nil {
+		gs.Remove(group.GetServerHandle(), true)
+		return nil, nil, err
+	}
+
+	out := make(chan interface{}, propertySubscriptionBuffer)
+	done := make(chan struct{})
+	go forwardProperty(ch, out, done, item.GetClientHandle())
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			group.UnregisterDataChange(ch)
+			gs.Remove(group.GetServerHandle(), true)
+			close(done)
+		})
+	}
+	return out, stop, nil
+}
+
+// subscribePropertyItem prepares the group of a property subscription and adds the property item to it.
+func subscribePropertyItem(group *OPCGroup, itemID string, rate uint32, ch chan *DataChangeCallBackData) (*OPCItem, error) {
+	err := group.SetUpdateRate(rate)
+	if err != nil {
+		return nil, err
+	}
+	err = group.RegisterDataChange(ch)
+	if err != nil {
+		return nil, err
+	}
+	item, err := group.OPCItems().AddItem(itemID)
+	if err != nil {
+		return nil, err
+	}
+	err = group.SetIsActive(true)
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// forwardProperty sends the values of the item with the client handle from the data changes of ch to out,
+// until done is closed. It closes out when it returns.
+func forwardProperty(ch chan *DataChangeCallBackData, out chan interface{}, done chan struct{}, clientHandle uint32) {
+	defer close(out)
+	for {
+		select {
+		case <-done:
+			return
+		case data := <-ch:
+			for k, h := range data.ItemClientHandles {
+				if h != clientHandle || k >= len(data.Values) || (k < len(data.Errors) && data.Errors[k] != nil) {
+					continue
+				}
+				select {
+				case out <- data.Values[k]:
+				case <-done:
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,92 @@
+//go:build windows
+
+package opcda
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wends155/opcda/com"
+	"golang.org/x/sys/windows"
+)
+
+func TestOPCItem_SubscribeProperty_Mocked(t *testing.T) {
+	removed, serverGroup := 0, uint32(3)
+	provider := &mockServerProvider{
+		AddGroupFn: func(name string, active bool, updateRate uint32, clientGroup uint32, timeBias *int32, deadband *float32, localeID uint32, iid *windows.GUID) (uint32, uint32, *com.IUnknown, error) {
+			serverGroup++
+			return serverGroup, updateRate, nil, nil
+		},
+		RemoveGroupFn: func(serverGroup uint32, force bool) error {
+			assert.Equal(t, uint32(5), serverGroup)
+			removed++
+			return nil
+		},
+		LookupItemIDsFn: func(itemID string, propertyIDs []uint32) ([]string, []int32, error) {
+			assert.Equal(t, "Tank.Level", itemID)
+			if propertyIDs[0] == uint32(OPC_PROPERTY_HIGH_EU) {
+				return []string{"Tank.Level.High"}, []int32{0}, nil
+			}
+			return []string{""}, []int32{int32(OPCInvalidPID)}, nil
+		},
+	}
+	server := newOPCServerWithProvider(provider, "mock", "localhost")
+	var added []string
+	server.factories.newGroup = func(gs *OPCGroups, iUnknown *com.IUnknown, clientGroupHandle uint32, serverGroupHandle uint32, groupName string, revisedUpdateRate uint32) (*OPCGroup, error) {
+		g := &OPCGroup{parent: gs, provider: gs.provider, groupProvider: &mockGroupProvider{}, serverGroupHandle: serverGroupHandle}
+		// a non-nil event makes the data callback count as advised
+		g.event = &DataEventReceiver{}
+		g.items = NewOPCItems(g, &mockItemMgtProvider{
+			AddItemsFn: func(items []com.TagOPCITEMDEF) ([]com.TagOPCITEMRESULTStruct, []int32, error) {
+				added = append(added, windows.UTF16PtrToString(items[0].SzItemID))
+				return []com.TagOPCITEMRESULTStruct{{Server: 1}}, []int32{0}, nil
+			},
+		}, gs.provider)
+		return g, nil
+	}
+	groups := server.GetOPCGroups()
+	owner, err := groups.Add("owner")
+	assert.NoError(t, err)
+	owner.event = nil
+	item, err := owner.OPCItems().AddItem("Tank.Level")
+	assert.NoError(t, err)
+
+	_, _, err = item.SubscribeProperty(OPC_PROPERTY_EU_UNITS, 1000)
+	assert.Error(t, err)
+
+	values, stop, err := item.SubscribeProperty(OPC_PROPERTY_HIGH_EU, 1000)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Tank.Level", "Tank.Level.High"}, added)
+	group, err := groups.GetOPCGroup(5)
+	assert.NoError(t, err)
+	handle := group.OPCItems().items[0].GetClientHandle()
+
+	group.fireDataChange(context.Background(), &CDataChangeCallBackData{
+		ItemClientHandles: []uint32{handle, handle},
+		Values:            []interface{}{float64(100), float64(0)},
+		Errors:            []int32{0, int32(OPCBadType)},
+	})
+	group.fireDataChange(context.Background(), &CDataChangeCallBackData{
+		ItemClientHandles: []uint32{handle},
+		Values:            []interface{}{float64(120)},
+	})
+	for _, want := range []float64{100, 120} {
+		select {
+		case v := <-values:
+			assert.Equal(t, want, v)
+		case <-time.After(time.Second):
+			t.Fatal("property value was not delivered")
+		}
+	}
+
+	group.event = nil
+	stop()
+	stop()
+	assert.Equal(t, 1, removed)
+	assert.Eventually(t, func() bool {
+		_, open := <-values
+		return !open
+	}, time.Second, time.Millisecond)
+}
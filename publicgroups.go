@@ -0,0 +1,160 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"github.com/wends155/opcda/com"
+)
+
+// ErrPublicGroupsNotSupported is returned by AddPublic and ConnectPublic when the server does not implement
+// public groups, that is IOPCServerPublicGroups or IOPCPublicGroupStateMgt.
+var ErrPublicGroupsNotSupported = errors.New("server does not support public groups")
+
+// publicGroupsProvider defines the internal contract for access to public groups.
+// It abstracts the optional IOPCServerPublicGroups server interface to allow for mocking and testing.
+type publicGroupsProvider interface {
+	// GetPublicGroupByName connects to the public group name and returns its IOPCGroupStateMgt interface.
+	GetPublicGroupByName(name string) (*com.IUnknown, error)
+	// Release releases the COM resources associated with the provider.
+	Release()
+}
+
+// comPublicGroupsProvider is the concrete implementation of publicGroupsProvider using COM.
+type comPublicGroupsProvider struct {
+	publicGroups *com.IOPCServerPublicGroups
+}
+
+// GetPublicGroupByName connects to the public group name and returns its IOPCGroupStateMgt interface.
+func (p *comPublicGroupsProvider) GetPublicGroupByName(name string) (*com.IUnknown, error) {
+	return p.publicGroups.GetPublicGroupByName(name, &com.IID_IOPCGroupStateMgt)
+}
+
+// Release releases the COM resources associated with the provider.
+func (p *comPublicGroupsProvider) Release() {
+	p.publicGroups.Release()
+}
+
+// queryComPublicGroups queries the server for IOPCServerPublicGroups and applies authInfo to the new proxy.
+func queryComPublicGroups(provider serverProvider, authInfo *com.COAUTHINFO) (publicGroupsProvider, error) {
+	var iUnknown *com.IUnknown
+	err := provider.QueryInterface(&com.IID_IOPCServerPublicGroups, unsafe.Pointer(&iUnknown))
+	if err != nil {
+		return nil, err
+	}
+	err = setProxyBlanket(iUnknown, authInfo)
+	if err != nil {
+		iUnknown.Release()
+		return nil, NewOPCWrapperError("set proxy blanket IOPCServerPublicGroups", err)
+	}
+	return &comPublicGroupsProvider{publicGroups: &com.IOPCServerPublicGroups{IUnknown: iUnknown}}, nil
+}
+
+// moveComGroupToPublic queries the group for IOPCPublicGroupStateMgt and calls MoveToPublic.
+func moveComGroupToPublic(g *OPCGroup) error {
+	var iUnknown *com.IUnknown
+	err := g.groupProvider.QueryInterface(&com.IID_IOPCPublicGroupStateMgt, unsafe.Pointer(&iUnknown))
+	if err != nil {
+		return err
+	}
+	defer iUnknown.Release()
+	err = setProxyBlanket(iUnknown, g.parent.authInfo())
+	if err != nil {
+		return NewOPCWrapperError("set proxy blanket IOPCPublicGroupStateMgt", err)
+	}
+	return (&com.IOPCPublicGroupStateMgt{IUnknown: iUnknown}).MoveToPublic()
+}
+
+// notSupportedPublic wraps err with ErrPublicGroupsNotSupported if the server lacks the interface.
+func notSupportedPublic(err error) error {
+	if errors.Is(err, com.HRESULT(com.E_NOINTERFACE)) {
+		return fmt.Errorf("%w: %w", ErrPublicGroupsNotSupported, err)
+	}
+	return err
+}
+
+// AddPublic creates a group like Add and makes it a public group, which other clients can connect to by
+// name with ConnectPublic. If the server does not support public groups or refuses the move, for example
+// because a public group of that name exists, the new group is removed again and the error returned;
+// unsupported servers return an error wrapping ErrPublicGroupsNotSupported.
+//
+// Example:
+//
+//	group, err := server.GetOPCGroups().AddPublic("Plant")
+//	if errors.Is(err, opcda.ErrPublicGroupsNotSupported) {
+//		group, err = server.GetOPCGroups().Add("Plant")
+//	}
+func (gs *OPCGroups) AddPublic(name string) (*OPCGroup, error) {
+	group, err := gs.Add(name)
+	if err != nil {
+		return nil, err
+	}
+	err = group.runtime().call(func() error { return gs.factories.moveToPublic(group) })
+	if err != nil {
+		err = notSupportedPublic(err)
+		if removeErr := gs.RemoveOPCGroup(group); removeErr != nil {
+			err = errors.Join(err, fmt.Errorf("remove group %q: %w", name, removeErr))
+		}
+		return nil, err
+	}
+	group.public = true
+	return group, nil
+}
+
+// ConnectPublic connects to the existing public group name, defined on the server or made public by a
+// client with AddPublic, and adds the connection to the collection. Its state, server handle and update rate
+// are read from the server. Removing the group with Remove or RemoveOPCGroup ends this connection only; the
+// public group stays on the server. Servers without public groups return an error wrapping
+// ErrPublicGroupsNotSupported.
+//
+// Example:
+//
+//	group, err := server.GetOPCGroups().ConnectPublic("Plant")
+//	if err != nil {
+//		return err
+//	}
+//	items := group.OPCItems()
+func (gs *OPCGroups) ConnectPublic(name string) (*OPCGroup, error) {
+	if gs == nil || gs.provider == nil {
+		return nil, errors.New("uninitialized groups or failed server connection")
+	}
+	gs.Lock()
+	defer gs.Unlock()
+	group, err := gs.connectPublic(name)
+	if err != nil {
+		return nil, err
+	}
+	gs.groups = append(gs.groups, group)
+	return group, nil
+}
+
+// connectPublic connects to the public group name and binds a new OPCGroup to the connection. The caller
+// must hold gs.
+func (gs *OPCGroups) connectPublic(name string) (*OPCGroup, error) {
+	publicGroups, err := gs.publicGroups()
+	if err != nil {
+		return nil, notSupportedPublic(err)
+	}
+	defer publicGroups.Release()
+	ppUnk, err := publicGroups.GetPublicGroupByName(name)
+	if err != nil {
+		return nil, err
+	}
+	group, err := gs.bindExisting(ppUnk, name)
+	if err != nil {
+		return nil, err
+	}
+	group.public = true
+	return group, nil
+}
+
+// IsPublic reports whether the group is a public group, added with AddPublic or connected with ConnectPublic.
+func (g *OPCGroup) IsPublic() bool {
+	if g == nil {
+		return false
+	}
+	return g.public
+}
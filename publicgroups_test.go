@@ -0,0 +1,105 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wends155/opcda/com"
+	"golang.org/x/sys/windows"
+)
+
+// mockPublicGroupFactories replaces the COM factories of public groups and the group construction of server.
+func mockPublicGroupFactories(server *OPCServer, query func(serverProvider, *com.COAUTHINFO) (publicGroupsProvider, error), move func(*OPCGroup) error, gp *mockGroupProvider) {
+	server.factories.queryPublicGroups, server.factories.moveToPublic = query, move
+	server.factories.newGroup = func(gs *OPCGroups, iUnknown *com.IUnknown, clientGroupHandle uint32, serverGroupHandle uint32, groupName string, revisedUpdateRate uint32) (*OPCGroup, error) {
+		return &OPCGroup{
+			parent:            gs,
+			provider:          gs.provider,
+			groupProvider:     gp,
+			groupName:         groupName,
+			clientGroupHandle: clientGroupHandle,
+			serverGroupHandle: serverGroupHandle,
+			revisedUpdateRate: revisedUpdateRate,
+		}, nil
+	}
+}
+
+func TestOPCGroups_ConnectPublic_Mocked(t *testing.T) {
+	var released bool
+	var requested string
+	server := newOPCServerWithProvider(&mockServerProvider{}, "mock", "localhost")
+	mockPublicGroupFactories(server, func(serverProvider, *com.COAUTHINFO) (publicGroupsProvider, error) {
+		return &mockPublicGroupsProvider{
+			GetPublicGroupByNameFn: func(name string) (*com.IUnknown, error) {
+				requested = name
+				return nil, nil
+			},
+			ReleaseFn: func() { released = true },
+		}, nil
+	}, nil, &mockGroupProvider{
+		GetStateFn: func() (uint32, bool, string, int32, float32, uint32, uint32, uint32, error) {
+			return 500, true, "Plant", 60, 1.5, 0x0409, 3, 77, nil
+		},
+	})
+	groups := server.GetOPCGroups()
+
+	group, err := groups.ConnectPublic("Plant")
+	assert.NoError(t, err)
+	assert.Equal(t, "Plant", requested)
+	assert.True(t, released, "the IOPCServerPublicGroups interface must be released")
+	assert.True(t, group.IsPublic())
+	assert.Equal(t, uint32(77), group.GetServerHandle())
+	assert.Equal(t, uint32(3), group.GetClientHandle())
+	assert.Equal(t, uint32(500), group.GetRevisedUpdateRate())
+	assert.True(t, group.requested.active)
+	assert.Equal(t, float32(1.5), group.requested.deadband)
+	assert.Equal(t, 1, groups.GetCount())
+}
+
+func TestOPCGroups_ConnectPublic_NotSupported_Mocked(t *testing.T) {
+	server := newOPCServerWithProvider(&mockServerProvider{}, "mock", "localhost")
+	mockPublicGroupFactories(server, func(serverProvider, *com.COAUTHINFO) (publicGroupsProvider, error) {
+		return nil, com.HRESULT(com.E_NOINTERFACE)
+	}, nil, &mockGroupProvider{})
+
+	_, err := server.GetOPCGroups().ConnectPublic("Plant")
+	assert.ErrorIs(t, err, ErrPublicGroupsNotSupported)
+	assert.Zero(t, server.GetOPCGroups().GetCount())
+
+	var nilGroups *OPCGroups
+	_, err = nilGroups.ConnectPublic("Plant")
+	assert.Error(t, err)
+}
+
+func TestOPCGroups_AddPublic_Mocked(t *testing.T) {
+	moveErr := error(nil)
+	var removed []uint32
+	server := newOPCServerWithProvider(&mockServerProvider{
+		AddGroupFn: func(name string, active bool, updateRate uint32, clientGroup uint32, timeBias *int32, deadband *float32, localeID uint32, iid *windows.GUID) (uint32, uint32, *com.IUnknown, error) {
+			return clientGroup + 10, updateRate, nil, nil
+		},
+		RemoveGroupFn: func(serverGroup uint32, force bool) error {
+			removed = append(removed, serverGroup)
+			return nil
+		},
+	}, "mock", "localhost")
+	mockPublicGroupFactories(server, nil, func(g *OPCGroup) error { return moveErr }, &mockGroupProvider{})
+	groups := server.GetOPCGroups()
+
+	group, err := groups.AddPublic("Plant")
+	assert.NoError(t, err)
+	assert.True(t, group.IsPublic())
+
+	moveErr = com.HRESULT(com.E_NOINTERFACE)
+	_, err = groups.AddPublic("Unsupported")
+	assert.ErrorIs(t, err, ErrPublicGroupsNotSupported)
+	moveErr = errors.New("duplicate name")
+	_, err = groups.AddPublic("Plant")
+	assert.ErrorIs(t, err, moveErr)
+	assert.Len(t, removed, 2, "groups that could not be made public are removed again")
+	assert.Equal(t, 1, groups.GetCount())
+	assert.False(t, (&OPCGroup{}).IsPublic())
+}
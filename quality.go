@@ -0,0 +1,169 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// UncertainPolicy decides how values with UNCERTAIN quality are treated where a decision is gated on quality,
+// such as writing only good values, filtering subscriptions or retrying reads. The library applies the policy
+// of a group to the data change subscriptions registered WithUsableQuality; reads and other subscriptions
+// deliver values with the quality the server reported, and the caller consults the policy through Usable or
+// OPCGroup.QualityUsable.
+type UncertainPolicy int32
+
+const (
+	// UncertainPassthrough lets UNCERTAIN values pass the quality checks of the caller. It is the default.
+	UncertainPassthrough UncertainPolicy = iota + 1
+	// UncertainAsGood treats UNCERTAIN values as GOOD.
+	UncertainAsGood
+	// UncertainAsBad treats UNCERTAIN values as BAD.
+	UncertainAsBad
+)
+
+// String returns the name of the policy.
+func (p UncertainPolicy) String() string {
+	switch p {
+	case UncertainPassthrough:
+		return "Passthrough"
+	case UncertainAsGood:
+		return "AsGood"
+	case UncertainAsBad:
+		return "AsBad"
+	}
+	return fmt.Sprintf("UncertainPolicy(%d)", int32(p))
+}
+
+// valid reports whether p is one of the defined policies.
+func (p UncertainPolicy) valid() bool {
+	return p >= UncertainPassthrough && p <= UncertainAsBad
+}
+
+// EffectiveQuality returns the major quality (OPC_QUALITY_GOOD, OPC_QUALITY_UNCERTAIN or OPC_QUALITY_BAD)
+// of quality after applying the policy. Only UNCERTAIN qualities are affected.
+func (p UncertainPolicy) EffectiveQuality(quality uint16) uint16 {
+	major := quality & OPC_QUALITY_MASK
+	if major == OPC_QUALITY_GOOD || major == OPC_QUALITY_BAD {
+		return major
+	}
+	switch p {
+	case UncertainAsGood:
+		return OPC_QUALITY_GOOD
+	case UncertainAsBad:
+		return OPC_QUALITY_BAD
+	}
+	return OPC_QUALITY_UNCERTAIN
+}
+
+// Usable reports whether a value with the quality passes a quality gate under the policy.
+// GOOD values always pass and BAD values never do; UNCERTAIN values pass unless the policy is UncertainAsBad.
+func (p UncertainPolicy) Usable(quality uint16) bool {
+	return p.EffectiveQuality(quality) != OPC_QUALITY_BAD
+}
+
+// defaultUncertainPolicy holds the package-wide policy used by groups without their own.
+var defaultUncertainPolicy = int32(UncertainPassthrough)
+
+// SetDefaultUncertainPolicy sets the package-wide UncertainPolicy used by groups that have not set their own.
+func SetDefaultUncertainPolicy(policy UncertainPolicy) error {
+	if !policy.valid() {
+		return fmt.Errorf("invalid uncertain policy %d", int32(policy))
+	}
+	atomic.StoreInt32(&defaultUncertainPolicy, int32(policy))
+	return nil
+}
+
+// GetDefaultUncertainPolicy returns the package-wide UncertainPolicy.
+func GetDefaultUncertainPolicy() UncertainPolicy {
+	return UncertainPolicy(atomic.LoadInt32(&defaultUncertainPolicy))
+}
+
+// SetUncertainPolicy sets the UncertainPolicy that QualityUsable and the subscriptions registered
+// WithUsableQuality apply for the group, overriding the package default. A zero policy reverts to the
+// package default.
+func (g *OPCGroup) SetUncertainPolicy(policy UncertainPolicy) error {
+	if g == nil {
+		return errors.New("uninitialized group")
+	}
+	if policy != 0 && !policy.valid() {
+		return fmt.Errorf("invalid uncertain policy %d", int32(policy))
+	}
+	atomic.StoreInt32(&g.uncertainPolicy, int32(policy))
+	return nil
+}
+
+// GetUncertainPolicy returns the UncertainPolicy in effect for the group: its own, or the package default.
+func (g *OPCGroup) GetUncertainPolicy() UncertainPolicy {
+	if g != nil {
+		if p := UncertainPolicy(atomic.LoadInt32(&g.uncertainPolicy)); p != 0 {
+			return p
+		}
+	}
+	return GetDefaultUncertainPolicy()
+}
+
+// QualityUsable reports whether a value with the quality is usable under the UncertainPolicy of the group.
+// It is a helper for the quality checks of the caller, such as in a data change handler.
+//
+// Example:
+//
+//	for i, quality := range data.Qualities {
+//		if group.QualityUsable(quality) {
+//			process(data.Values[i])
+//		}
+//	}
+func (g *OPCGroup) QualityUsable(quality uint16) bool {
+	return g.GetUncertainPolicy().Usable(quality)
+}
+
+// WithUsableQuality makes the new subscriber receive only the values whose quality is usable under the
+// UncertainPolicy of the group at delivery time, as reported by QualityUsable. The entries of other values
+// are removed from the data change, and a data change left without entries is not delivered, except for
+// the initial snapshot requested WithInitialSnapshot. The qualities delivered are those the server reported.
+//
+// Example:
+//
+//	err := group.SetUncertainPolicy(opcda.UncertainAsBad)
+//	ch := make(chan *opcda.DataChangeCallBackData, 16)
+//	err = group.RegisterDataChangeWithPolicy(ch, opcda.DeliveryDropNewest, opcda.WithUsableQuality())
+func WithUsableQuality() DataChangeOption {
+	return func(o *dataChangeOptions) {
+		o.usableQuality = true
+	}
+}
+
+// usableOnly returns a copy of data holding only the entries whose quality is usable under the policy of
+// the group, or data itself if every entry is usable.
+func (g *OPCGroup) usableOnly(data *DataChangeCallBackData) *DataChangeCallBackData {
+	policy := g.GetUncertainPolicy()
+	keep := make([]int, 0, len(data.ItemClientHandles))
+	for i := range data.ItemClientHandles {
+		if i >= len(data.Qualities) || policy.Usable(data.Qualities[i]) {
+			keep = append(keep, i)
+		}
+	}
+	if len(keep) == len(data.ItemClientHandles) {
+		return data
+	}
+	filtered := *data
+	filtered.ItemClientHandles = pick(data.ItemClientHandles, keep)
+	filtered.Values = pick(data.Values, keep)
+	filtered.Qualities = pick(data.Qualities, keep)
+	filtered.TimeStamps = pick(data.TimeStamps, keep)
+	filtered.Errors = pick(data.Errors, keep)
+	return &filtered
+}
+
+// pick returns the elements of s at the indexes in keep that s holds.
+func pick[T any](s []T, keep []int) []T {
+	picked := make([]T, 0, len(keep))
+	for _, i := range keep {
+		if i < len(s) {
+			picked = append(picked, s[i])
+		}
+	}
+	return picked
+}
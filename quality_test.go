@@ -0,0 +1,112 @@
+//go:build windows
+
+package opcda
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUncertainPolicy_EffectiveQuality(t *testing.T) {
+	const (
+		good            = OPC_QUALITY_GOOD | 0x03 // GOOD, high limited
+		uncertain       = OPC_QUALITY_UNCERTAIN | 0x14
+		bad             = OPC_QUALITY_BAD | 0x18
+		waitingForFirst = OPC_QUALITY_WAITING_FOR_INITIAL_DATA
+	)
+	tests := []struct {
+		policy                  UncertainPolicy
+		good, uncertain, bad    uint16
+		usableUncertain, usable bool
+	}{
+		{UncertainPassthrough, OPC_QUALITY_GOOD, OPC_QUALITY_UNCERTAIN, OPC_QUALITY_BAD, true, true},
+		{UncertainAsGood, OPC_QUALITY_GOOD, OPC_QUALITY_GOOD, OPC_QUALITY_BAD, true, true},
+		{UncertainAsBad, OPC_QUALITY_GOOD, OPC_QUALITY_BAD, OPC_QUALITY_BAD, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			assert.Equal(t, tt.good, tt.policy.EffectiveQuality(good))
+			assert.Equal(t, tt.uncertain, tt.policy.EffectiveQuality(uncertain))
+			assert.Equal(t, tt.bad, tt.policy.EffectiveQuality(bad))
+			assert.Equal(t, tt.usableUncertain, tt.policy.Usable(uncertain))
+			assert.Equal(t, tt.usable, tt.policy.Usable(good))
+			assert.False(t, tt.policy.Usable(bad))
+			assert.False(t, tt.policy.Usable(waitingForFirst))
+		})
+	}
+}
+
+func TestUncertainPolicy_DefaultAndGroupOverride(t *testing.T) {
+	defer SetDefaultUncertainPolicy(GetDefaultUncertainPolicy())
+	assert.Equal(t, UncertainPassthrough, GetDefaultUncertainPolicy())
+
+	alarms := &OPCGroup{}
+	trending := &OPCGroup{}
+	assert.NoError(t, SetDefaultUncertainPolicy(UncertainAsGood))
+	assert.NoError(t, alarms.SetUncertainPolicy(UncertainAsBad))
+	assert.False(t, alarms.QualityUsable(OPC_QUALITY_UNCERTAIN))
+	assert.True(t, trending.QualityUsable(OPC_QUALITY_UNCERTAIN))
+	assert.Equal(t, UncertainAsGood, trending.GetUncertainPolicy())
+
+	assert.NoError(t, alarms.SetUncertainPolicy(0))
+	assert.Equal(t, UncertainAsGood, alarms.GetUncertainPolicy())
+
+	assert.Error(t, SetDefaultUncertainPolicy(0))
+	assert.Error(t, alarms.SetUncertainPolicy(UncertainPolicy(9)))
+	assert.Equal(t, "UncertainPolicy(9)", UncertainPolicy(9).String())
+
+	var nilGroup *OPCGroup
+	assert.Error(t, nilGroup.SetUncertainPolicy(UncertainAsBad))
+	assert.Equal(t, UncertainAsGood, nilGroup.GetUncertainPolicy())
+}
+
+func TestOPCGroup_WithUsableQuality_Mocked(t *testing.T) {
+	group := newSnapshotTestGroup(nil)
+	group.items.items[0].quality = OPC_QUALITY_UNCERTAIN
+	assert.NoError(t, group.SetUncertainPolicy(UncertainAsBad))
+	usable := make(chan *DataChangeCallBackData, 4)
+	all := make(chan *DataChangeCallBackData, 4)
+	assert.NoError(t, group.RegisterDataChangeWithPolicy(usable, DeliveryDropNewest, WithUsableQuality(), WithInitialSnapshot(time.Minute)))
+	assert.NoError(t, group.RegisterDataChange(all))
+
+	initial := <-usable
+	assert.True(t, initial.Initial)
+	assert.Empty(t, initial.ItemClientHandles)
+
+	change := func() *CDataChangeCallBackData {
+		return &CDataChangeCallBackData{
+			ItemClientHandles: []uint32{1, 2, 3},
+			Values:            []interface{}{int32(1), int32(2), int32(3)},
+			Qualities:         []uint16{OPC_QUALITY_GOOD, OPC_QUALITY_UNCERTAIN, OPC_QUALITY_BAD},
+			TimeStamps:        make([]time.Time, 3),
+			Errors:            make([]int32, 3),
+		}
+	}
+	group.fireDataChange(context.Background(), change())
+	data := <-usable
+	assert.Equal(t, []uint32{1}, data.ItemClientHandles)
+	assert.Equal(t, []interface{}{int32(1)}, data.Values)
+	assert.Equal(t, []uint16{OPC_QUALITY_GOOD}, data.Qualities)
+	assert.Len(t, data.Errors, 1)
+	assert.Len(t, (<-all).ItemClientHandles, 3)
+
+	assert.NoError(t, group.SetUncertainPolicy(UncertainAsGood))
+	group.fireDataChange(context.Background(), change())
+	data = <-usable
+	assert.Equal(t, []uint32{1, 2}, data.ItemClientHandles)
+	assert.Equal(t, []uint16{OPC_QUALITY_GOOD, OPC_QUALITY_UNCERTAIN}, data.Qualities)
+	<-all
+
+	group.fireDataChange(context.Background(), &CDataChangeCallBackData{
+		ItemClientHandles: []uint32{3},
+		Values:            []interface{}{int32(3)},
+		Qualities:         []uint16{OPC_QUALITY_BAD},
+		TimeStamps:        make([]time.Time, 1),
+		Errors:            make([]int32, 1),
+	})
+	assert.Len(t, (<-all).ItemClientHandles, 1)
+	assert.Empty(t, usable)
+}
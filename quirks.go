@@ -0,0 +1,213 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/wends155/opcda/com"
+)
+
+// Quirks enables workarounds for servers that deviate from the OPC DA specification. Each workaround costs
+// extra calls when it triggers, so all of them are off by default and well-behaved servers never pay for them.
+type Quirks struct {
+	// DeactivateForSetDatatypes retries SetDatatypes calls that fail with OPC_E_INVALIDHANDLE on active items
+	// after deactivating the items, and restores their active state afterwards. Some servers reject data type
+	// changes of items that are being scanned.
+	DeactivateForSetDatatypes bool
+	// RetryPropertiesIndividually re-requests the properties of a GetItemProperties call whose values could not
+	// be decoded or that failed with E_FAIL, one at a time and, failing that, by reading the item ID the server
+	// maps the property to. Some servers return broken values for properties in a batch that they return
+	// correctly on their own.
+	RetryPropertiesIndividually bool
+}
+
+// QuirkDeactivateForSetDatatypes names the Quirks.DeactivateForSetDatatypes workaround in quirk log entries and errors.
+const QuirkDeactivateForSetDatatypes = "DeactivateForSetDatatypes"
+
+// QuirkRetryPropertiesIndividually names the Quirks.RetryPropertiesIndividually workaround in quirk log entries and errors.
+const QuirkRetryPropertiesIndividually = "RetryPropertiesIndividually"
+
+// quirkLogSize is the number of entries QuirkLog keeps; older entries are dropped.
+const quirkLogSize = 64
+
+// QuirkEntry records one application of a workaround enabled with SetQuirks.
+type QuirkEntry struct {
+	Time   time.Time // Time is when the workaround was applied.
+	Quirk  string    // Quirk names the workaround, e.g. QuirkDeactivateForSetDatatypes.
+	Detail string    // Detail describes what the workaround did.
+}
+
+// QuirkError is returned for an item when a workaround was applied but the retried call still failed.
+type QuirkError struct {
+	Quirk string // Quirk names the workaround that was applied.
+	Err   error  // Err is the error of the retried call.
+}
+
+// Error returns the error of the retried call, noting the workaround.
+func (e *QuirkError) Error() string {
+	return fmt.Sprintf("%v (after %s workaround)", e.Err, e.Quirk)
+}
+
+// Unwrap returns the error of the retried call.
+func (e *QuirkError) Unwrap() error {
+	return e.Err
+}
+
+// SetQuirks sets the workarounds applied to the calls of the connection.
+func (s *OPCServer) SetQuirks(quirks Quirks) error {
+	if s == nil {
+		return errors.New("uninitialized server connection")
+	}
+	s.quirksLock.Lock()
+	defer s.quirksLock.Unlock()
+	s.quirks = quirks
+	return nil
+}
+
+// GetQuirks returns the workarounds applied to the calls of the connection.
+func (s *OPCServer) GetQuirks() Quirks {
+	if s == nil {
+		return Quirks{}
+	}
+	s.quirksLock.Lock()
+	defer s.quirksLock.Unlock()
+	return s.quirks
+}
+
+// QuirkLog returns the most recent applications of workarounds, oldest first.
+func (s *OPCServer) QuirkLog() []QuirkEntry {
+	if s == nil {
+		return nil
+	}
+	s.quirksLock.Lock()
+	defer s.quirksLock.Unlock()
+	return append([]QuirkEntry(nil), s.quirkLog...)
+}
+
+// logQuirk appends an entry to the quirk log.
+func (s *OPCServer) logQuirk(quirk, detail string) {
+	s.quirksLock.Lock()
+	defer s.quirksLock.Unlock()
+	if len(s.quirkLog) == quirkLogSize {
+		s.quirkLog = append(s.quirkLog[:0], s.quirkLog[1:]...)
+	}
+	s.quirkLog = append(s.quirkLog, QuirkEntry{Time: time.Now(), Quirk: quirk, Detail: detail})
+}
+
+// setDatatypes changes the requested data types of items in one SetDatatypes call and records the new types
+// on the items. With Quirks.DeactivateForSetDatatypes enabled, active items rejected with OPC_E_INVALIDHANDLE
+// are deactivated, retried and reactivated. The returned slice holds the error of each item, nil on success.
+func setDatatypes(mgt itemMgtProvider, server *OPCServer, getError func(int32) error, items []*OPCItem, types []com.VT) ([]error, error) {
+	handles := make([]uint32, len(items))
+	for k, item := range items {
+		handles[k] = item.serverHandle
+	}
+	codes, err := mgt.SetDatatypes(handles, types)
+	if err != nil {
+		return nil, err
+	}
+	if len(codes) != len(items) {
+		return nil, fmt.Errorf("SetDatatypes returned %d results for %d items", len(codes), len(items))
+	}
+	results := make([]error, len(items))
+	var retry []int
+	for k, code := range codes {
+		switch {
+		case code >= 0:
+			items[k].setRequestedDataType(types[k])
+		case uint32(code) == OPCInvalidHandle && server.GetQuirks().DeactivateForSetDatatypes && items[k].GetIsActive():
+			retry = append(retry, k)
+		default:
+			results[k] = getError(code)
+		}
+	}
+	if len(retry) > 0 {
+		retryDatatypesInactive(mgt, server, getError, items, types, retry, results)
+	}
+	return results, nil
+}
+
+// retryDatatypesInactive applies the DeactivateForSetDatatypes workaround to the items at the indices in retry,
+// storing the outcome of each item in results.
+func retryDatatypesInactive(mgt itemMgtProvider, server *OPCServer, getError func(int32) error, items []*OPCItem, types []com.VT, retry []int, results []error) {
+	fail := func(k int, err error) {
+		results[k] = &QuirkError{Quirk: QuirkDeactivateForSetDatatypes, Err: err}
+	}
+	handles := make([]uint32, len(retry))
+	for n, k := range retry {
+		handles[n] = items[k].serverHandle
+	}
+	codes, err := mgt.SetActiveState(handles, false)
+	if err == nil && len(codes) != len(retry) {
+		err = fmt.Errorf("SetActiveState returned %d results for %d items", len(codes), len(retry))
+	}
+	if err != nil {
+		for _, k := range retry {
+			fail(k, fmt.Errorf("deactivate: %w", err))
+		}
+		server.logQuirk(QuirkDeactivateForSetDatatypes, fmt.Sprintf("deactivating %d items failed: %v", len(retry), err))
+		return
+	}
+	var inactive []int
+	for n, k := range retry {
+		if codes[n] < 0 {
+			fail(k, fmt.Errorf("deactivate: %w", getError(codes[n])))
+			continue
+		}
+		inactive = append(inactive, k)
+	}
+	if len(inactive) == 0 {
+		server.logQuirk(QuirkDeactivateForSetDatatypes, fmt.Sprintf("deactivating %d items failed", len(retry)))
+		return
+	}
+
+	handles = handles[:0]
+	retryTypes := make([]com.VT, 0, len(inactive))
+	for _, k := range inactive {
+		handles = append(handles, items[k].serverHandle)
+		retryTypes = append(retryTypes, types[k])
+	}
+	var succeeded int
+	codes, err = mgt.SetDatatypes(handles, retryTypes)
+	if err == nil && len(codes) != len(inactive) {
+		err = fmt.Errorf("SetDatatypes returned %d results for %d items", len(codes), len(inactive))
+	}
+	for n, k := range inactive {
+		switch {
+		case err != nil:
+			fail(k, err)
+		case codes[n] < 0:
+			fail(k, getError(codes[n]))
+		default:
+			items[k].setRequestedDataType(types[k])
+			succeeded++
+		}
+	}
+
+	// restore the active state whatever the retry did
+	codes, err = mgt.SetActiveState(handles, true)
+	if err == nil && len(codes) != len(inactive) {
+		err = fmt.Errorf("SetActiveState returned %d results for %d items", len(codes), len(inactive))
+	}
+	for n, k := range inactive {
+		restoreErr := err
+		if restoreErr == nil && codes[n] < 0 {
+			restoreErr = getError(codes[n])
+		}
+		if restoreErr == nil {
+			continue
+		}
+		items[k].Lock()
+		items[k].isActive = false
+		items[k].Unlock()
+		restoreErr = fmt.Errorf("reactivate: %w", restoreErr)
+		if results[k] != nil {
+			restoreErr = errors.Join(results[k].(*QuirkError).Err, restoreErr)
+		}
+		fail(k, restoreErr)
+	}
+	server.logQuirk(QuirkDeactivateForSetDatatypes, fmt.Sprintf("retried SetDatatypes of %d inactive items, %d succeeded", len(inactive), succeeded))
+}
@@ -0,0 +1,114 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wends155/opcda/com"
+)
+
+// scanningServer mocks a server that rejects data type changes of active items with OPC_E_INVALIDHANDLE.
+type scanningServer struct {
+	active       map[uint32]bool
+	failRetry    bool
+	setDatatypes int
+	setActive    []bool
+}
+
+// newQuirkTestItems adds an active and an inactive item to a group of a server driven by m.
+func newQuirkTestItems(t *testing.T, m *scanningServer, quirks Quirks) (*OPCServer, *OPCItems, []*OPCItem) {
+	m.active = make(map[uint32]bool)
+	server := newOPCServerWithProvider(&mockServerProvider{}, "mock", "localhost")
+	assert.NoError(t, server.SetQuirks(quirks))
+	group := newInflightTestGroup(server, &mockGroupProvider{})
+	group.items = NewOPCItems(group, &mockItemMgtProvider{
+		AddItemsFn: func(defs []com.TagOPCITEMDEF) ([]com.TagOPCITEMRESULTStruct, []int32, error) {
+			results := make([]com.TagOPCITEMRESULTStruct, len(defs))
+			for i := range results {
+				results[i].Server = uint32(100 + i)
+				m.active[results[i].Server] = defs[i].BActive != 0
+			}
+			return results, make([]int32, len(defs)), nil
+		},
+		SetDatatypesFn: func(serverHandles []uint32, requestedDataTypes []com.VT) ([]int32, error) {
+			m.setDatatypes++
+			errs := make([]int32, len(serverHandles))
+			for i, h := range serverHandles {
+				if m.active[h] || (m.failRetry && m.setDatatypes > 1) {
+					errs[i] = int32(OPCInvalidHandle)
+				}
+			}
+			return errs, nil
+		},
+		SetActiveStateFn: func(serverHandles []uint32, bActive bool) ([]int32, error) {
+			m.setActive = append(m.setActive, bActive)
+			for _, h := range serverHandles {
+				m.active[h] = bActive
+			}
+			return make([]int32, len(serverHandles)), nil
+		},
+	}, server.provider)
+	items, _, err := group.items.AddItemsWithOptions([]ItemDef{{Tag: "a", Active: true}, {Tag: "b"}})
+	assert.NoError(t, err)
+	return server, group.items, items
+}
+
+func TestOPCItem_SetRequestedDataType_QuirkOff_Mocked(t *testing.T) {
+	m := &scanningServer{}
+	server, _, items := newQuirkTestItems(t, m, Quirks{})
+
+	err := items[0].SetRequestedDataType(com.VT_R8)
+	assert.ErrorIs(t, err, ErrInvalidHandle)
+	assert.Equal(t, 1, m.setDatatypes)
+	assert.Empty(t, m.setActive, "well-behaved servers must not see extra calls")
+	assert.Empty(t, server.QuirkLog())
+}
+
+func TestOPCItem_SetRequestedDataType_DeactivatesAndRetries_Mocked(t *testing.T) {
+	m := &scanningServer{}
+	server, _, items := newQuirkTestItems(t, m, Quirks{DeactivateForSetDatatypes: true})
+
+	assert.NoError(t, items[0].SetRequestedDataType(com.VT_R8))
+	assert.Equal(t, com.VT_R8, items[0].GetRequestedDataType())
+	assert.Equal(t, []bool{false, true}, m.setActive)
+	assert.True(t, m.active[items[0].GetServerHandle()], "the active state must be restored")
+	assert.True(t, items[0].GetIsActive())
+	log := server.QuirkLog()
+	if assert.Len(t, log, 1) {
+		assert.Equal(t, QuirkDeactivateForSetDatatypes, log[0].Quirk)
+	}
+}
+
+func TestOPCItems_SetDataTypes_QuirkRetryFails_Mocked(t *testing.T) {
+	m := &scanningServer{failRetry: true}
+	server, items, added := newQuirkTestItems(t, m, Quirks{DeactivateForSetDatatypes: true})
+
+	errs := items.SetDataTypes([]uint32{added[0].GetServerHandle(), added[1].GetServerHandle(), 999}, []com.VT{com.VT_R8, com.VT_R8, com.VT_R8})
+	var quirkErr *QuirkError
+	if assert.ErrorAs(t, errs[0], &quirkErr) {
+		assert.Equal(t, QuirkDeactivateForSetDatatypes, quirkErr.Quirk)
+	}
+	assert.ErrorIs(t, errs[0], ErrInvalidHandle)
+	assert.NoError(t, errs[1], "inactive items are not affected by the workaround")
+	assert.Error(t, errs[2])
+	assert.False(t, errors.As(errs[2], &quirkErr))
+	assert.Equal(t, com.VT_EMPTY, added[0].GetRequestedDataType())
+	assert.Equal(t, com.VT_R8, added[1].GetRequestedDataType())
+	assert.Equal(t, []bool{false, true}, m.setActive)
+	assert.Len(t, server.QuirkLog(), 1)
+}
+
+func TestOPCServer_QuirkLog_Bounded(t *testing.T) {
+	server := newOPCServerWithProvider(&mockServerProvider{}, "mock", "localhost")
+	for range quirkLogSize + 5 {
+		server.logQuirk(QuirkDeactivateForSetDatatypes, "retried")
+	}
+	assert.Len(t, server.QuirkLog(), quirkLogSize)
+
+	var nilServer *OPCServer
+	assert.Error(t, nilServer.SetQuirks(Quirks{}))
+	assert.Nil(t, nilServer.QuirkLog())
+}
@@ -0,0 +1,266 @@
+//go:build windows
+
+package opcda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/wends155/opcda/com"
+	"golang.org/x/sys/windows"
+)
+
+// Reconnect re-establishes the connection to the same server, for example after a shutdown notification.
+// It connects again with the ProgID, node and credentials of the original connection, then recreates
+// every group with its requested settings and client handle, re-adds the items of each group with their
+// access path, requested data type, active state and client handle, and restores the data callback and
+// shutdown registrations. The existing OPCGroup and OPCItem values stay valid and are bound to the new
+// connection; groups and items the server no longer accepts are removed.
+//
+// If the new connection cannot be established, the old state is left untouched so Reconnect can be retried.
+// Errors re-creating individual groups or items are joined in the returned error.
+// Once the new connection is established and rebuilt, the functions registered with OnReconnect are called.
+func (s *OPCServer) Reconnect() error {
+	if s == nil || s.groups == nil {
+		return errors.New("uninitialized server connection")
+	}
+	fresh, err := s.connectAgain()
+	if err != nil {
+		return err
+	}
+	err = s.rebuild(fresh)
+	s.fireReconnect()
+	return err
+}
+
+// connectAgain opens a new connection to the server of s with the factories of s, through its pinned
+// runtime if it has one.
+func (s *OPCServer) connectAgain() (*OPCServer, error) {
+	if s.pinned != nil {
+		return s.pinned.connect(s.factories, s.Name, s.Node, s.authInfo)
+	}
+	return s.factories.connect(s.Name, s.Node, s.authInfo)
+}
+
+// OnReconnect registers fn to be called after every reconnection, once Reconnect has rebuilt the groups
+// and items, so post-connect setup can be repeated. It is called even when some groups or items could not
+// be restored, on the goroutine that called Reconnect and after the server locks are released, so fn may
+// use the server. A nil fn is ignored.
+func (s *OPCServer) OnReconnect(fn func()) {
+	if s == nil || fn == nil {
+		return
+	}
+	s.reconnectLock.Lock()
+	defer s.reconnectLock.Unlock()
+	s.reconnectHooks = append(s.reconnectHooks, fn)
+}
+
+// fireReconnect calls the functions registered with OnReconnect.
+func (s *OPCServer) fireReconnect() {
+	s.reconnectLock.Lock()
+	hooks := append([]func(){}, s.reconnectHooks...)
+	s.reconnectLock.Unlock()
+	for _, fn := range hooks {
+		fn()
+	}
+}
+
+// rebuild replaces the connection of the server with fresh and restores the groups, items and
+// callback registrations on it.
+func (s *OPCServer) rebuild(fresh *OPCServer) error {
+	var err error
+
+	// drop the old connection; errors are expected since the server is usually gone
+	var legacy []chan string
+	var events []chan ShutdownEvent
+	if s.event != nil {
+		s.event.mu.Lock()
+		legacy = append(legacy, s.event.receiver...)
+		events = append(events, s.event.events...)
+		s.event.mu.Unlock()
+		if s.provider != nil {
+			s.provider.UnadviseShutdown(s.cookie)
+		}
+		s.event.stop()
+		s.event = nil
+		s.cookie = 0
+	}
+	gs := s.groups
+	gs.Lock()
+	defer gs.Unlock()
+	advised := make([]bool, len(gs.groups))
+	for i, g := range gs.groups {
+		advised[i] = g.event != nil
+		g.Release()
+	}
+	s.releaseScratch()
+	s.releaseItemIO()
+	if s.provider != nil {
+		s.provider.Release()
+	}
+	// the old root context only covers loops of the released connection
+	if s.cancel != nil {
+		s.cancel()
+	}
+	if fresh.cancel != nil {
+		fresh.cancel()
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+
+	s.provider = fresh.provider
+	s.location = fresh.location
+	s.connectedSince.Store(fresh.connectedSince.Load())
+	s.clearErrorMessages()
+	gs.provider = fresh.provider
+
+	var errs []error
+	if s.clientName != "" {
+		err = s.provider.SetClientName(s.clientName)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("set client name: %w", err))
+		}
+	}
+	groups := gs.groups[:0]
+	for i, g := range gs.groups {
+		err = gs.rebind(g)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("re-add group %q: %w", g.groupName, err))
+			continue
+		}
+		groups = append(groups, g)
+		errs = append(errs, g.items.rebind()...)
+		if advised[i] {
+			err = g.advise()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("advise group %q: %w", g.groupName, err))
+			}
+		}
+	}
+	gs.groups = groups
+
+	if len(legacy) > 0 || len(events) > 0 {
+		err = s.adviseShutdown()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("advise shutdown: %w", err))
+		} else {
+			for _, ch := range legacy {
+				s.event.AddReceiver(ch)
+			}
+			for _, ch := range events {
+				s.event.AddEventReceiver(ch)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// rebind recreates a released group on the current connection and binds the existing OPCGroup to it.
+// Public groups are connected to again by name instead, so they must still exist on the server.
+// The caller must hold gs.
+func (gs *OPCGroups) rebind(g *OPCGroup) error {
+	var bound *OPCGroup
+	var serverGroup, revisedUpdateRate uint32
+	var err error
+	if g.public {
+		bound, err = gs.connectPublic(g.groupName)
+		if err != nil {
+			return err
+		}
+		serverGroup, revisedUpdateRate = bound.serverGroupHandle, bound.revisedUpdateRate
+		// the state of a public group is shared with other clients, so the server's is kept
+		g.requested = bound.requested
+	} else {
+		bound, serverGroup, revisedUpdateRate, err = gs.readd(g)
+		if err != nil {
+			return err
+		}
+	}
+	g.provider = gs.provider
+	g.groupProvider = bound.groupProvider
+	g.samplingMgt = bound.samplingMgt
+	g.deadbandMgt = bound.deadbandMgt
+	g.serverGroupHandle = serverGroup
+	g.revisedUpdateRate = revisedUpdateRate
+	if g.items == nil {
+		g.items = NewOPCItems(g, nil, gs.provider)
+	}
+	g.items.itemMgtProvider = bound.items.itemMgtProvider
+	g.items.provider = gs.provider
+	g.settleState(g.requested.active)
+	g.resumeDeviceRefresh()
+	return nil
+}
+
+// readd adds a private group again with the state requested for g and binds a new OPCGroup to it.
+// The caller must hold gs.
+func (gs *OPCGroups) readd(g *OPCGroup) (*OPCGroup, uint32, uint32, error) {
+	serverGroup, revisedUpdateRate, ppUnk, err := gs.provider.AddGroup(
+		g.groupName,
+		g.requested.active,
+		g.requested.updateRate,
+		g.clientGroupHandle,
+		g.requested.timeBiasArg(),
+		g.requested.deadbandArg(),
+		g.requested.localeID,
+		&com.IID_IOPCGroupStateMgt,
+	)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	bound, err := gs.bindGroup(ppUnk, g.clientGroupHandle, serverGroup, g.groupName, revisedUpdateRate)
+	if err != nil {
+		if ppUnk != nil {
+			ppUnk.Release()
+		}
+		gs.provider.RemoveGroup(serverGroup, true)
+		return nil, 0, 0, err
+	}
+	return bound, serverGroup, revisedUpdateRate, nil
+}
+
+// rebind re-adds the items of a rebound group and points them at the new interfaces and server handles.
+// Items the server rejects are removed from the collection and reported in the returned errors.
+func (is *OPCItems) rebind() []error {
+	is.Lock()
+	defer is.Unlock()
+	if len(is.items) == 0 {
+		return nil
+	}
+	definitions := make([]com.TagOPCITEMDEF, len(is.items))
+	for i, item := range is.items {
+		definitions[i] = com.TagOPCITEMDEF{
+			SzAccessPath: windows.StringToUTF16Ptr(item.accessPath),
+			SzItemID:     windows.StringToUTF16Ptr(item.tag),
+			BActive:      com.BoolToComBOOL(item.isActive),
+			HClient:      item.clientHandle,
+			VtRequested:  uint16(item.requestedDataType),
+		}
+	}
+	results, itemErrs, err := is.itemMgtProvider.AddItems(definitions)
+	if err != nil {
+		is.items = nil
+		is.byClientHandle = nil
+		return []error{fmt.Errorf("re-add items of group %q: %w", is.parent.groupName, err)}
+	}
+	var errs []error
+	items := is.items[:0]
+	for i, item := range is.items {
+		if itemErrs[i] < 0 {
+			errs = append(errs, fmt.Errorf("re-add item %q: %w", item.tag, is.getError(itemErrs[i])))
+			continue
+		}
+		item.Lock()
+		item.itemMgtProvider = is.itemMgtProvider
+		item.groupProvider = is.parent.groupProvider
+		item.provider = is.provider
+		item.serverHandle = results[i].Server
+		item.accessRights = results[i].AccessRights
+		item.nativeDataType = com.VT(results[i].NativeType)
+		item.Unlock()
+		items = append(items, item)
+	}
+	is.items = items
+	is.reindex()
+	return errs
+}
@@ -0,0 +1,106 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wends155/opcda/com"
+	"golang.org/x/sys/windows"
+)
+
+func TestOPCServer_Reconnect_Mocked(t *testing.T) {
+	oldReleased := false
+	oldProvider := &mockServerProvider{ReleaseFn: func() { oldReleased = true }}
+	server := newOPCServerWithProvider(oldProvider, "mock", "localhost")
+	shutdown := make(chan ShutdownEvent, 1)
+	assert.NoError(t, server.RegisterShutdown(shutdown))
+
+	group := &OPCGroup{
+		parent:            server.groups,
+		provider:          oldProvider,
+		groupProvider:     &mockGroupProvider{},
+		groupName:         "g1",
+		clientGroupHandle: 5,
+		serverGroupHandle: 1,
+		requested:         groupState{active: true, updateRate: 500, deadband: 2, deadbandSet: true, localeID: 1033},
+	}
+	group.items = NewOPCItems(group, &mockItemMgtProvider{}, oldProvider)
+	itemA := &OPCItem{parent: group.items, tag: "a", accessPath: "p", clientHandle: 11, serverHandle: 1, isActive: true, requestedDataType: com.VT_R8}
+	itemB := &OPCItem{parent: group.items, tag: "b", clientHandle: 12, serverHandle: 2}
+	group.items.items = []*OPCItem{itemA, itemB}
+	server.groups.groups = []*OPCGroup{group}
+
+	newItemMgt := &mockItemMgtProvider{
+		AddItemsFn: func(items []com.TagOPCITEMDEF) ([]com.TagOPCITEMRESULTStruct, []int32, error) {
+			assert.Len(t, items, 2)
+			assert.Equal(t, "a", windows.UTF16PtrToString(items[0].SzItemID))
+			assert.Equal(t, "p", windows.UTF16PtrToString(items[0].SzAccessPath))
+			assert.Equal(t, uint32(11), items[0].HClient)
+			assert.Equal(t, uint16(com.VT_R8), items[0].VtRequested)
+			assert.Equal(t, int32(1), items[0].BActive)
+			return []com.TagOPCITEMRESULTStruct{{Server: 100, NativeType: uint16(com.VT_R8)}, {}},
+				[]int32{0, int32(OPCUnknownItemID)}, nil
+		},
+	}
+	newGroupProvider := &mockGroupProvider{}
+	advised := 0
+	newProvider := &mockServerProvider{
+		AddGroupFn: func(name string, active bool, updateRate uint32, clientGroup uint32, timeBias *int32, deadband *float32, localeID uint32, iid *windows.GUID) (uint32, uint32, *com.IUnknown, error) {
+			assert.Equal(t, "g1", name)
+			assert.True(t, active)
+			assert.Equal(t, uint32(500), updateRate)
+			assert.Equal(t, uint32(5), clientGroup)
+			assert.Equal(t, float32(2), *deadband)
+			assert.Equal(t, uint32(1033), localeID)
+			return 42, 1000, nil, nil
+		},
+		AdviseShutdownFn: func(sink *com.IUnknown) (uint32, error) {
+			advised++
+			return 7, nil
+		},
+	}
+	server.factories.connect = func(progID, node string, authInfo *com.COAUTHINFO) (*OPCServer, error) {
+		assert.Equal(t, "mock", progID)
+		return &OPCServer{provider: newProvider}, nil
+	}
+	server.factories.newGroup = func(gs *OPCGroups, iUnknown *com.IUnknown, clientGroupHandle uint32, serverGroupHandle uint32, groupName string, revisedUpdateRate uint32) (*OPCGroup, error) {
+		bound := &OPCGroup{groupProvider: newGroupProvider}
+		bound.items = NewOPCItems(bound, newItemMgt, gs.provider)
+		return bound, nil
+	}
+
+	reconnected := 0
+	server.OnReconnect(func() {
+		reconnected++
+		// the hook runs after the server locks are released
+		assert.Equal(t, 1, server.groups.GetCount())
+	})
+	server.OnReconnect(nil)
+
+	err := server.Reconnect()
+	assert.Equal(t, 1, reconnected)
+	assert.ErrorContains(t, err, `re-add item "b"`)
+	assert.True(t, oldReleased)
+	assert.Same(t, group, server.groups.groups[0])
+	assert.Equal(t, uint32(42), group.GetServerHandle())
+	assert.Equal(t, uint32(1000), group.revisedUpdateRate)
+	assert.Equal(t, []*OPCItem{itemA}, group.items.items)
+	assert.Equal(t, uint32(100), itemA.GetServerHandle())
+	assert.Equal(t, newItemMgt, itemA.itemMgtProvider)
+	assert.Equal(t, 1, advised)
+	assert.Equal(t, uint32(7), server.cookie)
+}
+
+func TestOPCServer_Reconnect_ConnectFails_Mocked(t *testing.T) {
+	oldProvider := &mockServerProvider{ReleaseFn: func() { t.Fatal("old connection must be kept when reconnect fails") }}
+	server := newOPCServerWithProvider(oldProvider, "mock", "localhost")
+	server.factories.connect = func(progID, node string, authInfo *com.COAUTHINFO) (*OPCServer, error) {
+		return nil, errors.New("server unavailable")
+	}
+	server.OnReconnect(func() { t.Error("OnReconnect must not fire when reconnect fails") })
+	assert.EqualError(t, server.Reconnect(), "server unavailable")
+	assert.Equal(t, oldProvider, server.provider)
+}
@@ -0,0 +1,177 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+
+	"github.com/wends155/opcda/com"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// registryView reads the class registrations of one registry view of a node.
+// It abstracts the registry so the fallback lookups can be tested without one.
+type registryView interface {
+	// SubKeyNames returns the names of the registered classes and ProgIDs.
+	SubKeyNames() ([]string, error)
+	// ProgIDCLSID returns the CLSID registered for progID. With requireOPC it fails unless the ProgID
+	// also has the OPC subkey that marks OPC servers.
+	ProgIDCLSID(progID string, requireOPC bool) (string, error)
+	// Close releases the registry keys of the view.
+	Close()
+}
+
+// openRegistryViews opens the class registry views of a node; tests replace it with a mock.
+var openRegistryViews = openClassesViews
+
+// classesViewPaths are the class registrations scanned by the registry fallback, in lookup order: the merged
+// HKEY_CLASSES_ROOT view, the machine-wide classes and the classes of 32-bit servers on 64-bit Windows.
+var classesViewPaths = []struct {
+	root registry.Key
+	path string
+}{
+	{registry.CLASSES_ROOT, ""},
+	{registry.LOCAL_MACHINE, `SOFTWARE\Classes`},
+	{registry.LOCAL_MACHINE, `SOFTWARE\WOW6432Node\Classes`},
+}
+
+// comRegistryView is the concrete implementation of registryView using the Windows registry.
+type comRegistryView struct {
+	keys []registry.Key // keys holds the opened keys, the classes key last.
+}
+
+// openClassesViews opens the views of classesViewPaths on the node. Views that do not exist, such as the
+// WOW6432Node classes on 32-bit Windows, are skipped; an error is returned only if none can be opened.
+func openClassesViews(node string) ([]registryView, error) {
+	var views []registryView
+	var errs []error
+	for _, p := range classesViewPaths {
+		view, err := openClassesView(node, p.root, p.path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		views = append(views, view)
+	}
+	if len(views) == 0 {
+		return nil, errors.Join(errs...)
+	}
+	return views, nil
+}
+
+// openClassesView opens one classes view of the node.
+func openClassesView(node string, root registry.Key, path string) (*comRegistryView, error) {
+	view := &comRegistryView{}
+	if !com.IsLocal(node) {
+		remote, err := registry.OpenRemoteKey(node, root)
+		if err != nil {
+			return nil, err
+		}
+		view.keys = append(view.keys, remote)
+		root = remote
+	}
+	if path != "" {
+		key, err := registry.OpenKey(root, path, registry.READ)
+		if err != nil {
+			view.Close()
+			return nil, err
+		}
+		view.keys = append(view.keys, key)
+		root = key
+	}
+	if len(view.keys) == 0 {
+		// the predefined local root is used directly and never closed
+		view.keys = append(view.keys, root)
+	}
+	return view, nil
+}
+
+// classes returns the key holding the class registrations of the view.
+func (v *comRegistryView) classes() registry.Key {
+	return v.keys[len(v.keys)-1]
+}
+
+// SubKeyNames returns the names of the registered classes and ProgIDs.
+func (v *comRegistryView) SubKeyNames() ([]string, error) {
+	return v.classes().ReadSubKeyNames(-1)
+}
+
+// ProgIDCLSID returns the CLSID registered for progID, optionally requiring the OPC subkey.
+func (v *comRegistryView) ProgIDCLSID(progID string, requireOPC bool) (string, error) {
+	hProgIDKey, err := registry.OpenKey(v.classes(), progID, registry.READ)
+	if err != nil {
+		return "", err
+	}
+	defer hProgIDKey.Close()
+	if requireOPC {
+		hOPCKey, err := registry.OpenKey(hProgIDKey, "OPC", registry.READ)
+		if err != nil {
+			return "", err
+		}
+		hOPCKey.Close()
+	}
+	clsidStr, _, err := getClsidFromProgIDKey(hProgIDKey)
+	return clsidStr, err
+}
+
+// Close releases the registry keys of the view. Predefined keys are left open.
+func (v *comRegistryView) Close() {
+	for _, key := range v.keys {
+		if key != registry.CLASSES_ROOT && key != registry.LOCAL_MACHINE {
+			key.Close()
+		}
+	}
+}
+
+// closeRegistryViews closes every view.
+func closeRegistryViews(views []registryView) {
+	for _, view := range views {
+		view.Close()
+	}
+}
+
+// serversFromViews lists the OPC servers registered in any of the views, keeping the first registration
+// of each CLSID.
+func serversFromViews(views []registryView) []*ServerInfo {
+	var result []*ServerInfo
+	for _, view := range views {
+		names, _ := view.SubKeyNames()
+		for _, progID := range names {
+			clsidStr, err := view.ProgIDCLSID(progID, true)
+			if err != nil {
+				continue
+			}
+			clsid, err := windows.GUIDFromString(clsidStr)
+			if err != nil {
+				continue
+			}
+			result = append(result, &ServerInfo{
+				ProgID:       progID,
+				ClsStr:       clsidStr,
+				VerIndProgID: progID,
+				ClsID:        &clsid,
+			})
+		}
+	}
+	return dedupeServers(result)
+}
+
+// clsIDFromViews returns the CLSID of progID from the first view that registers it.
+func clsIDFromViews(views []registryView, progID string) (*windows.GUID, error) {
+	var errs []error
+	for _, view := range views {
+		clsidStr, err := view.ProgIDCLSID(progID, false)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		clsid, err := windows.GUIDFromString(clsidStr)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		return &clsid, nil
+	}
+	return nil, errors.Join(errs...)
+}
@@ -0,0 +1,75 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testServerCLSID = "{F8582CF2-88FB-11D0-B850-00C0F0104305}"
+	testServer2     = "{6E6170F0-FF2D-11D2-8087-00105AA8F840}"
+)
+
+// swapRegistryViews replaces the registry views opened by the registry fallback for a test.
+func swapRegistryViews(t *testing.T, views ...registryView) {
+	old := openRegistryViews
+	t.Cleanup(func() { openRegistryViews = old })
+	openRegistryViews = func(string) ([]registryView, error) { return views, nil }
+}
+
+func TestGetServersFromReg_MergesViews_Mocked(t *testing.T) {
+	classesRoot := &mockRegistryView{
+		classes: map[string]string{"Vendor.Server.1": testServerCLSID, "Other.Class": testServer2},
+		opc:     map[string]bool{"Vendor.Server.1": true},
+	}
+	machine := &mockRegistryView{
+		classes: map[string]string{"Vendor.Server.1": "{f8582cf2-88fb-11d0-b850-00c0f0104305}"},
+		opc:     map[string]bool{"Vendor.Server.1": true},
+	}
+	wow64 := &mockRegistryView{
+		classes: map[string]string{"Legacy.Server.1": testServer2, "Broken.Server": "not-a-guid"},
+		opc:     map[string]bool{"Legacy.Server.1": true, "Broken.Server": true},
+	}
+	swapRegistryViews(t, classesRoot, machine, wow64)
+
+	servers, err := getServersFromReg("localhost")
+	assert.NoError(t, err)
+	if assert.Len(t, servers, 2) {
+		assert.Equal(t, "Vendor.Server.1", servers[0].ProgID)
+		assert.Equal(t, testServerCLSID, servers[0].ClsStr)
+		assert.Equal(t, "Legacy.Server.1", servers[1].ProgID)
+		assert.Equal(t, testServer2, servers[1].ClsID.String())
+	}
+	for _, view := range []*mockRegistryView{classesRoot, machine, wow64} {
+		assert.Equal(t, 1, view.closed)
+	}
+}
+
+func TestGetClsIDFromReg_FallsBackToWOW64_Mocked(t *testing.T) {
+	empty := &mockRegistryView{}
+	wow64 := &mockRegistryView{classes: map[string]string{"Legacy.Server.1": testServer2}}
+	swapRegistryViews(t, empty, wow64)
+
+	clsid, err := getClsIDFromReg("Legacy.Server.1", "localhost")
+	assert.NoError(t, err)
+	assert.Equal(t, testServer2, clsid.String())
+
+	_, err = getClsIDFromReg("Missing.Server", "localhost")
+	assert.Error(t, err)
+	assert.Equal(t, 2, wow64.closed)
+}
+
+func TestGetServersFromReg_OpenError_Mocked(t *testing.T) {
+	old := openRegistryViews
+	t.Cleanup(func() { openRegistryViews = old })
+	openRegistryViews = func(string) ([]registryView, error) { return nil, errors.New("access denied") }
+
+	_, err := getServersFromReg("remote")
+	assert.Error(t, err)
+	_, err = getClsIDFromReg("Vendor.Server.1", "remote")
+	assert.Error(t, err)
+}
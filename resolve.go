@@ -0,0 +1,134 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/wends155/opcda/com"
+	"golang.org/x/sys/windows"
+)
+
+// Activation failures that mean the CLSID is no longer registered, typically after a server upgrade.
+const (
+	regdbEClassNotReg       = com.HRESULT(0x80040154) // REGDB_E_CLASSNOTREG
+	classEClassNotAvailable = com.HRESULT(0x80040111) // CLASS_E_CLASSNOTAVAILABLE
+	coEClassString          = com.HRESULT(0x800401F3) // CO_E_CLASSSTRING
+	coEAppNotFound          = com.HRESULT(0x800401F5) // CO_E_APPNOTFOUND
+)
+
+// serverKey identifies a cached ProgID resolution.
+type serverKey struct {
+	node   string
+	progID string
+}
+
+// newServerKey returns the cache key of a ProgID on a node. Node names and ProgIDs are case-insensitive,
+// so both are lowercased.
+func newServerKey(node, progID string) serverKey {
+	return serverKey{node: strings.ToLower(node), progID: strings.ToLower(progID)}
+}
+
+// serverCache caches the CLSIDs resolved from ProgIDs for the lifetime of the process.
+var serverCache = struct {
+	sync.Mutex
+	servers map[serverKey]*ServerInfo
+}{servers: make(map[serverKey]*ServerInfo)}
+
+// clsIDLookup looks up the CLSID of a ProgID on a node. Connections use getClsID; tests pass a mock.
+type clsIDLookup func(progID, node string, location com.CLSCTX, authInfo *com.COAUTHINFO) (*windows.GUID, error)
+
+// ResolveServer returns the class ID of a server given either its ProgID or its CLSID in
+// "{xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx}" form. ProgID lookups are cached per node and ProgID for the
+// lifetime of the process; Connect drops a cached entry and resolves the ProgID again when activation
+// reports that the cached class is not registered.
+func ResolveServer(node, progIDOrCLSID string) (*ServerInfo, error) {
+	return resolveServerWith(node, progIDOrCLSID, getClsID)
+}
+
+// resolveServerWith is ResolveServer looking up ProgIDs missing from the cache with lookup.
+func resolveServerWith(node, progIDOrCLSID string, lookup clsIDLookup) (*ServerInfo, error) {
+	location := com.CLSCTX_LOCAL_SERVER
+	if !com.IsLocal(node) {
+		location = com.CLSCTX_REMOTE_SERVER
+	}
+	info, _, err := resolveServer(progIDOrCLSID, node, location, nil, lookup)
+	if err != nil {
+		return nil, err
+	}
+	clsid := *info.ClsID
+	return &ServerInfo{ProgID: info.ProgID, ClsStr: info.ClsStr, VerIndProgID: info.VerIndProgID, ClsID: &clsid}, nil
+}
+
+// resolveServer resolves a ProgID or CLSID string, looking up ProgIDs missing from the cache with lookup,
+// and reports whether the result came from the cache.
+func resolveServer(progIDOrCLSID, node string, location com.CLSCTX, authInfo *com.COAUTHINFO, lookup clsIDLookup) (*ServerInfo, bool, error) {
+	if strings.HasPrefix(progIDOrCLSID, "{") {
+		clsid, err := windows.GUIDFromString(progIDOrCLSID)
+		if err != nil {
+			return nil, false, NewOPCWrapperError("parse clsid", err)
+		}
+		return &ServerInfo{ClsStr: clsid.String(), ClsID: &clsid}, false, nil
+	}
+	key := newServerKey(node, progIDOrCLSID)
+	serverCache.Lock()
+	info, ok := serverCache.servers[key]
+	serverCache.Unlock()
+	if ok {
+		return info, true, nil
+	}
+	clsid, err := lookup(progIDOrCLSID, node, location, authInfo)
+	if err != nil {
+		return nil, false, NewOPCWrapperError("get clsid", err)
+	}
+	info = &ServerInfo{ProgID: progIDOrCLSID, ClsStr: clsid.String(), ClsID: clsid}
+	serverCache.Lock()
+	serverCache.servers[key] = info
+	serverCache.Unlock()
+	return info, false, nil
+}
+
+// invalidateServer drops the cached resolution of a ProgID on a node.
+func invalidateServer(progID, node string) {
+	serverCache.Lock()
+	defer serverCache.Unlock()
+	delete(serverCache.servers, newServerKey(node, progID))
+}
+
+// isClassNotRegistered reports whether err means the activated CLSID is not, or no longer, registered.
+func isClassNotRegistered(err error) bool {
+	code, ok := errorCode(err)
+	if !ok {
+		return false
+	}
+	switch code {
+	case regdbEClassNotReg, classEClassNotAvailable, coEClassString, coEAppNotFound:
+		return true
+	}
+	return false
+}
+
+// withResolvedServer resolves the server with lookup and calls activate with its CLSID. If activation fails
+// because a cached CLSID is no longer registered, the cache entry is dropped, the ProgID is resolved again
+// and activate is retried once with the fresh CLSID.
+func withResolvedServer(progIDOrCLSID, node string, location com.CLSCTX, authInfo *com.COAUTHINFO, lookup clsIDLookup, activate func(clsid *windows.GUID) error) error {
+	info, cached, err := resolveServer(progIDOrCLSID, node, location, authInfo, lookup)
+	if err != nil {
+		return err
+	}
+	err = activate(info.ClsID)
+	if err == nil || !isClassNotRegistered(err) {
+		return err
+	}
+	invalidateServer(progIDOrCLSID, node)
+	if !cached {
+		return err
+	}
+	fresh, _, resolveErr := resolveServer(progIDOrCLSID, node, location, authInfo, lookup)
+	if resolveErr != nil {
+		return errors.Join(err, resolveErr)
+	}
+	return activate(fresh.ClsID)
+}
@@ -0,0 +1,129 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wends155/opcda/com"
+	"golang.org/x/sys/windows"
+)
+
+// resetServerCache clears the resolution cache before and after a test.
+func resetServerCache(t *testing.T) {
+	reset := func() {
+		serverCache.Lock()
+		serverCache.servers = make(map[serverKey]*ServerInfo)
+		serverCache.Unlock()
+	}
+	t.Cleanup(reset)
+	reset()
+}
+
+func TestResolveServer_CachesProgID(t *testing.T) {
+	lookups := 0
+	clsid := IID_CATID_OPCDAServer20
+	resetServerCache(t)
+	lookup := func(progID, node string, location com.CLSCTX, authInfo *com.COAUTHINFO) (*windows.GUID, error) {
+		lookups++
+		assert.Equal(t, "Vendor.Server.1", progID)
+		return &clsid, nil
+	}
+
+	info, err := resolveServerWith("localhost", "Vendor.Server.1", lookup)
+	assert.NoError(t, err)
+	assert.Equal(t, clsid.String(), info.ClsStr)
+	assert.Equal(t, "Vendor.Server.1", info.ProgID)
+	info.ClsID.Data1 = 0
+	info, err = resolveServerWith("LOCALHOST", "Vendor.Server.1", lookup)
+	assert.NoError(t, err)
+	assert.Equal(t, clsid, *info.ClsID)
+	assert.Equal(t, 1, lookups)
+	_, err = resolveServerWith("localhost", "vendor.server.1", lookup)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, lookups, "ProgIDs are case-insensitive")
+
+	info, err = resolveServerWith("localhost", IID_CATID_OPCDAServer30.String(), lookup)
+	assert.NoError(t, err)
+	assert.Equal(t, IID_CATID_OPCDAServer30, *info.ClsID)
+	assert.Equal(t, 1, lookups)
+
+	_, err = resolveServerWith("localhost", "{not-a-guid}", lookup)
+	assert.Error(t, err)
+}
+
+func TestWithResolvedServer_RetriesStaleCLSID(t *testing.T) {
+	stale := IID_CATID_OPCDAServer10
+	current := IID_CATID_OPCDAServer30
+	resolved := &stale
+	lookups := 0
+	resetServerCache(t)
+	lookup := func(progID, node string, location com.CLSCTX, authInfo *com.COAUTHINFO) (*windows.GUID, error) {
+		lookups++
+		return resolved, nil
+	}
+	_, err := resolveServerWith("localhost", "Vendor.Server.1", lookup)
+	assert.NoError(t, err)
+
+	// the server was upgraded and registered under a new CLSID
+	resolved = &current
+	var activated []windows.GUID
+	err = withResolvedServer("Vendor.Server.1", "localhost", com.CLSCTX_LOCAL_SERVER, nil, lookup, func(clsid *windows.GUID) error {
+		activated = append(activated, *clsid)
+		if *clsid == stale {
+			return NewOPCWrapperError("make com object IOPCServer", regdbEClassNotReg)
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []windows.GUID{stale, current}, activated)
+	assert.Equal(t, 2, lookups)
+
+	info, err := resolveServerWith("localhost", "Vendor.Server.1", lookup)
+	assert.NoError(t, err)
+	assert.Equal(t, current, *info.ClsID)
+	assert.Equal(t, 2, lookups)
+}
+
+func TestWithResolvedServer_NoRetry(t *testing.T) {
+	clsid := IID_CATID_OPCDAServer20
+	lookups := 0
+	resetServerCache(t)
+	lookup := func(progID, node string, location com.CLSCTX, authInfo *com.COAUTHINFO) (*windows.GUID, error) {
+		lookups++
+		return &clsid, nil
+	}
+
+	// a fresh resolution is not retried, but is not cached either
+	calls := 0
+	err := withResolvedServer("Vendor.Server.1", "localhost", com.CLSCTX_LOCAL_SERVER, nil, lookup, func(*windows.GUID) error {
+		calls++
+		return regdbEClassNotReg
+	})
+	assert.ErrorIs(t, err, regdbEClassNotReg)
+	assert.Equal(t, 1, calls)
+	_, err = resolveServerWith("localhost", "Vendor.Server.1", lookup)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, lookups)
+
+	// other activation failures keep the cached entry
+	accessDenied := errors.New("access denied")
+	err = withResolvedServer("Vendor.Server.1", "localhost", com.CLSCTX_LOCAL_SERVER, nil, lookup, func(*windows.GUID) error {
+		return accessDenied
+	})
+	assert.ErrorIs(t, err, accessDenied)
+	_, err = resolveServerWith("localhost", "Vendor.Server.1", lookup)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, lookups)
+
+	lookup = func(progID, node string, location com.CLSCTX, authInfo *com.COAUTHINFO) (*windows.GUID, error) {
+		return nil, errors.New("not found")
+	}
+	err = withResolvedServer("Missing.Server", "localhost", com.CLSCTX_LOCAL_SERVER, nil, lookup, func(*windows.GUID) error {
+		t.Fatal("activate called without a CLSID")
+		return nil
+	})
+	assert.ErrorContains(t, err, "get clsid")
+}
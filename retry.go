@@ -0,0 +1,94 @@
+//go:build windows
+
+package opcda
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/wends155/opcda/com"
+)
+
+// Failures that usually clear up on their own: the connection to the server dropped, the server is
+// unreachable for the moment, or it is too busy to take the call.
+const (
+	rpcEDisconnected         = com.HRESULT(0x80010108) // RPC_E_DISCONNECTED
+	rpcEServerDied           = com.HRESULT(0x80010007) // RPC_E_SERVER_DIED
+	rpcEServerDiedDNE        = com.HRESULT(0x80010012) // RPC_E_SERVER_DIED_DNE
+	rpcECallRejected         = com.HRESULT(0x80010001) // RPC_E_CALL_REJECTED
+	rpcEServerCallRetryLater = com.HRESULT(0x8001010A) // RPC_E_SERVERCALL_RETRYLATER
+	rpcSServerUnavailable    = com.HRESULT(0x800706BA) // RPC_S_SERVER_UNAVAILABLE
+	rpcSServerTooBusy        = com.HRESULT(0x800706BB) // RPC_S_SERVER_TOO_BUSY
+	rpcSCallFailed           = com.HRESULT(0x800706BE) // RPC_S_CALL_FAILED
+	rpcSCallFailedDNE        = com.HRESULT(0x800706BF) // RPC_S_CALL_FAILED_DNE
+	coEServerExecFailure     = com.HRESULT(0x80080005) // CO_E_SERVER_EXEC_FAILURE
+	win32ServerUnavailable   = com.HRESULT(1722)       // RPC_S_SERVER_UNAVAILABLE from Win32 APIs such as the remote registry
+)
+
+// IsTransient reports whether err is a failure that is likely to clear up if the call is repeated:
+// RPC_E_DISCONNECTED, RPC_E_SERVER_DIED, RPC_S_SERVER_UNAVAILABLE, RPC_S_CALL_FAILED and the server-busy
+// codes RPC_E_CALL_REJECTED, RPC_E_SERVERCALL_RETRYLATER, RPC_S_SERVER_TOO_BUSY and CO_E_SERVER_EXEC_FAILURE.
+// Wrapped errors and OPCError codes are inspected too. It is the default classification of RetryPolicy.
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	code, ok := errorCode(err)
+	if !ok {
+		return false
+	}
+	switch code {
+	case rpcEDisconnected, rpcEServerDied, rpcEServerDiedDNE, rpcECallRejected, rpcEServerCallRetryLater,
+		rpcSServerUnavailable, rpcSServerTooBusy, rpcSCallFailed, rpcSCallFailedDNE, coEServerExecFailure,
+		win32ServerUnavailable:
+		return true
+	}
+	return false
+}
+
+// RetryPolicy repeats a failed call while its error is classified as retryable.
+//
+// Example:
+//
+//	policy := opcda.RetryPolicy{MaxAttempts: 3, Delay: 500 * time.Millisecond}
+//	err := policy.Do(ctx, func() error {
+//		_, _, err := group.SyncRead(opcda.OPC_DS_DEVICE, handles)
+//		return err
+//	})
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first; values below 1 mean a single attempt.
+	MaxAttempts int
+	// Delay is the wait before the first retry; it doubles before every further retry.
+	Delay time.Duration
+	// ShouldRetry classifies errors as retryable; nil uses IsTransient.
+	ShouldRetry func(error) bool
+}
+
+// shouldRetry applies the classification of the policy.
+func (p RetryPolicy) shouldRetry(err error) bool {
+	if p.ShouldRetry != nil {
+		return p.ShouldRetry(err)
+	}
+	return IsTransient(err)
+}
+
+// Do calls fn until it succeeds, fails with an error the policy does not retry, or MaxAttempts is reached,
+// and returns the last error. It stops waiting and returns ctx.Err() joined with the last error when ctx is done.
+func (p RetryPolicy) Do(ctx context.Context, fn func() error) error {
+	delay := p.Delay
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= p.MaxAttempts || !p.shouldRetry(err) {
+			return err
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(ctx.Err(), err)
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
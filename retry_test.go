@@ -0,0 +1,94 @@
+//go:build windows
+
+package opcda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wends155/opcda/com"
+)
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"disconnected", syscall.Errno(0x80010108), true},
+		{"server unavailable", syscall.Errno(0x800706BA), true},
+		{"call failed", syscall.Errno(0x800706BE), true},
+		{"retry later", syscall.Errno(0x8001010A), true},
+		{"wrapped", NewOPCWrapperError("sync read", syscall.Errno(0x80010001)), true},
+		{"fmt wrapped", fmt.Errorf("read: %w", syscall.Errno(0x800706BB)), true},
+		{"hresult", fmt.Errorf("read: %w", com.HRESULT(0x80010108)), true},
+		{"opc error", &OPCError{ErrorCode: int32(-2147417848)}, true},
+		{"access denied", syscall.Errno(0x80070005), false},
+		{"unknown item", &OPCError{ErrorCode: int32(OPCUnknownItemID)}, false},
+		{"plain", errors.New("boom"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsTransient(tt.err))
+		})
+	}
+}
+
+func TestRetryPolicy_Do(t *testing.T) {
+	transient := syscall.Errno(0x80010108)
+
+	calls := 0
+	err := RetryPolicy{MaxAttempts: 3}.Do(context.Background(), func() error {
+		calls++
+		if calls < 3 {
+			return transient
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	err = RetryPolicy{MaxAttempts: 3}.Do(context.Background(), func() error {
+		calls++
+		return errors.New("permanent")
+	})
+	assert.EqualError(t, err, "permanent")
+	assert.Equal(t, 1, calls)
+
+	calls = 0
+	err = RetryPolicy{MaxAttempts: 2, ShouldRetry: func(error) bool { return true }}.Do(context.Background(), func() error {
+		calls++
+		return errors.New("custom")
+	})
+	assert.EqualError(t, err, "custom")
+	assert.Equal(t, 2, calls)
+
+	calls = 0
+	err = RetryPolicy{}.Do(context.Background(), func() error {
+		calls++
+		return transient
+	})
+	assert.ErrorIs(t, err, transient)
+	assert.Equal(t, 1, calls)
+}
+
+func TestRetryPolicy_Do_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	transient := syscall.Errno(0x800706BA)
+	calls := 0
+	err := RetryPolicy{MaxAttempts: 5, Delay: time.Hour}.Do(ctx, func() error {
+		calls++
+		cancel()
+		return transient
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorIs(t, err, transient)
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,88 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/wends155/opcda/com"
+)
+
+// NamespaceSeparator returns the string the server puts between the components of a hierarchical item ID,
+// such as "." or "/", so item IDs can be built from branch and leaf names. Servers implementing the OPC DA
+// 3.0 IOPCBrowse interface are browsed statelessly and the separator is read off the item IDs of a branch
+// and its children; other servers are browsed with OPCBrowser.DetectSeparator. The result is cached for
+// the connection, so later calls do not browse. ErrUnknownSeparator is returned when the separator cannot
+// be inferred, for example because the address space is flat.
+//
+// Example:
+//
+//	sep, err := server.NamespaceSeparator()
+//	if err == nil {
+//		itemID := strings.Join([]string{"Channel1", "Device1", "Tag1"}, sep)
+//	}
+func (s *OPCServer) NamespaceSeparator() (string, error) {
+	if s == nil || s.provider == nil {
+		return "", errors.New("uninitialized server connection")
+	}
+	if sep := s.separator.Load(); sep != nil {
+		return *sep, nil
+	}
+	if browse, err := s.browse3(); err == nil {
+		budget := separatorSearchLimit
+		sep := browseSeparator(browse, "", &budget)
+		browse.Release()
+		if sep != "" {
+			s.separator.Store(&sep)
+			return sep, nil
+		}
+	}
+	browser, err := s.factories.newBrowser(s)
+	if err != nil {
+		return "", err
+	}
+	defer browser.Release()
+	return browser.DetectSeparator()
+}
+
+// browseSeparator searches the branch itemID and the branches below it for a child whose item ID reveals
+// the separator, visiting at most budget branches. It returns "" when none does.
+func browseSeparator(browse browse3Provider, itemID string, budget *int) string {
+	elements, err := browse.Browse(itemID, com.BrowseOptions{Filter: com.OPC_BROWSE_FILTER_ALL})
+	if err != nil {
+		return ""
+	}
+	if itemID != "" {
+		for _, e := range elements {
+			if sep := separatorFromItemIDs(itemID, e.ItemID, e.Name); sep != "" {
+				return sep
+			}
+		}
+	}
+	for _, e := range elements {
+		if e.Flags&com.OPC_BROWSE_HASCHILDREN == 0 || e.ItemID == "" {
+			continue
+		}
+		if *budget <= 0 {
+			return ""
+		}
+		*budget--
+		if sep := browseSeparator(browse, e.ItemID, budget); sep != "" {
+			return sep
+		}
+	}
+	return ""
+}
+
+// separatorFromItemIDs infers the separator from the item ID of a branch and the item ID and name of one
+// of its children. It returns "" when the item IDs are not built from the names.
+func separatorFromItemIDs(branchID, childID, childName string) string {
+	if childName == "" || !strings.HasPrefix(childID, branchID) || !strings.HasSuffix(childID, childName) {
+		return ""
+	}
+	if len(childID) <= len(branchID)+len(childName) {
+		return ""
+	}
+	return childID[len(branchID) : len(childID)-len(childName)]
+}
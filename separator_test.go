@@ -0,0 +1,68 @@
+//go:build windows
+
+package opcda
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wends155/opcda/com"
+)
+
+func TestOPCServer_NamespaceSeparator_Browse3_Mocked(t *testing.T) {
+	browses := 0
+	server := newOPCServerWithProvider(&mockServerProvider{}, "mock", "localhost")
+	server.factories.queryBrowse3 = func(serverProvider, *com.COAUTHINFO) (browse3Provider, error) {
+		return &mockBrowse3Provider{
+			BrowseFn: func(itemID string, options com.BrowseOptions) ([]com.BrowseElement, error) {
+				browses++
+				switch itemID {
+				case "":
+					return []com.BrowseElement{
+						{Name: "Empty", ItemID: "Empty", Flags: com.OPC_BROWSE_HASCHILDREN},
+						{Name: "Channel1", ItemID: "Channel1", Flags: com.OPC_BROWSE_HASCHILDREN},
+					}, nil
+				case "Channel1":
+					return []com.BrowseElement{{Name: "Tag1", ItemID: "Channel1/Tag1", Flags: com.OPC_BROWSE_ISITEM}}, nil
+				}
+				return nil, nil
+			},
+		}, nil
+	}
+
+	sep, err := server.NamespaceSeparator()
+	assert.NoError(t, err)
+	assert.Equal(t, "/", sep)
+	assert.Equal(t, 3, browses)
+
+	sep, err = server.NamespaceSeparator()
+	assert.NoError(t, err)
+	assert.Equal(t, "/", sep)
+	assert.Equal(t, 3, browses)
+}
+
+func TestOPCServer_NamespaceSeparator_Fallback_Mocked(t *testing.T) {
+	server := newOPCServerWithProvider(&mockServerProvider{}, "mock", "localhost")
+	server.factories.newBrowser = func(parent *OPCServer) (*OPCBrowser, error) {
+		mock := &separatorBrowserProvider{mockBrowserProvider: newMockBrowserProvider(), sep: "::", organization: OPC_NS_HIERARCHIAL}
+		return newOPCBrowserWithProvider(mock, parent), nil
+	}
+	server.factories.queryBrowse3 = func(serverProvider, *com.COAUTHINFO) (browse3Provider, error) {
+		return nil, com.HRESULT(com.E_NOINTERFACE)
+	}
+
+	sep, err := server.NamespaceSeparator()
+	assert.NoError(t, err)
+	assert.Equal(t, "::", sep)
+
+	var nilServer *OPCServer
+	_, err = nilServer.NamespaceSeparator()
+	assert.Error(t, err)
+}
+
+func TestSeparatorFromItemIDs(t *testing.T) {
+	assert.Equal(t, ".", separatorFromItemIDs("A.B", "A.B.C", "C"))
+	assert.Equal(t, "", separatorFromItemIDs("A.B", "X.C", "C"))
+	assert.Equal(t, "", separatorFromItemIDs("A", "AC", "C"))
+	assert.Equal(t, "", separatorFromItemIDs("A", "A.C", ""))
+}
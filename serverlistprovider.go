@@ -0,0 +1,145 @@
+//go:build windows
+
+package opcda
+
+import (
+	"unsafe"
+
+	"github.com/wends155/opcda/com"
+	"golang.org/x/sys/windows"
+)
+
+// serverListProvider defines the internal contract for enumerating the OPC servers registered on a node.
+// It abstracts IOPCServerList2 and IOPCServerList to allow for mocking and testing.
+type serverListProvider interface {
+	// EnumClassesOfCategories returns the class IDs of the servers registered in any of the categories.
+	EnumClassesOfCategories(cids []windows.GUID) ([]windows.GUID, error)
+	// GetClassDetails returns the ProgID, user type and version independent ProgID of a server class.
+	GetClassDetails(classID *windows.GUID) (progID, userType, verIndProgID string, err error)
+	// Release releases the COM resources associated with the provider.
+	Release()
+}
+
+// newServerList2Provider and newServerListProvider connect to the server list of a node, authenticating
+// with authInfo when it is not nil; tests replace them with mocks.
+var (
+	newServerList2Provider = newComServerList2Provider
+	newServerListProvider  = newComServerListProvider
+)
+
+// comServerList2Provider is the concrete implementation of serverListProvider using IOPCServerList2.
+type comServerList2Provider struct {
+	sl *com.IOPCServerList2
+}
+
+// newComServerList2Provider creates the IOPCServerList2 object of the node.
+func newComServerList2Provider(node string, authInfo *com.COAUTHINFO) (serverListProvider, error) {
+	iCatInfo, err := com.MakeCOMObjectExAuth(node, serverListLocation(node), &com.CLSID_OpcServerList, &com.IID_IOPCServerList2, authInfo)
+	if err != nil {
+		return nil, NewOPCWrapperError("make com object IOPCServerListV2", err)
+	}
+	err = setProxyBlanket(iCatInfo, authInfo)
+	if err != nil {
+		iCatInfo.Release()
+		return nil, NewOPCWrapperError("set proxy blanket IOPCServerListV2", err)
+	}
+	return &comServerList2Provider{sl: &com.IOPCServerList2{IUnknown: iCatInfo}}, nil
+}
+
+// EnumClassesOfCategories returns the class IDs of the servers registered in any of the categories.
+func (p *comServerList2Provider) EnumClassesOfCategories(cids []windows.GUID) ([]windows.GUID, error) {
+	iEnum, err := p.sl.EnumClassesOfCategories(cids, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer iEnum.Release()
+	return readClassIDs(iEnum), nil
+}
+
+// GetClassDetails returns the ProgID, user type and version independent ProgID of a server class.
+func (p *comServerList2Provider) GetClassDetails(classID *windows.GUID) (string, string, string, error) {
+	progID, userType, verIndProgID, err := p.sl.GetClassDetails(classID)
+	if err != nil {
+		return "", "", "", err
+	}
+	defer func() {
+		com.CoTaskMemFree(unsafe.Pointer(progID))
+		com.CoTaskMemFree(unsafe.Pointer(userType))
+		com.CoTaskMemFree(unsafe.Pointer(verIndProgID))
+	}()
+	return windows.UTF16PtrToString(progID), windows.UTF16PtrToString(userType), windows.UTF16PtrToString(verIndProgID), nil
+}
+
+// Release releases the COM resources associated with the provider.
+func (p *comServerList2Provider) Release() {
+	p.sl.Release()
+}
+
+// comServerListProvider is the concrete implementation of serverListProvider using IOPCServerList.
+type comServerListProvider struct {
+	sl *com.IOPCServerList
+}
+
+// newComServerListProvider creates the IOPCServerList object of the node.
+func newComServerListProvider(node string, authInfo *com.COAUTHINFO) (serverListProvider, error) {
+	iCatInfo, err := com.MakeCOMObjectExAuth(node, serverListLocation(node), &com.CLSID_OpcServerList, &com.IID_IOPCServerList, authInfo)
+	if err != nil {
+		return nil, NewOPCWrapperError("make com object IOPCServerListV1", err)
+	}
+	err = setProxyBlanket(iCatInfo, authInfo)
+	if err != nil {
+		iCatInfo.Release()
+		return nil, NewOPCWrapperError("set proxy blanket IOPCServerListV1", err)
+	}
+	return &comServerListProvider{sl: &com.IOPCServerList{IUnknown: iCatInfo}}, nil
+}
+
+// EnumClassesOfCategories returns the class IDs of the servers registered in any of the categories.
+func (p *comServerListProvider) EnumClassesOfCategories(cids []windows.GUID) ([]windows.GUID, error) {
+	iEnum, err := p.sl.EnumClassesOfCategories(cids, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer iEnum.Release()
+	return readClassIDs(iEnum), nil
+}
+
+// GetClassDetails returns the ProgID and user type of a server class. IOPCServerList does not report
+// the version independent ProgID, so it is always empty.
+func (p *comServerListProvider) GetClassDetails(classID *windows.GUID) (string, string, string, error) {
+	progID, userType, err := p.sl.GetClassDetails(classID)
+	if err != nil {
+		return "", "", "", err
+	}
+	defer func() {
+		com.CoTaskMemFree(unsafe.Pointer(progID))
+		com.CoTaskMemFree(unsafe.Pointer(userType))
+	}()
+	return windows.UTF16PtrToString(progID), windows.UTF16PtrToString(userType), "", nil
+}
+
+// Release releases the COM resources associated with the provider.
+func (p *comServerListProvider) Release() {
+	p.sl.Release()
+}
+
+// serverListLocation returns the activation context of the server list of a node.
+func serverListLocation(node string) com.CLSCTX {
+	if !com.IsLocal(node) {
+		return com.CLSCTX_REMOTE_SERVER
+	}
+	return com.CLSCTX_LOCAL_SERVER
+}
+
+// readClassIDs drains a class ID enumerator.
+func readClassIDs(iEnum *com.IEnumGUID) []windows.GUID {
+	var classIDs []windows.GUID
+	for {
+		var classID windows.GUID
+		var actual uint32
+		if err := iEnum.Next(1, &classID, &actual); err != nil || actual == 0 {
+			return classIDs
+		}
+		classIDs = append(classIDs, classID)
+	}
+}
@@ -3,6 +3,8 @@
 package opcda
 
 import (
+	"errors"
+	"fmt"
 	"unsafe"
 
 	"github.com/wends155/opcda/com"
@@ -32,19 +34,47 @@ type serverProvider interface {
 	LookupItemIDs(itemID string, propertyIDs []uint32) ([]string, []int32, error)
 	// AddGroup creates a new OPC group with the specified parameters.
 	AddGroup(name string, active bool, updateRate uint32, clientGroup uint32, timeBias *int32, deadband *float32, localeID uint32, iid *windows.GUID) (serverGroup uint32, revisedUpdateRate uint32, ppUnk *com.IUnknown, err error)
+	// GetGroupByName returns an interface of an existing group of this connection by name.
+	GetGroupByName(name string, iid *windows.GUID) (*com.IUnknown, error)
+	// CreateGroupEnumerator returns an interface of an enumerator over the groups in scope.
+	CreateGroupEnumerator(scope uint32, iid *windows.GUID) (*com.IUnknown, error)
 	// RemoveGroup removes the specified group from the server.
 	RemoveGroup(serverGroup uint32, force bool) error
 	// Release releases the COM resources associated with the provider.
 	Release()
 	// QueryInterface queries the server for a specific interface.
 	QueryInterface(iid *windows.GUID, ppv unsafe.Pointer) error
+	// AdviseShutdown connects sink to the IOPCShutdown connection point of the server.
+	AdviseShutdown(sink *com.IUnknown) (cookie uint32, err error)
+	// UnadviseShutdown disconnects the sink identified by cookie and releases the connection point.
+	UnadviseShutdown(cookie uint32) error
+}
+
+// commonInterface defines the IOPCCommon methods used by comServerProvider.
+// It is satisfied by *com.IOPCCommon and allows the provider logic to be tested without COM.
+type commonInterface interface {
+	// GetErrorString retrieves the server text for an error code.
+	GetErrorString(dwError int32) (string, error)
+	// GetLocaleID retrieves the current locale identifier.
+	GetLocaleID() (uint32, error)
+	// SetLocaleID sets the locale identifier.
+	SetLocaleID(dwLcid uint32) error
+	// SetClientName registers the client name with the server.
+	SetClientName(szName string) error
+	// QueryAvailableLocaleIDs returns the locale identifiers supported by the server.
+	QueryAvailableLocaleIDs() ([]uint32, error)
+	// Release releases the underlying COM interface.
+	Release() uint32
 }
 
 // comServerProvider is the concrete implementation of serverProvider using COM.
 type comServerProvider struct {
 	iServer       *com.IOPCServer
-	iCommon       *com.IOPCCommon
+	iCommon       commonInterface
 	iItemProperty *com.IOPCItemProperties
+	authInfo      *com.COAUTHINFO // authInfo keeps the credentials used by the proxies alive.
+	container     *com.IConnectionPointContainer
+	point         *com.IConnectionPoint
 }
 
 // GetStatus retrieves the current status of the OPC server.
@@ -53,8 +83,20 @@ func (p *comServerProvider) GetStatus() (*com.ServerStatus, error) {
 }
 
 // GetErrorString converts an error code to a readable string.
+// Servers often have no text for vendor-specific codes; in that case the message of a
+// well-known OPC code is used, or the code formatted as hex so callers never get an empty string.
 func (p *comServerProvider) GetErrorString(errorCode uint32) (string, error) {
-	return p.iCommon.GetErrorString(errorCode)
+	str, err := p.iCommon.GetErrorString(int32(errorCode))
+	if err != nil {
+		return "", err
+	}
+	if str == "" {
+		if msg, ok := opcErrors[int32(errorCode)]; ok {
+			return msg, nil
+		}
+		return fmt.Sprintf("0x%08X", errorCode), nil
+	}
+	return str, nil
 }
 
 // GetLocaleID retrieves the current locale identifier for the server.
@@ -97,6 +139,16 @@ func (p *comServerProvider) AddGroup(name string, active bool, updateRate uint32
 	return p.iServer.AddGroup(name, active, updateRate, clientGroup, timeBias, deadband, localeID, iid)
 }
 
+// GetGroupByName returns an interface of an existing group of this connection by name.
+func (p *comServerProvider) GetGroupByName(name string, iid *windows.GUID) (*com.IUnknown, error) {
+	return p.iServer.GetGroupByName(name, iid)
+}
+
+// CreateGroupEnumerator returns an interface of an enumerator over the groups in scope.
+func (p *comServerProvider) CreateGroupEnumerator(scope uint32, iid *windows.GUID) (*com.IUnknown, error) {
+	return p.iServer.CreateGroupEnumerator(scope, iid)
+}
+
 // RemoveGroup removes the specified group from the server.
 func (p *comServerProvider) RemoveGroup(serverGroup uint32, force bool) error {
 	return p.iServer.RemoveGroup(serverGroup, force)
@@ -104,6 +156,7 @@ func (p *comServerProvider) RemoveGroup(serverGroup uint32, force bool) error {
 
 // Release releases the COM resources associated with the provider.
 func (p *comServerProvider) Release() {
+	p.releaseShutdownPoint()
 	if p.iItemProperty != nil {
 		p.iItemProperty.Release()
 	}
@@ -119,3 +172,67 @@ func (p *comServerProvider) Release() {
 func (p *comServerProvider) QueryInterface(iid *windows.GUID, ppv unsafe.Pointer) error {
 	return p.iServer.QueryInterface(iid, ppv)
 }
+
+// AdviseShutdown connects sink to the IOPCShutdown connection point of the server.
+func (p *comServerProvider) AdviseShutdown(sink *com.IUnknown) (cookie uint32, err error) {
+	if p.point != nil {
+		return 0, errors.New("shutdown connection point already advised")
+	}
+	var iUnknownContainer *com.IUnknown
+	err = p.QueryInterface(&com.IID_IConnectionPointContainer, unsafe.Pointer(&iUnknownContainer))
+	if err != nil {
+		return 0, NewOPCWrapperError("query interface IConnectionPointContainer", err)
+	}
+	defer func() {
+		if err != nil {
+			iUnknownContainer.Release()
+		}
+	}()
+	err = setProxyBlanket(iUnknownContainer, p.authInfo)
+	if err != nil {
+		return 0, NewOPCWrapperError("set proxy blanket IConnectionPointContainer", err)
+	}
+	container := &com.IConnectionPointContainer{IUnknown: iUnknownContainer}
+	point, err := container.FindConnectionPoint(&IID_IOPCShutdown)
+	if err != nil {
+		return 0, NewOPCWrapperError("container find connect point", err)
+	}
+	defer func() {
+		if err != nil {
+			point.Release()
+		}
+	}()
+	err = setProxyBlanket(point.IUnknown, p.authInfo)
+	if err != nil {
+		return 0, NewOPCWrapperError("set proxy blanket IConnectionPoint", err)
+	}
+	cookie, err = point.Advise(sink)
+	if err != nil {
+		return 0, NewOPCWrapperError("point advise", err)
+	}
+	p.container = container
+	p.point = point
+	return cookie, nil
+}
+
+// UnadviseShutdown disconnects the sink identified by cookie and releases the connection point.
+func (p *comServerProvider) UnadviseShutdown(cookie uint32) error {
+	if p.point == nil {
+		return nil
+	}
+	err := p.point.Unadvise(cookie)
+	p.releaseShutdownPoint()
+	return err
+}
+
+// releaseShutdownPoint releases the IOPCShutdown connection point and its container.
+func (p *comServerProvider) releaseShutdownPoint() {
+	if p.point != nil {
+		p.point.Release()
+		p.point = nil
+	}
+	if p.container != nil {
+		p.container.Release()
+		p.container = nil
+	}
+}
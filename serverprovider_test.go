@@ -0,0 +1,41 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComServerProvider_GetErrorString_Mocked(t *testing.T) {
+	messages := map[int32]string{
+		-2147467259: "Unspecified error",
+	}
+	p := &comServerProvider{
+		iCommon: &mockCommon{
+			GetErrorStringFn: func(dwError int32) (string, error) {
+				if dwError == -1 {
+					return "", errors.New("call failed")
+				}
+				return messages[dwError], nil
+			},
+		},
+	}
+
+	msg, err := p.GetErrorString(0x80004005)
+	assert.NoError(t, err)
+	assert.Equal(t, "Unspecified error", msg)
+
+	msg, err = p.GetErrorString(OPCUnknownItemID)
+	assert.NoError(t, err)
+	assert.Equal(t, opcErrors[int32(OPCUnknownItemID)], msg)
+
+	msg, err = p.GetErrorString(0xC0049999)
+	assert.NoError(t, err)
+	assert.Equal(t, "0xC0049999", msg)
+
+	_, err = p.GetErrorString(0xFFFFFFFF)
+	assert.Error(t, err)
+}
@@ -3,7 +3,11 @@
 package opcda
 
 import (
+	"context"
+	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 	"unsafe"
 
 	"github.com/wends155/opcda/com"
@@ -15,7 +19,27 @@ type ShutdownEventReceiver struct {
 	lpVtbl   *ShutdownEventReceiverVtbl
 	ref      int32
 	clsid    *windows.GUID
+	mu       sync.Mutex
 	receiver []chan string
+	events   []chan ShutdownEvent
+	server   *OPCServer
+	queue    chan ShutdownEvent
+	dropped  *uint64
+	stopped  atomic.Bool // stopped is set once the fan-out goroutine has returned.
+	cancel   context.CancelFunc
+}
+
+// shutdownQueueSize is the number of shutdown requests buffered between the COM callback and the subscribers.
+const shutdownQueueSize = 16
+
+// ShutdownEvent describes a shutdown request sent by an OPC server.
+type ShutdownEvent struct {
+	// Reason is the reason given by the server.
+	Reason string
+	// At is the time the shutdown callback was received.
+	At time.Time
+	// Server is the connection that received the shutdown request.
+	Server *OPCServer
 }
 
 type ShutdownEventReceiverVtbl struct {
@@ -33,15 +57,106 @@ func NewShutdownEventReceiver() *ShutdownEventReceiver {
 			pRelease:         syscall.NewCallback(ShutdownRelease),
 			pShutdownRequest: syscall.NewCallback(ShutdownRequest),
 		},
-		ref:   0,
-		clsid: &IID_IOPCShutdown,
+		ref:     0,
+		clsid:   &IID_IOPCShutdown,
+		queue:   make(chan ShutdownEvent, shutdownQueueSize),
+		dropped: new(uint64),
 	}
 }
 
+// start launches the goroutine that fans queued shutdown requests out to the subscribers until stop is
+// called or parent is cancelled.
+func (er *ShutdownEventReceiver) start(parent context.Context) {
+	var ctx context.Context
+	ctx, er.cancel = context.WithCancel(parent)
+	go er.loop(ctx)
+}
+
+// stop ends the fan-out goroutine started by start.
+func (er *ShutdownEventReceiver) stop() {
+	if er.cancel != nil {
+		er.cancel()
+		er.cancel = nil
+	}
+}
+
+// loop delivers queued shutdown requests until ctx is cancelled. Requests still queued then, or
+// arriving later, are counted as dropped.
+func (er *ShutdownEventReceiver) loop(ctx context.Context) {
+	defer er.discard()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-er.queue:
+			if ctx.Err() != nil {
+				er.drop(1)
+				return
+			}
+			er.deliver(event)
+		}
+	}
+}
+
+// discard marks the receiver stopped and counts the queued shutdown requests as dropped.
+func (er *ShutdownEventReceiver) discard() {
+	er.stopped.Store(true)
+	for {
+		select {
+		case <-er.queue:
+			er.drop(1)
+		default:
+			return
+		}
+	}
+}
+
+// drop records shutdown notifications that could not be delivered.
+func (er *ShutdownEventReceiver) drop(n uint64) {
+	atomic.AddUint64(er.dropped, n)
+}
+
 func (er *ShutdownEventReceiver) AddReceiver(ch chan string) {
+	er.mu.Lock()
+	defer er.mu.Unlock()
 	er.receiver = append(er.receiver, ch)
 }
 
+// RemoveReceiver removes a channel added with AddReceiver.
+// It reports whether the channel was found and how many receivers of either kind remain.
+func (er *ShutdownEventReceiver) RemoveReceiver(ch chan string) (bool, int) {
+	er.mu.Lock()
+	defer er.mu.Unlock()
+	for i, c := range er.receiver {
+		if c == ch {
+			er.receiver = append(er.receiver[:i], er.receiver[i+1:]...)
+			return true, len(er.receiver) + len(er.events)
+		}
+	}
+	return false, len(er.receiver) + len(er.events)
+}
+
+// AddEventReceiver adds a channel that receives a ShutdownEvent for each shutdown request.
+func (er *ShutdownEventReceiver) AddEventReceiver(ch chan ShutdownEvent) {
+	er.mu.Lock()
+	defer er.mu.Unlock()
+	er.events = append(er.events, ch)
+}
+
+// RemoveEventReceiver removes a channel added with AddEventReceiver.
+// It reports whether the channel was found and how many receivers of either kind remain.
+func (er *ShutdownEventReceiver) RemoveEventReceiver(ch chan ShutdownEvent) (bool, int) {
+	er.mu.Lock()
+	defer er.mu.Unlock()
+	for i, c := range er.events {
+		if c == ch {
+			er.events = append(er.events[:i], er.events[i+1:]...)
+			return true, len(er.receiver) + len(er.events)
+		}
+	}
+	return false, len(er.receiver) + len(er.events)
+}
+
 func ShutdownQueryInterface(this unsafe.Pointer, iid *windows.GUID, punk *unsafe.Pointer) uintptr {
 	er := (*ShutdownEventReceiver)(this)
 	*punk = nil
@@ -54,15 +169,46 @@ func ShutdownQueryInterface(this unsafe.Pointer, iid *windows.GUID, punk *unsafe
 }
 
 func ShutdownRequest(this *com.IUnknown, pReason *uint16) uintptr {
+	at := time.Now()
 	er := (*ShutdownEventReceiver)(unsafe.Pointer(this))
 	reason := windows.UTF16PtrToString(pReason)
-	for _, ch := range er.receiver {
+	if er.stopped.Load() {
+		er.drop(1)
+		return uintptr(com.S_OK)
+	}
+	// the server waits for this call to return, so never block on slow subscribers here
+	select {
+	case er.queue <- ShutdownEvent{Reason: reason, At: at, Server: er.server}:
+	default:
+		er.drop(1)
+	}
+	return uintptr(com.S_OK)
+}
+
+// deliver sends a shutdown request to every subscriber without blocking, as the group callbacks do.
+// Each subscriber whose channel is full counts as a dropped notification.
+func (er *ShutdownEventReceiver) deliver(event ShutdownEvent) {
+	er.mu.Lock()
+	receivers := make([]chan string, len(er.receiver))
+	copy(receivers, er.receiver)
+	events := make([]chan ShutdownEvent, len(er.events))
+	copy(events, er.events)
+	er.mu.Unlock()
+
+	for _, ch := range receivers {
 		select {
-		case ch <- reason:
+		case ch <- event.Reason:
 		default:
+			er.drop(1)
+		}
+	}
+	for _, ch := range events {
+		select {
+		case ch <- event:
+		default:
+			er.drop(1)
 		}
 	}
-	return uintptr(com.S_OK)
 }
 
 func ShutdownAddRef(this unsafe.Pointer) uintptr {
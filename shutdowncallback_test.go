@@ -0,0 +1,61 @@
+//go:build windows
+
+package opcda
+
+import (
+	"context"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wends155/opcda/com"
+	"golang.org/x/sys/windows"
+)
+
+func TestShutdownRequest_NonBlocking_Mocked(t *testing.T) {
+	server := newOPCServerWithProvider(&mockServerProvider{}, "mock", "localhost")
+	legacy := make(chan string, 1)
+	unread := make(chan ShutdownEvent)
+	assert.NoError(t, server.RegisterServerShutDown(legacy))
+	assert.NoError(t, server.RegisterShutdown(unread))
+
+	reason, _ := windows.UTF16PtrFromString("bye")
+	ShutdownRequest((*com.IUnknown)(unsafe.Pointer(server.event)), reason)
+
+	select {
+	case got := <-legacy:
+		assert.Equal(t, "bye", got)
+	case <-time.After(time.Second):
+		t.Fatal("shutdown reason was not delivered")
+	}
+	assert.Eventually(t, func() bool { return server.DroppedShutdownEvents() == 1 }, time.Second, time.Millisecond)
+	assert.NoError(t, server.Disconnect())
+}
+
+func TestShutdownRequest_QueueFull_Mocked(t *testing.T) {
+	er := NewShutdownEventReceiver()
+	reason, _ := windows.UTF16PtrFromString("bye")
+	for i := 0; i < shutdownQueueSize+1; i++ {
+		assert.Equal(t, uintptr(com.S_OK), ShutdownRequest((*com.IUnknown)(unsafe.Pointer(er)), reason))
+	}
+	assert.Equal(t, uint64(1), *er.dropped)
+	assert.Len(t, er.queue, shutdownQueueSize)
+}
+
+func TestShutdownEventReceiver_StopCountsQueued_Mocked(t *testing.T) {
+	er := NewShutdownEventReceiver()
+	reason, _ := windows.UTF16PtrFromString("bye")
+	for i := 0; i < 3; i++ {
+		ShutdownRequest((*com.IUnknown)(unsafe.Pointer(er)), reason)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	er.loop(ctx)
+	assert.Equal(t, uint64(3), *er.dropped)
+	assert.Empty(t, er.queue)
+
+	ShutdownRequest((*com.IUnknown)(unsafe.Pointer(er)), reason)
+	assert.Equal(t, uint64(4), *er.dropped, "requests after the loop stopped are counted too")
+	assert.Empty(t, er.queue)
+}
@@ -0,0 +1,239 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unsafe"
+
+	"github.com/wends155/opcda/com"
+	"golang.org/x/sys/windows"
+)
+
+// ErrSyncIO2NotSupported is returned by ReadMaxAge and WriteVQT when the group does not implement the OPC DA 3.0
+// IOPCSyncIO2 interface. Such groups can only be read from the cache or the device with SyncRead and
+// written with SyncWrite.
+var ErrSyncIO2NotSupported = errors.New("server does not support IOPCSyncIO2")
+
+// syncIO2Provider defines the internal contract for reads bounded by the age of cached values.
+// It abstracts the optional IOPCSyncIO2 group interface to allow for mocking and testing.
+type syncIO2Provider interface {
+	// ReadMaxAge reads the items with the given maximum ages in milliseconds.
+	ReadMaxAge(serverHandles []uint32, maxAge []uint32) ([]*com.ItemState, []int32, error)
+	// WriteVQT writes values, qualities and timestamps to the items.
+	WriteVQT(serverHandles []uint32, values []com.TagOPCITEMVQT) ([]int32, error)
+	// Release releases the COM resources associated with the provider.
+	Release()
+}
+
+// comSyncIO2Provider is the concrete implementation of syncIO2Provider using COM.
+type comSyncIO2Provider struct {
+	syncIO2 *com.IOPCSyncIO2
+}
+
+// ReadMaxAge reads the items with the given maximum ages in milliseconds.
+func (p *comSyncIO2Provider) ReadMaxAge(serverHandles []uint32, maxAge []uint32) ([]*com.ItemState, []int32, error) {
+	return p.syncIO2.ReadMaxAge(serverHandles, maxAge)
+}
+
+// WriteVQT writes values, qualities and timestamps to the items.
+func (p *comSyncIO2Provider) WriteVQT(serverHandles []uint32, values []com.TagOPCITEMVQT) ([]int32, error) {
+	return p.syncIO2.WriteVQT(serverHandles, values)
+}
+
+// Release releases the COM resources associated with the provider.
+func (p *comSyncIO2Provider) Release() {
+	p.syncIO2.Release()
+}
+
+// queryComSyncIO2 queries the group for IOPCSyncIO2 and applies authInfo to the new proxy.
+func queryComSyncIO2(provider groupProvider, authInfo *com.COAUTHINFO) (syncIO2Provider, error) {
+	var iUnknown *com.IUnknown
+	err := provider.QueryInterface(&com.IID_IOPCSyncIO2, unsafe.Pointer(&iUnknown))
+	if err != nil {
+		return nil, err
+	}
+	err = setProxyBlanket(iUnknown, authInfo)
+	if err != nil {
+		iUnknown.Release()
+		return nil, NewOPCWrapperError("set proxy blanket IOPCSyncIO2", err)
+	}
+	return &comSyncIO2Provider{syncIO2: &com.IOPCSyncIO2{IUnknown: iUnknown}}, nil
+}
+
+// syncIO2 returns the IOPCSyncIO2 interface of the group, querying it on first use. The outcome of
+// the query is cached until the group is released.
+func (g *OPCGroup) syncIO2() (syncIO2Provider, error) {
+	g.syncIO2Lock.Lock()
+	defer g.syncIO2Lock.Unlock()
+	if g.syncIO2Provider == nil && g.syncIO2Err == nil {
+		g.syncIO2Provider, g.syncIO2Err = g.pinnedSyncIO2()
+		if errors.Is(g.syncIO2Err, com.HRESULT(com.E_NOINTERFACE)) {
+			g.syncIO2Err = fmt.Errorf("%w: %w", ErrSyncIO2NotSupported, g.syncIO2Err)
+		}
+	}
+	return g.syncIO2Provider, g.syncIO2Err
+}
+
+// releaseSyncIO2 releases the IOPCSyncIO2 interface and forgets the outcome of the query.
+func (g *OPCGroup) releaseSyncIO2() {
+	g.syncIO2Lock.Lock()
+	defer g.syncIO2Lock.Unlock()
+	if g.syncIO2Provider != nil {
+		g.syncIO2Provider.Release()
+	}
+	g.syncIO2Provider, g.syncIO2Err = nil, nil
+}
+
+// ReadMaxAge reads items of the group with the OPC DA 3.0 IOPCSyncIO2 interface. For each item the server
+// returns its cached value if it is at most maxAge[i] milliseconds old and reads the device otherwise,
+// so repeated reads get fresh data without reading the device every time. A maxAge of 0 always reads the
+// device and 0xFFFFFFFF always reads the cache. maxAge must have one entry per server handle.
+// The interface is queried on first use; servers that only implement OPC DA 2.0 return an error
+// wrapping ErrSyncIO2NotSupported. Values that cannot be converted are reported as by SyncRead.
+// An empty serverHandles returns nil results without calling the server.
+//
+// Example:
+//
+//	states, errs, err := group.ReadMaxAge(serverHandles, []uint32{1000, 1000})
+//	if errors.Is(err, opcda.ErrSyncIO2NotSupported) {
+//		states, errs, err = group.SyncRead(opcda.OPC_DS_DEVICE, serverHandles)
+//	}
+func (g *OPCGroup) ReadMaxAge(serverHandles []uint32, maxAge []uint32) ([]*com.ItemState, []error, error) {
+	if g == nil || g.groupProvider == nil {
+		return nil, nil, errors.New("uninitialized group")
+	}
+	if len(maxAge) != len(serverHandles) {
+		return nil, nil, fmt.Errorf("got %d max ages for %d server handles", len(maxAge), len(serverHandles))
+	}
+	if len(serverHandles) == 0 {
+		return nil, nil, nil
+	}
+	provider, err := g.syncIO2()
+	if err != nil {
+		return nil, nil, err
+	}
+	states, errList, err := provider.ReadMaxAge(serverHandles, maxAge)
+	if err != nil {
+		return nil, nil, err
+	}
+	// IOPCSyncIO2 does not report client handles, unlike SyncRead
+	clientHandles := g.clientHandles()
+	resultErrs := make([]error, len(serverHandles))
+	for i, e := range errList {
+		if e < 0 {
+			resultErrs[i] = g.getError(e)
+			continue
+		}
+		if states[i] != nil {
+			states[i].ClientHandle = int32(clientHandles[serverHandles[i]])
+		}
+	}
+	splitUnsupportedStates(states, resultErrs)
+	return states, resultErrs, nil
+}
+
+// clientHandles maps the server handles of the items of the group to their client handles.
+func (g *OPCGroup) clientHandles() map[uint32]uint32 {
+	handles := make(map[uint32]uint32)
+	if g.items == nil {
+		return handles
+	}
+	g.items.RLock()
+	defer g.items.RUnlock()
+	for _, item := range g.items.items {
+		handles[item.GetServerHandle()] = item.GetClientHandle()
+	}
+	return handles
+}
+
+// OPCVQT is a value to write with WriteVQT, with an optional quality and timestamp.
+type OPCVQT struct {
+	// Value is the value to write.
+	Value interface{}
+	// Quality is the quality to write, such as OPC_QUALITY_GOOD; nil lets the server decide. The complete
+	// 16-bit word is written unmodified, including the substatus, limit and vendor bits.
+	Quality *uint16
+	// Timestamp is the timestamp to write; nil lets the server decide.
+	Timestamp *time.Time
+}
+
+// WriteVQT writes values to items of the group together with their quality and timestamp, using the
+// OPC DA 3.0 IOPCSyncIO2 interface. A nil Quality or Timestamp leaves it to the server, which usually
+// applies GOOD and the time of the write. Servers that do not accept quality or timestamp overrides
+// report OPC_E_NOTSUPPORTED for the item. vqts must have one entry per server handle.
+// The interface is queried on first use; servers that only implement OPC DA 2.0 return an error
+// wrapping ErrSyncIO2NotSupported. An empty serverHandles returns nil without calling the server.
+//
+// Example:
+//
+//	quality := uint16(opcda.OPC_QUALITY_UNCERTAIN)
+//	stamp := time.Now().Add(-time.Minute)
+//	errs, err := group.WriteVQT([]uint32{item.GetServerHandle()}, []opcda.OPCVQT{
+//		{Value: int32(42), Quality: &quality, Timestamp: &stamp},
+//	})
+func (g *OPCGroup) WriteVQT(serverHandles []uint32, vqts []OPCVQT) ([]error, error) {
+	if g == nil || g.groupProvider == nil {
+		return nil, errors.New("uninitialized group")
+	}
+	if len(vqts) != len(serverHandles) {
+		return nil, fmt.Errorf("got %d values for %d server handles", len(vqts), len(serverHandles))
+	}
+	if len(serverHandles) == 0 {
+		return nil, nil
+	}
+	provider, err := g.syncIO2()
+	if err != nil {
+		return nil, err
+	}
+	values, clearVariants, err := toItemVQTs(vqts)
+	if err != nil {
+		return nil, err
+	}
+	defer clearVariants()
+	errList, err := provider.WriteVQT(serverHandles, values)
+	if err != nil {
+		return nil, err
+	}
+	errs := make([]error, len(errList))
+	for i, e := range errList {
+		if e < 0 {
+			errs[i] = g.getError(e)
+		}
+	}
+	return errs, nil
+}
+
+// toItemVQTs converts values to write into their COM form. The returned function clears the variants
+// once the values have been written.
+func toItemVQTs(vqts []OPCVQT) ([]com.TagOPCITEMVQT, func(), error) {
+	values := make([]com.TagOPCITEMVQT, len(vqts))
+	variantWrappers := make([]*com.VariantWrapper, len(vqts))
+	clearVariants := func() {
+		for _, variant := range variantWrappers {
+			if variant != nil {
+				variant.Clear()
+			}
+		}
+	}
+	for i, vqt := range vqts {
+		variant, err := com.NewVariant(vqt.Value)
+		if err != nil {
+			clearVariants()
+			return nil, nil, err
+		}
+		variantWrappers[i] = variant
+		values[i].VDataValue = *variant.Variant
+		if vqt.Quality != nil {
+			values[i].BQualitySpecified = com.BoolToComBOOL(true)
+			values[i].WQuality = *vqt.Quality
+		}
+		if vqt.Timestamp != nil {
+			values[i].BTimeStampSpecified = com.BoolToComBOOL(true)
+			values[i].FtTimeStamp = windows.NsecToFiletime(vqt.Timestamp.UnixNano())
+		}
+	}
+	return values, clearVariants, nil
+}
@@ -0,0 +1,113 @@
+//go:build windows
+
+package opcda
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wends155/opcda/com"
+)
+
+func TestOPCGroup_ReadMaxAge_Mocked(t *testing.T) {
+	queries, released := 0, 0
+	syncIO2 := &mockSyncIO2Provider{
+		ReadMaxAgeFn: func(serverHandles []uint32, maxAge []uint32) ([]*com.ItemState, []int32, error) {
+			assert.Equal(t, []uint32{1, 2}, serverHandles)
+			assert.Equal(t, []uint32{1000, 0}, maxAge)
+			return []*com.ItemState{{Value: int32(7), Quality: OPC_QUALITY_GOOD}, nil},
+				[]int32{0, int32(OPCInvalidHandle)}, nil
+		},
+		ReleaseFn: func() { released++ },
+	}
+	server := newOPCServerWithProvider(&mockServerProvider{}, "mock", "localhost")
+	server.factories.querySyncIO2 = func(groupProvider, *com.COAUTHINFO) (syncIO2Provider, error) {
+		queries++
+		return syncIO2, nil
+	}
+	group := newInflightTestGroup(server, &mockGroupProvider{})
+	group.items = &OPCItems{items: []*OPCItem{{serverHandle: 1, clientHandle: 11}}}
+
+	states, errs, err := group.ReadMaxAge(nil, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, states)
+	assert.Nil(t, errs)
+	assert.Zero(t, queries)
+
+	_, _, err = group.ReadMaxAge([]uint32{1, 2}, []uint32{1000})
+	assert.Error(t, err)
+
+	for n := 0; n < 2; n++ {
+		states, errs, err = group.ReadMaxAge([]uint32{1, 2}, []uint32{1000, 0})
+		assert.NoError(t, err)
+		assert.Equal(t, int32(7), states[0].Value)
+		assert.Equal(t, int32(11), states[0].ClientHandle)
+		assert.NoError(t, errs[0])
+		assert.Error(t, errs[1])
+	}
+	assert.Equal(t, 1, queries)
+
+	group.Release()
+	assert.Equal(t, 1, released)
+}
+
+func TestOPCGroup_ReadMaxAge_NotSupported_Mocked(t *testing.T) {
+	queries := 0
+	server := newOPCServerWithProvider(&mockServerProvider{}, "mock", "localhost")
+	server.factories.querySyncIO2 = func(groupProvider, *com.COAUTHINFO) (syncIO2Provider, error) {
+		queries++
+		return nil, com.HRESULT(com.E_NOINTERFACE)
+	}
+	group := newInflightTestGroup(server, &mockGroupProvider{})
+
+	for n := 0; n < 2; n++ {
+		_, _, err := group.ReadMaxAge([]uint32{1}, []uint32{0})
+		assert.ErrorIs(t, err, ErrSyncIO2NotSupported)
+		assert.ErrorIs(t, err, com.HRESULT(com.E_NOINTERFACE))
+	}
+	assert.Equal(t, 1, queries)
+
+	var nilGroup *OPCGroup
+	_, _, err := nilGroup.ReadMaxAge([]uint32{1}, []uint32{0})
+	assert.Error(t, err)
+}
+
+func TestOPCGroup_WriteVQT_Mocked(t *testing.T) {
+	stamp := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	quality := uint16(OPC_QUALITY_UNCERTAIN)
+	syncIO2 := &mockSyncIO2Provider{
+		WriteVQTFn: func(serverHandles []uint32, values []com.TagOPCITEMVQT) ([]int32, error) {
+			assert.Equal(t, []uint32{1, 2}, serverHandles)
+			v, err := values[0].VDataValue.Value()
+			assert.NoError(t, err)
+			assert.Equal(t, int32(42), v)
+			assert.Equal(t, int32(1), values[0].BQualitySpecified)
+			assert.Equal(t, quality, values[0].WQuality)
+			assert.Equal(t, int32(1), values[0].BTimeStampSpecified)
+			assert.Equal(t, stamp.UnixNano(), values[0].FtTimeStamp.Nanoseconds())
+			assert.Zero(t, values[1].BQualitySpecified)
+			assert.Zero(t, values[1].BTimeStampSpecified)
+			return []int32{0, int32(OPCBadRights)}, nil
+		},
+	}
+	server := newOPCServerWithProvider(&mockServerProvider{}, "mock", "localhost")
+	server.factories.querySyncIO2 = func(groupProvider, *com.COAUTHINFO) (syncIO2Provider, error) {
+		return syncIO2, nil
+	}
+	group := newInflightTestGroup(server, &mockGroupProvider{})
+
+	errs, err := group.WriteVQT([]uint32{1, 2}, []OPCVQT{
+		{Value: int32(42), Quality: &quality, Timestamp: &stamp},
+		{Value: "text"},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, errs[0])
+	assert.Error(t, errs[1])
+
+	_, err = group.WriteVQT([]uint32{1}, nil)
+	assert.Error(t, err)
+	errs, err = group.WriteVQT(nil, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, errs)
+}
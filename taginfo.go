@@ -0,0 +1,204 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"github.com/wends155/opcda/com"
+)
+
+// TagInfo describes an item ID of the server: whether the server accepts it and what its standard
+// properties report.
+type TagInfo struct {
+	// ItemID is the item ID that was looked up.
+	ItemID string
+	// Exists reports whether the server accepted the item ID when validating it.
+	Exists bool
+	// ValidationError is the reason the server rejected the item ID; it is nil when Exists is true.
+	ValidationError error
+	// ItemInfo holds the standard properties of the item; it is only filled in when Exists is true.
+	// Properties the server lacks are reported in ItemInfo.Errors.
+	ItemInfo
+}
+
+// TagInfo validates the item ID and reads its canonical data type, access rights, description,
+// engineering units and EU and instrument ranges with a single GetItemProperties call.
+//
+// The item is validated in an inactive scratch group that is created on the first call, reused by
+// later calls and removed by Disconnect. An item ID the server rejects is not an error: the returned
+// TagInfo has Exists set to false and the reason in ValidationError. The returned error is only set
+// when the scratch group cannot be created or a call to the server fails.
+//
+// Example:
+//
+//	info, err := server.TagInfo("Channel1.Device1.Tag1")
+//	if err == nil && info.Exists {
+//		fmt.Printf("%s: %s [%s]\n", info.ItemID, info.Description, info.EUUnits)
+//	}
+func (s *OPCServer) TagInfo(itemID string) (*TagInfo, error) {
+	if s == nil || s.provider == nil || s.groups == nil {
+		return nil, errors.New("uninitialized server connection")
+	}
+	group, err := s.scratchGroup()
+	if err != nil {
+		return nil, err
+	}
+	validateErrs, err := group.items.Validate([]string{itemID}, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	info := &TagInfo{ItemID: itemID}
+	if len(validateErrs) > 0 && validateErrs[0] != nil {
+		info.ValidationError = validateErrs[0]
+		return info, nil
+	}
+	info.Exists = true
+	info.ItemInfo, err = readItemInfo(s.provider, itemID, s.getError)
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// ReadDevice reads items by item ID from the device through the inactive scratch group that TagInfo
+// validates items in, so one-shot reads never touch the active groups that feed subscriptions. The items
+// are added to the scratch group inactive for the read and removed afterwards. Unlike ReadItems it works
+// with OPC DA 2.0 servers, which lack IOPCItemIO. An item the server rejects when adding it or reading it
+// has its error set in Err; the returned error is only set when the scratch group cannot be created or a
+// call to the server fails. An empty itemIDs returns nil results without calling the server.
+//
+// Example:
+//
+//	results, err := server.ReadDevice([]string{"Channel1.Device1.Tag1"})
+//	if err == nil && results[0].Err == nil {
+//		fmt.Println(results[0].Value)
+//	}
+func (s *OPCServer) ReadDevice(itemIDs []string) ([]ItemResult, error) {
+	if s == nil || s.provider == nil || s.groups == nil {
+		return nil, errors.New("uninitialized server connection")
+	}
+	if len(itemIDs) == 0 {
+		return nil, nil
+	}
+	group, err := s.scratchGroup()
+	if err != nil {
+		return nil, err
+	}
+	defs := make([]ItemDef, len(itemIDs))
+	for i, itemID := range itemIDs {
+		defs[i].Tag = itemID
+	}
+	items, addErrs, err := group.items.AddItemsWithOptions(defs)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]ItemResult, len(itemIDs))
+	var handles []uint32
+	var indexes []int
+	for i, itemID := range itemIDs {
+		results[i].ItemID = itemID
+		if addErrs[i] != nil {
+			results[i].Err = addErrs[i]
+			continue
+		}
+		handles = append(handles, items[i].GetServerHandle())
+		indexes = append(indexes, i)
+	}
+	if len(handles) == 0 {
+		return results, nil
+	}
+	defer group.items.Remove(handles)
+	states, readErrs, err := group.SyncRead(OPC_DS_DEVICE, handles)
+	if err != nil {
+		return nil, err
+	}
+	for n, i := range indexes {
+		if readErrs[n] != nil {
+			results[i].Err = readErrs[n]
+			continue
+		}
+		if n < len(states) && states[n] != nil {
+			results[i].Value = states[n].Value
+			results[i].Quality = states[n].Quality
+			results[i].Timestamp = states[n].Timestamp
+		}
+	}
+	return results, nil
+}
+
+// getError converts a failed result code of the server into an OPCError.
+func (s *OPCServer) getError(errorCode int32) error {
+	return s.errors([]int32{errorCode})[0]
+}
+
+// scratchGroup returns the inactive group TagInfo validates items in and ReadDevice reads through,
+// creating it on first use.
+// The group is not part of the OPCGroups collection.
+func (s *OPCServer) scratchGroup() (*OPCGroup, error) {
+	s.scratchLock.Lock()
+	defer s.scratchLock.Unlock()
+	if s.scratch != nil {
+		return s.scratch, nil
+	}
+	group, err := s.addDetachedGroup()
+	if err != nil {
+		return nil, err
+	}
+	s.scratch = group
+	return group, nil
+}
+
+// addDetachedGroup adds an inactive, unnamed group to the server that is not part of the OPCGroups
+// collection. The caller removes it with removeDetachedGroup.
+func (s *OPCServer) addDetachedGroup() (*OPCGroup, error) {
+	gs := s.groups
+	hClientGroup := atomic.AddUint32(&gs.groupID, 1)
+	timeBias := int32(0)
+	deadband := float32(0)
+	serverGroup, revisedUpdateRate, ppUnk, err := s.provider.AddGroup(
+		"",
+		false,
+		gs.GetDefaultGroupUpdateRate(),
+		hClientGroup,
+		&timeBias,
+		&deadband,
+		gs.GetDefaultGroupLocaleID(),
+		&com.IID_IOPCGroupStateMgt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	group, err := gs.bindGroup(ppUnk, hClientGroup, serverGroup, "", revisedUpdateRate)
+	if err != nil {
+		if ppUnk != nil {
+			ppUnk.Release()
+		}
+		s.provider.RemoveGroup(serverGroup, true)
+		return nil, err
+	}
+	return group, nil
+}
+
+// removeDetachedGroup removes a group added by addDetachedGroup from the server and releases it.
+func (s *OPCServer) removeDetachedGroup(group *OPCGroup) error {
+	var err error
+	if s.provider != nil {
+		err = s.provider.RemoveGroup(group.serverGroupHandle, true)
+	}
+	group.Release()
+	return err
+}
+
+// releaseScratch removes the scratch group of TagInfo and ReadDevice from the server, if it was created.
+func (s *OPCServer) releaseScratch() error {
+	s.scratchLock.Lock()
+	defer s.scratchLock.Unlock()
+	if s.scratch == nil {
+		return nil
+	}
+	err := s.removeDetachedGroup(s.scratch)
+	s.scratch = nil
+	return err
+}
@@ -0,0 +1,139 @@
+//go:build windows
+
+package opcda
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wends155/opcda/com"
+	"golang.org/x/sys/windows"
+)
+
+func TestOPCServer_TagInfo_Mocked(t *testing.T) {
+	added, removed := 0, 0
+	provider := &mockServerProvider{
+		AddGroupFn: func(name string, active bool, updateRate uint32, clientGroup uint32, timeBias *int32, deadband *float32, localeID uint32, iid *windows.GUID) (uint32, uint32, *com.IUnknown, error) {
+			added++
+			assert.False(t, active)
+			return 9, updateRate, nil, nil
+		},
+		RemoveGroupFn: func(serverGroup uint32, force bool) error {
+			removed++
+			assert.Equal(t, uint32(9), serverGroup)
+			return nil
+		},
+		GetItemPropertiesFn: func(itemID string, propertyIDs []uint32) ([]interface{}, []int32, error) {
+			assert.Equal(t, "Random.Real8", itemID)
+			return []interface{}{int16(com.VT_R8), int32(1), nil, "Boiler temperature", float64(120), float64(-20), nil, nil},
+				[]int32{0, 0, int32(OPCInvalidPID), 0, 0, 0, int32(OPCInvalidPID), int32(OPCInvalidPID)}, nil
+		},
+	}
+	server := newOPCServerWithProvider(provider, "mock", "localhost")
+	server.factories.newGroup = func(gs *OPCGroups, iUnknown *com.IUnknown, clientGroupHandle uint32, serverGroupHandle uint32, groupName string, revisedUpdateRate uint32) (*OPCGroup, error) {
+		g := &OPCGroup{parent: gs, provider: gs.provider, serverGroupHandle: serverGroupHandle}
+		g.items = NewOPCItems(g, &mockItemMgtProvider{
+			ValidateItemsFn: func(items []com.TagOPCITEMDEF, bBlob bool) ([]com.TagOPCITEMRESULTStruct, []int32, error) {
+				if windows.UTF16PtrToString(items[0].SzItemID) == "Missing" {
+					return make([]com.TagOPCITEMRESULTStruct, 1), []int32{int32(OPCUnknownItemID)}, nil
+				}
+				return make([]com.TagOPCITEMRESULTStruct, 1), []int32{0}, nil
+			},
+		}, gs.provider)
+		return g, nil
+	}
+
+	info, err := server.TagInfo("Random.Real8")
+	assert.NoError(t, err)
+	assert.True(t, info.Exists)
+	assert.NoError(t, info.ValidationError)
+	assert.Equal(t, com.VT_R8, info.CanonicalDataType)
+	assert.Equal(t, OPC_READABLE, info.AccessRights)
+	assert.Equal(t, "Boiler temperature", info.Description)
+	assert.Equal(t, float64(-20), info.LowEU)
+	assert.Len(t, info.Errors, 3)
+	assert.Error(t, info.Errors[OPC_PROPERTY_EU_UNITS])
+	assert.Zero(t, server.groups.GetCount())
+
+	info, err = server.TagInfo("Missing")
+	assert.NoError(t, err)
+	assert.False(t, info.Exists)
+	assert.Error(t, info.ValidationError)
+	assert.Equal(t, 1, added)
+
+	assert.NoError(t, server.Disconnect())
+	assert.Equal(t, 1, removed)
+	assert.Nil(t, server.scratch)
+
+	var nilServer *OPCServer
+	_, err = nilServer.TagInfo("x")
+	assert.Error(t, err)
+}
+
+func TestOPCServer_ReadDevice_Mocked(t *testing.T) {
+	added := 0
+	provider := &mockServerProvider{
+		AddGroupFn: func(name string, active bool, updateRate uint32, clientGroup uint32, timeBias *int32, deadband *float32, localeID uint32, iid *windows.GUID) (uint32, uint32, *com.IUnknown, error) {
+			added++
+			assert.False(t, active)
+			return 9, updateRate, nil, nil
+		},
+	}
+	var removed []uint32
+	server := newOPCServerWithProvider(provider, "mock", "localhost")
+	server.factories.newGroup = func(gs *OPCGroups, iUnknown *com.IUnknown, clientGroupHandle uint32, serverGroupHandle uint32, groupName string, revisedUpdateRate uint32) (*OPCGroup, error) {
+		g := &OPCGroup{parent: gs, provider: gs.provider, serverGroupHandle: serverGroupHandle}
+		g.groupProvider = &mockGroupProvider{
+			SyncReadFn: func(source com.OPCDATASOURCE, serverHandles []uint32) ([]*com.ItemState, []int32, error) {
+				assert.Equal(t, OPC_DS_DEVICE, source)
+				assert.Equal(t, []uint32{100, 102}, serverHandles)
+				return []*com.ItemState{{Value: int32(5), Quality: OPC_QUALITY_GOOD}, nil}, []int32{0, int32(OPCBadRights)}, nil
+			},
+		}
+		g.items = NewOPCItems(g, &mockItemMgtProvider{
+			AddItemsFn: func(defs []com.TagOPCITEMDEF) ([]com.TagOPCITEMRESULTStruct, []int32, error) {
+				results := make([]com.TagOPCITEMRESULTStruct, len(defs))
+				errs := make([]int32, len(defs))
+				for i, def := range defs {
+					assert.Equal(t, int32(0), def.BActive)
+					results[i].Server = uint32(100 + i)
+					if windows.UTF16PtrToString(def.SzItemID) == "Missing" {
+						errs[i] = int32(OPCUnknownItemID)
+					}
+				}
+				return results, errs, nil
+			},
+			RemoveItemsFn: func(serverHandles []uint32) ([]int32, error) {
+				removed = append(removed, serverHandles...)
+				return make([]int32, len(serverHandles)), nil
+			},
+		}, gs.provider)
+		return g, nil
+	}
+
+	results, err := server.ReadDevice([]string{"Random.Int4", "Missing", "Secret"})
+	assert.NoError(t, err)
+	if assert.Len(t, results, 3) {
+		assert.Equal(t, "Random.Int4", results[0].ItemID)
+		assert.Equal(t, int32(5), results[0].Value)
+		assert.NoError(t, results[0].Err)
+		assert.Error(t, results[1].Err)
+		assert.Error(t, results[2].Err)
+		assert.Nil(t, results[2].Value)
+	}
+	assert.Equal(t, []uint32{100, 102}, removed)
+	assert.Zero(t, server.groups.GetCount())
+	assert.Empty(t, server.scratch.items.items)
+
+	// the scratch group is reused
+	_, err = server.ReadDevice([]string{"Random.Int4", "Missing", "Secret"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, added)
+	results, err = server.ReadDevice(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, results)
+
+	var nilServer *OPCServer
+	_, err = nilServer.ReadDevice([]string{"x"})
+	assert.Error(t, err)
+}
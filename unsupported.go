@@ -0,0 +1,48 @@
+//go:build windows
+
+package opcda
+
+import (
+	"github.com/wends155/opcda/com"
+)
+
+// splitUnsupported returns the raw com.VT and the *com.ErrUnsupportedVariant of a value the com package
+// could not convert, and v with a nil error for any other value.
+func splitUnsupported(v interface{}) (interface{}, error) {
+	if unsupported, ok := v.(*com.ErrUnsupportedVariant); ok {
+		return unsupported.VT, unsupported
+	}
+	return v, nil
+}
+
+// splitUnsupportedValues replaces the values that could not be converted with their raw com.VT and
+// reports the *com.ErrUnsupportedVariant in the matching entry of errs, unless it already holds an error.
+func splitUnsupportedValues(values []interface{}, errs []error) {
+	for i, v := range values {
+		value, err := splitUnsupported(v)
+		if err == nil {
+			continue
+		}
+		values[i] = value
+		if i < len(errs) && errs[i] == nil {
+			errs[i] = err
+		}
+	}
+}
+
+// splitUnsupportedStates is splitUnsupportedValues for the values of item states.
+func splitUnsupportedStates(states []*com.ItemState, errs []error) {
+	for i, state := range states {
+		if state == nil {
+			continue
+		}
+		value, err := splitUnsupported(state.Value)
+		if err == nil {
+			continue
+		}
+		state.Value = value
+		if i < len(errs) && errs[i] == nil {
+			errs[i] = err
+		}
+	}
+}
@@ -0,0 +1,43 @@
+//go:build windows
+
+package opcda
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/wends155/opcda/com"
+)
+
+// ErrShapeMismatch is returned by OPCItem.Write and OPCItem.WriteWithTimeout when a scalar value is written
+// to an item whose canonical data type is an array, or an array value to a scalar item. The write is
+// rejected before it reaches the server, which would otherwise report a less specific conversion error.
+var ErrShapeMismatch = errors.New("value shape does not match the canonical data type of the item")
+
+// checkShape returns ErrShapeMismatch if variant is an array and the canonical data type of the item is not,
+// or the other way round. Items of unknown canonical type, and VT_VARIANT items which can hold either, are
+// not checked.
+func (i *OPCItem) checkShape(variant *com.VARIANT) error {
+	canonical := i.GetCanonicalDataType()
+	if canonical == com.VT_EMPTY || canonical == com.VT_VARIANT {
+		return nil
+	}
+	itemIsArray := canonical&com.VT_ARRAY == com.VT_ARRAY
+	if variant.IsArray() == itemIsArray {
+		return nil
+	}
+	if itemIsArray {
+		return fmt.Errorf("%w: item %q is an array (VT 0x%04X), got a scalar value (VT 0x%04X)", ErrShapeMismatch, i.tag, uint16(canonical), uint16(variant.VT))
+	}
+	return fmt.Errorf("%w: item %q is a scalar (VT 0x%04X), got an array value (VT 0x%04X)", ErrShapeMismatch, i.tag, uint16(canonical), uint16(variant.VT))
+}
+
+// checkValueShape converts value to a VARIANT and checks its shape with checkShape.
+func (i *OPCItem) checkValueShape(value interface{}) error {
+	variant, err := com.NewVariant(value)
+	if err != nil {
+		return err
+	}
+	defer variant.Clear()
+	return i.checkShape(variant.Variant)
+}
@@ -0,0 +1,57 @@
+//go:build windows
+
+package opcda
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wends155/opcda/com"
+)
+
+func TestOPCItem_Write_ShapeMismatch_Mocked(t *testing.T) {
+	var writes int
+	group := &mockGroupProvider{
+		SyncWriteFn: func(serverHandles []uint32, values []com.VARIANT) ([]int32, error) {
+			writes++
+			return []int32{0}, nil
+		},
+	}
+	tests := []struct {
+		name      string
+		canonical com.VT
+		value     interface{}
+		mismatch  bool
+	}{
+		{name: "scalar to scalar", canonical: com.VT_I4, value: int32(1)},
+		{name: "array to array", canonical: com.VT_ARRAY | com.VT_I4, value: []int32{1, 2}},
+		{name: "scalar to array", canonical: com.VT_ARRAY | com.VT_I4, value: int32(1), mismatch: true},
+		{name: "array to scalar", canonical: com.VT_R8, value: []float64{1, 2}, mismatch: true},
+		{name: "unknown canonical type", canonical: com.VT_EMPTY, value: []int32{1}},
+		{name: "variant item", canonical: com.VT_VARIANT, value: []int32{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writes = 0
+			item := &OPCItem{groupProvider: group, tag: "Plant.Line1.Setpoints", nativeDataType: tt.canonical}
+			err := item.Write(tt.value)
+			if tt.mismatch {
+				assert.ErrorIs(t, err, ErrShapeMismatch)
+				assert.ErrorContains(t, err, "Plant.Line1.Setpoints")
+				assert.Zero(t, writes, "mismatched writes must not reach the server")
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, 1, writes)
+		})
+	}
+}
+
+func TestOPCItem_WriteWithTimeout_ShapeMismatch_Mocked(t *testing.T) {
+	group := &OPCGroup{provider: &mockServerProvider{}, event: &DataEventReceiver{}}
+	group.items = &OPCItems{parent: group}
+	item := &OPCItem{parent: group.items, tag: "Plant.Line1.Setpoints", nativeDataType: com.VT_ARRAY | com.VT_R4}
+
+	err := item.WriteWithTimeout(float32(1), 0)
+	assert.ErrorIs(t, err, ErrShapeMismatch)
+}